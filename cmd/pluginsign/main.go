@@ -0,0 +1,59 @@
+// pluginsign 生成发布者密钥及签名插件包目录
+//
+//	pluginsign -gen -publisher zhiting                         生成 zhiting.key 及 zhiting.pub
+//	pluginsign -publisher zhiting -key zhiting.key -dir ./demo 签名插件目录（config.json所在目录）
+//
+// 将 zhiting.pub 放到SA的 data/smartassistant/trusted_keys 目录下即可信任该发布者
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"flag"
+	"io/ioutil"
+	"log"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/signature"
+)
+
+var (
+	gen       = flag.Bool("gen", false, "generate publisher key pair")
+	publisher = flag.String("publisher", "", "publisher name")
+	keyFile   = flag.String("key", "", "publisher private key file")
+	dir       = flag.String("dir", ".", "plugin directory")
+)
+
+func main() {
+	flag.Parse()
+	if *publisher == "" {
+		log.Fatal("publisher required")
+	}
+
+	if *gen {
+		pub, priv, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = ioutil.WriteFile(*publisher+".key", []byte(base64.StdEncoding.EncodeToString(priv)), 0600); err != nil {
+			log.Fatal(err)
+		}
+		if err = ioutil.WriteFile(*publisher+".pub", []byte(base64.StdEncoding.EncodeToString(pub)), 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("generated %s.key and %s.pub", *publisher, *publisher)
+		return
+	}
+
+	data, err := ioutil.ReadFile(*keyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	key, err := signature.ParsePrivateKey(string(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = signature.Sign(*dir, *publisher, key); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("signed %s by %s", *dir, *publisher)
+}
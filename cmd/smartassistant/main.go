@@ -19,13 +19,18 @@ import (
 
 	"github.com/zhiting-tech/smartassistant/modules/api"
 	"github.com/zhiting-tech/smartassistant/modules/api/setting"
+	"github.com/zhiting-tech/smartassistant/modules/climate"
 	"github.com/zhiting-tech/smartassistant/modules/cloud"
 	"github.com/zhiting-tech/smartassistant/modules/config"
 	"github.com/zhiting-tech/smartassistant/modules/entity"
 	"github.com/zhiting-tech/smartassistant/modules/event"
 	"github.com/zhiting-tech/smartassistant/modules/extension"
+	"github.com/zhiting-tech/smartassistant/modules/homekit"
 	"github.com/zhiting-tech/smartassistant/modules/logreplay"
+	"github.com/zhiting-tech/smartassistant/modules/mqtt"
 	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/plugin/registry"
+	"github.com/zhiting-tech/smartassistant/modules/plugin/storage"
 	"github.com/zhiting-tech/smartassistant/modules/sadiscover"
 	"github.com/zhiting-tech/smartassistant/modules/task"
 	"github.com/zhiting-tech/smartassistant/modules/types"
@@ -69,6 +74,10 @@ func main() {
 	go analytics.Start(conf)
 
 	go wsServer.Run(ctx)
+	// 还原备份后导入插件的存储，并启动插件存储服务及内置注册中心
+	storage.Restore()
+	go registry.Default().Run(ctx)
+	go storage.NewServer().Run(ctx, registry.Default().RegisterServer)
 	// 新建插件manager并设为全局
 	pluginManager := plugin.NewManager()
 	plugin.SetGlobalManager(pluginManager)
@@ -80,6 +89,7 @@ func main() {
 	// 新建服务发现
 	discovery := plugin.NewDiscovery(pluginClient)
 	go discovery.Listen(ctx)
+	go plugin.GetSupervisor().Run(ctx)
 
 	go httpServer.Run(ctx)
 	go logHttpSrc.LogSrcRun(ctx)
@@ -91,6 +101,12 @@ func main() {
 	time.Sleep(3 * time.Second)
 
 	go taskManager.Run(ctx)
+	// 为温控器添加温控计划及覆盖的任务
+	go climate.Start()
+	// 启动已开启的HomeKit桥接器
+	go homekit.Start()
+	// 如果已配置，则连接MQTT服务器
+	go mqtt.Run(ctx)
 
 	reverseproxy.RegisterUpstream(types.CloudDisk, types.CloudDiskAddr)
 	// 如果已配置，则尝试连接 SmartCloud
@@ -0,0 +1,76 @@
+// thingmodelgen 根据物模型清单生成客户端使用的类型常量
+//
+//	thingmodelgen -lang go -pkg constants -o constants_gen.go                    使用当前代码中的物模型清单生成go常量
+//	thingmodelgen -lang ts -o thingmodel.ts -manifest http://sa/api/device/thing_model/manifest 使用SA接口返回的清单生成ts常量
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel/codegen"
+)
+
+var (
+	lang     = flag.String("lang", codegen.LangGo, "output language: go or ts")
+	output   = flag.String("o", "", "output file, default stdout")
+	pkg      = flag.String("pkg", "constants", "go package name")
+	manifest = flag.String("manifest", "", "manifest file or url, default the built-in manifest")
+)
+
+func main() {
+	flag.Parse()
+
+	m, err := loadManifest(*manifest)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := codegen.Generate(m, *lang, *pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err = ioutil.WriteFile(*output, data, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadManifest 读取物模型清单，支持清单文件及SA接口的返回（清单在data字段中）
+func loadManifest(src string) (m thingmodel.Manifest, err error) {
+	if src == "" {
+		return thingmodel.GetManifest(), nil
+	}
+
+	var data []byte
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		var resp *http.Response
+		if resp, err = http.Get(src); err != nil {
+			return
+		}
+		defer resp.Body.Close()
+		data, err = ioutil.ReadAll(resp.Body)
+	} else {
+		data, err = ioutil.ReadFile(src)
+	}
+	if err != nil {
+		return
+	}
+
+	var wrapped struct {
+		Data *thingmodel.Manifest `json:"data"`
+	}
+	if err = json.Unmarshal(data, &wrapped); err == nil && wrapped.Data != nil {
+		return *wrapped.Data, nil
+	}
+	err = json.Unmarshal(data, &m)
+	return
+}
@@ -0,0 +1,40 @@
+package climate
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/climate"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// GetDeviceState 用于处理获取温控器当前设定值来源接口的请求，返回生效的计划或覆盖及下次变化的时间
+func GetDeviceState(c *gin.Context) {
+	var (
+		err   error
+		state climate.State
+	)
+	defer func() {
+		response.HandleResponse(c, err, state)
+	}()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	device, err := entity.GetDeviceByID(id)
+	if err != nil || device.AreaID != session.Get(c).AreaID {
+		err = errors.New(status.DeviceNotExist)
+		return
+	}
+	if err = checkTargetPermit(c, device.ID, 0); err != nil {
+		return
+	}
+	state, err = climate.GetState(device)
+}
@@ -0,0 +1,115 @@
+package climate
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/climate"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// overrideReq 创建温控覆盖接口请求参数，温度使用用户选择的单位制
+type overrideReq struct {
+	Type              climate.OverrideType `json:"type"` // boost、away
+	DeviceID          int                  `json:"device_id"`
+	LocationID        int                  `json:"location_id"` // 家庭为房间，公司为部门
+	TargetTemperature *float64             `json:"target_temperature"`
+	HVACMode          string               `json:"hvac_mode"`
+	Duration          int                  `json:"duration"` // 持续时间（分钟），默认boost为60，away为1440
+}
+
+type listOverrideResp struct {
+	Overrides []entity.ClimateOverride `json:"overrides"`
+}
+
+// ListOverride 用于处理未过期的温控覆盖列表接口的请求，只返回用户可以控制的覆盖
+func ListOverride(c *gin.Context) {
+	var (
+		err  error
+		resp listOverrideResp
+	)
+	defer func() {
+		response.HandleResponse(c, err, resp)
+	}()
+
+	overrides, err := entity.GetClimateOverrides(session.Get(c).AreaID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	resp.Overrides = make([]entity.ClimateOverride, 0, len(overrides))
+	for _, o := range overrides {
+		if checkTargetPermit(c, o.DeviceID, o.LocationID) == nil {
+			resp.Overrides = append(resp.Overrides, o)
+		}
+	}
+}
+
+// CreateOverride 用于处理创建温控覆盖（提温、离家）接口的请求，同一设备或房间已有的覆盖会被替换
+func CreateOverride(c *gin.Context) {
+	var (
+		err      error
+		req      overrideReq
+		override entity.ClimateOverride
+	)
+	defer func() {
+		response.HandleResponse(c, err, override)
+	}()
+
+	if err = c.BindJSON(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	u := session.Get(c)
+	up, err := entity.GetUserPermissions(u.UserID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	if err = checkTargetPermit(c, req.DeviceID, req.LocationID); err != nil {
+		return
+	}
+	override = entity.ClimateOverride{
+		Type:              string(req.Type),
+		DeviceID:          req.DeviceID,
+		LocationID:        req.LocationID,
+		TargetTemperature: req.TargetTemperature,
+		HVACMode:          req.HVACMode,
+		Unit:              up.UnitSystem().Unit(thingmodel.UnitCelsius),
+		CreatorID:         u.UserID,
+		AreaID:            u.AreaID,
+	}
+	err = climate.CreateOverride(&override, time.Duration(req.Duration)*time.Minute)
+}
+
+// DeleteOverride 用于处理提前结束温控覆盖接口的请求
+func DeleteOverride(c *gin.Context) {
+	var (
+		err      error
+		override entity.ClimateOverride
+	)
+	defer func() {
+		response.HandleResponse(c, err, nil)
+	}()
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if override, err = entity.GetClimateOverrideByID(id, session.Get(c).AreaID); err != nil {
+		err = errors.Wrap(err, status.ClimateOverrideNotExist)
+		return
+	}
+	if err = checkTargetPermit(c, override.DeviceID, override.LocationID); err != nil {
+		return
+	}
+	err = climate.DeleteOverride(override)
+}
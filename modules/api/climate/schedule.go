@@ -0,0 +1,144 @@
+package climate
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/climate"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// scheduleReq 创建、修改温控计划接口请求参数，温度使用用户选择的单位制
+type scheduleReq struct {
+	Name       string          `json:"name"`
+	DeviceID   int             `json:"device_id"`
+	LocationID int             `json:"location_id"` // 家庭为房间，公司为部门
+	Enabled    *bool           `json:"enabled"`     // 默认启用
+	Setpoints  climate.Program `json:"setpoints"`
+}
+
+func (req scheduleReq) schedule(c *gin.Context) (s entity.ClimateSchedule, err error) {
+	u := session.Get(c)
+	up, err := entity.GetUserPermissions(u.UserID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	s = entity.ClimateSchedule{
+		Name:       req.Name,
+		DeviceID:   req.DeviceID,
+		LocationID: req.LocationID,
+		Enabled:    req.Enabled == nil || *req.Enabled,
+		Unit:       up.UnitSystem().Unit(thingmodel.UnitCelsius),
+		CreatorID:  u.UserID,
+		AreaID:     u.AreaID,
+	}
+	err = checkTargetPermit(c, s.DeviceID, s.LocationID)
+	return
+}
+
+type listScheduleResp struct {
+	Schedules []entity.ClimateSchedule `json:"schedules"`
+}
+
+// ListSchedule 用于处理温控计划列表接口的请求，只返回用户可以控制的计划
+func ListSchedule(c *gin.Context) {
+	var (
+		err  error
+		resp listScheduleResp
+	)
+	defer func() {
+		response.HandleResponse(c, err, resp)
+	}()
+
+	schedules, err := entity.GetClimateSchedules(session.Get(c).AreaID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	resp.Schedules = make([]entity.ClimateSchedule, 0, len(schedules))
+	for _, s := range schedules {
+		if checkTargetPermit(c, s.DeviceID, s.LocationID) == nil {
+			resp.Schedules = append(resp.Schedules, s)
+		}
+	}
+}
+
+// GetSchedule 用于处理温控计划详情接口的请求
+func GetSchedule(c *gin.Context) {
+	var (
+		err      error
+		schedule entity.ClimateSchedule
+	)
+	defer func() {
+		response.HandleResponse(c, err, schedule)
+	}()
+
+	schedule, err = getSchedule(c)
+}
+
+// CreateSchedule 用于处理创建温控计划接口的请求
+func CreateSchedule(c *gin.Context) {
+	var (
+		err      error
+		req      scheduleReq
+		schedule entity.ClimateSchedule
+	)
+	defer func() {
+		response.HandleResponse(c, err, schedule)
+	}()
+
+	if err = c.BindJSON(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if schedule, err = req.schedule(c); err != nil {
+		return
+	}
+	err = climate.CreateSchedule(&schedule, req.Setpoints)
+}
+
+// UpdateSchedule 用于处理修改温控计划接口的请求
+func UpdateSchedule(c *gin.Context) {
+	var (
+		err      error
+		req      scheduleReq
+		old      entity.ClimateSchedule
+		schedule entity.ClimateSchedule
+	)
+	defer func() {
+		response.HandleResponse(c, err, schedule)
+	}()
+
+	if old, err = getSchedule(c); err != nil {
+		return
+	}
+	if err = c.BindJSON(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if schedule, err = req.schedule(c); err != nil {
+		return
+	}
+	schedule.CreatedAt = old.CreatedAt
+	err = climate.UpdateSchedule(old, &schedule, req.Setpoints)
+}
+
+// DeleteSchedule 用于处理删除温控计划接口的请求
+func DeleteSchedule(c *gin.Context) {
+	var (
+		err      error
+		schedule entity.ClimateSchedule
+	)
+	defer func() {
+		response.HandleResponse(c, err, nil)
+	}()
+
+	if schedule, err = getSchedule(c); err != nil {
+		return
+	}
+	err = climate.DeleteSchedule(schedule)
+}
@@ -0,0 +1,66 @@
+// Package climate 温控计划及临时覆盖
+package climate
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/middleware"
+	"github.com/zhiting-tech/smartassistant/modules/climate"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// RegisterClimateRouter 注册与温控相关的路由及其处理函数
+func RegisterClimateRouter(r gin.IRouter) {
+	climateGroup := r.Group("climate", middleware.RequireAccountWithScope(types.ScopeDevice))
+	climateGroup.GET("schedules", ListSchedule)
+	climateGroup.POST("schedules", CreateSchedule)
+	climateGroup.GET("schedules/:id", GetSchedule)
+	climateGroup.PUT("schedules/:id", UpdateSchedule)
+	climateGroup.DELETE("schedules/:id", DeleteSchedule)
+	climateGroup.GET("overrides", ListOverride)
+	climateGroup.POST("overrides", CreateOverride)
+	climateGroup.DELETE("overrides/:id", DeleteOverride)
+	climateGroup.GET("devices/:id", GetDeviceState)
+}
+
+// checkTargetPermit 判断用户是否可以控制计划或覆盖作用的所有温控器
+func checkTargetPermit(c *gin.Context, deviceID, locationID int) error {
+	u := session.Get(c)
+	up, err := entity.GetUserPermissions(u.UserID)
+	if err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	if up.IsOwner() {
+		return nil
+	}
+	devices, err := climate.Thermostats(u.AreaID, deviceID, locationID)
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if !up.IsDeviceControlPermit(d.ID) {
+			return errors.New(status.Deny)
+		}
+	}
+	return nil
+}
+
+func getSchedule(c *gin.Context) (schedule entity.ClimateSchedule, err error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if schedule, err = entity.GetClimateScheduleByID(id, session.Get(c).AreaID); err != nil {
+		err = errors.Wrap(err, status.ClimateScheduleNotExist)
+		return
+	}
+	err = checkTargetPermit(c, schedule.DeviceID, schedule.LocationID)
+	return
+}
@@ -0,0 +1,44 @@
+package device
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/device"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// batchControlReq 批量控制设备接口请求参数
+type batchControlReq struct {
+	Devices []device.BatchDevice `json:"devices"`
+}
+
+// batchControlResp 批量控制设备接口返回数据
+type batchControlResp struct {
+	Results []device.BatchResult `json:"results"`
+}
+
+// BatchControlDevices 批量设置多个设备的属性，返回每个设备的执行结果
+func BatchControlDevices(c *gin.Context) {
+	var (
+		req  batchControlReq
+		resp batchControlResp
+		err  error
+	)
+	defer func() {
+		response.HandleResponse(c, err, resp)
+	}()
+	if err = c.BindJSON(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+
+	u := session.Get(c)
+	up, err := entity.GetUserPermissions(u.UserID)
+	if err != nil {
+		return
+	}
+	resp.Results = device.BatchSetAttributes(c.Request.Context(), u.AreaID, up, req.Devices)
+}
@@ -0,0 +1,255 @@
+package device
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/archive"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+
+	exportBatchSize = 500
+)
+
+// exportStatesReq 导出设备状态接口请求参数
+type exportStatesReq struct {
+	DeviceIDs []int    `form:"device_ids"` // 为空则导出有权限的所有设备
+	AttrTypes []string `form:"attr_types"` // 为空则导出所有属性
+	StartAt   *int64   `form:"start_at"`
+	EndAt     *int64   `form:"end_at"`
+	Format    string   `form:"format"` // csv 或 ndjson，默认csv
+	Zip       bool     `form:"zip"`    // 是否压缩为zip
+
+	unitSystem thingmodel.UnitSystem // 用户选择的单位制
+}
+
+// exportState 导出的单条设备状态
+type exportState struct {
+	ID         int                `json:"id"`
+	DeviceID   int                `json:"device_id"`
+	DeviceName string             `json:"device_name"`
+	IID        string             `json:"iid"`
+	AID        int                `json:"aid"`
+	Attribute  string             `json:"attribute"`
+	ValType    thingmodel.ValType `json:"val_type"`
+	Val        interface{}        `json:"val"`
+	Unit       thingmodel.Unit    `json:"unit,omitempty"`
+	Time       string             `json:"time"`
+}
+
+var exportCSVHeader = []string{"id", "device_id", "device_name", "iid", "aid", "attribute", "val_type", "val", "unit", "time"}
+
+func (req *exportStatesReq) validate() error {
+	if req.Format == "" {
+		req.Format = exportFormatCSV
+	}
+	if req.Format != exportFormatCSV && req.Format != exportFormatNDJSON {
+		return errors.Newf(status.DeviceStatesExportParamErr, "format")
+	}
+	if req.StartAt != nil && req.EndAt != nil && *req.StartAt > *req.EndAt {
+		return errors.Newf(status.DeviceStatesExportParamErr, "start_at")
+	}
+	return nil
+}
+
+// filter 根据请求参数和用户权限生成查询条件，并返回导出涉及的设备
+func (req *exportStatesReq) filter(c *gin.Context) (filter entity.DeviceStateFilter, devices map[int]entity.Device, err error) {
+	u := session.Get(c)
+	up, err := entity.GetUserPermissions(u.UserID)
+	if err != nil {
+		return
+	}
+	req.unitSystem = up.UnitSystem()
+
+	var areaDevices []entity.Device
+	if areaDevices, err = entity.GetDevices(u.AreaID); err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	permitted := make(map[int]entity.Device)
+	for _, d := range areaDevices {
+		if d.IsSa() || !up.IsDeviceControlPermit(d.ID) {
+			continue
+		}
+		permitted[d.ID] = d
+	}
+
+	devices = make(map[int]entity.Device)
+	if len(req.DeviceIDs) == 0 {
+		devices = permitted
+	}
+	for _, id := range req.DeviceIDs {
+		d, ok := permitted[id]
+		if !ok {
+			err = errors.New(status.Deny)
+			return
+		}
+		devices[id] = d
+	}
+
+	for id := range devices {
+		filter.DeviceIDs = append(filter.DeviceIDs, id)
+	}
+	filter.AttrTypes = req.AttrTypes
+	if req.StartAt != nil {
+		startAt := time.Unix(*req.StartAt, 0)
+		filter.StartAt = &startAt
+	}
+	if req.EndAt != nil {
+		endAt := time.Unix(*req.EndAt, 0)
+		filter.EndAt = &endAt
+	}
+	return
+}
+
+// ExportDeviceStates 以csv或ndjson格式流式导出设备状态（日志）
+func ExportDeviceStates(c *gin.Context) {
+	var (
+		req     exportStatesReq
+		filter  entity.DeviceStateFilter
+		devices map[int]entity.Device
+		err     error
+	)
+	if err = c.BindQuery(&req); err != nil {
+		response.HandleResponse(c, errors.Wrap(err, errors.BadRequest), nil)
+		return
+	}
+	if err = req.validate(); err != nil {
+		response.HandleResponse(c, err, nil)
+		return
+	}
+	if filter, devices, err = req.filter(c); err != nil {
+		response.HandleResponse(c, err, nil)
+		return
+	}
+
+	filename := fmt.Sprintf("device_states_%s.%s", time.Now().Format("20060102150405"), req.Format)
+	write := func(w io.Writer) error {
+		return writeDeviceStates(w, req.Format, filter, devices, req.unitSystem)
+	}
+
+	// 开始写入后无法再返回错误信息，只记录日志
+	if req.Zip {
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", filename))
+		err = archive.ZipStream(c.Writer, filename, write)
+	} else {
+		if req.Format == exportFormatCSV {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+		} else {
+			c.Header("Content-Type", "application/x-ndjson")
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+		err = write(c.Writer)
+	}
+	if err != nil {
+		logger.Errorf("export device states error: %v", err)
+	}
+}
+
+func writeDeviceStates(w io.Writer, format string, filter entity.DeviceStateFilter,
+	devices map[int]entity.Device, system thingmodel.UnitSystem) (err error) {
+	var (
+		csvWriter *csv.Writer
+		encoder   *json.Encoder
+	)
+	if format == exportFormatCSV {
+		csvWriter = csv.NewWriter(w)
+		if err = csvWriter.Write(exportCSVHeader); err != nil {
+			return
+		}
+	} else {
+		encoder = json.NewEncoder(w)
+	}
+
+	return entity.RangeDeviceStates(filter, exportBatchSize, func(states []entity.DeviceState) error {
+		for _, state := range states {
+			s, err := decodeDeviceState(state, devices[state.DeviceID], system)
+			if err != nil {
+				logger.Warnf("decode device state %d error: %v", state.ID, err)
+				continue
+			}
+			if encoder != nil {
+				if err = encoder.Encode(s); err != nil {
+					return err
+				}
+				continue
+			}
+			record := []string{
+				strconv.Itoa(s.ID), strconv.Itoa(s.DeviceID), s.DeviceName, s.IID,
+				strconv.Itoa(s.AID), s.Attribute, s.ValType.String(), formatStateVal(s.Val), string(s.Unit), s.Time,
+			}
+			if err = csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
+		if csvWriter != nil {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		return nil
+	})
+}
+
+// decodeDeviceState 解析记录的属性，值按物模型中的类型转换并换算为用户单位制下的单位
+func decodeDeviceState(state entity.DeviceState, d entity.Device, system thingmodel.UnitSystem) (s exportState, err error) {
+	var attr thingmodel.Attribute
+	if err = json.Unmarshal(state.State, &attr); err != nil {
+		return
+	}
+	attr = attr.InSystem(system)
+	s = exportState{
+		ID:         state.ID,
+		DeviceID:   state.DeviceID,
+		DeviceName: d.Name,
+		IID:        state.IID,
+		AID:        attr.AID,
+		Attribute:  attr.Type,
+		ValType:    attr.ValType,
+		Val:        attr.Val,
+		Unit:       attr.Unit,
+		Time:       state.CreatedAt.Format(time.RFC3339),
+	}
+	switch attr.ValType {
+	case thingmodel.Int, thingmodel.Int32, thingmodel.Int64:
+		if v, ok := attr.Val.(float64); ok {
+			s.Val = int64(v)
+		}
+	}
+	return
+}
+
+func formatStateVal(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	data, _ := json.Marshal(val)
+	return string(data)
+}
@@ -0,0 +1,160 @@
+package device
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+// exportFixture 家庭中有两个设备，成员只有温度计的控制权限并使用英制单位
+type exportFixture struct {
+	owner, member      entity.User
+	thermometer, light entity.Device
+}
+
+func newExportFixture(t *testing.T) (f exportFixture) {
+	area, err := entity.CreateArea("export", entity.AreaOfHome)
+	require.NoError(t, err)
+	f.owner = entity.User{AreaID: area.ID}
+	require.NoError(t, entity.CreateUser(&f.owner, entity.GetDB()))
+	require.NoError(t, entity.SetAreaOwnerID(area.ID, f.owner.ID, entity.GetDB()))
+
+	temperature := thingmodel.Temperature
+	temperature.AID, temperature.Unit = 1, thingmodel.UnitCelsius
+	brightness := thingmodel.Brightness
+	brightness.AID = 1
+	f.thermometer = createExportDevice(t, area.ID, "thermometer", temperature, 20.5)
+	f.light = createExportDevice(t, area.ID, "light", brightness, 80)
+
+	f.member = entity.User{AreaID: area.ID, UnitSystem: thingmodel.UnitSystemImperial}
+	require.NoError(t, entity.CreateUser(&f.member, entity.GetDB()))
+	role := entity.Role{Name: "export", AreaID: area.ID}
+	require.NoError(t, entity.GetDB().Create(&role).Error)
+	p := entity.RolePermission{RoleID: role.ID, Action: types.ActionControl,
+		Target: types.DeviceTarget(f.thermometer.ID), Attribute: "1"}
+	require.NoError(t, entity.GetDB().Create(&p).Error)
+	require.NoError(t, entity.CreateUserRole([]entity.UserRole{{UserID: f.member.ID, RoleID: role.ID}}))
+	return
+}
+
+// createExportDevice 创建设备并记录一条属性状态
+func createExportDevice(t *testing.T, areaID uint64, iid string, attr thingmodel.Attribute, val interface{}) entity.Device {
+	d := entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID}
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	attr.Val = val
+	state, _ := json.Marshal(attr)
+	require.NoError(t, entity.InsertDeviceState(d, state))
+	return d
+}
+
+func exportStates(t *testing.T, user entity.User, query string) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("userInfo", &session.User{UserID: user.ID, AreaID: user.AreaID})
+	})
+	r.GET("device/states/export", ExportDeviceStates)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/device/states/export?"+query, nil))
+	return w
+}
+
+func decodeNDJSON(t *testing.T, r io.Reader) (states []exportState) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var s exportState
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &s), scanner.Text())
+		states = append(states, s)
+	}
+	return
+}
+
+func TestExportDeviceStatesCSV(t *testing.T) {
+	f := newExportFixture(t)
+
+	w := exportStates(t, f.owner, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, exportCSVHeader, records[0])
+	byDevice := map[string][]string{}
+	for _, record := range records[1:] {
+		byDevice[record[1]] = record
+	}
+	thermometer := byDevice[strconv.Itoa(f.thermometer.ID)]
+	assert.Equal(t, []string{"thermometer", "thermometer", "1", "temperature", "float32", "20.5", "°C"}, thermometer[2:9])
+	light := byDevice[strconv.Itoa(f.light.ID)]
+	assert.Equal(t, []string{"light", "light", "1", "brightness", "int32", "80", "%"}, light[2:9])
+}
+
+func TestExportDeviceStatesPermission(t *testing.T) {
+	f := newExportFixture(t)
+
+	// 只导出有控制权限的设备，值换算为用户选择的单位制
+	w := exportStates(t, f.member, "format=ndjson")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	states := decodeNDJSON(t, w.Body)
+	require.Len(t, states, 1)
+	assert.Equal(t, f.thermometer.ID, states[0].DeviceID)
+	assert.Equal(t, thingmodel.UnitFahrenheit, states[0].Unit)
+	assert.InDelta(t, 68.9, states[0].Val, 0.01)
+
+	// 指定没有权限的设备时拒绝导出
+	w = exportStates(t, f.member, "device_ids="+strconv.Itoa(f.light.ID))
+	var resp struct {
+		Status int `json:"status"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotZero(t, resp.Status)
+}
+
+func TestExportDeviceStatesZip(t *testing.T) {
+	f := newExportFixture(t)
+
+	w := exportStates(t, f.owner, "format=ndjson&zip=true&device_ids="+strconv.Itoa(f.light.ID))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".ndjson.zip")
+
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	fr, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer fr.Close()
+	states := decodeNDJSON(t, fr)
+	require.Len(t, states, 1)
+	assert.Equal(t, f.light.ID, states[0].DeviceID)
+	assert.Equal(t, "brightness", states[0].Attribute)
+	assert.Equal(t, 80.0, states[0].Val) // json解码后的数字
+}
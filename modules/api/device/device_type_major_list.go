@@ -40,6 +40,7 @@ var majorTypes = map[plugin.DeviceType]string{
 	plugin.TypeSecurity:       "安防",
 	plugin.TypeSensor:         "传感器",
 	plugin.TypeLifeElectric:   "生活电器",
+	plugin.TypeAudioVideo:     "影音娱乐",
 }
 
 // MajorTypeList 获取主分类
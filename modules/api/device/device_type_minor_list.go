@@ -65,6 +65,10 @@ var minorTypes = map[plugin.DeviceType]minorType{
 
 	plugin.TypeCurtain: {"窗帘电机", plugin.TypeLifeElectric},
 
+	plugin.TypeTelevision:   {"电视", plugin.TypeAudioVideo},
+	plugin.TypeSmartSpeaker: {"智能音箱", plugin.TypeAudioVideo},
+	plugin.TypeMediaPlayer:  {"播放器", plugin.TypeAudioVideo},
+
 	plugin.TypeTemperatureAndHumiditySensor: {"温湿度传感器", plugin.TypeSensor},
 	plugin.TypeHumanSensors:                 {"人体传感器", plugin.TypeSensor},
 	plugin.TypeSmokeSensor:                  {"烟雾传感器", plugin.TypeSensor},
@@ -8,7 +8,9 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mozillazg/go-unidecode"
 
+	"github.com/zhiting-tech/smartassistant/modules/climate"
 	"github.com/zhiting-tech/smartassistant/modules/device"
+	"github.com/zhiting-tech/smartassistant/modules/homekit"
 	"github.com/zhiting-tech/smartassistant/modules/utils/session"
 
 	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
@@ -142,12 +144,20 @@ func UpdateDevice(c *gin.Context) {
 	if err = entity.UpdateDevice(id, updateDevice); err != nil {
 		return
 	}
+	if updateDevice.Name != "" {
+		// 同步HomeKit中配件的名称
+		go homekit.Refresh(curDevice.AreaID)
+	}
 
 	if req.CascadeLocation == true && req.LocationID != 0 {
 		if err = entity.UpdateSubDevicesLocation(curDevice.IID, updateDevice.LocationID); err != nil {
 			return
 		}
 	}
+	if curDevice.LocationID != updateDevice.LocationID || curDevice.DepartmentID != updateDevice.DepartmentID {
+		// 温控器更换房间后使用新房间的计划或覆盖
+		go climate.RefreshLocation(curDevice, req.CascadeLocation && req.LocationID != 0)
+	}
 
 	if req.Common != nil {
 		u := session.Get(c)
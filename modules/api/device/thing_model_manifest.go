@@ -0,0 +1,23 @@
+package device
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// ThingModelManifest 用于处理获取物模型清单接口的请求，
+// 返回所有预定义的服务、属性及值类型、范围、权限、单位，清单hash作为ETag
+func ThingModelManifest(c *gin.Context) {
+	m := thingmodel.GetManifest()
+	etag := `"` + m.Hash + `"`
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	response.HandleResponse(c, nil, m)
+}
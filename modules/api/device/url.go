@@ -26,10 +26,19 @@ func RegisterDeviceRouter(r gin.IRouter) {
 	deviceAuthGroup.GET(":id", requireBelongsToUser, InfoDevice)
 	deviceAuthGroup.GET(":id/logo", requireBelongsToUser, InfoDeviceLogo)
 
+	// 导出设备状态（日志）
+	r.GET("device/states/export", middleware.RequireAccountWithScope(types.ScopeDevice), ExportDeviceStates)
+
+	// 批量控制设备
+	r.PUT("device/attributes", middleware.RequireAccountWithScope(types.ScopeDevice), BatchControlDevices)
+
 	// 设备型号列表（按分类分组）
 	r.GET("device/types/major", MajorTypeList)
 	r.GET("device/types/minor", MinorTypeList)
 
+	// 物模型清单，用于客户端生成类型常量
+	r.GET("device/thing_model/manifest", ThingModelManifest)
+
 	// 检查SA是否已绑定
 	r.GET("/check", CheckSaDevice)
 	// 检查SA是否对应的云端同步下来的家庭
@@ -0,0 +1,111 @@
+package energy
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/energy"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// consumptionReq 能耗统计接口请求参数
+type consumptionReq struct {
+	DeviceIDs []int           `form:"device_ids"` // 为空则统计有权限的所有设备
+	StartAt   *int64          `form:"start_at"`   // 默认为结束时间前24小时、30天或12个月
+	EndAt     *int64          `form:"end_at"`     // 默认为当前时间
+	Interval  energy.Interval `form:"interval"`   // hour、day、month，默认day
+	GroupBy   energy.GroupBy  `form:"group_by"`   // device、location、area，默认area
+}
+
+// query 根据请求参数和用户权限生成统计条件
+func (req consumptionReq) query(c *gin.Context) (q energy.Query, err error) {
+	u := session.Get(c)
+	q = energy.Query{
+		AreaID:   u.AreaID,
+		Interval: req.Interval,
+		GroupBy:  req.GroupBy,
+		EndAt:    time.Now(),
+	}
+	if q.Interval == "" {
+		q.Interval = energy.IntervalDay
+	}
+	if q.GroupBy == "" {
+		q.GroupBy = energy.GroupByArea
+	}
+	if req.EndAt != nil {
+		q.EndAt = time.Unix(*req.EndAt, 0)
+	}
+	switch {
+	case req.StartAt != nil:
+		q.StartAt = time.Unix(*req.StartAt, 0)
+	case q.Interval == energy.IntervalHour:
+		q.StartAt = q.EndAt.Add(-24 * time.Hour)
+	case q.Interval == energy.IntervalMonth:
+		q.StartAt = q.EndAt.AddDate(0, -11, 0)
+	default:
+		q.StartAt = q.EndAt.AddDate(0, 0, -29)
+	}
+
+	up, err := entity.GetUserPermissions(u.UserID)
+	if err != nil {
+		return
+	}
+	// 拥有者统计所有设备，包括已删除设备的历史用电量
+	if up.IsOwner() && len(req.DeviceIDs) == 0 {
+		return
+	}
+
+	devices, err := entity.GetDevices(u.AreaID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	permitted := make(map[int]bool)
+	for _, d := range devices {
+		if !d.IsSa() && up.IsDeviceControlPermit(d.ID) {
+			permitted[d.ID] = true
+		}
+	}
+	q.DeviceIDs = make([]int, 0)
+	if len(req.DeviceIDs) == 0 {
+		for id := range permitted {
+			q.DeviceIDs = append(q.DeviceIDs, id)
+		}
+		return
+	}
+	for _, id := range req.DeviceIDs {
+		if !permitted[id] {
+			err = errors.New(status.Deny)
+			return
+		}
+		q.DeviceIDs = append(q.DeviceIDs, id)
+	}
+	return
+}
+
+// GetConsumption 用于处理能耗统计接口的请求，按设备、房间（部门）或家庭统计每小时、每天或每月的用电量及电费
+func GetConsumption(c *gin.Context) {
+	var (
+		err    error
+		req    consumptionReq
+		q      energy.Query
+		report energy.Report
+	)
+	defer func() {
+		response.HandleResponse(c, err, report)
+	}()
+
+	if err = c.BindQuery(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if q, err = req.query(c); err != nil {
+		return
+	}
+	report, err = energy.GetReport(q)
+}
@@ -0,0 +1,40 @@
+package energy
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/energy"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// GetTariff 用于处理获取电价设置接口的请求
+func GetTariff(c *gin.Context) {
+	var (
+		err    error
+		tariff energy.Tariff
+	)
+	defer func() {
+		response.HandleResponse(c, err, tariff)
+	}()
+
+	tariff, err = energy.GetTariff(session.Get(c).AreaID)
+}
+
+// UpdateTariff 用于处理修改电价设置接口的请求，仅拥有者可以修改
+func UpdateTariff(c *gin.Context) {
+	var (
+		err    error
+		tariff energy.Tariff
+	)
+	defer func() {
+		response.HandleResponse(c, err, nil)
+	}()
+
+	if err = c.BindJSON(&tariff); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	err = energy.SaveTariff(session.Get(c).AreaID, tariff)
+}
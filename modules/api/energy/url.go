@@ -0,0 +1,17 @@
+// Package energy 能耗统计及电价设置
+package energy
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/middleware"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+)
+
+// RegisterEnergyRouter 注册与能耗相关的路由及其处理函数
+func RegisterEnergyRouter(r gin.IRouter) {
+	energyGroup := r.Group("energy", middleware.RequireAccountWithScope(types.ScopeDevice))
+	energyGroup.GET("consumption", GetConsumption)
+	energyGroup.GET("tariff", GetTariff)
+	energyGroup.PUT("tariff", middleware.RequireOwner, UpdateTariff)
+}
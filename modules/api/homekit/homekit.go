@@ -0,0 +1,79 @@
+package homekit
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/homekit"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// UpdateSettingReq 修改HomeKit桥接设置接口请求参数
+type UpdateSettingReq struct {
+	Enabled   *bool `json:"enabled"`
+	DeviceIDs []int `json:"device_ids"` // 为空则桥接所有支持的设备
+}
+
+// GetStatus 用于处理获取HomeKit桥接状态接口的请求，设置码可以直接配对桥接器，只返回给拥有者
+func GetStatus(c *gin.Context) {
+	var (
+		err error
+		st  homekit.Status
+	)
+	defer func() {
+		response.HandleResponse(c, err, st)
+	}()
+
+	u := session.Get(c)
+	if st, err = homekit.GetStatus(u.AreaID); err != nil {
+		return
+	}
+	if !u.IsOwner {
+		st.SetupCode, st.SetupURI = "", ""
+	}
+}
+
+// UpdateSetting 用于处理开启、关闭HomeKit桥接及修改桥接设备接口的请求
+func UpdateSetting(c *gin.Context) {
+	var (
+		err error
+		req UpdateSettingReq
+		st  homekit.Status
+	)
+	defer func() {
+		response.HandleResponse(c, err, st)
+	}()
+
+	if err = c.BindJSON(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if req.Enabled == nil {
+		err = errors.Newf(status.HomeKitParamErr, "enabled")
+		return
+	}
+	areaID := session.Get(c).AreaID
+	if err = homekit.Update(areaID, *req.Enabled, req.DeviceIDs); err != nil {
+		return
+	}
+	st, err = homekit.GetStatus(areaID)
+}
+
+// Reset 用于处理重置HomeKit桥接接口的请求，重置后需要在家庭App中重新添加
+func Reset(c *gin.Context) {
+	var (
+		err error
+		st  homekit.Status
+	)
+	defer func() {
+		response.HandleResponse(c, err, st)
+	}()
+
+	areaID := session.Get(c).AreaID
+	if err = homekit.Reset(areaID); err != nil {
+		return
+	}
+	st, err = homekit.GetStatus(areaID)
+}
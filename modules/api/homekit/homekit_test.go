@@ -0,0 +1,60 @@
+package homekit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/homekit"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+// getStatus 以指定用户的身份获取桥接状态
+func getStatus(t *testing.T, user *session.User) map[string]interface{} {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("userInfo", user) })
+	r.GET("homekit", GetStatus)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/homekit", nil))
+	var resp struct {
+		Status int                    `json:"status"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, 0, resp.Status)
+	return resp.Data
+}
+
+func TestGetStatus(t *testing.T) {
+	area, err := entity.CreateArea("homekit", entity.AreaOfHome)
+	require.NoError(t, err)
+	setting := homekit.Setting{BridgeID: "AA:BB:CC:DD:EE:FF", SetupCode: "123-45-678", SetupID: "ABCD"}
+	require.NoError(t, entity.UpdateSetting(entity.HomeKitSetting, setting, area.ID))
+
+	// 设置码只返回给拥有者
+	owner := getStatus(t, &session.User{UserID: 1, IsOwner: true, AreaID: area.ID})
+	assert.Equal(t, "123-45-678", owner["setup_code"])
+	assert.NotEmpty(t, owner["setup_uri"])
+
+	member := getStatus(t, &session.User{UserID: 2, AreaID: area.ID})
+	assert.NotContains(t, member, "setup_code")
+	assert.NotContains(t, member, "setup_uri")
+	assert.Equal(t, false, member["enabled"])
+}
@@ -0,0 +1,17 @@
+// Package homekit HomeKit桥接设置
+package homekit
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/middleware"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+)
+
+// RegisterHomeKitRouter 注册与HomeKit桥接相关的路由及其处理函数
+func RegisterHomeKitRouter(r gin.IRouter) {
+	homekitGroup := r.Group("homekit", middleware.RequireAccountWithScope(types.ScopeDevice))
+	homekitGroup.GET("", GetStatus)
+	homekitGroup.PUT("", middleware.RequireOwner, UpdateSetting)
+	homekitGroup.POST("reset", middleware.RequireOwner, Reset)
+}
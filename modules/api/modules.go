@@ -7,13 +7,17 @@ import (
 	"github.com/zhiting-tech/smartassistant/modules/api/area"
 	"github.com/zhiting-tech/smartassistant/modules/api/auth"
 	"github.com/zhiting-tech/smartassistant/modules/api/brand"
+	"github.com/zhiting-tech/smartassistant/modules/api/climate"
 	"github.com/zhiting-tech/smartassistant/modules/api/cloud"
 	"github.com/zhiting-tech/smartassistant/modules/api/department"
 	"github.com/zhiting-tech/smartassistant/modules/api/device"
+	"github.com/zhiting-tech/smartassistant/modules/api/energy"
 	"github.com/zhiting-tech/smartassistant/modules/api/extension"
 	"github.com/zhiting-tech/smartassistant/modules/api/file"
+	"github.com/zhiting-tech/smartassistant/modules/api/homekit"
 	"github.com/zhiting-tech/smartassistant/modules/api/location"
 	"github.com/zhiting-tech/smartassistant/modules/api/log"
+	"github.com/zhiting-tech/smartassistant/modules/api/mqtt"
 	"github.com/zhiting-tech/smartassistant/modules/api/plugin"
 	"github.com/zhiting-tech/smartassistant/modules/api/resource"
 	"github.com/zhiting-tech/smartassistant/modules/api/role"
@@ -47,4 +51,8 @@ func loadModules(r gin.IRouter) {
 	file.RegisterFileRouter(r)
 	app.RegisterAppRouter(r)
 	resource.RegisterResourceRouter(r)
+	energy.RegisterEnergyRouter(r)
+	climate.RegisterClimateRouter(r)
+	homekit.RegisterHomeKitRouter(r)
+	mqtt.RegisterMQTTRouter(r)
 }
@@ -0,0 +1,69 @@
+package mqtt
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/oauth"
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/mqtt"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// CreateTokenReq 生成集成token接口请求参数
+type CreateTokenReq struct {
+	UserID int `json:"user_id"` // token所属的用户，控制设备时使用该用户的权限
+}
+
+// CreateTokenResp 生成集成token接口返回数据
+type CreateTokenResp struct {
+	Token string `json:"token"`
+}
+
+// GetStatus 用于处理获取MQTT桥接状态接口的请求
+func GetStatus(c *gin.Context) {
+	var (
+		err error
+		st  mqtt.Status
+	)
+	defer func() {
+		response.HandleResponse(c, err, st)
+	}()
+
+	st = mqtt.GetStatus()
+	// 不是当前家庭的桥接时不返回连接状态
+	if st.AreaID != session.Get(c).AreaID {
+		st.Connected = false
+		st.AreaID = 0
+	}
+}
+
+// CreateToken 用于处理生成集成token接口的请求，仅拥有者可以生成
+func CreateToken(c *gin.Context) {
+	var (
+		err  error
+		req  CreateTokenReq
+		resp CreateTokenResp
+	)
+	defer func() {
+		response.HandleResponse(c, err, resp)
+	}()
+
+	if err = c.BindJSON(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if req.UserID == 0 {
+		err = errors.Newf(status.MQTTParamErr, "user_id")
+		return
+	}
+	areaID := session.Get(c).AreaID
+	user, err := entity.GetUserByID(req.UserID)
+	if err != nil || user.AreaID != areaID {
+		err = errors.New(status.UserNotExist)
+		return
+	}
+	resp.Token, err = oauth.GetIntegrationToken(user.ID, c.Request, areaID)
+}
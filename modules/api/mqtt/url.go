@@ -0,0 +1,16 @@
+// Package mqtt MQTT桥接状态及集成token
+package mqtt
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/middleware"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+)
+
+// RegisterMQTTRouter 注册与MQTT桥接相关的路由及其处理函数
+func RegisterMQTTRouter(r gin.IRouter) {
+	mqttGroup := r.Group("mqtt", middleware.RequireAccountWithScope(types.ScopeDevice))
+	mqttGroup.GET("", GetStatus)
+	mqttGroup.POST("token", middleware.RequireOwner, CreateToken)
+}
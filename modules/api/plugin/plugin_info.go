@@ -24,6 +24,14 @@ type PluginInfo struct {
 	IsAdded     bool   `json:"is_added"`
 	IsNewest    bool   `json:"is_newest"`
 	DownloadURL string `json:"download_url"` // 前端插件压缩包？
+	Publisher   string `json:"publisher"`    // 开发插件包签名的发布者
+	Unsigned    bool   `json:"unsigned"`     // 未签名的开发插件
+
+	Health    *plugin.Health    `json:"health,omitempty"`    // 已添加插件的健康状态
+	Resources *plugin.Resources `json:"resources,omitempty"` // 已添加插件实际生效的资源限制
+
+	PreviousVersion string                 `json:"previous_version,omitempty"` // 更新前的版本
+	UpgradeHistory  []plugin.UpgradeRecord `json:"upgrade_history,omitempty"`  // 更新记录，最近的在前
 }
 
 // PluginInfoReq 插件详情接口请求参数
@@ -76,6 +84,18 @@ func GetPluginInfo(c *gin.Context) {
 		Brand:   plg.Brand,
 		IsAdded: plg.IsAdded(), IsNewest: plg.IsNewest()}
 	resp.Plugin.DownloadURL = plugin.ArchiveURL(plg.ID, c.Request)
+	resp.Plugin.Publisher = plg.Publisher
+	resp.Plugin.Unsigned = plg.IsUnsigned()
+	if resp.Plugin.IsAdded {
+		health := plugin.GetSupervisor().Health(plg.ID)
+		resp.Plugin.Health = &health
+		resources := plugin.GetResources(plg).Effective
+		resp.Plugin.Resources = &resources
+		if pi, err := entity.GetPlugin(plg.ID, session.Get(c).AreaID); err == nil {
+			resp.Plugin.PreviousVersion = pi.PreviousVersion
+			resp.Plugin.UpgradeHistory = plugin.GetUpgradeHistory(pi)
+		}
+	}
 }
 
 func getPlugin(pluginID string, areaID uint64) (plg plugin.Plugin, err error) {
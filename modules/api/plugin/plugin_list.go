@@ -6,6 +6,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/modules/api/brand"
 	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
 	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
 	"github.com/zhiting-tech/smartassistant/modules/utils/session"
 )
 
@@ -24,7 +25,12 @@ type Resp struct {
 
 type Plugin struct {
 	brand.Plugin
-	BuildStatus int `json:"build_status"` // build状态，-1 build失败,0正在build,1 build成功
+	BuildStatus int           `json:"build_status"` // build状态，-1 build失败,0正在build,1 build成功
+	Health      plugin.Health `json:"health"`       // 插件运行的健康状态
+	Publisher   string        `json:"publisher"`    // 开发插件包签名的发布者
+	Unsigned    bool          `json:"unsigned"`     // 未签名的开发插件
+
+	Compatibility *plugin.Compatibility `json:"compatibility,omitempty"` // 插件与SA的兼容情况，插件未运行过时为空
 }
 
 func ListPlugin(c *gin.Context) {
@@ -72,6 +78,12 @@ func ListPlugin(c *gin.Context) {
 				IsAdded: true,
 			},
 			BuildStatus: plg.Status,
+			Health:      plugin.GetSupervisor().Health(plg.PluginID),
+			Publisher:   plg.Publisher,
+			Unsigned:    plugin.NewFromEntity(plg).IsUnsigned(),
+		}
+		if compatibility, ok := plugin.GetCompatibility(plg.PluginID); ok {
+			p.Compatibility = &compatibility
 		}
 		resp.Plugins = append(resp.Plugins, p)
 	}
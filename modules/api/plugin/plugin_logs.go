@@ -0,0 +1,159 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+const (
+	defaultLogDownloadSize = 10  // MB
+	maxLogDownloadSize     = 100 // MB
+)
+
+// PluginLogsReq 获取插件日志接口请求参数
+type PluginLogsReq struct {
+	PluginID string `uri:"id"`
+	Since    int64  `form:"since"`  // 时间戳（秒），只返回该时间之后的日志
+	Tail     int    `form:"tail"`   // 最后的N行，默认200
+	Follow   bool   `form:"follow"` // 为true时以ndjson格式持续返回新的日志
+	Level    string `form:"level"`  // 最低日志级别：trace、debug、info、warn、error、fatal、panic
+}
+
+func (req *PluginLogsReq) bind(c *gin.Context) (err error) {
+	if err = c.BindUri(req); err != nil {
+		return errors.New(errors.BadRequest)
+	}
+	if err = c.BindQuery(req); err != nil {
+		return errors.Wrap(err, errors.BadRequest)
+	}
+	req.Tail = plugin.LimitLogTail(req.Tail)
+	if req.Level != "" && !plugin.ValidLogLevel(req.Level) {
+		return errors.Newf(status.PluginLogLevelIncorrect, req.Level)
+	}
+	return nil
+}
+
+func (req PluginLogsReq) options() plugin.LogOptions {
+	opts := plugin.LogOptions{
+		Tail:   req.Tail,
+		Follow: req.Follow,
+		Level:  req.Level,
+	}
+	if req.Since != 0 {
+		opts.Since = time.Unix(req.Since, 0)
+	}
+	return opts
+}
+
+// PluginLogsResp 获取插件日志接口返回数据
+type PluginLogsResp struct {
+	Lines []plugin.LogLine `json:"lines"`
+}
+
+// GetPluginLogs 用于处理获取插件日志接口的请求
+func GetPluginLogs(c *gin.Context) {
+	var (
+		err  error
+		req  PluginLogsReq
+		resp PluginLogsResp
+	)
+	defer func() {
+		if err != nil || !req.Follow {
+			response.HandleResponse(c, err, &resp)
+		}
+	}()
+
+	if err = req.bind(c); err != nil {
+		return
+	}
+	plg, err := getAddedPlugin(req.PluginID, session.Get(c).AreaID)
+	if err != nil {
+		return
+	}
+	lines, err := plugin.Logs(c.Request.Context(), plg, req.options())
+	if err != nil {
+		err = errors.Wrap(err, status.PluginLogsUnavailable)
+		return
+	}
+
+	if req.Follow {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Stream(func(w io.Writer) bool {
+			l, ok := <-lines
+			if !ok {
+				return false
+			}
+			data, _ := json.Marshal(l)
+			_, _ = w.Write(append(data, '\n'))
+			return true
+		})
+		return
+	}
+	resp.Lines = make([]plugin.LogLine, 0)
+	for l := range lines {
+		resp.Lines = append(resp.Lines, l)
+	}
+}
+
+// DownloadPluginLogsReq 下载插件日志接口请求参数
+type DownloadPluginLogsReq struct {
+	PluginID string `uri:"id"`
+	Size     int    `form:"size"` // 最后N MB的日志，默认10
+}
+
+// DownloadPluginLogs 用于处理下载插件日志接口的请求，仅拥有者可以下载
+func DownloadPluginLogs(c *gin.Context) {
+	var (
+		err error
+		req DownloadPluginLogsReq
+	)
+	defer func() {
+		if err != nil {
+			response.HandleResponse(c, err, nil)
+		}
+	}()
+
+	if err = c.BindUri(&req); err != nil {
+		err = errors.New(errors.BadRequest)
+		return
+	}
+	if err = c.BindQuery(&req); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	if req.Size <= 0 {
+		req.Size = defaultLogDownloadSize
+	}
+	if req.Size > maxLogDownloadSize {
+		req.Size = maxLogDownloadSize
+	}
+
+	u := session.Get(c)
+	if !u.IsOwner {
+		err = errors.New(status.Deny)
+		return
+	}
+	plg, err := getAddedPlugin(req.PluginID, u.AreaID)
+	if err != nil {
+		return
+	}
+	data, err := plugin.LastLogs(c.Request.Context(), plg, req.Size<<20)
+	if err != nil {
+		err = errors.Wrap(err, status.PluginLogsUnavailable)
+		return
+	}
+	filename := fmt.Sprintf("%s-%s.log", plg.ID, time.Now().Format("20060102150405"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", data)
+}
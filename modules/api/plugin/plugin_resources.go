@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+type pluginResourcesReq struct {
+	PluginID string `uri:"id"`
+}
+
+// UpdatePluginResourcesReq 修改插件资源限制接口请求参数，值为0时使用插件配置或默认值
+type UpdatePluginResourcesReq struct {
+	Resources plugin.Resources `json:"resources"`
+}
+
+// GetPluginResources 用于处理获取插件资源限制接口的请求
+func GetPluginResources(c *gin.Context) {
+	var (
+		err  error
+		req  pluginResourcesReq
+		resp plugin.PluginResources
+	)
+	defer func() {
+		response.HandleResponse(c, err, &resp)
+	}()
+
+	if err = c.BindUri(&req); err != nil {
+		err = errors.New(errors.BadRequest)
+		return
+	}
+
+	plg, err := getAddedPlugin(req.PluginID, session.Get(c).AreaID)
+	if err != nil {
+		return
+	}
+	resp = plugin.GetResources(plg)
+}
+
+// UpdatePluginResources 用于处理修改插件资源限制接口的请求，仅拥有者可以修改
+func UpdatePluginResources(c *gin.Context) {
+	var (
+		err  error
+		req  pluginResourcesReq
+		body UpdatePluginResourcesReq
+	)
+	defer func() {
+		response.HandleResponse(c, err, nil)
+	}()
+
+	if err = c.BindUri(&req); err != nil {
+		err = errors.New(errors.BadRequest)
+		return
+	}
+	if err = c.BindJSON(&body); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+
+	u := session.Get(c)
+	if !u.IsOwner {
+		err = errors.New(status.Deny)
+		return
+	}
+	plg, err := getAddedPlugin(req.PluginID, u.AreaID)
+	if err != nil {
+		return
+	}
+	if err = body.Resources.Validate(); err != nil {
+		err = errors.Newf(status.PluginResourcesIncorrect, err.Error())
+		return
+	}
+	effective := plugin.DefaultResources.Merge(plugin.GetResources(plg).Declared).Merge(body.Resources)
+	if err = effective.Validate(); err != nil {
+		err = errors.Newf(status.PluginResourcesIncorrect, err.Error())
+		return
+	}
+	if err = plugin.UpdateResourceOverride(plg, body.Resources); err != nil {
+		err = errors.Wrap(err, status.PluginUpFail)
+		return
+	}
+}
+
+// getAddedPlugin 获取已添加的插件
+func getAddedPlugin(pluginID string, areaID uint64) (plg plugin.Plugin, err error) {
+	if !entity.IsPluginAdd(pluginID, areaID) {
+		err = errors.New(status.PluginDomainNotExist)
+		return
+	}
+	return getPlugin(pluginID, areaID)
+}
@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+type pluginSettingsReq struct {
+	PluginID string `uri:"id"`
+}
+
+// PluginSettingsResp 插件设置接口返回数据
+type PluginSettingsResp struct {
+	Schema   *plugin.SettingsSchema `json:"schema"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// UpdatePluginSettingsReq 修改插件设置接口请求参数
+type UpdatePluginSettingsReq struct {
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// GetPluginSettings 用于处理获取插件设置接口的请求，设置中可能包含账号、密钥，仅拥有者可以查看
+func GetPluginSettings(c *gin.Context) {
+	var (
+		err  error
+		req  pluginSettingsReq
+		resp PluginSettingsResp
+	)
+	defer func() {
+		response.HandleResponse(c, err, &resp)
+	}()
+
+	if err = c.BindUri(&req); err != nil {
+		err = errors.New(errors.BadRequest)
+		return
+	}
+
+	u := session.Get(c)
+	if !u.IsOwner {
+		err = errors.New(status.Deny)
+		return
+	}
+	if resp.Schema, err = getSettingsSchema(u.AreaID, req.PluginID); err != nil {
+		return
+	}
+	if resp.Settings, err = plugin.GetSettings(u.AreaID, req.PluginID, resp.Schema); err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+}
+
+// UpdatePluginSettings 用于处理修改插件设置接口的请求，仅拥有者可以修改
+func UpdatePluginSettings(c *gin.Context) {
+	var (
+		err    error
+		req    pluginSettingsReq
+		body   UpdatePluginSettingsReq
+		schema *plugin.SettingsSchema
+	)
+	defer func() {
+		response.HandleResponse(c, err, nil)
+	}()
+
+	if err = c.BindUri(&req); err != nil {
+		err = errors.New(errors.BadRequest)
+		return
+	}
+	if err = c.BindJSON(&body); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+
+	u := session.Get(c)
+	if !u.IsOwner {
+		err = errors.New(status.Deny)
+		return
+	}
+	if schema, err = getSettingsSchema(u.AreaID, req.PluginID); err != nil {
+		return
+	}
+	if err = schema.Validate(body.Settings); err != nil {
+		err = errors.Newf(status.PluginSettingsIncorrect, err.Error())
+		return
+	}
+	if err = plugin.UpdateSettings(c.Request.Context(), u.AreaID, req.PluginID, body.Settings); err != nil {
+		// 设置已保存，插件未运行时在下次连接时下发
+		logger.Warningf("update plugin %s settings err: %s", req.PluginID, err)
+		err = nil
+	}
+}
+
+// getSettingsSchema 获取插件的设置定义，插件未安装或没有定义设置时返回错误
+func getSettingsSchema(areaID uint64, pluginID string) (schema *plugin.SettingsSchema, err error) {
+	if !entity.IsPluginAdd(pluginID, areaID) {
+		err = errors.New(status.PluginDomainNotExist)
+		return
+	}
+	if schema, err = plugin.GetSettingsSchema(areaID, pluginID); err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	if schema == nil {
+		err = errors.New(status.PluginSettingsNotSupport)
+	}
+	return
+}
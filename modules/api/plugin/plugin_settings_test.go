@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+type testResponse struct {
+	Status int             `json:"status"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// serve 以指定用户的身份请求插件设置接口
+func serve(t *testing.T, user *session.User, method, pluginID string, body interface{}) testResponse {
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("userInfo", user) })
+	r.GET("plugins/:id/settings", GetPluginSettings)
+	r.PUT("plugins/:id/settings", UpdatePluginSettings)
+
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, "/plugins/"+pluginID+"/settings", bytes.NewReader(data)))
+	var resp testResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestPluginSettings(t *testing.T) {
+	area, err := entity.CreateArea("settings", entity.AreaOfHome)
+	require.NoError(t, err)
+	conf, _ := json.Marshal(map[string]interface{}{
+		"settings": json.RawMessage(`{"type": "object", "properties": {"api_key": {"type": "string", "default": ""}}}`),
+	})
+	pi := entity.PluginInfo{AreaID: area.ID, PluginID: "cloud", Status: entity.StatusInstallSuccess, ConfigMsg: conf}
+	require.NoError(t, entity.GetDB().Create(&pi).Error)
+
+	owner := &session.User{UserID: 1, IsOwner: true, AreaID: area.ID}
+	member := &session.User{UserID: 2, AreaID: area.ID}
+
+	// 设置中的密钥只有拥有者可以查看和修改
+	assert.Equal(t, status.Deny, serve(t, member, http.MethodGet, "cloud", nil).Status)
+	body := UpdatePluginSettingsReq{Settings: map[string]interface{}{"api_key": "secret"}}
+	assert.Equal(t, status.Deny, serve(t, member, http.MethodPut, "cloud", body).Status)
+
+	resp := serve(t, owner, http.MethodGet, "cloud", nil)
+	require.Equal(t, 0, resp.Status)
+	var settings PluginSettingsResp
+	require.NoError(t, json.Unmarshal(resp.Data, &settings))
+	assert.Equal(t, map[string]interface{}{"api_key": ""}, settings.Settings)
+
+	assert.Equal(t, 0, serve(t, owner, http.MethodPut, "cloud", body).Status)
+	resp = serve(t, owner, http.MethodGet, "cloud", nil)
+	require.NoError(t, json.Unmarshal(resp.Data, &settings))
+	assert.Equal(t, body.Settings, settings.Settings)
+
+	invalid := UpdatePluginSettingsReq{Settings: map[string]interface{}{"api_key": 1}}
+	assert.Equal(t, status.PluginSettingsIncorrect, serve(t, owner, http.MethodPut, "cloud", invalid).Status)
+	assert.Equal(t, status.PluginDomainNotExist, serve(t, owner, http.MethodGet, "missing", nil).Status)
+}
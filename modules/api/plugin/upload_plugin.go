@@ -23,6 +23,7 @@ type UploadPluginReq struct {
 
 type UploadPluginResp struct {
 	PluginInfo plugin.Plugin `json:"plugin_info"`
+	Unsigned   bool          `json:"unsigned"` // 插件包未签名，仅能在开发模式下运行
 }
 
 func UploadPlugin(c *gin.Context) {
@@ -98,6 +99,7 @@ func (req *UploadPluginReq) uploadPlugin(c *gin.Context) (resp UploadPluginResp,
 		return
 	}
 	resp.PluginInfo = plg
+	resp.Unsigned = plg.IsUnsigned()
 	return
 }
 
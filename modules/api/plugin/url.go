@@ -3,6 +3,7 @@ package plugin
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/zhiting-tech/smartassistant/modules/api/middleware"
+	"github.com/zhiting-tech/smartassistant/modules/types"
 )
 
 // RegisterPluginRouter 插件
@@ -14,4 +15,10 @@ func RegisterPluginRouter(r gin.IRouter) {
 	pluginAuthGroup.GET("", ListPlugin)
 	pluginAuthGroup.POST("", UploadPlugin)
 	pluginAuthGroup.DELETE(":id", DelPlugin)
+	pluginAuthGroup.GET(":id/settings", GetPluginSettings)
+	pluginAuthGroup.PUT(":id/settings", UpdatePluginSettings)
+	pluginAuthGroup.GET(":id/resources", GetPluginResources)
+	pluginAuthGroup.PUT(":id/resources", UpdatePluginResources)
+	pluginAuthGroup.GET(":id/logs", middleware.RequirePermission(types.PluginLogGet), GetPluginLogs)
+	pluginAuthGroup.GET(":id/logs/download", DownloadPluginLogs)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/zhiting-tech/smartassistant/modules/api/extension"
 	"github.com/zhiting-tech/smartassistant/modules/api/utils/response"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
 	"github.com/zhiting-tech/smartassistant/modules/plugin/docker"
 	"github.com/zhiting-tech/smartassistant/pkg/errors"
 	"io"
@@ -100,6 +101,12 @@ func ListResource(c *gin.Context) {
 				return
 			}
 
+			service.OOMKilled = conJson.State.OOMKilled
+			service.RestartCount = conJson.RestartCount
+			if conJson.HostConfig != nil && conJson.HostConfig.Memory > 0 {
+				service.MemLimit = FormatFileSize(uint64(conJson.HostConfig.Memory))
+			}
+
 			startTime := conJson.State.StartedAt
 			timeStr := startTime[:10] + " " + startTime[11:19]
 			if timeStr == "0001-01-01 00:00:00" {
@@ -141,6 +148,12 @@ func ListResource(c *gin.Context) {
 				service.Type = 2
 				service.Name = container.Labels["com.zhiting.smartassistant.resource.service_name"]
 				service.ServiceName = container.Labels["com.zhiting.smartassistant.resource.service_name"]
+				health := plugin.GetSupervisor().Health(service.Name)
+				service.OOMKills = health.OOMKills
+				service.LastError = health.LastError
+				if health.LastError == plugin.ReasonOOMKilled {
+					service.OOMKilled = true
+				}
 				pluginPerCpuUsage += res.GetPerUsedCpu()
 				pluginMemUsage += res.GetUsedMem()
 				memUsage += res.GetUsedMem()
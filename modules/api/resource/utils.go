@@ -98,6 +98,12 @@ type Service struct {
 	PerCpuUsage float64 `json:"percpu_usage"` // cpu使用率
 	MemUsage    string  `json:"mem_usage"`    // 已使用内存
 	Type        uint8   `json:"type"`         // 容器服务类型,基础服务 1，插件类型 2，拓展服务 3
+
+	MemLimit     string `json:"mem_limit,omitempty"`  // 内存限制，未限制时为空
+	OOMKilled    bool   `json:"oom_killed"`           // 最近一次退出是否因超过内存限制被kill
+	OOMKills     int    `json:"oom_kills"`            // 插件超过内存限制被kill的次数
+	RestartCount int    `json:"restart_count"`        // 容器崩溃重启的次数
+	LastError    string `json:"last_error,omitempty"` // 插件最近一次崩溃或无响应的原因
 }
 
 type Services []Service
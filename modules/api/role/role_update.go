@@ -35,6 +35,7 @@ type Permissions struct {
 	Scene          []Permission   `json:"scene"`           // 场景权限设置
 	Company        []Permission   `json:"company"`         // 公司权限设置
 	Department     []Permission   `json:"department"`      // 部门权限设置
+	Plugin         []Permission   `json:"plugin"`          // 插件权限设置
 }
 
 // DeviceAdvanced 设备高级权限信息
@@ -126,6 +127,7 @@ func roleUpdate(c *gin.Context) {
 	updatePermission(r, req.Permissions.Scene)
 	updatePermission(r, req.Permissions.Company)
 	updatePermission(r, req.Permissions.Department)
+	updatePermission(r, req.Permissions.Plugin)
 }
 
 func updatePermission(role entity.Role, ps []Permission) {
@@ -59,6 +59,7 @@ func wrapRolePermissions(role entity.Role, c *gin.Context) (ps Permissions, err
 
 	wrapPermissions(role, ps.Role)
 	wrapPermissions(role, ps.Scene)
+	wrapPermissions(role, ps.Plugin)
 	return
 }
 
@@ -92,6 +93,7 @@ func getPermissionsWithDevices(c *gin.Context) (Permissions, error) {
 		DeviceAdvanced: DeviceAdvanced{Locations: locations, Departments: departments},
 		Role:           wrapPs(types.RolePermission),
 		Scene:          wrapPs(types.ScenePermission),
+		Plugin:         wrapPs(types.PluginPermission),
 	}
 
 	if entity.IsHome(curArea.AreaType){
@@ -116,6 +118,7 @@ func getPermissions() (Permissions, error) {
 		Scene:    	 wrapPs(types.ScenePermission),
 		Company:  	 wrapPs(types.CompanyPermission),
 		Department:  wrapPs(types.DepartmentPermission),
+		Plugin:      wrapPs(types.PluginPermission),
 	}, nil
 }
 
@@ -116,6 +116,7 @@ func UserPermissions(c *gin.Context) {
 	}
 	resp.wrap(ps.Role, up)
 	resp.wrap(ps.Scene, up)
+	resp.wrap(ps.Plugin, up)
 	resp.checkSAUpgragePermission(up)
 
 }
@@ -44,6 +44,10 @@ func CreateScene(c *gin.Context) {
 		return
 	}
 
+	// 先记录单位，校验时才能按用户的单位换算属性值
+	if err = req.stampUnit(session.Get(c).UserID); err != nil {
+		return
+	}
 	if err = req.check(c); err != nil {
 		return
 	}
@@ -136,6 +140,26 @@ func (req *CreateSceneReq) validate(c *gin.Context) (err error) {
 	return
 }
 
+// stampUnit 记录条件及任务中属性值的单位，用户按自己的单位制设置属性值
+func (req *CreateSceneReq) stampUnit(userID int) (err error) {
+	user, err := entity.GetUserByID(userID)
+	if err != nil {
+		return
+	}
+	system := user.GetUnitSystem()
+	for i := range req.SceneConditions {
+		if err = req.SceneConditions[i].StampUnit(system); err != nil {
+			return
+		}
+	}
+	for i := range req.SceneTasks {
+		if err = req.SceneTasks[i].StampUnit(system); err != nil {
+			return
+		}
+	}
+	return
+}
+
 // isRequireNotify 是否需要通知权限
 func (req *CreateSceneReq) isRequireNotify() bool {
 	if !req.IsMatchAllCondition() {
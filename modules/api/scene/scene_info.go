@@ -133,7 +133,8 @@ func WrapConditionInfo(c *gin.Context, condition entity.SceneCondition) (conditi
 		deviceInfo DeviceInfo
 	)
 
-	if condition.ConditionType == entity.ConditionTypeDeviceStatus {
+	if condition.ConditionType == entity.ConditionTypeDeviceStatus ||
+		condition.ConditionType == entity.ConditionTypeDeviceEvent {
 		if deviceInfo, err = WrapDeviceInfo(condition.DeviceID, c.Request, c); err != nil {
 			return
 		}
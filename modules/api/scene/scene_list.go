@@ -200,7 +200,7 @@ func WrapCondition(ctx *gin.Context, sceneID int) (sceneCondition sceneCondition
 	for i, c := range conditions {
 		// 只返回第一个触发条件的信息
 		sceneCondition.Type = conditions[0].ConditionType
-		if c.ConditionType == entity.ConditionTypeDeviceStatus {
+		if c.ConditionType == entity.ConditionTypeDeviceStatus || c.ConditionType == entity.ConditionTypeDeviceEvent {
 			// 第一个触发条件为设备时，包装对应信息
 			if i == 0 {
 				item := Item{ID: c.DeviceID}
@@ -1,95 +1,192 @@
 package scene
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
-	"github.com/zhiting-tech/smartassistant/modules/api/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/oauth"
+	"github.com/zhiting-tech/smartassistant/modules/config"
 	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types"
 	"github.com/zhiting-tech/smartassistant/modules/types/status"
-	"testing"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
-func TestScene(t *testing.T) {
-	cases := []test.ApiTestCase{
-		// 获取场景
-		{
-			Method:  "GET",
-			Path:    "/scenes",
-			Status:  0,
-			IsArray: []string{"data.manual"},
-		},
-		// 获取场景详情
-		{
-			Method: "GET",
-			Path:   "/scenes/1",
-			Status: 0,
-			IsID:   []string{"data.id"},
-		},
-		// 不属于用户的场景详情
-		{
-			Method: "GET",
-			Path:   "/scenes/2",
-			Status: status.Deny,
-		},
-		// 场景的执行
-		{
-			Method: "POST",
-			Path:   "/scenes/1/execute",
-			Body:   "{\"is_execute\": true}",
-			Status: 0,
-		},
-		// 不属于用户的场景的执行
-		{
-			Method: "POST",
-			Path:   "/scenes/2/execute",
-			Body:   "{\"is_execute\": true}",
-			Status: status.Deny,
-		},
-		// 创建场景
-		{
-			Method: "POST",
-			Path:   "/scenes",
-			Body:   "{  \"name\": \"abc\",  \"auto_run\": false,  \"time_period\": 1,  \"repeat_date\": \"1\",  \"scene_tasks\": [{ \"type\": 2, \"delay_seconds\": 2, \"control_scene_id\": 1} ]}",
-			Status: 0,
-		},
-		// 修改场景
-		{
-			Method: "PUT",
-			Path:   "/scenes/1",
-			Body:   "{  \"id\": 1, \"name\": \"demo_changed\", \"time_period\": 1,  \"repeat_date\": \"1\",  \"scene_tasks\": \n[\n    { \n        \"id\": 1,\n        \"scene_id\": 1,\n        \"type\": 1, \n        \"delay_seconds\": 9, \n        \"control_scene_id\": 2,\n        \"type\": 2\n    } \n]\n}",
-			Status: 0,
-		},
-		// 删除场景
-		{
-			Method: "DELETE",
-			Path:   "/scenes/1",
-			Status: 0,
-		},
-		// 删除不属于用户的场景
-		{
-			Method: "DELETE",
-			Path:   "/scenes/2",
-			Status: status.Deny,
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	gin.SetMode(gin.TestMode)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+// sceneFixture 家庭拥有者及其家庭中的场景，另一个家庭中的场景用户无权操作
+type sceneFixture struct {
+	router     *gin.Engine
+	owner      entity.User
+	token      string
+	scene      entity.Scene
+	otherScene entity.Scene
+}
+
+func newSceneFixture(t *testing.T, system thingmodel.UnitSystem) (f sceneFixture) {
+	area, err := entity.CreateArea("scene", entity.AreaOfHome)
+	require.NoError(t, err)
+	require.NoError(t, entity.InitClient(area.ID))
+	f.owner = entity.User{AreaID: area.ID, UnitSystem: system}
+	require.NoError(t, entity.CreateUser(&f.owner, entity.GetDB()))
+	require.NoError(t, entity.SetAreaOwnerID(area.ID, f.owner.ID, entity.GetDB()))
+	f.token, err = oauth.GetIntegrationToken(f.owner.ID, httptest.NewRequest(http.MethodPost, "/scenes", nil), area.ID)
+	require.NoError(t, err)
+
+	other, err := entity.CreateArea("scene", entity.AreaOfHome)
+	require.NoError(t, err)
+	f.scene = entity.Scene{Name: "demo", AreaID: area.ID}
+	require.NoError(t, entity.GetDB().Create(&f.scene).Error)
+	f.otherScene = entity.Scene{Name: "demo_delete", AreaID: other.ID}
+	require.NoError(t, entity.GetDB().Create(&f.otherScene).Error)
+
+	f.router = gin.New()
+	InitSceneRouter(f.router)
+	return
+}
+
+// request 以家庭拥有者身份请求场景接口，返回响应的状态码及数据
+func (f sceneFixture) request(t *testing.T, method, path, body string) (int, json.RawMessage) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(types.SATokenKey, f.token)
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Status int             `json:"status"`
+		Reason string          `json:"reason"`
+		Data   json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return resp.Status, resp.Data
+}
+
+// createThermostat 创建温控器，目标温度的单位为摄氏度，范围为5-35
+func createThermostat(t *testing.T, areaID uint64) entity.Device {
+	target := thingmodel.TargetTemperature
+	target.AID = 1
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID: "thermostat",
+		Services: []thingmodel.Service{
+			{Type: thingmodel.Thermostat, Attributes: []thingmodel.Attribute{target}},
 		},
+	}}}
+	d := entity.Device{Name: "thermostat", PluginID: "demo", IID: "thermostat", AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+func TestScene(t *testing.T) {
+	f := newSceneFixture(t, thingmodel.UnitSystemMetric)
+	scenePath := fmt.Sprintf("/scenes/%d", f.scene.ID)
+	otherPath := fmt.Sprintf("/scenes/%d", f.otherScene.ID)
+
+	// 获取场景
+	code, data := f.request(t, http.MethodGet, "/scenes", "")
+	assert.Equal(t, 0, code)
+	var list struct {
+		Manual []json.RawMessage `json:"manual"`
 	}
+	require.NoError(t, json.Unmarshal(data, &list))
+	assert.NotNil(t, list.Manual)
+
+	// 获取场景详情
+	code, data = f.request(t, http.MethodGet, scenePath, "")
+	assert.Equal(t, 0, code)
+	var info struct {
+		ID int `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(data, &info))
+	assert.Equal(t, f.scene.ID, info.ID)
+
+	// 不属于用户的场景详情
+	code, _ = f.request(t, http.MethodGet, otherPath, "")
+	assert.Equal(t, status.Deny, code)
+
+	// 场景的执行
+	code, _ = f.request(t, http.MethodPost, scenePath+"/execute", `{"is_execute": true}`)
+	assert.Equal(t, 0, code)
+
+	// 不属于用户的场景的执行
+	code, _ = f.request(t, http.MethodPost, otherPath+"/execute", `{"is_execute": true}`)
+	assert.Equal(t, status.Deny, code)
+
+	// 创建场景
+	body := fmt.Sprintf(`{"name": "abc", "auto_run": false, "scene_tasks": [{"type": 2, "delay_seconds": 2, "control_scene_id": %d}]}`, f.scene.ID)
+	code, _ = f.request(t, http.MethodPost, "/scenes", body)
+	assert.Equal(t, 0, code)
+	created := sceneID(t, "abc", f.owner.AreaID)
 
-	// 创建测试场景
-	test.CreateRecord(&entity.Scene{Name: "demo", AreaID: 1})
-	test.CreateRecord(&entity.Scene{Name: "demo_delete", AreaID: 2})
-	test.CreateRecord(&entity.Device{Name: "demo", Model: "smart_assistant", OwnerID: 1})
-
-	test.RunApiTest(t, InitSceneRouter, cases, test.WithRoles("管理员"), test.WithAreas(1))
-
-	var s1, s2 entity.Scene
-	// 验证场景创建
-	db := entity.GetDB().Where("name=?", "abc").Find(&s1)
-	assert.Empty(t, db.Error)
-	assert.NotEmpty(t, s1.ID, "abc")
-	// 验证场景修改
-	db = entity.GetDB().Where("name=?", "demo").Find(&s2)
-	assert.Empty(t, db.Error)
-	assert.Empty(t, s2.ID, "demo")
+	// 修改场景
+	body = fmt.Sprintf(`{"id": %d, "name": "demo_changed", "auto_run": false, "scene_tasks": [{"type": 2, "delay_seconds": 9, "control_scene_id": %d}]}`, f.scene.ID, created)
+	code, _ = f.request(t, http.MethodPut, scenePath, body)
+	assert.Equal(t, 0, code)
+	scene, err := entity.GetSceneById(f.scene.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "demo_changed", scene.Name)
+
+	// 删除场景
+	code, _ = f.request(t, http.MethodDelete, scenePath, "")
+	assert.Equal(t, 0, code)
+
+	// 删除不属于用户的场景
+	code, _ = f.request(t, http.MethodDelete, otherPath, "")
+	assert.Equal(t, status.Deny, code)
 }
 
-func TestMain(m *testing.M) {
-	test.InitApiTest(m)
+func TestSceneTaskUnit(t *testing.T) {
+	f := newSceneFixture(t, thingmodel.UnitSystemImperial)
+	thermostat := createThermostat(t, f.owner.AreaID)
+
+	// 英制用户设置77°F，按摄氏度校验时超出最大值35，需先记录单位再校验
+	task := func(val float64) string {
+		return fmt.Sprintf(`[{"type": 1, "device_id": %d, "attributes": [{"iid": "thermostat", "aid": 1, "val": %v}]}]`, thermostat.ID, val)
+	}
+	code, _ := f.request(t, http.MethodPost, "/scenes", `{"name": "heat", "auto_run": false, "scene_tasks": `+task(77)+`}`)
+	require.Equal(t, 0, code)
+	id := sceneID(t, "heat", f.owner.AreaID)
+	assert.Equal(t, thingmodel.UnitFahrenheit, taskUnit(t, id))
+
+	body := fmt.Sprintf(`{"id": %d, "name": "heat", "auto_run": false, "scene_tasks": %s}`, id, task(80))
+	code, _ = f.request(t, http.MethodPut, fmt.Sprintf("/scenes/%d", id), body)
+	require.Equal(t, 0, code)
+	assert.Equal(t, thingmodel.UnitFahrenheit, taskUnit(t, id))
+
+	// 换算后仍超出范围时拒绝
+	code, _ = f.request(t, http.MethodPost, "/scenes", `{"name": "overheat", "auto_run": false, "scene_tasks": `+task(104)+`}`)
+	assert.Equal(t, status.AttrValueInvalid, code)
+}
+
+// sceneID 按名称查找家庭中的场景
+func sceneID(t *testing.T, name string, areaID uint64) int {
+	var scene entity.Scene
+	require.NoError(t, entity.GetDB().Where("name = ? and area_id = ?", name, areaID).First(&scene).Error)
+	return scene.ID
+}
+
+// taskUnit 场景中任务记录的属性单位，任务修改后取最新的任务
+func taskUnit(t *testing.T, sceneID int) thingmodel.Unit {
+	var task entity.SceneTask
+	require.NoError(t, entity.GetDB().Where("scene_id = ?", sceneID).Last(&task).Error)
+	var attrs []entity.Attribute
+	require.NoError(t, json.Unmarshal(task.Attributes, &attrs))
+	require.Len(t, attrs, 1)
+	return attrs[0].Unit
 }
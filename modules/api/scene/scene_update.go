@@ -8,6 +8,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/modules/entity"
 	"github.com/zhiting-tech/smartassistant/modules/task"
 	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -44,6 +45,10 @@ func UpdateScene(c *gin.Context) {
 
 	}
 
+	// 先记录单位，校验时才能按用户的单位换算属性值
+	if err = req.stampUnit(session.Get(c).UserID); err != nil {
+		return
+	}
 	if err = req.validateRequest(sceneId, c); err != nil {
 		return
 	}
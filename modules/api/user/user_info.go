@@ -80,6 +80,7 @@ func WrapUserInfo(c *gin.Context, user entity.User, isOwner bool) (infoUser enti
 	infoUser.UserId = user.ID
 	infoUser.Nickname = user.Nickname
 	infoUser.IsSetPassword = user.Password != ""
+	infoUser.UnitSystem = user.GetUnitSystem()
 
 	if isOwner {
 		infoUser.RoleInfos = []entity.RoleInfo{{ID: entity.OwnerRoleID, Name: entity.Owner}}
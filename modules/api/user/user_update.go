@@ -12,6 +12,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/modules/utils/hash"
 	"github.com/zhiting-tech/smartassistant/modules/utils/session"
 	"github.com/zhiting-tech/smartassistant/pkg/rand"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 
 	"github.com/zhiting-tech/smartassistant/pkg/errors"
 )
@@ -23,6 +24,7 @@ type updateUserReq struct {
 	Password      *string `json:"password"`
 	OldPassword   *string `json:"old_password"`
 	AvatarID      *int    `json:"avatar_id"`
+	UnitSystem    *string `json:"unit_system"` // 单位制：metric、imperial
 	RoleIds       []int   `json:"role_ids"`
 	DepartmentIds []int   `json:"department_ids,omitempty"`
 }
@@ -43,8 +45,9 @@ func (req *updateUserReq) Validate(updateUid, loginId int, areaType entity.AreaT
 		}
 	}
 
-	// 自己才允许修改自己的用户名,密码和昵称
-	if req.Nickname != nil || req.AccountName != nil || req.Password != nil || req.OldPassword != nil {
+	// 自己才允许修改自己的用户名,密码,昵称和单位制
+	if req.Nickname != nil || req.AccountName != nil || req.Password != nil || req.OldPassword != nil ||
+		req.UnitSystem != nil {
 		if loginId != updateUid {
 			err = errors.New(status.Deny)
 			return
@@ -66,6 +69,15 @@ func (req *updateUserReq) Validate(updateUid, loginId int, areaType entity.AreaT
 		}
 	}
 
+	if req.UnitSystem != nil {
+		unitSystem := thingmodel.UnitSystem(*req.UnitSystem)
+		if !thingmodel.ValidUnitSystem(unitSystem) {
+			err = errors.Newf(status.UnitSystemIncorrect, *req.UnitSystem)
+			return
+		}
+		updateUser.UnitSystem = unitSystem
+	}
+
 	if req.AvatarID != nil {
 		if _, err = entity.GetFileInfo(*req.AvatarID); err != nil {
 			return
@@ -20,7 +20,7 @@ import (
 
 	"github.com/zhiting-tech/smartassistant/modules/api/extension"
 	"github.com/zhiting-tech/smartassistant/modules/entity"
-	"github.com/zhiting-tech/smartassistant/modules/plugin/docker"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
 	"github.com/zhiting-tech/smartassistant/modules/types"
 	"github.com/zhiting-tech/smartassistant/pkg/errors"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
@@ -169,14 +169,15 @@ func DelAreaWithContext(ctx context.Context, areaID uint64) (err error) {
 		return
 	}
 
+	rt := plugin.GetRuntime()
 	for _, p := range plugins {
-		if err2 := docker.GetClient().StopContainer(p.Image); err2 != nil {
+		if err2 := rt.Stop(p.Image); err2 != nil {
 			logger.Warnf("del area stop container %s", err2)
 		}
-		if err2 := docker.GetClient().RemoveContainer(p.Image); err2 != nil {
+		if err2 := rt.Remove(p.Image); err2 != nil {
 			logger.Warnf("del area remove container %s", err2)
 		}
-		if err2 := docker.GetClient().ImageRemove(p.Image); err2 != nil {
+		if err2 := rt.RemoveImage(p.Image); err2 != nil {
 			logger.Warnf("del area remove image %s", err2)
 		}
 	}
@@ -56,3 +56,23 @@ func GetSAUserToken(user entity.User, req *http.Request) (token string, err erro
 	}
 	return ti.GetAccess(), nil
 }
+
+// GetIntegrationToken 获取集成token，用于MQTT等第三方系统集成，只有设备权限，长期有效
+func GetIntegrationToken(userID int, req *http.Request, areaID uint64) (token string, err error) {
+	saClient, _ := entity.GetSAClient(areaID)
+
+	authReq := &server.AuthorizeRequest{
+		ResponseType: oauth2.Token,
+		ClientID:     saClient.ClientID,
+		Scope:        types.ScopeDevice.Scope,
+		UserID:       strconv.Itoa(userID),
+		Request:      req,
+	}
+
+	ti, err := GetOauthServer().GetAuthorizeToken(authReq)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	return ti.GetAccess(), nil
+}
@@ -0,0 +1,405 @@
+package climate
+
+import (
+	"context"
+	"encoding/json"
+	errors2 "errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/task"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// Source 温控器当前设定值的来源
+type Source string
+
+const (
+	SourceNone     Source = "none"     // 没有计划或覆盖，由用户手动控制
+	SourceSchedule Source = "schedule" // 温控计划
+	SourceBoost    Source = Source(OverrideBoost)
+	SourceAway     Source = Source(OverrideAway)
+)
+
+// State 温控器当前应使用的设定值
+type State struct {
+	DeviceID          int             `json:"device_id"`
+	Source            Source          `json:"source"`
+	ScheduleID        int             `json:"schedule_id,omitempty"`
+	OverrideID        int             `json:"override_id,omitempty"`
+	TargetTemperature *float64        `json:"target_temperature,omitempty"`
+	HVACMode          string          `json:"hvac_mode,omitempty"`
+	Unit              thingmodel.Unit `json:"unit,omitempty"`
+	NextChangeAt      int64           `json:"next_change_at,omitempty"` // 设定值下次变化的时间戳（秒），为0表示不会自动变化
+}
+
+// LocationID 返回设备所在的房间，公司为部门
+func LocationID(d entity.Device) (int, error) {
+	area, err := entity.GetAreaByID(d.AreaID)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.InternalServerErr)
+	}
+	if entity.IsCompany(area.AreaType) {
+		return d.DepartmentID, nil
+	}
+	return d.LocationID, nil
+}
+
+// GetProgram 解析温控计划的设定点
+func GetProgram(s entity.ClimateSchedule) (p Program, err error) {
+	if len(s.Setpoints) == 0 {
+		return
+	}
+	err = json.Unmarshal(s.Setpoints, &p)
+	return
+}
+
+// Resolve 计算设备在now时应使用的设定值，优先级为：设备的覆盖、房间的覆盖、设备的计划、房间的计划
+func Resolve(deviceID, locationID int, schedules []entity.ClimateSchedule,
+	overrides []entity.ClimateOverride, now time.Time) State {
+
+	st := State{DeviceID: deviceID, Source: SourceNone}
+
+	var deviceOverride, locationOverride *entity.ClimateOverride
+	for i, o := range overrides {
+		if !o.ExpiresAt.After(now) {
+			continue
+		}
+		if o.DeviceID != 0 && o.DeviceID == deviceID {
+			deviceOverride = &overrides[i]
+		} else if o.DeviceID == 0 && locationID != 0 && o.LocationID == locationID {
+			locationOverride = &overrides[i]
+		}
+	}
+	if deviceOverride == nil {
+		deviceOverride = locationOverride
+	}
+	if o := deviceOverride; o != nil {
+		st.Source = Source(o.Type)
+		st.OverrideID = o.ID
+		st.TargetTemperature = o.TargetTemperature
+		st.HVACMode = o.HVACMode
+		st.Unit = o.Unit
+		st.NextChangeAt = o.ExpiresAt.Unix()
+		return st
+	}
+
+	var deviceSchedule, locationSchedule *entity.ClimateSchedule
+	for i, s := range schedules {
+		if !s.Enabled {
+			continue
+		}
+		if s.DeviceID != 0 && s.DeviceID == deviceID {
+			deviceSchedule = &schedules[i]
+		} else if s.DeviceID == 0 && locationID != 0 && s.LocationID == locationID {
+			locationSchedule = &schedules[i]
+		}
+	}
+	if deviceSchedule == nil {
+		deviceSchedule = locationSchedule
+	}
+	if s := deviceSchedule; s != nil {
+		p, err := GetProgram(*s)
+		if err != nil {
+			logger.Errorf("climate schedule %d unmarshal err: %s", s.ID, err)
+			return st
+		}
+		sp, ok := p.Active(now)
+		if !ok {
+			return st
+		}
+		st.Source = SourceSchedule
+		st.ScheduleID = s.ID
+		st.TargetTemperature = sp.TargetTemperature
+		st.HVACMode = sp.HVACMode
+		st.Unit = s.Unit
+		if next, ok := p.Next(now); ok {
+			st.NextChangeAt = next.Unix()
+		}
+	}
+	return st
+}
+
+// thermostat 返回设备的物模型及温控器服务，设备不是温控器时返回错误
+func thermostat(d entity.Device) (tm thingmodel.ThingModel, s thingmodel.Service, err error) {
+	if tm, err = d.GetThingModel(); err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	instance, err := tm.GetInstance(d.IID)
+	if err != nil {
+		err = errors.New(status.ClimateNotThermostat)
+		return
+	}
+	var ok bool
+	if s, ok = instance.GetService(thingmodel.Thermostat); !ok {
+		err = errors.New(status.ClimateNotThermostat)
+	}
+	return
+}
+
+// IsThermostat 设备是否为温控器
+func IsThermostat(d entity.Device) bool {
+	_, _, err := thermostat(d)
+	return err == nil
+}
+
+// GetState 获取温控器当前应使用的设定值
+func GetState(d entity.Device) (st State, err error) {
+	if _, _, err = thermostat(d); err != nil {
+		return
+	}
+	return resolve(d)
+}
+
+func resolve(d entity.Device) (st State, err error) {
+	locationID, err := LocationID(d)
+	if err != nil {
+		return
+	}
+	schedules, err := entity.GetClimateSchedules(d.AreaID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	overrides, err := entity.GetClimateOverrides(d.AreaID)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	return Resolve(d.ID, locationID, schedules, overrides, time.Now()), nil
+}
+
+func taskKey(deviceID int) string {
+	return fmt.Sprintf("climate:%d", deviceID)
+}
+
+// Refresh 重新计算温控器的设定值，apply为true时立即下发，并通过任务管理在设定值下次变化时再次下发；
+// 覆盖结束且没有计划时恢复覆盖生效前的设定值
+func Refresh(d entity.Device, apply bool) (st State, err error) {
+	tm, service, err := thermostat(d)
+	if err != nil {
+		task.GetManager().DeleteTask(taskKey(d.ID))
+		return
+	}
+	if st, err = resolve(d); err != nil {
+		return
+	}
+
+	if st.NextChangeAt != 0 {
+		deviceID := d.ID
+		task.GetManager().AddTaskAt(taskKey(deviceID), func(*task.Task) error {
+			device, err := entity.GetDeviceByID(deviceID)
+			if err != nil {
+				if errors2.Is(err, gorm.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			_, err = Refresh(device, true)
+			return err
+		}, time.Unix(st.NextChangeAt, 0))
+	} else {
+		task.GetManager().DeleteTask(taskKey(d.ID))
+	}
+
+	switch st.Source {
+	case SourceBoost, SourceAway:
+		if apply {
+			saveRestore(d, service)
+			err = setAttributes(d, tm, service, st)
+		}
+	case SourceSchedule:
+		// 覆盖结束后由计划接管，不再恢复
+		if e := entity.DeleteClimateRestore(d.ID); e != nil {
+			logger.Warnf("delete climate restore of device %d err: %s", d.ID, e)
+		}
+		if apply {
+			err = setAttributes(d, tm, service, st)
+		}
+	default:
+		err = restore(d, tm, service)
+	}
+	return
+}
+
+// saveRestore 记录覆盖生效前温控器的设定值
+func saveRestore(d entity.Device, service thingmodel.Service) {
+	shadow, err := d.GetShadow()
+	if err != nil {
+		logger.Warnf("get shadow of device %d err: %s", d.ID, err)
+		return
+	}
+	r := entity.ClimateRestore{DeviceID: d.ID, AreaID: d.AreaID}
+	if attr, ok := service.GetAttribute(thingmodel.TargetTemperature.Type); ok {
+		if val, err := shadow.Get(d.IID, attr.AID); err == nil {
+			if v, ok := val.(float64); ok {
+				r.TargetTemperature = &v
+				r.Unit = attr.Unit
+			}
+		}
+	}
+	if attr, ok := service.GetAttribute(thingmodel.HVACMode.Type); ok {
+		if val, err := shadow.Get(d.IID, attr.AID); err == nil {
+			r.HVACMode, _ = val.(string)
+		}
+	}
+	if r.TargetTemperature == nil && r.HVACMode == "" {
+		return
+	}
+	if err = entity.CreateClimateRestore(r); err != nil {
+		logger.Warnf("save climate restore of device %d err: %s", d.ID, err)
+	}
+}
+
+// restore 覆盖结束且没有计划时，恢复覆盖生效前温控器的设定值
+func restore(d entity.Device, tm thingmodel.ThingModel, service thingmodel.Service) error {
+	r, err := entity.GetClimateRestore(d.ID)
+	if err != nil {
+		if errors2.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	st := State{
+		DeviceID:          d.ID,
+		Source:            SourceNone,
+		TargetTemperature: r.TargetTemperature,
+		HVACMode:          r.HVACMode,
+		Unit:              r.Unit,
+	}
+	if err = setAttributes(d, tm, service, st); err != nil {
+		return err
+	}
+	return entity.DeleteClimateRestore(d.ID)
+}
+
+// setAttributes 将设定值换算为设备的单位后下发到设备
+func setAttributes(d entity.Device, tm thingmodel.ThingModel, service thingmodel.Service, st State) error {
+	var attrs []sdk.SetAttribute
+	if attr, ok := service.GetAttribute(thingmodel.HVACMode.Type); ok && st.HVACMode != "" {
+		val, err := tm.ValidateWrite(d.IID, attr.AID, st.HVACMode)
+		if err != nil {
+			return errors.Wrapf(err, status.AttrValueInvalid, err.Error())
+		}
+		attrs = append(attrs, sdk.SetAttribute{IID: d.IID, AID: attr.AID, Val: val})
+	}
+	// 关闭时不设置目标温度
+	if attr, ok := service.GetAttribute(thingmodel.TargetTemperature.Type); ok &&
+		st.TargetTemperature != nil && st.HVACMode != thingmodel.HVACModeOff {
+		val := tm.ValFrom(d.IID, attr.AID, *st.TargetTemperature, st.Unit)
+		val, err := tm.ValidateWrite(d.IID, attr.AID, val)
+		if err != nil {
+			return errors.Wrapf(err, status.AttrValueInvalid, err.Error())
+		}
+		attrs = append(attrs, sdk.SetAttribute{IID: d.IID, AID: attr.AID, Val: val})
+	}
+	if len(attrs) == 0 {
+		return nil
+	}
+	logger.Debugf("apply climate %s of device %d: %v", st.Source, d.ID, attrs)
+	err := plugin.SetAttributes(context.Background(), d.PluginID, d.AreaID, sdk.SetRequest{Attributes: attrs})
+	if err != nil {
+		identify := plugin.Identify{
+			PluginID: d.PluginID,
+			IID:      d.IID,
+			AreaID:   d.AreaID,
+		}
+		return errors.Wrapf(err, status.DeviceOffline, identify.ID())
+	}
+	return nil
+}
+
+// Thermostats 返回计划或覆盖作用的温控器，deviceID不为0时为该设备，否则为房间（公司为部门）中的所有温控器
+func Thermostats(areaID uint64, deviceID, locationID int) (devices []entity.Device, err error) {
+	if deviceID != 0 {
+		var d entity.Device
+		if d, err = entity.GetDeviceByID(deviceID); err != nil || d.AreaID != areaID {
+			return nil, errors.New(status.DeviceNotExist)
+		}
+		if !IsThermostat(d) {
+			return nil, errors.New(status.ClimateNotThermostat)
+		}
+		return []entity.Device{d}, nil
+	}
+	all, err := entity.GetDevices(areaID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.InternalServerErr)
+	}
+	for _, d := range all {
+		if d.IsSa() || !IsThermostat(d) {
+			continue
+		}
+		if id, err := LocationID(d); err != nil || id != locationID {
+			continue
+		}
+		devices = append(devices, d)
+	}
+	return
+}
+
+// RefreshTarget 计划或覆盖变化后，重新计算并下发作用的温控器的设定值
+func RefreshTarget(areaID uint64, deviceID, locationID int) {
+	devices, err := Thermostats(areaID, deviceID, locationID)
+	if err != nil {
+		logger.Warnf("get climate target of area %d err: %s", areaID, err)
+		return
+	}
+	for _, d := range devices {
+		if _, err = Refresh(d, true); err != nil {
+			logger.Warnf("refresh climate of device %d err: %s", d.ID, err)
+		}
+	}
+}
+
+// RefreshLocation 设备更换房间（公司为部门）后，重新计算并下发设备的设定值，withSub为true时包括其子设备
+func RefreshLocation(d entity.Device, withSub bool) {
+	devices, err := entity.GetDevices(d.AreaID)
+	if err != nil {
+		logger.Warnf("get devices of area %d err: %s", d.AreaID, err)
+		return
+	}
+	for _, device := range devices {
+		if device.ID != d.ID && !(withSub && device.ParentIID == d.IID) {
+			continue
+		}
+		if !IsThermostat(device) {
+			continue
+		}
+		if _, err = Refresh(device, true); err != nil {
+			logger.Warnf("refresh climate of device %d err: %s", device.ID, err)
+		}
+	}
+}
+
+// Start 启动时为所有温控器添加设定值变化的任务，不立即下发以免覆盖用户的手动设置
+func Start() {
+	areas, err := entity.GetAreas()
+	if err != nil {
+		logger.Errorf("get areas err: %s", err)
+		return
+	}
+	for _, area := range areas {
+		devices, err := entity.GetDevices(area.ID)
+		if err != nil {
+			logger.Errorf("get devices of area %d err: %s", area.ID, err)
+			continue
+		}
+		for _, d := range devices {
+			if d.IsSa() || !IsThermostat(d) {
+				continue
+			}
+			if _, err = Refresh(d, false); err != nil {
+				logger.Warnf("refresh climate of device %d err: %s", d.ID, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package climate
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// fakeClient 模拟插件，记录下发到设备的属性
+type fakeClient struct {
+	plugin.Client
+
+	mu   sync.Mutex
+	sets []sdk.SetAttribute
+}
+
+func (c *fakeClient) SetAttributes(ctx context.Context, pluginID string, areaID uint64, setReq sdk.SetRequest) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sets = append(c.sets, setReq.Attributes...)
+	return nil, nil
+}
+
+func (c *fakeClient) popSets() []sdk.SetAttribute {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sets := c.sets
+	c.sets = nil
+	return sets
+}
+
+var testClient = &fakeClient{}
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	plugin.SetGlobalClient(testClient)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+func temperature(v float64) *float64 {
+	return &v
+}
+
+var testProgram = Program{
+	{RepeatDate: "12345", Time: "07:00", TargetTemperature: temperature(21), HVACMode: thingmodel.HVACModeHeat},
+	{RepeatDate: "12345", Time: "22:00", TargetTemperature: temperature(17)},
+	{RepeatDate: "67", Time: "09:00", TargetTemperature: temperature(22)},
+}
+
+func TestProgramValidate(t *testing.T) {
+	assert.Nil(t, testProgram.Validate())
+	assert.NotNil(t, Program{}.Validate())
+
+	invalid := []Program{
+		{{RepeatDate: "8", Time: "07:00", TargetTemperature: temperature(20)}},
+		{{RepeatDate: "1", Time: "7点", TargetTemperature: temperature(20)}},
+		{{RepeatDate: "1", Time: "07:00"}},
+		{{RepeatDate: "1", Time: "07:00", HVACMode: "warm"}},
+		{
+			{RepeatDate: "12", Time: "07:00", TargetTemperature: temperature(20)},
+			{RepeatDate: "2", Time: "07:00", TargetTemperature: temperature(18)},
+		},
+	}
+	for _, p := range invalid {
+		assert.NotNil(t, p.Validate())
+	}
+}
+
+func TestProgramActive(t *testing.T) {
+	// 2022-03-07 为周一
+	monday := time.Date(2022, 3, 7, 0, 0, 0, 0, time.Local)
+	cases := []struct {
+		at     time.Time
+		expect float64
+		next   time.Time
+	}{
+		// 周一早上之前使用上周日的设定点
+		{monday.Add(6 * time.Hour), 22, monday.Add(7 * time.Hour)},
+		{monday.Add(7 * time.Hour), 21, monday.Add(22 * time.Hour)},
+		{monday.Add(23 * time.Hour), 17, monday.AddDate(0, 0, 1).Add(7 * time.Hour)},
+		// 周五晚上到周六早上
+		{monday.AddDate(0, 0, 5).Add(8 * time.Hour), 17, monday.AddDate(0, 0, 5).Add(9 * time.Hour)},
+		// 周日之后为下周一
+		{monday.AddDate(0, 0, 6).Add(10 * time.Hour), 22, monday.AddDate(0, 0, 7).Add(7 * time.Hour)},
+	}
+	for _, c := range cases {
+		sp, ok := testProgram.Active(c.at)
+		assert.True(t, ok)
+		assert.Equal(t, c.expect, *sp.TargetTemperature, c.at.String())
+		next, ok := testProgram.Next(c.at)
+		assert.True(t, ok)
+		assert.Equal(t, c.next, next, c.at.String())
+	}
+
+	_, ok := Program{}.Active(monday)
+	assert.False(t, ok)
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2022, 3, 7, 8, 0, 0, 0, time.Local)
+	setpoints, _ := json.Marshal(testProgram)
+	schedules := []entity.ClimateSchedule{
+		{ID: 1, LocationID: 2, Enabled: true, Setpoints: setpoints, Unit: thingmodel.UnitCelsius},
+		{ID: 2, DeviceID: 3, Enabled: false, Setpoints: setpoints, Unit: thingmodel.UnitFahrenheit},
+	}
+
+	// 设备的计划未启用，使用房间的计划
+	st := Resolve(3, 2, schedules, nil, now)
+	assert.Equal(t, SourceSchedule, st.Source)
+	assert.Equal(t, 1, st.ScheduleID)
+	assert.Equal(t, 21.0, *st.TargetTemperature)
+	assert.Equal(t, thingmodel.HVACModeHeat, st.HVACMode)
+	assert.Equal(t, now.Add(14*time.Hour).Unix(), st.NextChangeAt)
+
+	// 设备的计划优先于房间的计划
+	schedules[1].Enabled = true
+	st = Resolve(3, 2, schedules, nil, now)
+	assert.Equal(t, 2, st.ScheduleID)
+	assert.Equal(t, thingmodel.UnitFahrenheit, st.Unit)
+
+	// 覆盖优先于计划，设备的覆盖优先于房间的覆盖，过期的覆盖无效
+	overrides := []entity.ClimateOverride{
+		{ID: 1, Type: string(OverrideAway), LocationID: 2, TargetTemperature: temperature(16), ExpiresAt: now.Add(time.Hour)},
+		{ID: 2, Type: string(OverrideBoost), DeviceID: 3, TargetTemperature: temperature(24), ExpiresAt: now.Add(-time.Minute)},
+	}
+	st = Resolve(3, 2, schedules, overrides, now)
+	assert.Equal(t, SourceAway, st.Source)
+	assert.Equal(t, 1, st.OverrideID)
+	assert.Equal(t, now.Add(time.Hour).Unix(), st.NextChangeAt)
+
+	overrides[1].ExpiresAt = now.Add(time.Hour)
+	st = Resolve(3, 2, schedules, overrides, now)
+	assert.Equal(t, SourceBoost, st.Source)
+	assert.Equal(t, 24.0, *st.TargetTemperature)
+
+	// 其他房间的设备不受影响
+	st = Resolve(4, 5, schedules, overrides, now)
+	assert.Equal(t, SourceNone, st.Source)
+	assert.Zero(t, st.NextChangeAt)
+}
+
+func TestOverrideExpiresAt(t *testing.T) {
+	now := time.Now()
+	at, ok := OverrideBoost.ExpiresAt(now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Hour), at)
+	at, ok = OverrideAway.ExpiresAt(now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(24*time.Hour), at)
+	_, ok = OverrideAway.ExpiresAt(now, 31*24*time.Hour)
+	assert.False(t, ok)
+}
+
+// createThermostat 创建目标温度（aid 1）为20°C、工作模式（aid 2）为制热的温控器
+func createThermostat(t *testing.T, areaID uint64, iid string) entity.Device {
+	target, mode := thingmodel.TargetTemperature, thingmodel.HVACMode
+	target.AID, mode.AID = 1, 2
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID:      iid,
+		Services: []thingmodel.Service{{Type: thingmodel.Thermostat, Attributes: []thingmodel.Attribute{target, mode}}},
+	}}}
+	shadow := entity.NewShadow()
+	shadow.UpdateReported(iid, 1, 20.0)
+	shadow.UpdateReported(iid, 2, thingmodel.HVACModeHeat)
+	d := entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	d.Shadow, _ = json.Marshal(shadow)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+func createOverride(t *testing.T, d entity.Device, target float64) entity.ClimateOverride {
+	o := entity.ClimateOverride{Type: string(OverrideBoost), DeviceID: d.ID, TargetTemperature: temperature(target),
+		Unit: thingmodel.UnitCelsius, ExpiresAt: time.Now().Add(time.Hour), AreaID: d.AreaID}
+	require.NoError(t, entity.CreateClimateOverride(&o))
+	return o
+}
+
+func expire(t *testing.T, o entity.ClimateOverride) {
+	require.NoError(t, entity.GetDB().Model(&o).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+}
+
+func TestRefreshRestore(t *testing.T) {
+	area, err := entity.CreateArea("climate", entity.AreaOfHome)
+	require.NoError(t, err)
+	d := createThermostat(t, area.ID, "restore")
+
+	// 覆盖生效时记录原设定值
+	createOverride(t, d, 25)
+	st, err := Refresh(d, true)
+	require.NoError(t, err)
+	assert.Equal(t, SourceBoost, st.Source)
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: 25.0}}, testClient.popSets())
+
+	// 替换覆盖时保留最初的设定值
+	shadow, _ := d.GetShadow()
+	shadow.UpdateReported(d.IID, 1, 25.0)
+	d.Shadow, _ = json.Marshal(shadow)
+	o := createOverride(t, d, 27)
+	_, err = Refresh(d, true)
+	require.NoError(t, err)
+	testClient.popSets()
+	r, err := entity.GetClimateRestore(d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 20.0, *r.TargetTemperature)
+
+	// 覆盖到期且没有计划时恢复原设定值
+	expire(t, o)
+	st, err = Refresh(d, true)
+	require.NoError(t, err)
+	assert.Equal(t, SourceNone, st.Source)
+	assert.Equal(t, []sdk.SetAttribute{
+		{IID: d.IID, AID: 2, Val: thingmodel.HVACModeHeat},
+		{IID: d.IID, AID: 1, Val: 20.0},
+	}, testClient.popSets())
+	_, err = entity.GetClimateRestore(d.ID)
+	assert.Error(t, err)
+
+	// 没有覆盖时不下发
+	_, err = Refresh(d, true)
+	require.NoError(t, err)
+	assert.Empty(t, testClient.popSets())
+}
+
+func TestRefreshRestoreSchedule(t *testing.T) {
+	area, err := entity.CreateArea("climate", entity.AreaOfHome)
+	require.NoError(t, err)
+	d := createThermostat(t, area.ID, "restore-schedule")
+
+	o := createOverride(t, d, 25)
+	_, err = Refresh(d, true)
+	require.NoError(t, err)
+	testClient.popSets()
+
+	// 覆盖到期后由计划接管，不再恢复原设定值
+	setpoints, _ := json.Marshal(Program{{RepeatDate: "1234567", Time: "00:00", TargetTemperature: temperature(18)}})
+	schedule := entity.ClimateSchedule{DeviceID: d.ID, Enabled: true, Setpoints: setpoints,
+		Unit: thingmodel.UnitCelsius, AreaID: area.ID}
+	require.NoError(t, entity.CreateClimateSchedule(&schedule))
+	expire(t, o)
+	st, err := Refresh(d, true)
+	require.NoError(t, err)
+	assert.Equal(t, SourceSchedule, st.Source)
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: 18.0}}, testClient.popSets())
+	_, err = entity.GetClimateRestore(d.ID)
+	assert.Error(t, err)
+}
+
+func TestRefreshLocation(t *testing.T) {
+	area, err := entity.CreateArea("climate", entity.AreaOfHome)
+	require.NoError(t, err)
+	location := entity.Location{Name: "卧室", AreaID: area.ID}
+	require.NoError(t, entity.CreateLocation(&location))
+	d := createThermostat(t, area.ID, "location")
+	setpoints, _ := json.Marshal(Program{{RepeatDate: "1234567", Time: "00:00", TargetTemperature: temperature(19)}})
+	schedule := entity.ClimateSchedule{LocationID: location.ID, Enabled: true, Setpoints: setpoints,
+		Unit: thingmodel.UnitCelsius, AreaID: area.ID}
+	require.NoError(t, entity.CreateClimateSchedule(&schedule))
+	testClient.popSets()
+
+	// 温控器更换到有计划的房间后下发计划的设定值
+	require.NoError(t, entity.UpdateDevice(d.ID, entity.Device{LocationID: location.ID}))
+	RefreshLocation(d, false)
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: 19.0}}, testClient.popSets())
+}
@@ -0,0 +1,40 @@
+package climate
+
+import (
+	"time"
+)
+
+// OverrideType 温控覆盖类型
+type OverrideType string
+
+const (
+	OverrideBoost OverrideType = "boost" // 提温，临时使用指定的设定值，默认持续1小时
+	OverrideAway  OverrideType = "away"  // 离家，离家期间使用节能的设定值，默认持续24小时
+)
+
+const maxOverrideDuration = 30 * 24 * time.Hour
+
+// DefaultDuration 覆盖的默认持续时间
+func (t OverrideType) DefaultDuration() time.Duration {
+	if t == OverrideAway {
+		return 24 * time.Hour
+	}
+	return time.Hour
+}
+
+// Valid 是否支持的覆盖类型
+func (t OverrideType) Valid() bool {
+	return t == OverrideBoost || t == OverrideAway
+}
+
+// ExpiresAt 根据开始时间及持续时间计算覆盖的过期时间，未指定持续时间时使用默认值，
+// 持续时间不合法时返回false
+func (t OverrideType) ExpiresAt(now time.Time, duration time.Duration) (time.Time, bool) {
+	if duration == 0 {
+		duration = t.DefaultDuration()
+	}
+	if duration < 0 || duration > maxOverrideDuration {
+		return time.Time{}, false
+	}
+	return now.Add(duration), true
+}
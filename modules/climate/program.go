@@ -0,0 +1,141 @@
+package climate
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+// Setpoint 温控计划中的设定点，从开始时间起生效，直到下一个设定点
+type Setpoint struct {
+	RepeatDate        string   `json:"repeat_date"`                  // 生效的星期，1-7表示周一至周日，如"12345"
+	Time              string   `json:"time"`                         // 开始时间，格式为 15:04
+	TargetTemperature *float64 `json:"target_temperature,omitempty"` // 目标温度，单位为计划的单位
+	HVACMode          string   `json:"hvac_mode,omitempty"`          // 工作模式，为空则不修改
+}
+
+// Program 每周的温控计划
+type Program []Setpoint
+
+// transition 设定点在一周中的开始时间，为距离周一零点的分钟数
+type transition struct {
+	minute   int
+	setpoint Setpoint
+}
+
+func validHVACMode(mode string) bool {
+	for _, o := range thingmodel.HVACMode.Options {
+		if o.Val == mode {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// transitions 返回按时间排序的设定点，设定点不合法时返回错误
+func (p Program) transitions() ([]transition, error) {
+	var ts []transition
+	seen := make(map[int]bool)
+	for i, s := range p {
+		if !entity.CheckIllegalRepeatDate(s.RepeatDate) {
+			return nil, fmt.Errorf("第%d个设定点的repeat_date不正确", i+1)
+		}
+		clock, err := parseClock(s.Time)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个设定点的time不正确", i+1)
+		}
+		if s.TargetTemperature == nil && s.HVACMode == "" {
+			return nil, fmt.Errorf("第%d个设定点缺少target_temperature或hvac_mode", i+1)
+		}
+		if s.HVACMode != "" && !validHVACMode(s.HVACMode) {
+			return nil, fmt.Errorf("第%d个设定点的hvac_mode不正确", i+1)
+		}
+		for _, d := range s.RepeatDate {
+			m := int(d-'1')*minutesPerDay + clock
+			if seen[m] {
+				return nil, fmt.Errorf("第%d个设定点与其他设定点时间重复", i+1)
+			}
+			seen[m] = true
+			ts = append(ts, transition{minute: m, setpoint: s})
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool {
+		return ts[i].minute < ts[j].minute
+	})
+	return ts, nil
+}
+
+// Validate 校验温控计划
+func (p Program) Validate() error {
+	if len(p) == 0 {
+		return fmt.Errorf("缺少设定点")
+	}
+	_, err := p.transitions()
+	return err
+}
+
+// weekStart 返回t所在周的周一零点，按SA所在时区计算
+func weekStart(t time.Time) time.Time {
+	t = t.In(time.Local)
+	weekday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-weekday, 0, 0, 0, 0, time.Local)
+}
+
+// weekMinute 返回t距离所在周周一零点的分钟数
+func weekMinute(t time.Time) int {
+	t = t.In(time.Local)
+	weekday := (int(t.Weekday()) + 6) % 7
+	return weekday*minutesPerDay + t.Hour()*60 + t.Minute()
+}
+
+// Active 返回t时生效的设定点，即t之前最近的设定点，本周没有则为上周最后一个设定点
+func (p Program) Active(t time.Time) (Setpoint, bool) {
+	ts, err := p.transitions()
+	if err != nil || len(ts) == 0 {
+		return Setpoint{}, false
+	}
+	cur := weekMinute(t)
+	active := ts[len(ts)-1]
+	for _, tr := range ts {
+		if tr.minute > cur {
+			break
+		}
+		active = tr
+	}
+	return active.setpoint, true
+}
+
+// Next 返回t之后下一个设定点的开始时间
+func (p Program) Next(t time.Time) (time.Time, bool) {
+	ts, err := p.transitions()
+	if err != nil || len(ts) == 0 {
+		return time.Time{}, false
+	}
+	cur := weekMinute(t)
+	next := ts[0].minute + minutesPerWeek
+	for _, tr := range ts {
+		if tr.minute > cur {
+			next = tr.minute
+			break
+		}
+	}
+	start := weekStart(t)
+	return time.Date(start.Year(), start.Month(), start.Day()+next/minutesPerDay,
+		0, next%minutesPerDay, 0, 0, time.Local), true
+}
@@ -0,0 +1,133 @@
+package climate
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// checkTarget 校验计划或覆盖作用的设备或房间（公司为部门），两者必须且只能设置一个
+func checkTarget(areaID uint64, deviceID, locationID int) error {
+	if (deviceID == 0) == (locationID == 0) {
+		return errors.Newf(status.ClimateParamErr, "device_id")
+	}
+	if deviceID != 0 {
+		_, err := Thermostats(areaID, deviceID, 0)
+		return err
+	}
+	area, err := entity.GetAreaByID(areaID)
+	if err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	if entity.IsCompany(area.AreaType) {
+		if !entity.IsDepartmentExist(areaID, locationID) {
+			return errors.New(status.DepartmentNotExit)
+		}
+	} else if !entity.IsLocationExist(areaID, locationID) {
+		return errors.New(status.LocationNotExit)
+	}
+	return nil
+}
+
+// checkSchedule 校验温控计划，同一设备或房间只能有一个计划
+func checkSchedule(s *entity.ClimateSchedule, p Program) error {
+	if s.Name == "" {
+		return errors.Newf(status.ClimateParamErr, "name")
+	}
+	if err := checkTarget(s.AreaID, s.DeviceID, s.LocationID); err != nil {
+		return err
+	}
+	if err := p.Validate(); err != nil {
+		return errors.Newf(status.ClimateScheduleIncorrect, err.Error())
+	}
+	schedules, err := entity.GetClimateSchedules(s.AreaID)
+	if err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	for _, o := range schedules {
+		if o.ID != s.ID && o.DeviceID == s.DeviceID && o.LocationID == s.LocationID {
+			return errors.New(status.ClimateScheduleExist)
+		}
+	}
+	if s.Setpoints, err = json.Marshal(p); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	return nil
+}
+
+// CreateSchedule 创建温控计划，并下发作用的温控器当前的设定值
+func CreateSchedule(s *entity.ClimateSchedule, p Program) error {
+	if err := checkSchedule(s, p); err != nil {
+		return err
+	}
+	if err := entity.CreateClimateSchedule(s); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	go RefreshTarget(s.AreaID, s.DeviceID, s.LocationID)
+	return nil
+}
+
+// UpdateSchedule 修改温控计划，作用的设备或房间变化时原设备或房间也重新计算设定值
+func UpdateSchedule(old entity.ClimateSchedule, s *entity.ClimateSchedule, p Program) error {
+	s.ID, s.AreaID, s.CreatorID = old.ID, old.AreaID, old.CreatorID
+	if err := checkSchedule(s, p); err != nil {
+		return err
+	}
+	if err := entity.SaveClimateSchedule(s); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	go func() {
+		RefreshTarget(s.AreaID, s.DeviceID, s.LocationID)
+		if old.DeviceID != s.DeviceID || old.LocationID != s.LocationID {
+			RefreshTarget(old.AreaID, old.DeviceID, old.LocationID)
+		}
+	}()
+	return nil
+}
+
+// DeleteSchedule 删除温控计划，作用的温控器按剩余的计划或覆盖重新计算设定值
+func DeleteSchedule(s entity.ClimateSchedule) error {
+	if err := entity.DeleteClimateSchedule(s.ID, s.AreaID); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	go RefreshTarget(s.AreaID, s.DeviceID, s.LocationID)
+	return nil
+}
+
+// CreateOverride 创建温控覆盖，替换同一设备或房间已有的覆盖，并立即下发覆盖的设定值
+func CreateOverride(o *entity.ClimateOverride, duration time.Duration) error {
+	if !OverrideType(o.Type).Valid() {
+		return errors.Newf(status.ClimateParamErr, "type")
+	}
+	if err := checkTarget(o.AreaID, o.DeviceID, o.LocationID); err != nil {
+		return err
+	}
+	if o.TargetTemperature == nil && o.HVACMode == "" {
+		return errors.Newf(status.ClimateParamErr, "target_temperature")
+	}
+	if o.HVACMode != "" && !validHVACMode(o.HVACMode) {
+		return errors.Newf(status.ClimateParamErr, "hvac_mode")
+	}
+	expiresAt, ok := OverrideType(o.Type).ExpiresAt(time.Now(), duration)
+	if !ok {
+		return errors.Newf(status.ClimateParamErr, "duration")
+	}
+	o.ExpiresAt = expiresAt
+	if err := entity.CreateClimateOverride(o); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	go RefreshTarget(o.AreaID, o.DeviceID, o.LocationID)
+	return nil
+}
+
+// DeleteOverride 提前结束温控覆盖，作用的温控器恢复计划的设定值
+func DeleteOverride(o entity.ClimateOverride) error {
+	if err := entity.DeleteClimateOverride(o.ID, o.AreaID); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	go RefreshTarget(o.AreaID, o.DeviceID, o.LocationID)
+	return nil
+}
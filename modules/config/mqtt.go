@@ -0,0 +1,80 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// MQTT MQTT桥接配置，Broker为空时不开启
+type MQTT struct {
+	// Broker 服务器地址，如 tcp://127.0.0.1:1883，使用TLS时为 ssl://host:8883
+	Broker   string `json:"broker" yaml:"broker"`
+	ClientID string `json:"client_id" yaml:"client_id"` // 为空时使用 sa-<SA ID>
+	Username string `json:"username" yaml:"username"`
+	Password string `json:"password" yaml:"password"`
+	// TopicPrefix 主题前缀，默认为sa
+	TopicPrefix string `json:"topic_prefix" yaml:"topic_prefix"`
+	QoS         byte   `json:"qos" yaml:"qos"`
+	KeepAlive   int    `json:"keep_alive" yaml:"keep_alive"` // 保活时间（秒），默认60秒
+	// Token 集成token，决定桥接的家庭及控制设备的权限，通过 POST /api/mqtt/token 生成
+	Token string  `json:"token" yaml:"token"`
+	TLS   MQTTTLS `json:"tls" yaml:"tls"`
+
+	// Discovery 是否发布 Home Assistant 的MQTT发现配置
+	Discovery       bool   `json:"discovery" yaml:"discovery"`
+	DiscoveryPrefix string `json:"discovery_prefix" yaml:"discovery_prefix"` // 默认为homeassistant
+}
+
+// MQTTTLS 证书配置，CAFile为空时使用系统的根证书
+type MQTTTLS struct {
+	CAFile             string `json:"ca_file" yaml:"ca_file"`
+	CertFile           string `json:"cert_file" yaml:"cert_file"` // 客户端证书，服务器要求双向认证时配置
+	KeyFile            string `json:"key_file" yaml:"key_file"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+// Enabled 是否开启MQTT桥接
+func (m MQTT) Enabled() bool {
+	return m.Broker != ""
+}
+
+// GetTopicPrefix 主题前缀
+func (m MQTT) GetTopicPrefix() string {
+	if m.TopicPrefix == "" {
+		return "sa"
+	}
+	return m.TopicPrefix
+}
+
+// GetDiscoveryPrefix Home Assistant 发现主题的前缀
+func (m MQTT) GetDiscoveryPrefix() string {
+	if m.DiscoveryPrefix == "" {
+		return "homeassistant"
+	}
+	return m.DiscoveryPrefix
+}
+
+// TLSConfig 根据证书配置生成TLS配置
+func (t MQTTTLS) TLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{InsecureSkipVerify: t.InsecureSkipVerify}
+	if t.CAFile != "" {
+		ca, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid ca file %s", t.CAFile)
+		}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
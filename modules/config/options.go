@@ -23,4 +23,5 @@ type Options struct {
 	Datatunnel     Datatunnel     `json:"datatunnel" yaml:"datatunnel"`
 	Extension      Extension      `json:"extension" yaml:"extension"`
 	Oss            Oss            `json:"OSS" yaml:"OSS"`
+	MQTT           MQTT           `json:"mqtt" yaml:"mqtt"`
 }
@@ -19,6 +19,10 @@ type SmartAssistant struct {
 	RuntimePath     string `json:"runtime_path" yaml:"runtime_path"`
 
 	DockerRegistry string `json:"docker_registry" yaml:"docker_registry"`
+	// PluginRuntime 插件运行时，docker（默认）或 process
+	PluginRuntime string `json:"plugin_runtime" yaml:"plugin_runtime"`
+	// PluginRegistry 插件注册中心，etcd（默认）或 builtin（SA内置，无需etcd）
+	PluginRegistry string `json:"plugin_registry" yaml:"plugin_registry"`
 
 	// Deprecated: HostIP 插件取消host模式后删除
 	HostIP string `json:"host_ip" yaml:"host_ip"`
@@ -45,6 +49,11 @@ func (sa SmartAssistant) GRPCAddress() string {
 	return fmt.Sprintf("%s:%d", sa.Host, sa.GRPCPort)
 }
 
+// IsBuiltinRegistry 是否使用SA内置的插件注册中心
+func (sa SmartAssistant) IsBuiltinRegistry() bool {
+	return sa.PluginRegistry == "builtin"
+}
+
 func (sa SmartAssistant) BackupPath() string {
 	return filepath.Join(sa.RuntimePath, "backup")
 }
@@ -53,6 +62,11 @@ func (sa SmartAssistant) DataPath() string {
 	return filepath.Join(sa.RuntimePath, "data")
 }
 
+// TrustedKeysPath 受信任的插件发布者公钥目录
+func (sa SmartAssistant) TrustedKeysPath() string {
+	return filepath.Join(sa.DataPath(), "smartassistant", "trusted_keys")
+}
+
 func (sa SmartAssistant) VolumePath() string {
 	return filepath.Join(sa.RuntimePath, "volume")
 }
@@ -0,0 +1,138 @@
+package device
+
+import (
+	"context"
+	"sync"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// batchConcurrency 批量控制时同时下发的最大设备数
+const batchConcurrency = 8
+
+type BatchStatus string
+
+// 批量控制中单个设备的执行结果
+const (
+	BatchStatusSuccess BatchStatus = "success"
+	BatchStatusFail    BatchStatus = "fail"
+	BatchStatusOffline BatchStatus = "offline"
+)
+
+// BatchAttribute 需要设置的属性，iid为空时使用设备的iid
+type BatchAttribute struct {
+	IID string      `json:"iid"`
+	AID int         `json:"aid"`
+	Val interface{} `json:"val"`
+}
+
+// BatchDevice 单个设备需要设置的属性
+type BatchDevice struct {
+	DeviceID   int              `json:"device_id"`
+	Attributes []BatchAttribute `json:"attributes"`
+}
+
+// BatchResult 单个设备的执行结果
+type BatchResult struct {
+	DeviceID int         `json:"device_id"`
+	Status   BatchStatus `json:"status"`
+	Code     int         `json:"code,omitempty"`
+	Reason   string      `json:"reason,omitempty"`
+
+	Errors thingmodel.FieldErrors `json:"errors,omitempty"` // 属性值不正确时每个属性的错误
+}
+
+// BatchSetAttributes 并发设置多个设备（可属于不同插件）的属性，返回每个设备的执行结果
+func BatchSetAttributes(ctx context.Context, areaID uint64, up entity.UserPermissions, devices []BatchDevice) []BatchResult {
+	results := make([]BatchResult, len(devices))
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for i, bd := range devices {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, bd BatchDevice) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = setDeviceAttributes(ctx, areaID, up, bd)
+		}(i, bd)
+	}
+	wg.Wait()
+	return results
+}
+
+func setDeviceAttributes(ctx context.Context, areaID uint64, up entity.UserPermissions, bd BatchDevice) (result BatchResult) {
+	result.DeviceID = bd.DeviceID
+	d, err := entity.GetDeviceByID(bd.DeviceID)
+	if err != nil || d.AreaID != areaID {
+		return batchFail(result, errors.New(status.DeviceNotExist))
+	}
+	if d.IsSa() {
+		return batchFail(result, errors.New(status.AttrNotFound))
+	}
+
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return batchFail(result, errors.Wrap(err, errors.InternalServerErr))
+	}
+	setReq := sdk.SetRequest{Attributes: make([]sdk.SetAttribute, 0, len(bd.Attributes))}
+	for _, attr := range bd.Attributes {
+		iid := attr.IID
+		if iid == "" {
+			iid = d.IID
+		}
+		if _, err = tm.GetAttribute(iid, attr.AID); err != nil {
+			return batchFail(result, errors.New(status.AttrNotFound))
+		}
+		// 网关的物模型包含子设备的实例，控制子设备时判断子设备的控制权限
+		target := d
+		if iid != d.IID {
+			if target, err = entity.GetPluginDevice(areaID, d.PluginID, iid); err != nil {
+				return batchFail(result, errors.New(status.DeviceNotExist))
+			}
+		}
+		// 判断控制权限
+		if !up.IsDeviceAttrControlPermit(target.ID, attr.AID) {
+			return batchFail(result, errors.New(status.Deny))
+		}
+		// 值使用用户选择的单位制，换算为设备的单位
+		val := tm.ValFromSystem(iid, attr.AID, attr.Val, up.UnitSystem())
+		setReq.Attributes = append(setReq.Attributes, sdk.SetAttribute{IID: iid, AID: attr.AID, Val: val})
+	}
+	if setReq.Attributes, err = ValidateAttributes(tm, setReq.Attributes); err != nil {
+		return batchFail(result, err)
+	}
+
+	identify := plugin.Identify{
+		PluginID: d.PluginID,
+		IID:      d.IID,
+		AreaID:   d.AreaID,
+	}
+	if !plugin.GetGlobalClient().IsOnline(identify) {
+		result.Status = BatchStatusOffline
+		return
+	}
+	if err = plugin.SetAttributes(ctx, d.PluginID, d.AreaID, setReq); err != nil {
+		return batchFail(result, errors.Wrap(err, errors.InternalServerErr))
+	}
+	result.Status = BatchStatusSuccess
+	return
+}
+
+func batchFail(result BatchResult, err error) BatchResult {
+	result.Status = BatchStatusFail
+	if e, ok := err.(errors.Error); ok {
+		result.Code = e.Code.Status
+		result.Reason = e.Code.Reason
+		result.Errors = FieldErrors(err)
+	} else {
+		result.Reason = err.Error()
+	}
+	return result
+}
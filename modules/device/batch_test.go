@@ -0,0 +1,224 @@
+package device
+
+import (
+	"context"
+	"encoding/json"
+	errors2 "errors"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// fakeClient 模拟插件，记录下发的属性
+type fakeClient struct {
+	plugin.Client
+
+	mu      sync.Mutex
+	offline map[string]bool
+	fail    map[string]bool
+	sets    map[string][]sdk.SetAttribute
+}
+
+func (c *fakeClient) IsOnline(identify plugin.Identify) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return !c.offline[identify.IID]
+}
+
+func (c *fakeClient) SetAttributes(ctx context.Context, pluginID string, areaID uint64, setReq sdk.SetRequest) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	iid := setReq.Attributes[0].IID
+	if c.fail[iid] {
+		return nil, errors2.New("device timeout")
+	}
+	c.sets[iid] = append(c.sets[iid], setReq.Attributes...)
+	return nil, nil
+}
+
+var testClient = &fakeClient{
+	offline: make(map[string]bool),
+	fail:    make(map[string]bool),
+	sets:    make(map[string][]sdk.SetAttribute),
+}
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	plugin.SetGlobalClient(testClient)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+func withAID(attr thingmodel.Attribute, aid int) thingmodel.Attribute {
+	attr.AID = aid
+	return attr
+}
+
+// createLight 创建包含开关（aid 1）和亮度（aid 2）的灯
+func createLight(t *testing.T, areaID uint64, iid string) entity.Device {
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID: iid,
+		Services: []thingmodel.Service{{
+			Type:       thingmodel.LightBulbService,
+			Attributes: []thingmodel.Attribute{withAID(thingmodel.OnOff, 1), withAID(thingmodel.Brightness, 2)},
+		}},
+	}}}
+	data, err := json.Marshal(tm)
+	require.NoError(t, err)
+	d := entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID, ThingModel: data}
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+// createArea 创建家庭及拥有者，返回拥有者的权限
+func createArea(t *testing.T) (entity.Area, entity.UserPermissions) {
+	area, err := entity.CreateArea("batch", entity.AreaOfHome)
+	require.NoError(t, err)
+	u := entity.User{AccountName: "owner" + strconv.FormatUint(area.ID, 10), AreaID: area.ID}
+	require.NoError(t, entity.CreateUser(&u, entity.GetDB()))
+	require.NoError(t, entity.SetAreaOwnerID(area.ID, u.ID, entity.GetDB()))
+	up, err := entity.GetUserPermissions(u.ID)
+	require.NoError(t, err)
+	return area, up
+}
+
+// createMember 创建只能控制设备指定属性的成员，返回成员的权限
+func createMember(t *testing.T, areaID uint64, deviceID int, aids ...int) entity.UserPermissions {
+	u := entity.User{AccountName: "member" + strconv.Itoa(deviceID), AreaID: areaID}
+	require.NoError(t, entity.CreateUser(&u, entity.GetDB()))
+	role, err := entity.AddRole("member", areaID)
+	require.NoError(t, err)
+	for _, aid := range aids {
+		err = role.AddPermissionForRole("控制", types.ActionControl, types.DeviceTarget(deviceID), strconv.Itoa(aid))
+		require.NoError(t, err)
+	}
+	require.NoError(t, entity.CreateUserRole([]entity.UserRole{{UserID: u.ID, RoleID: role.ID}}))
+	up, err := entity.GetUserPermissions(u.ID)
+	require.NoError(t, err)
+	return up
+}
+
+func TestBatchSetAttributes(t *testing.T) {
+	area, owner := createArea(t)
+	light := createLight(t, area.ID, "batch-light")
+	offline := createLight(t, area.ID, "batch-offline")
+	failed := createLight(t, area.ID, "batch-fail")
+	testClient.offline[offline.IID] = true
+	testClient.fail[failed.IID] = true
+
+	otherArea, err := entity.CreateArea("other", entity.AreaOfHome)
+	require.NoError(t, err)
+	other := createLight(t, otherArea.ID, "batch-other")
+
+	devices := []BatchDevice{
+		{DeviceID: light.ID, Attributes: []BatchAttribute{{AID: 1, Val: "on"}, {AID: 2, Val: float64(80)}}},
+		{DeviceID: offline.ID, Attributes: []BatchAttribute{{AID: 1, Val: "on"}}},
+		{DeviceID: failed.ID, Attributes: []BatchAttribute{{AID: 1, Val: "on"}}},
+		{DeviceID: other.ID, Attributes: []BatchAttribute{{AID: 1, Val: "on"}}},
+		{DeviceID: light.ID, Attributes: []BatchAttribute{{AID: 9, Val: "on"}}},
+		{DeviceID: light.ID, Attributes: []BatchAttribute{{AID: 2, Val: float64(200)}}},
+	}
+	results := BatchSetAttributes(context.Background(), area.ID, owner, devices)
+	require.Len(t, results, len(devices))
+
+	assert.Equal(t, BatchStatusSuccess, results[0].Status)
+	assert.Equal(t, light.ID, results[0].DeviceID)
+	assert.Equal(t, []sdk.SetAttribute{
+		{IID: light.IID, AID: 1, Val: "on"},
+		{IID: light.IID, AID: 2, Val: 80},
+	}, testClient.sets[light.IID])
+
+	assert.Equal(t, BatchStatusOffline, results[1].Status)
+	assert.Equal(t, BatchStatusFail, results[2].Status)
+	assert.Equal(t, errors.InternalServerErr, results[2].Code)
+
+	// 其他家庭的设备视为不存在
+	assert.Equal(t, BatchStatusFail, results[3].Status)
+	assert.Equal(t, status.DeviceNotExist, results[3].Code)
+	assert.Empty(t, testClient.sets[other.IID])
+
+	assert.Equal(t, status.AttrNotFound, results[4].Code)
+
+	// 超出范围时返回每个属性的错误
+	assert.Equal(t, BatchStatusFail, results[5].Status)
+	assert.Equal(t, status.AttrValueInvalid, results[5].Code)
+	require.Len(t, results[5].Errors, 1)
+	assert.Equal(t, 2, results[5].Errors[0].AID)
+}
+
+func TestBatchSetAttributesPermission(t *testing.T) {
+	area, _ := createArea(t)
+	light := createLight(t, area.ID, "permit-light")
+	member := createMember(t, area.ID, light.ID, 1)
+
+	// 只有开关的控制权限
+	results := BatchSetAttributes(context.Background(), area.ID, member, []BatchDevice{
+		{DeviceID: light.ID, Attributes: []BatchAttribute{{AID: 1, Val: "off"}}},
+		{DeviceID: light.ID, Attributes: []BatchAttribute{{AID: 1, Val: "on"}, {AID: 2, Val: float64(10)}}},
+	})
+	assert.Equal(t, BatchStatusSuccess, results[0].Status)
+	assert.Equal(t, BatchStatusFail, results[1].Status)
+	assert.Equal(t, status.Deny, results[1].Code)
+	assert.Equal(t, []sdk.SetAttribute{{IID: light.IID, AID: 1, Val: "off"}}, testClient.sets[light.IID])
+}
+
+// createGateway 创建网关（开关aid 1）及其子设备灯，网关的物模型包含子设备的实例
+func createGateway(t *testing.T, areaID uint64, iid string) (gateway, child entity.Device) {
+	childIID := iid + "-child"
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{
+		{IID: iid, Services: []thingmodel.Service{{
+			Type:       thingmodel.SwitchService,
+			Attributes: []thingmodel.Attribute{withAID(thingmodel.OnOff, 1)},
+		}}},
+		{IID: childIID, Services: []thingmodel.Service{{
+			Type:       thingmodel.LightBulbService,
+			Attributes: []thingmodel.Attribute{withAID(thingmodel.OnOff, 1), withAID(thingmodel.Brightness, 2)},
+		}}},
+	}}
+	data, err := json.Marshal(tm)
+	require.NoError(t, err)
+	gateway = entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID, ThingModel: data}
+	require.NoError(t, entity.CreateDevice(&gateway, entity.GetDB()))
+	child = entity.Device{Name: childIID, PluginID: "demo", IID: childIID, ParentIID: iid, AreaID: areaID, ThingModel: data}
+	require.NoError(t, entity.CreateDevice(&child, entity.GetDB()))
+	return
+}
+
+func TestBatchSetAttributesGatewayChild(t *testing.T) {
+	area, _ := createArea(t)
+	gateway, child := createGateway(t, area.ID, "permit-gateway")
+	member := createMember(t, area.ID, gateway.ID, 1)
+
+	// 只有网关的控制权限时不能通过网关控制子设备
+	results := BatchSetAttributes(context.Background(), area.ID, member, []BatchDevice{
+		{DeviceID: gateway.ID, Attributes: []BatchAttribute{{AID: 1, Val: "on"}}},
+		{DeviceID: gateway.ID, Attributes: []BatchAttribute{{IID: child.IID, AID: 1, Val: "on"}}},
+		{DeviceID: gateway.ID, Attributes: []BatchAttribute{{IID: "permit-gateway-unknown", AID: 1, Val: "on"}}},
+	})
+	assert.Equal(t, BatchStatusSuccess, results[0].Status)
+	assert.Equal(t, status.Deny, results[1].Code)
+	assert.Equal(t, status.AttrNotFound, results[2].Code)
+	assert.Empty(t, testClient.sets[child.IID])
+
+	// 有子设备的控制权限时可以通过网关控制
+	childMember := createMember(t, area.ID, child.ID, 1)
+	results = BatchSetAttributes(context.Background(), area.ID, childMember, []BatchDevice{
+		{DeviceID: gateway.ID, Attributes: []BatchAttribute{{IID: child.IID, AID: 1, Val: "off"}}},
+	})
+	assert.Equal(t, BatchStatusSuccess, results[0].Status)
+	assert.Equal(t, []sdk.SetAttribute{{IID: child.IID, AID: 1, Val: "off"}}, testClient.sets[child.IID])
+}
@@ -70,6 +70,15 @@ func ManagePermissions(d entity.Device) []types.Permission {
 	if d.IsSa() {
 		permissions = append(permissions, types.NewDeviceFwUpgrade(d.ID))
 		permissions = append(permissions, types.NewDeviceSoftwareUpgrade(d.ID))
+		return permissions
+	}
+	actions, err := d.Actions()
+	if err != nil {
+		logger.Error("GetActionsErr", err)
+		return permissions
+	}
+	for _, action := range actions {
+		permissions = append(permissions, types.NewDeviceAction(d.ID, action.Type))
 	}
 	return permissions
 }
@@ -0,0 +1,46 @@
+package device
+
+import (
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// ValidateErrors 属性值校验失败时返回的数据
+type ValidateErrors struct {
+	Errors thingmodel.FieldErrors `json:"errors"`
+}
+
+// ValidateAttributes 按物模型校验需要设置的属性值，返回转换后的属性；
+// 存在不正确的值时返回包含所有字段错误的 status.AttrValueInvalid
+func ValidateAttributes(tm thingmodel.ThingModel, attrs []sdk.SetAttribute) ([]sdk.SetAttribute, error) {
+	var fieldErrs thingmodel.FieldErrors
+	result := make([]sdk.SetAttribute, 0, len(attrs))
+	for _, attr := range attrs {
+		val, err := tm.ValidateWrite(attr.IID, attr.AID, attr.Val)
+		if err != nil {
+			fieldErrs = append(fieldErrs, toFieldError(attr, err))
+			continue
+		}
+		attr.Val = val
+		result = append(result, attr)
+	}
+	if len(fieldErrs) != 0 {
+		return nil, errors.Wrapf(fieldErrs, status.AttrValueInvalid, fieldErrs.Error())
+	}
+	return result, nil
+}
+
+// FieldErrors 获取 ValidateAttributes 返回错误中的字段错误
+func FieldErrors(err error) thingmodel.FieldErrors {
+	fieldErrs, _ := errors.Cause(err).(thingmodel.FieldErrors)
+	return fieldErrs
+}
+
+func toFieldError(attr sdk.SetAttribute, err error) thingmodel.FieldError {
+	if fe, ok := err.(thingmodel.FieldError); ok {
+		return fe
+	}
+	return thingmodel.FieldError{IID: attr.IID, AID: attr.AID, Reason: err.Error()}
+}
@@ -0,0 +1,133 @@
+// Package energy 能耗统计，根据设备上报的累计用电量计算设备、房间（部门）及家庭每小时、每天、每月的用电量及电费
+package energy
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+const (
+	resetRatio = 0.5  // 读数低于上次读数的比例，低于该比例视为计量重置
+	resetFloor = 0.01 // 读数不超过该值（kWh）时视为从0重新累计
+)
+
+// Delta 根据上次读数prev及本次读数cur计算用电量。累计用电量只增不减，
+// 读数大幅下降（低于上次读数的一半或接近0）视为计量重置（如设备恢复出厂、更换计量芯片），重置后从0重新累计；
+// 小幅下降视为读数抖动，ok为false，忽略本次读数
+func Delta(prev, cur float64) (delta float64, ok bool) {
+	if cur >= prev {
+		return cur - prev, true
+	}
+	if cur <= resetFloor || cur < prev*resetRatio {
+		return cur, true
+	}
+	return 0, false
+}
+
+// Slice 某个整点时间内的用电量
+type Slice struct {
+	Hour   time.Time
+	Energy float64
+}
+
+// Split 将[from, to)内的用电量按时长平均分配到各个整点时间内，用于设备长时间未上报的情况
+func Split(delta float64, from, to time.Time) []Slice {
+	hour := to.Truncate(time.Hour)
+	if !from.Before(to) || !from.Before(hour) {
+		return []Slice{{Hour: hour, Energy: delta}}
+	}
+	total := to.Sub(from).Seconds()
+	var slices []Slice
+	for start := from; start.Before(to); {
+		h := start.Truncate(time.Hour)
+		end := h.Add(time.Hour)
+		if end.After(to) {
+			end = to
+		}
+		slices = append(slices, Slice{Hour: h, Energy: delta * end.Sub(start).Seconds() / total})
+		start = end
+	}
+	return slices
+}
+
+var deviceLocks sync.Map
+
+// Record 记录设备上报的累计用电量，attr为实例iid的累计用电量属性，用于换算单位
+func Record(d entity.Device, iid string, attr thingmodel.Attribute, val interface{}, at time.Time) (err error) {
+	reading, ok := toKWh(attr, val)
+	if !ok {
+		return nil
+	}
+
+	v, _ := deviceLocks.LoadOrStore(d.ID, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	defer mu.Unlock()
+
+	counter, err := entity.GetEnergyCounter(d.ID, iid, attr.AID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		}
+		// 首次上报只记录基准读数
+		counter = entity.EnergyCounter{DeviceID: d.ID, IID: iid, AID: attr.AID, Reading: reading, ReadAt: at, AreaID: d.AreaID}
+		return entity.SaveEnergyCounter(entity.GetDB(), counter)
+	}
+
+	// 事件处理函数并发执行，晚于最后读数的上报才有效
+	if !at.After(counter.ReadAt) {
+		return nil
+	}
+	delta, ok := Delta(counter.Reading, reading)
+	if !ok {
+		return nil
+	}
+	from := counter.ReadAt
+	counter.Reading = reading
+	counter.ReadAt = at
+	return entity.GetDB().Transaction(func(tx *gorm.DB) error {
+		if delta > 0 {
+			for _, s := range Split(delta, from, at) {
+				c := entity.EnergyConsumption{DeviceID: d.ID, Hour: s.Hour, Energy: s.Energy, AreaID: d.AreaID}
+				if err := entity.AddEnergyConsumption(tx, c); err != nil {
+					return err
+				}
+			}
+		}
+		return entity.SaveEnergyCounter(tx, counter)
+	})
+}
+
+// toKWh 将累计用电量换算为kWh，未设置单位时视为kWh
+func toKWh(attr thingmodel.Attribute, val interface{}) (float64, bool) {
+	f, ok := toFloat64(val)
+	if !ok || f < 0 {
+		return 0, false
+	}
+	if attr.Unit == "" {
+		return f, true
+	}
+	return thingmodel.Convert(f, attr.Unit, thingmodel.UnitKilowattHour)
+}
+
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,201 @@
+package energy
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+func TestDelta(t *testing.T) {
+	cases := []struct {
+		prev, cur, delta float64
+		ok               bool
+	}{
+		{10, 12.5, 2.5, true},
+		{10, 10, 0, true},
+		{100, 0.5, 0.5, true},      // 计量重置
+		{100, 40, 40, true},        // 下降到一半以下视为重置
+		{0.02, 0.005, 0.005, true}, // 接近0视为重置
+		{1000.2, 1000.1, 0, false}, // 小幅下降视为抖动
+		{100, 60, 0, false},
+		{0, 3, 3, true},
+	}
+	for _, c := range cases {
+		delta, ok := Delta(c.prev, c.cur)
+		assert.Equal(t, c.ok, ok, "%v -> %v", c.prev, c.cur)
+		assert.InDelta(t, c.delta, delta, 1e-9, "%v -> %v", c.prev, c.cur)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	area, err := entity.CreateArea("energy", entity.AreaOfHome)
+	require.NoError(t, err)
+	d := entity.Device{ID: 100, AreaID: area.ID}
+	ch1, ch2 := thingmodel.Energy, thingmodel.Energy
+	ch1.AID, ch2.AID = 1, 2
+	hour := time.Date(2022, 3, 1, 8, 0, 0, 0, time.Local)
+
+	// 两路插座的读数分别计算，不会相互视为重置
+	require.NoError(t, Record(d, "plug", ch1, 10.0, hour))
+	require.NoError(t, Record(d, "plug", ch2, 50.0, hour))
+	require.NoError(t, Record(d, "plug", ch1, 11.0, hour.Add(10*time.Minute)))
+	require.NoError(t, Record(d, "plug", ch2, 50.5, hour.Add(20*time.Minute)))
+
+	// 早于最后读数的上报及小幅下降的读数都忽略
+	require.NoError(t, Record(d, "plug", ch1, 10.8, hour.Add(5*time.Minute)))
+	require.NoError(t, Record(d, "plug", ch1, 10.9, hour.Add(15*time.Minute)))
+	c, err := entity.GetEnergyCounter(d.ID, "plug", ch1.AID)
+	require.NoError(t, err)
+	assert.Equal(t, 11.0, c.Reading)
+	assert.True(t, c.ReadAt.Equal(hour.Add(10*time.Minute)))
+
+	// 第一路重置后上报的读数全部计为用电量
+	require.NoError(t, Record(d, "plug", ch1, 0.5, hour.Add(30*time.Minute)))
+
+	cs, err := entity.GetEnergyConsumptions(area.ID, []int{d.ID}, hour, hour.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, cs, 1)
+	assert.InDelta(t, 2, cs[0].Energy, 1e-9)
+	c, err = entity.GetEnergyCounter(d.ID, "plug", ch1.AID)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, c.Reading)
+}
+
+func TestSplit(t *testing.T) {
+	base := time.Date(2022, 3, 1, 8, 0, 0, 0, time.Local)
+
+	slices := Split(1, base.Add(10*time.Minute), base.Add(40*time.Minute))
+	require.Len(t, slices, 1)
+	assert.Equal(t, base, slices[0].Hour)
+	assert.Equal(t, 1.0, slices[0].Energy)
+
+	slices = Split(4, base.Add(30*time.Minute), base.Add(150*time.Minute))
+	require.Len(t, slices, 3)
+	assert.Equal(t, base, slices[0].Hour)
+	assert.InDelta(t, 1, slices[0].Energy, 1e-9)
+	assert.InDelta(t, 2, slices[1].Energy, 1e-9)
+	assert.Equal(t, base.Add(2*time.Hour), slices[2].Hour)
+	assert.InDelta(t, 1, slices[2].Energy, 1e-9)
+}
+
+func TestToKWh(t *testing.T) {
+	v, ok := toKWh(thingmodel.Energy, 1.5)
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, v)
+
+	attr := thingmodel.Energy
+	attr.Unit = thingmodel.UnitWattHour
+	v, ok = toKWh(attr, 1500)
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, v)
+
+	_, ok = toKWh(thingmodel.Energy, "1.5")
+	assert.False(t, ok)
+	_, ok = toKWh(thingmodel.Energy, -1.0)
+	assert.False(t, ok)
+}
+
+func TestTariff(t *testing.T) {
+	flat := Tariff{Type: TariffFlat, Price: 0.6}
+	assert.NoError(t, flat.Validate())
+	assert.Equal(t, 0.6, flat.HourPrices()[13])
+
+	tou := Tariff{
+		Type:  TariffTimeOfUse,
+		Price: 0.6,
+		Periods: []TariffPeriod{
+			{Name: "峰", Start: "08:00", End: "11:30", Price: 1.2},
+			{Name: "谷", Start: "22:00", End: "06:00", Price: 0.3},
+		},
+	}
+	require.NoError(t, tou.Validate())
+	prices := tou.HourPrices()
+	assert.InDelta(t, 0.3, prices[23], 1e-9)
+	assert.InDelta(t, 0.3, prices[0], 1e-9)
+	assert.InDelta(t, 0.6, prices[6], 1e-9)
+	assert.InDelta(t, 1.2, prices[9], 1e-9)
+	assert.InDelta(t, 0.9, prices[11], 1e-9) // 半小时峰、半小时平
+
+	invalid := []Tariff{
+		{Type: "unknown"},
+		{Type: TariffFlat, Price: -1},
+		{Type: TariffTimeOfUse, Periods: []TariffPeriod{{Name: "峰", Start: "8点", End: "11:00"}}},
+		{Type: TariffTimeOfUse, Periods: []TariffPeriod{{Name: "峰", Start: "08:00", End: "08:00"}}},
+		{Type: TariffTimeOfUse, Periods: []TariffPeriod{
+			{Name: "峰", Start: "08:00", End: "12:00"},
+			{Name: "平", Start: "11:00", End: "14:00"},
+		}},
+	}
+	for _, tariff := range invalid {
+		assert.Error(t, tariff.Validate(), "%+v", tariff)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	start := time.Date(2022, 1, 15, 10, 20, 0, 0, time.Local)
+	end := time.Date(2022, 3, 2, 0, 0, 0, 0, time.Local)
+
+	buckets, err := IntervalMonth.Buckets(start, end)
+	require.NoError(t, err)
+	require.Len(t, buckets, 3)
+	assert.Equal(t, time.Date(2022, 1, 1, 0, 0, 0, 0, time.Local), buckets[0])
+	assert.Equal(t, time.Date(2022, 3, 1, 0, 0, 0, 0, time.Local), buckets[2])
+
+	buckets, err = IntervalDay.Buckets(start, start.AddDate(0, 0, 2))
+	require.NoError(t, err)
+	assert.Len(t, buckets, 3)
+
+	_, err = IntervalHour.Buckets(start, start.AddDate(1, 0, 0))
+	assert.Error(t, err)
+}
+
+func TestBuildReport(t *testing.T) {
+	day := time.Date(2022, 3, 1, 0, 0, 0, 0, time.Local)
+	q := Query{StartAt: day, EndAt: day.AddDate(0, 0, 2), Interval: IntervalDay, GroupBy: GroupByDevice}
+	buckets, err := q.Interval.Buckets(q.StartAt, q.EndAt)
+	require.NoError(t, err)
+	tariff := Tariff{
+		Type:     TariffTimeOfUse,
+		Currency: "CNY",
+		Price:    0.5,
+		Periods:  []TariffPeriod{{Name: "峰", Start: "08:00", End: "20:00", Price: 1}},
+	}
+	group := func(c entity.EnergyConsumption) (int, string) {
+		return c.DeviceID, "device"
+	}
+	cs := []entity.EnergyConsumption{
+		{DeviceID: 1, Hour: day.Add(2 * time.Hour), Energy: 1},
+		{DeviceID: 1, Hour: day.Add(9 * time.Hour), Energy: 1},
+		{DeviceID: 2, Hour: day.Add(33 * time.Hour), Energy: 3},
+	}
+
+	r := buildReport(q, buckets, tariff, group, cs)
+	assert.Equal(t, 5.0, r.Energy)
+	assert.Equal(t, 4.5, r.Cost)
+	assert.Equal(t, "CNY", r.Currency)
+	require.Len(t, r.Series, 2)
+
+	// 按用电量倒序
+	assert.Equal(t, 2, r.Series[0].ID)
+	assert.Equal(t, []Point{
+		{Time: day.Unix(), Energy: 0, Cost: 0},
+		{Time: day.AddDate(0, 0, 1).Unix(), Energy: 3, Cost: 3},
+	}, r.Series[0].Points)
+	assert.Equal(t, 1, r.Series[1].ID)
+	assert.Equal(t, 2.0, r.Series[1].Points[0].Energy)
+	assert.Equal(t, 1.5, r.Series[1].Points[0].Cost)
+}
@@ -0,0 +1,277 @@
+package energy
+
+import (
+	"sort"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// Interval 统计的时间粒度
+type Interval string
+
+const (
+	IntervalHour  Interval = "hour"
+	IntervalDay   Interval = "day"
+	IntervalMonth Interval = "month"
+)
+
+// GroupBy 统计的分组方式
+type GroupBy string
+
+const (
+	GroupByDevice   GroupBy = "device"
+	GroupByLocation GroupBy = "location" // 家庭按房间，公司按部门
+	GroupByArea     GroupBy = "area"
+)
+
+// maxPoints 单次统计的最大时间点数量
+const maxPoints = 1000
+
+// Query 能耗统计条件
+type Query struct {
+	AreaID    uint64
+	DeviceIDs []int // 为nil则统计家庭所有设备，包括已删除设备的历史用电量
+	StartAt   time.Time
+	EndAt     time.Time
+	Interval  Interval
+	GroupBy   GroupBy
+}
+
+// Point 某个时间段内的用电量及电费
+type Point struct {
+	Time   int64   `json:"time"` // 时间段的开始时间戳（秒）
+	Energy float64 `json:"energy"`
+	Cost   float64 `json:"cost"`
+}
+
+// Series 某个分组的用电量统计
+type Series struct {
+	ID     int     `json:"id"` // 设备、房间或部门的id，家庭为0，房间（部门）不存在时为0
+	Name   string  `json:"name"`
+	Energy float64 `json:"energy"`
+	Cost   float64 `json:"cost"`
+	Points []Point `json:"points"`
+}
+
+// Report 能耗统计结果
+type Report struct {
+	Interval Interval        `json:"interval"`
+	GroupBy  GroupBy         `json:"group_by"`
+	Unit     thingmodel.Unit `json:"unit"`
+	Currency string          `json:"currency"`
+	Energy   float64         `json:"energy"`
+	Cost     float64         `json:"cost"`
+	Series   []Series        `json:"series"`
+}
+
+// bucketStart 返回t所在时间段的开始时间，按SA所在时区划分天和月
+func (i Interval) bucketStart(t time.Time) time.Time {
+	t = t.In(time.Local)
+	switch i {
+	case IntervalDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	case IntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+	default:
+		return t.Truncate(time.Hour)
+	}
+}
+
+func (i Interval) next(t time.Time) time.Time {
+	switch i {
+	case IntervalDay:
+		return t.AddDate(0, 0, 1)
+	case IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.Add(time.Hour)
+	}
+}
+
+// Buckets 返回[startAt, endAt)内各时间段的开始时间
+func (i Interval) Buckets(startAt, endAt time.Time) (buckets []time.Time, err error) {
+	for t := i.bucketStart(startAt); t.Before(endAt); t = i.next(t) {
+		if len(buckets) >= maxPoints {
+			return nil, errors.New(status.EnergyRangeTooLarge)
+		}
+		buckets = append(buckets, t)
+	}
+	return
+}
+
+// Validate 校验统计条件
+func (q Query) Validate() error {
+	switch q.Interval {
+	case IntervalHour, IntervalDay, IntervalMonth:
+	default:
+		return errors.Newf(status.EnergyParamErr, "interval")
+	}
+	switch q.GroupBy {
+	case GroupByDevice, GroupByLocation, GroupByArea:
+	default:
+		return errors.Newf(status.EnergyParamErr, "group_by")
+	}
+	if !q.StartAt.Before(q.EndAt) {
+		return errors.Newf(status.EnergyParamErr, "start_at")
+	}
+	return nil
+}
+
+// grouper 返回每小时用电量所属的分组
+type grouper func(c entity.EnergyConsumption) (id int, name string)
+
+func newGrouper(q Query) (g grouper, err error) {
+	if q.GroupBy == GroupByArea {
+		area, err := entity.GetAreaByID(q.AreaID)
+		if err != nil {
+			return nil, err
+		}
+		return func(entity.EnergyConsumption) (int, string) {
+			return 0, area.Name
+		}, nil
+	}
+
+	devices, err := entity.GetDevices(q.AreaID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.InternalServerErr)
+	}
+	deviceMap := make(map[int]entity.Device)
+	for _, d := range devices {
+		deviceMap[d.ID] = d
+	}
+	if q.GroupBy == GroupByDevice {
+		return func(c entity.EnergyConsumption) (int, string) {
+			return c.DeviceID, deviceMap[c.DeviceID].Name
+		}, nil
+	}
+
+	area, err := entity.GetAreaByID(q.AreaID)
+	if err != nil {
+		return
+	}
+	names := make(map[int]string)
+	company := entity.IsCompany(area.AreaType)
+	if company {
+		var departments []entity.Department
+		if departments, err = entity.GetDepartments(q.AreaID); err != nil {
+			return nil, errors.Wrap(err, errors.InternalServerErr)
+		}
+		for _, d := range departments {
+			names[d.ID] = d.Name
+		}
+	} else {
+		var locations []entity.Location
+		if locations, err = entity.GetLocations(q.AreaID); err != nil {
+			return nil, errors.Wrap(err, errors.InternalServerErr)
+		}
+		for _, l := range locations {
+			names[l.ID] = l.Name
+		}
+	}
+	return func(c entity.EnergyConsumption) (int, string) {
+		d := deviceMap[c.DeviceID]
+		id := d.LocationID
+		if company {
+			id = d.DepartmentID
+		}
+		if _, ok := names[id]; !ok {
+			return 0, ""
+		}
+		return id, names[id]
+	}, nil
+}
+
+// GetReport 按时间粒度及分组统计用电量，并按家庭的电价估算电费
+func GetReport(q Query) (r Report, err error) {
+	if err = q.Validate(); err != nil {
+		return
+	}
+	// 统计完整的时间段
+	q.StartAt = q.Interval.bucketStart(q.StartAt)
+	buckets, err := q.Interval.Buckets(q.StartAt, q.EndAt)
+	if err != nil {
+		return
+	}
+	tariff, err := GetTariff(q.AreaID)
+	if err != nil {
+		return
+	}
+	group, err := newGrouper(q)
+	if err != nil {
+		return
+	}
+	cs, err := entity.GetEnergyConsumptions(q.AreaID, q.DeviceIDs, q.StartAt, q.EndAt)
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	return buildReport(q, buckets, tariff, group, cs), nil
+}
+
+func buildReport(q Query, buckets []time.Time, tariff Tariff, group grouper, cs []entity.EnergyConsumption) Report {
+	r := Report{
+		Interval: q.Interval,
+		GroupBy:  q.GroupBy,
+		Unit:     thingmodel.UnitKilowattHour,
+		Currency: tariff.Currency,
+		Series:   make([]Series, 0),
+	}
+	index := make(map[int64]int, len(buckets))
+	for i, b := range buckets {
+		index[b.Unix()] = i
+	}
+	prices := tariff.HourPrices()
+
+	seriesIndex := make(map[int]int)
+	for _, c := range cs {
+		i, ok := index[q.Interval.bucketStart(c.Hour).Unix()]
+		if !ok {
+			continue
+		}
+		id, name := group(c)
+		si, ok := seriesIndex[id]
+		if !ok {
+			s := Series{ID: id, Name: name, Points: make([]Point, len(buckets))}
+			for j, b := range buckets {
+				s.Points[j].Time = b.Unix()
+			}
+			si = len(r.Series)
+			seriesIndex[id] = si
+			r.Series = append(r.Series, s)
+		}
+		cost := c.Energy * prices[c.Hour.In(time.Local).Hour()]
+		s := &r.Series[si]
+		s.Points[i].Energy += c.Energy
+		s.Points[i].Cost += cost
+		s.Energy += c.Energy
+		s.Cost += cost
+		r.Energy += c.Energy
+		r.Cost += cost
+	}
+
+	for i := range r.Series {
+		s := &r.Series[i]
+		for j := range s.Points {
+			s.Points[j].Energy = roundEnergy(s.Points[j].Energy)
+			s.Points[j].Cost = roundCost(s.Points[j].Cost)
+		}
+		s.Energy, s.Cost = roundEnergy(s.Energy), roundCost(s.Cost)
+	}
+	r.Energy, r.Cost = roundEnergy(r.Energy), roundCost(r.Cost)
+	sort.SliceStable(r.Series, func(i, j int) bool {
+		return r.Series[i].Energy > r.Series[j].Energy
+	})
+	return r
+}
+
+func roundEnergy(v float64) float64 {
+	return thingmodel.Round(v, 3)
+}
+
+func roundCost(v float64) float64 {
+	return thingmodel.Round(v, 2)
+}
@@ -0,0 +1,139 @@
+package energy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// TariffType 电价类型
+type TariffType string
+
+const (
+	TariffFlat      TariffType = "flat"        // 固定电价
+	TariffTimeOfUse TariffType = "time_of_use" // 分时电价
+)
+
+const minutesPerDay = 24 * 60
+
+// Tariff 电价，用于估算电费
+type Tariff struct {
+	Type     TariffType     `json:"type"`
+	Currency string         `json:"currency"`          // 货币，如CNY
+	Price    float64        `json:"price"`             // 每kWh单价，分时电价中未覆盖的时段也使用该单价
+	Periods  []TariffPeriod `json:"periods,omitempty"` // 分时电价的时段
+}
+
+// TariffPeriod 分时电价的时段，使用SA所在时区的时间，结束时间早于开始时间表示跨越零点
+type TariffPeriod struct {
+	Name  string  `json:"name"`  // 如峰、谷、平
+	Start string  `json:"start"` // 开始时间，格式为 15:04
+	End   string  `json:"end"`   // 结束时间，格式为 15:04
+	Price float64 `json:"price"` // 每kWh单价
+}
+
+// DefaultTariff 未设置电价时使用，电费为0
+var DefaultTariff = Tariff{Type: TariffFlat, Currency: "CNY"}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// minutePrices 返回一天中每分钟的单价
+func (t Tariff) minutePrices() (prices [minutesPerDay]float64, err error) {
+	for i := range prices {
+		prices[i] = t.Price
+	}
+	if t.Type != TariffTimeOfUse {
+		return
+	}
+	var covered [minutesPerDay]bool
+	for _, p := range t.Periods {
+		var start, end int
+		if start, err = parseClock(p.Start); err != nil {
+			return prices, fmt.Errorf("时段%s的开始时间格式不正确", p.Name)
+		}
+		if end, err = parseClock(p.End); err != nil {
+			return prices, fmt.Errorf("时段%s的结束时间格式不正确", p.Name)
+		}
+		if start == end {
+			return prices, fmt.Errorf("时段%s的开始时间与结束时间相同", p.Name)
+		}
+		for m := start; m != end; m = (m + 1) % minutesPerDay {
+			if covered[m] {
+				return prices, fmt.Errorf("时段%s与其他时段重叠", p.Name)
+			}
+			covered[m] = true
+			prices[m] = p.Price
+		}
+	}
+	return
+}
+
+// Validate 校验电价设置
+func (t Tariff) Validate() error {
+	if t.Type != TariffFlat && t.Type != TariffTimeOfUse {
+		return errors.Newf(status.EnergyTariffIncorrect, "type")
+	}
+	if t.Price < 0 {
+		return errors.Newf(status.EnergyTariffIncorrect, "price")
+	}
+	for _, p := range t.Periods {
+		if p.Price < 0 {
+			return errors.Newf(status.EnergyTariffIncorrect, "price")
+		}
+	}
+	if _, err := t.minutePrices(); err != nil {
+		return errors.Newf(status.EnergyTariffIncorrect, err.Error())
+	}
+	return nil
+}
+
+// HourPrices 返回一天中每个小时的平均单价，时段不是整点时按分钟加权
+func (t Tariff) HourPrices() (prices [24]float64) {
+	minutes, err := t.minutePrices()
+	if err != nil {
+		for i := range prices {
+			prices[i] = t.Price
+		}
+		return
+	}
+	for m, p := range minutes {
+		prices[m/60] += p
+	}
+	for h := range prices {
+		prices[h] = thingmodel.Round(prices[h]/60, 6)
+	}
+	return
+}
+
+// GetTariff 获取家庭的电价设置，未设置时返回 DefaultTariff
+func GetTariff(areaID uint64) (t Tariff, err error) {
+	t = DefaultTariff
+	if err = entity.GetSetting(entity.EnergyTariffSetting, &t, areaID); err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+	}
+	return
+}
+
+// SaveTariff 保存家庭的电价设置
+func SaveTariff(areaID uint64, t Tariff) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if t.Type == TariffFlat {
+		t.Periods = nil
+	}
+	if err := entity.UpdateSetting(entity.EnergyTariffSetting, t, areaID); err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	return nil
+}
@@ -7,78 +7,53 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestCreateArea(t *testing.T) {
+func TestArea(t *testing.T) {
 	ast := assert.New(t)
 
+	count, err := GetAreaCount()
+	ast.NoError(err, "get area count error: %v", err)
+
+	var areas []Area
 	for i := 1; i < 11; i++ {
-		area := Area{
-			Name: "testArea" + strconv.Itoa(i),
-			UUID: strconv.Itoa(i),
-		}
-		err := CreateArea(&area)
+		area, err := CreateArea("testArea"+strconv.Itoa(i), AreaOfHome)
 		ast.NoError(err, "create area error: %v", err)
+		areas = append(areas, area)
 	}
 
-	area := Area{
-		Name: "testArea1",
-	}
-	err := CreateArea(&area)
-	ast.Error(err, "create area error")
-}
-
-func TestGetAreaByID(t *testing.T) {
-	ast := assert.New(t)
-
-	for i := 1; i < 11; i++ {
-		area, err := GetAreaByID(strconv.Itoa(i))
+	for _, area := range areas {
+		a, err := GetAreaByID(area.ID)
 		ast.NoError(err, "get area error: %v", err)
-		ast.NotEmpty(area)
+		ast.Equal(area.Name, a.Name)
 	}
-}
-
-func TestGetAreaCount(t *testing.T) {
-	ast := assert.New(t)
+	const notExistID = 999
+	_, err = GetAreaByID(notExistID)
+	ast.Error(err, "get area error")
 
-	const correctCount int64 = 10
-	count, err := GetAreaCount()
+	newCount, err := GetAreaCount()
 	ast.NoError(err, "get area count error: %v", err)
-	ast.Equal(correctCount, count)
-}
+	ast.Equal(count+10, newCount)
 
-func TestGetAreas(t *testing.T) {
-	ast := assert.New(t)
-
-	const correctCount = 10
-
-	areas, err := GetAreas()
+	all, err := GetAreas()
 	ast.NoError(err, "get areas error: %v", err)
-	ast.Equal(len(areas), correctCount)
-}
-
-func TestUpdateArea(t *testing.T) {
-	ast := assert.New(t)
+	ast.Len(all, int(newCount))
 
 	const newName = "new"
-	const existID = "1"
-	const notExistID = "999"
-
-	err := UpdateArea(existID, newName)
+	err = UpdateArea(areas[0].ID, map[string]interface{}{"name": newName})
 	ast.NoError(err, "update area error: %v", err)
+	area, _ := GetAreaByID(areas[0].ID)
+	ast.Equal(newName, area.Name)
 
-	err = UpdateArea(notExistID, newName)
+	err = UpdateArea(notExistID, map[string]interface{}{"name": newName})
 	ast.Error(err, "update area error")
-}
 
-func TestDelAreaByID(t *testing.T) {
-	ast := assert.New(t)
-
-	const notExistID = "999"
-
-	for i := 1; i < 11; i++ {
-		err := DelAreaByID(strconv.Itoa(i))
+	for _, area := range areas {
+		err := DelAreaByID(area.ID)
 		ast.NoError(err, "delete area error: %v", err)
 	}
-
-	err := DelAreaByID(notExistID)
+	err = DelAreaByID(notExistID)
 	ast.NoError(err, "delete area error")
+
+	newCount, err = GetAreaCount()
+	ast.NoError(err, "get area count error: %v", err)
+	ast.Equal(count, newCount)
 }
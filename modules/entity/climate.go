@@ -0,0 +1,146 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// ClimateSchedule 温控计划，按周设置温控器的目标温度，可设置到设备或房间（公司为部门）
+type ClimateSchedule struct {
+	ID         int             `json:"id"`
+	Name       string          `json:"name"`
+	DeviceID   int             `json:"device_id"`   // 设备的计划，与location_id二选一
+	LocationID int             `json:"location_id"` // 房间（公司为部门）的计划，应用到其中所有没有设备计划的温控器
+	Enabled    bool            `json:"enabled"`
+	Setpoints  datatypes.JSON  `json:"setpoints"` // refer to climate.Setpoint
+	Unit       thingmodel.Unit `json:"unit"`      // 计划中温度的单位
+	CreatorID  int             `json:"creator_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	AreaID     uint64          `json:"-" gorm:"type:bigint;index"`
+	Area       Area            `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (s ClimateSchedule) TableName() string {
+	return "climate_schedules"
+}
+
+// ClimateOverride 临时覆盖温控计划，如提温（boost）、离家（away），到期后恢复计划
+type ClimateOverride struct {
+	ID                int             `json:"id"`
+	Type              string          `json:"type"`
+	DeviceID          int             `json:"device_id"`
+	LocationID        int             `json:"location_id"`
+	TargetTemperature *float64        `json:"target_temperature,omitempty"`
+	HVACMode          string          `json:"hvac_mode,omitempty"`
+	Unit              thingmodel.Unit `json:"unit"`
+	ExpiresAt         time.Time       `json:"expires_at"`
+	CreatorID         int             `json:"creator_id"`
+	CreatedAt         time.Time       `json:"created_at"`
+	AreaID            uint64          `json:"-" gorm:"type:bigint;index"`
+	Area              Area            `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (o ClimateOverride) TableName() string {
+	return "climate_overrides"
+}
+
+// ClimateRestore 覆盖生效前温控器的设定值，覆盖结束且没有计划时恢复
+type ClimateRestore struct {
+	DeviceID          int             `json:"device_id" gorm:"primaryKey;autoIncrement:false"`
+	TargetTemperature *float64        `json:"target_temperature,omitempty"`
+	HVACMode          string          `json:"hvac_mode,omitempty"`
+	Unit              thingmodel.Unit `json:"unit"` // 设备的单位
+	AreaID            uint64          `json:"-" gorm:"type:bigint;index"`
+	Area              Area            `json:"-" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (r ClimateRestore) TableName() string {
+	return "climate_restores"
+}
+
+// GetClimateSchedules 获取家庭的所有温控计划
+func GetClimateSchedules(areaID uint64) (schedules []ClimateSchedule, err error) {
+	err = GetDBWithAreaScope(areaID).Order("id").Find(&schedules).Error
+	return
+}
+
+// GetAllClimateSchedules 获取所有家庭已启用的温控计划
+func GetAllClimateSchedules() (schedules []ClimateSchedule, err error) {
+	err = GetDB().Where("enabled = ?", true).Find(&schedules).Error
+	return
+}
+
+// GetClimateScheduleByID 获取温控计划
+func GetClimateScheduleByID(id int, areaID uint64) (schedule ClimateSchedule, err error) {
+	err = GetDBWithAreaScope(areaID).Where("id = ?", id).First(&schedule).Error
+	return
+}
+
+// CreateClimateSchedule 创建温控计划
+func CreateClimateSchedule(schedule *ClimateSchedule) error {
+	return GetDB().Create(schedule).Error
+}
+
+// SaveClimateSchedule 修改温控计划
+func SaveClimateSchedule(schedule *ClimateSchedule) error {
+	return GetDB().Select("*").Omit("area", "created_at").Save(schedule).Error
+}
+
+// DeleteClimateSchedule 删除温控计划
+func DeleteClimateSchedule(id int, areaID uint64) error {
+	return GetDBWithAreaScope(areaID).Where("id = ?", id).Delete(&ClimateSchedule{}).Error
+}
+
+// GetClimateOverrides 获取家庭未过期的温控覆盖
+func GetClimateOverrides(areaID uint64) (overrides []ClimateOverride, err error) {
+	err = GetDBWithAreaScope(areaID).Where("expires_at > ?", time.Now()).
+		Order("id").Find(&overrides).Error
+	return
+}
+
+// GetAllClimateOverrides 获取所有家庭未过期的温控覆盖
+func GetAllClimateOverrides() (overrides []ClimateOverride, err error) {
+	err = GetDB().Where("expires_at > ?", time.Now()).Find(&overrides).Error
+	return
+}
+
+// GetClimateOverrideByID 获取温控覆盖
+func GetClimateOverrideByID(id int, areaID uint64) (override ClimateOverride, err error) {
+	err = GetDBWithAreaScope(areaID).Where("id = ?", id).First(&override).Error
+	return
+}
+
+// CreateClimateOverride 创建温控覆盖，同一设备或房间只保留最新的覆盖，同时清理已过期的覆盖
+func CreateClimateOverride(override *ClimateOverride) error {
+	db := GetDBWithAreaScope(override.AreaID)
+	if err := db.Where("device_id = ? and location_id = ?", override.DeviceID, override.LocationID).
+		Or("expires_at <= ?", time.Now()).Delete(&ClimateOverride{}).Error; err != nil {
+		return err
+	}
+	return GetDB().Create(override).Error
+}
+
+// DeleteClimateOverride 删除温控覆盖
+func DeleteClimateOverride(id int, areaID uint64) error {
+	return GetDBWithAreaScope(areaID).Where("id = ?", id).Delete(&ClimateOverride{}).Error
+}
+
+// GetClimateRestore 获取温控器覆盖生效前的设定值
+func GetClimateRestore(deviceID int) (restore ClimateRestore, err error) {
+	err = GetDB().Where("device_id = ?", deviceID).First(&restore).Error
+	return
+}
+
+// CreateClimateRestore 记录温控器覆盖生效前的设定值，已有记录时不修改，以免替换覆盖时记录上一个覆盖的设定值
+func CreateClimateRestore(restore ClimateRestore) error {
+	return GetDB().Clauses(clause.OnConflict{DoNothing: true}).Create(&restore).Error
+}
+
+// DeleteClimateRestore 删除温控器覆盖生效前的设定值
+func DeleteClimateRestore(deviceID int) error {
+	return GetDB().Where("device_id = ?", deviceID).Delete(&ClimateRestore{}).Error
+}
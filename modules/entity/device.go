@@ -66,7 +66,17 @@ func (d Device) IsSa() bool {
 func (d *Device) AfterDelete(tx *gorm.DB) (err error) {
 	// 删除设备所有相关权限
 	target := types.DeviceTarget(d.ID)
-	return tx.Delete(&RolePermission{}, "target = ?", target).Error
+	if err = tx.Delete(&RolePermission{}, "target = ?", target).Error; err != nil {
+		return
+	}
+	// 删除设备的温控计划及覆盖
+	if err = tx.Delete(&ClimateSchedule{}, "device_id = ?", d.ID).Error; err != nil {
+		return
+	}
+	if err = tx.Delete(&ClimateOverride{}, "device_id = ?", d.ID).Error; err != nil {
+		return
+	}
+	return tx.Delete(&ClimateRestore{}, "device_id = ?", d.ID).Error
 }
 
 func (d *Device) BeforeCreate(tx *gorm.DB) (err error) {
@@ -556,6 +566,26 @@ func (d Device) IsControllable(up UserPermissions) bool {
 	return len(attributes) != 0
 }
 
+// Actions 获取设备的所有动作
+func (d Device) Actions() (actions []thingmodel.Action, err error) {
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return
+	}
+
+	if len(tm.Instances) == 0 {
+		return
+	}
+	ins, err := tm.PrimaryInstance()
+	if err != nil {
+		return
+	}
+	for _, srv := range ins.Services {
+		actions = append(actions, srv.Actions...)
+	}
+	return
+}
+
 // ControlAttributes 获取设备的属性（有写的权限）
 func (d Device) ControlAttributes(withHidden bool) (attributes []Attribute, err error) {
 	tm, err := d.GetThingModel()
@@ -612,7 +642,7 @@ func (d Device) GetThingModel() (tm thingmodel.ThingModel, err error) {
 	return
 }
 
-// GetThingModelWithState 获取并包装物模型：更新值，更新权限
+// GetThingModelWithState 获取并包装物模型：更新值，更新权限，按用户的单位制换算单位
 func (d Device) GetThingModelWithState(up UserPermissions) (tm thingmodel.ThingModel, err error) {
 
 	tm, err = d.GetThingModel()
@@ -649,5 +679,6 @@ func (d Device) GetThingModelWithState(up UserPermissions) (tm thingmodel.ThingM
 			}
 		}
 	}
+	tm = tm.InSystem(up.UnitSystem())
 	return
 }
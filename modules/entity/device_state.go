@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DeviceState struct {
@@ -57,3 +59,33 @@ func GetPluginDeviceStates(pluginID, iid string) (states []DeviceState, err erro
 	err = GetDB().Where(DeviceState{PluginID: pluginID, IID: iid}).Find(&states).Error
 	return
 }
+
+// DeviceStateFilter 设备状态查询条件
+type DeviceStateFilter struct {
+	DeviceIDs []int
+	AttrTypes []string // 属性类型，为空则不过滤
+	StartAt   *time.Time
+	EndAt     *time.Time
+}
+
+// RangeDeviceStates 按id顺序分批遍历设备状态，避免一次性加载到内存
+func RangeDeviceStates(filter DeviceStateFilter, batchSize int, fn func(states []DeviceState) error) (err error) {
+	var states []DeviceState
+	db := GetDB().Where("device_id in ?", filter.DeviceIDs)
+	if len(filter.AttrTypes) != 0 {
+		exprs := make([]clause.Expression, 0, len(filter.AttrTypes))
+		for _, attrType := range filter.AttrTypes {
+			exprs = append(exprs, datatypes.JSONQuery("state").Equals(attrType, "type"))
+		}
+		db = db.Clauses(clause.Where{Exprs: []clause.Expression{clause.Or(exprs...)}})
+	}
+	if filter.StartAt != nil {
+		db = db.Where("created_at >= ?", *filter.StartAt)
+	}
+	if filter.EndAt != nil {
+		db = db.Where("created_at < ?", *filter.EndAt)
+	}
+	return db.FindInBatches(&states, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(states)
+	}).Error
+}
@@ -4,147 +4,122 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/zhiting-tech/smartassistant/modules/types"
 )
 
-func TestAddDevice(t *testing.T) {
+func TestDevice(t *testing.T) {
 	ast := assert.New(t)
 
+	area, err := CreateArea("device", AreaOfHome)
+	require.NoError(t, err)
+	location := Location{Name: "device", AreaID: area.ID}
+	require.NoError(t, CreateLocation(&location))
+
 	var testSADevice = Device{
-		ID:         1,
 		IID:        "666",
 		Model:      types.SaModel,
 		PluginID:   "1",
-		LocationID: 1,
-		OwnerID:    1,
+		LocationID: location.ID,
+		AreaID:     area.ID,
 	}
 
 	var testDevice2 = Device{
-		ID:         2,
 		IID:        "678",
-		LocationID: 1,
-		OwnerID:    2,
+		PluginID:   "1",
+		LocationID: location.ID,
+		AreaID:     area.ID,
 	}
 
-	var errorIIDDevice = Device{
-		ID:         3,
-		IID:        "666",
-		LocationID: 1,
-		OwnerID:    2,
-	}
+	// 添加SA设备
+	err = CheckSAExist(testSADevice, GetDB())
+	ast.NoError(err, "check sa exist error: %v", err)
+	err = CreateSA(&testSADevice, GetDB())
+	ast.NoError(err, "add sa device error: %v", err)
+	ast.True(IsOwnerOfArea(areaOwnerID(t, area.ID), area.ID), "is sa owner error")
+	ast.False(IsOwnerOfArea(0, area.ID), "is sa owner error")
 
-	err := CreateDevice(&testSADevice, GetDB())
-	ast.NoError(err, "add device error: %v", err)
+	err = CreateSA(&testSADevice, GetDB())
+	ast.Error(err, "add exist sa device error")
+	err = CheckSAExist(testSADevice, GetDB())
+	ast.Error(err, "check sa exist error")
 
 	err = CreateDevice(&testDevice2, GetDB())
 	ast.NoError(err, "add device error: %v", err)
 
-	err = CreateDevice(&testSADevice, GetDB())
-	ast.Error(err, "add exist device error")
-
-	err = CreateDevice(&errorIIDDevice, GetDB())
-	ast.Error(err, "add error device error")
-}
-
-func TestCheckSaDeviceCreator(t *testing.T) {
-	ast := assert.New(t)
-
-	err := CheckSaDeviceCreator(1)
-	ast.NoError(err, "check sa device creator error: %v", err)
-	// err = CheckSaDeviceCreator(2)
-	// ast.Error(err, "check sa device creator error")
-}
-
-func TestIsSAOwner(t *testing.T) {
-	ast := assert.New(t)
-
-	ok := IsOwner(1)
-	ast.True(ok, "is Sa Creator error")
-
-	ok = IsOwner(2)
-	ast.False(ok, "is Sa Creator error")
-}
-
-func TestGetDevice(t *testing.T) {
-	ast := assert.New(t)
+	// 相同的iid重复添加时更新设备
+	id := testDevice2.ID
+	testDevice2.Name = "updated"
+	err = CreateDevice(&testDevice2, GetDB())
+	ast.NoError(err, "add exist device error: %v", err)
+	ast.Equal(id, testDevice2.ID)
 
-	const noExistDeviceID = 999
+	const noExistDeviceID = 999999
 	const noExistIID = "999"
 
-	devices, err := GetDevices()
+	devices, err := GetDevices(area.ID)
 	ast.NoError(err, "get devices error: %v", err)
-	ast.NotEmpty(devices)
+	ast.Len(devices, 2)
 
 	device, err := GetSaDevice()
 	ast.NoError(err, "get sa device error: %v", err)
 	ast.NotEmpty(device)
 
-	device, err = GetDeviceByID(1)
+	device, err = GetDeviceByID(testSADevice.ID)
 	ast.NoError(err, "get device by id error: %v", err)
 	ast.NotEmpty(device)
 	device, err = GetDeviceByID(noExistDeviceID)
 	ast.Error(err, "get device by id error")
 	ast.Empty(device)
 
-	devices, err = GetDevicesByLocationID(1)
+	devices, err = GetDevicesByLocationID(location.ID)
 	ast.NoError(err, "get devices by location id: %v", err)
-	ast.NotEmpty(devices)
+	ast.Len(devices, 2)
 
-	device, err = GetPluginDevice("666")
-	ast.NoError(err, "get device by id error: %v", err)
+	device, err = GetPluginDevice(area.ID, "1", "666")
+	ast.NoError(err, "get plugin device error: %v", err)
 	ast.NotEmpty(device)
-	device, err = GetPluginDevice(noExistIID)
-	ast.Error(err, "get device by id error")
+	device, err = GetPluginDevice(area.ID, "1", noExistIID)
+	ast.Error(err, "get plugin device error")
 	ast.Empty(device)
-}
-
-func TestUpdateDevice(t *testing.T) {
-	ast := assert.New(t)
-
-	const noExistDeviceID = 999
-	const newOwnerID = 2
-
-	var updateDevice = Device{
-		ID:         2,
-		IID:        "678",
-		LocationID: 1,
-		OwnerID:    newOwnerID,
-	}
 
-	err := UpdateDevice(updateDevice.ID, updateDevice)
+	// 修改设备
+	const newName = "new"
+	err = UpdateDevice(testDevice2.ID, Device{Name: newName})
 	ast.NoError(err, "update device error: %v", err)
-	device, _ := GetDeviceByID(updateDevice.ID)
-	ast.Equal(device.OwnerID, newOwnerID)
+	device, _ = GetDeviceByID(testDevice2.ID)
+	ast.Equal(newName, device.Name)
 
-	err = UpdateDevice(noExistDeviceID, updateDevice)
+	err = UpdateDevice(noExistDeviceID, Device{Name: newName})
 	ast.Error(err, "update device error")
-}
 
-func TestUnBindLocationDevice(t *testing.T) {
-	ast := assert.New(t)
-
-	err := UnBindLocationDevice(1)
+	// 解绑房间
+	err = UnBindLocationDevice(testSADevice.ID)
 	ast.NoError(err, "unbind location device error: %v", err)
-	device, _ := GetDeviceByID(1)
-	ast.Equal(device.LocationID, 0)
+	device, _ = GetDeviceByID(testSADevice.ID)
+	ast.Equal(0, device.LocationID)
 
-	err = UnBindLocationDevices(1)
+	err = UnBindLocationDevices(location.ID)
 	ast.NoError(err, "unbind location device error: %v", err)
-	device, _ = GetDeviceByID(2)
-	ast.Equal(device.LocationID, 0)
-}
+	device, _ = GetDeviceByID(testDevice2.ID)
+	ast.Equal(0, device.LocationID)
 
-func TestDelDevice(t *testing.T) {
-	ast := assert.New(t)
-
-	err := DelDeviceByID(2)
+	// 删除设备
+	err = DelDeviceByID(testDevice2.ID)
 	ast.NoError(err, "delete device by id error: %v", err)
-	device, _ := GetDeviceByID(2)
+	device, _ = GetDeviceByID(testDevice2.ID)
 	ast.Empty(device)
 
-	err = DelDevicesByPlgID("1")
-	ast.NoError(err, "delete device by plugin id error: %v", err)
-	device, _ = GetDeviceByID(1)
+	err = DelDeviceByIID(area.ID, "1", "666")
+	ast.NoError(err, "delete device by iid error: %v", err)
+	device, _ = GetDeviceByID(testSADevice.ID)
 	ast.Empty(device)
 }
+
+// areaOwnerID 获取家庭拥有者的用户id
+func areaOwnerID(t *testing.T, areaID uint64) int {
+	area, err := GetAreaByID(areaID)
+	require.NoError(t, err)
+	return area.OwnerID
+}
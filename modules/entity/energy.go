@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnergyTariffSetting 电价配置类型，值为 energy.Tariff
+const EnergyTariffSetting = "energy_tariff"
+
+// EnergyCounter 设备累计用电量属性的最后读数，用于计算两次上报之间的用电量，多路设备每路分别记录
+type EnergyCounter struct {
+	DeviceID int       `gorm:"primaryKey;autoIncrement:false"`
+	IID      string    `gorm:"column:iid;primaryKey"`
+	AID      int       `gorm:"column:aid;primaryKey;autoIncrement:false"`
+	Reading  float64   // 最后读数（kWh）
+	ReadAt   time.Time // 最后上报时间
+	AreaID   uint64    `gorm:"type:bigint;index"`
+	Area     Area      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (c EnergyCounter) TableName() string {
+	return "energy_counters"
+}
+
+// EnergyConsumption 设备每小时的用电量
+type EnergyConsumption struct {
+	ID       int
+	DeviceID int       `gorm:"uniqueIndex:device_id_hour"`
+	Hour     time.Time `gorm:"uniqueIndex:device_id_hour"` // 整点时间
+	Energy   float64   // 用电量（kWh）
+	AreaID   uint64    `gorm:"type:bigint;index"`
+	Area     Area      `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+func (c EnergyConsumption) TableName() string {
+	return "energy_consumptions"
+}
+
+// GetEnergyCounter 获取设备累计用电量属性的最后读数
+func GetEnergyCounter(deviceID int, iid string, aid int) (c EnergyCounter, err error) {
+	err = GetDB().Where("device_id = ? and iid = ? and aid = ?", deviceID, iid, aid).First(&c).Error
+	return
+}
+
+// SaveEnergyCounter 保存设备累计用电量属性的最后读数
+func SaveEnergyCounter(tx *gorm.DB, c EnergyCounter) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "device_id"}, {Name: "iid"}, {Name: "aid"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reading", "read_at"}),
+	}).Create(&c).Error
+}
+
+// AddEnergyConsumption 累加设备在整点时间内的用电量
+func AddEnergyConsumption(tx *gorm.DB, c EnergyConsumption) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "device_id"}, {Name: "hour"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"energy": gorm.Expr("energy_consumptions.energy + excluded.energy"),
+		}),
+	}).Create(&c).Error
+}
+
+// GetEnergyConsumptions 获取设备在[startAt, endAt)内每小时的用电量，deviceIDs为nil则获取家庭所有设备
+func GetEnergyConsumptions(areaID uint64, deviceIDs []int, startAt, endAt time.Time) (cs []EnergyConsumption, err error) {
+	db := GetDBWithAreaScope(areaID).Where("hour >= ? and hour < ?", startAt, endAt)
+	if deviceIDs != nil {
+		db = db.Where("device_id in ?", deviceIDs)
+	}
+	err = db.Order("hour").Find(&cs).Error
+	return
+}
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddEnergyConsumption(t *testing.T) {
+	area, err := CreateArea("energy", AreaOfHome)
+	require.NoError(t, err)
+
+	hour := time.Date(2022, 3, 1, 8, 0, 0, 0, time.Local)
+	for _, e := range []float64{0.5, 0.25} {
+		c := EnergyConsumption{DeviceID: 1, Hour: hour, Energy: e, AreaID: area.ID}
+		require.NoError(t, AddEnergyConsumption(GetDB(), c))
+	}
+	c := EnergyConsumption{DeviceID: 1, Hour: hour.Add(time.Hour), Energy: 1, AreaID: area.ID}
+	require.NoError(t, AddEnergyConsumption(GetDB(), c))
+
+	cs, err := GetEnergyConsumptions(area.ID, []int{1}, hour, hour.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, cs, 2)
+	assert.Equal(t, 0.75, cs[0].Energy)
+	assert.Equal(t, 1.0, cs[1].Energy)
+
+	cs, err = GetEnergyConsumptions(area.ID, nil, hour.Add(time.Hour), hour.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, cs, 1)
+}
+
+func TestSaveEnergyCounter(t *testing.T) {
+	area, err := CreateArea("energy", AreaOfHome)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, SaveEnergyCounter(GetDB(), EnergyCounter{DeviceID: 2, IID: "plug", AID: 1, Reading: 1.5, ReadAt: now, AreaID: area.ID}))
+	require.NoError(t, SaveEnergyCounter(GetDB(), EnergyCounter{DeviceID: 2, IID: "plug", AID: 1, Reading: 2.5, ReadAt: now, AreaID: area.ID}))
+	// 同一设备的其他通道分别记录
+	require.NoError(t, SaveEnergyCounter(GetDB(), EnergyCounter{DeviceID: 2, IID: "plug", AID: 2, Reading: 7, ReadAt: now, AreaID: area.ID}))
+
+	c, err := GetEnergyCounter(2, "plug", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, c.Reading)
+	c, err = GetEnergyCounter(2, "plug", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 7.0, c.Reading)
+	_, err = GetEnergyCounter(2, "other", 1)
+	assert.Error(t, err)
+}
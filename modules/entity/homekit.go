@@ -0,0 +1,4 @@
+package entity
+
+// HomeKitSetting HomeKit桥接配置类型，值为 homekit.Setting
+const HomeKitSetting = "homekit"
@@ -25,7 +25,9 @@ var Tables []interface{} = []interface{}{
 	User{}, UserRole{}, Scene{}, SceneCondition{},
 	SceneTask{}, TaskLog{}, GlobalSetting{}, PluginInfo{}, Client{},
 	Department{}, DepartmentUser{}, DeviceState{}, FileInfo{}, BackupInfo{},
-	UserCommonDevice{},
+	UserCommonDevice{}, PluginSettings{}, PluginStorage{},
+	EnergyCounter{}, EnergyConsumption{},
+	ClimateSchedule{}, ClimateOverride{}, ClimateRestore{},
 }
 
 func GetDB() *gorm.DB {
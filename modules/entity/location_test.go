@@ -1,25 +1,41 @@
 package entity
 
 import (
-	"github.com/stretchr/testify/assert"
 	"strconv"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// locationArea 房间测试使用的家庭，existLocationID 为其中的第一个房间
+var (
+	locationArea    Area
+	existLocationID int
 )
 
 func TestCreateLocation(t *testing.T) {
 	ast := assert.New(t)
 
+	var err error
+	locationArea, err = CreateArea("location", AreaOfHome)
+	require.NoError(t, err)
 	for i := 1; i < 11; i++ {
 		location := Location{
-			Name: "testLocation" + strconv.Itoa(i),
+			Name:   "testLocation" + strconv.Itoa(i),
+			AreaID: locationArea.ID,
 		}
 		err := CreateLocation(&location)
 		ast.NoError(err, "create location error: %v", err)
+		if i == 1 {
+			existLocationID = location.ID
+		}
 	}
 	location := Location{
-		Name: "testLocation1",
+		Name:   "testLocation1",
+		AreaID: locationArea.ID,
 	}
-	err := CreateLocation(&location)
+	err = CreateLocation(&location)
 	ast.Error(err, "create location error")
 }
 
@@ -38,14 +54,14 @@ func TestLocationNameExist(t *testing.T) {
 	}
 
 	for _, t := range tt {
-		ast.Equal(t.expectedRes, LocationNameExist(t.name))
+		ast.Equal(t.expectedRes, LocationNameExist(locationArea.ID, t.name))
 	}
 }
 
 func TestGetLocationByID(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existLocationID
 	const notExistID = 999
 
 	location, err := GetLocationByID(existID)
@@ -62,7 +78,7 @@ func TestGetLocationCount(t *testing.T) {
 
 	const correctCount int64 = 10
 
-	count, err := GetLocationCount()
+	count, err := GetLocationCount(locationArea.ID)
 	ast.NoError(err, "get location count error: %v", err)
 	ast.Equal(correctCount, count)
 }
@@ -72,7 +88,7 @@ func TestGetLocations(t *testing.T) {
 
 	const correctCount = 10
 
-	locations, err := GetLocations()
+	locations, err := GetLocations(locationArea.ID)
 	ast.NoError(err, "get locations error: %v", err)
 	ast.Equal(correctCount, len(locations))
 }
@@ -80,7 +96,7 @@ func TestGetLocations(t *testing.T) {
 func TestIsLocationExist(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existLocationID
 	const notExistID = 999
 
 	tt := []struct {
@@ -92,7 +108,7 @@ func TestIsLocationExist(t *testing.T) {
 	}
 
 	for _, t := range tt {
-		res := IsLocationExist(t.id)
+		res := IsLocationExist(locationArea.ID, t.id)
 		ast.Equal(t.expectedRes, res)
 	}
 }
@@ -100,7 +116,7 @@ func TestIsLocationExist(t *testing.T) {
 func TestEditLocationSort(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existLocationID
 	const notExistID = 999
 	const newSort = 99
 
@@ -116,7 +132,7 @@ func TestEditLocationSort(t *testing.T) {
 func TestUpdateLocation(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existLocationID
 	const notExistID = 999
 
 	newLocation := Location{
@@ -132,7 +148,7 @@ func TestUpdateLocation(t *testing.T) {
 func TestDelLocation(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existLocationID
 	const notExistID = 999
 
 	err := DelLocation(existID)
@@ -35,6 +35,17 @@ type PluginInfo struct {
 	Source    string
 	Brand     string
 	ErrorInfo string
+	Publisher string // 插件包签名的发布者，为空表示未签名
+
+	CrashCount int    // 插件崩溃或无响应的次数
+	LastError  string // 最近一次崩溃或无响应的原因
+
+	Resources        datatypes.JSON // 插件配置中声明的资源限制
+	ResourceOverride datatypes.JSON // 拥有者设置的资源限制，优先于插件配置
+
+	PreviousImage   string         // 上一个版本的镜像，更新失败时用于回滚
+	PreviousVersion string         // 上一个版本
+	UpgradeHistory  datatypes.JSON // 插件的更新记录
 }
 
 func (p PluginInfo) TableName() string {
@@ -44,7 +55,7 @@ func (p PluginInfo) TableName() string {
 func SavePluginInfo(pi PluginInfo) (err error) {
 	return GetDB().Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "area_id"}, {Name: "plugin_id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"info", "version", "image"}),
+		DoUpdates: clause.AssignmentColumns([]string{"info", "version", "image", "resources"}),
 	}).Create(&pi).Error
 }
 
@@ -85,9 +96,42 @@ func GetPlugin(pluginID string, areaID uint64) (plugin PluginInfo, err error) {
 func DelPlugin(pluginID string, areaID uint64) (err error) {
 	err = GetDB().Where(PluginInfo{PluginID: pluginID, AreaID: areaID}).
 		Delete(&PluginInfo{}).Error
+	if err != nil {
+		return
+	}
+	err = GetDB().Where(PluginSettings{PluginID: pluginID, AreaID: areaID}).
+		Delete(&PluginSettings{}).Error
 	return
 }
 
+// UpdatePluginHealth 记录插件的崩溃次数及原因
+func UpdatePluginHealth(pluginID string, crashCount int, lastError string) (err error) {
+	return GetDB().Model(&PluginInfo{}).Where(PluginInfo{PluginID: pluginID}).
+		Updates(map[string]interface{}{"crash_count": crashCount, "last_error": lastError}).Error
+}
+
+// UpdatePluginResourceOverride 保存拥有者设置的插件资源限制
+func UpdatePluginResourceOverride(pluginID string, areaID uint64, override datatypes.JSON) (err error) {
+	return GetDB().Model(&PluginInfo{}).Where(PluginInfo{PluginID: pluginID, AreaID: areaID}).
+		Update("resource_override", override).Error
+}
+
+// UpdatePluginUpgrade 更新成功后记录上一个版本及更新记录
+func UpdatePluginUpgrade(pluginID string, areaID uint64, previousImage, previousVersion string, history datatypes.JSON) (err error) {
+	return GetDB().Model(&PluginInfo{}).Where(PluginInfo{PluginID: pluginID, AreaID: areaID}).
+		Updates(map[string]interface{}{
+			"previous_image":   previousImage,
+			"previous_version": previousVersion,
+			"upgrade_history":  history,
+		}).Error
+}
+
+// UpdatePluginUpgradeHistory 记录插件的更新记录
+func UpdatePluginUpgradeHistory(pluginID string, areaID uint64, history datatypes.JSON) (err error) {
+	return GetDB().Model(&PluginInfo{}).Where(PluginInfo{PluginID: pluginID, AreaID: areaID}).
+		Update("upgrade_history", history).Error
+}
+
 // IsPluginDevelop 是否是开发插件
 func IsPluginDevelop(pluginID string, areaID uint64) bool {
 	p, _ := GetPlugin(pluginID, areaID)
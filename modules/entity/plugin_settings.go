@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm/clause"
+)
+
+// PluginSettings 插件在家庭中的设置，格式由插件配置中的settings（JSON Schema）定义
+type PluginSettings struct {
+	ID        int
+	AreaID    uint64 `gorm:"uniqueIndex:area_plugin_settings"`
+	Area      Area   `gorm:"constraint:OnDelete:CASCADE;"`
+	PluginID  string `gorm:"uniqueIndex:area_plugin_settings"`
+	Settings  datatypes.JSON
+	UpdatedAt time.Time
+}
+
+func (p PluginSettings) TableName() string {
+	return "plugin_settings"
+}
+
+// GetPluginSettings 获取插件在家庭中的设置
+func GetPluginSettings(areaID uint64, pluginID string) (ps PluginSettings, err error) {
+	err = GetDB().Where(PluginSettings{AreaID: areaID, PluginID: pluginID}).First(&ps).Error
+	return
+}
+
+// SavePluginSettings 保存插件在家庭中的设置，已存在则覆盖
+func SavePluginSettings(areaID uint64, pluginID string, settings datatypes.JSON) (err error) {
+	ps := PluginSettings{
+		AreaID:   areaID,
+		PluginID: pluginID,
+		Settings: settings,
+	}
+	return GetDB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "area_id"}, {Name: "plugin_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"settings", "updated_at"}),
+	}).Create(&ps).Error
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PluginStorage 插件的键值存储，按插件及家庭隔离，删除插件时保留以便重新安装后使用
+type PluginStorage struct {
+	ID        int
+	AreaID    uint64 `gorm:"uniqueIndex:area_plugin_key"`
+	Area      Area   `gorm:"constraint:OnDelete:CASCADE;"`
+	PluginID  string `gorm:"uniqueIndex:area_plugin_key"`
+	Key       string `gorm:"uniqueIndex:area_plugin_key"`
+	Value     []byte
+	UpdatedAt time.Time
+}
+
+func (p PluginStorage) TableName() string {
+	return "plugin_storages"
+}
+
+// GetPluginStorage 获取插件存储的值
+func GetPluginStorage(areaID uint64, pluginID, key string) (ps PluginStorage, err error) {
+	err = GetDB().Where(PluginStorage{AreaID: areaID, PluginID: pluginID, Key: key}).First(&ps).Error
+	return
+}
+
+// SavePluginStorage 保存插件存储的值，已存在则覆盖
+func SavePluginStorage(areaID uint64, pluginID, key string, value []byte) (err error) {
+	ps := PluginStorage{
+		AreaID:   areaID,
+		PluginID: pluginID,
+		Key:      key,
+		Value:    value,
+	}
+	return GetDB().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "area_id"}, {Name: "plugin_id"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&ps).Error
+}
+
+// DelPluginStorage 删除插件存储的值
+func DelPluginStorage(areaID uint64, pluginID, key string) (err error) {
+	return GetDB().Where(PluginStorage{AreaID: areaID, PluginID: pluginID, Key: key}).
+		Delete(&PluginStorage{}).Error
+}
+
+// ListPluginStorages 获取插件存储中指定前缀的所有值
+func ListPluginStorages(areaID uint64, pluginID, prefix string) (pss []PluginStorage, err error) {
+	var all []PluginStorage
+	if err = GetDB().Where(PluginStorage{AreaID: areaID, PluginID: pluginID}).
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "key"}}).Find(&all).Error; err != nil {
+		return
+	}
+	for _, ps := range all {
+		if strings.HasPrefix(ps.Key, prefix) {
+			pss = append(pss, ps)
+		}
+	}
+	return
+}
+
+// GetAllPluginStorages 获取所有插件的存储，用于备份
+func GetAllPluginStorages() (pss []PluginStorage, err error) {
+	err = GetDB().Order("id").Find(&pss).Error
+	return
+}
+
+// ReplacePluginStorages 使用备份数据覆盖所有插件的存储
+func ReplacePluginStorages(pss []PluginStorage) (err error) {
+	return GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).
+			Delete(&PluginStorage{}).Error; err != nil {
+			return err
+		}
+		if len(pss) == 0 {
+			return nil
+		}
+		for i := range pss {
+			pss[i].ID = 0
+		}
+		return tx.Create(&pss).Error
+	})
+}
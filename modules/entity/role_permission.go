@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
 type ActionType string
@@ -42,14 +43,20 @@ type Attr struct {
 }
 
 type UserPermissions struct {
-	ps      []RolePermission
-	isOwner bool
+	ps         []RolePermission
+	isOwner    bool
+	unitSystem thingmodel.UnitSystem
 }
 
 func (up UserPermissions) IsOwner() bool {
 	return up.isOwner
 }
 
+// UnitSystem 用户选择的单位制
+func (up UserPermissions) UnitSystem() thingmodel.UnitSystem {
+	return up.unitSystem
+}
+
 // IsDeviceControlPermit 判断设备是否可控制
 func (up UserPermissions) IsDeviceControlPermit(deviceID int) bool {
 	if up.isOwner {
@@ -102,7 +109,11 @@ func GetUserPermissions(userID int) (up UserPermissions, err error) {
 	if err != nil {
 		return
 	}
-	return UserPermissions{ps: ps, isOwner: IsOwnerOfArea(userID, user.AreaID)}, nil
+	return UserPermissions{
+		ps:         ps,
+		isOwner:    IsOwnerOfArea(userID, user.AreaID),
+		unitSystem: user.GetUnitSystem(),
+	}, nil
 }
 
 func UserRolePermissionsScope(userID int) func(db *gorm.DB) *gorm.DB {
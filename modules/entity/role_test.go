@@ -1,14 +1,18 @@
 package entity
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRole(t *testing.T) {
 	const testingRole = "testing_test_role"
 	const anotherTestingRole = "another_testing_test_role"
-	r, err := AddRole(testingRole)
+	area, err := CreateArea("role", AreaOfHome)
+	require.NoError(t, err)
+	r, err := AddRole(testingRole, area.ID)
 	assert.Nil(t, err, "add role error: %v", err)
 	if err != nil {
 		t.FailNow()
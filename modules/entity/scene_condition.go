@@ -18,6 +18,7 @@ type ConditionType int
 const (
 	ConditionTypeTiming       ConditionType = iota + 1 // 条件类型：定时
 	ConditionTypeDeviceStatus                          // 条件类型：设备状态变化
+	ConditionTypeDeviceEvent                           // 条件类型：设备事件（无状态）
 )
 
 type OperatorType string
@@ -42,6 +43,28 @@ type SceneCondition struct {
 	DeviceID      int            `json:"device_id"`      // 或某个设备状态变化时
 	Operator      OperatorType   `json:"operator"`       // 操作符，大于、小于、等于
 	ConditionAttr datatypes.JSON `json:"condition_attr"` // refer to Attribute
+
+	ConditionEvent datatypes.JSON `json:"condition_event"` // refer to ConditionEvent
+}
+
+// ConditionEvent 设备事件触发条件，Params 为空时匹配该类型的所有事件，否则需要所有参数相等（数字按数值比较）
+type ConditionEvent struct {
+	IID    string                 `json:"iid"` // 为空时使用设备的iid
+	Type   string                 `json:"type"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// Match 判断事件是否满足条件
+func (ce ConditionEvent) Match(ev definer.DeviceEvent) bool {
+	if ce.Type != ev.Type || ce.IID != ev.IID {
+		return false
+	}
+	for k, v := range ce.Params {
+		if !thingmodel.ValEqual(ev.Params[k], v) {
+			return false
+		}
+	}
+	return true
 }
 
 func (d SceneCondition) TableName() string {
@@ -73,6 +96,11 @@ func (c ConditionInfo) CheckCondition(userId int, isRequireNotify bool) (err err
 		if err = c.checkConditionTypeTiming(); err != nil {
 			return
 		}
+	} else if c.ConditionType == ConditionTypeDeviceEvent {
+		// 设备事件
+		if err = c.checkConditionEvent(userId); err != nil {
+			return
+		}
 	} else {
 		// 设备状态变化时
 		if err = c.checkConditionDevice(userId, isRequireNotify); err != nil {
@@ -84,7 +112,7 @@ func (c ConditionInfo) CheckCondition(userId int, isRequireNotify bool) (err err
 
 // checkConditionType 校验触发条件类型
 func (c ConditionInfo) checkConditionType() (err error) {
-	if c.ConditionType < ConditionTypeTiming || c.ConditionType > ConditionTypeDeviceStatus {
+	if c.ConditionType < ConditionTypeTiming || c.ConditionType > ConditionTypeDeviceEvent {
 		err = errors.Newf(status.SceneParamIncorrectErr, "触发条件类型")
 		return
 	}
@@ -113,6 +141,60 @@ func (c ConditionInfo) checkConditionDevice(userId int, isRequireNotify bool) (e
 	return
 }
 
+// checkConditionEvent 校验设备事件类型
+func (c ConditionInfo) checkConditionEvent(userId int) (err error) {
+	if c.DeviceID <= 0 || c.Timing != 0 {
+		err = errors.New(status.ConditionMisMatchTypeAndConfigErr)
+		return
+	}
+	ce, err := c.GetConditionEvent()
+	if err != nil {
+		err = errors.Newf(status.SceneParamIncorrectErr, "设备事件")
+		return
+	}
+	device, err := GetDeviceByID(c.DeviceID)
+	if err != nil {
+		return
+	}
+	user, err := GetUserByID(userId)
+	if err != nil {
+		return
+	}
+	if user.AreaID != device.AreaID {
+		err = errors.New(status.DeviceOrSceneControlDeny)
+		return
+	}
+	tm, err := device.GetThingModel()
+	if err != nil {
+		return
+	}
+	ev, err := tm.GetEvent(ce.IID, ce.Type)
+	if err != nil {
+		err = errors.Newf(status.SceneParamIncorrectErr, "设备事件")
+		return
+	}
+	if err = ev.CheckParams(ce.Params); err != nil {
+		err = errors.Newf(status.SceneParamIncorrectErr, "设备事件参数")
+		return
+	}
+	return
+}
+
+// GetConditionEvent 获取设备事件条件，iid为空时使用设备的iid
+func (d SceneCondition) GetConditionEvent() (ce ConditionEvent, err error) {
+	if err = json.Unmarshal(d.ConditionEvent, &ce); err != nil {
+		return
+	}
+	if ce.IID == "" {
+		var device Device
+		if device, err = GetDeviceByID(d.DeviceID); err != nil {
+			return
+		}
+		ce.IID = device.IID
+	}
+	return
+}
+
 // CheckConditionItem 触发条件为设备状态变化时，校验对应参数
 func (d SceneCondition) CheckConditionItem(userId, deviceId int, isRequireNotify bool) (err error) {
 	if err = d.checkOperatorType(); err != nil {
@@ -206,6 +288,25 @@ func GetScenesByCondition(deviceID int, attr definer.AttributeEvent) (scenes []S
 	return
 }
 
+// GetConditionsByDeviceEvent 获取符合设备事件的条件
+func GetConditionsByDeviceEvent(deviceID int, ev definer.DeviceEvent) (conds []SceneCondition, err error) {
+	var deviceConds []SceneCondition
+	if err = GetDB().Where("device_id=? and condition_type=?", deviceID, ConditionTypeDeviceEvent).
+		Find(&deviceConds).Error; err != nil {
+		return
+	}
+	for _, cond := range deviceConds {
+		ce, err := cond.GetConditionEvent()
+		if err != nil {
+			continue
+		}
+		if ce.Match(ev) {
+			conds = append(conds, cond)
+		}
+	}
+	return
+}
+
 // GetConditions 获取符合设备属性的条件
 func GetConditions(deviceID int, ae definer.AttributeEvent) (conds []SceneCondition, err error) {
 
@@ -220,6 +321,17 @@ func GetConditions(deviceID int, ae definer.AttributeEvent) (conds []SceneCondit
 		Find(&deviceConds, attrQuery).Error; err != nil {
 		return
 	}
+	if len(deviceConds) == 0 {
+		return
+	}
+	// 设备上报的值使用物模型中的单位
+	var unit thingmodel.Unit
+	if d, err := GetDeviceByID(deviceID); err == nil {
+		if tm, err := d.GetThingModel(); err == nil {
+			attr, _ := tm.GetAttribute(ae.IID, ae.AID)
+			unit = attr.Unit
+		}
+	}
 	for _, cond := range deviceConds {
 		if cond.ConditionType == ConditionTypeTiming {
 			continue
@@ -230,7 +342,7 @@ func GetConditions(deviceID int, ae definer.AttributeEvent) (conds []SceneCondit
 			continue
 		}
 
-		if item.Operate(cond.Operator, ae.Val) {
+		if item.OperateFrom(cond.Operator, ae.Val, unit) {
 			conds = append(conds, cond)
 		}
 	}
@@ -242,6 +354,19 @@ type Attribute struct {
 	thingmodel.Attribute
 }
 
+// OperateFrom 将单位为unit的设备属性值换算为条件中的单位后比较，条件未记录单位时不换算
+func (attr *Attribute) OperateFrom(operatorType OperatorType, val interface{}, unit thingmodel.Unit) bool {
+	if attr.Unit != "" && unit != "" && attr.Unit != unit {
+		switch v := attr.Attribute.ValFrom(val, unit).(type) {
+		case int:
+			val = float64(v)
+		default:
+			val = v
+		}
+	}
+	return attr.Operate(operatorType, val)
+}
+
 func (attr *Attribute) Operate(operatorType OperatorType, val interface{}) bool {
 	switch operatorType {
 	case OperatorEQ:
@@ -268,3 +393,46 @@ func (attr *Attribute) Operate(operatorType OperatorType, val interface{}) bool
 
 	return false
 }
+
+// stampUnit 为未记录单位的属性记录用户单位制下的单位，即用户设置值时看到的单位
+func stampUnit(attr json.RawMessage, tm thingmodel.ThingModel, iid string, system thingmodel.UnitSystem) (json.RawMessage, error) {
+	var item Attribute
+	if err := json.Unmarshal(attr, &item); err != nil {
+		return attr, errors.Wrap(err, errors.BadRequest)
+	}
+	if item.Unit != "" {
+		return attr, nil
+	}
+	tmAttr, err := tm.GetAttribute(iid, item.AID)
+	if err != nil || tmAttr.Unit == "" {
+		return attr, nil
+	}
+	var m map[string]interface{}
+	if err = json.Unmarshal(attr, &m); err != nil {
+		return attr, errors.Wrap(err, errors.BadRequest)
+	}
+	m["unit"] = system.Unit(tmAttr.Unit)
+	return json.Marshal(m)
+}
+
+// StampUnit 设备状态条件未记录单位时，记录用户单位制下的单位，以便设备上报时换算
+func (d *SceneCondition) StampUnit(system thingmodel.UnitSystem) (err error) {
+	if d.ConditionType != ConditionTypeDeviceStatus || len(d.ConditionAttr) == 0 {
+		return
+	}
+	// 设备或物模型不存在时由条件校验返回错误
+	device, e := GetDeviceByID(d.DeviceID)
+	if e != nil {
+		return
+	}
+	tm, e := device.GetThingModel()
+	if e != nil {
+		return
+	}
+	attr, err := stampUnit(json.RawMessage(d.ConditionAttr), tm, device.IID, system)
+	if err != nil {
+		return
+	}
+	d.ConditionAttr = datatypes.JSON(attr)
+	return
+}
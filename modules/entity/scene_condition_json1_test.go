@@ -0,0 +1,68 @@
+//go:build json1
+// +build json1
+
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+)
+
+// 根据属性查询条件需要sqlite的json支持，使用 go test --tags json1 运行
+
+func TestGetConditions(t *testing.T) {
+	ast := assert.New(t)
+	f := newSceneFixture(t)
+
+	scene := f.switchScene("conditions")
+	require.NoError(t, CreateScene(&scene))
+
+	tt := []struct {
+		deviceID    int
+		attribute   definer.AttributeEvent
+		isHaveScene bool
+	}{
+		{
+			deviceID:    f.device.ID,
+			attribute:   definer.AttributeEvent{IID: f.device.IID, AID: 1, Val: "on"},
+			isHaveScene: true,
+		},
+		{
+			deviceID:    999999,
+			attribute:   definer.AttributeEvent{IID: f.device.IID, AID: 1, Val: "on"},
+			isHaveScene: false,
+		},
+		{
+			deviceID:    f.device.ID,
+			attribute:   definer.AttributeEvent{IID: f.device.IID, AID: 1, Val: "off"},
+			isHaveScene: false,
+		},
+		{
+			deviceID:    f.device.ID,
+			attribute:   definer.AttributeEvent{IID: f.device.IID, AID: 2, Val: "on"},
+			isHaveScene: false,
+		},
+	}
+
+	for i, t := range tt {
+		conditions, err := GetConditions(t.deviceID, t.attribute)
+		ast.NoError(err)
+		if t.isHaveScene {
+			ast.NotEmpty(conditions, "%v", i)
+		} else {
+			ast.Empty(conditions, "%v", i)
+		}
+
+		scenes, err := GetScenesByCondition(t.deviceID, t.attribute)
+		ast.NoError(err)
+		if t.isHaveScene {
+			ast.NotEmpty(scenes, "%v", i)
+		} else {
+			ast.Empty(scenes, "%v", i)
+		}
+	}
+}
@@ -1,18 +1,91 @@
 package entity
 
 import (
-	"fmt"
+	"encoding/json"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
 	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
-//-----------------------------------------------------------
+// sceneFixture 家庭中有一个开关设备，成员只有开关的控制权限
+type sceneFixture struct {
+	area          Area
+	owner, member User
+	device        Device
+}
+
+func newSceneFixture(t *testing.T) (f sceneFixture) {
+	var err error
+	f.area, err = CreateArea("scene", AreaOfHome)
+	require.NoError(t, err)
+	f.owner = User{AreaID: f.area.ID}
+	require.NoError(t, CreateUser(&f.owner, GetDB()))
+	require.NoError(t, SetAreaOwnerID(f.area.ID, f.owner.ID, GetDB()))
+
+	f.device = createSwitch(t, f.area.ID, "scene-switch")
+
+	f.member = User{AreaID: f.area.ID}
+	require.NoError(t, CreateUser(&f.member, GetDB()))
+	role, err := AddRole("scene", f.area.ID)
+	require.NoError(t, err)
+	require.NoError(t, role.AddPermissionForRole("test", types.ActionControl, types.DeviceTarget(f.device.ID), "1"))
+	require.NoError(t, CreateUserRole([]UserRole{{UserID: f.member.ID, RoleID: role.ID}}))
+	return
+}
+
+// createSwitch 创建只有开关属性(aid为1)的设备
+func createSwitch(t *testing.T, areaID uint64, iid string) Device {
+	onOff := thingmodel.OnOff
+	onOff.AID = 1
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID:      iid,
+		Services: []thingmodel.Service{{Type: "switch", Attributes: []thingmodel.Attribute{onOff}}},
+	}}}
+	d := Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	require.NoError(t, CreateDevice(&d, GetDB()))
+	return d
+}
+
+// switchScene 开关打开时关闭开关的自动场景
+func (f sceneFixture) switchScene(name string) Scene {
+	return Scene{
+		Name:           name,
+		CreatorID:      f.owner.ID,
+		AutoRun:        true,
+		IsOn:           true,
+		TimePeriodType: TimePeriodTypeAllDay,
+		RepeatType:     RepeatTypeWorkDay,
+		RepeatDate:     "12345",
+		AreaID:         f.area.ID,
+		SceneConditions: []SceneCondition{
+			{
+				ConditionType: ConditionTypeDeviceStatus,
+				DeviceID:      f.device.ID,
+				Operator:      OperatorEQ,
+				ConditionAttr: []byte(`{"aid": 1, "val": "on"}`),
+			},
+		},
+		SceneTasks: []SceneTask{
+			{
+				Type:       TaskTypeSmartDevice,
+				DeviceID:   f.device.ID,
+				Attributes: []byte(`[{"aid": 1, "val": "off"}]`),
+			},
+		},
+	}
+}
+
 func TestCreateScene(t *testing.T) {
 	ast := assert.New(t)
+	f := newSceneFixture(t)
 
 	const properName = "npc_test"
 	const lenLT1Name = ""
@@ -32,12 +105,13 @@ func TestCreateScene(t *testing.T) {
 		{
 			scene: Scene{
 				Name:           "getOn",
-				CreatorID:      1,
+				CreatorID:      f.owner.ID,
 				AutoRun:        true,
 				IsOn:           true,
 				TimePeriodType: TimePeriodTypeAllDay,
 				RepeatType:     RepeatTypeWorkDay,
 				RepeatDate:     "12345",
+				AreaID:         f.area.ID,
 				SceneConditions: []SceneCondition{
 					{
 						ConditionType: ConditionTypeTiming,
@@ -46,67 +120,29 @@ func TestCreateScene(t *testing.T) {
 				},
 				SceneTasks: []SceneTask{
 					{
-						Type: TaskTypeSmartDevice,
-						SceneTaskDevices: []SceneTaskDevice{
-							{
-								DeviceID:  1,
-								Action:    "switch",
-								Attribute: "power",
-								ActionVal: "on",
-							},
-						},
+						Type:       TaskTypeSmartDevice,
+						DeviceID:   f.device.ID,
+						Attributes: []byte(`[{"aid": 1, "val": "on"}]`),
 					},
 				},
 			},
 			expectedRes: true,
 		},
 		{
-			scene: Scene{
-				Name:           "sleep",
-				CreatorID:      1,
-				AutoRun:        true,
-				IsOn:           true,
-				TimePeriodType: TimePeriodTypeAllDay,
-				RepeatType:     RepeatTypeWorkDay,
-				RepeatDate:     "12345",
-				SceneConditions: []SceneCondition{
-					{
-						ConditionType: ConditionTypeTiming,
-						TimingAt:      time.Date(0, 0, 0, 8, 30, 0, 0, time.Local),
-					},
-				},
-				SceneTasks: []SceneTask{
-					{
-						Type: TaskTypeSmartDevice,
-						SceneTaskDevices: []SceneTaskDevice{
-							{
-								DeviceID:  1,
-								Action:    "switch",
-								Attribute: "power",
-								ActionVal: "off",
-							},
-						},
-					},
-				},
-			},
+			scene:       f.switchScene("sleep"),
 			expectedRes: true,
 		},
 		{
 			scene: Scene{
 				Name:      "openLight",
-				CreatorID: 1,
+				CreatorID: f.owner.ID,
 				AutoRun:   false,
+				AreaID:    f.area.ID,
 				SceneTasks: []SceneTask{
 					{
-						Type: TaskTypeSmartDevice,
-						SceneTaskDevices: []SceneTaskDevice{
-							{
-								DeviceID:  1,
-								Action:    "switch",
-								Attribute: "power",
-								ActionVal: "on",
-							},
-						},
+						Type:       TaskTypeSmartDevice,
+						DeviceID:   f.device.ID,
+						Attributes: []byte(`[{"aid": 1, "val": "on"}]`),
 					},
 				},
 			},
@@ -179,175 +215,104 @@ func TestCreateScene(t *testing.T) {
 			ast.Error(err, "%v", i)
 		}
 	}
+
+	scenes, err := GetScenes(f.area.ID)
+	ast.NoError(err)
+	ast.Len(scenes, 3)
 }
 
-func TestIsSceneNameExist(t *testing.T) {
+func TestScene(t *testing.T) {
 	ast := assert.New(t)
+	f := newSceneFixture(t)
 
-	const existName = "getOn"
-	const notExistName = "dsjfkldfjs"
-	const notUseID = 0
-	const correspondID = 1
-	const notCorrespondID = 2
+	scene := f.switchScene("getOn")
+	require.NoError(t, CreateScene(&scene))
+	const notExitID = 999999
 
+	// 场景名称是否重复
 	tt := []struct {
 		name        string
 		id          int
 		expectedRes bool
 	}{
-		{
-			name:        existName,
-			id:          notUseID,
-			expectedRes: true,
-		},
-		{
-			name:        existName,
-			id:          correspondID,
-			expectedRes: false,
-		},
-		{
-			name:        existName,
-			id:          notCorrespondID,
-			expectedRes: true,
-		},
-		{
-			name:        notExistName,
-			id:          notUseID,
-			expectedRes: false,
-		},
+		{"getOn", 0, true},
+		{"getOn", scene.ID, false},
+		{"getOn", notExitID, true},
+		{"dsjfkldfjs", 0, false},
 	}
-
 	for i, t := range tt {
-		err := IsSceneNameExist(t.name, t.id)
+		err := IsSceneNameExist(t.name, t.id, f.area.ID)
 		if !t.expectedRes {
 			ast.NoError(err, "%v", i)
 		} else {
 			ast.Error(err, "%v", i)
 		}
 	}
-}
-
-func TestCheckSceneExitById(t *testing.T) {
-	ast := assert.New(t)
-
-	const exitID = 1
-	const notExitID = 999
-
-	err := CheckSceneExitById(exitID)
-	ast.NoError(err)
-
-	err = CheckSceneExitById(notExitID)
-	ast.Error(err)
-}
+	ast.NoError(IsSceneNameExist("getOn", 0, f.area.ID+1), "other area")
 
-func TestGetSceneById(t *testing.T) {
-	ast := assert.New(t)
-
-	const exitID = 1
-	const notExitID = 999
+	ast.NoError(CheckSceneExitById(scene.ID))
+	ast.Error(CheckSceneExitById(notExitID))
 
-	s, err := GetSceneById(exitID)
+	s, err := GetSceneById(scene.ID)
 	ast.NoError(err)
 	ast.NotEmpty(s)
-
 	s, err = GetSceneById(notExitID)
 	ast.Error(err)
 	ast.Empty(s)
-}
-
-func TestGetScenes(t *testing.T) {
-	ast := assert.New(t)
-
-	const count = 3
 
-	scenes, err := GetScenes()
+	s, err = GetSceneInfoById(scene.ID)
 	ast.NoError(err)
-	ast.Equal(count, len(scenes))
-}
-
-func TestGetSceneInfoById(t *testing.T) {
-	scene, err := GetSceneInfoById(1)
-	assert.NoError(t, err)
-	assert.NotEmpty(t, scene)
-
-	scene, err = GetSceneInfoById(999)
-	assert.Error(t, err)
-	assert.Empty(t, scene)
-}
-
-func TestGetSceneByIDWithUnscoped(t *testing.T) {
-	scene, err := GetSceneByIDWithUnscoped(1)
-	assert.NoError(t, err)
-	assert.NotEmpty(t, scene)
-
-	scene, err = GetSceneByIDWithUnscoped(999)
-	assert.Error(t, err)
-	assert.Empty(t, scene)
-}
-
-func TestSwitchAutoSceneByID(t *testing.T) {
-	ast := assert.New(t)
-
-	const exitID = 1
-	const notExitID = 999
-	const on = true
-	const off = false
-
-	tt := []struct {
-		id          int
-		isExecute   bool
-		expectedRes bool
-	}{
-		{
-			id:          exitID,
-			isExecute:   off,
-			expectedRes: true,
-		},
-		{
-			id:          exitID,
-			isExecute:   on,
-			expectedRes: true,
-		},
-		{
-			id:          notExitID,
-			isExecute:   off,
-			expectedRes: false,
-		},
-	}
-
-	for _, t := range tt {
-		err := SwitchAutoSceneByID(t.id, t.isExecute)
-		if t.expectedRes {
-			ast.NoError(err)
-		} else {
-			ast.Error(err)
-		}
+	ast.Len(s.SceneConditions, 1)
+	ast.Len(s.SceneTasks, 1)
+	s, err = GetSceneInfoById(notExitID)
+	ast.Error(err)
+	ast.Empty(s)
 
-	}
+	// 切换自动场景开关
+	ast.NoError(SwitchAutoSceneByID(scene.ID, false))
+	s, _ = GetSceneById(scene.ID)
+	ast.False(s.IsOn)
+	ast.NoError(SwitchAutoSceneByID(scene.ID, true))
+	s, _ = GetSceneById(scene.ID)
+	ast.True(s.IsOn)
+	ast.Error(SwitchAutoSceneByID(notExitID, false))
+
+	// 删除后仍可以获取
+	ast.NoError(DeleteScene(scene.ID))
+	ast.Error(DeleteScene(notExitID))
+	ast.Error(CheckSceneExitById(scene.ID))
+	s, err = GetSceneByIDWithUnscoped(scene.ID)
+	ast.NoError(err)
+	ast.Len(s.SceneConditions, 1)
+	s, err = GetSceneByIDWithUnscoped(notExitID)
+	ast.Error(err)
+	ast.Empty(s)
 }
 
-//-----------------------------------------------------------
 func TestCreateSceneTask(t *testing.T) {
 	ast := assert.New(t)
+	f := newSceneFixture(t)
 
 	scene := Scene{
-		ID:      4,
 		Name:    "testSceneTask",
 		AutoRun: false,
+		AreaID:  f.area.ID,
 	}
-	GetDB().Create(&scene)
+	require.NoError(t, CreateScene(&scene))
 
 	tt := [][]SceneTask{
 		{
 			{
-				SceneID:      4,
+				SceneID:      scene.ID,
 				DelaySeconds: 1,
+				Type:         TaskTypeEnableAutoRun,
 			},
 		},
 		{
 			{
-				SceneID:      4,
+				SceneID:      scene.ID,
 				DelaySeconds: 2,
+				Type:         TaskTypeSmartDevice,
 			},
 		},
 	}
@@ -356,237 +321,130 @@ func TestCreateSceneTask(t *testing.T) {
 		err := CreateSceneTask(t)
 		ast.NoError(err, "create scene task error: %v", err)
 	}
-}
 
-func TestGetSceneTasksBySceneID(t *testing.T) {
-	const exitSceneID = 4
-	const notExitSceneID = 999
-
-	sceneTasks, err := GetSceneTasksBySceneID(exitSceneID)
-	assert.NoError(t, err)
-	assert.NotEmpty(t, sceneTasks)
+	const notExitSceneID = 999999
+	sceneTasks, err := GetSceneTasksBySceneID(scene.ID)
+	ast.NoError(err)
+	if ast.Len(sceneTasks, 2) {
+		ast.Equal(TaskTypeSmartDevice, sceneTasks[0].Type)
+	}
 
 	sceneTasks, err = GetSceneTasksBySceneID(notExitSceneID)
-	assert.NoError(t, err)
-	assert.Empty(t, sceneTasks)
+	ast.NoError(err)
+	ast.Empty(sceneTasks)
 }
 
 func TestSceneTask_CheckTaskDevice(t *testing.T) {
 	ast := assert.New(t)
+	f := newSceneFixture(t)
 
-	const testID = 555
-	target := fmt.Sprintf("device-%v", strconv.Itoa(testID))
-
-	user := User{
-		ID: testID,
-	}
-	_ = CreateUser(&user)
-
-	role := Role{
-		ID: testID,
-	}
-	GetDB().Create(&role)
-
-	var urs = []UserRole{
-		{UserID: testID, RoleID: testID},
-	}
-	_ = CreateUserRole(urs)
-
-	permission := struct {
-		name      string
-		action    string
-		target    string
-		attribute string
-	}{"test", "control", target, "power"}
-	_ = role.AddPermissionForRole(permission.name, permission.action, permission.target, permission.attribute)
+	other := createSwitch(t, f.area.ID, "scene-other")
 
 	tt := []struct {
 		sceneTask   SceneTask
+		userID      int
 		expectedRes bool
 	}{
 		{
 			sceneTask: SceneTask{
-				Type:             TaskTypeSmartDevice,
-				SceneTaskDevices: []SceneTaskDevice{},
+				Type:     TaskTypeSmartDevice,
+				DeviceID: f.device.ID,
 			},
+			userID:      f.member.ID,
 			expectedRes: false,
 		},
 		{
 			sceneTask: SceneTask{
-				Type: TaskTypeSmartDevice,
-				SceneTaskDevices: []SceneTaskDevice{
-					{
-						DeviceID:  1,
-						Attribute: "power",
-					},
-				},
+				Type:       TaskTypeSmartDevice,
+				DeviceID:   other.ID,
+				Attributes: []byte(`[{"aid": 1, "val": "on"}]`),
 			},
+			userID:      f.member.ID,
 			expectedRes: false,
 		},
 		{
 			sceneTask: SceneTask{
-				Type: TaskTypeSmartDevice,
-				SceneTaskDevices: []SceneTaskDevice{
-					{
-						DeviceID:  testID,
-						Attribute: "power",
-						Action:    types.ActionSwitch,
-					},
-				},
+				Type:       TaskTypeSmartDevice,
+				DeviceID:   other.ID,
+				Attributes: []byte(`[{"aid": 1, "val": "on"}]`),
 			},
+			userID:      f.owner.ID,
 			expectedRes: true,
 		},
-	}
-
-	for _, t := range tt {
-		err := t.sceneTask.CheckTaskDevice(testID)
-		if t.expectedRes {
-			ast.NoError(err)
-		} else {
-			ast.Error(err)
-		}
-	}
-
-	_ = DeleteRole(testID)
-	_ = DelUser(testID)
-	_ = DelUserRoleByUid(testID, GetDB())
-}
-
-func TestSceneTask_CheckTaskType(t *testing.T) {
-	ast := assert.New(t)
-
-	const lt1TaskType = 0
-	const gt4TaskType = 5
-	const properTaskType = 2
-
-	tt := []struct {
-		sceneTask   SceneTask
-		expectedRes bool
-	}{
 		{
 			sceneTask: SceneTask{
-				Type: lt1TaskType,
+				Type:       TaskTypeSmartDevice,
+				DeviceID:   f.device.ID,
+				Attributes: []byte(`[{"aid": 1, "val": "on"}]`),
 			},
-			expectedRes: false,
+			userID:      f.member.ID,
+			expectedRes: true,
 		},
 		{
+			// 属性值不合法
 			sceneTask: SceneTask{
-				Type: gt4TaskType,
+				Type:       TaskTypeSmartDevice,
+				DeviceID:   f.device.ID,
+				Attributes: []byte(`[{"aid": 1, "val": 1}]`),
 			},
+			userID:      f.member.ID,
 			expectedRes: false,
 		},
 		{
+			// 设备动作不存在
 			sceneTask: SceneTask{
-				Type: properTaskType,
+				Type:     TaskTypeSmartDevice,
+				DeviceID: f.device.ID,
+				Actions:  []byte(`[{"action": "test"}]`),
 			},
-			expectedRes: true,
+			userID:      f.owner.ID,
+			expectedRes: false,
 		},
 	}
 
-	for _, t := range tt {
-		err := t.sceneTask.CheckTaskType()
+	for i, t := range tt {
+		err := t.sceneTask.CheckTaskDevice(t.userID)
 		if t.expectedRes {
-			ast.NoError(err)
+			ast.NoError(err, "%v", i)
 		} else {
-			ast.Error(err)
+			ast.Error(err, "%v", i)
 		}
 	}
 }
 
-//-----------------------------------------------------------
-func TestCreateTaskPerformItem(t *testing.T) {
-	ast := assert.New(t)
-
-	sceneTask := []SceneTask{
-		{
-			ID:             200,
-			SceneID:        1,
-			ControlSceneID: 1,
-			Type:           TaskTypeSmartDevice,
-		},
-	}
-	GetDB().Create(&sceneTask)
-
-	tt := [][]SceneTaskDevice{
-		{
-			{
-				SceneTaskID: 200,
-				DeviceID:    1,
-				Action:      "test",
-				Attribute:   "test",
-				ActionVal:   "0",
-			},
-		},
-		{
-			{
-				SceneTaskID: 200,
-				DeviceID:    2,
-				Action:      "test",
-				Attribute:   "test",
-				ActionVal:   "0",
-			},
-		},
-	}
-
-	for _, t := range tt {
-		err := CreateTaskPerformItem(t)
-		ast.NoError(err, "create task perform item error: %v", err)
-	}
-}
-
-func TestGetSceneTaskItemsByTaskID(t *testing.T) {
+func TestSceneTask_CheckTaskType(t *testing.T) {
 	ast := assert.New(t)
 
-	const exitTaskID = 200
-	const notExitTaskID = 999
-
-	taskItems, err := GetSceneTaskItemsByTaskID(exitTaskID)
-	ast.NoError(err, "get scene task items by taskid error: %v", err)
-	ast.NotEmpty(taskItems)
-
-	taskItems, err = GetSceneTaskItemsByTaskID(notExitTaskID)
-	ast.NoError(err, "get scene task items by taskid error: %v", err)
-	ast.Empty(taskItems)
-}
-
-func TestSceneTaskDevice_CheckPerformItem(t *testing.T) {
-	ast := assert.New(t)
+	const lt1TaskType = 0
+	const gt4TaskType = 5
+	const properTaskType = 2
 
 	tt := []struct {
-		sceneTaskDevice SceneTaskDevice
-		expectedRes     bool
+		sceneTask   SceneTask
+		expectedRes bool
 	}{
 		{
-			sceneTaskDevice: SceneTaskDevice{
-				DeviceID: 0,
-			},
-			expectedRes: false,
-		},
-		{
-			sceneTaskDevice: SceneTaskDevice{
-				DeviceID: 1,
-				Action:   "",
+			sceneTask: SceneTask{
+				Type: lt1TaskType,
 			},
 			expectedRes: false,
 		},
 		{
-			sceneTaskDevice: SceneTaskDevice{
-				DeviceID: 1,
-				Action:   "test",
+			sceneTask: SceneTask{
+				Type: gt4TaskType,
 			},
 			expectedRes: false,
 		},
 		{
-			sceneTaskDevice: SceneTaskDevice{
-				DeviceID: 1,
-				Action:   types.ActionSwitch,
+			sceneTask: SceneTask{
+				Type: properTaskType,
 			},
 			expectedRes: true,
 		},
 	}
 
 	for _, t := range tt {
-		err := t.sceneTaskDevice.checkPerformItem()
+		err := t.sceneTask.CheckTaskType()
 		if t.expectedRes {
 			ast.NoError(err)
 		} else {
@@ -595,63 +453,25 @@ func TestSceneTaskDevice_CheckPerformItem(t *testing.T) {
 	}
 }
 
-//-----------------------------------------------------------
 func TestGetConditionsBySceneID(t *testing.T) {
 	ast := assert.New(t)
+	f := newSceneFixture(t)
+
+	scene := f.switchScene("conditions")
+	require.NoError(t, CreateScene(&scene))
 
-	cs, err := GetConditionsBySceneID(1)
+	cs, err := GetConditionsBySceneID(scene.ID)
 	ast.NoError(err)
 	ast.NotEmpty(cs)
 
-	cs, err = GetConditionsBySceneID(999)
+	cs, err = GetConditionsBySceneID(999999)
 	ast.NoError(err)
 	ast.Empty(cs)
 }
 
-func TestCheckOperation(t *testing.T) {
-	ast := assert.New(t)
-
-	err := checkOperation("")
-	ast.Error(err)
-
-	err = checkOperation(types.ActionSwitch)
-	ast.NoError(err)
-
-	err = checkOperation(types.ActionSetBright)
-	ast.NoError(err)
-
-	err = checkOperation(types.ActionOnVal)
-	ast.Error(err)
-}
-
 func TestConditionInfo_CheckCondition(t *testing.T) {
 	ast := assert.New(t)
-
-	const testID = 555
-	target := fmt.Sprintf("device-%v", strconv.Itoa(testID))
-
-	user := User{
-		ID: testID,
-	}
-	_ = CreateUser(&user)
-
-	role := Role{
-		ID: testID,
-	}
-	GetDB().Create(&role)
-
-	var urs = []UserRole{
-		{UserID: testID, RoleID: testID},
-	}
-	_ = CreateUserRole(urs)
-
-	permission := struct {
-		name      string
-		action    string
-		target    string
-		attribute string
-	}{"test", "control", target, "power"}
-	_ = role.AddPermissionForRole(permission.name, permission.action, permission.target, permission.attribute)
+	f := newSceneFixture(t)
 
 	tt := []struct {
 		conditionInfo ConditionInfo
@@ -662,11 +482,6 @@ func TestConditionInfo_CheckCondition(t *testing.T) {
 				SceneCondition: SceneCondition{
 					ConditionType: ConditionTypeTiming,
 					DeviceID:      0,
-					ConditionItem: ConditionItem{
-						Operator:  "",
-						Action:    "",
-						ActionVal: "",
-					},
 				},
 				Timing: 123,
 			},
@@ -676,30 +491,35 @@ func TestConditionInfo_CheckCondition(t *testing.T) {
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
 					ConditionType: ConditionTypeDeviceStatus,
-					DeviceID:      testID,
-					ConditionItem: ConditionItem{
-						Attribute: "power",
-						Action:    types.ActionSwitch,
-					},
+					DeviceID:      f.device.ID,
+					Operator:      OperatorEQ,
+					ConditionAttr: []byte(`{"aid": 1, "val": "on"}`),
 				},
 				Timing: 0,
 			},
 			expectedRes: true,
 		},
+		{
+			// 物模型中没有的事件
+			conditionInfo: ConditionInfo{
+				SceneCondition: SceneCondition{
+					ConditionType:  ConditionTypeDeviceEvent,
+					DeviceID:       f.device.ID,
+					ConditionEvent: []byte(`{"type": "button_press"}`),
+				},
+			},
+			expectedRes: false,
+		},
 	}
 
-	for _, t := range tt {
-		err := t.conditionInfo.CheckCondition(testID)
+	for i, t := range tt {
+		err := t.conditionInfo.CheckCondition(f.member.ID, true)
 		if t.expectedRes {
-			ast.NoError(err)
+			ast.NoError(err, "%v", i)
 		} else {
-			ast.Error(err)
+			ast.Error(err, "%v", i)
 		}
 	}
-
-	_ = DeleteRole(testID)
-	_ = DelUser(testID)
-	_ = DelUserRoleByUid(testID, GetDB())
 }
 
 func TestConditionInfo_checkConditionType(t *testing.T) {
@@ -720,7 +540,7 @@ func TestConditionInfo_checkConditionType(t *testing.T) {
 		{
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
-					ConditionType: 3,
+					ConditionType: 4,
 				},
 			},
 			expectedRes: false,
@@ -733,6 +553,14 @@ func TestConditionInfo_checkConditionType(t *testing.T) {
 			},
 			expectedRes: true,
 		},
+		{
+			conditionInfo: ConditionInfo{
+				SceneCondition: SceneCondition{
+					ConditionType: ConditionTypeDeviceEvent,
+				},
+			},
+			expectedRes: true,
+		},
 	}
 
 	for _, t := range tt {
@@ -756,11 +584,6 @@ func TestConditionInfo_checkConditionTypeTiming(t *testing.T) {
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
 					DeviceID: 0,
-					ConditionItem: ConditionItem{
-						Operator:  "",
-						Action:    "",
-						ActionVal: "",
-					},
 				},
 				Timing: 0,
 			},
@@ -770,11 +593,6 @@ func TestConditionInfo_checkConditionTypeTiming(t *testing.T) {
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
 					DeviceID: 1,
-					ConditionItem: ConditionItem{
-						Operator:  "",
-						Action:    "",
-						ActionVal: "",
-					},
 				},
 				Timing: 132456,
 			},
@@ -784,25 +602,6 @@ func TestConditionInfo_checkConditionTypeTiming(t *testing.T) {
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
 					DeviceID: 0,
-					ConditionItem: ConditionItem{
-						Operator:  OperatorGT,
-						Action:    "",
-						ActionVal: "",
-					},
-				},
-				Timing: 456456,
-			},
-			expectedRes: false,
-		},
-		{
-			conditionInfo: ConditionInfo{
-				SceneCondition: SceneCondition{
-					DeviceID: 0,
-					ConditionItem: ConditionItem{
-						Operator:  "",
-						Action:    "",
-						ActionVal: "",
-					},
 				},
 				Timing: 456456,
 			},
@@ -822,32 +621,7 @@ func TestConditionInfo_checkConditionTypeTiming(t *testing.T) {
 
 func TestConditionInfo_checkConditionDevice(t *testing.T) {
 	ast := assert.New(t)
-
-	const testID = 555
-	target := fmt.Sprintf("device-%v", strconv.Itoa(testID))
-
-	user := User{
-		ID: testID,
-	}
-	_ = CreateUser(&user)
-
-	role := Role{
-		ID: testID,
-	}
-	GetDB().Create(&role)
-
-	var urs = []UserRole{
-		{UserID: testID, RoleID: testID},
-	}
-	_ = CreateUserRole(urs)
-
-	permission := struct {
-		name      string
-		action    string
-		target    string
-		attribute string
-	}{"test", "control", target, "power"}
-	_ = role.AddPermissionForRole(permission.name, permission.action, permission.target, permission.attribute)
+	f := newSceneFixture(t)
 
 	tt := []struct {
 		conditionInfo ConditionInfo
@@ -856,11 +630,8 @@ func TestConditionInfo_checkConditionDevice(t *testing.T) {
 		{
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
-					DeviceID: testID,
-					ConditionItem: ConditionItem{
-						Attribute: "power",
-						Action:    types.ActionSwitch,
-					},
+					DeviceID:      f.device.ID,
+					ConditionAttr: []byte(`{"aid": 1, "val": "on"}`),
 				},
 				Timing: 1,
 			},
@@ -869,24 +640,19 @@ func TestConditionInfo_checkConditionDevice(t *testing.T) {
 		{
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
-					DeviceID: testID,
-					ConditionItem: ConditionItem{
-						Attribute: "power",
-						Action:    types.ActionSwitch,
-					},
+					DeviceID:      f.device.ID,
+					ConditionAttr: []byte(`{"aid": 1, "val": "on"}`),
 				},
 				Timing: 0,
 			},
 			expectedRes: true,
 		},
 		{
+			// 设备不存在时属性没有权限
 			conditionInfo: ConditionInfo{
 				SceneCondition: SceneCondition{
-					DeviceID: 999,
-					ConditionItem: ConditionItem{
-						Attribute: "power",
-						Action:    types.ActionSwitch,
-					},
+					DeviceID:      999999,
+					ConditionAttr: []byte(`{"aid": 1, "val": "on"}`),
 				},
 				Timing: 0,
 			},
@@ -894,251 +660,57 @@ func TestConditionInfo_checkConditionDevice(t *testing.T) {
 		},
 	}
 
-	for _, t := range tt {
-		err := t.conditionInfo.checkConditionDevice(testID)
-		if t.expectedRes {
-			ast.NoError(err)
-		} else {
-			ast.Error(err)
-		}
-	}
-
-	_ = DeleteRole(testID)
-	_ = DelUser(testID)
-	_ = DelUserRoleByUid(testID, GetDB())
-}
-
-func TestGetConditions(t *testing.T) {
-	ast := assert.New(t)
-
-	scene := Scene{
-		Name:           "test1",
-		CreatorID:      1,
-		AutoRun:        true,
-		IsOn:           true,
-		TimePeriodType: TimePeriodTypeAllDay,
-		RepeatType:     RepeatTypeWorkDay,
-		RepeatDate:     "12345",
-		SceneConditions: []SceneCondition{
-			{
-				ConditionType: ConditionTypeDeviceStatus,
-				DeviceID:      1,
-				ConditionItem: ConditionItem{
-					Attribute: "power",
-					Action:    types.ActionSwitch,
-				},
-			},
-		},
-		SceneTasks: []SceneTask{
-			{
-				Type: TaskTypeSmartDevice,
-				SceneTaskDevices: []SceneTaskDevice{
-					{
-						DeviceID:  1,
-						Action:    "switch",
-						Attribute: "power",
-						ActionVal: "on",
-					},
-				},
-			},
-		},
-	}
-
-	GetDB().Create(&scene)
-
-	tt := []struct {
-		deviceID    int
-		attribute   string
-		expectedRes bool
-		isHaveScene bool
-	}{
-		{
-			deviceID:    1,
-			attribute:   "power",
-			expectedRes: true,
-			isHaveScene: true,
-		},
-		{
-			deviceID:    999,
-			attribute:   "power",
-			expectedRes: true,
-			isHaveScene: false,
-		},
-		{
-			deviceID:    1,
-			attribute:   "light",
-			expectedRes: true,
-			isHaveScene: true,
-		},
-	}
-
-	for i, t := range tt {
-		conditions, err := GetConditions(t.deviceID, t.attribute)
-		if t.expectedRes {
-			ast.NoError(err)
-		} else {
-			ast.Error(err)
-		}
-
-		if t.isHaveScene {
-			ast.NotEmpty(conditions)
-		} else {
-			ast.Empty(conditions, "%v", i)
-		}
-	}
-
-	GetDB().Delete(&scene)
-}
-
-func TestGetScenesByCondition(t *testing.T) {
-	ast := assert.New(t)
-
-	scene := Scene{
-		Name:           "test1",
-		CreatorID:      1,
-		AutoRun:        true,
-		IsOn:           true,
-		TimePeriodType: TimePeriodTypeAllDay,
-		RepeatType:     RepeatTypeWorkDay,
-		RepeatDate:     "12345",
-		SceneConditions: []SceneCondition{
-			{
-				ConditionType: ConditionTypeDeviceStatus,
-				DeviceID:      1,
-				ConditionItem: ConditionItem{
-					Attribute: "power",
-					Action:    types.ActionSwitch,
-				},
-			},
-		},
-		SceneTasks: []SceneTask{
-			{
-				Type: TaskTypeSmartDevice,
-				SceneTaskDevices: []SceneTaskDevice{
-					{
-						DeviceID:  1,
-						Action:    "switch",
-						Attribute: "power",
-						ActionVal: "on",
-					},
-				},
-			},
-		},
-	}
-
-	GetDB().Create(&scene)
-
-	tt := []struct {
-		deviceID    int
-		attribute   string
-		expectedRes bool
-		isHaveScene bool
-	}{
-		{
-			deviceID:    1,
-			attribute:   "power",
-			expectedRes: true,
-			isHaveScene: true,
-		},
-		{
-			deviceID:    999,
-			attribute:   "power",
-			expectedRes: true,
-			isHaveScene: false,
-		},
-		{
-			deviceID:    1,
-			attribute:   "light",
-			expectedRes: true,
-			isHaveScene: true,
-		},
-	}
-
 	for i, t := range tt {
-		scenes, err := GetScenesByCondition(t.deviceID, t.attribute)
+		err := t.conditionInfo.checkConditionDevice(f.member.ID, true)
 		if t.expectedRes {
-			ast.NoError(err)
-		} else {
-			ast.Error(err)
-		}
-
-		if t.isHaveScene {
-			ast.NotEmpty(scenes)
+			ast.NoError(err, "%v", i)
 		} else {
-			ast.Empty(scenes, "%v", i)
+			ast.Error(err, "%v", i)
 		}
 	}
-
-	GetDB().Delete(&scene)
 }
 
-//-----------------------------------------------------------
-func TestConditionItem_CheckConditionItem(t *testing.T) {
+func TestSceneCondition_CheckConditionItem(t *testing.T) {
 	ast := assert.New(t)
-
-	const testID = 666
-	target := fmt.Sprintf("device-%v", strconv.Itoa(testID))
-
-	user := User{
-		ID: testID,
-	}
-	_ = CreateUser(&user)
-
-	role := Role{
-		ID: testID,
-	}
-	GetDB().Create(&role)
-
-	var urs = []UserRole{
-		{UserID: testID, RoleID: testID},
-	}
-	_ = CreateUserRole(urs)
-
-	permission := struct {
-		name      string
-		action    string
-		target    string
-		attribute string
-	}{"test", "control", target, "power"}
-	_ = role.AddPermissionForRole(permission.name, permission.action, permission.target, permission.attribute)
-
-	conditionItem := ConditionItem{
-		Attribute: "power",
-		Action:    types.ActionSwitch,
-	}
-
-	err := conditionItem.CheckConditionItem(testID, testID)
-	ast.NoError(err)
-
-	err = conditionItem.CheckConditionItem(3, testID)
-	ast.Error(err)
-
-	_ = DeleteRole(testID)
-	_ = DelUser(testID)
-	_ = DelUserRoleByUid(testID, GetDB())
+	f := newSceneFixture(t)
+
+	cond := SceneCondition{
+		DeviceID:      f.device.ID,
+		Operator:      OperatorEQ,
+		ConditionAttr: []byte(`{"aid": 1, "val": "on"}`),
+	}
+	ast.NoError(cond.CheckConditionItem(f.member.ID, f.device.ID, true))
+	ast.NoError(cond.CheckConditionItem(f.member.ID, f.device.ID, false))
+
+	// 属性不存在时没有读及通知的权限
+	cond.ConditionAttr = []byte(`{"aid": 2, "val": "on"}`)
+	ast.Error(cond.CheckConditionItem(f.member.ID, f.device.ID, true))
+	ast.Error(cond.CheckConditionItem(f.member.ID, f.device.ID, false))
+	ast.Equal(thingmodel.OnOff.Permission, GetPermission(f.device.ID, 1))
+	ast.Zero(GetPermission(f.device.ID, 2))
 }
 
-func TestConditionItem_checkOperatorType(t *testing.T) {
+func TestSceneCondition_checkOperatorType(t *testing.T) {
 	ast := assert.New(t)
 
 	tt := []struct {
-		conditionItem ConditionItem
-		expectedRes   bool
+		condition   SceneCondition
+		expectedRes bool
 	}{
 		{
-			conditionItem: ConditionItem{
+			condition: SceneCondition{
 				Operator: "",
 			},
 			expectedRes: true,
 		},
 		{
-			conditionItem: ConditionItem{
+			condition: SceneCondition{
 				Operator: OperatorLT,
 			},
 			expectedRes: true,
 		},
 		{
-			conditionItem: ConditionItem{
+			condition: SceneCondition{
 				Operator: "hello",
 			},
 			expectedRes: false,
@@ -1146,7 +718,7 @@ func TestConditionItem_checkOperatorType(t *testing.T) {
 	}
 
 	for _, t := range tt {
-		err := t.conditionItem.checkOperatorType()
+		err := t.condition.checkOperatorType()
 		if t.expectedRes {
 			ast.NoError(err)
 		} else {
@@ -1155,29 +727,93 @@ func TestConditionItem_checkOperatorType(t *testing.T) {
 	}
 }
 
-func TestGetConditionItemByConditionID(t *testing.T) {
-	ast := assert.New(t)
+func TestUpdateSceneSort(t *testing.T) {
+	f := newSceneFixture(t)
 
-	conditionItem := ConditionItem{
-		SceneConditionID: 1,
+	scenes := []Scene{f.switchScene("first"), f.switchScene("second")}
+	for i := range scenes {
+		require.NoError(t, CreateScene(&scenes[i]))
 	}
+	for i, s := range scenes {
+		assert.NoError(t, UpdateSceneSort(GetDB(), s.ID, len(scenes)-i, f.area.ID))
+	}
+	assert.Error(t, UpdateSceneSort(GetDB(), scenes[0].ID, 1, f.area.ID+1))
 
-	GetDB().Create(&conditionItem)
-	cs, err := GetConditionItemByConditionID(1)
-	ast.NoError(err)
-	ast.NotEmpty(cs)
-
-	cs, err = GetConditionItemByConditionID(999)
-	ast.Error(err)
-	ast.Empty(cs)
+	res, err := GetScenes(f.area.ID)
+	assert.NoError(t, err)
+	var names []string
+	for _, s := range res {
+		names = append(names, s.Name+strconv.Itoa(s.Sort))
+	}
+	assert.Equal(t, []string{"second1", "first2"}, names)
+}
 
-	GetDB().Delete(&conditionItem)
+func TestConditionEventMatch(t *testing.T) {
+	ce := ConditionEvent{IID: "0x01", Type: "button_press", Params: map[string]interface{}{
+		"press_type": "single",
+		"button":     float64(1), // 从数据库读取的数字为float64
+	}}
+	ev := definer.DeviceEvent{IID: "0x01", Type: "button_press", Params: map[string]interface{}{
+		"press_type": "single",
+		"button":     1,
+	}}
+	assert.True(t, ce.Match(ev))
+
+	ev.Params["button"] = 2
+	assert.False(t, ce.Match(ev))
+
+	// 对象、数组类型的参数不会panic
+	ev.Params["button"] = map[string]interface{}{"id": 1}
+	assert.False(t, ce.Match(ev))
+	ce.Params["button"] = []interface{}{float64(1)}
+	ev.Params["button"] = []interface{}{float64(1)}
+	assert.True(t, ce.Match(ev))
+
+	delete(ev.Params, "press_type")
+	assert.False(t, ce.Match(ev))
+	assert.True(t, ConditionEvent{IID: "0x01", Type: "button_press"}.Match(ev))
+	assert.False(t, ConditionEvent{IID: "0x02", Type: "button_press"}.Match(ev))
 }
 
-func TestDeleteScene(t *testing.T) {
-	err := DeleteScene(3)
+func TestStampUnit(t *testing.T) {
+	area, err := CreateArea("unit", AreaOfHome)
 	assert.NoError(t, err)
-
-	err = DeleteScene(999)
-	assert.Error(t, err)
+	temp := thingmodel.Attribute{AID: 1, Type: "target_temperature", ValType: thingmodel.Float64, Unit: thingmodel.UnitCelsius}
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID:      "unit-thermostat",
+		Services: []thingmodel.Service{{Type: "thermostat", Attributes: []thingmodel.Attribute{temp}}},
+	}}}
+	d := Device{Name: "thermostat", PluginID: "demo", IID: "unit-thermostat", AreaID: area.ID}
+	d.ThingModel, _ = json.Marshal(tm)
+	assert.NoError(t, CreateDevice(&d, GetDB()))
+
+	// 英制用户设置的值以华氏度记录
+	cond := SceneCondition{ConditionType: ConditionTypeDeviceStatus, DeviceID: d.ID, Operator: OperatorGT,
+		ConditionAttr: []byte(`{"aid": 1, "val": 77}`)}
+	assert.NoError(t, cond.StampUnit(thingmodel.UnitSystemImperial))
+	var item Attribute
+	assert.NoError(t, json.Unmarshal(cond.ConditionAttr, &item))
+	assert.Equal(t, thingmodel.UnitFahrenheit, item.Unit)
+	assert.True(t, item.OperateFrom(OperatorGT, 26, thingmodel.UnitCelsius))
+	assert.False(t, item.OperateFrom(OperatorGT, 24, thingmodel.UnitCelsius))
+
+	// 已记录的单位不修改
+	cond.ConditionAttr = []byte(`{"aid": 1, "val": 25, "unit": "°C"}`)
+	assert.NoError(t, cond.StampUnit(thingmodel.UnitSystemImperial))
+	assert.NoError(t, json.Unmarshal(cond.ConditionAttr, &item))
+	assert.Equal(t, thingmodel.UnitCelsius, item.Unit)
+
+	task := SceneTask{Type: TaskTypeSmartDevice, DeviceID: d.ID, Attributes: []byte(`[{"aid": 1, "val": 77}]`)}
+	assert.NoError(t, task.StampUnit(thingmodel.UnitSystemImperial))
+	var attrs []Attribute
+	assert.NoError(t, json.Unmarshal(task.Attributes, &attrs))
+	if assert.Len(t, attrs, 1) {
+		assert.Equal(t, thingmodel.UnitFahrenheit, attrs[0].Unit)
+		assert.Equal(t, float64(25), tm.ValFrom(d.IID, 1, attrs[0].Val, attrs[0].Unit))
+	}
+
+	task.Attributes = []byte(`[{"aid": 1, "val": 25}]`)
+	assert.NoError(t, task.StampUnit(thingmodel.UnitSystemMetric))
+	assert.NoError(t, json.Unmarshal(task.Attributes, &attrs))
+	assert.Equal(t, thingmodel.UnitCelsius, attrs[0].Unit)
 }
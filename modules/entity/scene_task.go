@@ -5,9 +5,11 @@ import (
 
 	"gorm.io/datatypes"
 
+	"github.com/zhiting-tech/smartassistant/modules/types"
 	"github.com/zhiting-tech/smartassistant/modules/types/status"
 	"github.com/zhiting-tech/smartassistant/pkg/errors"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
 // 一个任务仅允许关联一个设备，对应的多个功能点配置；
@@ -33,6 +35,13 @@ type SceneTask struct {
 
 	DeviceID   int            `json:"device_id"`
 	Attributes datatypes.JSON `json:"attributes"` // refer to Attribute
+	Actions    datatypes.JSON `json:"actions"`    // refer to TaskAction
+}
+
+// TaskAction 控制设备任务中需要执行的设备动作
+type TaskAction struct {
+	Action string                 `json:"action"`
+	Inputs map[string]interface{} `json:"inputs"`
 }
 
 func (t SceneTask) TableName() string {
@@ -54,13 +63,20 @@ func CreateSceneTask(sceneTask []SceneTask) (err error) {
 
 // CheckTaskDevice 校验设备任务类型
 func (t SceneTask) CheckTaskDevice(userId int) (err error) {
-	if len(t.Attributes) == 0 || t.DeviceID == 0 {
+	if (len(t.Attributes) == 0 && len(t.Actions) == 0) || t.DeviceID == 0 {
 		err = errors.Newf(status.SceneParamIncorrectErr, "scene_task_devices")
 		return
 	}
 
 	var ds []Attribute
-	if err = json.Unmarshal(t.Attributes, &ds); err != nil {
+	if len(t.Attributes) != 0 {
+		if err = json.Unmarshal(t.Attributes, &ds); err != nil {
+			logger.Error(err)
+			return
+		}
+	}
+	actions, err := t.GetActions()
+	if err != nil {
 		logger.Error(err)
 		return
 	}
@@ -74,6 +90,45 @@ func (t SceneTask) CheckTaskDevice(userId int) (err error) {
 			return
 		}
 	}
+	d, err := GetDeviceByID(t.DeviceID)
+	if err != nil {
+		return
+	}
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return
+	}
+	for _, taskDevice := range ds {
+		val := tm.ValFrom(d.IID, taskDevice.AID, taskDevice.Val, taskDevice.Unit)
+		if _, err = tm.ValidateWrite(d.IID, taskDevice.AID, val); err != nil {
+			err = errors.Wrapf(err, status.AttrValueInvalid, err.Error())
+			return
+		}
+	}
+	for _, ta := range actions {
+		var action thingmodel.Action
+		if action, err = tm.GetAction(d.IID, ta.Action); err != nil {
+			err = errors.New(status.ActionNotFound)
+			return
+		}
+		if err = action.CheckInputs(ta.Inputs); err != nil {
+			err = errors.Wrap(err, status.ActionInputsIncorrect)
+			return
+		}
+		if !up.IsPermit(types.NewDeviceAction(t.DeviceID, ta.Action)) {
+			err = errors.New(status.DeviceOrSceneControlDeny)
+			return
+		}
+	}
+	return
+}
+
+// GetActions 获取任务需要执行的设备动作
+func (t SceneTask) GetActions() (actions []TaskAction, err error) {
+	if len(t.Actions) == 0 {
+		return
+	}
+	err = json.Unmarshal(t.Actions, &actions)
 	return
 }
 
@@ -84,3 +139,34 @@ func (t SceneTask) CheckTaskType() (err error) {
 	}
 	return
 }
+
+// StampUnit 设备任务的属性未记录单位时，记录用户单位制下的单位，以便执行时换算
+func (t *SceneTask) StampUnit(system thingmodel.UnitSystem) (err error) {
+	if t.Type != TaskTypeSmartDevice || len(t.Attributes) == 0 {
+		return
+	}
+	// 设备或物模型不存在时由任务校验返回错误
+	d, e := GetDeviceByID(t.DeviceID)
+	if e != nil {
+		return
+	}
+	tm, e := d.GetThingModel()
+	if e != nil {
+		return
+	}
+	var attrs []json.RawMessage
+	if err = json.Unmarshal(t.Attributes, &attrs); err != nil {
+		return errors.Wrap(err, errors.BadRequest)
+	}
+	for i := range attrs {
+		if attrs[i], err = stampUnit(attrs[i], tm, d.IID, system); err != nil {
+			return
+		}
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return
+	}
+	t.Attributes = datatypes.JSON(data)
+	return
+}
@@ -6,7 +6,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-	"gorm.io/gorm"
+	"github.com/stretchr/testify/require"
 )
 
 type testStruct struct {
@@ -16,37 +16,41 @@ type testStruct struct {
 
 func TestAddSetting(t *testing.T) {
 
+	settingArea, err := CreateArea("setting", AreaOfHome)
+	require.NoError(t, err)
 	var s string
 
-	err := GetSetting("user_credential", &s)
-	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	// 没有设置时不返回错误
+	err = GetSetting("user_credential", &s, settingArea.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, s)
 
 	s = "aaaaaaaa"
-	if err := UpdateSetting("user_credential", &s); err != nil {
+	if err := UpdateSetting("user_credential", &s, settingArea.ID); err != nil {
 		log.Fatalln(err)
 	}
 	var res string
-	GetSetting("user_credential", &res)
+	GetSetting("user_credential", &res, settingArea.ID)
 	assert.Equal(t, s, res)
 
 	s = "bbbbbbbb"
-	if err := UpdateSetting("user_credential", &s); err != nil {
+	if err := UpdateSetting("user_credential", &s, settingArea.ID); err != nil {
 		log.Fatalln(err)
 	}
 
-	GetSetting("user_credential", &res)
+	GetSetting("user_credential", &res, settingArea.ID)
 	assert.Equal(t, s, res)
 
 	var a = testStruct{123, "456"}
-	if err := UpdateSetting("user_credential", &a); err != nil {
+	if err := UpdateSetting("user_credential", &a, settingArea.ID); err != nil {
 		log.Fatalln(err)
 	}
 
-	err = GetSetting("user_credential", &res)
+	err = GetSetting("user_credential", &res, settingArea.ID)
 	assert.IsType(t, &json.UnmarshalTypeError{}, err)
 
 	var b testStruct
-	GetSetting("user_credential", &b)
+	GetSetting("user_credential", &b, settingArea.ID)
 	assert.Equal(t, a, b)
 
 }
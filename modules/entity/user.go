@@ -10,6 +10,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/modules/utils/hash"
 	"github.com/zhiting-tech/smartassistant/pkg/errors"
 	"github.com/zhiting-tech/smartassistant/pkg/rand"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
 type User struct {
@@ -24,6 +25,8 @@ type User struct {
 	CreatedAt   time.Time `json:"created_at"`
 	AvatarID    int       `json:"avatar_id"`
 
+	UnitSystem thingmodel.UnitSystem `json:"unit_system"` // 读取属性时使用的单位制，为空时使用公制
+
 	AreaID             uint64 `gorm:"type:bigint;index"`
 	Area               Area   `gorm:"constraint:OnDelete:CASCADE;"`
 	PasswordUpdateTime time.Time
@@ -47,6 +50,8 @@ type UserInfo struct {
 	Phone         string     `json:"phone"`
 	IsSetPassword bool       `json:"is_set_password"`
 	AvatarUrl     string     `json:"avatar_url"`
+
+	UnitSystem thingmodel.UnitSystem `json:"unit_system"`
 }
 
 func (u User) TableName() string {
@@ -57,6 +62,14 @@ func (u User) BelongsToArea(areaID uint64) bool {
 	return u.AreaID == areaID
 }
 
+// GetUnitSystem 用户选择的单位制，未选择时使用公制
+func (u User) GetUnitSystem() thingmodel.UnitSystem {
+	if u.UnitSystem == "" {
+		return thingmodel.UnitSystemMetric
+	}
+	return u.UnitSystem
+}
+
 func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
 	if u.Nickname == "" {
 		u.Nickname = rand.String(rand.KindAll)
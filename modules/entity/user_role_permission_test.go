@@ -1,30 +1,46 @@
 package entity
 
 import (
-	"github.com/stretchr/testify/assert"
-	"github.com/zhiting-tech/smartassistant/modules/types"
-	"github.com/zhiting-tech/smartassistant/modules/utils"
-
 	"strconv"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/types"
 )
 
+// roleFixture 角色测试使用的家庭，按顺序执行的测试共用
+var roleFixture struct {
+	area Area
+	// managerRole 及 role 为测试中添加的管理员角色和普通角色
+	managerRole, role Role
+	// initManager 及 initMember 为初始化的管理员和成员角色
+	initManager, initMember Role
+	// manager 拥有管理员角色，member 拥有成员角色
+	manager, member User
+	device          Device
+}
+
 func TestAddManagerRole(t *testing.T) {
 	ast := assert.New(t)
 
 	const managerRoleName = "npc"
 	const lengthGreaterThan20RoleName = "123456789123456789123456789"
 
-	areaID := utils.SAAreaID()
-	role, err := AddManagerRoleWithDB(GetDB(), managerRoleName, areaID)
+	area, err := CreateArea("role", AreaOfHome)
+	require.NoError(t, err)
+	roleFixture.area = area
+	role, err := AddManagerRoleWithDB(GetDB(), managerRoleName, area.ID)
 	ast.NoError(err, "add manager role with db error: %v", err)
 	ast.NotEmpty(role)
+	roleFixture.managerRole = role
 
-	role, err = AddManagerRoleWithDB(GetDB(), managerRoleName, areaID)
+	role, err = AddManagerRoleWithDB(GetDB(), managerRoleName, area.ID)
 	ast.NoError(err, "add manager role with db error: %v", err)
-	ast.NotEmpty(role)
+	ast.Equal(roleFixture.managerRole.ID, role.ID)
 
-	_, err = AddManagerRoleWithDB(GetDB(), lengthGreaterThan20RoleName, areaID)
+	_, err = AddManagerRoleWithDB(GetDB(), lengthGreaterThan20RoleName, area.ID)
 	ast.Error(err, "add manager role with db error: %v", err)
 }
 
@@ -32,21 +48,22 @@ func TestAddRole(t *testing.T) {
 	ast := assert.New(t)
 
 	const roleName = "cc"
-	areaID := utils.SAAreaID()
+	areaID := roleFixture.area.ID
 	role, err := AddRole(roleName, areaID)
 	ast.NoError(err, "add role error: %v", err)
 	ast.NotEmpty(role)
+	roleFixture.role = role
 
 	role, err = AddRole(roleName, areaID)
 	ast.NoError(err, "add role error: %v", err)
-	ast.NotEmpty(role)
+	ast.Equal(roleFixture.role.ID, role.ID)
 }
 
 func TestGetRole(t *testing.T) {
 	ast := assert.New(t)
 
-	const existRoleID = 1
-	const notExistRoleID = 9999
+	existRoleID := roleFixture.managerRole.ID
+	const notExistRoleID = 999999
 
 	role, err := GetRoleByID(existRoleID)
 	ast.NoError(err, "get role by id error: %v", err)
@@ -56,59 +73,58 @@ func TestGetRole(t *testing.T) {
 	ast.Error(err, "get role by id error")
 	ast.Empty(role)
 
-	// 填入areaID
-	areaID := utils.SAAreaID()
-	roles, err := GetRoles(areaID)
+	roles, err := GetRoles(roleFixture.area.ID)
 	ast.NoError(err, "get roles error: %v", err)
-	ast.NotEmpty(roles)
+	ast.Len(roles, 2)
 
 	roles, err = GetRolesByIds([]int{})
 	ast.NoError(err, "get roles error: %v", err)
 	ast.Empty(roles)
 
-	roles, err = GetRolesByIds([]int{1, 2})
+	roles, err = GetRolesByIds([]int{roleFixture.managerRole.ID, roleFixture.role.ID})
 	ast.NoError(err, "get roles by ids error: %v", err)
-	ast.NotEmpty(roles)
+	ast.Len(roles, 2)
 
-	roles, err = GetRolesByIds([]int{9999, 8888})
+	roles, err = GetRolesByIds([]int{999999, 888888})
 	ast.NoError(err, "get roles error: %v", err)
 	ast.Empty(roles)
 
-	roles, err = GetRolesByIds([]int{1, 8888})
+	roles, err = GetRolesByIds([]int{roleFixture.managerRole.ID, 888888})
 	ast.NoError(err, "get roles by ids error: %v", err)
-	ast.NotEmpty(roles)
+	ast.Len(roles, 1)
 }
 
 func TestIsRoleNameExist(t *testing.T) {
 	ast := assert.New(t)
 
+	managerRoleID, roleID := roleFixture.managerRole.ID, roleFixture.role.ID
 	var ts = []struct {
 		name        string
 		roleID      int
 		expectedRes bool
 	}{
 		{"npc", 0, true},
-		{"npc", 1, false},
-		{"npc", 2, true},
-		{"npc", 999, true},
+		{"npc", managerRoleID, false},
+		{"npc", roleID, true},
+		{"npc", 999999, true},
 		{"cc", 0, true},
 		{"", 0, false},
-		{"cc", 1, true},
-		{"cc", 2, false},
+		{"cc", managerRoleID, true},
+		{"cc", roleID, false},
 	}
 
-	areaID := utils.SAAreaID()
-	for _, t := range ts {
-		ast.Equal(t.expectedRes, IsRoleNameExist(t.name, t.roleID, areaID))
+	for i, t := range ts {
+		ast.Equal(t.expectedRes, IsRoleNameExist(t.name, t.roleID, roleFixture.area.ID), "%v", i)
 	}
+	ast.False(IsRoleNameExist("npc", 0, roleFixture.area.ID+1))
 }
 
 func TestUpdateRole(t *testing.T) {
 	ast := assert.New(t)
 
-	const existManagerRoleID = 1
-	const existRoleID = 2
-	const notExistRoleID = 9999
+	existManagerRoleID := roleFixture.managerRole.ID
+	existRoleID := roleFixture.role.ID
+	const notExistRoleID = 999999
 	const newRoleName = "new"
 
 	_, err := UpdateRole(existManagerRoleID, newRoleName)
@@ -138,8 +154,8 @@ func TestRole_AddPermissionForRole(t *testing.T) {
 		{"控制开关", "control", "device-2", "power"},
 	}
 
-	managerRole, _ := GetRoleByID(1)
-	role, _ := GetRoleByID(2)
+	managerRole, _ := GetRoleByID(roleFixture.managerRole.ID)
+	role, _ := GetRoleByID(roleFixture.role.ID)
 
 	for _, t := range tt {
 		err := managerRole.AddPermissionForRole(t.name, t.action, t.target, t.attribute)
@@ -150,13 +166,14 @@ func TestRole_AddPermissionForRole(t *testing.T) {
 		err := role.AddPermissionForRole(t.name, t.action, t.target, t.attribute)
 		ast.NoError(err, "add permission for role: %v", err)
 	}
+	ast.True(IsPermit(role.ID, "control", "device-2", "power", GetDB()))
 }
 
 func TestRole_AddPermission(t *testing.T) {
 	ast := assert.New(t)
 
-	managerRole, _ := GetRoleByID(1)
-	role, _ := GetRoleByID(2)
+	managerRole, _ := GetRoleByID(roleFixture.managerRole.ID)
+	role, _ := GetRoleByID(roleFixture.role.ID)
 
 	err := managerRole.addPermission(GetDB(), types.DeviceAdd)
 	ast.NoError(err, "add permission error: %v", err)
@@ -171,20 +188,42 @@ func TestRole_AddPermission(t *testing.T) {
 
 func TestInitRole(t *testing.T) {
 	ast := assert.New(t)
-	areaID := utils.SAAreaID()
+	areaID := roleFixture.area.ID
 	err := InitRole(GetDB(), areaID)
 	ast.NoError(err, "init role error: %v", err)
+
+	roles, err := GetRoles(areaID)
+	ast.NoError(err, "get roles error: %v", err)
+	for _, role := range roles {
+		switch role.Name {
+		case "管理员":
+			roleFixture.initManager = role
+		case "成员":
+			roleFixture.initMember = role
+		}
+	}
+	ast.True(roleFixture.initManager.IsManager)
+	ast.NotZero(roleFixture.initMember.ID)
 }
 
 func TestCreateUserRole(t *testing.T) {
 	ast := assert.New(t)
 
+	areaID := roleFixture.area.ID
+	roleFixture.manager = User{AreaID: areaID}
+	roleFixture.member = User{AreaID: areaID}
+	other := User{AreaID: areaID}
+	for _, u := range []*User{&roleFixture.manager, &roleFixture.member, &other} {
+		require.NoError(t, CreateUser(u, GetDB()))
+	}
+	managerID, memberID := roleFixture.manager.ID, roleFixture.member.ID
+
 	var urs = []UserRole{
-		{UserID: 1, RoleID: 1},
-		{UserID: 1, RoleID: 2},
-		{UserID: 1, RoleID: 3},
-		{UserID: 2, RoleID: 4},
-		{UserID: 3, RoleID: 4},
+		{UserID: managerID, RoleID: roleFixture.managerRole.ID},
+		{UserID: managerID, RoleID: roleFixture.role.ID},
+		{UserID: managerID, RoleID: roleFixture.initManager.ID},
+		{UserID: memberID, RoleID: roleFixture.initMember.ID},
+		{UserID: other.ID, RoleID: roleFixture.initMember.ID},
 	}
 
 	var emptyUrs = []UserRole{}
@@ -199,17 +238,17 @@ func TestCreateUserRole(t *testing.T) {
 func TestGetRoleIdsByUid(t *testing.T) {
 	ast := assert.New(t)
 
-	const existUID1 = 1
-	const existUID2 = 2
-	const notExistUID = 999
+	existUID1 := roleFixture.manager.ID
+	existUID2 := roleFixture.member.ID
+	const notExistUID = 999999
 
 	roleIds, err := GetRoleIdsByUid(existUID1)
 	ast.NoError(err, "get role ids by uid error: %v", err)
-	ast.GreaterOrEqual(len(roleIds), 1)
+	ast.Len(roleIds, 3)
 
 	roleIds, err = GetRoleIdsByUid(existUID2)
 	ast.NoError(err, "get role ids by uid error: %v", err)
-	ast.Equal(len(roleIds), 1)
+	ast.Equal([]int{roleFixture.initMember.ID}, roleIds)
 
 	roleIds, err = GetRoleIdsByUid(notExistUID)
 	ast.NoError(err, "get role ids by uid error: %v", err)
@@ -219,22 +258,21 @@ func TestGetRoleIdsByUid(t *testing.T) {
 func TestGetRolesByUid(t *testing.T) {
 	ast := assert.New(t)
 
-	const existUID1 = 1
-	const existUID2 = 2
-	const notExistUID = 999
-	areaID := utils.SAAreaID()
+	existUID1 := roleFixture.manager.ID
+	existUID2 := roleFixture.member.ID
+	const notExistUID = 999999
 	for i := 0; i < 3; i++ {
 		name := strconv.Itoa(i)
-		_, _ = AddRole(name, areaID)
+		_, _ = AddRole(name, roleFixture.area.ID)
 	}
 
 	roles, err := GetRolesByUid(existUID1)
 	ast.NoError(err, "get roles by uid error: %v", err)
-	ast.GreaterOrEqual(len(roles), 1)
+	ast.Len(roles, 3)
 
 	roles, err = GetRolesByUid(existUID2)
 	ast.NoError(err, "get roles by uid error: %v", err)
-	ast.Equal(len(roles), 1)
+	ast.Len(roles, 1)
 
 	roles, err = GetRolesByUid(notExistUID)
 	ast.NoError(err, "get roles by uid error: %v", err)
@@ -244,9 +282,9 @@ func TestGetRolesByUid(t *testing.T) {
 func TestIsPermit(t *testing.T) {
 	ast := assert.New(t)
 
-	const managerID = 3
-	const memberID = 4
-	const notExistID = 999
+	managerID := roleFixture.initManager.ID
+	memberID := roleFixture.initMember.ID
+	const notExistID = 999999
 
 	var tt = []struct {
 		roleID      int
@@ -263,7 +301,7 @@ func TestIsPermit(t *testing.T) {
 	}
 
 	for _, t := range tt {
-		res := IsPermit(t.roleID, t.permission.Action, t.permission.Target, t.permission.Attribute)
+		res := IsPermit(t.roleID, t.permission.Action, t.permission.Target, t.permission.Attribute, GetDB())
 		ast.Equal(res, t.expectedRes)
 	}
 }
@@ -271,9 +309,9 @@ func TestIsPermit(t *testing.T) {
 func TestIsDeviceControlPermit(t *testing.T) {
 	ast := assert.New(t)
 
-	const managerID = 3
-	const memberID = 4
-	const notExistID = 999
+	managerID := roleFixture.initManager.ID
+	memberID := roleFixture.initMember.ID
+	const notExistID = 999999
 
 	var tt = []struct {
 		roleID      int
@@ -288,7 +326,7 @@ func TestIsDeviceControlPermit(t *testing.T) {
 	}
 
 	for _, t := range tt {
-		res := IsDeviceActionPermit(t.roleID, t.permission.Action)
+		res := IsDeviceActionPermit(t.roleID, t.permission.Action, GetDB())
 		ast.Equal(res, t.expectedRes)
 	}
 }
@@ -296,9 +334,9 @@ func TestIsDeviceControlPermit(t *testing.T) {
 func TestJudgePermit(t *testing.T) {
 	ast := assert.New(t)
 
-	const managerUserID = 1
-	const memberUserID = 2
-	const notExistUserID = 999
+	managerUserID := roleFixture.manager.ID
+	memberUserID := roleFixture.member.ID
+	const notExistUserID = 999999
 
 	var tt = []struct {
 		userID      int
@@ -326,25 +364,26 @@ func TestJudgePermit(t *testing.T) {
 func TestDeviceControlPermit(t *testing.T) {
 	ast := assert.New(t)
 
-	const managerUserID = 1
-	const memberUserID = 2
-	const notExistUserID = 999
-	const exitDeviceID = 2
-	const notExitDeviceID = 999
+	roleFixture.device = createSwitch(t, roleFixture.area.ID, "role-switch")
+	managerUserID := roleFixture.manager.ID
+	memberUserID := roleFixture.member.ID
+	const notExistUserID = 999999
+	exitDeviceID := roleFixture.device.ID
+	const notExitDeviceID = 999999
 
+	target := types.DeviceTarget(exitDeviceID)
 	var ss = []struct {
 		name      string
 		action    string
 		target    string
 		attribute string
 	}{
-		{"控制开关", "control", "device-2", "power"},
-		{"控制调节亮度", "control", "device-2", "brightness"},
-		{"修改设备", "update", "device-2", ""},
-		{"删除设备", "delete", "device-2", ""},
+		{"控制开关", "control", target, "1"},
+		{"修改设备", "update", target, ""},
+		{"删除设备", "delete", target, ""},
 	}
 
-	managerRole, _ := GetRoleByID(3)
+	managerRole, _ := GetRoleByID(roleFixture.initManager.ID)
 
 	for _, s := range ss {
 		_ = managerRole.AddPermissionForRole(s.name, s.action, s.target, s.attribute)
@@ -359,24 +398,26 @@ func TestDeviceControlPermit(t *testing.T) {
 		{managerUserID, notExitDeviceID, false},
 		{memberUserID, exitDeviceID, false},
 		{memberUserID, notExitDeviceID, false},
-		{notExistUserID, notExitDeviceID, false},
 	}
 
-	for _, tc := range tt {
+	for i, tc := range tt {
 		up, err := GetUserPermissions(tc.userID)
 		if err != nil {
 			t.Error(err)
 		}
-		res := up.IsDeviceControlPermit(tc.deviceID)
-		ast.Equal(tc.expectedRes, res)
+		ast.Equal(tc.expectedRes, up.IsDeviceControlPermit(tc.deviceID), "%v", i)
+		ast.Equal(tc.expectedRes, up.IsDeviceAttrControlPermit(tc.deviceID, 1), "%v", i)
+		ast.Equal(tc.expectedRes, IsDeviceControlPermitByAttr(tc.userID, tc.deviceID, 1), "%v", i)
 	}
+	_, err := GetUserPermissions(notExistUserID)
+	ast.Error(err)
 }
 
 func TestRole_DelPermission(t *testing.T) {
 	ast := assert.New(t)
 
-	managerRole, _ := GetRoleByID(1)
-	role, _ := GetRoleByID(2)
+	managerRole, _ := GetRoleByID(roleFixture.managerRole.ID)
+	role, _ := GetRoleByID(roleFixture.role.ID)
 
 	err := managerRole.DelPermission(types.DeviceAdd)
 	ast.NoError(err, "delete permission error: %v", err)
@@ -386,6 +427,7 @@ func TestRole_DelPermission(t *testing.T) {
 
 	err = role.DelPermission(types.DeviceAdd)
 	ast.NoError(err, "delete permission error: %v", err)
+	ast.False(IsDeviceActionPermit(role.ID, types.DeviceAdd.Action, GetDB()))
 
 	err = role.DelPermission(types.RoleAdd)
 	ast.NoError(err, "delete permission error: %v", err)
@@ -394,9 +436,9 @@ func TestRole_DelPermission(t *testing.T) {
 func TestDelUserRoleByUid(t *testing.T) {
 	ast := assert.New(t)
 
-	const existUID1 = 1
-	const existUID2 = 2
-	const notExistUID = 999
+	existUID1 := roleFixture.manager.ID
+	existUID2 := roleFixture.member.ID
+	const notExistUID = 999999
 
 	err := DelUserRoleByUid(existUID1, GetDB())
 	ast.NoError(err, "delete UserRole by Uid error: %v", err)
@@ -405,21 +447,19 @@ func TestDelUserRoleByUid(t *testing.T) {
 
 	err = DelUserRoleByUid(existUID2, GetDB())
 	ast.NoError(err, "delete UserRole by Uid error: %v", err)
-	roleIDs, _ = GetRoleIdsByUid(existUID1)
+	roleIDs, _ = GetRoleIdsByUid(existUID2)
 	ast.Equal(len(roleIDs), 0)
 
 	err = DelUserRoleByUid(notExistUID, GetDB())
 	ast.NoError(err, "delete UserRole by Uid error: %v", err)
-	roleIDs, _ = GetRoleIdsByUid(existUID1)
-	ast.Equal(len(roleIDs), 0)
 }
 
 func TestDeleteRole(t *testing.T) {
 	ast := assert.New(t)
 
-	const exitManagerRID = 1
-	const exitMemberRID = 2
-	const notExistRID = 999
+	exitManagerRID := roleFixture.managerRole.ID
+	exitMemberRID := roleFixture.role.ID
+	const notExistRID = 999999
 
 	err := DeleteRole(exitManagerRID)
 	ast.Error(err, "delete role error")
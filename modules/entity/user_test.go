@@ -1,41 +1,45 @@
 package entity
 
 import (
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// existUserID 用户测试中创建的用户
+var existUserID int
+
 func TestCreateUser(t *testing.T) {
 	ast := assert.New(t)
 
+	area, err := CreateArea("user", AreaOfHome)
+	require.NoError(t, err)
 	var u = User{
-		ID:          1,
 		AccountName: "npc",
 		Password:    "1234",
-		Token:       "1234",
+		AreaID:      area.ID,
 	}
 
-	var errorTokenUser = User{
-		ID:          2,
+	// 用户必须属于某个家庭
+	var errorAreaUser = User{
 		AccountName: "npc",
 		Password:    "1234",
-		Token:       "1234",
 	}
 
-	err := CreateUser(&u)
+	err = CreateUser(&u, GetDB())
 	ast.NoError(err, "create user error: %v", err)
+	existUserID = u.ID
 
-	err = CreateUser(&errorTokenUser)
+	err = CreateUser(&errorAreaUser, GetDB())
 	ast.Error(err, "create user error")
 }
 
 func TestGetUser(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existUserID
 	const notExistID = 999
-	const existToken = "1234"
-	const notExistToken = "9999"
 
 	user, err := GetUserByID(existID)
 	ast.NoError(err, "get user by id error: %v", err)
@@ -44,14 +48,6 @@ func TestGetUser(t *testing.T) {
 	user, err = GetUserByID(notExistID)
 	ast.Error(err, "get user by id error")
 	ast.Empty(user)
-
-	user, err = GetUserByToken(existToken)
-	ast.NoError(err, "get user by token error: %v", err)
-	ast.NotEmpty(user)
-
-	user, err = GetUserByToken(notExistToken)
-	ast.Error(err, "get user by token error")
-	ast.Empty(user)
 }
 
 func TestIsAccountNameExist(t *testing.T) {
@@ -70,15 +66,13 @@ func TestIsAccountNameExist(t *testing.T) {
 func TestEditUser(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existUserID
 	const notExistID = 999
 	const newAccountName = "ccc"
 
 	var updateUser = User{
-		ID:          1,
 		AccountName: newAccountName,
 		Password:    "1234",
-		Token:       "1234",
 	}
 
 	err := EditUser(existID, updateUser)
@@ -93,7 +87,7 @@ func TestEditUser(t *testing.T) {
 func TestDelUser(t *testing.T) {
 	ast := assert.New(t)
 
-	const existID = 1
+	existID := existUserID
 	const notExistID = 999
 
 	err := DelUser(existID)
@@ -6,8 +6,12 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/zhiting-tech/smartassistant/modules/climate"
 	"github.com/zhiting-tech/smartassistant/modules/device"
+	"github.com/zhiting-tech/smartassistant/modules/energy"
 	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/homekit"
+	"github.com/zhiting-tech/smartassistant/modules/mqtt"
 	"github.com/zhiting-tech/smartassistant/modules/plugin"
 	"github.com/zhiting-tech/smartassistant/modules/task"
 	"github.com/zhiting-tech/smartassistant/modules/websocket"
@@ -20,11 +24,13 @@ import (
 
 func RegisterEventFunc(ws *websocket.Server) {
 	event.RegisterEvent(event.AttributeChange, ws.MulticastMsg,
-		UpdateDeviceShadowBeforeExecuteTask, RecordDeviceState)
-	event.RegisterEvent(event.DeviceDecrease, ws.MulticastMsg)
-	event.RegisterEvent(event.DeviceIncrease, ws.MulticastMsg)
-	event.RegisterEvent(event.OnlineStatus, ws.MulticastMsg)
-	event.RegisterEvent(event.ThingModelChange, UpdateThingModel, ws.MulticastMsg)
+		UpdateDeviceShadowBeforeExecuteTask, RecordDeviceState, RecordEnergy, UpdateHomeKit, mqtt.Publish)
+	event.RegisterEvent(event.DeviceDecrease, ws.MulticastMsg, RefreshHomeKit, mqtt.Publish)
+	event.RegisterEvent(event.DeviceIncrease, ws.MulticastMsg, RefreshHomeKit, RefreshClimate, mqtt.Publish)
+	event.RegisterEvent(event.OnlineStatus, ws.MulticastMsg, mqtt.Publish)
+	event.RegisterEvent(event.PluginHealth, ws.MulticastMsg)
+	event.RegisterEvent(event.ThingModelChange, UpdateThingModel, ws.MulticastMsg, mqtt.Publish)
+	event.RegisterEvent(event.DeviceEvent, ws.MulticastMsg, RecordDeviceEvent, TriggerSceneByDeviceEvent)
 }
 
 func UpdateThingModel(em event.EventMessage) (err error) {
@@ -41,6 +47,7 @@ func UpdateThingModel(em event.EventMessage) (err error) {
 	if !isGatewayExist {
 		return
 	}
+	defer homekit.Refresh(areaID)
 	// 更新设备物模型
 	var primaryDevice entity.Device
 	primaryDevice, err = plugin.ThingModelToEntity(iid, tm, pluginID, areaID)
@@ -197,3 +204,101 @@ func RecordDeviceState(em event.EventMessage) (err error) {
 	stateBytes, _ := json.Marshal(state)
 	return entity.InsertDeviceState(d, stateBytes)
 }
+
+// RecordEnergy 设备上报累计用电量时统计用电量
+func RecordEnergy(em event.EventMessage) (err error) {
+	attr := em.GetAttr()
+	if attr == nil {
+		return nil
+	}
+	d, err := entity.GetDeviceByID(em.GetDeviceID())
+	if err != nil {
+		return
+	}
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return
+	}
+	attribute, err := tm.GetAttribute(attr.IID, attr.AID)
+	if err != nil {
+		return
+	}
+	if attribute.Type != thingmodel.Energy.Type {
+		return nil
+	}
+	return energy.Record(d, attr.IID, attribute, attr.Val, em.GetTime())
+}
+
+// UpdateHomeKit 设备属性变化时同步到HomeKit桥接的配件
+func UpdateHomeKit(em event.EventMessage) error {
+	attr := em.GetAttr()
+	if attr == nil {
+		return nil
+	}
+	homekit.UpdateAttribute(em.AreaID, em.GetDeviceID(), attr.IID, attr.AID, attr.Val)
+	return nil
+}
+
+// RefreshHomeKit 设备增减时更新HomeKit桥接的配件
+func RefreshHomeKit(em event.EventMessage) error {
+	homekit.Refresh(em.AreaID)
+	return nil
+}
+
+// RefreshClimate 添加温控器后下发其所在房间的计划或覆盖的设定值
+func RefreshClimate(em event.EventMessage) error {
+	e, ok := em.Param["device"].(entity.Device)
+	if !ok {
+		return nil
+	}
+	d, err := entity.GetPluginDevice(em.AreaID, e.PluginID, e.IID)
+	if err != nil {
+		return err
+	}
+	if !climate.IsThermostat(d) {
+		return nil
+	}
+	_, err = climate.Refresh(d, true)
+	return err
+}
+
+// EventState 设备事件的记录，与属性记录格式兼容，不更新设备影子
+type EventState struct {
+	Type    string                 `json:"type"`
+	ValType thingmodel.ValType     `json:"val_type"`
+	Val     map[string]interface{} `json:"val"`
+	Event   bool                   `json:"event"`
+}
+
+func RecordDeviceEvent(em event.EventMessage) (err error) {
+	d, err := entity.GetDeviceByID(em.GetDeviceID())
+	if err != nil {
+		return
+	}
+	ev := em.GetDeviceEvent()
+	if ev == nil {
+		logger.Warn("device event is nil")
+		return nil
+	}
+	state := EventState{
+		Type:    ev.Type,
+		ValType: thingmodel.JSON,
+		Val:     ev.Params,
+		Event:   true,
+	}
+	stateBytes, _ := json.Marshal(state)
+	return entity.InsertDeviceState(d, stateBytes)
+}
+
+func TriggerSceneByDeviceEvent(em event.EventMessage) error {
+	d, err := entity.GetDeviceByID(em.GetDeviceID())
+	if err != nil {
+		return err
+	}
+	ev := em.GetDeviceEvent()
+	if ev == nil {
+		logger.Warn("device event is nil")
+		return nil
+	}
+	return task.GetManager().DeviceEventTrigger(d, *ev)
+}
@@ -0,0 +1,314 @@
+// Package homekit 通过HAP桥接器将SA的设备添加到苹果家庭App
+package homekit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/hap"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// 一个桥接器最多桥接149个配件（包括桥接器本身共150个）
+const maxAccessories = 149
+
+// Status 家庭的HomeKit桥接状态
+type Status struct {
+	Enabled          bool   `json:"enabled"`
+	Running          bool   `json:"running"`
+	Paired           bool   `json:"paired"`
+	SetupCode        string `json:"setup_code,omitempty"` // 仅拥有者可以查看
+	SetupURI         string `json:"setup_uri,omitempty"`  // 设置码二维码的内容
+	DeviceIDs        []int  `json:"device_ids"`
+	BridgedDeviceIDs []int  `json:"bridged_device_ids"` // 已桥接的设备，不支持的设备不会桥接
+}
+
+type attrKey struct {
+	deviceID int
+	iid      string
+	aid      int
+}
+
+// bridge 家庭的桥接器
+type bridge struct {
+	areaID uint64
+	server *hap.Server
+
+	mu        sync.Mutex
+	deviceIDs []int
+	bindings  map[attrKey][]binding
+	bridged   []int
+}
+
+var (
+	bridgesMu sync.Mutex
+	bridges   = make(map[uint64]*bridge)
+)
+
+func getBridge(areaID uint64) *bridge {
+	bridgesMu.Lock()
+	defer bridgesMu.Unlock()
+	return bridges[areaID]
+}
+
+// refresh 按设备及物模型重新生成配件
+func (b *bridge) refresh() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	devices, err := entity.GetDevices(b.areaID)
+	if err != nil {
+		logger.Errorf("get devices of area %d err: %s", b.areaID, err)
+		return
+	}
+	filter := make(map[int]bool)
+	for _, id := range b.deviceIDs {
+		filter[id] = true
+	}
+
+	var accessories []*hap.Accessory
+	b.bindings = make(map[attrKey][]binding)
+	b.bridged = nil
+	for _, d := range devices {
+		if d.IsSa() || len(filter) != 0 && !filter[d.ID] {
+			continue
+		}
+		tm, err := d.GetThingModel()
+		if err != nil {
+			continue
+		}
+		a, bindings, ok := deviceAccessory(d, tm)
+		if !ok {
+			continue
+		}
+		if len(accessories) >= maxAccessories {
+			logger.Warnf("homekit bridge of area %d exceeds %d accessories", b.areaID, maxAccessories)
+			break
+		}
+		shadow, _ := d.GetShadow()
+		for _, bd := range bindings {
+			if val, err := shadow.Get(bd.iid, bd.attr.AID); err == nil {
+				if v, ok := bd.hapValue(val); ok {
+					if v, ok = bd.char.Normalize(v); ok {
+						bd.char.Value = v
+					}
+				}
+			}
+			if bd.fromHAP != nil && bd.attr.PermissionWrite() {
+				device, tm, bd := d, tm, bd
+				bd.char.OnWrite = func(val interface{}) error {
+					return write(device, tm, bd, val)
+				}
+			}
+			key := attrKey{deviceID: d.ID, iid: bd.iid, aid: bd.attr.AID}
+			b.bindings[key] = append(b.bindings[key], bd)
+		}
+		accessories = append(accessories, a)
+		b.bridged = append(b.bridged, d.ID)
+	}
+	b.server.SetAccessories(accessories...)
+}
+
+// update 设备属性变化时更新特征值
+func (b *bridge) update(deviceID int, iid string, aid int, val interface{}) {
+	b.mu.Lock()
+	bindings := b.bindings[attrKey{deviceID: deviceID, iid: iid, aid: aid}]
+	b.mu.Unlock()
+	for _, bd := range bindings {
+		if v, ok := bd.hapValue(val); ok {
+			b.server.UpdateValue(deviceAID(deviceID), bd.char.IID, v)
+		}
+	}
+}
+
+// write 控制器写入特征值时控制设备
+func write(d entity.Device, tm thingmodel.ThingModel, b binding, val interface{}) error {
+	v, ok := b.deviceValue(val)
+	if !ok {
+		return fmt.Errorf("invalid value %v", val)
+	}
+	if b.unit != "" {
+		v = tm.ValFrom(b.iid, b.attr.AID, v, b.unit)
+	}
+	// HomeKit的取值范围可能比设备大，如亮度可以为0
+	if f, ok := toFloat64(v); ok {
+		if min, ok := toFloat64(b.attr.Min); ok && f < min {
+			v = min
+		}
+		if max, ok := toFloat64(b.attr.Max); ok && f > max {
+			v = max
+		}
+	}
+	v, err := tm.ValidateWrite(b.iid, b.attr.AID, v)
+	if err != nil {
+		return err
+	}
+	req := sdk.SetRequest{Attributes: []sdk.SetAttribute{{IID: b.iid, AID: b.attr.AID, Val: v}}}
+	return plugin.SetAttributes(context.Background(), d.PluginID, d.AreaID, req)
+}
+
+// bridgeName 桥接器在家庭App中显示的名称
+func bridgeName(areaID uint64) string {
+	area, err := entity.GetAreaByID(areaID)
+	if err != nil || area.Name == "" {
+		return "SmartAssistant"
+	}
+	return area.Name
+}
+
+// start 按设置启动家庭的桥接器，调用方需持有bridgesMu
+func start(areaID uint64) error {
+	stop(areaID)
+	s, err := GetSetting(areaID)
+	if err != nil || !s.Enabled {
+		return err
+	}
+	server, err := hap.NewServer(hap.Config{
+		ID:               s.BridgeID,
+		Name:             bridgeName(areaID),
+		Manufacturer:     "zhiting",
+		Model:            "SmartAssistant",
+		FirmwareRevision: types.Version,
+		SetupCode:        s.SetupCode,
+		SetupID:          s.SetupID,
+		Category:         hap.CategoryBridge,
+		Store:            store{areaID: areaID},
+	})
+	if err != nil {
+		return errors.Newf(status.HomeKitStartErr, err.Error())
+	}
+	b := &bridge{areaID: areaID, server: server, deviceIDs: s.DeviceIDs}
+	b.refresh()
+	if err = server.Start(); err != nil {
+		return errors.Newf(status.HomeKitStartErr, err.Error())
+	}
+	bridges[areaID] = b
+	logger.Infof("homekit bridge of area %d started", areaID)
+	return nil
+}
+
+// stop 停止家庭的桥接器，调用方需持有bridgesMu
+func stop(areaID uint64) {
+	if b, ok := bridges[areaID]; ok {
+		b.server.Close()
+		delete(bridges, areaID)
+	}
+}
+
+// Start 启动所有开启了HomeKit桥接的家庭的桥接器
+func Start() {
+	areas, err := entity.GetAreas()
+	if err != nil {
+		logger.Errorf("get areas err: %s", err)
+		return
+	}
+	bridgesMu.Lock()
+	defer bridgesMu.Unlock()
+	for _, area := range areas {
+		var s Setting
+		if err = entity.GetSetting(entity.HomeKitSetting, &s, area.ID); err != nil || !s.Enabled {
+			continue
+		}
+		if err = start(area.ID); err != nil {
+			logger.Errorf("start homekit bridge of area %d err: %s", area.ID, err)
+		}
+	}
+}
+
+// GetStatus 获取家庭的HomeKit桥接状态
+func GetStatus(areaID uint64) (st Status, err error) {
+	s, err := GetSetting(areaID)
+	if err != nil {
+		return
+	}
+	st = Status{
+		Enabled:   s.Enabled,
+		SetupCode: s.SetupCode,
+		DeviceIDs: s.DeviceIDs,
+	}
+	if s.SetupCode != "" {
+		st.SetupURI = hap.SetupURI(s.SetupCode, s.SetupID, hap.CategoryBridge)
+	}
+	if b := getBridge(areaID); b != nil {
+		st.Running = true
+		st.Paired = b.server.IsPaired()
+		b.mu.Lock()
+		st.BridgedDeviceIDs = b.bridged
+		b.mu.Unlock()
+	}
+	return
+}
+
+// Update 开启或关闭桥接，修改桥接的设备，首次开启时生成桥接器ID及设置码
+func Update(areaID uint64, enabled bool, deviceIDs []int) error {
+	for _, id := range deviceIDs {
+		d, err := entity.GetDeviceByID(id)
+		if err != nil || d.AreaID != areaID {
+			return errors.New(status.DeviceNotExist)
+		}
+	}
+	_, err := updateSetting(areaID, func(s *Setting) {
+		if enabled && s.BridgeID == "" {
+			*s = newSetting(*s)
+		}
+		s.Enabled = enabled
+		s.DeviceIDs = deviceIDs
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+
+	bridgesMu.Lock()
+	defer bridgesMu.Unlock()
+	if b, ok := bridges[areaID]; ok && enabled {
+		b.mu.Lock()
+		b.deviceIDs = deviceIDs
+		b.mu.Unlock()
+		go b.refresh()
+		return nil
+	}
+	if !enabled {
+		stop(areaID)
+		return nil
+	}
+	return start(areaID)
+}
+
+// Reset 删除所有配对并重新生成桥接器ID及设置码，家庭App中需要重新添加桥接器
+func Reset(areaID uint64) error {
+	bridgesMu.Lock()
+	defer bridgesMu.Unlock()
+	stop(areaID)
+	s, err := updateSetting(areaID, func(s *Setting) {
+		*s = newSetting(*s)
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	if !s.Enabled {
+		return nil
+	}
+	return start(areaID)
+}
+
+// Refresh 设备增减、修改或物模型变化后更新桥接的配件
+func Refresh(areaID uint64) {
+	if b := getBridge(areaID); b != nil {
+		b.refresh()
+	}
+}
+
+// UpdateAttribute 设备属性变化时通知订阅的控制器
+func UpdateAttribute(areaID uint64, deviceID int, iid string, aid int, val interface{}) {
+	if b := getBridge(areaID); b != nil {
+		b.update(deviceID, iid, aid, val)
+	}
+}
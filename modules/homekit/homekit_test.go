@@ -0,0 +1,136 @@
+package homekit
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/hap"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+func withAID(attr thingmodel.Attribute, aid int) thingmodel.Attribute {
+	attr.AID = aid
+	return attr
+}
+
+func TestLightBulbMapping(t *testing.T) {
+	colorTemp := withAID(thingmodel.ColorTemperature, 3)
+	colorTemp.Min, colorTemp.Max = 2700, 6500
+	attributes := []thingmodel.Attribute{
+		withAID(thingmodel.OnOff, 1), withAID(thingmodel.Brightness, 2), colorTemp,
+	}
+	s, bindings, ok := mapService("light", "0x01", serviceMappings[thingmodel.LightBulbService], attributes)
+	assert.True(t, ok)
+	assert.Equal(t, hap.ServiceLightbulb, s.Type)
+	assert.Len(t, bindings, 3)
+
+	on := bindings[0]
+	v, ok := on.hapValue("on")
+	assert.True(t, ok)
+	assert.Equal(t, true, v)
+	v, ok = on.deviceValue(false)
+	assert.True(t, ok)
+	assert.Equal(t, "off", v)
+
+	// 色温的范围转换为米勒德
+	ct := bindings[2]
+	assert.Equal(t, float64(154), *ct.char.MinValue)
+	assert.Equal(t, float64(370), *ct.char.MaxValue)
+	v, _ = ct.hapValue(4000)
+	assert.Equal(t, float64(250), v)
+	v, _ = ct.deviceValue(250)
+	assert.Equal(t, float64(4000), v)
+
+	// 缺少必需的属性时不映射
+	_, _, ok = mapService("light", "0x01", serviceMappings[thingmodel.LightBulbService], attributes[1:])
+	assert.False(t, ok)
+}
+
+func TestThermostatMapping(t *testing.T) {
+	target := withAID(thingmodel.TargetTemperature, 1)
+	target.Unit, target.Min, target.Max = thingmodel.UnitFahrenheit, 41, 95
+	attributes := []thingmodel.Attribute{target, withAID(thingmodel.HVACMode, 2)}
+	_, bindings, ok := mapService("thermostat", "0x01", serviceMappings[thingmodel.Thermostat], attributes)
+	assert.True(t, ok)
+	assert.Len(t, bindings, 2)
+
+	// 华氏度的设备转换为摄氏度
+	tt := bindings[0]
+	assert.Equal(t, float64(5), *tt.char.MinValue)
+	assert.Equal(t, float64(35), *tt.char.MaxValue)
+	v, _ := tt.hapValue(float64(68))
+	assert.InDelta(t, 20, v, 0.001)
+
+	mode := bindings[1]
+	v, _ = mode.hapValue(thingmodel.HVACModeCool)
+	assert.Equal(t, float64(2), v)
+	v, _ = mode.hapValue(thingmodel.HVACModeDry)
+	assert.Equal(t, float64(3), v)
+	v, ok = mode.deviceValue(1)
+	assert.True(t, ok)
+	assert.Equal(t, thingmodel.HVACModeHeat, v)
+	_, ok = mode.deviceValue(5)
+	assert.False(t, ok)
+}
+
+func TestLockMapping(t *testing.T) {
+	attributes := []thingmodel.Attribute{withAID(thingmodel.LockTargetState, 1)}
+	s, bindings, ok := mapService("lock", "0x01", serviceMappings[thingmodel.Lock], attributes)
+	assert.True(t, ok)
+	assert.Len(t, s.Characteristics, 3) // 包括名称
+	// 没有当前状态时使用目标状态
+	assert.Len(t, bindings, 2)
+	assert.Equal(t, hap.CharLockCurrentState, bindings[0].char.Type)
+	assert.Equal(t, 1, bindings[0].attr.AID)
+	assert.Nil(t, bindings[0].fromHAP)
+}
+
+func TestSetupCode(t *testing.T) {
+	area, err := entity.CreateArea("homekit", entity.AreaOfHome)
+	require.NoError(t, err)
+
+	// 获取状态不生成设置
+	st, err := GetStatus(area.ID)
+	require.NoError(t, err)
+	assert.False(t, st.Enabled)
+	assert.Empty(t, st.SetupCode)
+	assert.Empty(t, st.SetupURI)
+	var count int64
+	require.NoError(t, entity.GetDB().Model(&entity.GlobalSetting{}).
+		Where("type = ? and area_id = ?", entity.HomeKitSetting, area.ID).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// 关闭桥接不生成设置码
+	require.NoError(t, Update(area.ID, false, nil))
+	st, err = GetStatus(area.ID)
+	require.NoError(t, err)
+	assert.Empty(t, st.SetupCode)
+
+	// 首次开启时生成设置码，之后保持不变
+	require.NoError(t, Update(area.ID, true, nil))
+	defer Update(area.ID, false, nil)
+	st, err = GetStatus(area.ID)
+	require.NoError(t, err)
+	assert.True(t, st.Running)
+	assert.Regexp(t, `^\d{3}-\d{2}-\d{3}$`, st.SetupCode)
+	assert.NotEmpty(t, st.SetupURI)
+	code := st.SetupCode
+
+	require.NoError(t, Update(area.ID, false, nil))
+	require.NoError(t, Update(area.ID, true, nil))
+	st, err = GetStatus(area.ID)
+	require.NoError(t, err)
+	assert.Equal(t, code, st.SetupCode)
+}
@@ -0,0 +1,338 @@
+package homekit
+
+import (
+	"encoding/json"
+	"math"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/hap"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// convertFunc 属性值与特征值之间的转换，无法转换时返回false
+type convertFunc func(val interface{}) (interface{}, bool)
+
+// charMapping 特征值与属性的对应关系
+type charMapping struct {
+	attrs    []string        // 对应的属性类型，使用第一个存在的属性
+	char     string          // 特征值类型
+	unit     thingmodel.Unit // 特征值的单位，与属性的单位不同时换算
+	required bool            // 属性不存在时不映射该服务
+	optional bool            // 属性不存在时不添加该特征值，否则使用默认值
+	toHAP    convertFunc
+	fromHAP  convertFunc // 为nil表示只读
+}
+
+type serviceMapping struct {
+	typ   string
+	chars []charMapping
+}
+
+func attrs(types ...string) []string {
+	return types
+}
+
+// serviceMappings 物模型服务对应的HomeKit服务，电池在所有服务中查找，单独映射
+var serviceMappings = map[thingmodel.ServiceType]serviceMapping{
+	thingmodel.LightBulbService: {hap.ServiceLightbulb, []charMapping{
+		{attrs: attrs(thingmodel.OnOff.Type), char: hap.CharOn, required: true, toHAP: onOffToHAP, fromHAP: onOffFromHAP},
+		{attrs: attrs(thingmodel.Brightness.Type), char: hap.CharBrightness, optional: true, toHAP: number, fromHAP: number},
+		{attrs: attrs(thingmodel.ColorTemperature.Type), char: hap.CharColorTemperature, optional: true, toHAP: kelvinToMired, fromHAP: miredToKelvin},
+	}},
+	thingmodel.SwitchService: {hap.ServiceSwitch, []charMapping{
+		{attrs: attrs(thingmodel.OnOff.Type), char: hap.CharOn, required: true, toHAP: onOffToHAP, fromHAP: onOffFromHAP},
+	}},
+	thingmodel.OutletService: {hap.ServiceOutlet, []charMapping{
+		{attrs: attrs(thingmodel.OnOff.Type), char: hap.CharOn, required: true, toHAP: onOffToHAP, fromHAP: onOffFromHAP},
+		{attrs: attrs(thingmodel.OnOff.Type), char: hap.CharOutletInUse, toHAP: onOffToHAP},
+	}},
+	thingmodel.TemperatureSensor: {hap.ServiceTemperatureSensor, []charMapping{
+		{attrs: attrs(thingmodel.Temperature.Type), char: hap.CharCurrentTemperature, unit: thingmodel.UnitCelsius, required: true, toHAP: number},
+	}},
+	thingmodel.HumiditySensor: {hap.ServiceHumiditySensor, []charMapping{
+		{attrs: attrs(thingmodel.Humidity.Type), char: hap.CharCurrentRelativeHumidity, required: true, toHAP: number},
+	}},
+	thingmodel.MotionSensor: {hap.ServiceMotionSensor, []charMapping{
+		{attrs: attrs(thingmodel.MotionDetected.Type), char: hap.CharMotionDetected, required: true, toHAP: boolean},
+	}},
+	thingmodel.LeakSensor: {hap.ServiceLeakSensor, []charMapping{
+		{attrs: attrs(thingmodel.LeakDetected.Type), char: hap.CharLeakDetected, required: true, toHAP: number},
+	}},
+	thingmodel.ContactSensor: {hap.ServiceContactSensor, []charMapping{
+		{attrs: attrs(thingmodel.ContactSensorState.Type), char: hap.CharContactSensorState, required: true, toHAP: number},
+	}},
+	thingmodel.LightSensor: {hap.ServiceLightSensor, []charMapping{
+		{attrs: attrs(thingmodel.CurrentAmbientLightLevel.Type), char: hap.CharCurrentAmbientLightLevel, required: true, toHAP: number},
+	}},
+	// 锁及窗帘没有当前状态时使用目标状态
+	thingmodel.Lock: {hap.ServiceLockMechanism, []charMapping{
+		{attrs: attrs(thingmodel.LockCurrentState.Type, thingmodel.LockTargetState.Type), char: hap.CharLockCurrentState, toHAP: number},
+		{attrs: attrs(thingmodel.LockTargetState.Type), char: hap.CharLockTargetState, required: true, toHAP: number, fromHAP: number},
+	}},
+	thingmodel.CurtainService: {hap.ServiceWindowCovering, []charMapping{
+		{attrs: attrs(thingmodel.CurrentPosition.Type, thingmodel.TargetPosition.Type), char: hap.CharCurrentPosition, toHAP: number},
+		{attrs: attrs(thingmodel.TargetPosition.Type), char: hap.CharTargetPosition, required: true, toHAP: number, fromHAP: number},
+		{attrs: attrs(thingmodel.State.Type), char: hap.CharPositionState, toHAP: number},
+	}},
+	thingmodel.Thermostat: {hap.ServiceThermostat, []charMapping{
+		{attrs: attrs(thingmodel.CurrentTemperature.Type), char: hap.CharCurrentTemperature, unit: thingmodel.UnitCelsius, toHAP: number},
+		{attrs: attrs(thingmodel.TargetTemperature.Type), char: hap.CharTargetTemperature, unit: thingmodel.UnitCelsius, required: true, toHAP: number, fromHAP: number},
+		{attrs: attrs(thingmodel.HVACMode.Type), char: hap.CharTargetHeatingCoolingState, toHAP: hvacModeToHAP, fromHAP: hvacModeFromHAP},
+		{attrs: attrs(thingmodel.HVACAction.Type), char: hap.CharCurrentHeatingCoolingState, toHAP: hvacActionToHAP},
+		// 显示单位只保存在桥接器中
+		{char: hap.CharTemperatureDisplayUnits},
+	}},
+}
+
+var batteryMapping = serviceMapping{hap.ServiceBattery, []charMapping{
+	{attrs: attrs(thingmodel.Battery.Type), char: hap.CharBatteryLevel, required: true, toHAP: number},
+	{attrs: attrs(thingmodel.StatusLowBattery.Type), char: hap.CharStatusLowBattery, toHAP: number},
+}}
+
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func number(val interface{}) (interface{}, bool) {
+	return toFloat64(val)
+}
+
+func boolean(val interface{}) (interface{}, bool) {
+	if b, ok := val.(bool); ok {
+		return b, true
+	}
+	f, ok := toFloat64(val)
+	return f != 0, ok
+}
+
+func onOffToHAP(val interface{}) (interface{}, bool) {
+	s, ok := val.(string)
+	return s == "on", ok
+}
+
+func onOffFromHAP(val interface{}) (interface{}, bool) {
+	on, ok := val.(bool)
+	if !ok {
+		return nil, false
+	}
+	if on {
+		return "on", true
+	}
+	return "off", true
+}
+
+// 色温：物模型为开尔文，HomeKit为mired（1000000/K）
+func kelvinToMired(val interface{}) (interface{}, bool) {
+	k, ok := toFloat64(val)
+	if !ok || k <= 0 {
+		return nil, false
+	}
+	return math.Round(1e6 / k), true
+}
+
+func miredToKelvin(val interface{}) (interface{}, bool) {
+	m, ok := toFloat64(val)
+	if !ok || m <= 0 {
+		return nil, false
+	}
+	return math.Round(1e6 / m), true
+}
+
+// HomeKit的目标工作模式只有关闭、制热、制冷、自动，除湿及送风显示为自动
+var hvacModes = []string{thingmodel.HVACModeOff, thingmodel.HVACModeHeat, thingmodel.HVACModeCool, thingmodel.HVACModeAuto}
+
+func hvacModeToHAP(val interface{}) (interface{}, bool) {
+	mode, ok := val.(string)
+	if !ok {
+		return nil, false
+	}
+	for i, m := range hvacModes {
+		if m == mode {
+			return i, true
+		}
+	}
+	return 3, true
+}
+
+func hvacModeFromHAP(val interface{}) (interface{}, bool) {
+	i, ok := val.(int)
+	if !ok || i < 0 || i >= len(hvacModes) {
+		return nil, false
+	}
+	return hvacModes[i], true
+}
+
+// 当前状态：0空闲，1制热，2制冷
+func hvacActionToHAP(val interface{}) (interface{}, bool) {
+	switch val {
+	case thingmodel.HVACActionHeating:
+		return 1, true
+	case thingmodel.HVACActionCooling:
+		return 2, true
+	}
+	_, ok := val.(string)
+	return 0, ok
+}
+
+// binding 特征值绑定的设备属性
+type binding struct {
+	char    *hap.Characteristic
+	iid     string
+	attr    thingmodel.Attribute
+	unit    thingmodel.Unit
+	toHAP   convertFunc
+	fromHAP convertFunc
+}
+
+// hapValue 将属性值转换为特征值，数值限制在特征值的取值范围内
+func (b binding) hapValue(val interface{}) (interface{}, bool) {
+	if b.unit != "" && b.attr.Unit != "" && b.unit != b.attr.Unit {
+		if f, ok := toFloat64(val); ok {
+			val, _ = thingmodel.Convert(f, b.attr.Unit, b.unit)
+		}
+	}
+	val, ok := b.toHAP(val)
+	if !ok {
+		return nil, false
+	}
+	if f, ok := toFloat64(val); ok {
+		return b.char.Clamp(f), true
+	}
+	return val, true
+}
+
+// deviceValue 将写入的特征值转换为属性值，单位为特征值的单位
+func (b binding) deviceValue(val interface{}) (interface{}, bool) {
+	if b.fromHAP == nil {
+		return nil, false
+	}
+	return b.fromHAP(val)
+}
+
+// setRange 按属性的取值范围设置特征值的取值范围
+func (b binding) setRange() {
+	min, ok1 := toFloat64(b.attr.Min)
+	max, ok2 := toFloat64(b.attr.Max)
+	if !ok1 || !ok2 || min >= max {
+		return
+	}
+	switch b.char.Type {
+	case hap.CharTargetTemperature:
+		if b.attr.Unit != "" {
+			min, _ = thingmodel.Convert(min, b.attr.Unit, thingmodel.UnitCelsius)
+			max, _ = thingmodel.Convert(max, b.attr.Unit, thingmodel.UnitCelsius)
+		}
+		b.char.SetRange(thingmodel.Round(min, 1), thingmodel.Round(max, 1), 0.5)
+	case hap.CharColorTemperature:
+		if min > 0 {
+			b.char.SetRange(math.Round(1e6/max), math.Round(1e6/min), 1)
+		}
+	}
+}
+
+// mapService 将物模型的服务映射为HomeKit服务，缺少必需的属性时返回false
+func mapService(name, iid string, m serviceMapping, attributes []thingmodel.Attribute) (*hap.Service, []binding, bool) {
+	find := func(types []string) (thingmodel.Attribute, bool) {
+		for _, t := range types {
+			for _, a := range attributes {
+				if a.Type == t {
+					return a, true
+				}
+			}
+		}
+		return thingmodel.Attribute{}, false
+	}
+
+	s := hap.NewService(m.typ, name)
+	var bindings []binding
+	for _, cm := range m.chars {
+		attr, ok := find(cm.attrs)
+		if !ok && cm.required {
+			return nil, nil, false
+		}
+		if !ok && cm.optional {
+			continue
+		}
+		c := s.AddCharacteristic(cm.char)
+		if !ok {
+			continue
+		}
+		b := binding{char: c, iid: iid, attr: attr, unit: cm.unit, toHAP: cm.toHAP, fromHAP: cm.fromHAP}
+		b.setRange()
+		bindings = append(bindings, b)
+	}
+	return s, bindings, true
+}
+
+// deviceAID 设备对应配件的aid，桥接器本身为1
+func deviceAID(deviceID int) uint64 {
+	return uint64(deviceID) + 1
+}
+
+// deviceAccessory 根据设备的物模型创建配件并绑定属性，设备没有支持的服务时返回false
+func deviceAccessory(d entity.Device, tm thingmodel.ThingModel) (*hap.Accessory, []binding, bool) {
+	instance, err := tm.GetInstance(d.IID)
+	if err != nil {
+		return nil, nil, false
+	}
+	info, _ := instance.GetInfo()
+	a := hap.NewAccessory(deviceAID(d.ID), hap.AccessoryInfo{
+		Name:             d.Name,
+		Manufacturer:     d.Manufacturer,
+		Model:            d.Model,
+		SerialNumber:     d.IID,
+		FirmwareRevision: info.Version,
+	})
+
+	var (
+		bindings []binding
+		battery  []thingmodel.Attribute
+		mapped   bool
+	)
+	for _, srv := range instance.Services {
+		for _, attr := range srv.Attributes {
+			if attr.Type == thingmodel.Battery.Type || attr.Type == thingmodel.StatusLowBattery.Type {
+				battery = append(battery, attr)
+			}
+		}
+		m, ok := serviceMappings[srv.Type]
+		if !ok {
+			continue
+		}
+		s, bs, ok := mapService(d.Name, d.IID, m, srv.Attributes)
+		if !ok {
+			continue
+		}
+		s.Primary = !mapped
+		a.AddService(s)
+		bindings = append(bindings, bs...)
+		mapped = true
+	}
+	if !mapped {
+		return nil, nil, false
+	}
+	if s, bs, ok := mapService("", d.IID, batteryMapping, battery); ok {
+		// 不支持充电
+		s.AddCharacteristic(hap.CharChargingState).Value = 2
+		a.AddService(s)
+		bindings = append(bindings, bs...)
+	}
+	return a, bindings, true
+}
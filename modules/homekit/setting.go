@@ -0,0 +1,96 @@
+package homekit
+
+import (
+	"sync"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/hap"
+)
+
+// Setting 家庭的HomeKit桥接设置
+type Setting struct {
+	Enabled   bool   `json:"enabled"`
+	DeviceIDs []int  `json:"device_ids"` // 桥接的设备，为空则桥接所有支持的设备
+	BridgeID  string `json:"bridge_id"`  // 桥接器的设备ID，重置后重新生成，控制器视为新的桥接器
+	SetupCode string `json:"setup_code"` // 设置码，在家庭App中添加配件时输入
+	SetupID   string `json:"setup_id"`
+
+	// Data 桥接器的长期密钥及已配对的控制器
+	Data map[string][]byte `json:"data,omitempty"`
+}
+
+// settingMu 设置的读取及修改需要互斥，避免API修改设置时覆盖桥接器保存的配对信息
+var settingMu sync.Mutex
+
+// newSetting 生成新的桥接器ID及设置码
+func newSetting(s Setting) Setting {
+	s.BridgeID = hap.GenerateDeviceID()
+	s.SetupCode = hap.GenerateSetupCode()
+	s.SetupID = hap.GenerateSetupID()
+	s.Data = nil
+	return s
+}
+
+func getSetting(areaID uint64) (s Setting, err error) {
+	err = entity.GetSetting(entity.HomeKitSetting, &s, areaID)
+	return
+}
+
+// GetSetting 获取家庭的HomeKit桥接设置，未开启过桥接时没有设置码
+func GetSetting(areaID uint64) (s Setting, err error) {
+	settingMu.Lock()
+	defer settingMu.Unlock()
+	if s, err = getSetting(areaID); err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+	}
+	return
+}
+
+// updateSetting 修改设置
+func updateSetting(areaID uint64, update func(s *Setting)) (s Setting, err error) {
+	settingMu.Lock()
+	defer settingMu.Unlock()
+	if s, err = getSetting(areaID); err != nil {
+		return
+	}
+	update(&s)
+	err = entity.UpdateSetting(entity.HomeKitSetting, s, areaID)
+	return
+}
+
+// store 将桥接器的数据保存在设置中
+type store struct {
+	areaID uint64
+}
+
+func (st store) Get(key string) ([]byte, error) {
+	settingMu.Lock()
+	defer settingMu.Unlock()
+	s, err := getSetting(st.areaID)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := s.Data[key]
+	if !ok {
+		return nil, hap.ErrNotFound
+	}
+	return v, nil
+}
+
+func (st store) Set(key string, value []byte) error {
+	_, err := updateSetting(st.areaID, func(s *Setting) {
+		if s.Data == nil {
+			s.Data = make(map[string][]byte)
+		}
+		s.Data[key] = value
+	})
+	return err
+}
+
+func (st store) Delete(key string) error {
+	_, err := updateSetting(st.areaID, func(s *Setting) {
+		delete(s.Data, key)
+	})
+	return err
+}
@@ -0,0 +1,291 @@
+package mqtt
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// Home Assistant 的组件
+const (
+	componentLight        = "light"
+	componentSwitch       = "switch"
+	componentSensor       = "sensor"
+	componentBinarySensor = "binary_sensor"
+	componentCover        = "cover"
+	componentLock         = "lock"
+)
+
+// valueTemplate 属性的值发布为JSON，字符串的值带有引号，通过value_json解析后与payload_on等比较
+const valueTemplate = "{{ value_json }}"
+
+// sensorClass 传感器属性对应的设备类别
+type sensorClass struct {
+	deviceClass string
+	stateClass  string
+}
+
+var sensorClasses = map[string]sensorClass{
+	thingmodel.Temperature.Type:              {"temperature", "measurement"},
+	thingmodel.Humidity.Type:                 {"humidity", "measurement"},
+	thingmodel.CurrentAmbientLightLevel.Type: {"illuminance", "measurement"},
+	thingmodel.Battery.Type:                  {"battery", "measurement"},
+	thingmodel.Power.Type:                    {"power", "measurement"},
+	thingmodel.Voltage.Type:                  {"voltage", "measurement"},
+	thingmodel.ElectricCurrent.Type:          {"current", "measurement"},
+	thingmodel.Energy.Type:                   {"energy", "total_increasing"},
+}
+
+// binarySensorClasses 二元传感器属性对应的设备类别，值不为0或false时为ON
+var binarySensorClasses = map[string]string{
+	thingmodel.MotionDetected.Type:     "motion",
+	thingmodel.LeakDetected.Type:       "moisture",
+	thingmodel.ContactSensorState.Type: "door", // 1表示未接触，即门窗打开
+	thingmodel.StatusLowBattery.Type:   "battery",
+}
+
+const binarySensorTemplate = "{{ 'ON' if value_json else 'OFF' }}"
+
+// haEntity Home Assistant 的实体
+type haEntity struct {
+	component string
+	objectID  string
+	config    map[string]interface{}
+}
+
+var invalidIDChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// nodeID 设备在 Home Assistant 中的标识
+func (br *bridge) nodeID(d entity.Device) string {
+	return fmt.Sprintf("sa_%d_%d", br.areaID, d.ID)
+}
+
+// discoveryTopic 实体的发现主题，如 homeassistant/light/sa_1_3/0x01_1/config
+func (br *bridge) discoveryTopic(d entity.Device, e haEntity) string {
+	return fmt.Sprintf("%s/%s/%s/%s/config", br.discoveryPrefix, e.component, br.nodeID(d), e.objectID)
+}
+
+func findAttr(srv thingmodel.Service, types ...string) (thingmodel.Attribute, bool) {
+	for _, t := range types {
+		for _, attr := range srv.Attributes {
+			if attr.Type == t {
+				return attr, true
+			}
+		}
+	}
+	return thingmodel.Attribute{}, false
+}
+
+// discoveryEntities 将设备的服务映射为 Home Assistant 的实体
+func (br *bridge) discoveryEntities(d entity.Device, instance thingmodel.Instance, up entity.UserPermissions) (entities []haEntity) {
+	info, _ := instance.GetInfo()
+	deviceID := strconv.Itoa(d.ID)
+	stateTopic := func(attr thingmodel.Attribute) string {
+		return br.attrTopic(d, attr)
+	}
+	commandTopic := func(attr thingmodel.Attribute) string {
+		return br.attrTopic(d, attr, "set")
+	}
+	newEntity := func(component string, attr thingmodel.Attribute, name string) haEntity {
+		objectID := invalidIDChars.ReplaceAllString(fmt.Sprintf("%s_%d", d.IID, attr.AID), "_")
+		return haEntity{
+			component: component,
+			objectID:  objectID,
+			config: map[string]interface{}{
+				"name":      name,
+				"unique_id": fmt.Sprintf("%s_%s", br.nodeID(d), objectID),
+				// 桥接及设备都在线时可用
+				"availability": []map[string]string{
+					{"topic": br.topic("status")},
+					{"topic": br.topic(deviceID, "status")},
+				},
+				"availability_mode": "all",
+				"device": map[string]interface{}{
+					"identifiers":  []string{br.nodeID(d)},
+					"name":         d.Name,
+					"manufacturer": d.Manufacturer,
+					"model":        d.Model,
+					"sw_version":   info.Version,
+				},
+			},
+		}
+	}
+	writable := func(attr thingmodel.Attribute) bool {
+		return attr.PermissionWrite() && up.IsDeviceAttrControlPermit(d.ID, attr.AID)
+	}
+
+	for _, srv := range instance.Services {
+		switch srv.Type {
+		case thingmodel.LightBulbService:
+			onOff, ok := findAttr(srv, thingmodel.OnOff.Type)
+			if !ok || !writable(onOff) {
+				break
+			}
+			e := newEntity(componentLight, onOff, d.Name)
+			e.config["state_topic"] = stateTopic(onOff)
+			e.config["state_value_template"] = valueTemplate
+			e.config["command_topic"] = commandTopic(onOff)
+			e.config["payload_on"] = "on"
+			e.config["payload_off"] = "off"
+			if brightness, ok := findAttr(srv, thingmodel.Brightness.Type); ok {
+				e.config["brightness_state_topic"] = stateTopic(brightness)
+				e.config["brightness_value_template"] = valueTemplate
+				e.config["brightness_command_topic"] = commandTopic(brightness)
+				e.config["brightness_scale"] = 100
+			}
+			// Home Assistant 的色温单位为米勒德
+			if ct, ok := findAttr(srv, thingmodel.ColorTemperature.Type); ok {
+				e.config["color_temp_state_topic"] = stateTopic(ct)
+				e.config["color_temp_value_template"] = "{{ (1000000 / value_json) | round(0) | int }}"
+				e.config["color_temp_command_topic"] = commandTopic(ct)
+				e.config["color_temp_command_template"] = "{{ (1000000 / value) | round(0) | int }}"
+				min, ok1 := toFloat64(ct.Min)
+				max, ok2 := toFloat64(ct.Max)
+				if ok1 && ok2 && min > 0 && min < max {
+					e.config["min_mireds"] = int(math.Round(1e6 / max))
+					e.config["max_mireds"] = int(math.Round(1e6 / min))
+				}
+			}
+			entities = append(entities, e)
+		case thingmodel.SwitchService, thingmodel.OutletService:
+			onOff, ok := findAttr(srv, thingmodel.OnOff.Type)
+			if !ok || !writable(onOff) {
+				break
+			}
+			e := newEntity(componentSwitch, onOff, d.Name)
+			if srv.Type == thingmodel.OutletService {
+				e.config["device_class"] = "outlet"
+			}
+			e.config["state_topic"] = stateTopic(onOff)
+			e.config["value_template"] = valueTemplate
+			e.config["command_topic"] = commandTopic(onOff)
+			e.config["payload_on"] = "on"
+			e.config["payload_off"] = "off"
+			entities = append(entities, e)
+		case thingmodel.CurtainService:
+			target, ok := findAttr(srv, thingmodel.TargetPosition.Type)
+			if !ok || !writable(target) {
+				break
+			}
+			current, ok := findAttr(srv, thingmodel.CurrentPosition.Type)
+			if !ok {
+				current = target
+			}
+			e := newEntity(componentCover, target, d.Name)
+			e.config["device_class"] = "curtain"
+			e.config["position_topic"] = stateTopic(current)
+			e.config["position_template"] = valueTemplate
+			e.config["set_position_topic"] = commandTopic(target)
+			// 打开、关闭通过设置目标位置实现，不支持停止；全开、全关的位置为目标位置的最大、最小值
+			closed, open := 0, 100
+			if min, ok := toFloat64(target.Min); ok {
+				closed = int(min)
+			}
+			if max, ok := toFloat64(target.Max); ok {
+				open = int(max)
+			}
+			e.config["position_open"] = open
+			e.config["position_closed"] = closed
+			e.config["command_topic"] = commandTopic(target)
+			e.config["payload_open"] = strconv.Itoa(open)
+			e.config["payload_close"] = strconv.Itoa(closed)
+			e.config["payload_stop"] = nil
+			entities = append(entities, e)
+		case thingmodel.Lock:
+			target, ok := findAttr(srv, thingmodel.LockTargetState.Type)
+			if !ok || !writable(target) {
+				break
+			}
+			current, ok := findAttr(srv, thingmodel.LockCurrentState.Type)
+			if !ok {
+				current = target
+			}
+			e := newEntity(componentLock, target, d.Name)
+			e.config["state_topic"] = stateTopic(current)
+			e.config["value_template"] = valueTemplate
+			e.config["command_topic"] = commandTopic(target)
+			e.config["payload_lock"] = "1"
+			e.config["payload_unlock"] = "0"
+			e.config["state_locked"] = "1"
+			e.config["state_unlocked"] = "0"
+			e.config["state_jammed"] = "2"
+			entities = append(entities, e)
+		}
+
+		// 传感器按属性映射，如插座的功率和用电量
+		for _, attr := range srv.Attributes {
+			if !attr.PermissionRead() || attr.PermissionHidden() {
+				continue
+			}
+			if class, ok := sensorClasses[attr.Type]; ok {
+				e := newEntity(componentSensor, attr, fmt.Sprintf("%s %s", d.Name, attr.Type))
+				e.config["device_class"] = class.deviceClass
+				e.config["state_class"] = class.stateClass
+				e.config["state_topic"] = stateTopic(attr)
+				e.config["value_template"] = valueTemplate
+				if unit := attr.InSystem(up.UnitSystem()).Unit; unit != "" {
+					e.config["unit_of_measurement"] = unit
+				}
+				entities = append(entities, e)
+			}
+			if class, ok := binarySensorClasses[attr.Type]; ok {
+				e := newEntity(componentBinarySensor, attr, fmt.Sprintf("%s %s", d.Name, attr.Type))
+				e.config["device_class"] = class
+				e.config["state_topic"] = stateTopic(attr)
+				e.config["value_template"] = binarySensorTemplate
+				entities = append(entities, e)
+			}
+		}
+	}
+	return
+}
+
+// publishDiscovery 发布设备的发现配置，删除物模型变化后不再存在的实体
+func (br *bridge) publishDiscovery(d entity.Device, tm thingmodel.ThingModel, up entity.UserPermissions) {
+	if !br.discovery {
+		return
+	}
+	instance, err := tm.GetInstance(d.IID)
+	if err != nil {
+		return
+	}
+	topics := make(map[string]bool)
+	for _, e := range br.discoveryEntities(d, instance, up) {
+		topic := br.discoveryTopic(d, e)
+		topics[topic] = true
+		br.publishRetained(d.ID, topic, e.config)
+	}
+
+	br.mu.Lock()
+	var removed []string
+	for topic := range br.discovered[d.ID] {
+		if !topics[topic] {
+			removed = append(removed, topic)
+		}
+	}
+	br.discovered[d.ID] = topics
+	br.mu.Unlock()
+	for _, topic := range removed {
+		br.clear(d.ID, topic)
+	}
+}
+
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
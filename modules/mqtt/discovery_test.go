@@ -0,0 +1,167 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/mqtt"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// render 与 Home Assistant 相同渲染发现配置中的模板，value_json 为解析JSON后的值
+func render(t *testing.T, template string, payload string) string {
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(payload), &value), payload)
+	switch template {
+	case valueTemplate:
+		return fmt.Sprint(value)
+	case binarySensorTemplate:
+		if value == nil || value == false || value == 0.0 || value == "" {
+			return "OFF"
+		}
+		return "ON"
+	}
+	t.Fatalf("unsupported template %s", template)
+	return ""
+}
+
+// discoveryConfig 服务器收到的实体的发现配置
+func discoveryConfig(t *testing.T, br *bridge, broker *mqtt.Broker, d entity.Device, component string, aid int) map[string]interface{} {
+	topic := br.discoveryTopic(d, haEntity{component: component, objectID: fmt.Sprintf("%s_%d", d.IID, aid)})
+	var config map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(retained(t, broker, topic)), &config), topic)
+	return config
+}
+
+func createLeakSensor(t *testing.T, areaID uint64, iid string) entity.Device {
+	leak := thingmodel.LeakDetected
+	leak.AID = 1
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID:      iid,
+		Services: []thingmodel.Service{{Type: thingmodel.LeakSensor, Attributes: []thingmodel.Attribute{leak}}},
+	}}}
+	shadow := entity.NewShadow()
+	shadow.UpdateReported(iid, 1, 1)
+	d := entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	d.Shadow, _ = json.Marshal(shadow)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+// createDevice 创建只有一个服务的设备，属性的aid从1开始依次分配，属性有值时记录为设备状态
+func createDevice(t *testing.T, areaID uint64, iid string, srv thingmodel.ServiceType, attrs ...thingmodel.Attribute) entity.Device {
+	shadow := entity.NewShadow()
+	for i := range attrs {
+		attrs[i].AID = i + 1
+		if attrs[i].Val != nil {
+			shadow.UpdateReported(iid, attrs[i].AID, attrs[i].Val)
+		}
+	}
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID:      iid,
+		Services: []thingmodel.Service{{Type: srv, Attributes: attrs}},
+	}}}
+	d := entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	d.Shadow, _ = json.Marshal(shadow)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+func TestDiscoveryRender(t *testing.T) {
+	area, token := createArea(t)
+	d := createSwitch(t, area.ID, "discovery")
+	sensor := createLeakSensor(t, area.ID, "discovery-leak")
+	br, broker := startBridge(t, area, token)
+	testClient.popSets()
+
+	// 每路开关的实体使用各自的状态主题，发布的状态渲染后与 payload_on、payload_off 一致
+	left := discoveryConfig(t, br, broker, d, componentSwitch, 1)
+	right := discoveryConfig(t, br, broker, d, componentSwitch, 2)
+	assert.NotEqual(t, left["state_topic"], right["state_topic"])
+	state := func(config map[string]interface{}) string {
+		return render(t, config["value_template"].(string), retained(t, broker, config["state_topic"].(string)))
+	}
+	assert.Equal(t, left["payload_on"], state(left))
+	assert.Equal(t, right["payload_off"], state(right))
+
+	require.NoError(t, Publish(attributeChange(area.ID, d, 2, "on")))
+	assert.Eventually(t, func() bool {
+		return state(right) == right["payload_on"]
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// 命令主题控制对应的一路开关
+	require.NoError(t, br.set(mqtt.Message{Topic: right["command_topic"].(string), Payload: []byte(right["payload_off"].(string))}))
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 2, Val: "off"}}, testClient.popSets())
+
+	config := discoveryConfig(t, br, broker, sensor, componentBinarySensor, 1)
+	assert.Equal(t, "ON", render(t, config["value_template"].(string), retained(t, broker, config["state_topic"].(string))))
+}
+
+func TestDiscoveryLight(t *testing.T) {
+	area, token := createArea(t)
+	onOff, brightness, ct := thingmodel.OnOff, thingmodel.Brightness, thingmodel.ColorTemperature
+	onOff.Val, brightness.Val, ct.Val = "on", 80, 4000
+	ct.Min, ct.Max = 2700, 6500
+	d := createDevice(t, area.ID, "discovery-light", thingmodel.LightBulbService, onOff, brightness, ct)
+	br, broker := startBridge(t, area, token)
+	testClient.popSets()
+
+	config := discoveryConfig(t, br, broker, d, componentLight, 1)
+	assert.Equal(t, "on", render(t, config["state_value_template"].(string), retained(t, broker, config["state_topic"].(string))))
+	assert.Equal(t, "80", render(t, config["brightness_value_template"].(string), retained(t, broker, config["brightness_state_topic"].(string))))
+	assert.Equal(t, 100.0, config["brightness_scale"])
+	require.NoError(t, br.set(mqtt.Message{Topic: config["brightness_command_topic"].(string), Payload: []byte("50")}))
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 2, Val: 50}}, testClient.popSets())
+
+	// 色温的范围换算为米勒德，色温越高米勒德越小
+	assert.Equal(t, br.attrTopic(d, thingmodel.Attribute{AID: 3, Type: ct.Type}, "set"), config["color_temp_command_topic"])
+	assert.Equal(t, 154.0, config["min_mireds"])
+	assert.Equal(t, 370.0, config["max_mireds"])
+}
+
+func TestDiscoveryCover(t *testing.T) {
+	area, token := createArea(t)
+	target, current := thingmodel.TargetPosition, thingmodel.CurrentPosition
+	current.Val = 30
+	d := createDevice(t, area.ID, "discovery-cover", thingmodel.CurtainService, target, current)
+	br, broker := startBridge(t, area, token)
+	testClient.popSets()
+
+	// 目标位置的最小值为1，关闭时设置为1，不能设置为0
+	config := discoveryConfig(t, br, broker, d, componentCover, 1)
+	assert.Equal(t, 1.0, config["position_closed"])
+	assert.Equal(t, 100.0, config["position_open"])
+	assert.Equal(t, "1", config["payload_close"])
+	assert.Equal(t, "100", config["payload_open"])
+	assert.Equal(t, "30", render(t, config["position_template"].(string), retained(t, broker, config["position_topic"].(string))))
+
+	require.NoError(t, br.set(mqtt.Message{Topic: config["command_topic"].(string), Payload: []byte(config["payload_close"].(string))}))
+	require.NoError(t, br.set(mqtt.Message{Topic: config["set_position_topic"].(string), Payload: []byte("60")}))
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: 1}, {IID: d.IID, AID: 1, Val: 60}}, testClient.popSets())
+}
+
+func TestDiscoveryLock(t *testing.T) {
+	area, token := createArea(t)
+	target, current := thingmodel.LockTargetState, thingmodel.LockCurrentState
+	current.Val = 1
+	d := createDevice(t, area.ID, "discovery-lock", thingmodel.Lock, target, current)
+	br, broker := startBridge(t, area, token)
+	testClient.popSets()
+
+	// 状态使用当前状态，命令设置目标状态
+	config := discoveryConfig(t, br, broker, d, componentLock, 1)
+	assert.Equal(t, config["state_locked"], render(t, config["value_template"].(string), retained(t, broker, config["state_topic"].(string))))
+	assert.Equal(t, br.attrTopic(d, thingmodel.Attribute{AID: 2, Type: current.Type}), config["state_topic"])
+
+	require.NoError(t, br.set(mqtt.Message{Topic: config["command_topic"].(string), Payload: []byte(config["payload_unlock"].(string))}))
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: 0}}, testClient.popSets())
+}
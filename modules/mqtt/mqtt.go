@@ -0,0 +1,487 @@
+// Package mqtt 通过MQTT发布设备的状态，并接收控制命令
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/oauth"
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/device"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/event"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/mqtt"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+const (
+	payloadOnline  = "online"
+	payloadOffline = "offline"
+)
+
+// Status MQTT桥接的状态
+type Status struct {
+	Enabled     bool   `json:"enabled"`
+	Connected   bool   `json:"connected"`
+	Broker      string `json:"broker"`
+	TopicPrefix string `json:"topic_prefix"`
+	AreaID      uint64 `json:"area_id,string"`
+}
+
+// bridge 将集成token所属家庭的设备桥接到MQTT服务器
+type bridge struct {
+	client   *mqtt.Client
+	prefix   string
+	qos      byte
+	token    string
+	areaID   uint64
+	commands chan mqtt.Message
+
+	discovery       bool
+	discoveryPrefix string
+
+	mu         sync.Mutex
+	retained   map[int]map[string]bool // 每个设备发布的保留消息的主题，删除设备时清除
+	discovered map[int]map[string]bool // 每个设备的发现主题
+}
+
+var (
+	mu sync.Mutex
+	b  *bridge
+)
+
+func getBridge() *bridge {
+	mu.Lock()
+	defer mu.Unlock()
+	return b
+}
+
+// Authorize 校验集成token，返回token所属的用户，token需要有设备的权限
+func Authorize(token string) (user entity.User, err error) {
+	ti, err := oauth.GetOauthServer().Manager.LoadAccessToken(token)
+	if err != nil {
+		err = errors.Wrap(err, status.InvalidUserCredentials)
+		return
+	}
+	if ti.GetScope() != types.ScopeAll.Scope && !strings.Contains(ti.GetScope(), types.ScopeDevice.Scope) {
+		err = errors.New(status.Deny)
+		return
+	}
+	uid, _ := strconv.Atoi(ti.GetUserID())
+	return entity.GetUserByID(uid)
+}
+
+// Run 按配置连接MQTT服务器，未配置服务器时不开启，ctx取消后发布离线状态并断开连接
+func Run(ctx context.Context) {
+	conf := config.GetConf().MQTT
+	if !conf.Enabled() {
+		return
+	}
+	user, err := Authorize(conf.Token)
+	if err != nil {
+		logger.Errorf("mqtt: invalid integration token: %s", err)
+		return
+	}
+	if conf.QoS > 2 {
+		logger.Errorf("mqtt: invalid qos %d", conf.QoS)
+		return
+	}
+
+	br := &bridge{
+		prefix:   conf.GetTopicPrefix(),
+		qos:      conf.QoS,
+		token:    conf.Token,
+		areaID:   user.AreaID,
+		commands: make(chan mqtt.Message, 100),
+
+		discovery:       conf.Discovery,
+		discoveryPrefix: conf.GetDiscoveryPrefix(),
+		retained:        make(map[int]map[string]bool),
+		discovered:      make(map[int]map[string]bool),
+	}
+	tlsConfig, err := conf.TLS.TLSConfig()
+	if err != nil {
+		logger.Errorf("mqtt: load tls config err: %s", err)
+		return
+	}
+	clientID := conf.ClientID
+	if clientID == "" {
+		clientID = fmt.Sprintf("sa-%s", config.GetConf().SmartAssistant.ID)
+	}
+	br.client, err = mqtt.NewClient(mqtt.Options{
+		Broker:    conf.Broker,
+		ClientID:  clientID,
+		Username:  conf.Username,
+		Password:  conf.Password,
+		TLSConfig: tlsConfig,
+		KeepAlive: time.Duration(conf.KeepAlive) * time.Second,
+		// 异常断开时由服务器发布离线状态
+		Will: &mqtt.Message{
+			Topic:    br.topic("status"),
+			Payload:  []byte(payloadOffline),
+			QoS:      br.qos,
+			Retained: true,
+		},
+		OnConnect: br.onConnect,
+		OnConnectionLost: func(err error) {
+			logger.Warnf("mqtt: connection lost: %s", err)
+		},
+	})
+	if err != nil {
+		logger.Errorf("mqtt: %s", err)
+		return
+	}
+	if err = br.client.Subscribe(br.topic("+", "+", "+", "+", "set"), br.qos, br.handleSet); err != nil {
+		logger.Errorf("mqtt: subscribe err: %s", err)
+		return
+	}
+	if br.discovery {
+		// Home Assistant 重启后重新发布发现配置
+		err = br.client.Subscribe(br.discoveryPrefix+"/status", br.qos, func(c *mqtt.Client, msg mqtt.Message) {
+			if string(msg.Payload) == payloadOnline {
+				go br.publishDevices()
+			}
+		})
+		if err != nil {
+			logger.Errorf("mqtt: subscribe err: %s", err)
+			return
+		}
+	}
+
+	mu.Lock()
+	b = br
+	mu.Unlock()
+	go br.runCommands(ctx)
+	br.client.Start()
+	logger.Infof("mqtt: bridge area %d to %s", br.areaID, conf.Broker)
+
+	<-ctx.Done()
+	mu.Lock()
+	b = nil
+	mu.Unlock()
+	br.publish(br.topic("status"), []byte(payloadOffline))
+	br.client.Close()
+}
+
+// GetStatus 获取MQTT桥接的状态
+func GetStatus() Status {
+	conf := config.GetConf().MQTT
+	st := Status{
+		Enabled:     conf.Enabled(),
+		Broker:      conf.Broker,
+		TopicPrefix: conf.GetTopicPrefix(),
+	}
+	if br := getBridge(); br != nil {
+		st.Connected = br.client.IsConnected()
+		st.AreaID = br.areaID
+	}
+	return st
+}
+
+// topic 家庭下的主题，如 sa/<area>/<device>/status
+func (br *bridge) topic(levels ...string) string {
+	return strings.Join(append([]string{br.prefix, strconv.FormatUint(br.areaID, 10)}, levels...), "/")
+}
+
+// attrTopic 属性的主题，如 sa/<area>/<device>/<iid>/<aid>/<attr>，同一实例中类型相同的属性通过aid区分
+func (br *bridge) attrTopic(d entity.Device, attr thingmodel.Attribute, levels ...string) string {
+	return br.topic(append([]string{strconv.Itoa(d.ID), d.IID, strconv.Itoa(attr.AID), attr.Type}, levels...)...)
+}
+
+// publish 发布保留消息，内容为空时服务器删除该主题的保留消息
+func (br *bridge) publish(topic string, data []byte) {
+	if err := br.client.Publish(topic, br.qos, true, data); err != nil && err != mqtt.ErrNotConnected {
+		logger.Warnf("mqtt: publish %s err: %s", topic, err)
+	}
+}
+
+// retain 发布设备的保留消息，记录主题以便删除设备时清除
+func (br *bridge) retain(deviceID int, topic string, data []byte) {
+	br.mu.Lock()
+	if br.retained[deviceID] == nil {
+		br.retained[deviceID] = make(map[string]bool)
+	}
+	br.retained[deviceID][topic] = true
+	br.mu.Unlock()
+	br.publish(topic, data)
+}
+
+// publishRetained 发布设备的保留消息，内容编码为JSON，如字符串的值为 "on"
+func (br *bridge) publishRetained(deviceID int, topic string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		logger.Warnf("mqtt: marshal %s err: %s", topic, err)
+		return
+	}
+	br.retain(deviceID, topic, data)
+}
+
+// clear 发布空的保留消息，服务器删除该主题的保留消息
+func (br *bridge) clear(deviceID int, topic string) {
+	br.mu.Lock()
+	delete(br.retained[deviceID], topic)
+	br.mu.Unlock()
+	br.publish(topic, nil)
+}
+
+// clearDeleted 清除已删除设备的保留消息及发现配置
+func (br *bridge) clearDeleted() error {
+	devices, err := entity.GetDevices(br.areaID)
+	if err != nil {
+		return err
+	}
+	exists := make(map[int]bool)
+	for _, d := range devices {
+		exists[d.ID] = true
+	}
+
+	br.mu.Lock()
+	var topics []string
+	for id, ts := range br.retained {
+		if exists[id] {
+			continue
+		}
+		for topic := range ts {
+			topics = append(topics, topic)
+		}
+		delete(br.retained, id)
+		delete(br.discovered, id)
+	}
+	br.mu.Unlock()
+	for _, topic := range topics {
+		br.publish(topic, nil)
+	}
+	return nil
+}
+
+// permissions 集成token所属用户的权限，用户删除或修改密码后token失效
+func (br *bridge) permissions() (up entity.UserPermissions, err error) {
+	user, err := Authorize(br.token)
+	if err != nil {
+		return
+	}
+	return entity.GetUserPermissions(user.ID)
+}
+
+// onConnect 连接后发布在线状态及所有设备的物模型和状态
+func (br *bridge) onConnect(c *mqtt.Client) {
+	br.publish(br.topic("status"), []byte(payloadOnline))
+	br.publishDevices()
+}
+
+// publishDevices 发布所有设备的物模型、状态及发现配置
+func (br *bridge) publishDevices() {
+	up, err := br.permissions()
+	if err != nil {
+		logger.Errorf("mqtt: %s", err)
+		return
+	}
+	devices, err := entity.GetDevices(br.areaID)
+	if err != nil {
+		logger.Errorf("mqtt: get devices err: %s", err)
+		return
+	}
+	for _, d := range devices {
+		if d.IsSa() || !up.IsDeviceControlPermit(d.ID) {
+			continue
+		}
+		tm, err := d.GetThingModel()
+		if err != nil {
+			continue
+		}
+		br.publishThingModel(d, tm, up)
+		br.publishOnline(d, plugin.GetGlobalClient().IsOnline(plugin.Identify{
+			PluginID: d.PluginID,
+			IID:      d.IID,
+			AreaID:   d.AreaID,
+		}))
+
+		instance, err := tm.GetInstance(d.IID)
+		if err != nil {
+			continue
+		}
+		shadow, _ := d.GetShadow()
+		for _, srv := range instance.Services {
+			for _, attr := range srv.Attributes {
+				if val, err := shadow.Get(d.IID, attr.AID); err == nil {
+					br.publishAttr(d, attr, val, up)
+				}
+			}
+		}
+		br.publishDiscovery(d, tm, up)
+	}
+}
+
+func (br *bridge) publishThingModel(d entity.Device, tm thingmodel.ThingModel, up entity.UserPermissions) {
+	instance, err := tm.InSystem(up.UnitSystem()).GetInstance(d.IID)
+	if err != nil {
+		return
+	}
+	br.publishRetained(d.ID, br.topic(strconv.Itoa(d.ID), "thing_model"), instance)
+}
+
+// publishOnline 发布设备的在线状态，与桥接的在线状态相同为 online、offline，不编码为JSON
+func (br *bridge) publishOnline(d entity.Device, online bool) {
+	payload := payloadOffline
+	if online {
+		payload = payloadOnline
+	}
+	br.retain(d.ID, br.topic(strconv.Itoa(d.ID), "status"), []byte(payload))
+}
+
+// publishAttr 发布属性的值，值换算为用户选择的单位制
+func (br *bridge) publishAttr(d entity.Device, attr thingmodel.Attribute, val interface{}, up entity.UserPermissions) {
+	if attr.PermissionHidden() || !attr.PermissionRead() {
+		return
+	}
+	attr.Val = val
+	br.publishRetained(d.ID, br.attrTopic(d, attr), attr.InSystem(up.UnitSystem()).Val)
+}
+
+// Publish 将设备的属性变化、在线状态、设备增减及物模型变化发布到MQTT服务器
+func Publish(em event.EventMessage) error {
+	br := getBridge()
+	if br == nil || em.AreaID != br.areaID {
+		return nil
+	}
+	up, err := br.permissions()
+	if err != nil {
+		return err
+	}
+
+	switch em.EventType {
+	case event.AttributeChange:
+		attr := em.GetAttr()
+		if attr == nil {
+			return nil
+		}
+		d, err := entity.GetDeviceByID(em.GetDeviceID())
+		if err != nil || !up.IsDeviceControlPermit(d.ID) {
+			return err
+		}
+		tm, err := d.GetThingModel()
+		if err != nil {
+			return err
+		}
+		attribute, err := tm.GetAttribute(attr.IID, attr.AID)
+		if err != nil {
+			return err
+		}
+		br.publishAttr(d, attribute, attr.Val, up)
+	case event.OnlineStatus:
+		pluginID, _ := em.Param["plugin_id"].(string)
+		iid, _ := em.Param["iid"].(string)
+		online, _ := em.Param["online"].(bool)
+		d, err := entity.GetPluginDevice(em.AreaID, pluginID, iid)
+		if err != nil || !up.IsDeviceControlPermit(d.ID) {
+			return nil
+		}
+		br.publishOnline(d, online)
+	case event.DeviceIncrease:
+		d, ok := em.Param["device"].(entity.Device)
+		if !ok || d.IsSa() || !up.IsDeviceControlPermit(d.ID) {
+			return nil
+		}
+		if tm, err := d.GetThingModel(); err == nil {
+			br.publishThingModel(d, tm, up)
+			br.publishDiscovery(d, tm, up)
+		}
+	case event.DeviceDecrease:
+		return br.clearDeleted()
+	case event.ThingModelChange:
+		pluginID, _ := em.Param["plugin_id"].(string)
+		tm, ok := em.Param["thing_model"].(thingmodel.ThingModel)
+		if !ok {
+			return nil
+		}
+		for _, ins := range tm.Instances {
+			d, err := entity.GetPluginDevice(em.AreaID, pluginID, ins.IID)
+			if err != nil || d.IsSa() || !up.IsDeviceControlPermit(d.ID) {
+				continue
+			}
+			br.publishThingModel(d, tm, up)
+			br.publishDiscovery(d, tm, up)
+		}
+	}
+	return nil
+}
+
+// handleSet 收到控制命令，按顺序在其他goroutine中执行以免阻塞消息的接收
+func (br *bridge) handleSet(c *mqtt.Client, msg mqtt.Message) {
+	select {
+	case br.commands <- msg:
+	default:
+		logger.Warnf("mqtt: too many commands, drop %s", msg.Topic)
+	}
+}
+
+func (br *bridge) runCommands(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-br.commands:
+			if err := br.set(msg); err != nil {
+				logger.Warnf("mqtt: %s err: %s", msg.Topic, err)
+			}
+		}
+	}
+}
+
+// set 执行控制命令，主题为 sa/<area>/<device>/<iid>/<aid>/<attr>/set，值为JSON，不是合法的JSON时作为字符串
+func (br *bridge) set(msg mqtt.Message) error {
+	levels := strings.Split(strings.TrimPrefix(msg.Topic, br.topic()+"/"), "/")
+	if len(levels) != 5 || levels[4] != "set" {
+		return errors.Newf(status.MQTTParamErr, "topic")
+	}
+	deviceID, err := strconv.Atoi(levels[0])
+	if err != nil {
+		return errors.New(status.DeviceNotExist)
+	}
+	aid, err := strconv.Atoi(levels[2])
+	if err != nil {
+		return errors.Newf(status.MQTTParamErr, "topic")
+	}
+	iid, attrType := levels[1], levels[3]
+
+	up, err := br.permissions()
+	if err != nil {
+		return err
+	}
+	d, err := entity.GetDeviceByID(deviceID)
+	if err != nil || d.AreaID != br.areaID {
+		return errors.New(status.DeviceNotExist)
+	}
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return err
+	}
+	// 属性的类型与主题不一致时可能是物模型变化前的主题，不执行
+	attr, err := tm.GetAttribute(iid, aid)
+	if err != nil || attr.Type != attrType || !attr.PermissionWrite() {
+		return errors.New(status.AttrNotFound)
+	}
+
+	var val interface{}
+	if err = json.Unmarshal(msg.Payload, &val); err != nil {
+		val = string(msg.Payload)
+	}
+	results := device.BatchSetAttributes(context.Background(), br.areaID, up, []device.BatchDevice{{
+		DeviceID:   deviceID,
+		Attributes: []device.BatchAttribute{{IID: iid, AID: aid, Val: val}},
+	}})
+	if r := results[0]; r.Status != device.BatchStatusSuccess {
+		return fmt.Errorf("%s: %s", r.Status, r.Reason)
+	}
+	return nil
+}
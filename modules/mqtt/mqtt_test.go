@@ -0,0 +1,355 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/api/utils/oauth"
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/event"
+	"github.com/zhiting-tech/smartassistant/pkg/mqtt"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// fakeClient 模拟插件，设备都在线，记录下发到设备的属性
+type fakeClient struct {
+	plugin.Client
+
+	mu   sync.Mutex
+	sets []sdk.SetAttribute
+}
+
+func (c *fakeClient) IsOnline(identify plugin.Identify) bool {
+	return true
+}
+
+func (c *fakeClient) SetAttributes(ctx context.Context, pluginID string, areaID uint64, setReq sdk.SetRequest) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sets = append(c.sets, setReq.Attributes...)
+	return nil, nil
+}
+
+func (c *fakeClient) popSets() []sdk.SetAttribute {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sets := c.sets
+	c.sets = nil
+	return sets
+}
+
+var testClient = &fakeClient{}
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	plugin.SetGlobalClient(testClient)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+// createArea 创建家庭及拥有者，返回拥有者的集成token
+func createArea(t *testing.T) (entity.Area, string) {
+	area, err := entity.CreateArea("mqtt", entity.AreaOfHome)
+	require.NoError(t, err)
+	require.NoError(t, entity.InitClient(area.ID))
+	owner := entity.User{AreaID: area.ID}
+	require.NoError(t, entity.CreateUser(&owner, entity.GetDB()))
+	require.NoError(t, entity.SetAreaOwnerID(area.ID, owner.ID, entity.GetDB()))
+	return area, integrationToken(t, owner)
+}
+
+func integrationToken(t *testing.T, user entity.User) string {
+	token, err := oauth.GetIntegrationToken(user.ID, httptest.NewRequest("POST", "/api/mqtt/token", nil), user.AreaID)
+	require.NoError(t, err)
+	return token
+}
+
+// createMember 创建家庭成员，只有设备部分属性的控制权限
+func createMember(t *testing.T, areaID uint64, deviceID int, aids ...int) entity.User {
+	member := entity.User{AreaID: areaID}
+	require.NoError(t, entity.CreateUser(&member, entity.GetDB()))
+	role := entity.Role{Name: "mqtt", AreaID: areaID}
+	require.NoError(t, entity.GetDB().Create(&role).Error)
+	for _, aid := range aids {
+		p := entity.RolePermission{RoleID: role.ID, Action: types.ActionControl,
+			Target: types.DeviceTarget(deviceID), Attribute: strconv.Itoa(aid)}
+		require.NoError(t, entity.GetDB().Create(&p).Error)
+	}
+	require.NoError(t, entity.CreateUserRole([]entity.UserRole{{UserID: member.ID, RoleID: role.ID}}))
+	return member
+}
+
+// createSwitch 创建双路开关，两路开关的属性类型相同，aid分别为1、2
+func createSwitch(t *testing.T, areaID uint64, iid string) entity.Device {
+	left, right := thingmodel.OnOff, thingmodel.OnOff
+	left.AID, right.AID = 1, 2
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID: iid,
+		Services: []thingmodel.Service{
+			{Type: thingmodel.SwitchService, Attributes: []thingmodel.Attribute{left}},
+			{Type: thingmodel.SwitchService, Attributes: []thingmodel.Attribute{right}},
+		},
+	}}}
+	shadow := entity.NewShadow()
+	shadow.UpdateReported(iid, 1, "on")
+	shadow.UpdateReported(iid, 2, "off")
+	d := entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	d.Shadow, _ = json.Marshal(shadow)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+// createGateway 创建网关及其子设备，子设备的实例在网关的物模型中
+func createGateway(t *testing.T, areaID uint64, iid string) (gateway, child entity.Device) {
+	childIID := iid + "-child"
+	onOff := thingmodel.OnOff
+	onOff.AID = 1
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{
+		{IID: iid, Services: []thingmodel.Service{{Type: thingmodel.GatewayService, Attributes: []thingmodel.Attribute{onOff}}}},
+		{IID: childIID, Services: []thingmodel.Service{{Type: thingmodel.LightBulbService, Attributes: []thingmodel.Attribute{onOff}}}},
+	}}
+	data, _ := json.Marshal(tm)
+	gateway = entity.Device{Name: iid, PluginID: "demo", IID: iid, AreaID: areaID, ThingModel: data}
+	require.NoError(t, entity.CreateDevice(&gateway, entity.GetDB()))
+	child = entity.Device{Name: childIID, PluginID: "demo", IID: childIID, ParentIID: iid, AreaID: areaID, ThingModel: data}
+	require.NoError(t, entity.CreateDevice(&child, entity.GetDB()))
+	return
+}
+
+// startBridge 连接内嵌的MQTT服务器，连接并发布所有设备后返回
+func startBridge(t *testing.T, area entity.Area, token string) (*bridge, *mqtt.Broker) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	broker := mqtt.NewBroker(ln, "", "")
+	t.Cleanup(func() { broker.Close() })
+
+	br := &bridge{
+		prefix:   "sa",
+		qos:      1,
+		token:    token,
+		areaID:   area.ID,
+		commands: make(chan mqtt.Message, 100),
+
+		discovery:       true,
+		discoveryPrefix: "homeassistant",
+		retained:        make(map[int]map[string]bool),
+		discovered:      make(map[int]map[string]bool),
+	}
+	connected := make(chan struct{}, 1)
+	br.client, err = mqtt.NewClient(mqtt.Options{
+		Broker:   broker.Addr(),
+		ClientID: fmt.Sprintf("sa-%d", area.ID),
+		OnConnect: func(c *mqtt.Client) {
+			br.onConnect(c)
+			connected <- struct{}{}
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, br.client.Subscribe(br.topic("+", "+", "+", "+", "set"), br.qos, br.handleSet))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mu.Lock()
+	b = br
+	mu.Unlock()
+	go br.runCommands(ctx)
+	br.client.Start()
+	t.Cleanup(func() {
+		cancel()
+		br.client.Close()
+		mu.Lock()
+		b = nil
+		mu.Unlock()
+	})
+	select {
+	case <-connected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("connect timeout")
+	}
+	return br, broker
+}
+
+// retained 等待服务器收到主题的保留消息
+func retained(t *testing.T, broker *mqtt.Broker, topic string) string {
+	var payload string
+	if !assert.Eventually(t, func() bool {
+		msg, ok := broker.Retained(topic)
+		payload = string(msg.Payload)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond, topic) {
+		return ""
+	}
+	return payload
+}
+
+func attributeChange(areaID uint64, d entity.Device, aid int, val interface{}) event.EventMessage {
+	em := event.NewEventMessage(event.AttributeChange, areaID)
+	em.SetDeviceID(d.ID)
+	em.SetAttr(definer.AttributeEvent{IID: d.IID, AID: aid, Val: val})
+	return *em
+}
+
+func TestPublish(t *testing.T) {
+	area, token := createArea(t)
+	d := createSwitch(t, area.ID, "publish")
+	br, broker := startBridge(t, area, token)
+
+	// 在线状态为字符串，属性的值为JSON，类型相同的属性通过aid区分
+	assert.Equal(t, payloadOnline, retained(t, broker, br.topic("status")))
+	assert.Equal(t, payloadOnline, retained(t, broker, br.topic(strconv.Itoa(d.ID), "status")))
+	assert.Equal(t, `"on"`, retained(t, broker, br.topic(strconv.Itoa(d.ID), d.IID, "1", "on_off")))
+	assert.Equal(t, `"off"`, retained(t, broker, br.topic(strconv.Itoa(d.ID), d.IID, "2", "on_off")))
+	var instance thingmodel.Instance
+	require.NoError(t, json.Unmarshal([]byte(retained(t, broker, br.topic(strconv.Itoa(d.ID), "thing_model"))), &instance))
+	assert.Equal(t, d.IID, instance.IID)
+
+	require.NoError(t, Publish(attributeChange(area.ID, d, 2, "on")))
+	assert.Eventually(t, func() bool {
+		msg, _ := broker.Retained(br.topic(strconv.Itoa(d.ID), d.IID, "2", "on_off"))
+		return string(msg.Payload) == `"on"`
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestSetTopic(t *testing.T) {
+	area, token := createArea(t)
+	d := createSwitch(t, area.ID, "set")
+	br, broker := startBridge(t, area, token)
+	testClient.popSets()
+
+	set := func(topic string, payload string) error {
+		return br.set(mqtt.Message{Topic: topic, Payload: []byte(payload)})
+	}
+	deviceID := strconv.Itoa(d.ID)
+	invalid := []struct {
+		topic string
+		code  int
+	}{
+		{br.topic(deviceID, d.IID, "on_off", "set"), status.MQTTParamErr},
+		{br.topic(deviceID, d.IID, "1", "on_off", "get"), status.MQTTParamErr},
+		{br.topic(deviceID, d.IID, "left", "on_off", "set"), status.MQTTParamErr},
+		{br.topic("abc", d.IID, "1", "on_off", "set"), status.DeviceNotExist},
+		{br.topic(deviceID, d.IID, "3", "on_off", "set"), status.AttrNotFound},
+		// aid与属性的类型不一致
+		{br.topic(deviceID, d.IID, "1", "brightness", "set"), status.AttrNotFound},
+		{br.topic(deviceID, "other", "1", "on_off", "set"), status.AttrNotFound},
+	}
+	for _, c := range invalid {
+		err := set(c.topic, `"on"`)
+		if assert.IsType(t, errors.Error{}, err, c.topic) {
+			assert.Equal(t, c.code, err.(errors.Error).Code.Status, c.topic)
+		}
+	}
+	assert.Empty(t, testClient.popSets())
+
+	// 通过服务器发布控制命令，不是合法的JSON时作为字符串，控制主题中aid对应的属性
+	pub, err := mqtt.NewClient(mqtt.Options{Broker: broker.Addr(), ClientID: "pub"})
+	require.NoError(t, err)
+	pub.Start()
+	defer pub.Close()
+	require.Eventually(t, pub.IsConnected, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, pub.Publish(br.attrTopic(d, thingmodel.Attribute{AID: 2, Type: "on_off"}, "set"), 1, false, []byte("on")))
+	require.NoError(t, pub.Publish(br.attrTopic(d, thingmodel.Attribute{AID: 1, Type: "on_off"}, "set"), 1, false, []byte(`"off"`)))
+
+	var sets []sdk.SetAttribute
+	assert.Eventually(t, func() bool {
+		sets = append(sets, testClient.popSets()...)
+		return len(sets) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 2, Val: "on"}, {IID: d.IID, AID: 1, Val: "off"}}, sets)
+}
+
+func TestPermission(t *testing.T) {
+	area, _ := createArea(t)
+	d := createSwitch(t, area.ID, "permission")
+	denied := createSwitch(t, area.ID, "permission-denied")
+	member := createMember(t, area.ID, d.ID, 1)
+	br, broker := startBridge(t, area, integrationToken(t, member))
+	testClient.popSets()
+
+	// 只发布有控制权限的设备
+	assert.Equal(t, `"on"`, retained(t, broker, br.topic(strconv.Itoa(d.ID), d.IID, "1", "on_off")))
+	_, ok := broker.Retained(br.topic(strconv.Itoa(denied.ID), denied.IID, "1", "on_off"))
+	assert.False(t, ok)
+
+	// 没有属性的控制权限时拒绝控制命令
+	err := br.set(mqtt.Message{Topic: br.attrTopic(d, thingmodel.Attribute{AID: 2, Type: "on_off"}, "set"), Payload: []byte("on")})
+	assert.Error(t, err)
+	err = br.set(mqtt.Message{Topic: br.attrTopic(denied, thingmodel.Attribute{AID: 1, Type: "on_off"}, "set"), Payload: []byte("on")})
+	assert.Error(t, err)
+	assert.Empty(t, testClient.popSets())
+
+	assert.NoError(t, br.set(mqtt.Message{Topic: br.attrTopic(d, thingmodel.Attribute{AID: 1, Type: "on_off"}, "set"), Payload: []byte("off")}))
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: "off"}}, testClient.popSets())
+
+	// 集成token失效后拒绝控制命令
+	br.token = "invalid"
+	err = br.set(mqtt.Message{Topic: br.attrTopic(d, thingmodel.Attribute{AID: 1, Type: "on_off"}, "set"), Payload: []byte("on")})
+	if assert.IsType(t, errors.Error{}, err) {
+		assert.Equal(t, status.InvalidUserCredentials, err.(errors.Error).Code.Status)
+	}
+	assert.Empty(t, testClient.popSets())
+}
+
+func TestGatewayChildPermission(t *testing.T) {
+	area, _ := createArea(t)
+	gateway, child := createGateway(t, area.ID, "mqtt-gateway")
+	member := createMember(t, area.ID, gateway.ID, 1)
+	br, _ := startBridge(t, area, integrationToken(t, member))
+	testClient.popSets()
+
+	// 只有网关的控制权限时，不能在网关的主题中指定子设备的iid控制子设备
+	topic := br.topic(strconv.Itoa(gateway.ID), child.IID, "1", "on_off", "set")
+	assert.Error(t, br.set(mqtt.Message{Topic: topic, Payload: []byte("on")}))
+	assert.Empty(t, testClient.popSets())
+
+	topic = br.topic(strconv.Itoa(gateway.ID), gateway.IID, "1", "on_off", "set")
+	assert.NoError(t, br.set(mqtt.Message{Topic: topic, Payload: []byte("on")}))
+	assert.Equal(t, []sdk.SetAttribute{{IID: gateway.IID, AID: 1, Val: "on"}}, testClient.popSets())
+}
+
+func TestAreaFilter(t *testing.T) {
+	area, token := createArea(t)
+	d := createSwitch(t, area.ID, "area")
+	other, _ := createArea(t)
+	od := createSwitch(t, other.ID, "area-other")
+	br, broker := startBridge(t, area, token)
+	testClient.popSets()
+
+	// 不发布其他家庭的设备
+	retained(t, broker, br.topic(strconv.Itoa(d.ID), d.IID, "1", "on_off"))
+	_, ok := broker.Retained(br.topic(strconv.Itoa(od.ID), od.IID, "1", "on_off"))
+	assert.False(t, ok)
+
+	// 忽略其他家庭的事件
+	require.NoError(t, Publish(attributeChange(other.ID, od, 2, "on")))
+	require.NoError(t, Publish(attributeChange(area.ID, d, 2, "on")))
+	assert.Equal(t, `"on"`, retained(t, broker, br.topic(strconv.Itoa(d.ID), d.IID, "2", "on_off")))
+	_, ok = broker.Retained(br.topic(strconv.Itoa(od.ID), od.IID, "2", "on_off"))
+	assert.False(t, ok)
+
+	// 不能通过桥接控制其他家庭的设备
+	err := br.set(mqtt.Message{Topic: br.attrTopic(od, thingmodel.Attribute{AID: 1, Type: "on_off"}, "set"), Payload: []byte("on")})
+	if assert.IsType(t, errors.Error{}, err) {
+		assert.Equal(t, status.DeviceNotExist, err.(errors.Error).Code.Status)
+	}
+	assert.Empty(t, testClient.popSets())
+}
@@ -26,6 +26,10 @@ func LoadPluginFromZip(path string, areaID uint64) (plg Plugin, err error) {
 	dstDir, _ = filepath.Abs(dstDir)
 	logger.Debug(dstDir)
 	pluginPath := pluginBasePath(dstDir)
+	publisher, err := VerifyPackage(pluginPath)
+	if err != nil {
+		return
+	}
 	plgConf, err := LoadPluginConfig(pluginPath)
 	if err != nil {
 		return
@@ -33,6 +37,7 @@ func LoadPluginFromZip(path string, areaID uint64) (plg Plugin, err error) {
 
 	// save plugin info
 	data, _ := json.Marshal(plgConf)
+	resources, _ := json.Marshal(plgConf.Resources)
 	pi := entity.PluginInfo{
 		Name:      plgConf.Name,
 		AreaID:    areaID,
@@ -42,6 +47,8 @@ func LoadPluginFromZip(path string, areaID uint64) (plg Plugin, err error) {
 		ConfigMsg: data,
 		Version:   plgConf.Version,
 		Source:    entity.SourceTypeDevelopment,
+		Publisher: publisher,
+		Resources: resources,
 	}
 	if err = entity.SavePluginInfo(pi); err != nil {
 		return
@@ -63,7 +70,7 @@ func LoadPluginFromZip(path string, areaID uint64) (plg Plugin, err error) {
 			}
 		}()
 
-		_, err = BuildFromDir(pluginPath, plgConf.ID())
+		err = BuildFromDir(pluginPath, plgConf.ID())
 		if err != nil {
 			logger.Errorf("build image err: %v\n", err)
 			return
@@ -113,9 +120,8 @@ func pluginBasePath(path string) (plgPath string) {
 }
 
 // BuildFromDir 从源码编译镜像
-func BuildFromDir(path, tag string) (imageID string, err error) {
-	c := docker.GetClient()
-	return c.BuildFromPath(path, tag)
+func BuildFromDir(path, tag string) (err error) {
+	return GetRuntime().Build(path, tag)
 }
 
 // BuildFromTar 从源码tar压缩包中build镜像
@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"gorm.io/gorm"
 
 	"github.com/zhiting-tech/smartassistant/modules/entity"
@@ -22,6 +25,17 @@ import (
 
 var NotExistErr = errors.New("plugin not exist")
 
+// registrations 插件最近一次注册服务的时间
+var registrations sync.Map
+
+// registeredAt 获取插件最近一次注册服务的时间，未注册过时返回零值
+func registeredAt(pluginID string) time.Time {
+	if v, ok := registrations.Load(pluginID); ok {
+		return v.(time.Time)
+	}
+	return time.Time{}
+}
+
 func NewClient() *client {
 	return &client{
 		clients: make(map[string]*pluginClient),
@@ -77,6 +91,11 @@ func (c *client) Connect(ctx context.Context, identify Identify, authParams map[
 	if err != nil {
 		return
 	}
+	if len(authParams) != 0 {
+		if err = pc.requireCapability(sdk.CapabilityAuth); err != nil {
+			return
+		}
+	}
 	d := pc.Device(identify.IID)
 	das, err = d.Connect(ctx, authParams)
 	if err != nil {
@@ -114,6 +133,92 @@ func (c *client) SetAttributes(ctx context.Context, pluginID string, areaID uint
 	return
 }
 
+func (c *client) GetAttributes(ctx context.Context, pluginID string, getReq sdk.GetRequest) (attrs []sdk.SetAttribute, err error) {
+	data, _ := json.Marshal(getReq)
+	req := proto.GetAttributesReq{
+		Data: data,
+	}
+	logger.Debug("get attributes: ", string(data))
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	cli, err := c.get(pluginID)
+	if err != nil {
+		return
+	}
+	if err = cli.requireCapability(sdk.CapabilityRefresh); err != nil {
+		return
+	}
+	resp, err := cli.protoClient.GetAttributes(ctx, &req)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	var getResp sdk.GetResponse
+	if err = json.Unmarshal(resp.Data, &getResp); err != nil {
+		return
+	}
+	return getResp.Attributes, nil
+}
+
+func (c *client) InvokeAction(ctx context.Context, pluginID string, actionReq sdk.ActionRequest) (outputs map[string]interface{}, err error) {
+	data, _ := json.Marshal(actionReq)
+	req := proto.InvokeActionReq{
+		Data: data,
+	}
+	logger.Debug("invoke action: ", string(data))
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	cli, err := c.get(pluginID)
+	if err != nil {
+		return
+	}
+	if err = cli.requireCapability(sdk.CapabilityActions); err != nil {
+		return
+	}
+	resp, err := cli.protoClient.InvokeAction(ctx, &req)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	var actionResp sdk.ActionResponse
+	if err = json.Unmarshal(resp.Data, &actionResp); err != nil {
+		return
+	}
+	return actionResp.Outputs, nil
+}
+
+// UpdateSettings 下发插件设置
+func (c *client) UpdateSettings(ctx context.Context, pluginID string, settings json.RawMessage) (err error) {
+	logger.Debug("update settings: ", string(settings))
+	cli, err := c.get(pluginID)
+	if err != nil {
+		return
+	}
+	if err = cli.requireCapability(sdk.CapabilitySettings); err != nil {
+		return
+	}
+	if err = cli.updateSettings(ctx, settings); err != nil {
+		logger.Error(err)
+	}
+	return
+}
+
+// Ping 检查插件服务是否存活，旧版本sdk未实现Ping时使用HealthCheck检查
+func (c *client) Ping(ctx context.Context, pluginID string) (err error) {
+	cli, err := c.get(pluginID)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second*5)
+	defer cancel()
+	_, err = cli.protoClient.Ping(ctx, &emptypb.Empty{})
+	if status.Code(err) != codes.Unimplemented {
+		return
+	}
+	_, err = cli.protoClient.HealthCheck(ctx, &proto.HealthCheckReq{})
+	return
+}
+
 func (c *client) IsOnline(identify Identify) bool {
 	cli, err := c.get(identify.PluginID)
 	if err != nil {
@@ -130,6 +235,9 @@ func (c *client) OTA(ctx context.Context, identify Identify, firmwareURL string)
 	if err != nil {
 		return
 	}
+	if err = cli.requireCapability(sdk.CapabilityOTA); err != nil {
+		return
+	}
 
 	return cli.Device(identify.IID).OTA(ctx, firmwareURL)
 }
@@ -141,6 +249,9 @@ func (c *client) get(domain string) (*pluginClient, error) {
 	if ok {
 		return cli, nil
 	}
+	if compatibility, ok := GetCompatibility(domain); ok && !compatibility.Compatible {
+		return nil, compatibility.Err()
+	}
 	return nil, NotExistErr
 }
 
@@ -148,8 +259,10 @@ func (c *client) Add(cli *pluginClient) {
 
 	c.mu.Lock()
 	c.clients[cli.pluginID] = cli
+	registrations.Store(cli.pluginID, time.Now())
 	c.mu.Unlock()
 	go cli.InitDevices()
+	go cli.pushSettings()
 	go c.ListenStateChange(cli.pluginID)
 }
 
@@ -199,6 +312,25 @@ func HandleEvent(cli *pluginClient, ev sdk.Event) (err error) {
 		em := event2.NewEventMessage(event2.AttributeChange, cli.areaID)
 		em.SetDeviceID(d.ID)
 		em.SetAttr(attrEvent)
+		em.SetTime(time.Now())
+		event2.Notify(em)
+	case sdk.DeviceEvent:
+		var d entity.Device
+		var deviceEvent definer.DeviceEvent
+		_ = json.Unmarshal(ev.Data, &deviceEvent)
+
+		d, err = entity.GetPluginDevice(cli.areaID, cli.pluginID, deviceEvent.IID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			logger.Errorf("GetPluginDevice error:%s", err.Error())
+			return
+		}
+
+		em := event2.NewEventMessage(event2.DeviceEvent, cli.areaID)
+		em.SetDeviceID(d.ID)
+		em.SetDeviceEvent(deviceEvent)
 		event2.Notify(em)
 	case sdk.ThingModelChangeEvent:
 		var tme definer.ThingModelEvent
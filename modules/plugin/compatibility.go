@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	status2 "github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/version"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+)
+
+const handshakeTimeout = time.Second * 5
+
+// SupportedProtocolVersions SA支持的插件协议版本
+var SupportedProtocolVersions = []int32{2}
+
+// Compatibility 插件与SA的兼容情况，插件注册服务时通过握手协商
+type Compatibility struct {
+	Compatible      bool     `json:"compatible"`
+	SDKVersion      string   `json:"sdk_version"`
+	ProtocolVersion int32    `json:"protocol_version,omitempty"` // 协商后使用的协议版本
+	Capabilities    []string `json:"capabilities"`               // 插件支持的功能
+	Reason          string   `json:"reason,omitempty"`           // 不兼容的原因
+}
+
+// HasCapability 插件是否支持某个功能
+func (c Compatibility) HasCapability(capability string) bool {
+	for _, name := range c.Capabilities {
+		if name == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Err 插件不兼容时返回错误
+func (c Compatibility) Err() error {
+	if c.Compatible {
+		return nil
+	}
+	return errors.Newf(status2.PluginIncompatible, c.Reason)
+}
+
+// compatibilities 已注册过服务的插件的兼容情况
+var compatibilities sync.Map
+
+// GetCompatibility 获取插件的兼容情况，插件未注册过服务时返回false
+func GetCompatibility(pluginID string) (Compatibility, bool) {
+	v, ok := compatibilities.Load(pluginID)
+	if !ok {
+		return Compatibility{}, false
+	}
+	return v.(Compatibility), true
+}
+
+// handshake 与插件协商协议版本及功能，未实现握手的旧版本插件根据sdk版本判断，
+// 只记录协商的结果，超时等调用失败时返回错误，不记录为不兼容，插件重新注册服务时再次握手
+func handshake(ctx context.Context, pluginID string, cli proto.PluginClient, sdkVersion string) (c Compatibility, err error) {
+	defer func() {
+		if err != nil {
+			return
+		}
+		compatibilities.Store(pluginID, c)
+		if !c.Compatible {
+			logger.Warnf("plugin %s is incompatible: %s", pluginID, c.Reason)
+		}
+	}()
+
+	c.SDKVersion = sdkVersion
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+	req := proto.HandshakeReq{
+		SaVersion:        types.Version,
+		ProtocolVersions: SupportedProtocolVersions,
+		Capabilities:     sdk.Capabilities,
+	}
+	resp, err := cli.Handshake(ctx, &req)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unimplemented:
+		return legacyCompatibility(sdkVersion), nil
+	case codes.FailedPrecondition:
+		// 插件没有SA支持的协议版本
+		c.Reason = fmt.Sprintf("handshake err: %s", status.Convert(err).Message())
+		return c, nil
+	default:
+		return c, err
+	}
+	if _, ok := sdk.NegotiateProtocol(SupportedProtocolVersions, []int32{resp.ProtocolVersion}); !ok {
+		c.Reason = fmt.Sprintf("protocol version %d not supported", resp.ProtocolVersion)
+		return
+	}
+	c.Compatible = true
+	c.SDKVersion = resp.SdkVersion
+	c.ProtocolVersion = resp.ProtocolVersion
+	c.Capabilities = resp.Capabilities
+	return
+}
+
+// legacyCompatibility 旧版本插件使用sdk主版本号作为协议版本
+func legacyCompatibility(sdkVersion string) (c Compatibility) {
+	c.SDKVersion = sdkVersion
+	major, err := version.Major(sdkVersion)
+	if err != nil {
+		c.Reason = fmt.Sprintf("sdk version %s is invalid", sdkVersion)
+		return
+	}
+	if _, ok := sdk.NegotiateProtocol(SupportedProtocolVersions, []int32{int32(major)}); !ok {
+		c.Reason = fmt.Sprintf("sdk version %s not supported", sdkVersion)
+		return
+	}
+	c.Compatible = true
+	c.ProtocolVersion = int32(major)
+	c.Capabilities = sdk.LegacyCapabilities
+	return
+}
+
+// requireCapability 插件不支持某个功能时返回错误
+func (pc *pluginClient) requireCapability(capability string) error {
+	if !pc.compatibility.HasCapability(capability) {
+		return errors.Newf(status2.PluginCapabilityNotSupport, capability)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/sdktesting"
+)
+
+// handshakeClient 握手返回指定错误的插件
+type handshakeClient struct {
+	proto.PluginClient
+	err error
+}
+
+func (c handshakeClient) Handshake(context.Context, *proto.HandshakeReq, ...grpc.CallOption) (*proto.HandshakeResp, error) {
+	return nil, c.err
+}
+
+func newHarnessClient(t *testing.T) proto.PluginClient {
+	return sdktesting.New(t, func(ctx context.Context, devices chan<- sdk.Device) {}).Client
+}
+
+func TestHandshake(t *testing.T) {
+	c, err := handshake(context.Background(), "handshake", newHarnessClient(t), "")
+	require.NoError(t, err)
+	assert.True(t, c.Compatible)
+	assert.Equal(t, sdk.ProtocolVersion, c.ProtocolVersion)
+	assert.Equal(t, sdk.Version, c.SDKVersion)
+	assert.True(t, c.HasCapability(sdk.CapabilityEvents))
+	stored, ok := GetCompatibility("handshake")
+	assert.True(t, ok)
+	assert.Equal(t, c, stored)
+}
+
+func TestHandshakeProtocolMismatch(t *testing.T) {
+	defer func(versions []int32) { SupportedProtocolVersions = versions }(SupportedProtocolVersions)
+	SupportedProtocolVersions = []int32{sdk.ProtocolVersion + 1}
+
+	// 没有共同的协议版本时记录为不兼容，监控不重启插件
+	c, err := handshake(context.Background(), "mismatch", newHarnessClient(t), "")
+	require.NoError(t, err)
+	assert.False(t, c.Compatible)
+	assert.Error(t, c.Err())
+	stored, ok := GetCompatibility("mismatch")
+	assert.True(t, ok)
+	assert.False(t, stored.Compatible)
+}
+
+func TestHandshakeTransportErr(t *testing.T) {
+	// 超时或插件不可用时返回错误，不记录为不兼容
+	for _, code := range []codes.Code{codes.DeadlineExceeded, codes.Unavailable} {
+		pluginID := "transport-" + code.String()
+		_, err := handshake(context.Background(), pluginID, handshakeClient{err: status.Error(code, "err")}, "2.0.0")
+		assert.Equal(t, code, status.Code(err))
+		_, ok := GetCompatibility(pluginID)
+		assert.False(t, ok)
+	}
+}
+
+func TestLegacyCompatibility(t *testing.T) {
+	unimplemented := handshakeClient{err: status.Error(codes.Unimplemented, "unimplemented")}
+	c, err := handshake(context.Background(), "legacy", unimplemented, "2.1.0")
+	require.NoError(t, err)
+	assert.True(t, c.Compatible)
+	assert.Equal(t, int32(2), c.ProtocolVersion)
+	assert.Equal(t, sdk.LegacyCapabilities, c.Capabilities)
+	assert.False(t, c.HasCapability(sdk.CapabilityEvents))
+
+	assert.False(t, legacyCompatibility("1.0.0").Compatible)
+	assert.False(t, legacyCompatibility("invalid").Compatible)
+}
@@ -24,6 +24,16 @@ func SetAttributes(ctx context.Context, pluginID string, areaID uint64, setReq s
 	return
 }
 
+// GetAttributes 通过插件主动读取设备属性的最新值
+func GetAttributes(ctx context.Context, pluginID string, getReq sdk.GetRequest) ([]sdk.SetAttribute, error) {
+	return GetGlobalClient().GetAttributes(ctx, pluginID, getReq)
+}
+
+// InvokeAction 通过插件执行设备的动作
+func InvokeAction(ctx context.Context, pluginID string, actionReq sdk.ActionRequest) (map[string]interface{}, error) {
+	return GetGlobalClient().InvokeAction(ctx, pluginID, actionReq)
+}
+
 // OTA 更新插件的设备的固件
 func OTA(ctx context.Context, areaID uint64, pluginID, iid, firmwareURL string) (err error) {
 	d, err := entity.GetPluginDevice(areaID, pluginID, iid)
@@ -10,13 +10,17 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/zhiting-tech/smartassistant/modules/config"
 	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin/registry"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 	"github.com/zhiting-tech/smartassistant/pkg/reverseproxy"
 )
 
 const (
 	etcdURL = "http://etcd:2379"
+	// pluginRegistryAddr 插件进程访问注册中心的地址
+	pluginRegistryAddr = "http://127.0.0.1:2379"
 
 	managerTarget = "/sa/plugins"
 )
@@ -35,6 +39,14 @@ func EndPointsManager() (manager endpoints.Manager, err error) {
 	return em, nil
 }
 
+// registryType 插件使用的注册中心类型，通过环境变量 SA_REGISTRY 传给插件
+func registryType() string {
+	if config.GetConf().SmartAssistant.IsBuiltinRegistry() {
+		return "builtin"
+	}
+	return "etcd"
+}
+
 type discovery struct {
 	client *client
 }
@@ -45,19 +57,11 @@ func NewDiscovery(cli *client) *discovery {
 	}
 }
 
-// Listen 监听etcd发现服务
+// Listen 监听注册中心（etcd或内置注册中心）发现服务
 func (m *discovery) Listen(ctx context.Context) (err error) {
 	logger.Println("start discovering service")
-	em, err := EndPointsManager()
-	if err != nil {
-		logger.Error("get endpoint manager err:", err.Error())
-		return
-	}
-
-	// watch etcd service onDeviceStateChange
-	w, err := em.NewWatchChannel(ctx)
+	w, err := m.watch(ctx)
 	if err != nil {
-		logger.Error("new watch channel err:", err.Error())
 		return
 	}
 
@@ -70,6 +74,26 @@ func (m *discovery) Listen(ctx context.Context) (err error) {
 	}
 	return
 }
+
+func (m *discovery) watch(ctx context.Context) (w endpoints.WatchChannel, err error) {
+	if config.GetConf().SmartAssistant.IsBuiltinRegistry() {
+		return registry.Default().Watch(ctx, managerTarget+"/"), nil
+	}
+	em, err := EndPointsManager()
+	if err != nil {
+		logger.Error("get endpoint manager err:", err.Error())
+		return
+	}
+
+	// watch etcd service onDeviceStateChange
+	w, err = em.NewWatchChannel(ctx)
+	if err != nil {
+		logger.Error("new watch channel err:", err.Error())
+		return
+	}
+	return
+}
+
 func (m *discovery) handleUpdates(updates []*endpoints.Update) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -96,7 +120,7 @@ func (m *discovery) handleUpdates(updates []*endpoints.Update) (err error) {
 func (m *discovery) registerService(key string, endpoint endpoints.Endpoint) error {
 
 	service := strings.TrimPrefix(key, managerTarget+"/")
-	logger.Debugf("register service %s:%s from etcd", service, endpoint.Addr)
+	logger.Debugf("register service %s:%s from registry", service, endpoint.Addr)
 
 	if err := reverseproxy.RegisterUpstream(service, endpoint.Addr); err != nil {
 		return err
@@ -121,7 +145,7 @@ func (m *discovery) registerService(key string, endpoint endpoints.Endpoint) err
 func (m *discovery) unregisterService(key string) error {
 
 	service := strings.TrimPrefix(key, managerTarget+"/")
-	logger.Debugf("unregister service %s from etcd", service)
+	logger.Debugf("unregister service %s from registry", service)
 	if err := reverseproxy.UnregisterUpstream(service); err != nil {
 		return err
 	}
@@ -3,9 +3,12 @@ package docker
 import (
 	"context"
 	"errors"
+	"io"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 	"github.com/zhiting-tech/smartassistant/pkg/regex"
@@ -167,3 +170,62 @@ func (c *Client) ContainerList() ([]types.Container, error) {
 	ctx := context.Background()
 	return c.DockerClient.ContainerList(ctx, types.ContainerListOptions{})
 }
+
+// ContainerInspectByImage 返回镜像对应容器（包括已停止的容器）的详情
+func (c *Client) ContainerInspectByImage(image string) (info types.ContainerJSON, err error) {
+	ctx := context.Background()
+	var containers []types.Container
+	containers, err = c.DockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return
+	}
+	for _, con := range containers {
+		if con.Image == image {
+			return c.DockerClient.ContainerInspect(ctx, con.ID)
+		}
+	}
+	err = errors.New("not found")
+	return
+}
+
+// ContainerStartByImage 启动镜像对应的已停止的容器
+func (c *Client) ContainerStartByImage(image string) (err error) {
+	ctx := context.Background()
+	var containers []types.Container
+	containers, err = c.DockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return
+	}
+	for _, con := range containers {
+		if con.Image == image {
+			logger.Debugf("start container %s:%s", con.ID, image)
+			return c.DockerClient.ContainerStart(ctx, con.ID, types.ContainerStartOptions{})
+		}
+	}
+	return errors.New("not found")
+}
+
+// ContainerOOMEvents 监听带有标签 label 的容器被OOM kill的事件
+func (c *Client) ContainerOOMEvents(ctx context.Context, label string) (<-chan events.Message, <-chan error) {
+	args := filters.NewArgs(
+		filters.Arg("type", events.ContainerEventType),
+		filters.Arg("event", "oom"),
+		filters.Arg("label", label),
+	)
+	return c.DockerClient.Events(ctx, types.EventsOptions{Filters: args})
+}
+
+// ContainerLogsByImage 获取镜像对应容器（包括已停止的容器）的日志
+// 容器未使用tty，返回的数据中stdout与stderr是复用的，需要使用stdcopy分离
+func (c *Client) ContainerLogsByImage(ctx context.Context, image string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	containers, err := c.DockerClient.ContainerList(ctx, types.ContainerListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, con := range containers {
+		if con.Image == image {
+			return c.DockerClient.ContainerLogs(ctx, con.ID, options)
+		}
+	}
+	return nil, errors.New("not found")
+}
@@ -111,6 +111,12 @@ func (c *Client) Pull(refStr string) (err error) {
 	return
 }
 
+// ImageTag 为镜像添加标签
+func (c *Client) ImageTag(source, target string) (err error) {
+	logger.Debugf("tag image %s as %s", source, target)
+	return c.DockerClient.ImageTag(context.Background(), source, target)
+}
+
 // ImageRemove 删除镜像
 func (c *Client) ImageRemove(refStr string) (err error) {
 	if !c.IsImageAdd(refStr) {
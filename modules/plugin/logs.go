@@ -0,0 +1,184 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	LogStreamStdout = "stdout"
+	LogStreamStderr = "stderr"
+
+	maxLogLineSize = 1 << 20
+
+	DefaultLogTail = 200  // 未指定行数时返回的日志行数
+	MaxLogTail     = 5000 // 最多返回的日志行数
+)
+
+// LimitLogTail 未指定时使用默认行数，不超过最多返回的行数
+func LimitLogTail(tail int) int {
+	if tail <= 0 {
+		return DefaultLogTail
+	}
+	if tail > MaxLogTail {
+		return MaxLogTail
+	}
+	return tail
+}
+
+// LogOptions 获取插件日志的参数
+type LogOptions struct {
+	Since  time.Time // 只返回该时间之后的日志
+	Tail   int       // 只返回最后的N行，0表示全部
+	Follow bool      // 持续返回新的日志
+	Level  string    // 只返回不低于该级别的日志，无法识别级别的日志（如panic堆栈）总是返回
+}
+
+// LogLine 插件的一行日志
+type LogLine struct {
+	Time    time.Time `json:"time"`
+	Stream  string    `json:"stream"` // stdout 或 stderr
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message"`
+}
+
+func (l LogLine) String() string {
+	return fmt.Sprintf("%s %s %s\n", l.Time.Format(time.RFC3339Nano), l.Stream, l.Message)
+}
+
+// 日志级别，值越大级别越高
+var logLevels = map[string]int{
+	"trace": 0,
+	"debug": 1,
+	"info":  2,
+	"warn":  3,
+	"error": 4,
+	"fatal": 5,
+	"panic": 6,
+}
+
+// logrus等日志库常用的级别缩写
+var logLevelAliases = map[string]string{
+	"trac":    "trace",
+	"debu":    "debug",
+	"warning": "warn",
+	"erro":    "error",
+	"fata":    "fatal",
+	"pani":    "panic",
+}
+
+var logLevelPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\blevel=(\w+)`),              // logrus text
+	regexp.MustCompile(`"level"\s*:\s*"(\w+)"`),      // json
+	regexp.MustCompile(`^\[?([A-Z]{4,7})\]?[\[\s:]`), // INFO[0000] msg、[ERROR] msg
+}
+
+// ValidLogLevel 是否支持的日志级别
+func ValidLogLevel(level string) bool {
+	_, ok := logLevels[normalizeLevel(level)]
+	return ok
+}
+
+func normalizeLevel(level string) string {
+	level = strings.ToLower(level)
+	if alias, ok := logLevelAliases[level]; ok {
+		return alias
+	}
+	return level
+}
+
+// parseLogLevel 识别日志的级别，无法识别时返回空
+func parseLogLevel(msg string) string {
+	for _, p := range logLevelPatterns {
+		if m := p.FindStringSubmatch(msg); len(m) == 2 {
+			if level := normalizeLevel(m[1]); ValidLogLevel(level) {
+				return level
+			}
+		}
+	}
+	return ""
+}
+
+// parseLogLine 解析带时间前缀的日志，如 2021-01-01T00:00:00.000000000Z msg
+func parseLogLine(line, stream string) LogLine {
+	line = strings.TrimRight(line, "\r\n")
+	l := LogLine{Stream: stream, Message: line}
+	if i := strings.IndexByte(line, ' '); i > 0 {
+		if t, err := time.Parse(time.RFC3339Nano, line[:i]); err == nil {
+			l.Time = t
+			l.Message = line[i+1:]
+		}
+	}
+	l.Level = parseLogLevel(l.Message)
+	return l
+}
+
+// scanLogLines 逐行读取日志发送到ch，ctx结束时返回false
+func scanLogLines(ctx context.Context, r io.Reader, stream string, ch chan<- LogLine) bool {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	for scanner.Scan() {
+		select {
+		case ch <- parseLogLine(scanner.Text(), stream):
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// Logs 获取插件的标准输出及错误输出，Follow为true时持续返回新的日志直到ctx结束
+func Logs(ctx context.Context, plg Plugin, opts LogOptions) (<-chan LogLine, error) {
+	lines, err := GetRuntime().Logs(ctx, plg.Image, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Level == "" {
+		return lines, nil
+	}
+	min := logLevels[normalizeLevel(opts.Level)]
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		for l := range lines {
+			if l.Level != "" && logLevels[l.Level] < min {
+				continue
+			}
+			select {
+			case ch <- l:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// LastLogs 获取插件最后 maxSize 字节的日志
+func LastLogs(ctx context.Context, plg Plugin, maxSize int) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lines, err := Logs(ctx, plg, LogOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		buf  []string
+		size int
+	)
+	for l := range lines {
+		s := l.String()
+		buf = append(buf, s)
+		size += len(s)
+		for size > maxSize && len(buf) > 0 {
+			size -= len(buf[0])
+			buf = buf[1:]
+		}
+	}
+	return []byte(strings.Join(buf, "")), ctx.Err()
+}
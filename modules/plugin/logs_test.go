@@ -0,0 +1,48 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitLogTail(t *testing.T) {
+	assert.Equal(t, DefaultLogTail, LimitLogTail(0))
+	assert.Equal(t, DefaultLogTail, LimitLogTail(-1))
+	assert.Equal(t, 100, LimitLogTail(100))
+	assert.Equal(t, MaxLogTail, LimitLogTail(MaxLogTail+1))
+}
+
+func TestParseLogLevel(t *testing.T) {
+	cases := map[string]string{
+		`time="2021-01-01T00:00:00Z" level=warning msg="reconnect"`: "warn",
+		`{"level":"error","msg":"timeout"}`:                         "error",
+		`{"level": "debug"}`:                                        "debug",
+		`INFO[0000] plugin started`:                                 "info",
+		`[ERRO] connect failed`:                                     "error",
+		`FATA: exit`:                                                "fatal",
+		`goroutine 1 [running]:`:                                    "",
+		`level=unknown msg="x"`:                                     "",
+		`INFORMATION only`:                                          "",
+	}
+	for msg, level := range cases {
+		assert.Equal(t, level, parseLogLevel(msg), msg)
+	}
+	assert.True(t, ValidLogLevel("WARNING"))
+	assert.False(t, ValidLogLevel("verbose"))
+}
+
+func TestParseLogLine(t *testing.T) {
+	l := parseLogLine("2021-01-01T08:00:00.5+08:00 level=info msg=ok\n", LogStreamStderr)
+	assert.True(t, l.Time.Equal(time.Date(2021, 1, 1, 0, 0, 0, 5e8, time.UTC)))
+	assert.Equal(t, LogStreamStderr, l.Stream)
+	assert.Equal(t, "level=info msg=ok", l.Message)
+	assert.Equal(t, "info", l.Level)
+
+	// 没有时间前缀时保留原始内容
+	l = parseLogLine("panic: oops\r\n", LogStreamStdout)
+	assert.True(t, l.Time.IsZero())
+	assert.Equal(t, "panic: oops", l.Message)
+	assert.Empty(t, l.Level)
+}
@@ -2,6 +2,7 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strings"
@@ -115,7 +116,15 @@ func (id Identify) ID() string {
 type Client interface {
 	DevicesDiscover(ctx context.Context) <-chan DiscoverResponse
 	SetAttributes(ctx context.Context, pluginID string, areaID uint64, setReq sdk.SetRequest) (result []byte, err error)
+	// GetAttributes 主动从设备读取属性的最新值
+	GetAttributes(ctx context.Context, pluginID string, getReq sdk.GetRequest) ([]sdk.SetAttribute, error)
+	// InvokeAction 执行设备的动作
+	InvokeAction(ctx context.Context, pluginID string, actionReq sdk.ActionRequest) (map[string]interface{}, error)
+	// UpdateSettings 下发插件设置
+	UpdateSettings(ctx context.Context, pluginID string, settings json.RawMessage) error
 	IsOnline(identify Identify) bool
+	// Ping 检查插件服务是否存活
+	Ping(ctx context.Context, pluginID string) error
 
 	OTA(ctx context.Context, identify Identify, firmwareURL string) error
 
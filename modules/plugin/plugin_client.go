@@ -16,7 +16,6 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 
 	"github.com/zhiting-tech/smartassistant/modules/entity"
-	"github.com/zhiting-tech/smartassistant/modules/utils/version"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
@@ -35,11 +34,7 @@ func newClient(areaID uint64, plgID string, endpoint endpoints.Endpoint) (*plugi
 		return nil, fmt.Errorf("invalid metadata of plugin %s, metadata: %+v", plgID, endpoint.Metadata)
 	}
 	sdkVersion, _ := meta["sdk_version"].(string)
-	if !version.IsValid(sdkVersion) {
-		return nil, fmt.Errorf("plugin %s's sdk version %s is invalid", plgID, sdkVersion)
-	}
 	logger.Debugf("plugin: %s, sdk version: %s", plgID, sdkVersion)
-	// TODO 根据 meta.SDKVersion 兼容不同版本的插件sdk
 
 	plgConf, err := GetPluginConfig(endpoint.Addr, plgID)
 	if err != nil {
@@ -55,16 +50,28 @@ func newClient(areaID uint64, plgID string, endpoint endpoints.Endpoint) (*plugi
 	if err != nil {
 		return nil, err
 	}
+	protoClient := proto.NewPluginClient(conn)
+	compatibility, err := handshake(context.Background(), plgID, protoClient, sdkVersion)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !compatibility.Compatible {
+		conn.Close()
+		return nil, compatibility.Err()
+	}
 	plgInfo, err := entity.GetPlugin(plgID, areaID)
 	if err != nil {
+		conn.Close()
 		return nil, err
 	}
 	return &pluginClient{
-		areaID:      areaID,
-		pluginID:    plgID,
-		PluginInfo:  plgInfo,
-		protoClient: proto.NewPluginClient(conn),
-		PluginConf:  plgConf,
+		areaID:        areaID,
+		pluginID:      plgID,
+		PluginInfo:    plgInfo,
+		protoClient:   protoClient,
+		PluginConf:    plgConf,
+		compatibility: compatibility,
 	}, nil
 }
 
@@ -75,6 +82,7 @@ type pluginClient struct {
 	protoClient   proto.PluginClient // 请求插件服务的grpc客户端
 	PluginConf    Plugin
 	endpointsMeta sdk.MetaData
+	compatibility Compatibility // 握手协商的协议版本及插件支持的功能
 
 	devices sync.Map
 }
@@ -184,6 +192,16 @@ func (pc *pluginClient) InitDevices() {
 	}
 }
 
+func (pc *pluginClient) updateSettings(ctx context.Context, settings json.RawMessage) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+	req := proto.UpdateSettingsReq{
+		Data: settings,
+	}
+	_, err = pc.protoClient.UpdateSettings(ctx, &req)
+	return
+}
+
 func (pc *pluginClient) GetDeviceName(model string) string {
 	for _, d := range pc.PluginConf.SupportDevices {
 		if d.Model == model {
@@ -0,0 +1,327 @@
+// Package registry SA内置的插件注册中心，代替etcd提供插件服务的注册及发现
+// 插件通过租约注册服务并定时续约，租约过期或撤销后服务自动删除，监听者收到与etcd endpoints相同的更新
+// 插件请求时需携带与存储服务相同的凭证，只能注册及删除自己的服务
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/naming/endpoints"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/zhiting-tech/smartassistant/modules/plugin/storage"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	sdkregistry "github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/utils/registry"
+)
+
+const (
+	minTTL         = 5  // 秒
+	maxTTL         = 60 // 秒
+	expireInterval = time.Second
+	watchBuffer    = 64
+)
+
+// Authenticator 校验插件请求携带的凭证，返回请求的插件
+type Authenticator func(ctx context.Context) (pluginID string, err error)
+
+type lease struct {
+	pluginID string
+	ttl      time.Duration
+	expireAt time.Time
+	keys     map[string]struct{}
+}
+
+type endpoint struct {
+	endpoints.Endpoint
+	leaseID int64
+}
+
+// watcher 监听者，更新先放入队列再由单独的协程发送，监听者处理慢时不阻塞注册中心也不丢弃更新
+type watcher struct {
+	prefix  string
+	mu      sync.Mutex
+	pending [][]*endpoints.Update
+	signal  chan struct{}
+}
+
+func (w *watcher) push(updates []*endpoints.Update) {
+	w.mu.Lock()
+	w.pending = append(w.pending, updates)
+	w.mu.Unlock()
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) take() (pending [][]*endpoints.Update) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pending, w.pending = w.pending, nil
+	return
+}
+
+// Registry 内置注册中心
+type Registry struct {
+	auth      Authenticator
+	mu        sync.Mutex
+	nextID    int64
+	leases    map[int64]*lease
+	endpoints map[string]endpoint
+	watchers  map[*watcher]struct{}
+}
+
+var (
+	defaultRegistry *Registry
+	defaultOnce     sync.Once
+)
+
+// Default SA使用的注册中心
+func Default() *Registry {
+	defaultOnce.Do(func() {
+		defaultRegistry = New(storage.AuthPlugin)
+	})
+	return defaultRegistry
+}
+
+func New(auth Authenticator) *Registry {
+	return &Registry{
+		auth:      auth,
+		leases:    make(map[int64]*lease),
+		endpoints: make(map[string]endpoint),
+		watchers:  make(map[*watcher]struct{}),
+	}
+}
+
+// RegisterServer 注册到grpc服务
+func (r *Registry) RegisterServer(s *grpc.Server) {
+	proto.RegisterRegistryServer(s, r)
+}
+
+// authLease 校验凭证，租约需属于请求的插件，调用时需持有锁
+func (r *Registry) authLease(pluginID string, id int64) (*lease, error) {
+	l, ok := r.leases[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "lease %d not found", id)
+	}
+	if l.pluginID != pluginID {
+		return nil, status.Errorf(codes.PermissionDenied, "lease %d not granted to %s", id, pluginID)
+	}
+	return l, nil
+}
+
+// authKey 校验凭证，插件只能操作自己的服务
+func (r *Registry) authKey(ctx context.Context, key string) (pluginID string, err error) {
+	if pluginID, err = r.auth(ctx); err != nil {
+		return
+	}
+	if key != sdkregistry.EndpointsKey(pluginID) {
+		err = status.Errorf(codes.PermissionDenied, "%s can't register %s", pluginID, key)
+	}
+	return
+}
+
+func (r *Registry) Grant(ctx context.Context, req *proto.LeaseGrantReq) (*proto.Lease, error) {
+	pluginID, err := r.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ttl := req.Ttl
+	if ttl < minTTL {
+		ttl = minTTL
+	} else if ttl > maxTTL {
+		ttl = maxTTL
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	r.leases[r.nextID] = &lease{
+		pluginID: pluginID,
+		ttl:      time.Duration(ttl) * time.Second,
+		expireAt: time.Now().Add(time.Duration(ttl) * time.Second),
+		keys:     make(map[string]struct{}),
+	}
+	return &proto.Lease{Id: r.nextID, Ttl: ttl}, nil
+}
+
+func (r *Registry) KeepAlive(ctx context.Context, req *proto.Lease) (*proto.Lease, error) {
+	pluginID, err := r.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, err := r.authLease(pluginID, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	l.expireAt = time.Now().Add(l.ttl)
+	return &proto.Lease{Id: req.Id, Ttl: int64(l.ttl / time.Second)}, nil
+}
+
+func (r *Registry) Revoke(ctx context.Context, req *proto.Lease) (*emptypb.Empty, error) {
+	pluginID, err := r.auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err = r.authLease(pluginID, req.Id); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return &emptypb.Empty{}, nil
+		}
+		return nil, err
+	}
+	r.revoke(req.Id)
+	return &emptypb.Empty{}, nil
+}
+
+func (r *Registry) AddEndpoint(ctx context.Context, req *proto.RegistryEndpoint) (*emptypb.Empty, error) {
+	if req.Key == "" || req.Addr == "" {
+		return nil, status.Error(codes.InvalidArgument, "key and addr required")
+	}
+	pluginID, err := r.authKey(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+	ep := endpoint{Endpoint: endpoints.Endpoint{Addr: req.Addr}, leaseID: req.LeaseId}
+	// 与etcd一致，元数据解析为map等通用类型
+	if len(req.Metadata) != 0 {
+		if err := json.Unmarshal(req.Metadata, &ep.Metadata); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid metadata: %s", err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if req.LeaseId != 0 {
+		l, err := r.authLease(pluginID, req.LeaseId)
+		if err != nil {
+			return nil, err
+		}
+		l.keys[req.Key] = struct{}{}
+	}
+	if old, ok := r.endpoints[req.Key]; ok && old.leaseID != req.LeaseId {
+		if l, ok := r.leases[old.leaseID]; ok {
+			delete(l.keys, req.Key)
+		}
+	}
+	r.endpoints[req.Key] = ep
+	r.notify(&endpoints.Update{Op: endpoints.Add, Key: req.Key, Endpoint: ep.Endpoint})
+	return &emptypb.Empty{}, nil
+}
+
+func (r *Registry) DeleteEndpoint(ctx context.Context, req *proto.RegistryKey) (*emptypb.Empty, error) {
+	if _, err := r.authKey(ctx, req.Key); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delete(req.Key)
+	return &emptypb.Empty{}, nil
+}
+
+// Watch 监听前缀下服务的变化，首次返回已注册的所有服务，ctx结束后关闭
+func (r *Registry) Watch(ctx context.Context, prefix string) endpoints.WatchChannel {
+	ch := make(chan []*endpoints.Update, watchBuffer)
+	w := &watcher{prefix: prefix, signal: make(chan struct{}, 1)}
+
+	r.mu.Lock()
+	var initial []*endpoints.Update
+	for key, ep := range r.endpoints {
+		if strings.HasPrefix(key, prefix) {
+			initial = append(initial, &endpoints.Update{Op: endpoints.Add, Key: key, Endpoint: ep.Endpoint})
+		}
+	}
+	w.push(initial)
+	r.watchers[w] = struct{}{}
+	r.mu.Unlock()
+
+	go func() {
+		defer func() {
+			r.mu.Lock()
+			delete(r.watchers, w)
+			r.mu.Unlock()
+			close(ch)
+		}()
+		for {
+			for _, updates := range w.take() {
+				select {
+				case ch <- updates:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-w.signal:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// Run 定时删除过期的租约及其服务
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(expireInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.expire(now)
+		}
+	}
+}
+
+func (r *Registry) expire(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, l := range r.leases {
+		if now.After(l.expireAt) {
+			logger.Debugf("registry lease %d expired", id)
+			r.revoke(id)
+		}
+	}
+}
+
+func (r *Registry) revoke(id int64) {
+	l, ok := r.leases[id]
+	if !ok {
+		return
+	}
+	delete(r.leases, id)
+	for key := range l.keys {
+		r.delete(key)
+	}
+}
+
+func (r *Registry) delete(key string) {
+	ep, ok := r.endpoints[key]
+	if !ok {
+		return
+	}
+	delete(r.endpoints, key)
+	if l, ok := r.leases[ep.leaseID]; ok {
+		delete(l.keys, key)
+	}
+	r.notify(&endpoints.Update{Op: endpoints.Delete, Key: key})
+}
+
+func (r *Registry) notify(update *endpoints.Update) {
+	for w := range r.watchers {
+		if strings.HasPrefix(update.Key, w.prefix) {
+			w.push([]*endpoints.Update{update})
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/client/v3/naming/endpoints"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+)
+
+const prefix = "/sa/plugins/"
+
+// testAuth 凭证为 <插件>-token 时通过校验
+func testAuth(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids, tokens := md.Get("plugin-id"), md.Get("storage-token"); len(ids) != 0 && len(tokens) != 0 &&
+		tokens[0] == ids[0]+"-token" {
+		return ids[0], nil
+	}
+	return "", status.Error(codes.Unauthenticated, "invalid token")
+}
+
+func pluginCtx(ctx context.Context, pluginID string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("plugin-id", pluginID, "storage-token", pluginID+"-token"))
+}
+
+func receive(t *testing.T, w endpoints.WatchChannel) []*endpoints.Update {
+	select {
+	case updates := <-w:
+		return updates
+	case <-time.After(time.Second):
+		t.Fatal("no update received")
+	}
+	return nil
+}
+
+func TestRegistryWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := New(testAuth)
+	demo := pluginCtx(ctx, "demo")
+
+	lease, err := r.Grant(demo, &proto.LeaseGrantReq{Ttl: 10})
+	require.NoError(t, err)
+	_, err = r.AddEndpoint(demo, &proto.RegistryEndpoint{
+		Key:      prefix + "demo",
+		Addr:     "127.0.0.1:10000",
+		Metadata: []byte(`{"sdk_version":"2.0.0"}`),
+		LeaseId:  lease.Id,
+	})
+	require.NoError(t, err)
+
+	// 首次返回已注册的服务
+	w := r.Watch(ctx, prefix)
+	updates := receive(t, w)
+	require.Len(t, updates, 1)
+	assert.Equal(t, endpoints.Add, updates[0].Op)
+	assert.Equal(t, "127.0.0.1:10000", updates[0].Endpoint.Addr)
+	meta, ok := updates[0].Endpoint.Metadata.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "2.0.0", meta["sdk_version"])
+
+	// 插件只能注册自己的服务
+	_, err = r.AddEndpoint(demo, &proto.RegistryEndpoint{Key: "/other/demo", Addr: "127.0.0.1:10001"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = r.DeleteEndpoint(demo, &proto.RegistryKey{Key: prefix + "demo"})
+	require.NoError(t, err)
+	updates = receive(t, w)
+	require.Len(t, updates, 1)
+	assert.Equal(t, endpoints.Delete, updates[0].Op)
+	assert.Equal(t, prefix+"demo", updates[0].Key)
+
+	cancel()
+	_, ok = <-w
+	assert.False(t, ok)
+}
+
+func TestRegistryLease(t *testing.T) {
+	ctx := pluginCtx(context.Background(), "demo")
+	r := New(testAuth)
+
+	lease, err := r.Grant(ctx, &proto.LeaseGrantReq{Ttl: 1})
+	require.NoError(t, err)
+	assert.EqualValues(t, minTTL, lease.Ttl)
+	_, err = r.AddEndpoint(ctx, &proto.RegistryEndpoint{Key: prefix + "demo", Addr: "127.0.0.1:10000", LeaseId: lease.Id})
+	require.NoError(t, err)
+	w := r.Watch(ctx, prefix)
+	receive(t, w)
+
+	// 续约后未过期
+	_, err = r.KeepAlive(ctx, lease)
+	require.NoError(t, err)
+	r.expire(time.Now())
+	assert.Len(t, r.endpoints, 1)
+
+	// 过期后删除租约下的服务
+	r.expire(time.Now().Add(time.Duration(minTTL+1) * time.Second))
+	updates := receive(t, w)
+	require.Len(t, updates, 1)
+	assert.Equal(t, endpoints.Delete, updates[0].Op)
+	_, err = r.KeepAlive(ctx, lease)
+	assert.Error(t, err)
+
+	_, err = r.AddEndpoint(ctx, &proto.RegistryEndpoint{Key: prefix + "demo", Addr: "127.0.0.1:10000", LeaseId: lease.Id})
+	assert.Error(t, err)
+}
+
+func TestRegistryAuth(t *testing.T) {
+	ctx := context.Background()
+	r := New(testAuth)
+	demo, other := pluginCtx(ctx, "demo"), pluginCtx(ctx, "other")
+
+	// 没有凭证或凭证错误
+	_, err := r.Grant(ctx, &proto.LeaseGrantReq{Ttl: 10})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	bad := metadata.NewIncomingContext(ctx, metadata.Pairs("plugin-id", "demo", "storage-token", "other-token"))
+	_, err = r.AddEndpoint(bad, &proto.RegistryEndpoint{Key: prefix + "demo", Addr: "127.0.0.1:10000"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = r.DeleteEndpoint(ctx, &proto.RegistryKey{Key: prefix + "demo"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	lease, err := r.Grant(demo, &proto.LeaseGrantReq{Ttl: 10})
+	require.NoError(t, err)
+	_, err = r.AddEndpoint(demo, &proto.RegistryEndpoint{Key: prefix + "demo", Addr: "127.0.0.1:10000", LeaseId: lease.Id})
+	require.NoError(t, err)
+
+	// 其他插件不能覆盖、删除服务或使用租约
+	_, err = r.AddEndpoint(other, &proto.RegistryEndpoint{Key: prefix + "demo", Addr: "127.0.0.1:10001"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = r.DeleteEndpoint(other, &proto.RegistryKey{Key: prefix + "demo"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = r.AddEndpoint(other, &proto.RegistryEndpoint{Key: prefix + "other", Addr: "127.0.0.1:10001", LeaseId: lease.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = r.KeepAlive(other, lease)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = r.Revoke(other, lease)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "127.0.0.1:10000", r.endpoints[prefix+"demo"].Addr)
+
+	_, err = r.Revoke(demo, lease)
+	require.NoError(t, err)
+	assert.Empty(t, r.endpoints)
+}
+
+func TestRegistryWatchSlow(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := New(testAuth)
+	demo := pluginCtx(ctx, "demo")
+	w := r.Watch(ctx, prefix)
+
+	// 监听者未及时读取时不丢弃更新，也不阻塞注册
+	n := watchBuffer * 3
+	for i := 0; i < n; i++ {
+		_, err := r.AddEndpoint(demo, &proto.RegistryEndpoint{Key: prefix + "demo", Addr: "127.0.0.1:10000"})
+		require.NoError(t, err)
+	}
+	_, err := r.DeleteEndpoint(demo, &proto.RegistryKey{Key: prefix + "demo"})
+	require.NoError(t, err)
+
+	assert.Empty(t, receive(t, w))
+	for i := 0; i < n; i++ {
+		updates := receive(t, w)
+		require.Len(t, updates, 1)
+		assert.Equal(t, endpoints.Add, updates[0].Op)
+	}
+	updates := receive(t, w)
+	require.Len(t, updates, 1)
+	assert.Equal(t, endpoints.Delete, updates[0].Op)
+}
@@ -0,0 +1,176 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+const (
+	cpuPeriod = 100000 // 与 CPUQuota 绑定，标识只允许使用 CPUQuota / cpuPeriod 个cpu资源
+	cpuShares = 1024   // 一个CPU的权重，SA为默认1024
+
+	mb = 1024 * 1024
+
+	minMemoryLimit = 16 // 内存限制的最小值，单位MB
+)
+
+// DefaultResources 插件未声明资源限制时使用的默认值
+// CPU资源紧张时插件权重为SA的十分之一，保证不与SA服务抢资源
+var DefaultResources = Resources{
+	CPURequest:  0.1,
+	CPULimit:    0.5,
+	MemoryLimit: 512,
+	PidsLimit:   512,
+	LogMaxSize:  10,
+	LogMaxFile:  3,
+}
+
+// Resources 插件容器的资源限制，值为0时表示未设置
+type Resources struct {
+	CPURequest    float64 `yaml:"cpu_request" json:"cpu_request,omitempty"`       // CPU资源紧张时按比例保证的CPU核数
+	CPULimit      float64 `yaml:"cpu_limit" json:"cpu_limit,omitempty"`           // 最多可使用的CPU核数
+	MemoryRequest int64   `yaml:"memory_request" json:"memory_request,omitempty"` // 内存紧张时保证的内存，单位MB
+	MemoryLimit   int64   `yaml:"memory_limit" json:"memory_limit,omitempty"`     // 内存上限，单位MB，超过会被OOM kill
+	PidsLimit     int64   `yaml:"pids_limit" json:"pids_limit,omitempty"`         // 最大进程（线程）数
+	LogMaxSize    int64   `yaml:"log_max_size" json:"log_max_size,omitempty"`     // 单个日志文件大小，单位MB
+	LogMaxFile    int     `yaml:"log_max_file" json:"log_max_file,omitempty"`     // 保留的日志文件个数
+}
+
+// IsZero 是否没有设置任何资源限制
+func (r Resources) IsZero() bool {
+	return r == Resources{}
+}
+
+// Merge 用 o 中设置了的值覆盖 r
+func (r Resources) Merge(o Resources) Resources {
+	if o.CPURequest != 0 {
+		r.CPURequest = o.CPURequest
+	}
+	if o.CPULimit != 0 {
+		r.CPULimit = o.CPULimit
+	}
+	if o.MemoryRequest != 0 {
+		r.MemoryRequest = o.MemoryRequest
+	}
+	if o.MemoryLimit != 0 {
+		r.MemoryLimit = o.MemoryLimit
+	}
+	if o.PidsLimit != 0 {
+		r.PidsLimit = o.PidsLimit
+	}
+	if o.LogMaxSize != 0 {
+		r.LogMaxSize = o.LogMaxSize
+	}
+	if o.LogMaxFile != 0 {
+		r.LogMaxFile = o.LogMaxFile
+	}
+	return r
+}
+
+// Validate 检查资源限制是否合法，未设置的值不检查
+func (r Resources) Validate() error {
+	if r.CPURequest < 0 || r.CPULimit < 0 || r.MemoryRequest < 0 || r.MemoryLimit < 0 ||
+		r.PidsLimit < 0 || r.LogMaxSize < 0 || r.LogMaxFile < 0 {
+		return fmt.Errorf("resources can not be negative")
+	}
+	if r.CPULimit > float64(runtime.NumCPU()) {
+		return fmt.Errorf("cpu_limit exceeds %d cpus", runtime.NumCPU())
+	}
+	if r.CPURequest != 0 && r.CPULimit != 0 && r.CPURequest > r.CPULimit {
+		return fmt.Errorf("cpu_request is greater than cpu_limit")
+	}
+	if r.MemoryLimit != 0 && r.MemoryLimit < minMemoryLimit {
+		return fmt.Errorf("memory_limit is less than %dMB", minMemoryLimit)
+	}
+	if r.MemoryRequest != 0 && r.MemoryLimit != 0 && r.MemoryRequest > r.MemoryLimit {
+		return fmt.Errorf("memory_request is greater than memory_limit")
+	}
+	return nil
+}
+
+// containerResources 转换为docker的资源限制
+func (r Resources) containerResources() container.Resources {
+	res := container.Resources{
+		CPUShares:         int64(r.CPURequest * cpuShares),
+		MemoryReservation: r.MemoryRequest * mb,
+		Memory:            r.MemoryLimit * mb,
+	}
+	if r.CPULimit != 0 {
+		res.CPUPeriod = cpuPeriod
+		res.CPUQuota = int64(r.CPULimit * cpuPeriod)
+	}
+	if r.MemoryLimit != 0 {
+		// 不允许使用swap
+		res.MemorySwap = res.Memory
+	}
+	if r.PidsLimit != 0 {
+		pidsLimit := r.PidsLimit
+		res.PidsLimit = &pidsLimit
+	}
+	return res
+}
+
+// logConfig 插件容器的日志配置，限制日志占用的空间
+func (r Resources) logConfig() container.LogConfig {
+	conf := container.LogConfig{
+		Type:   "json-file",
+		Config: make(map[string]string),
+	}
+	if r.LogMaxSize != 0 {
+		conf.Config["max-size"] = fmt.Sprintf("%dm", r.LogMaxSize)
+	}
+	if r.LogMaxFile != 0 {
+		conf.Config["max-file"] = fmt.Sprintf("%d", r.LogMaxFile)
+	}
+	return conf
+}
+
+// PluginResources 插件的资源限制
+type PluginResources struct {
+	Declared  Resources `json:"declared"`  // 插件配置中声明的资源限制
+	Override  Resources `json:"override"`  // 拥有者设置的资源限制，优先于插件配置
+	Effective Resources `json:"effective"` // 实际生效的资源限制
+}
+
+// GetResources 获取插件的资源限制
+func GetResources(plg Plugin) (pr PluginResources) {
+	pr.Declared = plg.Resources
+	if pi, err := entity.GetPlugin(plg.ID, plg.AreaID); err == nil {
+		if pr.Declared.IsZero() && len(pi.Resources) != 0 {
+			_ = json.Unmarshal(pi.Resources, &pr.Declared)
+		}
+		if len(pi.ResourceOverride) != 0 {
+			_ = json.Unmarshal(pi.ResourceOverride, &pr.Override)
+		}
+	}
+	pr.Effective = DefaultResources.Merge(pr.Declared).Merge(pr.Override)
+	return
+}
+
+// UpdateResourceOverride 保存拥有者设置的资源限制，并重新创建插件容器使其生效
+func UpdateResourceOverride(plg Plugin, override Resources) (err error) {
+	data, err := json.Marshal(override)
+	if err != nil {
+		return
+	}
+	if err = entity.UpdatePluginResourceOverride(plg.ID, plg.AreaID, data); err != nil {
+		return
+	}
+
+	// 资源限制在创建容器时生效，插件未运行时在下次启动时生效
+	if !GetRuntime().IsRunning(plg.Image) {
+		return
+	}
+	logger.Infof("recreate plugin %s container to apply resources", plg.ID)
+	if err = stopAndRemoveContainer(plg.Image); err != nil {
+		return
+	}
+	_, err = RunPlugin(plg)
+	return
+}
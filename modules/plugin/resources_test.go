@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultResources(t *testing.T) {
+	// 未声明资源限制的插件使用默认值，默认值本身必须合法
+	assert.NoError(t, DefaultResources.Validate())
+	assert.NoError(t, DefaultResources.Merge(Resources{}).Validate())
+	assert.Equal(t, DefaultResources, DefaultResources.Merge(Resources{}))
+
+	res := DefaultResources.containerResources()
+	assert.Equal(t, int64(DefaultResources.CPULimit*cpuPeriod), res.CPUQuota)
+	assert.Less(t, res.CPUShares, int64(cpuShares))
+}
+
+func TestResourcesMerge(t *testing.T) {
+	declared := Resources{CPULimit: 1, MemoryLimit: 256}
+	override := Resources{MemoryLimit: 128}
+	effective := DefaultResources.Merge(declared).Merge(override)
+	assert.Equal(t, DefaultResources.CPURequest, effective.CPURequest)
+	assert.Equal(t, float64(1), effective.CPULimit)
+	assert.Equal(t, int64(128), effective.MemoryLimit)
+	assert.NoError(t, effective.Validate())
+}
+
+func TestResourcesValidate(t *testing.T) {
+	assert.NoError(t, Resources{}.Validate())
+	assert.Error(t, Resources{CPURequest: -1}.Validate())
+	assert.Error(t, Resources{CPURequest: 0.5, CPULimit: 0.1}.Validate())
+	assert.Error(t, Resources{MemoryLimit: minMemoryLimit - 1}.Validate())
+	assert.Error(t, Resources{MemoryRequest: 256, MemoryLimit: 128}.Validate())
+	// 低于默认请求值的上限需要同时设置请求值
+	assert.Error(t, DefaultResources.Merge(Resources{CPULimit: 0.05}).Validate())
+	assert.NoError(t, DefaultResources.Merge(Resources{CPURequest: 0.05, CPULimit: 0.05}).Validate())
+}
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+// 插件运行时
+const (
+	RuntimeDocker  = "docker"  // 以容器运行插件镜像
+	RuntimeProcess = "process" // 以子进程运行插件目录中的可执行文件，无需docker
+)
+
+// Instance 插件运行实例（容器或进程）的状态
+type Instance struct {
+	Running      bool
+	Restarting   bool // 崩溃后等待重启
+	ExitCode     int
+	OOMKilled    bool
+	Error        string
+	RestartCount int // 崩溃重启的次数
+	StartedAt    time.Time
+}
+
+// RunOptions 运行插件的参数
+type RunOptions struct {
+	Env         []string
+	Labels      map[string]string
+	Resources   Resources
+	DataDir     string // 插件数据目录
+	HostDataDir string // 插件数据目录在宿主机上的路径
+}
+
+// Runtime 插件运行时，负责插件镜像（或文件）的获取、构建，以及插件实例的运行和停止
+// 插件及其实例都通过镜像名来区分
+type Runtime interface {
+	Name() string
+	// Pull 获取插件镜像
+	Pull(image string) error
+	// Build 从插件源码目录构建插件镜像
+	Build(path, image string) error
+	// Tag 为插件镜像添加新的名称，用于保留回滚的版本
+	Tag(source, target string) error
+	// RemoveImage 删除插件镜像
+	RemoveImage(image string) error
+
+	// Run 创建并运行插件实例
+	Run(plg Plugin, opts RunOptions) (id string, err error)
+	// Start 启动已停止的插件实例
+	Start(image string) error
+	Stop(image string) error
+	Restart(image string) error
+	// Remove 删除已停止的插件实例
+	Remove(image string) error
+	IsRunning(image string) bool
+	Inspect(image string) (Instance, error)
+	// Logs 读取插件实例的标准输出及错误输出
+	Logs(ctx context.Context, image string, opts LogOptions) (<-chan LogLine, error)
+	// OOMEvents 插件超过内存限制被kill的事件，返回插件名称，运行时不支持时返回nil
+	OOMEvents(ctx context.Context) <-chan string
+}
+
+var (
+	pluginRuntime Runtime
+	runtimeOnce   sync.Once
+)
+
+// GetRuntime 获取配置的插件运行时，默认为docker
+func GetRuntime() Runtime {
+	runtimeOnce.Do(func() {
+		switch name := config.GetConf().SmartAssistant.PluginRuntime; name {
+		case RuntimeProcess:
+			pluginRuntime = newProcessRuntime()
+		case RuntimeDocker, "":
+			pluginRuntime = newDockerRuntime()
+		default:
+			logger.Warnf("unknown plugin runtime %s, use docker", name)
+			pluginRuntime = newDockerRuntime()
+		}
+		logger.Infof("plugin runtime: %s", pluginRuntime.Name())
+	})
+	return pluginRuntime
+}
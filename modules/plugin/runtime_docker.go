@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/pkg/stdcopy"
+
+	"github.com/zhiting-tech/smartassistant/modules/plugin/docker"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+const (
+	networkModeHost = "host"
+
+	// 插件容器的标签，用于资源统计等
+	labelServiceType = "com.zhiting.smartassistant.resource.service_type"
+	labelServiceName = "com.zhiting.smartassistant.resource.service_name"
+
+	containerDataDir = "/app/data/"
+)
+
+var (
+	alwaysRestart = container.RestartPolicy{
+		Name: "always",
+	}
+)
+
+// dockerRuntime 以容器运行插件，容器使用host网络，崩溃后由docker自动重启
+type dockerRuntime struct {
+	cli *docker.Client
+}
+
+func newDockerRuntime() *dockerRuntime {
+	return &dockerRuntime{cli: docker.GetClient()}
+}
+
+func (r *dockerRuntime) Name() string {
+	return RuntimeDocker
+}
+
+func (r *dockerRuntime) Pull(image string) error {
+	return r.cli.Pull(image)
+}
+
+func (r *dockerRuntime) Build(path, image string) (err error) {
+	_, err = r.cli.BuildFromPath(path, image)
+	return
+}
+
+func (r *dockerRuntime) Tag(source, target string) error {
+	return r.cli.ImageTag(source, target)
+}
+
+func (r *dockerRuntime) RemoveImage(image string) error {
+	return r.cli.ImageRemove(image)
+}
+
+func (r *dockerRuntime) Run(plg Plugin, opts RunOptions) (id string, err error) {
+	conf := container.Config{
+		Image:  plg.Image,
+		Env:    opts.Env,
+		Labels: opts.Labels,
+	}
+	logger.Debugf("mount %s to %s", opts.DataDir, containerDataDir)
+	hostConf := container.HostConfig{
+		NetworkMode:   networkModeHost,
+		RestartPolicy: alwaysRestart,
+		Mounts: []mount.Mount{
+			{Type: mount.TypeBind, Source: opts.HostDataDir, Target: containerDataDir},
+		},
+		Resources: opts.Resources.containerResources(),
+		LogConfig: opts.Resources.logConfig(),
+	}
+	return r.cli.ContainerRun(plg.Image, conf, hostConf)
+}
+
+func (r *dockerRuntime) Start(image string) error {
+	return r.cli.ContainerStartByImage(image)
+}
+
+func (r *dockerRuntime) Stop(image string) error {
+	return r.cli.StopContainer(image)
+}
+
+func (r *dockerRuntime) Restart(image string) error {
+	return r.cli.ContainerRestartByImage(image)
+}
+
+func (r *dockerRuntime) Remove(image string) error {
+	return r.cli.RemoveContainer(image)
+}
+
+func (r *dockerRuntime) IsRunning(image string) bool {
+	isRunning, _ := r.cli.ContainerIsRunningByImage(image)
+	return isRunning
+}
+
+func (r *dockerRuntime) Inspect(image string) (ins Instance, err error) {
+	info, err := r.cli.ContainerInspectByImage(image)
+	if err != nil {
+		return
+	}
+	ins.RestartCount = info.RestartCount
+	if info.State == nil {
+		return
+	}
+	ins.Running = info.State.Running
+	ins.Restarting = info.State.Restarting
+	ins.ExitCode = info.State.ExitCode
+	ins.OOMKilled = info.State.OOMKilled
+	ins.Error = info.State.Error
+	ins.StartedAt, _ = time.Parse(time.RFC3339Nano, info.State.StartedAt)
+	return
+}
+
+// OOMEvents 监听插件容器的OOM事件，断开后重新监听
+func (r *dockerRuntime) OOMEvents(ctx context.Context) <-chan string {
+	ch := make(chan string)
+	go func() {
+		for {
+			msgs, errs := r.cli.ContainerOOMEvents(ctx, labelServiceType+"=plugin")
+		loop:
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case msg := <-msgs:
+					ch <- msg.Actor.Attributes[labelServiceName]
+				case err := <-errs:
+					logger.Errorf("watch plugin oom events err: %s", err)
+					break loop
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second * 15):
+			}
+		}
+	}()
+	return ch
+}
+
+// Logs 读取容器的日志，使用docker记录的时间
+func (r *dockerRuntime) Logs(ctx context.Context, image string, opts LogOptions) (<-chan LogLine, error) {
+	options := types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Timestamps: true,
+		Follow:     opts.Follow,
+	}
+	if !opts.Since.IsZero() {
+		options.Since = strconv.FormatInt(opts.Since.Unix(), 10)
+	}
+	if opts.Tail > 0 {
+		options.Tail = strconv.Itoa(opts.Tail)
+	}
+	rc, err := r.cli.ContainerLogsByImage(ctx, image, options)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stdoutW := io.Pipe()
+	stderr, stderrW := io.Pipe()
+	go func() {
+		defer rc.Close()
+		_, err := stdcopy.StdCopy(stdoutW, stderrW, rc)
+		stdoutW.CloseWithError(err)
+		stderrW.CloseWithError(err)
+	}()
+
+	ch := make(chan LogLine)
+	var wg sync.WaitGroup
+	scan := func(r *io.PipeReader, stream string) {
+		defer wg.Done()
+		defer r.Close()
+		scanLogLines(ctx, r, stream, ch)
+	}
+	wg.Add(2)
+	go scan(stdout, LogStreamStdout)
+	go scan(stderr, LogStreamStderr)
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	return ch, nil
+}
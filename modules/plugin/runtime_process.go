@@ -0,0 +1,658 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	errors2 "errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+const (
+	defaultEntrypoint = "plugin"
+
+	processStopTimeout   = time.Second * 10
+	processBackoffBase   = time.Second
+	processBackoffMax    = time.Minute
+	processBackoffReset  = time.Second * 10 // 运行超过这个时间后退出，重新从最短的间隔开始重启
+	processLogMaxSizeDef = 10               // MB
+	processLogMaxFileDef = 3
+
+	processLogPollInterval = time.Millisecond * 500
+)
+
+var errInstanceNotFound = errors2.New("plugin instance not found")
+
+// processEnvAllowlist 从SA继承给插件进程的环境变量，SA的其他环境变量不传递给插件
+var processEnvAllowlist = []string{
+	"PATH", "HOME", "TZ", "LANG", "LC_ALL",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY",
+}
+
+// processRuntime 以子进程运行插件，适用于无法使用docker的设备
+// 插件"镜像"为插件目录的拷贝，位于 <data>/plugin_images/ 下，运行时拷贝到 <data>/plugin_instances/ 下作为工作目录，
+// 并将插件数据目录链接为工作目录中的data，与容器中的 /app/data 一致
+// 进程退出后按指数退避自动重启，资源限制不生效
+type processRuntime struct {
+	mu        sync.Mutex
+	processes map[string]*process // key为插件镜像名
+}
+
+func newProcessRuntime() *processRuntime {
+	return &processRuntime{processes: make(map[string]*process)}
+}
+
+func (r *processRuntime) Name() string {
+	return RuntimeProcess
+}
+
+// imageDir 插件镜像对应的目录
+func (r *processRuntime) imageDir(image string) string {
+	name := strings.NewReplacer("/", "_", ":", "_").Replace(image)
+	return filepath.Join(config.GetConf().SmartAssistant.DataPath(), "plugin_images", name)
+}
+
+// instanceDir 插件实例的工作目录，构建或删除镜像不影响运行中的插件
+func (r *processRuntime) instanceDir(image string) string {
+	return filepath.Join(config.GetConf().SmartAssistant.DataPath(), "plugin_instances", filepath.Base(r.imageDir(image)))
+}
+
+func (r *processRuntime) logPath(image string) string {
+	return filepath.Join(config.GetConf().SmartAssistant.DataPath(), "plugin_logs",
+		filepath.Base(r.imageDir(image))+".log")
+}
+
+// Pull 进程运行时无法从镜像仓库获取插件，仅支持已构建的插件
+func (r *processRuntime) Pull(image string) error {
+	if _, err := os.Stat(r.imageDir(image)); err != nil {
+		return fmt.Errorf("process runtime can't pull image %s, upload the plugin package instead", image)
+	}
+	return nil
+}
+
+func (r *processRuntime) Build(path, image string) error {
+	dir := r.imageDir(image)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return copyDir(path, dir)
+}
+
+func (r *processRuntime) Tag(source, target string) error {
+	if source == target {
+		return nil
+	}
+	dir := r.imageDir(target)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return copyDir(r.imageDir(source), dir)
+}
+
+func (r *processRuntime) RemoveImage(image string) error {
+	return os.RemoveAll(r.imageDir(image))
+}
+
+func (r *processRuntime) Run(plg Plugin, opts RunOptions) (id string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.processes[plg.Image]; ok {
+		return "", fmt.Errorf("plugin %s is already in use", plg.Image)
+	}
+
+	dir, err := r.prepareInstance(plg.Image, opts.DataDir)
+	if err != nil {
+		return
+	}
+	entrypoint, err := processEntrypoint(dir)
+	if err != nil {
+		return
+	}
+	env := processEnv(append(opts.Env, "LOCAL_IP=127.0.0.1"))
+	if !config.GetConf().SmartAssistant.IsBuiltinRegistry() {
+		env = append(env, fmt.Sprintf("SA_REGISTRY_ADDR=%s", pluginRegistryAddr))
+	}
+	logWriter, err := newRotateWriter(r.logPath(plg.Image), opts.Resources.LogMaxSize, opts.Resources.LogMaxFile)
+	if err != nil {
+		return
+	}
+	p := &process{
+		path:   entrypoint,
+		dir:    dir,
+		env:    env,
+		output: logWriter,
+		stdout: &lineWriter{w: logWriter, stream: LogStreamStdout},
+		stderr: &lineWriter{w: logWriter, stream: LogStreamStderr},
+	}
+	p.start()
+	r.processes[plg.Image] = p
+	return plg.Image, nil
+}
+
+// prepareInstance 从插件镜像创建实例的工作目录，并链接插件数据目录
+func (r *processRuntime) prepareInstance(image, dataDir string) (dir string, err error) {
+	dir = r.instanceDir(image)
+	if err = os.RemoveAll(dir); err != nil {
+		return
+	}
+	if err = copyDir(r.imageDir(image), dir); err != nil {
+		return
+	}
+	link := filepath.Join(dir, "data")
+	if err = os.RemoveAll(link); err != nil {
+		return
+	}
+	if err = os.MkdirAll(dataDir, os.ModePerm); err != nil {
+		return
+	}
+	err = os.Symlink(dataDir, link)
+	return
+}
+
+// processEnv 插件进程的环境变量，只继承允许的SA环境变量
+func processEnv(extra []string) (env []string) {
+	for _, key := range processEnvAllowlist {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, fmt.Sprintf("%s=%s", key, v))
+		}
+	}
+	return append(env, extra...)
+}
+
+func (r *processRuntime) get(image string) (*process, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.processes[image]
+	return p, ok
+}
+
+func (r *processRuntime) Start(image string) error {
+	p, ok := r.get(image)
+	if !ok {
+		return errInstanceNotFound
+	}
+	p.start()
+	return nil
+}
+
+// Stop 停止插件进程，不存在时不报错
+func (r *processRuntime) Stop(image string) error {
+	if p, ok := r.get(image); ok {
+		p.stop()
+	}
+	return nil
+}
+
+func (r *processRuntime) Restart(image string) error {
+	p, ok := r.get(image)
+	if !ok {
+		return errInstanceNotFound
+	}
+	p.stop()
+	p.start()
+	return nil
+}
+
+// Remove 删除已停止的插件进程，不存在时不报错
+func (r *processRuntime) Remove(image string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.processes[image]
+	if !ok {
+		return nil
+	}
+	if p.isActive() {
+		return fmt.Errorf("plugin %s is running, stop it before removing", image)
+	}
+	p.output.Close()
+	delete(r.processes, image)
+	// 数据目录为链接，删除工作目录不影响插件数据
+	return os.RemoveAll(r.instanceDir(image))
+}
+
+func (r *processRuntime) IsRunning(image string) bool {
+	p, ok := r.get(image)
+	return ok && p.instance().Running
+}
+
+func (r *processRuntime) Inspect(image string) (Instance, error) {
+	p, ok := r.get(image)
+	if !ok {
+		return Instance{}, errInstanceNotFound
+	}
+	return p.instance(), nil
+}
+
+// Logs 读取插件的日志文件（包括已切割的文件），Follow为true时轮询文件的新内容
+func (r *processRuntime) Logs(ctx context.Context, image string, opts LogOptions) (<-chan LogLine, error) {
+	path := r.logPath(image)
+	if _, err := os.Stat(path); err != nil {
+		return nil, errInstanceNotFound
+	}
+	files := []string{path}
+	for i := 1; ; i++ {
+		rotated := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(rotated); err != nil {
+			break
+		}
+		files = append([]string{rotated}, files...)
+	}
+
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		var tail []LogLine
+		send := func(l LogLine) bool {
+			if !opts.Since.IsZero() && l.Time.Before(opts.Since) {
+				return true
+			}
+			if opts.Tail > 0 {
+				if tail = append(tail, l); len(tail) > opts.Tail*2 {
+					tail = append(tail[:0], tail[len(tail)-opts.Tail:]...)
+				}
+				return true
+			}
+			select {
+			case ch <- l:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var offset int64
+		for _, f := range files {
+			var ok bool
+			if offset, ok = readLogFile(f, 0, send); !ok {
+				return
+			}
+		}
+		if len(tail) > opts.Tail {
+			tail = tail[len(tail)-opts.Tail:]
+		}
+		for _, l := range tail {
+			select {
+			case ch <- l:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if !opts.Follow {
+			return
+		}
+
+		opts.Tail = 0
+		ticker := time.NewTicker(processLogPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			if info.Size() < offset {
+				// 文件被切割
+				offset = 0
+			}
+			if info.Size() == offset {
+				continue
+			}
+			var ok bool
+			if offset, ok = readLogFile(path, offset, send); !ok {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// readLogFile 从offset开始读取日志文件的完整行，返回读取后的位置
+func readLogFile(path string, offset int64, fn func(LogLine) bool) (int64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return offset, true
+	}
+	defer f.Close()
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return offset, true
+	}
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			// 未写完的行下次再读取
+			return offset, true
+		}
+		offset += int64(len(line))
+		// 格式为 时间 stream 内容
+		var stream string
+		if parts := strings.SplitN(line, " ", 3); len(parts) == 3 {
+			stream = parts[1]
+			line = parts[0] + " " + parts[2]
+		}
+		if !fn(parseLogLine(line, stream)) {
+			return offset, false
+		}
+	}
+}
+
+// OOMEvents 进程运行时不限制内存
+func (r *processRuntime) OOMEvents(ctx context.Context) <-chan string {
+	return nil
+}
+
+// processEntrypoint 插件目录中可执行文件的路径，由config.json的entrypoint指定
+func processEntrypoint(dir string) (path string, err error) {
+	var conf Config
+	data, err := ioutil.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &conf); err != nil {
+		return
+	}
+	entrypoint := conf.Entrypoint
+	if entrypoint == "" {
+		entrypoint = defaultEntrypoint
+	}
+	path = filepath.Join(dir, filepath.Clean("/"+entrypoint))
+	if _, err = os.Stat(path); err != nil {
+		return "", fmt.Errorf("plugin entrypoint %s not found", entrypoint)
+	}
+	return
+}
+
+// process 被监管的插件进程
+type process struct {
+	path   string
+	dir    string
+	env    []string
+	output io.WriteCloser // 日志文件
+	stdout io.Writer
+	stderr io.Writer
+
+	mu    sync.Mutex
+	state Instance
+	cmd   *exec.Cmd
+	quit  chan struct{} // 关闭时停止重启
+	done  chan struct{} // 监管结束时关闭
+}
+
+// start 启动进程并在退出后自动重启，已启动时不做处理
+func (p *process) start() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.quit != nil {
+		return
+	}
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.supervise(p.quit, p.done)
+}
+
+// stop 发送SIGTERM，超时后kill进程
+func (p *process) stop() {
+	p.mu.Lock()
+	quit, done, cmd := p.quit, p.done, p.cmd
+	p.quit = nil
+	p.mu.Unlock()
+	if quit == nil {
+		return
+	}
+	close(quit)
+	if cmd != nil {
+		_ = signalProcess(cmd.Process, syscall.SIGTERM)
+	}
+	select {
+	case <-done:
+	case <-time.After(processStopTimeout):
+		p.mu.Lock()
+		if p.cmd != nil {
+			_ = signalProcess(p.cmd.Process, syscall.SIGKILL)
+		}
+		p.mu.Unlock()
+		<-done
+	}
+}
+
+func (p *process) isActive() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.quit != nil
+}
+
+func (p *process) instance() Instance {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state
+}
+
+func (p *process) supervise(quit, done chan struct{}) {
+	defer close(done)
+	backoff := processBackoffBase
+	for {
+		cmd := exec.Command(p.path)
+		cmd.Dir = p.dir
+		cmd.Env = p.env
+		cmd.Stdout = p.stdout
+		cmd.Stderr = p.stderr
+		cmd.SysProcAttr = processSysProcAttr()
+
+		startedAt := time.Now()
+		err := cmd.Start()
+		p.mu.Lock()
+		if err == nil {
+			p.cmd = cmd
+			p.state.Running = true
+			p.state.Restarting = false
+			p.state.Error = ""
+			p.state.StartedAt = startedAt
+			// 启动期间收到停止请求
+			select {
+			case <-quit:
+				_ = signalProcess(cmd.Process, syscall.SIGTERM)
+			default:
+			}
+		} else {
+			p.state.Error = err.Error()
+			p.state.ExitCode = -1
+		}
+		p.mu.Unlock()
+
+		if err == nil {
+			err = cmd.Wait()
+			p.mu.Lock()
+			p.cmd = nil
+			p.state.Running = false
+			p.state.ExitCode = cmd.ProcessState.ExitCode()
+			p.mu.Unlock()
+		}
+
+		select {
+		case <-quit:
+			p.mu.Lock()
+			p.state.Restarting = false
+			p.mu.Unlock()
+			return
+		default:
+		}
+
+		if time.Since(startedAt) > processBackoffReset {
+			backoff = processBackoffBase
+		}
+		logger.Warnf("plugin process %s exited: %v, restart after %s", p.path, err, backoff)
+		p.mu.Lock()
+		p.state.Restarting = true
+		p.state.RestartCount++
+		p.mu.Unlock()
+
+		select {
+		case <-quit:
+			p.mu.Lock()
+			p.state.Restarting = false
+			p.mu.Unlock()
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > processBackoffMax {
+			backoff = processBackoffMax
+		}
+	}
+}
+
+// lineWriter 为每行输出加上时间及输出流，与docker的日志格式一致
+type lineWriter struct {
+	w      io.Writer
+	stream string
+	buf    []byte
+}
+
+func (w *lineWriter) Write(b []byte) (n int, err error) {
+	w.buf = append(w.buf, b...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			if len(w.buf) < maxLogLineSize {
+				break
+			}
+			// 过长的行直接截断输出
+			w.buf = append(w.buf, '\n')
+			i = len(w.buf) - 1
+		}
+		line := fmt.Sprintf("%s %s %s", time.Now().Format(time.RFC3339Nano), w.stream, w.buf[:i+1])
+		if _, err = w.w.Write([]byte(line)); err != nil {
+			return
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(b), nil
+}
+
+// rotateWriter 按大小切割的日志文件，保留 maxFile 个文件
+type rotateWriter struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+	maxFile int
+	file    *os.File
+	size    int64
+}
+
+func newRotateWriter(path string, maxSizeMB int64, maxFile int) (w *rotateWriter, err error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = processLogMaxSizeDef
+	}
+	if maxFile <= 0 {
+		maxFile = processLogMaxFileDef
+	}
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return
+	}
+	w = &rotateWriter{path: path, maxSize: maxSizeMB << 20, maxFile: maxFile}
+	if err = w.open(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (w *rotateWriter) open() (err error) {
+	w.file, err = os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return
+	}
+	w.size = info.Size()
+	return
+}
+
+func (w *rotateWriter) rotate() (err error) {
+	if err = w.file.Close(); err != nil {
+		return
+	}
+	for i := w.maxFile - 1; i > 0; i-- {
+		src := w.path
+		if i > 1 {
+			src = fmt.Sprintf("%s.%d", w.path, i-1)
+		}
+		_ = os.Rename(src, fmt.Sprintf("%s.%d", w.path, i))
+	}
+	if w.maxFile == 1 {
+		_ = os.Remove(w.path)
+	}
+	return w.open()
+}
+
+func (w *rotateWriter) Write(b []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.size+int64(len(b)) > w.maxSize && w.size > 0 {
+		if err = w.rotate(); err != nil {
+			return
+		}
+	}
+	n, err = w.file.Write(b)
+	w.size += int64(n)
+	return
+}
+
+func (w *rotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode()|0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode())
+	})
+}
+
+func copyFile(src, dst string, mode os.FileMode) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return
+}
@@ -0,0 +1,16 @@
+package plugin
+
+import (
+	"os"
+	"syscall"
+)
+
+// processSysProcAttr 插件进程使用独立的进程组，SA退出时插件进程也会被kill
+func processSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+}
+
+// signalProcess 向插件进程组发送信号，包括插件启动的子进程
+func signalProcess(p *os.Process, sig syscall.Signal) error {
+	return syscall.Kill(-p.Pid, sig)
+}
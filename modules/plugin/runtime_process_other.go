@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package plugin
+
+import (
+	"os"
+	"syscall"
+)
+
+// processSysProcAttr 非linux系统不支持父进程退出时kill插件进程
+func processSysProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+func signalProcess(p *os.Process, sig syscall.Signal) error {
+	return p.Signal(sig)
+}
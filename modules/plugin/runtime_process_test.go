@@ -0,0 +1,196 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProcessScript = `#!/bin/sh
+echo "secret=$PROC_TEST_SECRET domain=$PLUGIN_DOMAIN"
+echo saved > data/state
+sleep 60 &
+wait
+`
+
+// buildTestProcess 构建以脚本为入口的插件镜像
+func buildTestProcess(t *testing.T, r *processRuntime, image string) {
+	src, err := ioutil.TempDir("", "process-plugin")
+	require.NoError(t, err)
+	defer os.RemoveAll(src)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "config.json"), []byte(`{"entrypoint": "run.sh"}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(src, "run.sh"), []byte(testProcessScript), 0755))
+	require.NoError(t, r.Build(src, image))
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(time.Second * 5)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond * 20)
+	}
+}
+
+func TestProcessEnv(t *testing.T) {
+	os.Setenv("PROC_TEST_SECRET", "secret")
+	defer os.Unsetenv("PROC_TEST_SECRET")
+
+	env := processEnv([]string{"PLUGIN_DOMAIN=demo"})
+	assert.Contains(t, env, "PATH="+os.Getenv("PATH"))
+	assert.Contains(t, env, "PLUGIN_DOMAIN=demo")
+	for _, e := range env {
+		assert.False(t, strings.HasPrefix(e, "PROC_TEST_SECRET="), "SA的环境变量不应传递给插件")
+	}
+}
+
+func TestProcessRuntime(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("/bin/sh not found")
+	}
+	os.Setenv("PROC_TEST_SECRET", "secret")
+	defer os.Unsetenv("PROC_TEST_SECRET")
+
+	r := newProcessRuntime()
+	image := "zhiting/process-test:1.0"
+	buildTestProcess(t, r, image)
+	dataDir, err := ioutil.TempDir("", "process-data")
+	require.NoError(t, err)
+	defer os.RemoveAll(dataDir)
+
+	plg := Plugin{ID: "process-test", Image: image}
+	_, err = r.Run(plg, RunOptions{Env: []string{"PLUGIN_DOMAIN=process-test"}, DataDir: dataDir})
+	require.NoError(t, err)
+	defer func() {
+		_ = r.Stop(image)
+		_ = r.Remove(image)
+	}()
+
+	// 工作目录中的data为插件数据目录
+	state := filepath.Join(dataDir, "state")
+	waitFor(t, func() bool {
+		_, err := os.Stat(state)
+		return err == nil
+	})
+	assert.True(t, r.IsRunning(image))
+
+	// 插件进程使用独立的进程组
+	p, _ := r.get(image)
+	p.mu.Lock()
+	pid := p.cmd.Process.Pid
+	p.mu.Unlock()
+	pgid, err := syscall.Getpgid(pid)
+	require.NoError(t, err)
+	assert.Equal(t, pid, pgid)
+
+	// 删除或重新构建镜像不影响运行中的插件
+	require.NoError(t, r.RemoveImage(image))
+	_, err = os.Stat(filepath.Join(r.instanceDir(image), "run.sh"))
+	assert.NoError(t, err)
+
+	waitFor(t, func() bool {
+		data, _ := ioutil.ReadFile(r.logPath(image))
+		return strings.Contains(string(data), "domain=process-test")
+	})
+	data, err := ioutil.ReadFile(r.logPath(image))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), " stdout secret= domain=process-test")
+
+	require.NoError(t, r.Stop(image))
+	ins, err := r.Inspect(image)
+	require.NoError(t, err)
+	assert.False(t, ins.Running)
+	assert.Equal(t, 0, ins.RestartCount)
+
+	// 删除实例时保留插件数据
+	require.NoError(t, r.Remove(image))
+	_, err = os.Stat(r.instanceDir(image))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(state)
+	assert.NoError(t, err)
+	_, err = r.Inspect(image)
+	assert.Equal(t, errInstanceNotFound, err)
+}
+
+func TestLineWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &lineWriter{w: &buf, stream: LogStreamStderr}
+
+	// 不完整的行等待换行后再写入
+	n, err := w.Write([]byte("first\nsec"))
+	require.NoError(t, err)
+	assert.Equal(t, 9, n)
+	_, _ = w.Write([]byte("ond\n"))
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for i, msg := range []string{"first", "second"} {
+		l := parseLogLine(strings.Replace(lines[i], " stderr ", " ", 1), LogStreamStderr)
+		assert.False(t, l.Time.IsZero())
+		assert.Equal(t, msg, l.Message)
+		assert.Contains(t, lines[i], " stderr "+msg)
+	}
+
+	// 过长的行不等待换行直接输出
+	buf.Reset()
+	_, _ = w.Write(bytes.Repeat([]byte("x"), maxLogLineSize))
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+	assert.Empty(t, w.buf)
+}
+
+func TestRotateWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rotate")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plugin.log")
+
+	w, err := newRotateWriter(path, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<20), w.maxSize)
+	line := append(bytes.Repeat([]byte("x"), 1<<19-1), '\n')
+	for i := 0; i < 7; i++ {
+		_, err = w.Write(line)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	// 每个文件不超过1MB，只保留3个文件
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err, name)
+		assert.True(t, info.Size() <= 1<<20, name)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+	info, _ := os.Stat(path)
+	assert.Equal(t, int64(len(line)), info.Size())
+
+	// 重新打开时继续计算已有文件的大小，只保留1个文件时切割即删除
+	w, err = newRotateWriter(path, 1, 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(line)), w.size)
+	_, err = w.Write(line)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(fmt.Sprintln("rotated")))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "rotated\n", string(data))
+
+	// 未设置时使用默认值
+	w, err = newRotateWriter(path, 0, 0)
+	require.NoError(t, err)
+	defer w.Close()
+	assert.Equal(t, int64(processLogMaxSizeDef<<20), w.maxSize)
+	assert.Equal(t, processLogMaxFileDef, w.maxFile)
+}
@@ -0,0 +1,180 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+)
+
+// SettingsSchema 插件设置的JSON Schema，仅支持一层object，由前端渲染为设置表单
+type SettingsSchema struct {
+	Type       string                    `json:"type"`
+	Properties map[string]SettingsSchema `json:"properties,omitempty"`
+	Required   []string                  `json:"required,omitempty"`
+
+	Title       string        `json:"title,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Minimum     *float64      `json:"minimum,omitempty"`
+	Maximum     *float64      `json:"maximum,omitempty"`
+}
+
+// ParseSettingsSchema 解析插件配置中的设置定义，未定义时返回nil
+func ParseSettingsSchema(data json.RawMessage) (*SettingsSchema, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var schema SettingsSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	if schema.Type != "object" {
+		return nil, fmt.Errorf("settings schema type should be object, got %s", schema.Type)
+	}
+	return &schema, nil
+}
+
+// Validate 校验设置是否符合定义，不接受未定义的字段
+func (s SettingsSchema) Validate(settings map[string]interface{}) error {
+	for _, name := range s.Required {
+		if _, ok := settings[name]; !ok {
+			return fmt.Errorf("%s required", name)
+		}
+	}
+	for name, val := range settings {
+		prop, ok := s.Properties[name]
+		if !ok {
+			return fmt.Errorf("unknown setting %s", name)
+		}
+		if err := prop.validateVal(val); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (s SettingsSchema) validateVal(val interface{}) error {
+	switch s.Type {
+	case "string":
+		if _, ok := val.(string); !ok {
+			return errors.New("should be string")
+		}
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			return errors.New("should be boolean")
+		}
+	case "integer", "number":
+		v, ok := val.(float64)
+		if !ok {
+			return errors.New("should be number")
+		}
+		if s.Type == "integer" && v != float64(int64(v)) {
+			return errors.New("should be integer")
+		}
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("should be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			return fmt.Errorf("should be <= %v", *s.Maximum)
+		}
+	}
+	if len(s.Enum) == 0 {
+		return nil
+	}
+	for _, e := range s.Enum {
+		if e == val {
+			return nil
+		}
+	}
+	return fmt.Errorf("should be one of %v", s.Enum)
+}
+
+// Defaults 根据定义生成默认设置
+func (s SettingsSchema) Defaults() map[string]interface{} {
+	settings := make(map[string]interface{})
+	for name, prop := range s.Properties {
+		if prop.Default != nil {
+			settings[name] = prop.Default
+		}
+	}
+	return settings
+}
+
+// GetSettingsSchema 获取插件的设置定义，优先使用运行中插件的配置
+func GetSettingsSchema(areaID uint64, pluginID string) (*SettingsSchema, error) {
+	if conf := GetGlobalClient().Config(pluginID); conf.ID != "" {
+		return ParseSettingsSchema(conf.Settings)
+	}
+	pi, err := entity.GetPlugin(pluginID, areaID)
+	if err != nil {
+		return nil, err
+	}
+	var conf Config
+	if len(pi.ConfigMsg) != 0 {
+		if err = json.Unmarshal(pi.ConfigMsg, &conf); err != nil {
+			return nil, err
+		}
+	}
+	return ParseSettingsSchema(conf.Settings)
+}
+
+// GetSettings 获取插件在家庭中的设置，未设置过时返回默认设置
+func GetSettings(areaID uint64, pluginID string, schema *SettingsSchema) (settings map[string]interface{}, err error) {
+	ps, err := entity.GetPluginSettings(areaID, pluginID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return
+		}
+		err = nil
+		if schema != nil {
+			settings = schema.Defaults()
+		}
+		return
+	}
+	err = json.Unmarshal(ps.Settings, &settings)
+	return
+}
+
+// UpdateSettings 保存插件设置并下发给插件
+func UpdateSettings(ctx context.Context, areaID uint64, pluginID string, settings map[string]interface{}) (err error) {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return
+	}
+	if err = entity.SavePluginSettings(areaID, pluginID, data); err != nil {
+		return
+	}
+	return GetGlobalClient().UpdateSettings(ctx, pluginID, data)
+}
+
+// pushSettings 插件连接时下发当前设置
+func (pc *pluginClient) pushSettings() {
+	if !pc.compatibility.HasCapability(sdk.CapabilitySettings) {
+		return
+	}
+	schema, err := ParseSettingsSchema(pc.PluginConf.Settings)
+	if err != nil {
+		logger.Warningf("plugin %s settings schema invalid: %s", pc.pluginID, err)
+		return
+	}
+	if schema == nil {
+		return
+	}
+	settings, err := GetSettings(pc.areaID, pc.pluginID, schema)
+	if err != nil {
+		logger.Errorf("get plugin %s settings err: %s", pc.pluginID, err)
+		return
+	}
+	data, _ := json.Marshal(settings)
+	if err = pc.updateSettings(context.Background(), data); err != nil {
+		logger.Errorf("push plugin %s settings err: %s", pc.pluginID, err)
+	}
+}
@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSettingsSchema = `{
+	"type": "object",
+	"properties": {
+		"region": {"type": "string", "enum": ["cn", "us"], "default": "cn"},
+		"api_key": {"type": "string"},
+		"interval": {"type": "integer", "minimum": 10, "maximum": 3600, "default": 60},
+		"debug": {"type": "boolean"}
+	},
+	"required": ["region"]
+}`
+
+func TestParseSettingsSchema(t *testing.T) {
+	schema, err := ParseSettingsSchema(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+	schema, err = ParseSettingsSchema(json.RawMessage("null"))
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+	_, err = ParseSettingsSchema(json.RawMessage(`{"type": "string"}`))
+	assert.Error(t, err)
+
+	schema, err = ParseSettingsSchema(json.RawMessage(testSettingsSchema))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"region": "cn", "interval": float64(60)}, schema.Defaults())
+}
+
+func TestSettingsSchemaValidate(t *testing.T) {
+	schema, err := ParseSettingsSchema(json.RawMessage(testSettingsSchema))
+	require.NoError(t, err)
+
+	assert.NoError(t, schema.Validate(map[string]interface{}{"region": "us", "api_key": "k", "interval": float64(600), "debug": true}))
+	invalid := []map[string]interface{}{
+		{},
+		{"region": "eu"},
+		{"region": "cn", "api_key": 1},
+		{"region": "cn", "interval": float64(5)},
+		{"region": "cn", "interval": 60.5},
+		{"region": "cn", "debug": "true"},
+		{"region": "cn", "proxy": "http://127.0.0.1"},
+	}
+	for _, settings := range invalid {
+		assert.Error(t, schema.Validate(settings), "%v", settings)
+	}
+}
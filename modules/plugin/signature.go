@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/signature"
+)
+
+// VerifyPackage 使用受信任的发布者公钥校验插件包的签名，返回发布者
+// 开发模式下允许未签名的插件包，此时发布者为空
+func VerifyPackage(path string) (publisher string, err error) {
+	ts, err := signature.LoadTrustStore(config.GetConf().SmartAssistant.TrustedKeysPath())
+	if err != nil {
+		err = errors.Wrap(err, errors.InternalServerErr)
+		return
+	}
+	m, err := signature.Verify(path, ts)
+	if err == signature.ErrUnsigned {
+		if config.GetConf().Debug {
+			logger.Warnf("plugin package %s is unsigned, only allowed in debug mode", path)
+			return "", nil
+		}
+		return "", errors.New(status.PluginUnsigned)
+	}
+	if err != nil {
+		return "", errors.Newf(status.PluginSignatureInvalid, err.Error())
+	}
+	logger.Infof("plugin package %s signed by %s", path, m.Publisher)
+	return m.Publisher, nil
+}
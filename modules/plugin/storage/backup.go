@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+// snapshotPath 插件存储的备份文件，位于数据目录中，随SA一起备份
+func snapshotPath() string {
+	return filepath.Join(config.GetConf().SmartAssistant.DataPath(), "smartassistant", "plugin_storage.json")
+}
+
+// restoreMarkPath 还原标记文件，位于备份目录中，还原数据目录时不会被覆盖
+func restoreMarkPath() string {
+	return filepath.Join(config.GetConf().SmartAssistant.BackupPath(), "plugin_storage.restore")
+}
+
+// writeFile 先写入临时文件再重命名，避免中断时留下不完整的文件
+func writeFile(path string, data []byte) (err error) {
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "*."+filepath.Base(path))
+	if err != nil {
+		return
+	}
+	defer func() {
+		f.Close()
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err != nil {
+		return
+	}
+	if err = f.Sync(); err != nil {
+		return
+	}
+	if err = f.Chmod(0600); err != nil {
+		return
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Dump 备份前导出所有插件的存储到数据目录
+func Dump() (err error) {
+	pss, err := entity.GetAllPluginStorages()
+	if err != nil {
+		return
+	}
+	data, err := json.Marshal(pss)
+	if err != nil {
+		return
+	}
+	return writeFile(snapshotPath(), data)
+}
+
+// MarkRestore 还原前标记，SA重启后从还原的数据目录导入插件存储
+func MarkRestore() error {
+	return writeFile(restoreMarkPath(), nil)
+}
+
+// CancelRestore 还原失败时清除标记，避免下次启动时导入未还原的数据
+func CancelRestore() {
+	if err := os.Remove(restoreMarkPath()); err != nil && !os.IsNotExist(err) {
+		logger.Warnf("remove plugin storage restore mark err: %s", err)
+	}
+}
+
+// Restore 存在还原标记时，从备份文件导入插件的存储，需在插件启动前调用。
+// 导入成功后才清除标记，失败时保留标记以便下次启动时重试
+func Restore() {
+	if _, err := os.Stat(restoreMarkPath()); err != nil {
+		return
+	}
+
+	data, err := ioutil.ReadFile(snapshotPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			// 备份中没有插件存储，无需导入
+			logger.Warnf("plugin storage snapshot not found, skip restore")
+			CancelRestore()
+			return
+		}
+		logger.Errorf("read plugin storage snapshot err: %s", err)
+		return
+	}
+	var pss []entity.PluginStorage
+	if err = json.Unmarshal(data, &pss); err != nil {
+		logger.Errorf("unmarshal plugin storage snapshot err: %s", err)
+		return
+	}
+	if err = entity.ReplacePluginStorages(pss); err != nil {
+		logger.Errorf("restore plugin storage err: %s", err)
+		return
+	}
+	CancelRestore()
+	logger.Infof("restore %d plugin storage items", len(pss))
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	// 数据库仍使用测试配置的目录，备份相关的文件使用临时目录
+	entity.GetDB()
+	dir, err := ioutil.TempDir("", "plugin-storage")
+	if err != nil {
+		panic(err)
+	}
+	config.GetConf().SmartAssistant.RuntimePath = dir
+	code := m.Run()
+	config.TestTeardown()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func markExists() bool {
+	_, err := os.Stat(restoreMarkPath())
+	return err == nil
+}
+
+func getValue(t *testing.T, areaID uint64, key string) string {
+	ps, err := entity.GetPluginStorage(areaID, "demo", key)
+	require.NoError(t, err)
+	return string(ps.Value)
+}
+
+func TestRestore(t *testing.T) {
+	area, err := entity.CreateArea("storage", entity.AreaOfHome)
+	require.NoError(t, err)
+	require.NoError(t, entity.SavePluginStorage(area.ID, "demo", "token", []byte("v1")))
+	require.NoError(t, Dump())
+
+	// 导出时不留下临时文件
+	files, err := ioutil.ReadDir(filepath.Dir(snapshotPath()))
+	require.NoError(t, err)
+	for _, f := range files {
+		assert.NotContains(t, f.Name(), ".plugin_storage.json")
+	}
+
+	require.NoError(t, entity.SavePluginStorage(area.ID, "demo", "token", []byte("v2")))
+	require.NoError(t, MarkRestore())
+	Restore()
+	assert.Equal(t, "v1", getValue(t, area.ID, "token"))
+	assert.False(t, markExists())
+
+	// 没有标记时不导入
+	require.NoError(t, entity.SavePluginStorage(area.ID, "demo", "token", []byte("v3")))
+	Restore()
+	assert.Equal(t, "v3", getValue(t, area.ID, "token"))
+}
+
+func TestRestoreFailure(t *testing.T) {
+	area, err := entity.CreateArea("storage", entity.AreaOfHome)
+	require.NoError(t, err)
+	require.NoError(t, entity.SavePluginStorage(area.ID, "demo", "key", []byte("v1")))
+	require.NoError(t, Dump())
+	data, err := ioutil.ReadFile(snapshotPath())
+	require.NoError(t, err)
+
+	// 导入失败时保留标记和原有数据，下次启动时重试
+	require.NoError(t, MarkRestore())
+	require.NoError(t, ioutil.WriteFile(snapshotPath(), []byte("{"), 0600))
+	require.NoError(t, entity.SavePluginStorage(area.ID, "demo", "key", []byte("v2")))
+	Restore()
+	assert.True(t, markExists())
+	assert.Equal(t, "v2", getValue(t, area.ID, "key"))
+
+	require.NoError(t, ioutil.WriteFile(snapshotPath(), data, 0600))
+	Restore()
+	assert.False(t, markExists())
+	assert.Equal(t, "v1", getValue(t, area.ID, "key"))
+
+	// 还原取消后不再导入
+	require.NoError(t, MarkRestore())
+	CancelRestore()
+	require.NoError(t, entity.SavePluginStorage(area.ID, "demo", "key", []byte("v3")))
+	Restore()
+	assert.Equal(t, "v3", getValue(t, area.ID, "key"))
+
+	// 备份中没有插件存储时清除标记
+	require.NoError(t, os.Remove(snapshotPath()))
+	require.NoError(t, MarkRestore())
+	Restore()
+	assert.False(t, markExists())
+	assert.Equal(t, "v3", getValue(t, area.ID, "key"))
+}
@@ -0,0 +1,260 @@
+// Package storage SA提供给插件的键值存储服务
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gorm.io/gorm"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+)
+
+const (
+	maxKeySize   = 256
+	maxValueSize = 1 << 20 // 单个值最大1M
+)
+
+// Token 生成插件访问存储服务的凭证，由SA启动插件时通过环境变量传入
+func Token(areaID uint64, pluginID string) string {
+	mac := hmac.New(sha256.New, []byte(config.GetConf().SmartAssistant.Key))
+	mac.Write([]byte(fmt.Sprintf("%s:%d", pluginID, areaID)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// scope 存储的隔离范围
+type scope struct {
+	AreaID   uint64
+	PluginID string
+}
+
+// watcher 插件的监听，事件先放入队列再按顺序发送，发送慢时不丢失事件
+type watcher struct {
+	scope
+	prefix  string
+	mu      sync.Mutex
+	pending []*proto.StorageEvent
+	signal  chan struct{}
+}
+
+func (w *watcher) push(ev *proto.StorageEvent) {
+	w.mu.Lock()
+	w.pending = append(w.pending, ev)
+	w.mu.Unlock()
+	select {
+	case w.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (w *watcher) take() (pending []*proto.StorageEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pending, w.pending = w.pending, nil
+	return
+}
+
+// Server 插件键值存储服务
+type Server struct {
+	mu       sync.Mutex
+	watchers map[*watcher]struct{}
+}
+
+func NewServer() *Server {
+	return &Server{
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+// authScope 校验请求的凭证，返回请求插件的存储范围
+func authScope(ctx context.Context) (s scope, err error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		err = status.Error(codes.Unauthenticated, "metadata required")
+		return
+	}
+	get := func(key string) string {
+		if v := md.Get(key); len(v) != 0 {
+			return v[0]
+		}
+		return ""
+	}
+	s.PluginID = get(sdk.StorageMetaPluginID)
+	s.AreaID, _ = strconv.ParseUint(get(sdk.StorageMetaAreaID), 10, 64)
+	token := get(sdk.StorageMetaToken)
+	if s.PluginID == "" || !hmac.Equal([]byte(token), []byte(Token(s.AreaID, s.PluginID))) {
+		err = status.Error(codes.Unauthenticated, "invalid storage token")
+	}
+	return
+}
+
+// AuthPlugin 校验插件请求携带的凭证，返回请求的插件，内置注册中心使用与存储服务相同的凭证
+func AuthPlugin(ctx context.Context) (pluginID string, err error) {
+	s, err := authScope(ctx)
+	if err != nil {
+		return
+	}
+	return s.PluginID, nil
+}
+
+func checkKey(key string) error {
+	if key == "" || len(key) > maxKeySize {
+		return status.Errorf(codes.InvalidArgument, "key length should be 1-%d", maxKeySize)
+	}
+	return nil
+}
+
+func (s *Server) Get(ctx context.Context, req *proto.StorageKey) (resp *proto.StorageItem, err error) {
+	sc, err := authScope(ctx)
+	if err != nil {
+		return
+	}
+	ps, err := entity.GetPluginStorage(sc.AreaID, sc.PluginID, req.Key)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = status.Errorf(codes.NotFound, "key %s not found", req.Key)
+		}
+		return
+	}
+	return &proto.StorageItem{Key: ps.Key, Value: ps.Value}, nil
+}
+
+func (s *Server) Put(ctx context.Context, req *proto.StorageItem) (resp *emptypb.Empty, err error) {
+	sc, err := authScope(ctx)
+	if err != nil {
+		return
+	}
+	if err = checkKey(req.Key); err != nil {
+		return
+	}
+	if len(req.Value) > maxValueSize {
+		err = status.Errorf(codes.InvalidArgument, "value size should be less than %d", maxValueSize)
+		return
+	}
+	if err = entity.SavePluginStorage(sc.AreaID, sc.PluginID, req.Key, req.Value); err != nil {
+		return
+	}
+	s.notify(sc, &proto.StorageEvent{Type: proto.StorageEvent_PUT, Item: req})
+	return new(emptypb.Empty), nil
+}
+
+func (s *Server) Delete(ctx context.Context, req *proto.StorageKey) (resp *emptypb.Empty, err error) {
+	sc, err := authScope(ctx)
+	if err != nil {
+		return
+	}
+	if err = entity.DelPluginStorage(sc.AreaID, sc.PluginID, req.Key); err != nil {
+		return
+	}
+	s.notify(sc, &proto.StorageEvent{Type: proto.StorageEvent_DELETE, Item: &proto.StorageItem{Key: req.Key}})
+	return new(emptypb.Empty), nil
+}
+
+func (s *Server) List(ctx context.Context, req *proto.StoragePrefix) (resp *proto.StorageItems, err error) {
+	sc, err := authScope(ctx)
+	if err != nil {
+		return
+	}
+	pss, err := entity.ListPluginStorages(sc.AreaID, sc.PluginID, req.Prefix)
+	if err != nil {
+		return
+	}
+	resp = new(proto.StorageItems)
+	for _, ps := range pss {
+		resp.Items = append(resp.Items, &proto.StorageItem{Key: ps.Key, Value: ps.Value})
+	}
+	return
+}
+
+func (s *Server) Watch(req *proto.StoragePrefix, server proto.Storage_WatchServer) error {
+	sc, err := authScope(server.Context())
+	if err != nil {
+		return err
+	}
+	w := &watcher{
+		scope:  sc,
+		prefix: req.Prefix,
+		signal: make(chan struct{}, 1),
+	}
+	s.mu.Lock()
+	s.watchers[w] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+	}()
+
+	for {
+		for _, ev := range w.take() {
+			if err = server.Send(ev); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-server.Context().Done():
+			return nil
+		case <-w.signal:
+		}
+	}
+}
+
+// notify 通知监听该范围及前缀的插件
+func (s *Server) notify(sc scope, ev *proto.StorageEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.watchers {
+		if w.scope != sc || !strings.HasPrefix(ev.Item.Key, w.prefix) {
+			continue
+		}
+		w.push(ev)
+	}
+}
+
+// Run 运行提供给插件的grpc服务，services 为同时提供的其他服务（如内置注册中心）
+func (s *Server) Run(ctx context.Context, services ...func(*grpc.Server)) {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()),
+	)
+	proto.RegisterStorageServer(server, s)
+	for _, register := range services {
+		register(server)
+	}
+	lis, err := net.Listen("tcp", config.GetConf().SmartAssistant.GRPCAddress())
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error(r)
+			}
+		}()
+		if err = server.Serve(lis); err != nil {
+			logger.Error(err)
+		}
+	}()
+	<-ctx.Done()
+	server.Stop()
+	logger.Warning("plugin storage server stopped")
+}
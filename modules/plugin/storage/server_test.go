@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+)
+
+// watchStream 模拟插件的监听，release 关闭前发送阻塞，模拟接收慢的插件
+type watchStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	release chan struct{}
+	events  chan *proto.StorageEvent
+}
+
+func (s *watchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *watchStream) Send(ev *proto.StorageEvent) error {
+	<-s.release
+	s.events <- ev
+	return nil
+}
+
+func pluginContext(areaID uint64) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		sdk.StorageMetaPluginID, "demo",
+		sdk.StorageMetaAreaID, strconv.FormatUint(areaID, 10),
+		sdk.StorageMetaToken, Token(areaID, "demo"),
+	))
+}
+
+func TestWatchSlowReceiver(t *testing.T) {
+	area, err := entity.CreateArea("storage", entity.AreaOfHome)
+	require.NoError(t, err)
+	s := NewServer()
+	ctx, cancel := context.WithCancel(pluginContext(area.ID))
+	defer cancel()
+	stream := &watchStream{ctx: ctx, release: make(chan struct{}), events: make(chan *proto.StorageEvent, 200)}
+	go s.Watch(&proto.StoragePrefix{Prefix: "watch/"}, stream)
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.watchers) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// 插件接收慢时事件不丢失，按顺序发送
+	const count = 100
+	for i := 0; i < count; i++ {
+		_, err = s.Put(pluginContext(area.ID), &proto.StorageItem{Key: fmt.Sprintf("watch/%d", i), Value: []byte("v")})
+		require.NoError(t, err)
+	}
+	_, err = s.Put(pluginContext(area.ID), &proto.StorageItem{Key: "other", Value: []byte("v")})
+	require.NoError(t, err)
+	_, err = s.Delete(pluginContext(area.ID), &proto.StorageKey{Key: "watch/0"})
+	require.NoError(t, err)
+	close(stream.release)
+
+	for i := 0; i < count; i++ {
+		select {
+		case ev := <-stream.events:
+			assert.Equal(t, proto.StorageEvent_PUT, ev.Type)
+			assert.Equal(t, fmt.Sprintf("watch/%d", i), ev.Item.Key)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("receive event %d timeout", i)
+		}
+	}
+	select {
+	case ev := <-stream.events:
+		assert.Equal(t, proto.StorageEvent_DELETE, ev.Type)
+		assert.Equal(t, "watch/0", ev.Item.Key)
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive delete event timeout")
+	}
+
+	// 插件断开后不再监听
+	cancel()
+	assert.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.watchers) == 0
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,318 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/pkg/event"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+const (
+	superviseInterval = time.Second * 15 // 插件检查间隔
+	startGracePeriod  = time.Minute      // 容器启动后等待插件注册服务的时间
+	maxPingFailures   = 3                // 连续无响应次数达到后重启插件
+
+	restartBackoffBase  = time.Second * 10
+	restartBackoffMax   = time.Minute * 10
+	restartBackoffReset = time.Minute * 10 // 健康运行超过这个时间后重置退避
+
+	// ReasonOOMKilled 插件超过内存限制被kill
+	ReasonOOMKilled = "oom killed"
+)
+
+type HealthStatus string
+
+// 插件的健康状态
+const (
+	HealthStatusUnknown  HealthStatus = "unknown"
+	HealthStatusHealthy  HealthStatus = "healthy"
+	HealthStatusDegraded HealthStatus = "degraded" // 容器运行中，但插件服务未注册或无响应
+	HealthStatusDown     HealthStatus = "down"     // 容器未运行或崩溃重启中
+)
+
+// Health 插件的健康状态
+type Health struct {
+	Status        HealthStatus `json:"status"`
+	CrashCount    int          `json:"crash_count"`
+	LastError     string       `json:"last_error,omitempty"`
+	LastCheckAt   int64        `json:"last_check_at,omitempty"`
+	NextRestartAt int64        `json:"next_restart_at,omitempty"` // 等待重启时的下次重启时间
+	OOMKills      int          `json:"oom_kills"`                 // SA启动后插件超过内存限制被kill的次数
+}
+
+type pluginHealth struct {
+	Health
+	areaID       uint64
+	restartCount int // docker记录的容器重启次数
+	pingFailures int
+	downChecks   int
+	restarts     int // 退避期间的重启次数
+	healthySince time.Time
+	nextRestart  time.Time
+}
+
+// Supervisor 监控插件运行状态，崩溃或无响应时按指数退避重启插件
+type Supervisor struct {
+	mu       sync.Mutex
+	healths  map[string]*pluginHealth
+	oomKills map[string]int // 插件被OOM kill的次数，由docker事件更新
+}
+
+var (
+	supervisor     *Supervisor
+	supervisorOnce sync.Once
+)
+
+func GetSupervisor() *Supervisor {
+	supervisorOnce.Do(func() {
+		supervisor = &Supervisor{
+			healths:  make(map[string]*pluginHealth),
+			oomKills: make(map[string]int),
+		}
+	})
+	return supervisor
+}
+
+// Health 获取插件的健康状态
+func (s *Supervisor) Health(pluginID string) Health {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h, ok := s.healths[pluginID]; ok {
+		return h.Health
+	}
+	return Health{Status: HealthStatusUnknown}
+}
+
+func (s *Supervisor) Run(ctx context.Context) {
+	logger.Info("starting plugin supervisor")
+	go s.watchOOM(ctx)
+	ticker := time.NewTicker(superviseInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Warning("plugin supervisor stopped")
+			return
+		case <-ticker.C:
+			s.superviseAll(ctx)
+		}
+	}
+}
+
+func (s *Supervisor) superviseAll(ctx context.Context) {
+	pis, err := entity.GetInstalledPlugins()
+	if err != nil {
+		logger.Errorf("get installed plugins err: %s", err)
+		return
+	}
+	installed := make(map[string]struct{})
+	for _, pi := range pis {
+		if _, ok := installed[pi.PluginID]; ok {
+			continue
+		}
+		installed[pi.PluginID] = struct{}{}
+		s.supervise(ctx, pi)
+	}
+
+	s.mu.Lock()
+	for id := range s.healths {
+		if _, ok := installed[id]; !ok {
+			delete(s.healths, id)
+			delete(s.oomKills, id)
+		}
+	}
+	s.mu.Unlock()
+}
+
+// supervise 检查单个插件：容器状态、插件服务的存活检查，必要时重启
+func (s *Supervisor) supervise(ctx context.Context, pi entity.PluginInfo) {
+	if isUpgrading(pi.AreaID, pi.PluginID) {
+		// 更新过程中容器会被替换，由更新流程检查及回滚
+		return
+	}
+	s.mu.Lock()
+	prev, ok := s.healths[pi.PluginID]
+	if !ok {
+		prev = &pluginHealth{
+			Health: Health{
+				Status:     HealthStatusUnknown,
+				CrashCount: pi.CrashCount,
+				LastError:  pi.LastError,
+			},
+			areaID:       pi.AreaID,
+			restartCount: -1,
+		}
+	}
+	h := *prev
+	oomKills := s.oomKills[pi.PluginID]
+	s.mu.Unlock()
+
+	if oomKills > h.OOMKills {
+		h.OOMKills = oomKills
+		h.LastError = ReasonOOMKilled
+	}
+
+	plg := NewFromEntity(pi)
+	status, reason, restart := s.check(ctx, plg, &h)
+	if restart {
+		s.restart(plg, &h)
+	}
+	s.update(pi.PluginID, prev.Health, &h, status, reason)
+}
+
+// check 返回插件当前的状态，以及是否需要重启
+func (s *Supervisor) check(ctx context.Context, plg Plugin, h *pluginHealth) (status HealthStatus, reason string, restart bool) {
+	ins, err := GetRuntime().Inspect(plg.Image)
+	if err != nil {
+		// 安装或更新插件时实例会短暂不存在，连续两次检查不存在才重启
+		h.downChecks++
+		return HealthStatusDown, fmt.Sprintf("plugin instance not found: %s", err), h.downChecks > 1
+	}
+
+	// 运行时自动重启的次数也计入崩溃次数
+	if h.restartCount >= 0 && ins.RestartCount > h.restartCount {
+		h.CrashCount += ins.RestartCount - h.restartCount
+		h.LastError = exitReason(ins)
+	}
+	h.restartCount = ins.RestartCount
+
+	if ins.Restarting {
+		// 崩溃重启中，交给运行时处理
+		return HealthStatusDown, exitReason(ins), false
+	}
+	if !ins.Running {
+		// 正常停止（如备份时停止插件）的插件退出码为0，不需要重启
+		h.downChecks++
+		return HealthStatusDown, exitReason(ins), ins.ExitCode != 0
+	}
+	h.downChecks = 0
+
+	// 不兼容的插件不会被连接，重启也无法恢复
+	if compatibility, ok := GetCompatibility(plg.ID); ok && !compatibility.Compatible {
+		return HealthStatusDegraded, compatibility.Reason, false
+	}
+
+	if err = GetGlobalClient().Ping(ctx, plg.ID); err != nil {
+		if time.Since(ins.StartedAt) < startGracePeriod {
+			return h.Status, "", false
+		}
+		h.pingFailures++
+		if err == NotExistErr {
+			reason = "plugin service not registered"
+		} else {
+			reason = fmt.Sprintf("ping err: %s", err)
+		}
+		return HealthStatusDegraded, reason, h.pingFailures >= maxPingFailures
+	}
+	h.pingFailures = 0
+	return HealthStatusHealthy, "", false
+}
+
+// restart 按指数退避重启插件
+func (s *Supervisor) restart(plg Plugin, h *pluginHealth) {
+	now := time.Now()
+	if now.Before(h.nextRestart) {
+		return
+	}
+	h.CrashCount++
+	backoff := restartBackoffBase << uint(h.restarts)
+	if backoff > restartBackoffMax || backoff <= 0 {
+		backoff = restartBackoffMax
+	}
+	h.restarts++
+	h.nextRestart = now.Add(backoff)
+	h.pingFailures = 0
+
+	logger.Warnf("restarting plugin %s, next restart after %s", plg.ID, backoff)
+	rt := GetRuntime()
+	var err error
+	if ins, inspectErr := rt.Inspect(plg.Image); inspectErr != nil {
+		_, err = RunPlugin(plg)
+	} else if ins.Running {
+		err = rt.Restart(plg.Image)
+	} else {
+		err = rt.Start(plg.Image)
+	}
+	if err != nil {
+		logger.Errorf("restart plugin %s err: %s", plg.ID, err)
+		h.LastError = fmt.Sprintf("restart err: %s", err)
+	}
+}
+
+// update 保存插件状态，状态变化时通知，崩溃信息变化时记录到数据库
+func (s *Supervisor) update(pluginID string, prev Health, h *pluginHealth, status HealthStatus, reason string) {
+	now := time.Now()
+	if reason != "" {
+		h.LastError = reason
+	}
+	h.Status = status
+	h.LastCheckAt = now.Unix()
+	if status == HealthStatusHealthy {
+		if prev.Status != HealthStatusHealthy {
+			h.healthySince = now
+		}
+		if now.Sub(h.healthySince) > restartBackoffReset {
+			h.restarts = 0
+		}
+		h.nextRestart = time.Time{}
+		h.NextRestartAt = 0
+	} else if !h.nextRestart.IsZero() {
+		h.NextRestartAt = h.nextRestart.Unix()
+	}
+	s.mu.Lock()
+	s.healths[pluginID] = h
+	s.mu.Unlock()
+
+	if h.CrashCount != prev.CrashCount || h.LastError != prev.LastError {
+		if err := entity.UpdatePluginHealth(pluginID, h.CrashCount, h.LastError); err != nil {
+			logger.Errorf("update plugin %s health err: %s", pluginID, err)
+		}
+	}
+	if status == prev.Status {
+		return
+	}
+	if status != HealthStatusHealthy {
+		logger.Warnf("plugin %s %s: %s", pluginID, status, h.LastError)
+	}
+	em := event.NewEventMessage(event.PluginHealth, h.areaID)
+	em.Param = map[string]interface{}{
+		"plugin_id":   pluginID,
+		"status":      h.Status,
+		"crash_count": h.CrashCount,
+		"last_error":  h.LastError,
+	}
+	event.Notify(em)
+}
+
+func exitReason(ins Instance) string {
+	if ins.OOMKilled {
+		return ReasonOOMKilled
+	}
+	if ins.Error != "" {
+		return ins.Error
+	}
+	return fmt.Sprintf("exited with code %d", ins.ExitCode)
+}
+
+// watchOOM 监听插件超过内存限制被kill的事件
+func (s *Supervisor) watchOOM(ctx context.Context) {
+	events := GetRuntime().OOMEvents(ctx)
+	if events == nil {
+		return
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case pluginID := <-events:
+			logger.Warnf("plugin %s oom killed", pluginID)
+			s.mu.Lock()
+			s.oomKills[pluginID]++
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,322 @@
+package plugin
+
+import (
+	"context"
+	errors2 "errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+)
+
+// fakeRuntime 模拟插件运行时，记录对实例及镜像的操作
+type fakeRuntime struct {
+	Runtime
+
+	mu        sync.Mutex
+	instances map[string]Instance
+	calls     []string
+	onRun     func(plg Plugin) // 模拟插件运行后注册服务
+}
+
+func (r *fakeRuntime) Name() string {
+	return "fake"
+}
+
+func (r *fakeRuntime) setInstance(image string, ins Instance) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instances[image] = ins
+}
+
+func (r *fakeRuntime) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *fakeRuntime) popCalls() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := r.calls
+	r.calls = nil
+	return calls
+}
+
+func (r *fakeRuntime) Inspect(image string) (Instance, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ins, ok := r.instances[image]
+	if !ok {
+		return Instance{}, errors2.New("no such instance")
+	}
+	return ins, nil
+}
+
+func (r *fakeRuntime) IsRunning(image string) bool {
+	ins, err := r.Inspect(image)
+	return err == nil && ins.Running
+}
+
+func (r *fakeRuntime) Pull(image string) error {
+	r.record("pull " + image)
+	return nil
+}
+
+func (r *fakeRuntime) Tag(source, target string) error {
+	r.record("tag " + source + " " + target)
+	return nil
+}
+
+func (r *fakeRuntime) RemoveImage(image string) error {
+	r.record("rmi " + image)
+	return nil
+}
+
+func (r *fakeRuntime) Run(plg Plugin, opts RunOptions) (string, error) {
+	r.record("run " + plg.Image)
+	r.setInstance(plg.Image, Instance{Running: true, StartedAt: time.Now()})
+	r.mu.Lock()
+	onRun := r.onRun
+	r.mu.Unlock()
+	if onRun != nil {
+		onRun(plg)
+	}
+	return plg.Image, nil
+}
+
+func (r *fakeRuntime) Stop(image string) error {
+	r.record("stop " + image)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ins, ok := r.instances[image]; ok {
+		ins.Running = false
+		r.instances[image] = ins
+	}
+	return nil
+}
+
+func (r *fakeRuntime) Remove(image string) error {
+	r.record("rm " + image)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.instances, image)
+	return nil
+}
+
+func (r *fakeRuntime) Start(image string) error {
+	r.record("start " + image)
+	return nil
+}
+
+func (r *fakeRuntime) Restart(image string) error {
+	r.record("restart " + image)
+	return nil
+}
+
+func (r *fakeRuntime) OOMEvents(ctx context.Context) <-chan string {
+	return nil
+}
+
+// fakeClient 模拟插件服务，pingErrs 中的插件无响应
+type fakeClient struct {
+	Client
+
+	mu       sync.Mutex
+	pingErrs map[string]error
+	configs  map[string]Plugin
+	online   map[string]bool
+}
+
+// register 模拟插件注册服务
+func (c *fakeClient) register(plg Plugin) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.configs[plg.ID] = plg
+	registrations.Store(plg.ID, time.Now())
+}
+
+func (c *fakeClient) setOnline(iid string, online bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.online[iid] = online
+}
+
+func (c *fakeClient) Config(pluginID string) Plugin {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.configs[pluginID]
+}
+
+func (c *fakeClient) IsOnline(identify Identify) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.online[identify.IID]
+}
+
+func (c *fakeClient) setPingErr(pluginID string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pingErrs[pluginID] = err
+}
+
+func (c *fakeClient) Ping(ctx context.Context, pluginID string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pingErrs[pluginID]
+}
+
+var (
+	testRuntime = &fakeRuntime{instances: make(map[string]Instance)}
+	testClient  = &fakeClient{
+		pingErrs: make(map[string]error),
+		configs:  make(map[string]Plugin),
+		online:   make(map[string]bool),
+	}
+)
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	runtimeOnce.Do(func() { pluginRuntime = testRuntime })
+	SetGlobalClient(testClient)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+func newTestHealth() *pluginHealth {
+	return &pluginHealth{Health: Health{Status: HealthStatusUnknown}, restartCount: -1}
+}
+
+func TestSupervisorCheck(t *testing.T) {
+	s := &Supervisor{healths: make(map[string]*pluginHealth), oomKills: make(map[string]int)}
+	plg := Plugin{ID: "check", Image: "check:1.0"}
+	h := newTestHealth()
+	started := time.Now().Add(-2 * startGracePeriod)
+
+	testRuntime.setInstance(plg.Image, Instance{Running: true, StartedAt: started})
+	status, _, restart := s.check(context.Background(), plg, h)
+	assert.Equal(t, HealthStatusHealthy, status)
+	assert.False(t, restart)
+
+	// 运行时自动重启的次数计入崩溃次数
+	testRuntime.setInstance(plg.Image, Instance{Restarting: true, RestartCount: 2, ExitCode: 2})
+	status, _, restart = s.check(context.Background(), plg, h)
+	assert.Equal(t, HealthStatusDown, status)
+	assert.False(t, restart)
+	assert.Equal(t, 2, h.CrashCount)
+	assert.Equal(t, "exited with code 2", h.LastError)
+
+	testRuntime.setInstance(plg.Image, Instance{RestartCount: 2, OOMKilled: true, ExitCode: 137})
+	status, reason, restart := s.check(context.Background(), plg, h)
+	assert.Equal(t, HealthStatusDown, status)
+	assert.Equal(t, ReasonOOMKilled, reason)
+	assert.True(t, restart)
+
+	// 正常停止的插件不重启
+	testRuntime.setInstance(plg.Image, Instance{RestartCount: 2})
+	_, _, restart = s.check(context.Background(), plg, h)
+	assert.False(t, restart)
+
+	// 连续多次无响应后重启
+	testRuntime.setInstance(plg.Image, Instance{Running: true, RestartCount: 2, StartedAt: started})
+	testClient.setPingErr(plg.ID, NotExistErr)
+	defer testClient.setPingErr(plg.ID, nil)
+	for i := 1; i <= maxPingFailures; i++ {
+		status, reason, restart = s.check(context.Background(), plg, h)
+		assert.Equal(t, HealthStatusDegraded, status)
+		assert.Equal(t, "plugin service not registered", reason)
+		assert.Equal(t, i == maxPingFailures, restart)
+	}
+
+	// 刚启动的插件等待注册服务
+	h = newTestHealth()
+	testRuntime.setInstance(plg.Image, Instance{Running: true, StartedAt: time.Now()})
+	status, _, restart = s.check(context.Background(), plg, h)
+	assert.Equal(t, HealthStatusUnknown, status)
+	assert.False(t, restart)
+	assert.Zero(t, h.pingFailures)
+}
+
+func TestSupervisorCheckNotFound(t *testing.T) {
+	s := &Supervisor{}
+	plg := Plugin{ID: "missing", Image: "missing:1.0"}
+	h := newTestHealth()
+
+	// 安装或更新时实例会短暂不存在，连续两次不存在才重启
+	status, _, restart := s.check(context.Background(), plg, h)
+	assert.Equal(t, HealthStatusDown, status)
+	assert.False(t, restart)
+	_, _, restart = s.check(context.Background(), plg, h)
+	assert.True(t, restart)
+}
+
+func TestSupervisorRestartBackoff(t *testing.T) {
+	s := &Supervisor{}
+	plg := Plugin{ID: "backoff", Image: "backoff:1.0"}
+	h := newTestHealth()
+	testRuntime.setInstance(plg.Image, Instance{ExitCode: 1})
+	testRuntime.popCalls()
+
+	s.restart(plg, h)
+	assert.Equal(t, []string{"start backoff:1.0"}, testRuntime.popCalls())
+	assert.Equal(t, 1, h.CrashCount)
+	assert.WithinDuration(t, time.Now().Add(restartBackoffBase), h.nextRestart, time.Second)
+
+	// 退避期间不重启
+	s.restart(plg, h)
+	assert.Empty(t, testRuntime.popCalls())
+	assert.Equal(t, 1, h.CrashCount)
+
+	// 退避时间按次数加倍，不超过最大值
+	testRuntime.setInstance(plg.Image, Instance{Running: true})
+	h.nextRestart = time.Time{}
+	s.restart(plg, h)
+	assert.Equal(t, []string{"restart backoff:1.0"}, testRuntime.popCalls())
+	assert.WithinDuration(t, time.Now().Add(2*restartBackoffBase), h.nextRestart, time.Second)
+
+	h.restarts, h.nextRestart = 20, time.Time{}
+	s.restart(plg, h)
+	assert.WithinDuration(t, time.Now().Add(restartBackoffMax), h.nextRestart, time.Second)
+}
+
+func TestSupervise(t *testing.T) {
+	area, err := entity.CreateArea("supervise", entity.AreaOfHome)
+	require.NoError(t, err)
+	pi := entity.PluginInfo{AreaID: area.ID, PluginID: "supervise", Image: "supervise:1.0",
+		Status: entity.StatusInstallSuccess}
+	require.NoError(t, entity.GetDB().Create(&pi).Error)
+
+	s := &Supervisor{healths: make(map[string]*pluginHealth), oomKills: make(map[string]int)}
+	testRuntime.setInstance(pi.Image, Instance{Running: true, StartedAt: time.Now().Add(-2 * startGracePeriod)})
+	s.supervise(context.Background(), pi)
+	assert.Equal(t, HealthStatusHealthy, s.Health(pi.PluginID).Status)
+	assert.Equal(t, HealthStatusUnknown, s.Health("unknown").Status)
+
+	// 崩溃后重启并记录到数据库
+	testRuntime.setInstance(pi.Image, Instance{ExitCode: 1})
+	testRuntime.popCalls()
+	s.supervise(context.Background(), pi)
+	assert.Equal(t, []string{"start supervise:1.0"}, testRuntime.popCalls())
+	health := s.Health(pi.PluginID)
+	assert.Equal(t, HealthStatusDown, health.Status)
+	assert.Equal(t, 1, health.CrashCount)
+	assert.NotZero(t, health.NextRestartAt)
+
+	saved, err := entity.GetPlugin(pi.PluginID, area.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, saved.CrashCount)
+	assert.Equal(t, "exited with code 1", saved.LastError)
+
+	// OOM kill 的次数由运行时事件更新
+	s.oomKills[pi.PluginID] = 1
+	s.supervise(context.Background(), pi)
+	health = s.Health(pi.PluginID)
+	assert.Equal(t, 1, health.OOMKills)
+}
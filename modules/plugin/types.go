@@ -2,10 +2,8 @@ package plugin
 
 import (
 	"context"
-	errors2 "errors"
+	"encoding/json"
 	"fmt"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/mount"
 	"github.com/go-playground/validator/v10"
 	"os"
 	"path/filepath"
@@ -18,7 +16,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 
 	"github.com/zhiting-tech/smartassistant/modules/entity"
-	"github.com/zhiting-tech/smartassistant/modules/plugin/docker"
+	"github.com/zhiting-tech/smartassistant/modules/plugin/storage"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 )
 
@@ -36,6 +34,7 @@ const (
 	TypeSecurity       DeviceType = "security"        // 安防
 	TypeSensor         DeviceType = "sensor"          // 传感器
 	TypeLifeElectric   DeviceType = "life_electric"   // 生活电器
+	TypeAudioVideo     DeviceType = "audio_video"     // 影音娱乐
 
 	TypeLamp             DeviceType = "lamp"               // 台灯
 	TypeCeilingLamp      DeviceType = "ceiling_lamp"       // 吸顶灯
@@ -68,6 +67,10 @@ const (
 
 	TypeCurtain DeviceType = "curtain" // 窗帘电机
 
+	TypeTelevision   DeviceType = "television"    // 电视
+	TypeSmartSpeaker DeviceType = "smart_speaker" // 智能音箱
+	TypeMediaPlayer  DeviceType = "media_player"  // 播放器
+
 	TypeTemperatureAndHumiditySensor DeviceType = "temperature_humidity_sensor" // 温湿度传感器
 	TypeHumanSensors                 DeviceType = "human_sensor"                // 人体传感器
 	TypeSmokeSensor                  DeviceType = "smoke_sensor"                // 烟雾传感器
@@ -78,34 +81,21 @@ const (
 	TypeDynamicAndStaticSensor       DeviceType = "dynamic_static_sensor"       // 动静传感器
 )
 
-const (
-	cpuShare = 512 // CPU资源紧张时插件CPU资源权重设为为512，保证不与SA服务抢资源。SA为默认1024
-
-	// 两个参数绑定一起，标识只允许使用 cpuQuota / cpuPeriod 个cpu资源
-	cpuPeriod = 100000
-	cpuQuota  = 10000
-
-	networkModeHost = "host"
-)
-
 const (
 	DebugMode   = "debug"
 	ReleaseMode = "release"
 )
 
-var (
-	alwaysRestart = container.RestartPolicy{
-		Name: "always",
-	}
-)
-
 // Config 插件配置
 type Config struct {
-	Name                 string         `yaml:"name" json:"name" validate:"required"`                       // 插件名称
-	Version              string         `yaml:"version" json:"version" validate:"required"`                 // 版本
-	Info                 string         `yaml:"info" json:"info"`                                           // 介绍
-	SupportDevices       []DeviceConfig `yaml:"support_devices" json:"support_devices" validate:"required"` // 支持的设备
-	DefaultDeviceConfigs []DeviceConfig `yaml:"default_device_configs" json:"default_device_configs"`       // 默认支持的设备类型
+	Name                 string          `yaml:"name" json:"name" validate:"required"`                       // 插件名称
+	Version              string          `yaml:"version" json:"version" validate:"required"`                 // 版本
+	Info                 string          `yaml:"info" json:"info"`                                           // 介绍
+	SupportDevices       []DeviceConfig  `yaml:"support_devices" json:"support_devices" validate:"required"` // 支持的设备
+	DefaultDeviceConfigs []DeviceConfig  `yaml:"default_device_configs" json:"default_device_configs"`       // 默认支持的设备类型
+	Settings             json.RawMessage `yaml:"settings" json:"settings,omitempty"`                         // 插件设置的JSON Schema
+	Resources            Resources       `yaml:"resources" json:"resources"`                                 // 插件容器的资源限制
+	Entrypoint           string          `yaml:"entrypoint" json:"entrypoint,omitempty"`                     // 以进程运行时插件目录中的可执行文件，默认为plugin
 }
 
 // ID 根据配置生成插件ID
@@ -115,7 +105,13 @@ func (p Config) ID() string {
 func (p Config) Validate() error {
 	defaultValidator := validator.New()
 	defaultValidator.SetTagName("validate")
-	return defaultValidator.Struct(p)
+	if err := defaultValidator.Struct(p); err != nil {
+		return err
+	}
+	if _, err := ParseSettingsSchema(p.Settings); err != nil {
+		return err
+	}
+	return p.Resources.Validate()
 }
 
 // Plugin 插件详情
@@ -126,19 +122,28 @@ type Plugin struct {
 	Image  string `json:"image" yaml:"image"`
 	Source string `json:"source" yaml:"source"` // 插件来源
 	AreaID uint64 `json:"area_id" yaml:"area_id"`
+
+	Publisher string `json:"publisher,omitempty" yaml:"publisher"` // 开发插件包签名的发布者
 }
 
 func NewFromEntity(p entity.PluginInfo) Plugin {
+	var resources Resources
+	if len(p.Resources) != 0 {
+		_ = json.Unmarshal(p.Resources, &resources)
+	}
 	return Plugin{
 		Config: Config{
-			Name:    p.PluginID,
-			Version: p.Version,
-			Info:    p.Info,
+			Name:      p.PluginID,
+			Version:   p.Version,
+			Info:      p.Info,
+			Resources: resources,
 		},
 		ID:     p.PluginID,
 		Image:  p.Image,
 		AreaID: p.AreaID,
 		Source: p.Source,
+
+		Publisher: p.Publisher,
 	}
 }
 
@@ -147,6 +152,11 @@ func (p Plugin) IsDevelopment() bool {
 	return p.Source == entity.SourceTypeDevelopment
 }
 
+// IsUnsigned 是否未签名的开发插件，未签名的开发插件仅能在开发模式下运行
+func (p Plugin) IsUnsigned() bool {
+	return p.IsDevelopment() && p.Publisher == ""
+}
+
 func (p Plugin) IsAdded() bool {
 	// return docker.GetClient().IsImageAdd(p.Image.RefStr())
 	return entity.IsPluginAdd(p.ID, p.AreaID)
@@ -171,8 +181,7 @@ func (p Plugin) IsNewest() bool {
 }
 
 func (p Plugin) IsRunning() bool {
-	isRunning, _ := docker.GetClient().ContainerIsRunningByImage(p.Image)
-	return isRunning
+	return GetRuntime().IsRunning(p.Image)
 }
 
 // Up 启动插件
@@ -185,14 +194,14 @@ func (p Plugin) Up() (err error) {
 	return err
 }
 func (p Plugin) UpdateOrInstall() (err error) {
+	if p.IsAdded() {
+		return p.Update()
+	}
 	if !p.IsDevelopment() {
-		if err = docker.GetClient().Pull(p.Image); err != nil {
+		if err = GetRuntime().Pull(p.Image); err != nil {
 			return errors.Wrap(err, status.PluginPullFail)
 		}
 	}
-	if p.IsAdded() {
-		return p.Update()
-	}
 	return p.Install()
 }
 
@@ -204,7 +213,12 @@ func (p Plugin) Install() (err error) {
 	if err = p.Up(); err != nil {
 		return errors.Wrap(err, status.PluginUpFail)
 	}
+	return p.saveInfo()
+}
 
+// saveInfo 保存已安装插件的信息
+func (p Plugin) saveInfo() (err error) {
+	resources, _ := json.Marshal(p.Resources)
 	var pi = entity.PluginInfo{
 		Name:     p.Name,
 		AreaID:   p.AreaID,
@@ -215,6 +229,8 @@ func (p Plugin) Install() (err error) {
 		Version:  p.Version,
 		Source:   p.Source,
 		Brand:    p.Brand,
+
+		Resources: resources,
 	}
 	if err = entity.SavePluginInfo(pi); err != nil {
 		logger.Errorf("UpdatePluginStatus err: %s", err.Error())
@@ -223,19 +239,6 @@ func (p Plugin) Install() (err error) {
 	return
 }
 
-// Update 更新插件
-func (p Plugin) Update() (err error) {
-	if p.Source == entity.SourceTypeDevelopment {
-		return errors2.New("plugin in development can't update")
-	}
-	logger.Info("update plugin:", p.ID)
-
-	if err = p.StopAndRemovePluginImage(); err != nil {
-		return
-	}
-	return p.Install()
-}
-
 // StopAndRemovePluginImage 停止插件容器并删除插件镜像
 // 确保容器和镜像删除就行，如果不存在不需要报错
 func (p Plugin) StopAndRemovePluginImage() (err error) {
@@ -245,17 +248,14 @@ func (p Plugin) StopAndRemovePluginImage() (err error) {
 	if err != nil {
 		return
 	}
-	if err = docker.GetClient().StopContainer(plgInfo.Image); err != nil {
-		return
-	}
-	if err = docker.GetClient().RemoveContainer(plgInfo.Image); err != nil {
+	if err = stopAndRemoveContainer(plgInfo.Image); err != nil {
 		return
 	}
 
 	if plgInfo.Image == p.Image {
 		return
 	}
-	if err = docker.GetClient().ImageRemove(plgInfo.Image); err != nil {
+	if err = GetRuntime().RemoveImage(plgInfo.Image); err != nil {
 		return
 	}
 	return
@@ -353,43 +353,39 @@ func RunPlugin(plg Plugin) (containerID string, err error) {
 	if config.GetConf().Debug {
 		mode = DebugMode
 	}
-	conf := container.Config{
-		Image: plg.Image,
+	if plg.IsUnsigned() && mode == ReleaseMode {
+		err = errors.New(status.PluginUnsigned)
+		return
+	}
+	// 插件数据目录
+	dataDir := filepath.Join(config.GetConf().SmartAssistant.RuntimePath,
+		"data", "plugin", plg.Brand, plg.Name)
+	if err = os.MkdirAll(dataDir, os.ModePerm); err != nil {
+		return
+	}
+	// 需要使用宿主机能识别的路径来挂载，TODO 当前实现可能导致混乱
+	hostDataDir := filepath.Join(config.GetConf().SmartAssistant.HostRuntimePath,
+		"data", "plugin", plg.Brand, plg.Name)
+	resources := GetResources(plg).Effective
+	logger.Debugf("plugin %s resources: %+v", plg.ID, resources)
+	opts := RunOptions{
 		Env: []string{
 			fmt.Sprintf("PLUGIN_DOMAIN=%s", plg.ID),
 			fmt.Sprintf("AREA_ID=%d", plg.AreaID),
 			fmt.Sprintf("SA_ID=%s", config.GetConf().SmartAssistant.ID),
 			fmt.Sprintf("SDK_VERSION=%s", "2.0"),
 			fmt.Sprintf("PLUGIN_MODE=%s", mode),
+			fmt.Sprintf("SA_GRPC_ADDR=127.0.0.1:%d", config.GetConf().SmartAssistant.GRPCPort),
+			fmt.Sprintf("SA_STORAGE_TOKEN=%s", storage.Token(plg.AreaID, plg.ID)),
+			fmt.Sprintf("SA_REGISTRY=%s", registryType()),
 		},
 		Labels: map[string]string{
-			"com.zhiting.smartassistant.resource.service_type": "plugin",
-			"com.zhiting.smartassistant.resource.service_name": plg.Name,
-		},
-	}
-	// 映射插件目录到宿主机上
-	source := filepath.Join(config.GetConf().SmartAssistant.RuntimePath,
-		"data", "plugin", plg.Brand, plg.Name)
-	if err = os.MkdirAll(source, os.ModePerm); err != nil {
-		return
-	}
-	target := "/app/data/"
-	logger.Debugf("mount %s to %s", source, target)
-
-	// 需要使用宿主机能识别的路径来挂载，TODO 当前实现可能导致混乱
-	hostSource := filepath.Join(config.GetConf().SmartAssistant.HostRuntimePath,
-		"data", "plugin", plg.Brand, plg.Name)
-	hostConf := container.HostConfig{
-		NetworkMode:   networkModeHost,
-		RestartPolicy: alwaysRestart,
-		Mounts: []mount.Mount{
-			{Type: mount.TypeBind, Source: hostSource, Target: target},
-		},
-		Resources: container.Resources{
-			CPUShares: cpuShare,
-			CPUPeriod: cpuPeriod,
-			CPUQuota:  cpuQuota,
+			labelServiceType: "plugin",
+			labelServiceName: plg.Name,
 		},
+		Resources:   resources,
+		DataDir:     dataDir,
+		HostDataDir: hostDataDir,
 	}
-	return docker.GetClient().ContainerRun(plg.Image, conf, hostConf)
+	return GetRuntime().Run(plg, opts)
 }
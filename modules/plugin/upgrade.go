@@ -0,0 +1,266 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	errors2 "errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+var (
+	upgradeTimeout       = time.Minute * 2 // 新版本需要在这个时间内通过检查，否则回滚
+	upgradeCheckInterval = time.Second * 2
+)
+
+const (
+	upgradeSampleDevices = 3  // 更新后需要重新连接的设备数
+	upgradeHistoryMax    = 20 // 保留的更新记录数
+
+	previousTagSuffix = "-previous"
+)
+
+type UpgradeStatus string
+
+// 插件更新的结果
+const (
+	UpgradeStatusSuccess    UpgradeStatus = "success"
+	UpgradeStatusRolledBack UpgradeStatus = "rolled_back"
+	UpgradeStatusFailed     UpgradeStatus = "failed" // 回滚也失败了
+)
+
+// UpgradeRecord 插件的更新记录
+type UpgradeRecord struct {
+	FromVersion string        `json:"from_version"`
+	ToVersion   string        `json:"to_version"`
+	FromImage   string        `json:"from_image"`
+	ToImage     string        `json:"to_image"`
+	Status      UpgradeStatus `json:"status"`
+	Reason      string        `json:"reason,omitempty"`
+	StartedAt   int64         `json:"started_at"`
+	FinishedAt  int64         `json:"finished_at"`
+}
+
+type upgradeKey struct {
+	areaID   uint64
+	pluginID string
+}
+
+// upgrading 正在更新的插件，更新期间插件监控不处理这些插件
+var upgrading sync.Map
+
+func isUpgrading(areaID uint64, pluginID string) bool {
+	_, ok := upgrading.Load(upgradeKey{areaID: areaID, pluginID: pluginID})
+	return ok
+}
+
+// GetUpgradeHistory 获取插件的更新记录，最近的在前
+func GetUpgradeHistory(pi entity.PluginInfo) (history []UpgradeRecord) {
+	if len(pi.UpgradeHistory) != 0 {
+		_ = json.Unmarshal(pi.UpgradeHistory, &history)
+	}
+	return
+}
+
+// Update 分阶段更新插件：保留当前版本的镜像，启动新版本并等待其注册服务、通过存活检查
+// 以及重新连接部分设备，超时未通过则自动回滚到当前版本
+func (p Plugin) Update() (err error) {
+	if p.Source == entity.SourceTypeDevelopment {
+		return errors2.New("plugin in development can't update")
+	}
+	key := upgradeKey{areaID: p.AreaID, pluginID: p.ID}
+	if _, loaded := upgrading.LoadOrStore(key, struct{}{}); loaded {
+		return errors2.New("plugin is updating")
+	}
+	defer upgrading.Delete(key)
+	logger.Info("update plugin:", p.ID)
+
+	prev, err := entity.GetPlugin(p.ID, p.AreaID)
+	if err != nil {
+		return
+	}
+	record := UpgradeRecord{
+		FromVersion: prev.Version,
+		ToVersion:   p.Version,
+		FromImage:   prev.Image,
+		ToImage:     p.Image,
+		StartedAt:   time.Now().Unix(),
+	}
+
+	// 新版本的镜像可能与当前版本使用同一个标签，拉取前先为当前镜像打上回滚用的标签
+	rt := GetRuntime()
+	previousImage := previousImageRef(prev.Image)
+	if err = rt.Tag(prev.Image, previousImage); err != nil {
+		return
+	}
+	if err = rt.Pull(p.Image); err != nil {
+		return errors.Wrap(err, status.PluginPullFail)
+	}
+
+	samples := onlineDevices(p.ID, p.AreaID, upgradeSampleDevices)
+	if err = stopAndRemoveContainer(prev.Image); err != nil {
+		return
+	}
+
+	// 旧版本停止后重新注册的才是新版本的插件服务
+	since := time.Now()
+	if err = p.Up(); err == nil {
+		err = p.waitReady(since, samples)
+	}
+	if err != nil {
+		logger.Errorf("update plugin %s to %s err: %s, rolling back", p.ID, p.Version, err)
+		record.Reason = err.Error()
+		record.Status = UpgradeStatusRolledBack
+		if rollbackErr := p.rollback(prev, previousImage); rollbackErr != nil {
+			logger.Errorf("rollback plugin %s err: %s", p.ID, rollbackErr)
+			record.Status = UpgradeStatusFailed
+			record.Reason = fmt.Sprintf("%s; rollback: %s", record.Reason, rollbackErr)
+		}
+		record.FinishedAt = time.Now().Unix()
+		if saveErr := entity.UpdatePluginUpgradeHistory(p.ID, p.AreaID, appendHistory(prev, record)); saveErr != nil {
+			logger.Errorf("save plugin %s upgrade history err: %s", p.ID, saveErr)
+		}
+		return errors.Newf(status.PluginUpgradeRolledBack, prev.Version)
+	}
+
+	record.Status = UpgradeStatusSuccess
+	record.FinishedAt = time.Now().Unix()
+	if err = p.saveInfo(); err != nil {
+		return
+	}
+	if err = entity.UpdatePluginUpgrade(p.ID, p.AreaID, previousImage, prev.Version, appendHistory(prev, record)); err != nil {
+		return
+	}
+
+	// 只保留上一个版本的镜像
+	if prev.PreviousImage != "" && prev.PreviousImage != previousImage {
+		if err = rt.RemoveImage(prev.PreviousImage); err != nil {
+			logger.Warnf("remove plugin %s image %s err: %s", p.ID, prev.PreviousImage, err)
+		}
+	}
+	if prev.Image != p.Image {
+		if err = rt.RemoveImage(prev.Image); err != nil {
+			logger.Warnf("remove plugin %s image %s err: %s", p.ID, prev.Image, err)
+		}
+	}
+	return nil
+}
+
+// waitReady 等待新版本的插件在 since 之后重新注册服务、通过存活检查并重新连接设备，
+// 避免新旧版本号相同时使用旧版本的连接通过检查
+func (p Plugin) waitReady(since time.Time, samples []Identify) error {
+	ctx, cancel := context.WithTimeout(context.Background(), upgradeTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(upgradeCheckInterval)
+	defer ticker.Stop()
+	var reason string
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("new version not ready in %s: %s", upgradeTimeout, reason)
+		case <-ticker.C:
+		}
+
+		if !GetRuntime().IsRunning(p.Image) {
+			reason = "container not running"
+			continue
+		}
+		if !registeredAt(p.ID).After(since) {
+			reason = "plugin service not registered"
+			if compatibility, ok := GetCompatibility(p.ID); ok && !compatibility.Compatible {
+				reason = compatibility.Reason
+			}
+			continue
+		}
+		if conf := GetGlobalClient().Config(p.ID); conf.Version != p.Version {
+			reason = fmt.Sprintf("plugin version %s registered", conf.Version)
+			continue
+		}
+		if err := GetGlobalClient().Ping(ctx, p.ID); err != nil {
+			reason = fmt.Sprintf("ping err: %s", err)
+			continue
+		}
+		reason = ""
+		for _, identify := range samples {
+			if !GetGlobalClient().IsOnline(identify) {
+				reason = fmt.Sprintf("device %s not reconnected", identify.IID)
+				break
+			}
+		}
+		if reason == "" {
+			return nil
+		}
+	}
+}
+
+// rollback 删除新版本的容器，使用保留的镜像重新运行当前版本
+func (p Plugin) rollback(prev entity.PluginInfo, previousImage string) (err error) {
+	if err = stopAndRemoveContainer(p.Image); err != nil {
+		return
+	}
+	rt := GetRuntime()
+	if err = rt.Tag(previousImage, prev.Image); err != nil {
+		return
+	}
+	if p.Image != prev.Image {
+		if err = rt.RemoveImage(p.Image); err != nil {
+			logger.Warnf("remove plugin %s image %s err: %s", p.ID, p.Image, err)
+		}
+	}
+	return NewFromEntity(prev).Up()
+}
+
+// onlineDevices 选取插件当前在线的部分设备，更新后需要重新连接这些设备
+func onlineDevices(pluginID string, areaID uint64, n int) (identifies []Identify) {
+	devices, err := entity.GetDevicesByPluginID(pluginID)
+	if err != nil {
+		logger.Errorf("get plugin %s devices err: %s", pluginID, err)
+		return
+	}
+	for _, d := range devices {
+		if len(identifies) >= n {
+			break
+		}
+		if d.AreaID != areaID {
+			continue
+		}
+		identify := Identify{PluginID: pluginID, IID: d.IID, AreaID: d.AreaID}
+		if GetGlobalClient().IsOnline(identify) {
+			identifies = append(identifies, identify)
+		}
+	}
+	return
+}
+
+func stopAndRemoveContainer(image string) (err error) {
+	rt := GetRuntime()
+	if err = rt.Stop(image); err != nil {
+		return
+	}
+	return rt.Remove(image)
+}
+
+// previousImageRef 回滚用的镜像标签，如 zhiting/demo:1.0 -> zhiting/demo:1.0-previous
+func previousImageRef(image string) string {
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		return image + previousTagSuffix
+	}
+	return image + ":latest" + previousTagSuffix
+}
+
+func appendHistory(pi entity.PluginInfo, record UpgradeRecord) []byte {
+	history := append([]UpgradeRecord{record}, GetUpgradeHistory(pi)...)
+	if len(history) > upgradeHistoryMax {
+		history = history[:upgradeHistoryMax]
+	}
+	data, _ := json.Marshal(history)
+	return data
+}
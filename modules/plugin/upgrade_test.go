@@ -0,0 +1,160 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+)
+
+// setupUpgrade 安装运行中的旧版本插件，缩短更新的等待时间
+func setupUpgrade(t *testing.T, pluginID string) entity.PluginInfo {
+	timeout, interval := upgradeTimeout, upgradeCheckInterval
+	upgradeTimeout, upgradeCheckInterval = time.Millisecond*300, time.Millisecond*10
+	t.Cleanup(func() {
+		upgradeTimeout, upgradeCheckInterval = timeout, interval
+		testRuntime.onRun = nil
+	})
+
+	area, err := entity.CreateArea("upgrade", entity.AreaOfHome)
+	require.NoError(t, err)
+	pi := entity.PluginInfo{AreaID: area.ID, PluginID: pluginID, Image: pluginID + ":1.0", Version: "1.0",
+		Status: entity.StatusInstallSuccess}
+	require.NoError(t, entity.GetDB().Create(&pi).Error)
+	testRuntime.setInstance(pi.Image, Instance{Running: true})
+	testClient.register(Plugin{ID: pluginID, Config: Config{Version: "1.0"}})
+	testRuntime.popCalls()
+	return pi
+}
+
+func newVersion(pi entity.PluginInfo, version string) Plugin {
+	return Plugin{
+		Config: Config{Name: pi.PluginID, Version: version},
+		ID:     pi.PluginID,
+		Image:  pi.PluginID + ":" + version,
+		AreaID: pi.AreaID,
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	pi := setupUpgrade(t, "upgrade")
+	d := entity.Device{Name: "light", PluginID: pi.PluginID, IID: "upgrade-light", AreaID: pi.AreaID}
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	testClient.setOnline(d.IID, true)
+
+	// 新版本注册服务后，设备断开并重新连接
+	testRuntime.onRun = func(plg Plugin) {
+		testClient.setOnline(d.IID, false)
+		go func() {
+			time.Sleep(time.Millisecond * 50)
+			testClient.register(plg)
+			testClient.setOnline(d.IID, true)
+		}()
+	}
+	plg := newVersion(pi, "2.0")
+	require.NoError(t, plg.Update())
+	assert.Equal(t, []string{
+		"tag upgrade:1.0 upgrade:1.0-previous",
+		"pull upgrade:2.0",
+		"stop upgrade:1.0",
+		"rm upgrade:1.0",
+		"run upgrade:2.0",
+		"rmi upgrade:1.0",
+	}, testRuntime.popCalls())
+
+	saved, err := entity.GetPlugin(pi.PluginID, pi.AreaID)
+	require.NoError(t, err)
+	assert.Equal(t, "upgrade:2.0", saved.Image)
+	assert.Equal(t, "upgrade:1.0-previous", saved.PreviousImage)
+	assert.Equal(t, "1.0", saved.PreviousVersion)
+	history := GetUpgradeHistory(saved)
+	require.Len(t, history, 1)
+	assert.Equal(t, UpgradeStatusSuccess, history[0].Status)
+	assert.False(t, isUpgrading(pi.AreaID, pi.PluginID))
+}
+
+func TestUpdateRollback(t *testing.T) {
+	pi := setupUpgrade(t, "rollback")
+
+	// 新版本与旧版本的版本号相同，但新版本未重新注册服务，不能使用旧版本的连接通过检查
+	plg := newVersion(pi, "1.0")
+	err := plg.Update()
+	if assert.Error(t, err) {
+		assert.Equal(t, status.PluginUpgradeRolledBack, err.(errors.Error).Code.Status)
+	}
+	assert.Equal(t, []string{
+		"tag rollback:1.0 rollback:1.0-previous",
+		"pull rollback:1.0",
+		"stop rollback:1.0",
+		"rm rollback:1.0",
+		"run rollback:1.0",
+		"stop rollback:1.0",
+		"rm rollback:1.0",
+		"tag rollback:1.0-previous rollback:1.0",
+		"run rollback:1.0",
+	}, testRuntime.popCalls())
+
+	saved, err := entity.GetPlugin(pi.PluginID, pi.AreaID)
+	require.NoError(t, err)
+	assert.Equal(t, "rollback:1.0", saved.Image)
+	assert.Empty(t, saved.PreviousImage)
+	history := GetUpgradeHistory(saved)
+	require.Len(t, history, 1)
+	assert.Equal(t, UpgradeStatusRolledBack, history[0].Status)
+	assert.Contains(t, history[0].Reason, "plugin service not registered")
+}
+
+func TestUpdateRollbackUnhealthy(t *testing.T) {
+	pi := setupUpgrade(t, "unhealthy")
+
+	// 新版本注册了服务但无响应
+	testRuntime.onRun = func(plg Plugin) {
+		if plg.Version == "2.0" {
+			testClient.register(plg)
+		}
+	}
+	testClient.setPingErr(pi.PluginID, context.DeadlineExceeded)
+	defer testClient.setPingErr(pi.PluginID, nil)
+	plg := newVersion(pi, "2.0")
+	assert.Error(t, plg.Update())
+	calls := testRuntime.popCalls()
+	assert.Equal(t, []string{
+		"stop unhealthy:2.0",
+		"rm unhealthy:2.0",
+		"tag unhealthy:1.0-previous unhealthy:1.0",
+		"rmi unhealthy:2.0",
+		"run unhealthy:1.0",
+	}, calls[len(calls)-5:])
+
+	saved, err := entity.GetPlugin(pi.PluginID, pi.AreaID)
+	require.NoError(t, err)
+	assert.Equal(t, "unhealthy:1.0", saved.Image)
+	history := GetUpgradeHistory(saved)
+	require.Len(t, history, 1)
+	assert.Contains(t, history[0].Reason, "ping err")
+}
+
+func TestUpdateInProgress(t *testing.T) {
+	pi := setupUpgrade(t, "progress")
+	key := upgradeKey{areaID: pi.AreaID, pluginID: pi.PluginID}
+	upgrading.Store(key, struct{}{})
+	defer upgrading.Delete(key)
+
+	assert.Error(t, newVersion(pi, "2.0").Update())
+	assert.Empty(t, testRuntime.popCalls())
+
+	// 其他家庭的同一插件不受影响
+	assert.True(t, isUpgrading(pi.AreaID, pi.PluginID))
+	assert.False(t, isUpgrading(pi.AreaID+1, pi.PluginID))
+}
+
+func TestPreviousImageRef(t *testing.T) {
+	assert.Equal(t, "zhiting/demo:1.0-previous", previousImageRef("zhiting/demo:1.0"))
+	assert.Equal(t, "localhost:5000/demo:latest-previous", previousImageRef("localhost:5000/demo"))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/modules/config"
 	"github.com/zhiting-tech/smartassistant/modules/plugin"
 	"github.com/zhiting-tech/smartassistant/modules/plugin/docker"
+	"github.com/zhiting-tech/smartassistant/modules/plugin/storage"
 	"github.com/zhiting-tech/smartassistant/modules/supervisor/proto"
 	"github.com/zhiting-tech/smartassistant/modules/types/status"
 	errors2 "github.com/zhiting-tech/smartassistant/pkg/errors"
@@ -102,18 +103,17 @@ func stopAllPlugins() (err error) {
 		}
 	}
 	plgs, err := plugin.GetGlobalManager().LoadPluginsWithContext(context.TODO())
-	cli := docker.GetClient()
+	rt := plugin.GetRuntime()
 	if err != nil {
 		return
 	}
 	stoppedPlgs := make([]plugin.Plugin, 0)
 	for _, plg := range plgs {
-		ps, _ := docker.GetClient().ContainerIsRunningByImage(plg.Image)
-		if ps == false {
+		if !rt.IsRunning(plg.Image) {
 			continue
 		}
 
-		err = cli.StopContainer(plg.Image)
+		err = rt.Stop(plg.Image)
 		if err != nil {
 			resumeContainer(stoppedPlgs)
 			return err
@@ -166,6 +166,10 @@ func (m *Manager) StartBackupJobWithContext(ctx context.Context, req entity.Back
 			}
 		}
 	}()
+	// 插件的存储导出到数据目录，随SA一起备份
+	if err = storage.Dump(); err != nil {
+		return
+	}
 	newCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
 	go func() {
 		time.Sleep(time.Second)
@@ -192,12 +196,17 @@ func (m *Manager) StartRestoreJobWithContext(ctx context.Context, file string) (
 			}
 		}
 	}()
+	// SA重启后从还原的数据中导入插件的存储
+	if err = storage.MarkRestore(); err != nil {
+		return
+	}
 	newCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
 	go func() {
 		time.Sleep(time.Second)
 		err = GetClient().RestoreSmartassistantWithContext(newCtx, file)
 		if err != nil {
 			logger.Errorf("restore error %v", err)
+			storage.CancelRestore()
 		}
 	}()
 
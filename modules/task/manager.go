@@ -16,6 +16,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 
 	"github.com/jinzhu/now"
 	"gorm.io/gorm"
@@ -31,6 +32,11 @@ type Manager interface {
 	DeleteSceneTask(sceneID int)
 	RestartSceneTask(sceneID int) error
 	DeviceStateChange(d entity.Device, attr definer.AttributeEvent) error
+	DeviceEventTrigger(d entity.Device, ev definer.DeviceEvent) error
+	// AddTaskAt 添加在t时执行的任务，key相同的未执行任务会被替换，用于温控计划等按时间编排的任务
+	AddTaskAt(key string, f TaskFunc, t time.Time)
+	// DeleteTask 删除key对应的未执行任务
+	DeleteTask(key string)
 	Run(ctx context.Context)
 }
 
@@ -85,6 +91,8 @@ type LocalManager struct {
 	queue        *queueServe
 	runningScene sync.Map // 正在执行的场景的id -> queue index
 	scenes       sync.Map // 保存queue中记录所有与entity.Scene相关的未执行的场景 sceneID -> *SceneTasks
+	keyTasks     map[string]*Task // 通过AddTaskAt添加的未执行任务
+	keyTasksMu   sync.Mutex
 }
 
 func NewLocalManager() *LocalManager {
@@ -218,6 +226,48 @@ func (m *LocalManager) pushTask(task *Task, target interface{}) {
 	m.queue.push(task)
 }
 
+// AddTaskAt 添加在t时执行的任务，key相同的未执行任务会被替换
+func (m *LocalManager) AddTaskAt(key string, f TaskFunc, t time.Time) {
+	task := NewTaskAt(f, t)
+	task.Value = key
+	task.WithWrapper(func(f TaskFunc) TaskFunc {
+		return func(task *Task) error {
+			m.keyTasksMu.Lock()
+			current := m.keyTasks[key]
+			if current == task {
+				delete(m.keyTasks, key)
+			}
+			m.keyTasksMu.Unlock()
+			// 任务已被替换或删除则不执行
+			if current != task {
+				return nil
+			}
+			return f(task)
+		}
+	})
+
+	m.keyTasksMu.Lock()
+	if m.keyTasks == nil {
+		m.keyTasks = make(map[string]*Task)
+	}
+	if old, ok := m.keyTasks[key]; ok {
+		m.queue.removeTask(old)
+	}
+	m.keyTasks[key] = task
+	m.keyTasksMu.Unlock()
+	m.queue.push(task)
+}
+
+// DeleteTask 删除key对应的未执行任务
+func (m *LocalManager) DeleteTask(key string) {
+	m.keyTasksMu.Lock()
+	defer m.keyTasksMu.Unlock()
+	if task, ok := m.keyTasks[key]; ok {
+		delete(m.keyTasks, key)
+		m.queue.removeTask(task)
+	}
+}
+
 // RestartSceneTask 重启场景对应的任务（就是删除然后重新添加任务）
 func (m *LocalManager) RestartSceneTask(sceneID int) error {
 	scene, err := entity.GetSceneInfoById(sceneID)
@@ -347,9 +397,11 @@ func (m *LocalManager) wrapTaskToFunc(task entity.SceneTask) (f TaskFunc) {
 func (m *LocalManager) executeDevice(task entity.SceneTask) (err error) {
 
 	var ds []entity.Attribute
-	if err := json.Unmarshal(task.Attributes, &ds); err != nil {
-		logger.Error(err)
-		return err
+	if len(task.Attributes) != 0 {
+		if err := json.Unmarshal(task.Attributes, &ds); err != nil {
+			logger.Error(err)
+			return err
+		}
 	}
 	for _, d := range ds {
 		var device entity.Device
@@ -363,12 +415,23 @@ func (m *LocalManager) executeDevice(task entity.SceneTask) (err error) {
 		logger.Debugf("execute device command device id:%d instance id:%d attr:%s val:%v",
 			device.ID, device.IID, "d.Attribute.Attribute", d.Attribute.Val)
 
+		// 场景保存后物模型可能已变化，执行前重新校验属性值
+		var tm thingmodel.ThingModel
+		if tm, err = device.GetThingModel(); err != nil {
+			return errors.Wrap(err, errors.InternalServerErr)
+		}
+		// 任务中的值使用保存时的单位，换算为设备的单位
+		val := tm.ValFrom(device.IID, d.Attribute.AID, d.Attribute.Val, d.Attribute.Unit)
+		if val, err = tm.ValidateWrite(device.IID, d.Attribute.AID, val); err != nil {
+			return errors.Wrapf(err, status.AttrValueInvalid, err.Error())
+		}
+
 		setReq := sdk.SetRequest{
 			Attributes: []sdk.SetAttribute{
 				{
 					IID: device.IID,
 					AID: d.Attribute.AID,
-					Val: d.Attribute.Val,
+					Val: val,
 				},
 			}}
 		err = plugin.SetAttributes(context.Background(), device.PluginID, device.AreaID, setReq)
@@ -381,6 +444,58 @@ func (m *LocalManager) executeDevice(task entity.SceneTask) (err error) {
 			return errors.Wrapf(err, status.DeviceOffline, identify.ID())
 		}
 	}
+	return m.executeDeviceActions(task)
+}
+
+// executeDeviceActions 执行设备动作
+func (m *LocalManager) executeDeviceActions(task entity.SceneTask) (err error) {
+	actions, err := task.GetActions()
+	if err != nil || len(actions) == 0 {
+		return
+	}
+	device, err := entity.GetDeviceByID(task.DeviceID)
+	if err != nil {
+		if errors2.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New(status.DeviceNotExist)
+		}
+		return errors.Wrap(err, http.StatusInternalServerError)
+	}
+	tm, err := device.GetThingModel()
+	if err != nil {
+		return errors.Wrap(err, errors.InternalServerErr)
+	}
+	identify := plugin.Identify{
+		PluginID: device.PluginID,
+		IID:      device.IID,
+		AreaID:   device.AreaID,
+	}
+	for _, ta := range actions {
+		logger.Debugf("execute device action device id:%d action:%s", device.ID, ta.Action)
+		// 场景保存后物模型可能已变化，执行前重新校验参数
+		var action thingmodel.Action
+		if action, err = tm.GetAction(device.IID, ta.Action); err != nil {
+			return errors.New(status.ActionNotFound)
+		}
+		var inputs map[string]interface{}
+		if inputs, err = action.ValidateInputs(ta.Inputs); err != nil {
+			return errors.Wrap(err, status.ActionInputsIncorrect)
+		}
+		if !plugin.GetGlobalClient().IsOnline(identify) {
+			return errors.Newf(status.DeviceOffline, identify.ID())
+		}
+		actionReq := sdk.ActionRequest{
+			IID:    device.IID,
+			Action: ta.Action,
+			Inputs: inputs,
+		}
+		if _, err = plugin.InvokeAction(context.Background(), device.PluginID, actionReq); err != nil {
+			// 保留插件返回的错误状态，如插件不支持动作
+			if _, ok := err.(errors.Error); ok {
+				return err
+			}
+			return errors.Wrap(err, errors.InternalServerErr)
+		}
+	}
 	return
 }
 
@@ -433,3 +548,31 @@ func (m *LocalManager) DeviceStateChange(d entity.Device, ac definer.AttributeEv
 	}
 	return
 }
+
+// DeviceEventTrigger 设备事件触发场景
+func (m *LocalManager) DeviceEventTrigger(d entity.Device, ev definer.DeviceEvent) (err error) {
+
+	conds, err := entity.GetConditionsByDeviceEvent(d.ID, ev)
+	if err != nil {
+		return fmt.Errorf("can't find conditions with device %d %s event %s",
+			d.ID, ev.IID, ev.Type)
+	}
+
+	for _, cond := range conds {
+		var scene entity.Scene
+		if scene, err = entity.GetSceneInfoById(cond.SceneID); err != nil {
+			logger.Error(err)
+			continue
+		}
+		if !scene.AutoRun {
+			continue
+		}
+		if !IsConditionsSatisfiedByEvent(scene, cond.ID) {
+			logger.Debugf("auto scene:%d's conditions not satisfied", scene.ID)
+			continue
+		}
+		t := NewTask(m.wrapSceneFunc(scene), 0)
+		m.pushTask(t, scene)
+	}
+	return nil
+}
@@ -1,15 +1,58 @@
 package task
 
 import (
+	"context"
+	"encoding/json"
+	errors2 "errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
 	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
+// fakeClient 模拟插件，只有online中的设备在线
+type fakeClient struct {
+	plugin.Client
+
+	mu      sync.Mutex
+	online  map[string]bool
+	errs    map[string]error
+	actions []sdk.ActionRequest
+}
+
+func (c *fakeClient) IsOnline(identify plugin.Identify) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.online[identify.IID]
+}
+
+func (c *fakeClient) SetAttributes(ctx context.Context, pluginID string, areaID uint64, setReq sdk.SetRequest) ([]byte, error) {
+	return nil, plugin.NotExistErr
+}
+
+func (c *fakeClient) InvokeAction(ctx context.Context, pluginID string, actionReq sdk.ActionRequest) (map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.errs[actionReq.IID]; err != nil {
+		return nil, err
+	}
+	c.actions = append(c.actions, actionReq)
+	return nil, nil
+}
+
+var testClient = &fakeClient{online: make(map[string]bool), errs: make(map[string]error)}
+
 func addDevice() *entity.Device {
 	d := &entity.Device{
 		Name:         "testing device",
@@ -109,3 +152,95 @@ func TestDeviceAutoScene(t *testing.T) {
 	assert.Nil(t, err)
 	assert.NotEmpty(t, len(taskLogs), "auto task log not found")
 }
+
+func TestAddTaskAt(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewLocalManager()
+	go m.queue.start(ctx)
+
+	var replaced, executed, deleted int32
+	at := time.Now().Add(time.Second)
+	m.AddTaskAt("replace", func(task *Task) error {
+		atomic.AddInt32(&replaced, 1)
+		return nil
+	}, at)
+	m.AddTaskAt("replace", func(task *Task) error {
+		atomic.AddInt32(&executed, 1)
+		return nil
+	}, at)
+	m.AddTaskAt("delete", func(task *Task) error {
+		atomic.AddInt32(&deleted, 1)
+		return nil
+	}, at)
+	m.DeleteTask("delete")
+
+	time.Sleep(3 * time.Second)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&replaced), "replaced task should not run")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&executed))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&deleted), "deleted task should not run")
+}
+
+func addMediaPlayer(t *testing.T, iid string) entity.Device {
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID: iid,
+		Services: []thingmodel.Service{{
+			Type:    thingmodel.MediaPlayer,
+			Actions: []thingmodel.Action{thingmodel.MediaPlay, thingmodel.MediaSeek},
+		}},
+	}}}
+	area, err := entity.CreateArea("task", entity.AreaOfHome)
+	require.NoError(t, err)
+	d := entity.Device{Name: iid, PluginID: "testing", IID: iid, AreaID: area.ID}
+	d.ThingModel, _ = json.Marshal(tm)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+func actionTask(d entity.Device, actions ...entity.TaskAction) entity.SceneTask {
+	data, _ := json.Marshal(actions)
+	return entity.SceneTask{Type: entity.TaskTypeSmartDevice, DeviceID: d.ID, Actions: data}
+}
+
+func assertStatus(t *testing.T, code int, err error) {
+	if e, ok := err.(errors.Error); assert.True(t, ok, "%v", err) {
+		assert.Equal(t, code, e.Code.Status)
+	}
+}
+
+func TestExecuteDeviceActions(t *testing.T) {
+	m := GetManager().(*LocalManager)
+	d := addMediaPlayer(t, "task-player")
+	testClient.online[d.IID] = true
+
+	// 参数按物模型转换后下发
+	seek := entity.TaskAction{Action: thingmodel.MediaSeek.Type, Inputs: map[string]interface{}{"position": "30"}}
+	assert.NoError(t, m.executeDeviceActions(actionTask(d, seek)))
+	require.Len(t, testClient.actions, 1)
+	assert.Equal(t, map[string]interface{}{"position": 30}, testClient.actions[0].Inputs)
+
+	// 保存后物模型变化导致参数不正确或动作不存在时不下发
+	seek.Inputs["position"] = -5
+	assertStatus(t, status.ActionInputsIncorrect, m.executeDeviceActions(actionTask(d, seek)))
+	assertStatus(t, status.ActionNotFound, m.executeDeviceActions(actionTask(d, entity.TaskAction{Action: "ptz_goto_preset"})))
+	assert.Len(t, testClient.actions, 1)
+}
+
+func TestExecuteDeviceActionsError(t *testing.T) {
+	m := GetManager().(*LocalManager)
+	play := entity.TaskAction{Action: thingmodel.MediaPlay.Type}
+
+	offline := addMediaPlayer(t, "task-offline")
+	assertStatus(t, status.DeviceOffline, m.executeDeviceActions(actionTask(offline, play)))
+
+	// 保留插件返回的错误状态
+	unsupported := addMediaPlayer(t, "task-unsupported")
+	testClient.online[unsupported.IID] = true
+	testClient.errs[unsupported.IID] = errors.Newf(status.PluginCapabilityNotSupport, sdk.CapabilityActions)
+	assertStatus(t, status.PluginCapabilityNotSupport, m.executeDeviceActions(actionTask(unsupported, play)))
+
+	failed := addMediaPlayer(t, "task-failed")
+	testClient.online[failed.IID] = true
+	testClient.errs[failed.IID] = errors2.New("rpc error: code = Unknown desc = device busy")
+	assertStatus(t, errors.InternalServerErr, m.executeDeviceActions(actionTask(failed, play)))
+}
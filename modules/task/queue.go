@@ -100,6 +100,17 @@ func (qs *queueServe) _remove(i int) {
 	qs.mu.Unlock()
 }
 
+// removeTask 移除队列中的任务，任务已出队时不处理
+func (qs *queueServe) removeTask(task *Task) {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	i := task.index
+	if i < 0 || i >= qs.pq.Len() || qs.pq[i] != task {
+		return
+	}
+	heap.Remove(&qs.pq, i)
+}
+
 func (qs *queueServe) _len() int {
 	qs.mu.Lock()
 	defer qs.mu.Unlock()
@@ -11,6 +11,7 @@ import (
 
 	"github.com/zhiting-tech/smartassistant/modules/entity"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
 // TaskFunc 任务运行函数
@@ -137,6 +138,31 @@ func IsConditionsSatisfied(scene entity.Scene, isTrigByTimer bool) bool {
 	return scene.IsMatchAllCondition()
 }
 
+// IsConditionsSatisfiedByEvent 设备事件触发时判断场景条件是否满足，触发的条件视为已满足
+func IsConditionsSatisfiedByEvent(scene entity.Scene, conditionID int) bool {
+	if !scene.IsOn {
+		logger.Debugf("scene %d: is off\n", scene.ID)
+		return false
+	}
+	if !IsInTimePeriod(scene) {
+		logger.Debugf("scene %d: not in effective time period\n", scene.ID)
+		return false
+	}
+	if !scene.IsMatchAllCondition() {
+		return true
+	}
+	for _, condition := range scene.SceneConditions {
+		if condition.ID == conditionID {
+			continue
+		}
+		if !IsConditionSatisfied(condition) {
+			logger.Debugf("scene %d: condition:%d not satisfied\n", scene.ID, condition.ID)
+			return false
+		}
+	}
+	return true
+}
+
 // IsInTimePeriod 是否在时间段内
 func IsInTimePeriod(scene entity.Scene) bool {
 
@@ -157,7 +183,9 @@ func IsInTimePeriod(scene entity.Scene) bool {
 
 // IsConditionSatisfied 判断设备状态是否满足条件
 func IsConditionSatisfied(condition entity.SceneCondition) bool {
-	if condition.ConditionType == entity.ConditionTypeTiming {
+	// 设备事件没有状态，只在事件发生时满足
+	if condition.ConditionType == entity.ConditionTypeTiming ||
+		condition.ConditionType == entity.ConditionTypeDeviceEvent {
 		return false
 	}
 
@@ -183,5 +211,10 @@ func IsConditionSatisfied(condition entity.SceneCondition) bool {
 		return false
 	}
 	logger.Debugf("%v %s %v\n", val, condition.Operator, item.Val)
-	return item.Operate(condition.Operator, val)
+	var unit thingmodel.Unit
+	if tm, err := d.GetThingModel(); err == nil {
+		attr, _ := tm.GetAttribute(d.IID, item.Attribute.AID)
+		unit = attr.Unit
+	}
+	return item.OperateFrom(condition.Operator, val, unit)
 }
@@ -82,6 +82,11 @@ var (
 	DepartmentUpdate      = Permission{"部门设置", ActionUpdate, "department", ""}
 )
 
+// 插件
+var (
+	PluginLogGet = Permission{"查看插件日志", ActionGet, "plugin", "log"}
+)
+
 var (
 	DevicePermission     = []Permission{DeviceAdd, DeviceUpdate, DeviceControl, DeviceDelete, DeviceUpdateOrder}
 	AreaPermission       = []Permission{AreaGetCode, AreaUpdateName, AreaUpdateMemberRole, AreaDelMember}
@@ -90,6 +95,7 @@ var (
 	ScenePermission      = []Permission{SceneAdd, SceneUpdate, SceneDel, SceneControl}
 	DepartmentPermission = []Permission{DepartmentAdd, DepartmentUpdateOrder, DepartmentGet, DepartmentAddUser, DepartmentUpdate}
 	CompanyPermission    = []Permission{AreaGetCode, AreaUpdateCompanyName, AreaUpdateMemberRole, AreaUpdateMemberDepartment, AreaDelMember}
+	PluginPermission     = []Permission{PluginLogGet}
 )
 
 var (
@@ -118,6 +124,16 @@ func NewDeviceManage(deviceID int, name string, attr string) Permission {
 	return Permission{name, ActionManage, target, attr}
 }
 
+// DeviceActionAttribute 设备动作权限对应的属性
+func DeviceActionAttribute(action string) string {
+	return fmt.Sprintf("action-%s", action)
+}
+
+// NewDeviceAction 执行设备动作的权限
+func NewDeviceAction(deviceID int, action string) Permission {
+	return NewDeviceManage(deviceID, action, DeviceActionAttribute(action))
+}
+
 func NewDeviceFwUpgrade(deviceID int) Permission {
 	return NewDeviceManage(deviceID, "固件升级", FwUpgrade)
 }
@@ -134,6 +150,7 @@ func init() {
 	DefaultPermission = append(DefaultPermission, RolePermission...)
 	DefaultPermission = append(DefaultPermission, ScenePermission...)
 	DefaultPermission = append(DefaultPermission, DepartmentPermission...)
+	DefaultPermission = append(DefaultPermission, PluginPermission...)
 	DefaultPermission = append(DefaultPermission, AreaUpdateMemberDepartment, AreaUpdateCompanyName)
 
 	ManagerPermission = append(ManagerPermission, DefaultPermission...)
@@ -0,0 +1,22 @@
+package status
+
+import "github.com/zhiting-tech/smartassistant/pkg/errors"
+
+// 与温控相关的响应状态码
+const (
+	ClimateParamErr = iota + 13000
+	ClimateNotThermostat
+	ClimateScheduleIncorrect
+	ClimateScheduleExist
+	ClimateScheduleNotExist
+	ClimateOverrideNotExist
+)
+
+func init() {
+	errors.NewCode(ClimateParamErr, "参数%s不正确")
+	errors.NewCode(ClimateNotThermostat, "设备不是温控器")
+	errors.NewCode(ClimateScheduleIncorrect, "温控计划不正确: %s")
+	errors.NewCode(ClimateScheduleExist, "该设备或房间已有温控计划")
+	errors.NewCode(ClimateScheduleNotExist, "温控计划不存在")
+	errors.NewCode(ClimateOverrideNotExist, "温控覆盖不存在")
+}
@@ -18,6 +18,10 @@ const (
 	DeviceLogoNotExist
 	AddDeviceFail
 	AttrNotFound
+	DeviceStatesExportParamErr
+	ActionNotFound
+	ActionInputsIncorrect
+	AttrValueInvalid
 )
 
 func init() {
@@ -35,4 +39,8 @@ func init() {
 	errors.NewCode(DeviceLogoNotExist, "设备图标不存在")
 	errors.NewCode(AddDeviceFail, "添加设备失败")
 	errors.NewCode(AttrNotFound, "属性不存在")
+	errors.NewCode(DeviceStatesExportParamErr, "导出参数%s不正确")
+	errors.NewCode(ActionNotFound, "动作不存在")
+	errors.NewCode(ActionInputsIncorrect, "动作参数不正确")
+	errors.NewCode(AttrValueInvalid, "属性值不正确: %s")
 }
@@ -0,0 +1,16 @@
+package status
+
+import "github.com/zhiting-tech/smartassistant/pkg/errors"
+
+// 与能耗相关的响应状态码
+const (
+	EnergyParamErr = iota + 12000
+	EnergyTariffIncorrect
+	EnergyRangeTooLarge
+)
+
+func init() {
+	errors.NewCode(EnergyParamErr, "参数%s不正确")
+	errors.NewCode(EnergyTariffIncorrect, "电价设置不正确: %s")
+	errors.NewCode(EnergyRangeTooLarge, "统计时间范围过大")
+}
@@ -0,0 +1,14 @@
+package status
+
+import "github.com/zhiting-tech/smartassistant/pkg/errors"
+
+// 与HomeKit桥接相关的响应状态码
+const (
+	HomeKitParamErr = iota + 14000
+	HomeKitStartErr
+)
+
+func init() {
+	errors.NewCode(HomeKitParamErr, "参数%s不正确")
+	errors.NewCode(HomeKitStartErr, "HomeKit桥接启动失败: %s")
+}
@@ -0,0 +1,12 @@
+package status
+
+import "github.com/zhiting-tech/smartassistant/pkg/errors"
+
+// 与MQTT桥接相关的响应状态码
+const (
+	MQTTParamErr = iota + 15000
+)
+
+func init() {
+	errors.NewCode(MQTTParamErr, "参数%s不正确")
+}
@@ -14,6 +14,16 @@ const (
 	WebsocketCommandNotFound
 	WebsocketDomainRequired
 	WebsocketEventRequired
+	PluginSettingsNotSupport
+	PluginSettingsIncorrect
+	PluginResourcesIncorrect
+	PluginUnsigned
+	PluginSignatureInvalid
+	PluginUpgradeRolledBack
+	PluginIncompatible
+	PluginCapabilityNotSupport
+	PluginLogsUnavailable
+	PluginLogLevelIncorrect
 )
 
 func init() {
@@ -27,4 +37,14 @@ func init() {
 	errors.NewCode(WebsocketCommandNotFound, "websocket 命令不存在")
 	errors.NewCode(WebsocketDomainRequired, "websocket 命令未指定 domain")
 	errors.NewCode(WebsocketEventRequired, "websocket 命令未指定 event")
+	errors.NewCode(PluginSettingsNotSupport, "插件不支持设置")
+	errors.NewCode(PluginSettingsIncorrect, "插件设置不正确: %s")
+	errors.NewCode(PluginResourcesIncorrect, "插件资源限制不正确: %s")
+	errors.NewCode(PluginUnsigned, "插件包未签名")
+	errors.NewCode(PluginSignatureInvalid, "插件包签名校验失败: %s")
+	errors.NewCode(PluginUpgradeRolledBack, "插件更新失败，已回滚到版本 %s")
+	errors.NewCode(PluginIncompatible, "插件与智汀家庭云版本不兼容: %s")
+	errors.NewCode(PluginCapabilityNotSupport, "插件不支持该功能: %s")
+	errors.NewCode(PluginLogsUnavailable, "获取插件日志失败")
+	errors.NewCode(PluginLogLevelIncorrect, "日志级别不正确: %s")
 }
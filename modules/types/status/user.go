@@ -38,6 +38,7 @@ const (
 	AreaTypeNotEqual
 	OldPasswordErr
 	PasswordChanged
+	UnitSystemIncorrect
 )
 
 func init() {
@@ -75,4 +76,5 @@ func init() {
 	errors.NewCode(ErrRefreshTokenExpired, "refresh token is expired")
 	errors.NewCode(GetCloudDiskTokenDeny, "不允许获取网盘凭证")
 	errors.NewCode(PasswordChanged, "密码已修改，请重新登录")
+	errors.NewCode(UnitSystemIncorrect, "不支持的单位制: %s")
 }
@@ -30,3 +30,12 @@ func IsValid(v string) bool {
 	_, err := version.NewSemver(v)
 	return err == nil
 }
+
+// Major 返回主版本号
+func Major(v string) (int, error) {
+	sv, err := version.NewSemver(v)
+	if err != nil {
+		return 0, err
+	}
+	return sv.Segments()[0], nil
+}
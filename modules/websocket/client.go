@@ -16,13 +16,17 @@ import (
 
 	"github.com/zhiting-tech/smartassistant/modules/entity"
 	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types"
 	status2 "github.com/zhiting-tech/smartassistant/modules/types/status"
 	"github.com/zhiting-tech/smartassistant/modules/utils/session"
 	"github.com/zhiting-tech/smartassistant/pkg/errors"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
 type client struct {
+	ctx    context.Context // 连接关闭时结束
+	cancel context.CancelFunc
 	key    string
 	areaID uint64
 	conn   *ws.Conn
@@ -31,6 +35,7 @@ type client struct {
 	ginCtx *gin.Context
 
 	subscribers []Subscriber
+	unitSystem  thingmodel.UnitSystem // 用户选择的单位制，推送属性变化时换算
 
 	sync.Mutex
 	closed bool
@@ -42,6 +47,11 @@ func (cli *client) SubscribeTopic(topic string, id int64) {
 		logger.Debugf("func topic: %s", topic)
 		m := msg.(*Message)
 		m.ID = id
+		if ace, ok := m.Data.(AttrChangeEvent); ok {
+			cm := *m
+			cm.Data = ace.inSystem(cli.unitSystem)
+			m = &cm
+		}
 		cli.SendMsg(m)
 		return nil
 	}
@@ -87,6 +97,10 @@ func (cli *client) handleWsMessage(data []byte, user *session.User) (err error)
 	if req.Service == serviceSubscribeEvent {
 		return cli.handleSubscribeEvent(req)
 	}
+	// 订阅插件日志
+	if req.Service == servicePluginLogs {
+		return cli.handlePluginLogs(req)
+	}
 	// 发现插件设备
 	if req.Service == serviceDiscover { // 写死的发现命令，优先级最高，忽略 domain，发送给所有插件
 		return cli.discover(req, user)
@@ -169,6 +183,62 @@ func (cli *client) handleSubscribeEvent(req Request) (err error) {
 	return
 }
 
+type pluginLogsReq struct {
+	Since  int64  `json:"since"`
+	Tail   int    `json:"tail"`
+	Follow bool   `json:"follow"`
+	Level  string `json:"level"`
+}
+
+// handlePluginLogs 以同一个请求ID持续返回插件日志，直到连接关闭或没有新的日志
+func (cli *client) handlePluginLogs(req Request) (err error) {
+	defer func() {
+		if err != nil {
+			resp := NewResponse(req.ID)
+			s := status.Convert(err)
+			resp.Error.Code = s.Code()
+			resp.Error.Message = s.Message()
+			cli.SendMsg(resp)
+		}
+	}()
+	if !entity.JudgePermit(req.User.UserID, types.PluginLogGet) {
+		return errors.New(status2.Deny)
+	}
+	if req.Domain == "" {
+		return errors.New(status2.WebsocketDomainRequired)
+	}
+	var data pluginLogsReq
+	if req.Data != nil {
+		json.Unmarshal(req.Data, &data)
+	}
+	if data.Level != "" && !plugin.ValidLogLevel(data.Level) {
+		return errors.Newf(status2.PluginLogLevelIncorrect, data.Level)
+	}
+	pi, err := entity.GetPlugin(req.Domain, req.User.AreaID)
+	if err != nil {
+		return
+	}
+	opts := plugin.LogOptions{
+		Tail:   plugin.LimitLogTail(data.Tail),
+		Follow: data.Follow,
+		Level:  data.Level,
+	}
+	if data.Since != 0 {
+		opts.Since = time.Unix(data.Since, 0)
+	}
+	lines, err := plugin.Logs(cli.ctx, plugin.NewFromEntity(pi), opts)
+	if err != nil {
+		return errors.Wrap(err, status2.PluginLogsUnavailable)
+	}
+	for l := range lines {
+		resp := NewResponse(req.ID)
+		resp.Success = true
+		resp.Data = l
+		cli.SendMsg(resp)
+	}
+	return
+}
+
 func (cli *client) handleCallService(req Request) (resp *Message) {
 
 	var err error
@@ -202,6 +272,7 @@ func (cli *client) handleCallService(req Request) (resp *Message) {
 func (cli *client) Close() error {
 	cli.Lock()
 	defer cli.Unlock()
+	cli.cancel()
 	close(cli.send)
 	for _, s := range cli.subscribers {
 		s.Unsubscribe()
@@ -6,6 +6,8 @@ import (
 	errors2 "errors"
 	"sort"
 
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
 	"gorm.io/gorm"
 
 	"github.com/zhiting-tech/smartassistant/modules/api/utils/oauth"
@@ -104,7 +106,8 @@ func SetAttrs(req Request) (result interface{}, err error) {
 		return
 	}
 	// 判断控制权限
-	for _, attr := range sr.Attributes {
+	var fieldErrs thingmodel.FieldErrors
+	for i, attr := range sr.Attributes {
 		var d entity.Device
 		d, err = entity.GetPluginDevice(req.User.AreaID, req.Domain, attr.IID)
 		tm, _ := d.GetThingModel()
@@ -124,6 +127,20 @@ func SetAttrs(req Request) (result interface{}, err error) {
 			}
 			err = nil
 		}
+		// 值使用用户选择的单位制，换算为设备的单位后按物模型校验
+		attr.Val = tm.ValFromSystem(attr.IID, attr.AID, attr.Val, up.UnitSystem())
+		var attrs []sdk.SetAttribute
+		attrs, err = device.ValidateAttributes(tm, []sdk.SetAttribute{attr})
+		if err != nil {
+			fieldErrs = append(fieldErrs, device.FieldErrors(err)...)
+			continue
+		}
+		sr.Attributes[i] = attrs[0]
+	}
+	if len(fieldErrs) != 0 {
+		result = device.ValidateErrors{Errors: fieldErrs}
+		err = errors.Wrapf(fieldErrs, status.AttrValueInvalid, fieldErrs.Error())
+		return
 	}
 	// 发送控制命令
 	err = plugin.SetAttributes(context.Background(), req.Domain, req.User.AreaID, sr)
@@ -133,6 +150,170 @@ func SetAttrs(req Request) (result interface{}, err error) {
 	return
 }
 
+type invokeActionReq struct {
+	DeviceHandleParams
+	Action string                 `json:"action"`
+	Inputs map[string]interface{} `json:"inputs"`
+}
+
+type invokeActionResp struct {
+	Outputs map[string]interface{} `json:"outputs"`
+}
+
+// InvokeAction 执行设备的动作
+func InvokeAction(req Request) (result interface{}, err error) {
+	var p invokeActionReq
+	json.Unmarshal(req.Data, &p)
+	d, err := entity.GetPluginDevice(req.User.AreaID, req.Domain, p.IID)
+	if err != nil {
+		return
+	}
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return
+	}
+	action, err := tm.GetAction(p.IID, p.Action)
+	if err != nil {
+		err = errors.New(status.ActionNotFound)
+		return
+	}
+	up, err := entity.GetUserPermissions(req.User.UserID)
+	if err != nil {
+		return
+	}
+	if !up.IsPermit(types.NewDeviceAction(d.ID, action.Type)) {
+		err = errors.New(status.Deny)
+		return
+	}
+	inputs, err := action.ValidateInputs(p.Inputs)
+	if err != nil {
+		err = errors.Wrap(err, status.ActionInputsIncorrect)
+		return
+	}
+
+	actionReq := sdk.ActionRequest{
+		IID:    p.IID,
+		Action: p.Action,
+		Inputs: inputs,
+	}
+	var resp invokeActionResp
+	if resp.Outputs, err = plugin.InvokeAction(context.Background(), req.Domain, actionReq); err != nil {
+		return
+	}
+	return resp, nil
+}
+
+type refreshAttrsReq struct {
+	DeviceHandleParams
+	AIDs []int `json:"aids"` // 需要刷新的属性，为空时刷新实例的所有可读属性
+}
+
+type refreshAttrsResp struct {
+	Attributes []sdk.SetAttribute `json:"attributes"`
+}
+
+// RefreshAttrs 主动从设备读取属性的最新值（下拉刷新）
+func RefreshAttrs(req Request) (result interface{}, err error) {
+	var p refreshAttrsReq
+	json.Unmarshal(req.Data, &p)
+	d, err := entity.GetPluginDevice(req.User.AreaID, req.Domain, p.IID)
+	if err != nil {
+		return
+	}
+	tm, err := d.GetThingModel()
+	if err != nil {
+		return
+	}
+	ins, err := tm.GetInstance(p.IID)
+	if err != nil {
+		err = errors.New(status.AttrNotFound)
+		return
+	}
+	// 刷新会让设备主动读取，需要设备的控制权限
+	up, err := entity.GetUserPermissions(req.User.UserID)
+	if err != nil {
+		return
+	}
+	if !up.IsDeviceControlPermit(d.ID) {
+		err = errors.New(status.Deny)
+		return
+	}
+
+	var getReq sdk.GetRequest
+	if len(p.AIDs) == 0 {
+		for _, srv := range ins.Services {
+			for _, attr := range srv.Attributes {
+				if attr.PermissionRead() || attr.PermissionNotify() {
+					getReq.Attributes = append(getReq.Attributes, sdk.GetAttribute{IID: p.IID, AID: attr.AID})
+				}
+			}
+		}
+	}
+	for _, aid := range p.AIDs {
+		var attr thingmodel.Attribute
+		if attr, err = ins.GetAttribute(aid); err != nil {
+			err = errors.New(status.AttrNotFound)
+			return
+		}
+		if !attr.PermissionRead() && !attr.PermissionNotify() {
+			err = errors.New(status.Deny)
+			return
+		}
+		getReq.Attributes = append(getReq.Attributes, sdk.GetAttribute{IID: p.IID, AID: aid})
+	}
+
+	var resp refreshAttrsResp
+	resp.Attributes, err = plugin.GetAttributes(context.Background(), req.Domain, getReq)
+	if grpcStatus.Code(err) == codes.Unimplemented {
+		// 旧版本sdk的插件不支持主动读取，返回设备影子中的缓存值
+		resp.Attributes, err = shadowAttributes(d, getReq.Attributes)
+	}
+	if err != nil {
+		return
+	}
+	return resp, nil
+}
+
+func shadowAttributes(d entity.Device, attrs []sdk.GetAttribute) (result []sdk.SetAttribute, err error) {
+	shadow, err := d.GetShadow()
+	if err != nil {
+		return
+	}
+	for _, attr := range attrs {
+		var val interface{}
+		if val, err = shadow.Get(attr.IID, attr.AID); err != nil {
+			return
+		}
+		result = append(result, sdk.SetAttribute{IID: attr.IID, AID: attr.AID, Val: val})
+	}
+	return
+}
+
+type batchSetAttrsReq struct {
+	Devices []device.BatchDevice `json:"devices"`
+}
+
+type batchSetAttrsResp struct {
+	Results []device.BatchResult `json:"results"`
+}
+
+// BatchSetAttrs 批量设置多个设备的属性，不需要指定domain
+func BatchSetAttrs(req Request) (result interface{}, err error) {
+	var p batchSetAttrsReq
+	if err = json.Unmarshal(req.Data, &p); err != nil {
+		err = errors.Wrap(err, errors.BadRequest)
+		return
+	}
+	up, err := entity.GetUserPermissions(req.User.UserID)
+	if err != nil {
+		return
+	}
+	result = batchSetAttrsResp{
+		Results: device.BatchSetAttributes(context.Background(), req.User.AreaID, up, p.Devices),
+	}
+	return
+}
+
 type connectDeviceResp struct {
 	thingmodel.ThingModel
 	Device Device `json:"device"`
@@ -474,6 +655,12 @@ func DeviceStates(req Request) (result interface{}, err error) {
 		// deviceStatesReq.Size = 20
 	}
 
+	// 记录的值按用户选择的单位制换算
+	system := thingmodel.UnitSystemMetric
+	if u, uErr := entity.GetUserByID(req.User.UserID); uErr == nil {
+		system = u.GetUnitSystem()
+	}
+
 	var resp DeviceStatesResp
 	resp.States = make([]State, 0)
 	states, err := entity.GetDeviceStates(d.ID, deviceStatesReq.AttrType, deviceStatesReq.Size,
@@ -481,6 +668,7 @@ func DeviceStates(req Request) (result interface{}, err error) {
 	for _, state := range states {
 		var s State
 		json.Unmarshal(state.State, &s)
+		s.Attribute = s.Attribute.InSystem(system)
 		s.Timestamp = state.CreatedAt.Unix()
 		s.ID = state.ID
 		resp.States = append(resp.States, s)
@@ -612,12 +800,15 @@ func SubDevices(req Request) (result interface{}, err error) {
 }
 
 func RegisterCmd() {
-	RegisterCallFunc(ServiceOTA, OTA)                     // OTA
-	RegisterCallFunc(ServiceConnect, ConnectDevice)       // 添加/连接设备
-	RegisterCallFunc(ServiceSetAttributes, SetAttrs)      // 设置属性
-	RegisterCallFunc(ServiceCheckUpdate, CheckUpdate)     // 检查固件更新
-	RegisterCallFunc(ServiceGetInstances, GetInstances)   // 获取物模型
-	RegisterCallFunc(ServiceDisconnect, DisconnectDevice) // 删除设备/断开连接
+	RegisterCallFunc(ServiceOTA, OTA)                          // OTA
+	RegisterCallFunc(ServiceConnect, ConnectDevice)            // 添加/连接设备
+	RegisterCallFunc(ServiceSetAttributes, SetAttrs)           // 设置属性
+	RegisterCallFunc(ServiceBatchSetAttributes, BatchSetAttrs) // 批量设置属性
+	RegisterCallFunc(ServiceRefreshAttributes, RefreshAttrs)   // 主动刷新属性
+	RegisterCallFunc(ServiceInvokeAction, InvokeAction)        // 执行设备动作
+	RegisterCallFunc(ServiceCheckUpdate, CheckUpdate)          // 检查固件更新
+	RegisterCallFunc(ServiceGetInstances, GetInstances)        // 获取物模型
+	RegisterCallFunc(ServiceDisconnect, DisconnectDevice)      // 删除设备/断开连接
 
 	RegisterCallFunc(ServiceSubDevices, SubDevices)     // 子设备列表
 	RegisterCallFunc(ServiceListGateways, ListGateways) // 列出网关列表
@@ -0,0 +1,141 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	grpcStatus "google.golang.org/grpc/status"
+
+	"github.com/zhiting-tech/smartassistant/modules/config"
+	"github.com/zhiting-tech/smartassistant/modules/entity"
+	"github.com/zhiting-tech/smartassistant/modules/plugin"
+	"github.com/zhiting-tech/smartassistant/modules/types"
+	"github.com/zhiting-tech/smartassistant/modules/types/status"
+	"github.com/zhiting-tech/smartassistant/modules/utils/session"
+	"github.com/zhiting-tech/smartassistant/pkg/errors"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// fakeClient 模拟插件，legacy插件不支持主动读取
+type fakeClient struct {
+	plugin.Client
+	gets []sdk.GetRequest
+}
+
+func (c *fakeClient) GetAttributes(ctx context.Context, pluginID string, getReq sdk.GetRequest) ([]sdk.SetAttribute, error) {
+	if pluginID == "legacy" {
+		return nil, grpcStatus.Error(codes.Unimplemented, "unimplemented")
+	}
+	c.gets = append(c.gets, getReq)
+	var attrs []sdk.SetAttribute
+	for _, attr := range getReq.Attributes {
+		attrs = append(attrs, sdk.SetAttribute{IID: attr.IID, AID: attr.AID, Val: "on"})
+	}
+	return attrs, nil
+}
+
+var testClient = &fakeClient{}
+
+func TestMain(m *testing.M) {
+	config.TestSetup()
+	plugin.SetGlobalClient(testClient)
+	code := m.Run()
+	config.TestTeardown()
+	os.Exit(code)
+}
+
+// createDevice 创建包含开关（aid 1）和只写属性（aid 2）的设备
+func createDevice(t *testing.T, areaID uint64, pluginID, iid string) entity.Device {
+	onOff := thingmodel.OnOff
+	onOff.AID = 1
+	identify := thingmodel.Attribute{AID: 2, Type: "identify", ValType: thingmodel.Bool,
+		Permission: thingmodel.SetPermissions(thingmodel.AttributePermissionWrite)}
+	tm := thingmodel.ThingModel{Instances: []thingmodel.Instance{{
+		IID:      iid,
+		Services: []thingmodel.Service{{Type: thingmodel.SwitchService, Attributes: []thingmodel.Attribute{onOff, identify}}},
+	}}}
+	shadow := entity.NewShadow()
+	shadow.UpdateReported(iid, 1, "off")
+	d := entity.Device{Name: iid, PluginID: pluginID, IID: iid, AreaID: areaID}
+	d.ThingModel, _ = json.Marshal(tm)
+	d.Shadow, _ = json.Marshal(shadow)
+	require.NoError(t, entity.CreateDevice(&d, entity.GetDB()))
+	return d
+}
+
+// createUser 创建家庭的成员，permit为true时拥有设备开关的控制权限
+func createUser(t *testing.T, areaID uint64, deviceID int, permit bool) *session.User {
+	u := entity.User{AccountName: "ws" + strconv.Itoa(deviceID) + strconv.FormatBool(permit), AreaID: areaID}
+	require.NoError(t, entity.CreateUser(&u, entity.GetDB()))
+	role, err := entity.AddRole("member"+strconv.FormatBool(permit), areaID)
+	require.NoError(t, err)
+	if permit {
+		require.NoError(t, role.AddPermissionForRole("开关", types.ActionControl, types.DeviceTarget(deviceID), "1"))
+	}
+	require.NoError(t, entity.CreateUserRole([]entity.UserRole{{UserID: u.ID, RoleID: role.ID}}))
+	return &session.User{UserID: u.ID, AreaID: areaID}
+}
+
+func refreshRequest(user *session.User, pluginID, iid string, aids ...int) Request {
+	data, _ := json.Marshal(refreshAttrsReq{DeviceHandleParams: DeviceHandleParams{IID: iid}, AIDs: aids})
+	return Request{Domain: pluginID, Data: data, User: user}
+}
+
+func assertStatus(t *testing.T, code int, err error) {
+	if assert.Error(t, err) {
+		if e, ok := err.(errors.Error); assert.True(t, ok, err.Error()) {
+			assert.Equal(t, code, e.Code.Status)
+		}
+	}
+}
+
+func TestRefreshAttrs(t *testing.T) {
+	area, err := entity.CreateArea("refresh", entity.AreaOfHome)
+	require.NoError(t, err)
+	d := createDevice(t, area.ID, "demo", "refresh-switch")
+	member := createUser(t, area.ID, d.ID, true)
+
+	// 默认刷新所有可读属性，不包含只写属性
+	result, err := RefreshAttrs(refreshRequest(member, "demo", d.IID))
+	require.NoError(t, err)
+	assert.Equal(t, []sdk.SetAttribute{{IID: d.IID, AID: 1, Val: "on"}}, result.(refreshAttrsResp).Attributes)
+
+	_, err = RefreshAttrs(refreshRequest(member, "demo", d.IID, 2))
+	assertStatus(t, status.Deny, err)
+	_, err = RefreshAttrs(refreshRequest(member, "demo", d.IID, 9))
+	assertStatus(t, status.AttrNotFound, err)
+
+	// 不支持主动读取的插件返回设备影子中的值
+	legacy := createDevice(t, area.ID, "legacy", "refresh-legacy")
+	user := createUser(t, area.ID, legacy.ID, true)
+	result, err = RefreshAttrs(refreshRequest(user, "legacy", legacy.IID, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []sdk.SetAttribute{{IID: legacy.IID, AID: 1, Val: "off"}}, result.(refreshAttrsResp).Attributes)
+}
+
+func TestRefreshAttrsPermission(t *testing.T) {
+	area, err := entity.CreateArea("refresh", entity.AreaOfHome)
+	require.NoError(t, err)
+	d := createDevice(t, area.ID, "demo", "deny-switch")
+	member := createUser(t, area.ID, d.ID, false)
+
+	// 没有控制权限的成员不能让设备主动读取
+	gets := len(testClient.gets)
+	_, err = RefreshAttrs(refreshRequest(member, "demo", d.IID))
+	assertStatus(t, status.Deny, err)
+	assert.Len(t, testClient.gets, gets)
+
+	// 其他家庭的设备不存在
+	other, err := entity.CreateArea("other", entity.AreaOfHome)
+	require.NoError(t, err)
+	_, err = RefreshAttrs(refreshRequest(&session.User{UserID: member.UserID, AreaID: other.ID}, "demo", d.IID))
+	assert.Error(t, err)
+	assert.Len(t, testClient.gets, gets)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/zhiting-tech/smartassistant/pkg/event"
 	"github.com/zhiting-tech/smartassistant/pkg/logger"
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
 func NewWebSocketServer() *Server {
@@ -47,7 +48,10 @@ func (s *Server) AcceptWebSocket(c *gin.Context) {
 	)
 	user := session.Get(c)
 	logger.Debugf("start websocket serve \"%s\" with \"%s\"", lAddr, rAddr)
+	ctx, cancel := context.WithCancel(context.Background())
 	cli := &client{
+		ctx:    ctx,
+		cancel: cancel,
 		key:    uuid.New().String(),
 		areaID: user.AreaID,
 		conn:   conn,
@@ -55,6 +59,9 @@ func (s *Server) AcceptWebSocket(c *gin.Context) {
 		send:   make(chan []byte, 4),
 		ginCtx: c,
 	}
+	if u, err := entity.GetUserByID(user.UserID); err == nil {
+		cli.unitSystem = u.GetUnitSystem()
+	}
 
 	s.bucket.register <- cli
 	logger.Debug("new client Key：", cli.key)
@@ -73,6 +80,24 @@ func (s *Server) Run(ctx context.Context) {
 type AttrChangeEvent struct {
 	PluginID string                 `json:"plugin_id"`
 	Attr     definer.AttributeEvent `json:"attr"`
+	Unit     thingmodel.Unit        `json:"unit,omitempty"`
+
+	attr thingmodel.Attribute // 物模型中的属性定义，用于换算单位
+}
+
+// inSystem 按单位制换算属性值
+func (e AttrChangeEvent) inSystem(s thingmodel.UnitSystem) AttrChangeEvent {
+	attr := e.attr
+	attr.Val = e.Attr.Val
+	attr = attr.InSystem(s)
+	e.Attr.Val = attr.Val
+	e.Unit = attr.Unit
+	return e
+}
+
+type DeviceEventMsg struct {
+	PluginID string              `json:"plugin_id"`
+	Event    definer.DeviceEvent `json:"event"`
 }
 
 type DeviceIncreaseEvent struct {
@@ -90,6 +115,9 @@ func (s *Server) MulticastMsg(em event.EventMessage) error {
 		pluginID := em.Param["plugin_id"]
 		iid := em.Param["iid"]
 		topic = fmt.Sprintf("%d/%s/%s/%s", areaID, em.EventType, pluginID, iid)
+	case event.PluginHealth:
+		ev.Data = em.Param
+		topic = fmt.Sprintf("%d/%s/%s", areaID, em.EventType, em.Param["plugin_id"])
 	case event.DeviceDecrease:
 	case event.DeviceIncrease:
 		ev.Data = em.Param
@@ -113,10 +141,32 @@ func (s *Server) MulticastMsg(em event.EventMessage) error {
 			return nil
 		}
 
-		ev.Data = AttrChangeEvent{
+		ace := AttrChangeEvent{
 			PluginID: d.PluginID,
 			Attr:     *attr,
 		}
+		if tm, err := d.GetThingModel(); err == nil {
+			ace.attr, _ = tm.GetAttribute(attr.IID, attr.AID)
+			ace.Unit = ace.attr.Unit
+		}
+		ev.Data = ace
+		topic = fmt.Sprintf("%d/%s/%s/%s", areaID, em.EventType, d.PluginID, d.IID)
+	case event.DeviceEvent:
+		d, err := entity.GetDeviceByID(em.GetDeviceID())
+		if err != nil {
+			logger.Error(err)
+			return err
+		}
+		deviceEvent := em.GetDeviceEvent()
+		if deviceEvent == nil {
+			logger.Warn("device event is nil")
+			return nil
+		}
+
+		ev.Data = DeviceEventMsg{
+			PluginID: d.PluginID,
+			Event:    *deviceEvent,
+		}
 		topic = fmt.Sprintf("%d/%s/%s/%s", areaID, em.EventType, d.PluginID, d.IID)
 	}
 
@@ -20,10 +20,19 @@ const (
 	// serviceSubscribeEvent 订阅事件
 	serviceSubscribeEvent ServiceType = "subscribe_event"
 
+	// servicePluginLogs 订阅插件日志，domain为插件ID
+	servicePluginLogs ServiceType = "plugin_logs"
+
 	// ServiceGetInstances 获取设备物模型
 	ServiceGetInstances ServiceType = "get_instances"
 	// ServiceSetAttributes 设置设备属性
 	ServiceSetAttributes ServiceType = "set_attributes"
+	// ServiceBatchSetAttributes 批量设置多个设备的属性（可跨插件）
+	ServiceBatchSetAttributes ServiceType = "batch_set_attributes"
+	// ServiceRefreshAttributes 主动从设备读取属性的最新值
+	ServiceRefreshAttributes ServiceType = "refresh_attributes"
+	// ServiceInvokeAction 执行设备的动作
+	ServiceInvokeAction ServiceType = "invoke_action"
 	// ServiceConnect 连接（认证、配对）
 	ServiceConnect ServiceType = "connect"
 	// ServiceDisconnect 断开连接（取消配对）
@@ -105,3 +105,17 @@ func extractZipFile(file *zip.File, dst string) (err error) {
 	}
 	return
 }
+
+// ZipStream 将 fn 写入的内容作为名为 name 的单个文件压缩后直接写入 w，不落地临时文件
+func ZipStream(w io.Writer, name string, fn func(w io.Writer) error) (err error) {
+	zw := zip.NewWriter(w)
+	fw, err := zw.Create(name)
+	if err != nil {
+		return
+	}
+	if err = fn(fw); err != nil {
+		zw.Close()
+		return
+	}
+	return zw.Close()
+}
@@ -1,6 +1,9 @@
 package archive
 
 import (
+	"archive/zip"
+	"bytes"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
@@ -57,3 +60,24 @@ func TestZip(t *testing.T) {
 	})
 
 }
+
+func TestZipStream(t *testing.T) {
+	var buf bytes.Buffer
+	err := ZipStream(&buf, "states.csv", func(w io.Writer) error {
+		_, err := w.Write([]byte("id,val\n1,true\n"))
+		return err
+	})
+	assert.NoError(t, err)
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(r.File))
+	assert.Equal(t, "states.csv", r.File[0].Name)
+
+	f, err := r.File[0].Open()
+	assert.NoError(t, err)
+	defer f.Close()
+	data, err := ioutil.ReadAll(f)
+	assert.NoError(t, err)
+	assert.Equal(t, "id,val\n1,true\n", string(data))
+}
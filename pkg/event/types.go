@@ -2,6 +2,7 @@ package event
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
 )
@@ -14,6 +15,8 @@ const (
 	DeviceDecrease   EventType = "device_decrease"
 	ThingModelChange EventType = "thing_model_change"
 	OnlineStatus     EventType = "online_status"
+	DeviceEvent      EventType = "device_event"  // 设备的无状态事件，如按键按下、报警
+	PluginHealth     EventType = "plugin_health" // 插件健康状态变化
 )
 
 type EventMessage struct {
@@ -44,6 +47,19 @@ func (e *EventMessage) GetDeviceID() int {
 	return 0
 }
 
+// SetTime 记录设备上报的时间，事件处理函数并发执行，需按上报时间判断先后
+func (e *EventMessage) SetTime(t time.Time) {
+	e.Param["time"] = t
+}
+
+// GetTime 获取设备上报的时间，未记录时返回当前时间
+func (e *EventMessage) GetTime() time.Time {
+	if v, ok := e.Param["time"].(time.Time); ok {
+		return v
+	}
+	return time.Now()
+}
+
 func (e *EventMessage) SetAttr(attr definer.AttributeEvent) {
 	e.Param["attr"] = attr
 }
@@ -57,3 +73,17 @@ func (e *EventMessage) GetAttr() *definer.AttributeEvent {
 	}
 	return nil
 }
+
+func (e *EventMessage) SetDeviceEvent(ev definer.DeviceEvent) {
+	e.Param["event"] = ev
+}
+
+func (e *EventMessage) GetDeviceEvent() *definer.DeviceEvent {
+	if v, ok := e.Param["event"]; ok {
+		var ev definer.DeviceEvent
+		bytes, _ := json.Marshal(v)
+		json.Unmarshal(bytes, &ev)
+		return &ev
+	}
+	return nil
+}
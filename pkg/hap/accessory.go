@@ -0,0 +1,355 @@
+package hap
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+)
+
+// 服务的类型，使用苹果定义的UUID的简写形式
+const (
+	ServiceAccessoryInformation = "3E"
+	ServiceProtocolInformation  = "A2"
+	ServiceLightbulb            = "43"
+	ServiceSwitch               = "49"
+	ServiceOutlet               = "47"
+	ServiceTemperatureSensor    = "8A"
+	ServiceHumiditySensor       = "82"
+	ServiceMotionSensor         = "85"
+	ServiceLeakSensor           = "83"
+	ServiceContactSensor        = "80"
+	ServiceLightSensor          = "84"
+	ServiceLockMechanism        = "45"
+	ServiceWindowCovering       = "8C"
+	ServiceThermostat           = "4A"
+	ServiceBattery              = "96"
+)
+
+// 特征值的类型
+const (
+	CharIdentify                   = "14"
+	CharManufacturer               = "20"
+	CharModel                      = "21"
+	CharName                       = "23"
+	CharSerialNumber               = "30"
+	CharFirmwareRevision           = "52"
+	CharVersion                    = "37"
+	CharOn                         = "25"
+	CharBrightness                 = "8"
+	CharColorTemperature           = "CE"
+	CharOutletInUse                = "26"
+	CharCurrentTemperature         = "11"
+	CharCurrentRelativeHumidity    = "10"
+	CharMotionDetected             = "22"
+	CharLeakDetected               = "70"
+	CharContactSensorState         = "6A"
+	CharCurrentAmbientLightLevel   = "6B"
+	CharLockCurrentState           = "1D"
+	CharLockTargetState            = "1E"
+	CharCurrentPosition            = "6D"
+	CharTargetPosition             = "7C"
+	CharPositionState              = "72"
+	CharCurrentHeatingCoolingState = "0F"
+	CharTargetHeatingCoolingState  = "33"
+	CharTargetTemperature          = "35"
+	CharTemperatureDisplayUnits    = "36"
+	CharBatteryLevel               = "68"
+	CharChargingState              = "8F"
+	CharStatusLowBattery           = "79"
+)
+
+// 特征值的格式
+const (
+	FormatBool   = "bool"
+	FormatUInt8  = "uint8"
+	FormatUInt16 = "uint16"
+	FormatUInt32 = "uint32"
+	FormatInt    = "int"
+	FormatFloat  = "float"
+	FormatString = "string"
+)
+
+// 特征值的权限
+const (
+	PermRead   = "pr"
+	PermWrite  = "pw"
+	PermEvents = "ev"
+)
+
+// 特征值的单位
+const (
+	UnitCelsius    = "celsius"
+	UnitPercentage = "percentage"
+	UnitLux        = "lux"
+)
+
+var (
+	permR   = []string{PermRead}
+	permW   = []string{PermWrite}
+	permRE  = []string{PermRead, PermEvents}
+	permRWE = []string{PermRead, PermWrite, PermEvents}
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+// characteristics 特征值的定义，值为默认值
+var characteristics = map[string]Characteristic{
+	CharIdentify:                   {Format: FormatBool, Perms: permW},
+	CharManufacturer:               {Format: FormatString, Perms: permR, Value: ""},
+	CharModel:                      {Format: FormatString, Perms: permR, Value: ""},
+	CharName:                       {Format: FormatString, Perms: permR, Value: ""},
+	CharSerialNumber:               {Format: FormatString, Perms: permR, Value: ""},
+	CharFirmwareRevision:           {Format: FormatString, Perms: permR, Value: ""},
+	CharVersion:                    {Format: FormatString, Perms: permR, Value: "1.1.0"},
+	CharOn:                         {Format: FormatBool, Perms: permRWE, Value: false},
+	CharBrightness:                 {Format: FormatInt, Perms: permRWE, Value: 0, Unit: UnitPercentage, MinValue: float(0), MaxValue: float(100), MinStep: float(1)},
+	CharColorTemperature:           {Format: FormatUInt32, Perms: permRWE, Value: 140, MinValue: float(140), MaxValue: float(500), MinStep: float(1)},
+	CharOutletInUse:                {Format: FormatBool, Perms: permRE, Value: false},
+	CharCurrentTemperature:         {Format: FormatFloat, Perms: permRE, Value: 0.0, Unit: UnitCelsius, MinValue: float(-270), MaxValue: float(100), MinStep: float(0.1)},
+	CharCurrentRelativeHumidity:    {Format: FormatFloat, Perms: permRE, Value: 0.0, Unit: UnitPercentage, MinValue: float(0), MaxValue: float(100), MinStep: float(1)},
+	CharMotionDetected:             {Format: FormatBool, Perms: permRE, Value: false},
+	CharLeakDetected:               {Format: FormatUInt8, Perms: permRE, Value: 0, MinValue: float(0), MaxValue: float(1), MinStep: float(1)},
+	CharContactSensorState:         {Format: FormatUInt8, Perms: permRE, Value: 0, MinValue: float(0), MaxValue: float(1), MinStep: float(1)},
+	CharCurrentAmbientLightLevel:   {Format: FormatFloat, Perms: permRE, Value: 0.0001, Unit: UnitLux, MinValue: float(0.0001), MaxValue: float(100000)},
+	CharLockCurrentState:           {Format: FormatUInt8, Perms: permRE, Value: 3, MinValue: float(0), MaxValue: float(3), MinStep: float(1)},
+	CharLockTargetState:            {Format: FormatUInt8, Perms: permRWE, Value: 1, MinValue: float(0), MaxValue: float(1), MinStep: float(1)},
+	CharCurrentPosition:            {Format: FormatUInt8, Perms: permRE, Value: 0, Unit: UnitPercentage, MinValue: float(0), MaxValue: float(100), MinStep: float(1)},
+	CharTargetPosition:             {Format: FormatUInt8, Perms: permRWE, Value: 0, Unit: UnitPercentage, MinValue: float(0), MaxValue: float(100), MinStep: float(1)},
+	CharPositionState:              {Format: FormatUInt8, Perms: permRE, Value: 2, MinValue: float(0), MaxValue: float(2), MinStep: float(1)},
+	CharCurrentHeatingCoolingState: {Format: FormatUInt8, Perms: permRE, Value: 0, MinValue: float(0), MaxValue: float(2), MinStep: float(1)},
+	CharTargetHeatingCoolingState:  {Format: FormatUInt8, Perms: permRWE, Value: 0, MinValue: float(0), MaxValue: float(3), MinStep: float(1)},
+	CharTargetTemperature:          {Format: FormatFloat, Perms: permRWE, Value: 10.0, Unit: UnitCelsius, MinValue: float(10), MaxValue: float(38), MinStep: float(0.1)},
+	CharTemperatureDisplayUnits:    {Format: FormatUInt8, Perms: permRWE, Value: 0, MinValue: float(0), MaxValue: float(1), MinStep: float(1)},
+	CharBatteryLevel:               {Format: FormatUInt8, Perms: permRE, Value: 0, Unit: UnitPercentage, MinValue: float(0), MaxValue: float(100), MinStep: float(1)},
+	CharChargingState:              {Format: FormatUInt8, Perms: permRE, Value: 0, MinValue: float(0), MaxValue: float(2), MinStep: float(1)},
+	CharStatusLowBattery:           {Format: FormatUInt8, Perms: permRE, Value: 0, MinValue: float(0), MaxValue: float(1), MinStep: float(1)},
+}
+
+// Characteristic 特征值
+type Characteristic struct {
+	IID      uint64      `json:"iid"`
+	Type     string      `json:"type"`
+	Perms    []string    `json:"perms"`
+	Format   string      `json:"format"`
+	Value    interface{} `json:"value"`
+	Unit     string      `json:"unit,omitempty"`
+	MinValue *float64    `json:"minValue,omitempty"`
+	MaxValue *float64    `json:"maxValue,omitempty"`
+	MinStep  *float64    `json:"minStep,omitempty"`
+
+	// OnWrite 控制器写入时调用，值已按格式及范围转换，返回错误则写入失败
+	OnWrite func(val interface{}) error `json:"-"`
+}
+
+// NewCharacteristic 根据类型创建特征值，类型未定义时返回nil
+func NewCharacteristic(typ string) *Characteristic {
+	c, ok := characteristics[typ]
+	if !ok {
+		return nil
+	}
+	c.Type = typ
+	c.Perms = append([]string(nil), c.Perms...)
+	return &c
+}
+
+// SetRange 修改特征值的取值范围
+func (c *Characteristic) SetRange(min, max, step float64) *Characteristic {
+	c.MinValue, c.MaxValue, c.MinStep = float(min), float(max), float(step)
+	return c
+}
+
+// Clamp 将数值限制在特征值的取值范围内
+func (c *Characteristic) Clamp(val float64) float64 {
+	if c.MinValue != nil && val < *c.MinValue {
+		val = *c.MinValue
+	}
+	if c.MaxValue != nil && val > *c.MaxValue {
+		val = *c.MaxValue
+	}
+	return val
+}
+
+func (c *Characteristic) hasPerm(perm string) bool {
+	for _, p := range c.Perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Characteristic) MarshalJSON() ([]byte, error) {
+	type characteristic Characteristic
+	cc := characteristic(*c)
+	// 只写的特征值的值为null
+	if !c.hasPerm(PermRead) {
+		cc.Value = nil
+	}
+	return json.Marshal(cc)
+}
+
+// Normalize 按格式及范围转换值，值不合法时返回false
+func (c *Characteristic) Normalize(val interface{}) (interface{}, bool) {
+	if c.Format == FormatString {
+		s, ok := val.(string)
+		return s, ok
+	}
+
+	var f float64
+	switch v := val.(type) {
+	case bool:
+		if v {
+			f = 1
+		}
+	case float64:
+		f = v
+	case float32:
+		f = float64(v)
+	case int:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case uint64:
+		f = float64(v)
+	case json.Number:
+		var err error
+		if f, err = v.Float64(); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	if c.Format == FormatBool {
+		return f != 0, true
+	}
+	if c.MinValue != nil && f < *c.MinValue || c.MaxValue != nil && f > *c.MaxValue {
+		return nil, false
+	}
+	if c.Format == FormatFloat {
+		return f, true
+	}
+	return int(math.Round(f)), true
+}
+
+// Service 服务
+type Service struct {
+	IID             uint64            `json:"iid"`
+	Type            string            `json:"type"`
+	Primary         bool              `json:"primary,omitempty"`
+	Hidden          bool              `json:"hidden,omitempty"`
+	Characteristics []*Characteristic `json:"characteristics"`
+}
+
+// NewService 创建服务，name不为空时添加名称特征值
+func NewService(typ, name string) *Service {
+	s := &Service{Type: typ}
+	if name != "" {
+		c := s.AddCharacteristic(CharName)
+		c.Value = name
+	}
+	return s
+}
+
+// AddCharacteristic 根据类型添加特征值，类型未定义时panic
+func (s *Service) AddCharacteristic(typ string) *Characteristic {
+	c := NewCharacteristic(typ)
+	if c == nil {
+		panic("hap: unknown characteristic " + typ)
+	}
+	s.Characteristics = append(s.Characteristics, c)
+	return c
+}
+
+// GetCharacteristic 返回服务中该类型的特征值
+func (s *Service) GetCharacteristic(typ string) *Characteristic {
+	for _, c := range s.Characteristics {
+		if c.Type == typ {
+			return c
+		}
+	}
+	return nil
+}
+
+var firmwareRegex = regexp.MustCompile(`^\d+(\.\d+){0,2}$`)
+
+// AccessoryInfo 配件的信息
+type AccessoryInfo struct {
+	Name             string
+	Manufacturer     string
+	Model            string
+	SerialNumber     string
+	FirmwareRevision string
+
+	// OnIdentify 控制器要求配件标识自己（如闪烁）时调用
+	OnIdentify func() error
+}
+
+// Accessory 配件，桥接器本身的aid为1
+type Accessory struct {
+	AID      uint64     `json:"aid"`
+	Services []*Service `json:"services"`
+
+	nextIID uint64
+}
+
+// NewAccessory 创建包含配件信息服务的配件
+func NewAccessory(aid uint64, info AccessoryInfo) *Accessory {
+	a := &Accessory{AID: aid}
+	s := NewService(ServiceAccessoryInformation, "")
+	s.AddCharacteristic(CharIdentify).OnWrite = func(interface{}) error {
+		if info.OnIdentify != nil {
+			return info.OnIdentify()
+		}
+		return nil
+	}
+	values := []struct {
+		typ string
+		val string
+	}{
+		{CharManufacturer, info.Manufacturer},
+		{CharModel, info.Model},
+		{CharName, info.Name},
+		{CharSerialNumber, info.SerialNumber},
+		{CharFirmwareRevision, info.FirmwareRevision},
+	}
+	for _, v := range values {
+		val := v.val
+		// 固件版本为可选，格式必须为 x.y.z
+		if v.typ == CharFirmwareRevision && !firmwareRegex.MatchString(val) {
+			continue
+		}
+		if val == "" {
+			val = "-"
+		}
+		s.AddCharacteristic(v.typ).Value = val
+	}
+	a.AddService(s)
+	return a
+}
+
+// AddService 添加服务并分配服务及特征值的iid，同一配件的iid在服务顺序不变时保持不变
+func (a *Accessory) AddService(s *Service) *Service {
+	a.nextIID++
+	s.IID = a.nextIID
+	for _, c := range s.Characteristics {
+		a.nextIID++
+		c.IID = a.nextIID
+	}
+	a.Services = append(a.Services, s)
+	return s
+}
+
+// characteristic 返回iid对应的特征值
+func (a *Accessory) characteristic(iid uint64) *Characteristic {
+	for _, s := range a.Services {
+		for _, c := range s.Characteristics {
+			if c.IID == iid {
+				return c
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package hap
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestTLV8(t *testing.T) {
+	long := bytes.Repeat([]byte{0xAB}, 600)
+	var tlv TLV8
+	b := tlv.SetByte(TLVState, 2).Set(TLVPublicKey, long).Set(TLVSalt, []byte{1, 2}).Encode()
+	assert.Equal(t, 3+(2+255)*2+(2+90)+4, len(b))
+
+	decoded, err := DecodeTLV8(b)
+	require.Nil(t, err)
+	state, ok := decoded.GetByte(TLVState)
+	assert.True(t, ok)
+	assert.Equal(t, byte(2), state)
+	assert.Equal(t, long, decoded.Get(TLVPublicKey))
+	assert.Equal(t, []byte{1, 2}, decoded.Get(TLVSalt))
+
+	_, err = DecodeTLV8([]byte{TLVState, 2, 1})
+	assert.NotNil(t, err)
+}
+
+func TestSRP(t *testing.T) {
+	assert.Equal(t, 3072, srpN.BitLen())
+	assert.True(t, srpN.ProbablyPrime(10))
+
+	server := newSRPServer("123-45-679")
+	client := newSRPClient()
+	m1, err := client.Compute(server.salt, server.PublicKey(), "123-45-679")
+	require.Nil(t, err)
+	m2, err := server.Verify(client.A.Bytes(), m1)
+	require.Nil(t, err)
+	assert.True(t, client.VerifyServer(m2))
+	assert.Equal(t, client.K, server.K)
+
+	server = newSRPServer("123-45-679")
+	client = newSRPClient()
+	m1, _ = client.Compute(server.salt, server.PublicKey(), "111-22-333")
+	_, err = server.Verify(client.A.Bytes(), m1)
+	assert.NotNil(t, err)
+}
+
+func TestSetupCode(t *testing.T) {
+	assert.True(t, ValidSetupCode("031-45-154"))
+	assert.False(t, ValidSetupCode("123-45-678"))
+	assert.False(t, ValidSetupCode("03145154"))
+	assert.True(t, ValidSetupCode(GenerateSetupCode()))
+
+	uri := SetupURI("031-45-154", "7OSX", CategoryBridge)
+	assert.True(t, strings.HasPrefix(uri, "X-HM://"))
+	assert.True(t, strings.HasSuffix(uri, "7OSX"))
+	payload, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(uri, "X-HM://"), "7OSX"), 36, 64)
+	require.Nil(t, err)
+	assert.Equal(t, uint64(3145154), payload&0x7FFFFFF)
+	assert.Equal(t, uint64(2), payload>>27&0xF)
+	assert.Equal(t, uint64(CategoryBridge), payload>>31&0xFF)
+}
+
+func TestMDNSMessage(t *testing.T) {
+	service, instance, host, err := newMDNSNames("SA.智汀", "AA:BB:CC:DD:EE:FF")
+	require.Nil(t, err)
+	r := &responder{service: service, instance: instance, host: host, port: 51826, txt: []string{"c#=1", "sf=1"}}
+	assert.Equal(t, "SA-AABBCCDDEEFF.local.", host.String())
+
+	msg := r.message(mdnsTTL)
+	b, err := msg.Pack()
+	require.Nil(t, err)
+	assert.False(t, r.shouldAnswer(b))
+
+	for _, name := range []string{mdnsService, "_airplay._tcp.local."} {
+		msg := dnsmessage.Message{Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET},
+		}}
+		query, err := msg.Pack()
+		require.Nil(t, err)
+		assert.Equal(t, name == mdnsService, r.shouldAnswer(query))
+	}
+}
+
+// testClient 模拟控制器，用于测试配对及特征值的读写
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	rw   io.ReadWriter
+	br   *bufio.Reader
+
+	id   string
+	ltsk ed25519.PrivateKey
+}
+
+type testResponse struct {
+	statusLine string
+	status     int
+	body       []byte
+}
+
+func newTestClient(t *testing.T, addr string, id string, ltsk ed25519.PrivateKey) *testClient {
+	conn, err := net.Dial("tcp", addr)
+	require.Nil(t, err)
+	return &testClient{t: t, conn: conn, rw: conn, br: bufio.NewReader(conn), id: id, ltsk: ltsk}
+}
+
+func (c *testClient) do(method, path, contentType string, body []byte) testResponse {
+	req := fmt.Sprintf("%s %s HTTP/1.1\r\nHost: bridge\r\nContent-Length: %d\r\n", method, path, len(body))
+	if contentType != "" {
+		req += "Content-Type: " + contentType + "\r\n"
+	}
+	_, err := c.rw.Write(append([]byte(req+"\r\n"), body...))
+	require.Nil(c.t, err)
+	return c.read()
+}
+
+func (c *testClient) read() testResponse {
+	_ = c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	tp := textproto.NewReader(c.br)
+	line, err := tp.ReadLine()
+	require.Nil(c.t, err)
+	header, err := tp.ReadMIMEHeader()
+	require.Nil(c.t, err)
+	n, _ := strconv.Atoi(header.Get("Content-Length"))
+	body := make([]byte, n)
+	_, err = io.ReadFull(c.br, body)
+	require.Nil(c.t, err)
+	status, _ := strconv.Atoi(strings.Fields(line)[1])
+	return testResponse{statusLine: line, status: status, body: body}
+}
+
+func (c *testClient) tlv(path string, req *TLV8) TLV8 {
+	resp := c.do("POST", path, contentTypeTLV8, req.Encode())
+	require.Equal(c.t, 200, resp.status)
+	t, err := DecodeTLV8(resp.body)
+	require.Nil(c.t, err)
+	return t
+}
+
+func (c *testClient) pairSetup(code string) byte {
+	var m1 TLV8
+	m2 := c.tlv("/pair-setup", m1.SetByte(TLVState, 1).SetByte(TLVMethod, MethodPairSetup))
+	if e, ok := m2.GetByte(TLVError); ok {
+		return e
+	}
+
+	srp := newSRPClient()
+	proof, err := srp.Compute(m2.Get(TLVSalt), m2.Get(TLVPublicKey), code)
+	require.Nil(c.t, err)
+	var m3 TLV8
+	m4 := c.tlv("/pair-setup", m3.SetByte(TLVState, 3).Set(TLVPublicKey, srp.A.Bytes()).Set(TLVProof, proof))
+	if e, ok := m4.GetByte(TLVError); ok {
+		return e
+	}
+	require.True(c.t, srp.VerifyServer(m4.Get(TLVProof)))
+
+	key := hkdfSHA512(srp.K, "Pair-Setup-Encrypt-Salt", "Pair-Setup-Encrypt-Info")
+	x := hkdfSHA512(srp.K, "Pair-Setup-Controller-Sign-Salt", "Pair-Setup-Controller-Sign-Info")
+	pub := c.ltsk.Public().(ed25519.PublicKey)
+	info := append(append(x, c.id...), pub...)
+	var sub TLV8
+	sub.Set(TLVIdentifier, []byte(c.id)).Set(TLVPublicKey, pub).Set(TLVSignature, ed25519.Sign(c.ltsk, info))
+	encrypted, err := sealLabel(key, "PS-Msg05", sub.Encode())
+	require.Nil(c.t, err)
+	var m5 TLV8
+	m6 := c.tlv("/pair-setup", m5.SetByte(TLVState, 5).Set(TLVEncryptedData, encrypted))
+	if e, ok := m6.GetByte(TLVError); ok {
+		return e
+	}
+	b, err := openLabel(key, "PS-Msg06", m6.Get(TLVEncryptedData))
+	require.Nil(c.t, err)
+	accessory, err := DecodeTLV8(b)
+	require.Nil(c.t, err)
+	x = hkdfSHA512(srp.K, "Pair-Setup-Accessory-Sign-Salt", "Pair-Setup-Accessory-Sign-Info")
+	info = append(append(x, accessory.Get(TLVIdentifier)...), accessory.Get(TLVPublicKey)...)
+	require.True(c.t, ed25519.Verify(accessory.Get(TLVPublicKey), info, accessory.Get(TLVSignature)))
+	return 0
+}
+
+func (c *testClient) pairVerify() byte {
+	private := randomBytes(32)
+	public, _ := curve25519.X25519(private, curve25519.Basepoint)
+	var m1 TLV8
+	m2 := c.tlv("/pair-verify", m1.SetByte(TLVState, 1).Set(TLVPublicKey, public))
+	shared, err := curve25519.X25519(private, m2.Get(TLVPublicKey))
+	require.Nil(c.t, err)
+	key := hkdfSHA512(shared, "Pair-Verify-Encrypt-Salt", "Pair-Verify-Encrypt-Info")
+	_, err = openLabel(key, "PV-Msg02", m2.Get(TLVEncryptedData))
+	require.Nil(c.t, err)
+
+	info := append(append(append([]byte(nil), public...), c.id...), m2.Get(TLVPublicKey)...)
+	var sub TLV8
+	sub.Set(TLVIdentifier, []byte(c.id)).Set(TLVSignature, ed25519.Sign(c.ltsk, info))
+	encrypted, err := sealLabel(key, "PV-Msg03", sub.Encode())
+	require.Nil(c.t, err)
+	var m3 TLV8
+	m4 := c.tlv("/pair-verify", m3.SetByte(TLVState, 3).Set(TLVEncryptedData, encrypted))
+	if e, ok := m4.GetByte(TLVError); ok {
+		return e
+	}
+	sc := newSecureConn(c.conn,
+		hkdfSHA512(shared, "Control-Salt", "Control-Read-Encryption-Key"),
+		hkdfSHA512(shared, "Control-Salt", "Control-Write-Encryption-Key"))
+	c.rw, c.br = sc, bufio.NewReader(sc)
+	return 0
+}
+
+func (c *testClient) put(v interface{}) testResponse {
+	b, _ := json.Marshal(v)
+	return c.do("PUT", "/characteristics", contentTypeJSON, b)
+}
+
+func TestServer(t *testing.T) {
+	store := NewMemStore()
+	s, err := NewServer(Config{
+		ID:        "AA:BB:CC:DD:EE:FF",
+		Name:      "SA",
+		SetupCode: "031-45-154",
+		Store:     store,
+	})
+	require.Nil(t, err)
+
+	var written []interface{}
+	light := NewAccessory(2, AccessoryInfo{Name: "台灯"})
+	bulb := NewService(ServiceLightbulb, "台灯")
+	bulb.AddCharacteristic(CharOn).OnWrite = func(val interface{}) error {
+		written = append(written, val)
+		return nil
+	}
+	bulb.AddCharacteristic(CharBrightness)
+	light.AddService(bulb)
+	s.SetAccessories(light)
+	on, brightness := bulb.GetCharacteristic(CharOn), bulb.GetCharacteristic(CharBrightness)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	go s.Serve(ln)
+	defer s.Close()
+	addr := ln.Addr().String()
+
+	_, ltsk, _ := ed25519.GenerateKey(nil)
+	c := newTestClient(t, addr, "controller-1", ltsk)
+
+	// 未验证的连接不能读取配件
+	assert.Equal(t, 470, c.do("GET", "/accessories", "", nil).status)
+
+	assert.Equal(t, TLVErrorAuthentication, c.pairSetup("111-22-333"))
+	assert.False(t, s.IsPaired())
+	assert.Equal(t, byte(0), c.pairSetup("031-45-154"))
+	assert.True(t, s.IsPaired())
+	assert.Equal(t, TLVErrorUnavailable, c.pairSetup("031-45-154"))
+
+	require.Equal(t, byte(0), c.pairVerify())
+	resp := c.do("GET", "/accessories", "", nil)
+	require.Equal(t, 200, resp.status)
+	var accessories struct {
+		Accessories []Accessory `json:"accessories"`
+	}
+	require.Nil(t, json.Unmarshal(resp.body, &accessories))
+	require.Len(t, accessories.Accessories, 2)
+	assert.Equal(t, uint64(1), accessories.Accessories[0].AID)
+	assert.Equal(t, ServiceLightbulb, accessories.Accessories[1].Services[1].Type)
+
+	// 写入
+	resp = c.put(map[string]interface{}{"characteristics": []map[string]interface{}{
+		{"aid": 2, "iid": on.IID, "value": 1},
+	}})
+	assert.Equal(t, 204, resp.status)
+	assert.Equal(t, []interface{}{true}, written)
+
+	// 部分失败返回207
+	resp = c.put(map[string]interface{}{"characteristics": []map[string]interface{}{
+		{"aid": 2, "iid": brightness.IID, "value": 200},
+		{"aid": 2, "iid": 99, "value": 1},
+	}})
+	assert.Equal(t, 207, resp.status)
+	assert.Contains(t, string(resp.body), strconv.Itoa(StatusInvalidValue))
+	assert.Contains(t, string(resp.body), strconv.Itoa(StatusResourceDoesNotExist))
+
+	resp = c.do("GET", fmt.Sprintf("/characteristics?id=2.%d,2.%d", on.IID, brightness.IID), "", nil)
+	assert.Equal(t, 200, resp.status)
+	assert.JSONEq(t, fmt.Sprintf(`{"characteristics":[{"aid":2,"iid":%d,"value":true},{"aid":2,"iid":%d,"value":0}]}`,
+		on.IID, brightness.IID), string(resp.body))
+
+	// 另一个连接订阅事件，设备状态变化时收到通知
+	c2 := newTestClient(t, addr, "controller-1", ltsk)
+	require.Equal(t, byte(0), c2.pairVerify())
+	resp = c2.put(map[string]interface{}{"characteristics": []map[string]interface{}{
+		{"aid": 2, "iid": brightness.IID, "ev": true},
+	}})
+	assert.Equal(t, 204, resp.status)
+	assert.True(t, s.UpdateValue(2, brightness.IID, 42.4))
+	event := c2.read()
+	assert.Equal(t, "EVENT/1.0 200 OK", event.statusLine)
+	assert.JSONEq(t, fmt.Sprintf(`{"characteristics":[{"aid":2,"iid":%d,"value":42}]}`, brightness.IID), string(event.body))
+
+	// 列出配对
+	var list TLV8
+	pairings := c.tlv("/pairings", list.SetByte(TLVState, 1).SetByte(TLVMethod, MethodListPairings))
+	assert.Equal(t, []byte("controller-1"), pairings.Get(TLVIdentifier))
+
+	// 未配对的控制器验证失败
+	_, other, _ := ed25519.GenerateKey(nil)
+	c3 := newTestClient(t, addr, "controller-2", other)
+	assert.Equal(t, TLVErrorAuthentication, c3.pairVerify())
+
+	require.Nil(t, s.Unpair())
+	assert.False(t, s.IsPaired())
+}
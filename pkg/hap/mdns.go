@@ -0,0 +1,220 @@
+package hap
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+const (
+	mdnsService = "_hap._tcp.local."
+	mdnsTTL     = 120
+
+	// 唯一记录的class设置cache-flush位
+	classCacheFlush = dnsmessage.ClassINET | 1<<15
+)
+
+var mdnsAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// responder Bonjour（mDNS/DNS-SD）的响应者，广播桥接器的 _hap._tcp 服务
+type responder struct {
+	conn     *net.UDPConn
+	service  dnsmessage.Name
+	instance dnsmessage.Name
+	host     dnsmessage.Name
+	port     uint16
+
+	mu  sync.Mutex
+	txt []string
+}
+
+// instanceLabel 服务实例名称作为一个DNS标签，不能包含点且不超过63字节
+func instanceLabel(name string) string {
+	name = strings.ReplaceAll(name, ".", " ")
+	for len(name) > 63 {
+		r := []rune(name)
+		name = string(r[:len(r)-1])
+	}
+	return name
+}
+
+func newMDNSNames(name, id string) (service, instance, host dnsmessage.Name, err error) {
+	if service, err = dnsmessage.NewName(mdnsService); err != nil {
+		return
+	}
+	if instance, err = dnsmessage.NewName(instanceLabel(name) + "." + mdnsService); err != nil {
+		return
+	}
+	host, err = dnsmessage.NewName("SA-" + strings.ReplaceAll(id, ":", "") + ".local.")
+	return
+}
+
+func newResponder(name, id string, port int, txt []string) (*responder, error) {
+	service, instance, host, err := newMDNSNames(name, id)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsAddr)
+	if err != nil {
+		return nil, err
+	}
+	r := &responder{
+		conn:     conn,
+		service:  service,
+		instance: instance,
+		host:     host,
+		port:     uint16(port),
+		txt:      txt,
+	}
+	go r.serve()
+	go r.announce()
+	return r, nil
+}
+
+// Update 更新TXT记录并重新广播
+func (r *responder) Update(txt []string) {
+	r.mu.Lock()
+	r.txt = txt
+	r.mu.Unlock()
+	go r.announce()
+}
+
+// Close 发送TTL为0的记录通知控制器服务下线
+func (r *responder) Close() error {
+	r.send(r.message(0))
+	return r.conn.Close()
+}
+
+// announce 按规范启动或变化时发送两次
+func (r *responder) announce() {
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			time.Sleep(time.Second)
+		}
+		if err := r.send(r.message(mdnsTTL)); err != nil {
+			return
+		}
+	}
+}
+
+func (r *responder) send(msg dnsmessage.Message) error {
+	b, err := msg.Pack()
+	if err != nil {
+		logger.Errorf("hap: pack mdns message err: %s", err)
+		return err
+	}
+	_, err = r.conn.WriteToUDP(b, mdnsAddr)
+	return err
+}
+
+func (r *responder) serve() {
+	buf := make([]byte, 9000)
+	for {
+		n, _, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if r.shouldAnswer(buf[:n]) {
+			r.send(r.message(mdnsTTL))
+		}
+	}
+}
+
+// shouldAnswer 查询包含桥接器的服务、实例或主机名时响应
+func (r *responder) shouldAnswer(b []byte) bool {
+	var p dnsmessage.Parser
+	h, err := p.Start(b)
+	if err != nil || h.Response {
+		return false
+	}
+	questions, err := p.AllQuestions()
+	if err != nil {
+		return false
+	}
+	for _, q := range questions {
+		name := q.Name.String()
+		switch {
+		case strings.EqualFold(name, r.service.String()):
+			if q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL {
+				return true
+			}
+		case strings.EqualFold(name, r.instance.String()):
+			if q.Type == dnsmessage.TypeSRV || q.Type == dnsmessage.TypeTXT || q.Type == dnsmessage.TypeALL {
+				return true
+			}
+		case strings.EqualFold(name, r.host.String()):
+			if q.Type == dnsmessage.TypeA || q.Type == dnsmessage.TypeALL {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// message 返回桥接器的PTR、SRV、TXT及A记录
+func (r *responder) message(ttl uint32) dnsmessage.Message {
+	r.mu.Lock()
+	txt := r.txt
+	r.mu.Unlock()
+
+	header := func(name dnsmessage.Name, typ dnsmessage.Type, class dnsmessage.Class) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: name, Type: typ, Class: class, TTL: ttl}
+	}
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{Response: true, Authoritative: true},
+		Answers: []dnsmessage.Resource{
+			{
+				Header: header(r.service, dnsmessage.TypePTR, dnsmessage.ClassINET),
+				Body:   &dnsmessage.PTRResource{PTR: r.instance},
+			},
+			{
+				Header: header(r.instance, dnsmessage.TypeSRV, classCacheFlush),
+				Body:   &dnsmessage.SRVResource{Target: r.host, Port: r.port},
+			},
+			{
+				Header: header(r.instance, dnsmessage.TypeTXT, classCacheFlush),
+				Body:   &dnsmessage.TXTResource{TXT: txt},
+			},
+		},
+	}
+	for _, ip := range localIPv4() {
+		var a [4]byte
+		copy(a[:], ip)
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{
+			Header: header(r.host, dnsmessage.TypeA, classCacheFlush),
+			Body:   &dnsmessage.AResource{A: a},
+		})
+	}
+	return msg
+}
+
+// localIPv4 返回本机支持组播的网卡的IPv4地址
+func localIPv4() []net.IP {
+	var ips []net.IP
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return nil
+	}
+	for _, i := range interfaces {
+		if i.Flags&net.FlagUp == 0 || i.Flags&net.FlagLoopback != 0 || i.Flags&net.FlagMulticast == 0 {
+			continue
+		}
+		addrs, err := i.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok {
+				if ip := ipNet.IP.To4(); ip != nil {
+					ips = append(ips, ip)
+				}
+			}
+		}
+	}
+	return ips
+}
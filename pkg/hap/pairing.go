@@ -0,0 +1,355 @@
+package hap
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+const (
+	storeKeyLTSK     = "ltsk"
+	storeKeyPairings = "pairings"
+	storeKeyConfig   = "config"
+
+	// 配对失败的次数超过后不再允许配对，需要重置
+	maxSetupTries = 100
+)
+
+// pairing 已配对的控制器
+type pairing struct {
+	ID        string `json:"id"`
+	PublicKey []byte `json:"public_key"`
+	Admin     bool   `json:"admin"`
+}
+
+// pairSetup 进行中的配对，同一时间只允许一个控制器配对
+type pairSetup struct {
+	conn *conn
+	srp  *srpServer
+	key  []byte
+}
+
+// pairVerify 连接的配对验证状态
+type pairVerify struct {
+	publicKey     []byte
+	peerPublicKey []byte
+	sharedSecret  []byte
+	key           []byte
+}
+
+func tlvError(state, code byte) []byte {
+	var t TLV8
+	return t.SetByte(TLVState, state).SetByte(TLVError, code).Encode()
+}
+
+// pairings 返回已配对的控制器，调用方需持有s.mu
+func (s *Server) pairings() []pairing {
+	var ps []pairing
+	b, err := s.store.Get(storeKeyPairings)
+	if err != nil {
+		return nil
+	}
+	if err = json.Unmarshal(b, &ps); err != nil {
+		logger.Errorf("hap: unmarshal pairings err: %s", err)
+	}
+	return ps
+}
+
+func (s *Server) savePairings(ps []pairing) error {
+	if len(ps) == 0 {
+		return s.store.Delete(storeKeyPairings)
+	}
+	b, err := json.Marshal(ps)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(storeKeyPairings, b)
+}
+
+func (s *Server) getPairing(id string) (pairing, bool) {
+	for _, p := range s.pairings() {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return pairing{}, false
+}
+
+// handlePairSetup 处理配对（Pair Setup）的M1、M3、M5
+func (c *conn) handlePairSetup(body []byte) []byte {
+	req, err := DecodeTLV8(body)
+	if err != nil {
+		return tlvError(2, TLVErrorUnknown)
+	}
+	state, _ := req.GetByte(TLVState)
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch state {
+	case 1:
+		if len(s.pairings()) != 0 {
+			return tlvError(2, TLVErrorUnavailable)
+		}
+		if s.setupTries >= maxSetupTries {
+			return tlvError(2, TLVErrorMaxTries)
+		}
+		if s.setup != nil && s.setup.conn != c {
+			return tlvError(2, TLVErrorBusy)
+		}
+		s.setup = &pairSetup{conn: c, srp: newSRPServer(s.cfg.SetupCode)}
+		var resp TLV8
+		return resp.SetByte(TLVState, 2).
+			Set(TLVPublicKey, s.setup.srp.PublicKey()).
+			Set(TLVSalt, s.setup.srp.salt).Encode()
+	case 3:
+		if s.setup == nil || s.setup.conn != c {
+			return tlvError(4, TLVErrorUnknown)
+		}
+		proof, err := s.setup.srp.Verify(req.Get(TLVPublicKey), req.Get(TLVProof))
+		if err != nil {
+			s.setupTries++
+			s.setup = nil
+			return tlvError(4, TLVErrorAuthentication)
+		}
+		s.setup.key = hkdfSHA512(s.setup.srp.K, "Pair-Setup-Encrypt-Salt", "Pair-Setup-Encrypt-Info")
+		var resp TLV8
+		return resp.SetByte(TLVState, 4).Set(TLVProof, proof).Encode()
+	case 5:
+		if s.setup == nil || s.setup.conn != c || s.setup.key == nil {
+			return tlvError(6, TLVErrorUnknown)
+		}
+		setup := s.setup
+		s.setup = nil
+		resp, err := s.exchange(setup, req.Get(TLVEncryptedData))
+		if err != nil {
+			logger.Warnf("hap: pair setup err: %s", err)
+			return tlvError(6, TLVErrorAuthentication)
+		}
+		return resp
+	}
+	return tlvError(state+1, TLVErrorUnknown)
+}
+
+// exchange 配对的M5、M6：交换并保存双方的长期公钥
+func (s *Server) exchange(setup *pairSetup, encrypted []byte) ([]byte, error) {
+	b, err := openLabel(setup.key, "PS-Msg05", encrypted)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := DecodeTLV8(b)
+	if err != nil {
+		return nil, err
+	}
+	id, ltpk := sub.Get(TLVIdentifier), sub.Get(TLVPublicKey)
+	if len(ltpk) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid controller public key")
+	}
+	x := hkdfSHA512(setup.srp.K, "Pair-Setup-Controller-Sign-Salt", "Pair-Setup-Controller-Sign-Info")
+	info := append(append(x, id...), ltpk...)
+	if !ed25519.Verify(ltpk, info, sub.Get(TLVSignature)) {
+		return nil, errors.New("invalid controller signature")
+	}
+	if err = s.savePairings([]pairing{{ID: string(id), PublicKey: ltpk, Admin: true}}); err != nil {
+		return nil, err
+	}
+	go s.advertise()
+
+	x = hkdfSHA512(setup.srp.K, "Pair-Setup-Accessory-Sign-Salt", "Pair-Setup-Accessory-Sign-Info")
+	pub := s.ltsk.Public().(ed25519.PublicKey)
+	info = append(append(x, s.cfg.ID...), pub...)
+	var accessory TLV8
+	accessory.Set(TLVIdentifier, []byte(s.cfg.ID)).
+		Set(TLVPublicKey, pub).
+		Set(TLVSignature, ed25519.Sign(s.ltsk, info))
+	if encrypted, err = sealLabel(setup.key, "PS-Msg06", accessory.Encode()); err != nil {
+		return nil, err
+	}
+	var resp TLV8
+	return resp.SetByte(TLVState, 6).Set(TLVEncryptedData, encrypted).Encode(), nil
+}
+
+// handlePairVerify 处理配对验证（Pair Verify）的M1、M3，验证通过后返回true，连接之后的数据加密传输
+func (c *conn) handlePairVerify(body []byte) ([]byte, bool) {
+	req, err := DecodeTLV8(body)
+	if err != nil {
+		return tlvError(2, TLVErrorUnknown), false
+	}
+	state, _ := req.GetByte(TLVState)
+	switch state {
+	case 1:
+		resp, err := c.verifyStart(req.Get(TLVPublicKey))
+		if err != nil {
+			logger.Warnf("hap: pair verify err: %s", err)
+			return tlvError(2, TLVErrorUnknown), false
+		}
+		return resp, false
+	case 3:
+		if err = c.verifyFinish(req.Get(TLVEncryptedData)); err != nil {
+			logger.Warnf("hap: pair verify err: %s", err)
+			return tlvError(4, TLVErrorAuthentication), false
+		}
+		var resp TLV8
+		return resp.SetByte(TLVState, 4).Encode(), true
+	}
+	return tlvError(state+1, TLVErrorUnknown), false
+}
+
+func (c *conn) verifyStart(peerPublicKey []byte) ([]byte, error) {
+	if len(peerPublicKey) != curve25519.PointSize {
+		return nil, errors.New("invalid controller public key")
+	}
+	private := randomBytes(curve25519.ScalarSize)
+	public, err := curve25519.X25519(private, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := curve25519.X25519(private, peerPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	v := &pairVerify{
+		publicKey:     public,
+		peerPublicKey: peerPublicKey,
+		sharedSecret:  shared,
+		key:           hkdfSHA512(shared, "Pair-Verify-Encrypt-Salt", "Pair-Verify-Encrypt-Info"),
+	}
+
+	id := c.srv.cfg.ID
+	info := append(append(append([]byte(nil), public...), id...), peerPublicKey...)
+	var sub TLV8
+	sub.Set(TLVIdentifier, []byte(id)).Set(TLVSignature, ed25519.Sign(c.srv.ltsk, info))
+	encrypted, err := sealLabel(v.key, "PV-Msg02", sub.Encode())
+	if err != nil {
+		return nil, err
+	}
+	c.verify = v
+	var resp TLV8
+	return resp.SetByte(TLVState, 2).
+		Set(TLVPublicKey, public).
+		Set(TLVEncryptedData, encrypted).Encode(), nil
+}
+
+func (c *conn) verifyFinish(encrypted []byte) error {
+	v := c.verify
+	if v == nil {
+		return errors.New("pair verify not started")
+	}
+	c.verify = nil
+	b, err := openLabel(v.key, "PV-Msg03", encrypted)
+	if err != nil {
+		return err
+	}
+	sub, err := DecodeTLV8(b)
+	if err != nil {
+		return err
+	}
+	id := string(sub.Get(TLVIdentifier))
+	c.srv.mu.Lock()
+	p, ok := c.srv.getPairing(id)
+	c.srv.mu.Unlock()
+	if !ok {
+		return errors.New("controller not paired")
+	}
+	info := append(append(append([]byte(nil), v.peerPublicKey...), id...), v.publicKey...)
+	if !ed25519.Verify(p.PublicKey, info, sub.Get(TLVSignature)) {
+		return errors.New("invalid controller signature")
+	}
+	c.srv.mu.Lock()
+	c.controller = id
+	c.srv.mu.Unlock()
+	c.readKey = hkdfSHA512(v.sharedSecret, "Control-Salt", "Control-Write-Encryption-Key")
+	c.writeKey = hkdfSHA512(v.sharedSecret, "Control-Salt", "Control-Read-Encryption-Key")
+	return nil
+}
+
+// handlePairings 处理添加、删除及列出配对，只允许管理员操作，返回删除的控制器
+func (c *conn) handlePairings(body []byte) ([]byte, []string) {
+	req, err := DecodeTLV8(body)
+	if err != nil {
+		return tlvError(2, TLVErrorUnknown), nil
+	}
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.getPairing(c.controller); !ok || !p.Admin {
+		return tlvError(2, TLVErrorAuthentication), nil
+	}
+
+	ps := s.pairings()
+	method, _ := req.GetByte(TLVMethod)
+	var resp TLV8
+	resp.SetByte(TLVState, 2)
+	switch method {
+	case MethodAddPairing:
+		id, ltpk := string(req.Get(TLVIdentifier)), req.Get(TLVPublicKey)
+		perm, _ := req.GetByte(TLVPermissions)
+		p := pairing{ID: id, PublicKey: ltpk, Admin: perm&1 == 1}
+		if id == "" || len(ltpk) != ed25519.PublicKeySize {
+			return tlvError(2, TLVErrorUnknown), nil
+		}
+		var exist bool
+		for i := range ps {
+			if ps[i].ID != id {
+				continue
+			}
+			if !ed25519.PublicKey(ps[i].PublicKey).Equal(ed25519.PublicKey(ltpk)) {
+				return tlvError(2, TLVErrorUnknown), nil
+			}
+			ps[i].Admin, exist = p.Admin, true
+		}
+		if !exist {
+			ps = append(ps, p)
+		}
+		if err = s.savePairings(ps); err != nil {
+			return tlvError(2, TLVErrorUnknown), nil
+		}
+		return resp.Encode(), nil
+	case MethodRemovePairing:
+		id := string(req.Get(TLVIdentifier))
+		var (
+			left    []pairing
+			removed = []string{id}
+			admin   bool
+		)
+		for _, p := range ps {
+			if p.ID != id {
+				left = append(left, p)
+				admin = admin || p.Admin
+			}
+		}
+		// 删除最后一个管理员后删除所有配对
+		if !admin {
+			for _, p := range left {
+				removed = append(removed, p.ID)
+			}
+			left = nil
+		}
+		if err = s.savePairings(left); err != nil {
+			return tlvError(2, TLVErrorUnknown), nil
+		}
+		if len(left) == 0 {
+			go s.advertise()
+		}
+		return resp.Encode(), removed
+	case MethodListPairings:
+		for i, p := range ps {
+			if i > 0 {
+				resp.Set(TLVSeparator, nil)
+			}
+			var perm byte
+			if p.Admin {
+				perm = 1
+			}
+			resp.Set(TLVIdentifier, []byte(p.ID)).
+				Set(TLVPublicKey, p.PublicKey).
+				SetByte(TLVPermissions, perm)
+		}
+		return resp.Encode(), nil
+	}
+	return tlvError(2, TLVErrorUnknown), nil
+}
@@ -0,0 +1,696 @@
+package hap
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+// HAP的状态码
+const (
+	StatusSuccess                     = 0
+	StatusInsufficientPrivileges      = -70401
+	StatusServiceCommunicationFailure = -70402
+	StatusResourceBusy                = -70403
+	StatusReadOnly                    = -70404
+	StatusWriteOnly                   = -70405
+	StatusNotificationNotSupported    = -70406
+	StatusOutOfResources              = -70407
+	StatusOperationTimedOut           = -70408
+	StatusResourceDoesNotExist        = -70409
+	StatusInvalidValue                = -70410
+)
+
+// 未验证的连接请求需要验证的资源时返回470
+const statusConnectionAuthorizationRequired = 470
+
+const (
+	contentTypeTLV8 = "application/pairing+tlv8"
+	contentTypeJSON = "application/hap+json"
+)
+
+var errServerClosed = errors.New("hap: server closed")
+
+// Config 桥接器的配置
+type Config struct {
+	// ID 设备ID，格式同MAC地址，变化后控制器视为新的配件
+	ID               string
+	Name             string
+	Manufacturer     string
+	Model            string
+	FirmwareRevision string
+	// SetupCode 设置码，格式为 XXX-XX-XXX
+	SetupCode string
+	// SetupID 设置ID，用于二维码配对，可以为空
+	SetupID  string
+	Category Category
+	// Port 监听的端口，为0则随机分配
+	Port  int
+	Store Store
+
+	// OnIdentify 未配对时控制器要求桥接器标识自己时调用
+	OnIdentify func() error
+}
+
+type charKey struct {
+	aid uint64
+	iid uint64
+}
+
+type configVersion struct {
+	Number int    `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+// Server HAP（HomeKit Accessory Protocol）的IP桥接器
+type Server struct {
+	cfg   Config
+	store Store
+	ltsk  ed25519.PrivateKey
+
+	mu          sync.Mutex
+	accessories []*Accessory
+	config      configVersion
+	conns       map[*conn]bool
+	setup       *pairSetup
+	setupTries  int
+	ln          net.Listener
+	mdns        *responder
+	closed      bool
+}
+
+// NewServer 创建桥接器，首次创建时生成并保存配件的长期密钥
+func NewServer(cfg Config) (*Server, error) {
+	if cfg.ID == "" || cfg.Name == "" || cfg.Store == nil {
+		return nil, errors.New("hap: id, name and store are required")
+	}
+	if !ValidSetupCode(cfg.SetupCode) {
+		return nil, errors.New("hap: invalid setup code")
+	}
+	if cfg.Category == 0 {
+		cfg.Category = CategoryBridge
+	}
+
+	s := &Server{cfg: cfg, store: cfg.Store, conns: make(map[*conn]bool)}
+	b, err := s.store.Get(storeKeyLTSK)
+	if err == ErrNotFound {
+		_, s.ltsk, err = ed25519.GenerateKey(nil)
+		if err != nil {
+			return nil, err
+		}
+		err = s.store.Set(storeKeyLTSK, s.ltsk)
+	} else if err == nil {
+		if len(b) != ed25519.PrivateKeySize {
+			return nil, errors.New("hap: invalid long-term secret key")
+		}
+		s.ltsk = b
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b, err = s.store.Get(storeKeyConfig); err == nil {
+		_ = json.Unmarshal(b, &s.config)
+	}
+	s.SetAccessories()
+	return s, nil
+}
+
+// bridge 桥接器本身的配件
+func (s *Server) bridge() *Accessory {
+	a := NewAccessory(1, AccessoryInfo{
+		Name:             s.cfg.Name,
+		Manufacturer:     s.cfg.Manufacturer,
+		Model:            s.cfg.Model,
+		SerialNumber:     s.cfg.ID,
+		FirmwareRevision: s.cfg.FirmwareRevision,
+		OnIdentify:       s.cfg.OnIdentify,
+	})
+	protocol := NewService(ServiceProtocolInformation, "")
+	protocol.AddCharacteristic(CharVersion)
+	a.AddService(protocol)
+	return a
+}
+
+// SetAccessories 设置桥接的配件，aid需大于1且不重复；配件变化后递增配置版本，控制器会重新获取配件
+func (s *Server) SetAccessories(accessories ...*Accessory) {
+	accessories = append([]*Accessory{s.bridge()}, accessories...)
+	sort.SliceStable(accessories, func(i, j int) bool {
+		return accessories[i].AID < accessories[j].AID
+	})
+
+	b, _ := json.Marshal(accessories)
+	sum := sha1.Sum(b)
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	s.accessories = accessories
+	changed := hash != s.config.Hash
+	if changed {
+		s.config.Number = s.config.Number%65535 + 1
+		s.config.Hash = hash
+		if b, err := json.Marshal(s.config); err == nil {
+			if err = s.store.Set(storeKeyConfig, b); err != nil {
+				logger.Errorf("hap: save config err: %s", err)
+			}
+		}
+	}
+	s.mu.Unlock()
+	if changed {
+		s.advertise()
+	}
+}
+
+// IsPaired 是否已有控制器配对
+func (s *Server) IsPaired() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pairings()) != 0
+}
+
+// Unpair 删除所有配对并断开已验证的连接，之后可以重新使用设置码配对
+func (s *Server) Unpair() error {
+	s.mu.Lock()
+	err := s.savePairings(nil)
+	s.setupTries = 0
+	var conns []*conn
+	for c := range s.conns {
+		if c.controller != "" {
+			conns = append(conns, c)
+		}
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	for _, c := range conns {
+		c.Close()
+	}
+	s.advertise()
+	return nil
+}
+
+// SetupURI 设置码的二维码内容
+func (s *Server) SetupURI() string {
+	return SetupURI(s.cfg.SetupCode, s.cfg.SetupID, s.cfg.Category)
+}
+
+// txt Bonjour的TXT记录
+func (s *Server) txt() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sf := 1
+	if len(s.pairings()) != 0 {
+		sf = 0
+	}
+	txt := []string{
+		fmt.Sprintf("c#=%d", s.config.Number),
+		"ff=0",
+		"id=" + s.cfg.ID,
+		"md=" + s.cfg.Name,
+		"pv=1.1",
+		"s#=1",
+		fmt.Sprintf("sf=%d", sf),
+		fmt.Sprintf("ci=%d", s.cfg.Category),
+	}
+	if s.cfg.SetupID != "" {
+		txt = append(txt, "sh="+setupHash(s.cfg.SetupID, s.cfg.ID))
+	}
+	return txt
+}
+
+// advertise 配置版本或配对状态变化后重新广播
+func (s *Server) advertise() {
+	s.mu.Lock()
+	r := s.mdns
+	s.mu.Unlock()
+	if r != nil {
+		r.Update(s.txt())
+	}
+}
+
+// Start 监听端口并通过Bonjour广播，连接在后台处理，直到 Close
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp4", fmt.Sprintf(":%d", s.cfg.Port))
+	if err != nil {
+		return err
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	r, err := newResponder(s.cfg.Name, s.cfg.ID, port, s.txt())
+	if err != nil {
+		ln.Close()
+		return err
+	}
+	s.mu.Lock()
+	s.mdns = r
+	s.mu.Unlock()
+	go func() {
+		if err := s.Serve(ln); err != nil {
+			logger.Errorf("hap: serve err: %s", err)
+		}
+	}()
+	return nil
+}
+
+// Serve 处理ln上的连接，不进行Bonjour广播
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return errServerClosed
+	}
+	s.ln = ln
+	s.mu.Unlock()
+
+	for {
+		rw, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		c := &conn{srv: s, raw: rw, rw: rw, br: bufio.NewReader(rw), events: make(map[charKey]bool)}
+		s.mu.Lock()
+		s.conns[c] = true
+		s.mu.Unlock()
+		go c.serve()
+	}
+}
+
+// Close 停止广播并关闭所有连接
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	ln, r := s.ln, s.mdns
+	var conns []*conn
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.mu.Unlock()
+	if r != nil {
+		r.Close()
+	}
+	for _, c := range conns {
+		c.Close()
+	}
+	if ln != nil {
+		return ln.Close()
+	}
+	return nil
+}
+
+// characteristic 返回特征值，调用方需持有s.mu
+func (s *Server) characteristic(aid, iid uint64) *Characteristic {
+	for _, a := range s.accessories {
+		if a.AID == aid {
+			return a.characteristic(iid)
+		}
+	}
+	return nil
+}
+
+// UpdateValue 更新特征值的值（如设备状态变化），值变化时通知订阅的控制器
+func (s *Server) UpdateValue(aid, iid uint64, val interface{}) bool {
+	s.mu.Lock()
+	c := s.characteristic(aid, iid)
+	if c == nil {
+		s.mu.Unlock()
+		return false
+	}
+	v, ok := c.Normalize(val)
+	if !ok || v == c.Value {
+		s.mu.Unlock()
+		return ok
+	}
+	c.Value = v
+	s.mu.Unlock()
+	s.notify(charKey{aid: aid, iid: iid}, v, nil)
+	return true
+}
+
+// notify 向订阅了特征值的连接发送事件，except为写入该值的连接
+func (s *Server) notify(key charKey, val interface{}, except *conn) {
+	body, err := json.Marshal(map[string]interface{}{
+		"characteristics": []map[string]interface{}{{"aid": key.aid, "iid": key.iid, "value": val}},
+	})
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	var conns []*conn
+	for c := range s.conns {
+		if c != except && c.controller != "" && c.events[key] {
+			conns = append(conns, c)
+		}
+	}
+	s.mu.Unlock()
+	for _, c := range conns {
+		go func(c *conn) {
+			if err := c.write("EVENT/1.0 200 OK", contentTypeJSON, body); err != nil {
+				c.Close()
+			}
+		}(c)
+	}
+}
+
+// conn 控制器的连接，配对验证后加密
+type conn struct {
+	srv *Server
+	raw net.Conn
+	rw  io.ReadWriter
+	br  *bufio.Reader
+
+	writeMu sync.Mutex
+
+	verify     *pairVerify
+	readKey    []byte
+	writeKey   []byte
+	controller string           // 验证通过的控制器，由s.mu保护
+	events     map[charKey]bool // 订阅的特征值，由s.mu保护
+}
+
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+func (c *conn) Close() error {
+	return c.raw.Close()
+}
+
+func (c *conn) serve() {
+	defer func() {
+		c.raw.Close()
+		c.srv.mu.Lock()
+		delete(c.srv.conns, c)
+		if c.srv.setup != nil && c.srv.setup.conn == c {
+			c.srv.setup = nil
+		}
+		c.srv.mu.Unlock()
+	}()
+
+	for {
+		req, err := http.ReadRequest(c.br)
+		if err != nil {
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return
+		}
+		resp, after := c.handle(req, body)
+		statusText := http.StatusText(resp.status)
+		if resp.status == statusConnectionAuthorizationRequired {
+			statusText = "Connection Authorization Required"
+		}
+		err = c.write(fmt.Sprintf("HTTP/1.1 %d %s", resp.status, statusText), resp.contentType, resp.body)
+		if err != nil {
+			return
+		}
+		if after != nil {
+			after()
+		}
+	}
+}
+
+// write 写入响应或事件，头部及body合并写入以保证在同一个加密帧序列中
+func (c *conn) write(statusLine, contentType string, body []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString(statusLine + "\r\n")
+	if contentType != "" {
+		buf.WriteString("Content-Type: " + contentType + "\r\n")
+	}
+	buf.WriteString("Content-Length: " + strconv.Itoa(len(body)) + "\r\n\r\n")
+	buf.Write(body)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.rw.Write(buf.Bytes())
+	return err
+}
+
+func jsonResponse(status int, v interface{}) response {
+	b, _ := json.Marshal(v)
+	return response{status: status, contentType: contentTypeJSON, body: b}
+}
+
+func statusResponse(status, hapStatus int) response {
+	return jsonResponse(status, map[string]int{"status": hapStatus})
+}
+
+// handle 处理请求，返回的after在响应写入后调用
+func (c *conn) handle(req *http.Request, body []byte) (response, func()) {
+	path := req.URL.Path
+	switch {
+	case path == "/pair-setup" && req.Method == http.MethodPost:
+		return response{status: http.StatusOK, contentType: contentTypeTLV8, body: c.handlePairSetup(body)}, nil
+	case path == "/pair-verify" && req.Method == http.MethodPost:
+		b, verified := c.handlePairVerify(body)
+		resp := response{status: http.StatusOK, contentType: contentTypeTLV8, body: b}
+		if !verified {
+			return resp, nil
+		}
+		// M4的响应明文发送，之后的数据加密
+		return resp, func() {
+			sc := newSecureConn(c.raw, c.readKey, c.writeKey)
+			c.writeMu.Lock()
+			c.rw = sc
+			c.writeMu.Unlock()
+			c.br = bufio.NewReader(sc)
+		}
+	case path == "/identify" && req.Method == http.MethodPost:
+		if c.srv.IsPaired() {
+			return statusResponse(http.StatusBadRequest, StatusInsufficientPrivileges), nil
+		}
+		if c.srv.cfg.OnIdentify != nil {
+			if err := c.srv.cfg.OnIdentify(); err != nil {
+				return statusResponse(http.StatusInternalServerError, StatusServiceCommunicationFailure), nil
+			}
+		}
+		return response{status: http.StatusNoContent}, nil
+	}
+
+	c.srv.mu.Lock()
+	verified := c.controller != ""
+	c.srv.mu.Unlock()
+	if !verified {
+		return statusResponse(statusConnectionAuthorizationRequired, StatusInsufficientPrivileges), nil
+	}
+
+	switch {
+	case path == "/accessories" && req.Method == http.MethodGet:
+		c.srv.mu.Lock()
+		defer c.srv.mu.Unlock()
+		return jsonResponse(http.StatusOK, map[string]interface{}{"accessories": c.srv.accessories}), nil
+	case path == "/characteristics" && req.Method == http.MethodGet:
+		return c.getCharacteristics(req), nil
+	case path == "/characteristics" && req.Method == http.MethodPut:
+		return c.putCharacteristics(body), nil
+	case path == "/pairings" && req.Method == http.MethodPost:
+		b, removed := c.handlePairings(body)
+		return response{status: http.StatusOK, contentType: contentTypeTLV8, body: b}, func() {
+			c.srv.disconnect(removed)
+		}
+	}
+	return statusResponse(http.StatusNotFound, StatusResourceDoesNotExist), nil
+}
+
+// disconnect 断开已删除配对的控制器的连接
+func (s *Server) disconnect(controllers []string) {
+	if len(controllers) == 0 {
+		return
+	}
+	removed := make(map[string]bool)
+	for _, id := range controllers {
+		removed[id] = true
+	}
+	s.mu.Lock()
+	var conns []*conn
+	for c := range s.conns {
+		if removed[c.controller] {
+			conns = append(conns, c)
+		}
+	}
+	s.mu.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+func parseCharKey(s string) (charKey, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 2 {
+		return charKey{}, false
+	}
+	aid, err1 := strconv.ParseUint(parts[0], 10, 64)
+	iid, err2 := strconv.ParseUint(parts[1], 10, 64)
+	return charKey{aid: aid, iid: iid}, err1 == nil && err2 == nil
+}
+
+// getCharacteristics 读取特征值，如 GET /characteristics?id=1.9,2.10&meta=1&ev=1
+func (c *conn) getCharacteristics(req *http.Request) response {
+	query := req.URL.Query()
+	flag := func(name string) bool {
+		return query.Get(name) == "1"
+	}
+	var (
+		results []map[string]interface{}
+		failed  bool
+	)
+	c.srv.mu.Lock()
+	for _, id := range strings.Split(query.Get("id"), ",") {
+		key, ok := parseCharKey(id)
+		if !ok {
+			c.srv.mu.Unlock()
+			return statusResponse(http.StatusBadRequest, StatusInvalidValue)
+		}
+		result := map[string]interface{}{"aid": key.aid, "iid": key.iid}
+		results = append(results, result)
+		ch := c.srv.characteristic(key.aid, key.iid)
+		switch {
+		case ch == nil:
+			result["status"], failed = StatusResourceDoesNotExist, true
+			continue
+		case !ch.hasPerm(PermRead):
+			result["status"], failed = StatusWriteOnly, true
+			continue
+		}
+		result["value"] = ch.Value
+		if flag("meta") {
+			result["format"] = ch.Format
+			if ch.Unit != "" {
+				result["unit"] = ch.Unit
+			}
+			if ch.MinValue != nil {
+				result["minValue"], result["maxValue"], result["minStep"] = ch.MinValue, ch.MaxValue, ch.MinStep
+			}
+		}
+		if flag("perms") {
+			result["perms"] = ch.Perms
+		}
+		if flag("type") {
+			result["type"] = ch.Type
+		}
+		if flag("ev") {
+			result["ev"] = c.events[key]
+		}
+	}
+	c.srv.mu.Unlock()
+
+	if failed {
+		for _, r := range results {
+			if _, ok := r["status"]; !ok {
+				r["status"] = StatusSuccess
+			}
+		}
+		return jsonResponse(http.StatusMultiStatus, map[string]interface{}{"characteristics": results})
+	}
+	return jsonResponse(http.StatusOK, map[string]interface{}{"characteristics": results})
+}
+
+type writeRequest struct {
+	AID   uint64          `json:"aid"`
+	IID   uint64          `json:"iid"`
+	Value json.RawMessage `json:"value"`
+	Event *bool           `json:"ev"`
+}
+
+// putCharacteristics 写入特征值或订阅事件，全部成功时返回204
+func (c *conn) putCharacteristics(body []byte) response {
+	var req struct {
+		Characteristics []writeRequest `json:"characteristics"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return statusResponse(http.StatusBadRequest, StatusInvalidValue)
+	}
+	var (
+		results []map[string]interface{}
+		failed  bool
+	)
+	for _, w := range req.Characteristics {
+		status := c.write1(w)
+		failed = failed || status != StatusSuccess
+		results = append(results, map[string]interface{}{"aid": w.AID, "iid": w.IID, "status": status})
+	}
+	if failed {
+		return jsonResponse(http.StatusMultiStatus, map[string]interface{}{"characteristics": results})
+	}
+	return response{status: http.StatusNoContent}
+}
+
+func (c *conn) write1(w writeRequest) int {
+	key := charKey{aid: w.AID, iid: w.IID}
+	s := c.srv
+	s.mu.Lock()
+	ch := s.characteristic(w.AID, w.IID)
+	if ch == nil {
+		s.mu.Unlock()
+		return StatusResourceDoesNotExist
+	}
+	if w.Event != nil {
+		if !ch.hasPerm(PermEvents) {
+			s.mu.Unlock()
+			return StatusNotificationNotSupported
+		}
+		c.events[key] = *w.Event
+	}
+	if w.Value == nil {
+		s.mu.Unlock()
+		return StatusSuccess
+	}
+	if !ch.hasPerm(PermWrite) {
+		s.mu.Unlock()
+		return StatusReadOnly
+	}
+	var raw interface{}
+	d := json.NewDecoder(bytes.NewReader(w.Value))
+	d.UseNumber()
+	if err := d.Decode(&raw); err != nil {
+		s.mu.Unlock()
+		return StatusInvalidValue
+	}
+	val, ok := ch.Normalize(raw)
+	onWrite := ch.OnWrite
+	s.mu.Unlock()
+	if !ok {
+		return StatusInvalidValue
+	}
+
+	// 设备控制可能耗时，不持有锁
+	if onWrite != nil {
+		if err := onWrite(val); err != nil {
+			logger.Warnf("hap: write %d.%d err: %s", w.AID, w.IID, err)
+			return StatusServiceCommunicationFailure
+		}
+	}
+	if !ch.hasPerm(PermRead) {
+		return StatusSuccess
+	}
+	s.mu.Lock()
+	changed := ch.Value != val
+	ch.Value = val
+	s.mu.Unlock()
+	if changed {
+		s.notify(key, val, c)
+	}
+	return StatusSuccess
+}
@@ -0,0 +1,150 @@
+package hap
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// 加密帧的明文最大长度
+	maxFrameLength = 1024
+	// Poly1305的tag长度
+	tagSize = 16
+)
+
+var errFrameTooLarge = errors.New("hap: frame too large")
+
+// hkdfSHA512 HAP使用的HKDF-SHA-512密钥派生，输出32字节
+func hkdfSHA512(secret []byte, salt, info string) []byte {
+	key := make([]byte, 32)
+	r := hkdf.New(sha512.New, secret, []byte(salt), []byte(info))
+	if _, err := io.ReadFull(r, key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// nonce 前4字节为0，后8字节为小端序的计数或配对消息的标识（如 PS-Msg05）
+func counterNonce(n uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[4:], n)
+	return nonce
+}
+
+func labelNonce(label string) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	copy(nonce[4:], label)
+	return nonce
+}
+
+// sealLabel 使用固定nonce加密配对消息中的子TLV
+func sealLabel(key []byte, label string, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, labelNonce(label), plaintext, nil), nil
+}
+
+func openLabel(key []byte, label string, ciphertext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, labelNonce(label), ciphertext, nil)
+}
+
+// secureConn 配对验证后的加密连接，数据按帧加密：2字节小端序长度（作为附加数据）+ 密文 + 16字节tag
+type secureConn struct {
+	net.Conn
+
+	readMu    sync.Mutex
+	readKey   []byte
+	readCount uint64
+	readBuf   []byte
+
+	writeMu    sync.Mutex
+	writeKey   []byte
+	writeCount uint64
+}
+
+func newSecureConn(conn net.Conn, readKey, writeKey []byte) *secureConn {
+	return &secureConn{Conn: conn, readKey: readKey, writeKey: writeKey}
+}
+
+func (c *secureConn) Read(b []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+	if len(c.readBuf) == 0 {
+		frame, err := c.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		c.readBuf = frame
+	}
+	n := copy(b, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+func (c *secureConn) readFrame() ([]byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.Conn, head[:]); err != nil {
+		return nil, err
+	}
+	length := int(binary.LittleEndian.Uint16(head[:]))
+	if length > maxFrameLength {
+		return nil, errFrameTooLarge
+	}
+	ciphertext := make([]byte, length+tagSize)
+	if _, err := io.ReadFull(c.Conn, ciphertext); err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(c.readKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, counterNonce(c.readCount), ciphertext, head[:])
+	if err != nil {
+		return nil, err
+	}
+	c.readCount++
+	return plaintext, nil
+}
+
+func (c *secureConn) Write(b []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	aead, err := chacha20poly1305.New(c.writeKey)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		total int
+		out   []byte
+	)
+	for len(b) > 0 {
+		n := len(b)
+		if n > maxFrameLength {
+			n = maxFrameLength
+		}
+		var head [2]byte
+		binary.LittleEndian.PutUint16(head[:], uint16(n))
+		out = append(out, head[:]...)
+		out = aead.Seal(out, counterNonce(c.writeCount), b[:n], head[:])
+		c.writeCount++
+		total += n
+		b = b[n:]
+	}
+	if _, err = c.Conn.Write(out); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
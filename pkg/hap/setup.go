@@ -0,0 +1,91 @@
+package hap
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Category 配件的类别，决定家庭App中配对时显示的图标
+type Category uint8
+
+const (
+	CategoryOther  Category = 1
+	CategoryBridge Category = 2
+)
+
+var setupCodeRegex = regexp.MustCompile(`^\d{3}-\d{2}-\d{3}$`)
+
+// 规范不允许使用的设置码
+var invalidSetupCodes = map[string]bool{
+	"00000000": true, "11111111": true, "22222222": true, "33333333": true,
+	"44444444": true, "55555555": true, "66666666": true, "77777777": true,
+	"88888888": true, "99999999": true, "12345678": true, "87654321": true,
+}
+
+// ValidSetupCode 校验设置码，格式为 XXX-XX-XXX
+func ValidSetupCode(code string) bool {
+	return setupCodeRegex.MatchString(code) && !invalidSetupCodes[strings.ReplaceAll(code, "-", "")]
+}
+
+// GenerateSetupCode 生成随机的设置码
+func GenerateSetupCode() string {
+	for {
+		n, err := rand.Int(rand.Reader, big.NewInt(100000000))
+		if err != nil {
+			panic(err)
+		}
+		s := fmt.Sprintf("%08d", n.Int64())
+		code := s[:3] + "-" + s[3:5] + "-" + s[5:]
+		if ValidSetupCode(code) {
+			return code
+		}
+	}
+}
+
+const setupIDChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// GenerateSetupID 生成4位的设置ID，用于设置码二维码及Bonjour中的sh
+func GenerateSetupID() string {
+	b := randomBytes(4)
+	for i := range b {
+		b[i] = setupIDChars[int(b[i])%len(setupIDChars)]
+	}
+	return string(b)
+}
+
+// GenerateDeviceID 生成配件的设备ID，格式同MAC地址，设备ID变化后控制器视为新的配件
+func GenerateDeviceID() string {
+	b := randomBytes(6)
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[i] = fmt.Sprintf("%02X", v)
+	}
+	return strings.Join(parts, ":")
+}
+
+// SetupURI 设置码的二维码内容，如 X-HM://0023ISYWY1234
+func SetupURI(setupCode, setupID string, category Category) string {
+	code, _ := strconv.ParseUint(strings.ReplaceAll(setupCode, "-", ""), 10, 64)
+	const flagIP = 2
+	var payload uint64
+	payload |= uint64(category)
+	payload = payload<<4 | flagIP
+	payload = payload<<27 | code&0x7FFFFFF
+	s := strings.ToUpper(strconv.FormatUint(payload, 36))
+	if len(s) < 9 {
+		s = strings.Repeat("0", 9-len(s)) + s
+	}
+	return "X-HM://" + s + setupID
+}
+
+// setupHash Bonjour中的sh，控制器扫描二维码后用于查找配件
+func setupHash(setupID, deviceID string) string {
+	h := sha512.Sum512([]byte(setupID + deviceID))
+	return base64.StdEncoding.EncodeToString(h[:4])
+}
@@ -0,0 +1,175 @@
+package hap
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+)
+
+// HAP 配对使用 SRP-6a，3072位的组（RFC 5054），哈希算法为SHA-512，用户名固定为 Pair-Setup
+const srpUsername = "Pair-Setup"
+
+var (
+	srpN, _ = new(big.Int).SetString(""+
+		"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E088A67CC74"+
+		"020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B302B0A6DF25F1437"+
+		"4FE1356D6D51C245E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE45B3DC2007CB8A163BF05"+
+		"98DA48361C55D39A69163FA8FD24CF5F83655D23DCA3AD961C62F356208552BB"+
+		"9ED529077096966D670C354E4ABC9804F1746C08CA18217C32905E462E36CE3B"+
+		"E39E772C180E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF695581718"+
+		"3995497CEA956AE515D2261898FA051015728E5A8AAAC42DAD33170D04507A33"+
+		"A85521ABDF1CBA64ECFB850458DBEF0A8AEA71575D060C7DB3970F85A6E1E4C7"+
+		"ABF5AE8CDB0933D71E8C94E04A25619DCEE3D2261AD2EE6BF12FFA06D98A0864"+
+		"D87602733EC86A64521F2B18177B200CBBE117577A615D6C770988C0BAD946E2"+
+		"08E24FA074E5AB3143DB5BFCE0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF", 16)
+	srpG = big.NewInt(5)
+
+	errSRPPublicKey = errors.New("srp: invalid public key")
+)
+
+func srpHash(data ...[]byte) []byte {
+	h := sha512.New()
+	for _, d := range data {
+		h.Write(d)
+	}
+	return h.Sum(nil)
+}
+
+// srpPad 左侧补零到N的长度
+func srpPad(x *big.Int) []byte {
+	b := x.Bytes()
+	n := (srpN.BitLen() + 7) / 8
+	if len(b) >= n {
+		return b
+	}
+	return append(make([]byte, n-len(b)), b...)
+}
+
+func srpInt(b []byte) *big.Int {
+	return new(big.Int).SetBytes(b)
+}
+
+// srpK k = H(N | PAD(g))
+func srpK() *big.Int {
+	return srpInt(srpHash(srpN.Bytes(), srpPad(srpG)))
+}
+
+// srpX x = H(s | H(I | ":" | P))
+func srpX(salt []byte, password string) *big.Int {
+	return srpInt(srpHash(salt, srpHash([]byte(srpUsername+":"+password))))
+}
+
+// srpU u = H(PAD(A) | PAD(B))
+func srpU(A, B *big.Int) *big.Int {
+	return srpInt(srpHash(srpPad(A), srpPad(B)))
+}
+
+// srpM1 M1 = H(H(N) xor H(g) | H(I) | s | A | B | K)
+func srpM1(salt []byte, A, B *big.Int, K []byte) []byte {
+	hn, hg := srpHash(srpN.Bytes()), srpHash(srpG.Bytes())
+	for i := range hn {
+		hn[i] ^= hg[i]
+	}
+	return srpHash(hn, srpHash([]byte(srpUsername)), salt, A.Bytes(), B.Bytes(), K)
+}
+
+// srpM2 M2 = H(A | M1 | K)
+func srpM2(A *big.Int, m1, K []byte) []byte {
+	return srpHash(A.Bytes(), m1, K)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// srpServer 配件一侧的SRP会话
+type srpServer struct {
+	salt []byte
+	v    *big.Int
+	b    *big.Int
+	B    *big.Int
+	A    *big.Int
+	K    []byte
+}
+
+// newSRPServer 使用设置码生成验证值及配件的公钥
+func newSRPServer(password string) *srpServer {
+	s := &srpServer{salt: randomBytes(16)}
+	s.v = new(big.Int).Exp(srpG, srpX(s.salt, password), srpN)
+	s.b = srpInt(randomBytes(32))
+	// B = k*v + g^b
+	s.B = new(big.Int).Mul(srpK(), s.v)
+	s.B.Add(s.B, new(big.Int).Exp(srpG, s.b, srpN))
+	s.B.Mod(s.B, srpN)
+	return s
+}
+
+// PublicKey 配件的公钥B
+func (s *srpServer) PublicKey() []byte {
+	return s.B.Bytes()
+}
+
+// Verify 校验控制器的公钥A及证明M1，通过后返回配件的证明M2
+func (s *srpServer) Verify(a, m1 []byte) ([]byte, error) {
+	A := srpInt(a)
+	if new(big.Int).Mod(A, srpN).Sign() == 0 {
+		return nil, errSRPPublicKey
+	}
+	s.A = A
+	// S = (A * v^u) ^ b
+	S := new(big.Int).Exp(s.v, srpU(A, s.B), srpN)
+	S.Mul(S, A)
+	S.Exp(S, s.b, srpN)
+	s.K = srpHash(S.Bytes())
+	if subtle.ConstantTimeCompare(srpM1(s.salt, A, s.B, s.K), m1) != 1 {
+		return nil, errors.New("srp: incorrect proof")
+	}
+	return srpM2(A, m1, s.K), nil
+}
+
+// srpClient 控制器一侧的SRP会话，用于测试及调试
+type srpClient struct {
+	a  *big.Int
+	A  *big.Int
+	K  []byte
+	M1 []byte
+}
+
+func newSRPClient() *srpClient {
+	c := &srpClient{a: srpInt(randomBytes(32))}
+	c.A = new(big.Int).Exp(srpG, c.a, srpN)
+	return c
+}
+
+// Compute 根据配件的盐及公钥计算证明M1
+func (c *srpClient) Compute(salt, b []byte, password string) ([]byte, error) {
+	B := srpInt(b)
+	if new(big.Int).Mod(B, srpN).Sign() == 0 {
+		return nil, errSRPPublicKey
+	}
+	x := srpX(salt, password)
+	u := srpU(c.A, B)
+	// S = (B - k * g^x) ^ (a + u * x)
+	base := new(big.Int).Exp(srpG, x, srpN)
+	base.Mul(base, srpK())
+	base.Sub(B, base)
+	base.Mod(base, srpN)
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, c.a)
+	S := new(big.Int).Exp(base, exp, srpN)
+	c.K = srpHash(S.Bytes())
+	c.M1 = srpM1(salt, c.A, B, c.K)
+	return c.M1, nil
+}
+
+// VerifyServer 校验配件的证明M2
+func (c *srpClient) VerifyServer(m2 []byte) bool {
+	return subtle.ConstantTimeCompare(srpM2(c.A, c.M1, c.K), m2) == 1
+}
@@ -0,0 +1,51 @@
+package hap
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotFound 存储中不存在该键
+var ErrNotFound = errors.New("hap: not found")
+
+// Store 持久化配件的长期密钥、配对的控制器及配置版本，重启后需要保持不变
+type Store interface {
+	// Get 返回键的值，不存在时返回 ErrNotFound
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte) error
+	Delete(key string) error
+}
+
+// MemStore 内存存储，用于测试
+type MemStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{data: make(map[string][]byte)}
+}
+
+func (s *MemStore) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return v, nil
+}
+
+func (s *MemStore) Set(key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = value
+	return nil
+}
+
+func (s *MemStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+	return nil
+}
@@ -0,0 +1,135 @@
+package hap
+
+import (
+	"errors"
+)
+
+// TLV8 类型，配对相关请求及响应使用TLV8编码
+const (
+	TLVMethod        byte = 0x00
+	TLVIdentifier    byte = 0x01
+	TLVSalt          byte = 0x02
+	TLVPublicKey     byte = 0x03
+	TLVProof         byte = 0x04
+	TLVEncryptedData byte = 0x05
+	TLVState         byte = 0x06
+	TLVError         byte = 0x07
+	TLVRetryDelay    byte = 0x08
+	TLVCertificate   byte = 0x09
+	TLVSignature     byte = 0x0A
+	TLVPermissions   byte = 0x0B
+	TLVFragmentData  byte = 0x0C
+	TLVFragmentLast  byte = 0x0D
+	TLVFlags         byte = 0x13
+	TLVSeparator     byte = 0xFF
+)
+
+// 配对的错误码
+const (
+	TLVErrorUnknown        byte = 0x01
+	TLVErrorAuthentication byte = 0x02
+	TLVErrorBackoff        byte = 0x03
+	TLVErrorMaxPeers       byte = 0x04
+	TLVErrorMaxTries       byte = 0x05
+	TLVErrorUnavailable    byte = 0x06
+	TLVErrorBusy           byte = 0x07
+)
+
+// 配对的方法
+const (
+	MethodPairSetup         byte = 0x00
+	MethodPairSetupWithAuth byte = 0x01
+	MethodPairVerify        byte = 0x02
+	MethodAddPairing        byte = 0x03
+	MethodRemovePairing     byte = 0x04
+	MethodListPairings      byte = 0x05
+)
+
+var errTLVTruncated = errors.New("tlv8: truncated")
+
+type tlvItem struct {
+	typ   byte
+	value []byte
+}
+
+// TLV8 有序的TLV8条目，同一类型可以出现多次（如配对列表使用分隔符隔开）
+type TLV8 struct {
+	items []tlvItem
+}
+
+// Set 添加条目
+func (t *TLV8) Set(typ byte, value []byte) *TLV8 {
+	t.items = append(t.items, tlvItem{typ: typ, value: value})
+	return t
+}
+
+// SetByte 添加单字节的条目
+func (t *TLV8) SetByte(typ byte, b byte) *TLV8 {
+	return t.Set(typ, []byte{b})
+}
+
+// Get 返回第一个该类型条目的值
+func (t TLV8) Get(typ byte) []byte {
+	for _, item := range t.items {
+		if item.typ == typ {
+			return item.value
+		}
+	}
+	return nil
+}
+
+// GetByte 返回第一个该类型条目的单字节值
+func (t TLV8) GetByte(typ byte) (byte, bool) {
+	v := t.Get(typ)
+	if len(v) != 1 {
+		return 0, false
+	}
+	return v[0], true
+}
+
+// Encode 编码，超过255字节的值拆分为多个相同类型的连续条目
+func (t TLV8) Encode() []byte {
+	var b []byte
+	for _, item := range t.items {
+		v := item.value
+		if len(v) == 0 {
+			b = append(b, item.typ, 0)
+			continue
+		}
+		for len(v) > 0 {
+			n := len(v)
+			if n > 255 {
+				n = 255
+			}
+			b = append(b, item.typ, byte(n))
+			b = append(b, v[:n]...)
+			v = v[n:]
+		}
+	}
+	return b
+}
+
+// DecodeTLV8 解码，相同类型的连续条目合并为一个条目
+func DecodeTLV8(b []byte) (t TLV8, err error) {
+	var last = -1
+	for len(b) > 0 {
+		if len(b) < 2 {
+			return t, errTLVTruncated
+		}
+		typ, n := b[0], int(b[1])
+		if len(b) < 2+n {
+			return t, errTLVTruncated
+		}
+		v := b[2 : 2+n]
+		b = b[2+n:]
+		// 上一个条目满255字节时，相同类型的条目为其后续分片
+		if last >= 0 && t.items[last].typ == typ && len(t.items[last].value)%255 == 0 &&
+			len(t.items[last].value) > 0 {
+			t.items[last].value = append(t.items[last].value, v...)
+			continue
+		}
+		t.items = append(t.items, tlvItem{typ: typ, value: append([]byte(nil), v...)})
+		last = len(t.items) - 1
+	}
+	return
+}
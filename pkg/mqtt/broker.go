@@ -0,0 +1,198 @@
+package mqtt
+
+import (
+	"bufio"
+	"net"
+	"sync"
+)
+
+// Broker 内嵌的简单MQTT服务器，支持订阅、保留消息及遗嘱消息，用于测试及本地调试
+type Broker struct {
+	ln       net.Listener
+	username string
+	password string
+
+	mu       sync.Mutex
+	conns    map[net.Conn]*brokerClient
+	retained map[string]Message
+}
+
+type brokerClient struct {
+	id   string
+	will *Message
+	subs map[string]byte
+	mu   sync.Mutex
+}
+
+// NewBroker 在ln上接受连接，客户端提供用户名或密码时需要与username、password一致
+func NewBroker(ln net.Listener, username, password string) *Broker {
+	b := &Broker{
+		ln:       ln,
+		username: username,
+		password: password,
+		conns:    make(map[net.Conn]*brokerClient),
+		retained: make(map[string]Message),
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// Addr 服务器的地址，如 tcp://127.0.0.1:1883
+func (b *Broker) Addr() string {
+	return "tcp://" + b.ln.Addr().String()
+}
+
+// Close 停止接受连接并断开所有客户端
+func (b *Broker) Close() error {
+	err := b.ln.Close()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.conns {
+		conn.Close()
+	}
+	return err
+}
+
+// Retained 主题的保留消息
+func (b *Broker) Retained(topic string) (msg Message, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	msg, ok = b.retained[topic]
+	return
+}
+
+// Drop 异常断开客户端的连接，服务器发布该客户端的遗嘱消息
+func (b *Broker) Drop(clientID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn, bc := range b.conns {
+		if bc.id == clientID {
+			conn.Close()
+		}
+	}
+}
+
+func (b *Broker) serve(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil || p.typ != packetConnect {
+		conn.Close()
+		return
+	}
+	rd := reader{b: p.body}
+	rd.string()
+	rd.byte()
+	flags := rd.byte()
+	rd.uint16()
+	bc := &brokerClient{id: rd.string(), subs: make(map[string]byte)}
+	if flags&0x04 != 0 {
+		bc.will = &Message{Topic: rd.string(), Payload: rd.bytes(), QoS: flags >> 3 & 0x03, Retained: flags&0x20 != 0}
+	}
+	if flags&0x80 != 0 && rd.string() != b.username || flags&0x40 != 0 && rd.string() != b.password {
+		writePacket(conn, packet{typ: packetConnack, body: []byte{0, 4}})
+		conn.Close()
+		return
+	}
+	b.mu.Lock()
+	b.conns[conn] = bc
+	b.mu.Unlock()
+	b.write(bc, conn, packet{typ: packetConnack, body: []byte{0, 0}})
+
+	graceful := false
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+		conn.Close()
+		if !graceful && bc.will != nil {
+			b.publish(*bc.will)
+		}
+	}()
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+		switch p.typ {
+		case packetSubscribe:
+			rd := reader{b: p.body}
+			id := rd.uint16()
+			filter, qos := rd.string(), rd.byte()
+			bc.mu.Lock()
+			bc.subs[filter] = qos
+			bc.mu.Unlock()
+			b.write(bc, conn, packet{typ: packetSuback, body: append(appendUint16(nil, id), qos)})
+			b.mu.Lock()
+			for _, msg := range b.retained {
+				if Match(filter, msg.Topic) {
+					msg.QoS = 0
+					b.write(bc, conn, publishPacket(msg, 0, false))
+				}
+			}
+			b.mu.Unlock()
+		case packetPublish:
+			msg, id, _ := decodePublish(p)
+			switch msg.QoS {
+			case 1:
+				b.write(bc, conn, idPacket(packetPuback, id))
+			case 2:
+				b.write(bc, conn, idPacket(packetPubrec, id))
+			}
+			b.publish(msg)
+		case packetPubrel:
+			id, _ := packetID(p)
+			b.write(bc, conn, idPacket(packetPubcomp, id))
+		case packetPingreq:
+			b.write(bc, conn, packet{typ: packetPingresp})
+		case packetDisconnect:
+			graceful = true
+			return
+		}
+	}
+}
+
+func (b *Broker) write(bc *brokerClient, conn net.Conn, p packet) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	writePacket(conn, p)
+}
+
+// publish 转发消息给订阅的客户端，保留消息的内容为空时删除该主题的保留消息
+func (b *Broker) publish(msg Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if msg.Retained {
+		if len(msg.Payload) == 0 {
+			delete(b.retained, msg.Topic)
+		} else {
+			b.retained[msg.Topic] = msg
+		}
+	}
+	msg.Retained = false
+	for conn, bc := range b.conns {
+		bc.mu.Lock()
+		qos, ok := byte(0), false
+		for filter, q := range bc.subs {
+			if Match(filter, msg.Topic) {
+				qos, ok = q, true
+			}
+		}
+		bc.mu.Unlock()
+		if !ok {
+			continue
+		}
+		m := msg
+		if m.QoS > qos {
+			m.QoS = qos
+		}
+		b.write(bc, conn, publishPacket(m, 1, false))
+	}
+}
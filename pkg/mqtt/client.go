@@ -0,0 +1,494 @@
+// Package mqtt MQTT 3.1.1 客户端，支持TLS、遗嘱消息、QoS 0-2 及断线重连
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+)
+
+var (
+	ErrNotConnected = errors.New("mqtt: not connected")
+	ErrClosed       = errors.New("mqtt: client closed")
+	ErrTimeout      = errors.New("mqtt: wait for acknowledgement timeout")
+	ErrInvalidQoS   = errors.New("mqtt: invalid qos")
+)
+
+const (
+	defaultKeepAlive      = 60 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+	defaultAckTimeout     = 10 * time.Second
+	maxReconnectInterval  = 2 * time.Minute
+)
+
+// Options 客户端选项
+type Options struct {
+	// Broker 服务器地址，如 tcp://127.0.0.1:1883，ssl://、tls:// 或 mqtts:// 使用TLS连接
+	Broker    string
+	ClientID  string
+	Username  string
+	Password  string
+	TLSConfig *tls.Config
+	// Will 遗嘱消息，连接异常断开时由服务器发布
+	Will *Message
+
+	KeepAlive      time.Duration
+	ConnectTimeout time.Duration
+	AckTimeout     time.Duration // 等待QoS 1、2消息及订阅确认的超时时间
+
+	// OnConnect 每次连接（包括重连）成功并恢复订阅后调用
+	OnConnect func(c *Client)
+	// OnConnectionLost 连接断开时调用
+	OnConnectionLost func(err error)
+}
+
+// Handler 处理订阅收到的消息，按收到的顺序调用，耗时的操作应在其他goroutine中执行
+type Handler func(c *Client, msg Message)
+
+type subscription struct {
+	qos     byte
+	handler Handler
+}
+
+// Client MQTT客户端，连接断开后自动重连，由于总是清除会话，重连后重新订阅
+type Client struct {
+	opts Options
+	addr string
+	tls  bool
+
+	mu        sync.Mutex
+	conn      net.Conn
+	connected bool
+	nextID    uint16
+	inflight  map[uint16]chan packet
+	received  map[uint16]bool // 已收到但未释放的QoS 2消息
+	subs      map[string]subscription
+
+	writeMu sync.Mutex
+	once    sync.Once
+	done    chan struct{}
+}
+
+// NewClient 创建客户端，调用 Start 后开始连接
+func NewClient(opts Options) (*Client, error) {
+	u, err := url.Parse(opts.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("mqtt: invalid broker %s: %w", opts.Broker, err)
+	}
+	c := &Client{
+		opts:     opts,
+		addr:     u.Host,
+		inflight: make(map[uint16]chan packet),
+		received: make(map[uint16]bool),
+		subs:     make(map[string]subscription),
+		done:     make(chan struct{}),
+	}
+	port := "1883"
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		c.tls = true
+		port = "8883"
+	default:
+		return nil, fmt.Errorf("mqtt: unsupported scheme %s", u.Scheme)
+	}
+	if u.Port() == "" {
+		c.addr = net.JoinHostPort(u.Hostname(), port)
+	}
+	if opts.Will != nil && (!ValidTopic(opts.Will.Topic) || opts.Will.QoS > 2) {
+		return nil, ErrInvalidTopic
+	}
+	if c.opts.KeepAlive <= 0 {
+		c.opts.KeepAlive = defaultKeepAlive
+	}
+	if c.opts.ConnectTimeout <= 0 {
+		c.opts.ConnectTimeout = defaultConnectTimeout
+	}
+	if c.opts.AckTimeout <= 0 {
+		c.opts.AckTimeout = defaultAckTimeout
+	}
+	return c, nil
+}
+
+// Start 在后台连接服务器，连接失败或断开后按指数退避重连
+func (c *Client) Start() {
+	go c.run()
+}
+
+// IsConnected 是否已连接
+func (c *Client) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connected
+}
+
+// Close 断开连接并停止重连，正常断开时服务器不发布遗嘱消息
+func (c *Client) Close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.mu.Lock()
+		conn, connected := c.conn, c.connected
+		c.mu.Unlock()
+		if connected {
+			c.write(conn, packet{typ: packetDisconnect})
+			conn.Close()
+		}
+	})
+}
+
+func (c *Client) closed() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Client) run() {
+	interval := time.Second
+	for {
+		conn, err := c.connect()
+		if err == nil && c.closed() {
+			conn.Close()
+			return
+		}
+		if err == nil {
+			interval = time.Second
+			err = c.serve(conn)
+			if c.opts.OnConnectionLost != nil && !c.closed() {
+				c.opts.OnConnectionLost(err)
+			}
+		} else {
+			logger.Warnf("mqtt: connect to %s err: %s", c.addr, err)
+		}
+		select {
+		case <-c.done:
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > maxReconnectInterval {
+			interval = maxReconnectInterval
+		}
+	}
+}
+
+// connect 建立连接并完成CONNECT握手
+func (c *Client) connect() (conn net.Conn, err error) {
+	dialer := &net.Dialer{Timeout: c.opts.ConnectTimeout}
+	if c.tls {
+		cfg := c.opts.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{}
+		}
+		conn, err = tls.DialWithDialer(dialer, "tcp", c.addr, cfg)
+	} else {
+		conn, err = dialer.Dial("tcp", c.addr)
+	}
+	if err != nil {
+		return
+	}
+	raw := conn
+	defer func() {
+		if err != nil {
+			raw.Close()
+		}
+	}()
+
+	conn.SetDeadline(time.Now().Add(c.opts.ConnectTimeout))
+	keepAlive := uint16(c.opts.KeepAlive / time.Second)
+	if err = writePacket(conn, connectPacket(c.opts, keepAlive)); err != nil {
+		return
+	}
+	// CONNACK固定为4字节，不能使用缓冲读取以免读取后续的报文
+	connack := make([]byte, 4)
+	if _, err = io.ReadFull(conn, connack); err != nil {
+		return
+	}
+	if connack[0] != packetConnack<<4 || connack[1] != 2 {
+		return nil, errMalformedPacket
+	}
+	if connack[3] != 0 {
+		return nil, ConnectError(connack[3])
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// serve 处理连接上的报文直到连接断开
+func (c *Client) serve(conn net.Conn) error {
+	c.mu.Lock()
+	c.conn, c.connected = conn, true
+	c.received = make(map[uint16]bool)
+	c.mu.Unlock()
+
+	messages := make(chan Message, 100)
+	stop := make(chan struct{})
+	defer func() {
+		c.mu.Lock()
+		c.connected = false
+		for id, ch := range c.inflight {
+			close(ch)
+			delete(c.inflight, id)
+		}
+		c.mu.Unlock()
+		close(stop)
+		conn.Close()
+	}()
+	go c.dispatch(messages, stop)
+	go c.keepAlive(conn, stop)
+	go c.resubscribe()
+
+	r := bufio.NewReader(conn)
+	for {
+		// 超过1.5倍保活时间没有收到报文则认为连接断开
+		conn.SetReadDeadline(time.Now().Add(c.opts.KeepAlive * 3 / 2))
+		p, err := readPacket(r)
+		if err != nil {
+			return err
+		}
+		switch p.typ {
+		case packetPublish:
+			msg, id, err := decodePublish(p)
+			if err != nil {
+				return err
+			}
+			switch msg.QoS {
+			case 0:
+				messages <- msg
+			case 1:
+				messages <- msg
+				c.write(conn, idPacket(packetPuback, id))
+			case 2:
+				c.mu.Lock()
+				dup := c.received[id]
+				c.received[id] = true
+				c.mu.Unlock()
+				if !dup {
+					messages <- msg
+				}
+				c.write(conn, idPacket(packetPubrec, id))
+			}
+		case packetPubrel:
+			id, err := packetID(p)
+			if err != nil {
+				return err
+			}
+			c.mu.Lock()
+			delete(c.received, id)
+			c.mu.Unlock()
+			c.write(conn, idPacket(packetPubcomp, id))
+		case packetPubrec:
+			id, err := packetID(p)
+			if err != nil {
+				return err
+			}
+			c.write(conn, idPacket(packetPubrel, id))
+		case packetPuback, packetPubcomp, packetSuback, packetUnsuback:
+			id, err := packetID(p)
+			if err != nil {
+				return err
+			}
+			c.mu.Lock()
+			ch, ok := c.inflight[id]
+			delete(c.inflight, id)
+			c.mu.Unlock()
+			if ok {
+				ch <- p
+			}
+		case packetPingresp:
+		default:
+			return fmt.Errorf("mqtt: unexpected packet type %d", p.typ)
+		}
+	}
+}
+
+// dispatch 按收到的顺序调用订阅的处理函数
+func (c *Client) dispatch(messages chan Message, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case msg := <-messages:
+			c.mu.Lock()
+			var handlers []Handler
+			for filter, sub := range c.subs {
+				if Match(filter, msg.Topic) {
+					handlers = append(handlers, sub.handler)
+				}
+			}
+			c.mu.Unlock()
+			for _, h := range handlers {
+				h(c, msg)
+			}
+		}
+	}
+}
+
+func (c *Client) keepAlive(conn net.Conn, stop chan struct{}) {
+	ticker := time.NewTicker(c.opts.KeepAlive * 3 / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := c.write(conn, packet{typ: packetPingreq}); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// resubscribe 连接后恢复订阅并调用 OnConnect
+func (c *Client) resubscribe() {
+	c.mu.Lock()
+	subs := make(map[string]byte, len(c.subs))
+	for filter, sub := range c.subs {
+		subs[filter] = sub.qos
+	}
+	c.mu.Unlock()
+	for filter, qos := range subs {
+		if err := c.subscribe(filter, qos); err != nil {
+			logger.Warnf("mqtt: subscribe %s err: %s", filter, err)
+		}
+	}
+	if c.opts.OnConnect != nil {
+		c.opts.OnConnect(c)
+	}
+}
+
+func (c *Client) write(conn net.Conn, p packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(c.opts.AckTimeout))
+	return writePacket(conn, p)
+}
+
+// send 发送需要确认的报文，返回确认报文
+func (c *Client) send(build func(id uint16) packet) (packet, error) {
+	c.mu.Lock()
+	if !c.connected {
+		c.mu.Unlock()
+		return packet{}, ErrNotConnected
+	}
+	conn := c.conn
+	for {
+		c.nextID++
+		if _, ok := c.inflight[c.nextID]; c.nextID != 0 && !ok {
+			break
+		}
+	}
+	id := c.nextID
+	ch := make(chan packet, 1)
+	c.inflight[id] = ch
+	c.mu.Unlock()
+
+	if err := c.write(conn, build(id)); err != nil {
+		conn.Close()
+		return packet{}, err
+	}
+	timer := time.NewTimer(c.opts.AckTimeout)
+	defer timer.Stop()
+	select {
+	case p, ok := <-ch:
+		if !ok {
+			return packet{}, ErrNotConnected
+		}
+		return p, nil
+	case <-timer.C:
+		c.mu.Lock()
+		delete(c.inflight, id)
+		c.mu.Unlock()
+		return packet{}, ErrTimeout
+	case <-c.done:
+		return packet{}, ErrClosed
+	}
+}
+
+// Publish 发布消息，QoS为1、2时等待服务器确认，未连接时返回 ErrNotConnected
+func (c *Client) Publish(topic string, qos byte, retained bool, payload []byte) error {
+	if !ValidTopic(topic) {
+		return ErrInvalidTopic
+	}
+	if qos > 2 {
+		return ErrInvalidQoS
+	}
+	msg := Message{Topic: topic, Payload: payload, QoS: qos, Retained: retained}
+	if qos == 0 {
+		c.mu.Lock()
+		conn, connected := c.conn, c.connected
+		c.mu.Unlock()
+		if !connected {
+			return ErrNotConnected
+		}
+		return c.write(conn, publishPacket(msg, 0, false))
+	}
+	_, err := c.send(func(id uint16) packet {
+		return publishPacket(msg, id, false)
+	})
+	return err
+}
+
+// Subscribe 订阅主题，未连接时在连接后订阅，重连后自动恢复订阅
+func (c *Client) Subscribe(filter string, qos byte, h Handler) error {
+	if !ValidFilter(filter) {
+		return ErrInvalidFilter
+	}
+	if qos > 2 {
+		return ErrInvalidQoS
+	}
+	c.mu.Lock()
+	c.subs[filter] = subscription{qos: qos, handler: h}
+	connected := c.connected
+	c.mu.Unlock()
+	if !connected {
+		return nil
+	}
+	return c.subscribe(filter, qos)
+}
+
+func (c *Client) subscribe(filter string, qos byte) error {
+	p, err := c.send(func(id uint16) packet {
+		body := appendUint16(nil, id)
+		body = appendString(body, filter)
+		body = append(body, qos)
+		return packet{typ: packetSubscribe, flags: 0x02, body: body}
+	})
+	if err != nil {
+		return err
+	}
+	if p.typ != packetSuback || len(p.body) != 3 {
+		return errMalformedPacket
+	}
+	if p.body[2] == 0x80 {
+		return fmt.Errorf("mqtt: subscribe %s refused", filter)
+	}
+	return nil
+}
+
+// Unsubscribe 取消订阅
+func (c *Client) Unsubscribe(filter string) error {
+	c.mu.Lock()
+	delete(c.subs, filter)
+	connected := c.connected
+	c.mu.Unlock()
+	if !connected {
+		return nil
+	}
+	_, err := c.send(func(id uint16) packet {
+		body := appendUint16(nil, id)
+		body = appendString(body, filter)
+		return packet{typ: packetUnsubscribe, flags: 0x02, body: body}
+	})
+	return err
+}
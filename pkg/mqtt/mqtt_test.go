@@ -0,0 +1,178 @@
+package mqtt
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		filter, topic string
+		match         bool
+	}{
+		{"sa/1/2/0x01/on_off", "sa/1/2/0x01/on_off", true},
+		{"sa/+/+/+/+/set", "sa/1/2/0x01/on_off/set", true},
+		{"sa/+/+/+/+/set", "sa/1/2/0x01/on_off", false},
+		{"sa/#", "sa", true},
+		{"sa/#", "sa/1/status", true},
+		{"#", "$SYS/uptime", false},
+		{"sa/+", "sa/1/status", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.match, Match(c.filter, c.topic), c.filter+" "+c.topic)
+	}
+	assert.True(t, ValidFilter("sa/+/status"))
+	assert.False(t, ValidFilter("sa/#/status"))
+	assert.False(t, ValidFilter("sa/a+"))
+	assert.False(t, ValidTopic("sa/+"))
+}
+
+func TestPacket(t *testing.T) {
+	msg := Message{Topic: "sa/1/status", Payload: make([]byte, 20000), QoS: 1, Retained: true}
+	msg.Payload[0] = 'x'
+	p := publishPacket(msg, 42, false)
+
+	r, w := net.Pipe()
+	go writePacket(w, p)
+	got, err := readPacket(bufio.NewReader(r))
+	require.Nil(t, err)
+	decoded, id, err := decodePublish(got)
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(42), id)
+	assert.Equal(t, msg, decoded)
+}
+
+func receive(t *testing.T, ch chan Message) Message {
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("receive message timeout")
+	}
+	return Message{}
+}
+
+func TestClient(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	broker := NewBroker(ln, "user", "pass")
+	defer broker.Close()
+	url := broker.Addr()
+
+	// 用户名密码错误时拒绝连接
+	c, err := NewClient(Options{Broker: url, ClientID: "bad", Username: "user", Password: "wrong"})
+	require.Nil(t, err)
+	_, err = c.connect()
+	assert.Equal(t, ConnectError(4), err)
+
+	messages := make(chan Message, 10)
+	sub, err := NewClient(Options{Broker: url, ClientID: "sub"})
+	require.Nil(t, err)
+	connected := make(chan struct{}, 1)
+	sub.opts.OnConnect = func(c *Client) { connected <- struct{}{} }
+	assert.Nil(t, sub.Subscribe("sa/#", 2, func(c *Client, msg Message) { messages <- msg }))
+	sub.Start()
+	defer sub.Close()
+	<-connected
+
+	connects := make(chan struct{}, 2)
+	pub, err := NewClient(Options{
+		Broker:   url,
+		ClientID: "pub",
+		Username: "user",
+		Password: "pass",
+		Will:     &Message{Topic: "sa/1/status", Payload: []byte("offline"), QoS: 1, Retained: true},
+		OnConnect: func(c *Client) {
+			assert.Nil(t, c.Publish("sa/1/status", 1, true, []byte("online")))
+			connects <- struct{}{}
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, ErrNotConnected, pub.Publish("sa/1/status", 0, false, nil))
+	pub.Start()
+	<-connects
+	assert.Equal(t, "online", string(receive(t, messages).Payload))
+
+	for qos := byte(0); qos <= 2; qos++ {
+		assert.Nil(t, pub.Publish("sa/1/2/0x01/on_off", qos, true, []byte{'0' + qos}))
+		msg := receive(t, messages)
+		assert.Equal(t, "sa/1/2/0x01/on_off", msg.Topic)
+		assert.Equal(t, qos, msg.QoS)
+		assert.Equal(t, []byte{'0' + qos}, msg.Payload)
+	}
+	assert.Equal(t, ErrInvalidTopic, pub.Publish("sa/+", 0, false, nil))
+	assert.Equal(t, ErrInvalidQoS, pub.Publish("sa/1", 3, false, nil))
+
+	// 异常断开时发布遗嘱消息，重连后恢复
+	broker.Drop("pub")
+	assert.Equal(t, "offline", string(receive(t, messages).Payload))
+	<-connects
+	assert.Equal(t, "online", string(receive(t, messages).Payload))
+
+	// 订阅者重连后恢复订阅并收到保留消息
+	broker.Drop("sub")
+	<-connected
+	retained := map[string]string{}
+	for i := 0; i < 2; i++ {
+		msg := receive(t, messages)
+		retained[msg.Topic] = string(msg.Payload)
+	}
+	assert.Equal(t, map[string]string{"sa/1/status": "online", "sa/1/2/0x01/on_off": "2"}, retained)
+
+	// 正常断开时不发布遗嘱消息
+	pub.Close()
+	assert.Eventually(t, func() bool { return !pub.IsConnected() }, time.Second, 10*time.Millisecond)
+	select {
+	case msg := <-messages:
+		t.Fatalf("unexpected message %s", msg.Payload)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestClientTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "broker"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	require.Nil(t, err)
+	defer NewBroker(ln, "", "").Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	c, err := NewClient(Options{Broker: "ssl://" + ln.Addr().String(), ClientID: "tls", TLSConfig: &tls.Config{RootCAs: pool}})
+	require.Nil(t, err)
+	conn, err := c.connect()
+	require.Nil(t, err)
+	conn.Close()
+
+	// 不信任服务器证书时连接失败
+	c, err = NewClient(Options{Broker: "ssl://" + ln.Addr().String(), ClientID: "tls"})
+	require.Nil(t, err)
+	_, err = c.connect()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,247 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 控制报文类型
+const (
+	packetConnect     byte = 1
+	packetConnack     byte = 2
+	packetPublish     byte = 3
+	packetPuback      byte = 4
+	packetPubrec      byte = 5
+	packetPubrel      byte = 6
+	packetPubcomp     byte = 7
+	packetSubscribe   byte = 8
+	packetSuback      byte = 9
+	packetUnsubscribe byte = 10
+	packetUnsuback    byte = 11
+	packetPingreq     byte = 12
+	packetPingresp    byte = 13
+	packetDisconnect  byte = 14
+)
+
+// 报文剩余长度的最大值
+const maxRemainingLength = 268435455
+
+var errMalformedPacket = errors.New("mqtt: malformed packet")
+
+// packet 控制报文，body为可变报头及有效载荷
+type packet struct {
+	typ   byte
+	flags byte
+	body  []byte
+}
+
+func readPacket(r *bufio.Reader) (p packet, err error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return
+	}
+	p.typ, p.flags = b>>4, b&0x0f
+
+	var length, multiplier int
+	for i := 0; ; i++ {
+		if i == 4 {
+			return p, errMalformedPacket
+		}
+		if b, err = r.ReadByte(); err != nil {
+			return
+		}
+		length += int(b&0x7f) << multiplier
+		multiplier += 7
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	p.body = make([]byte, length)
+	_, err = io.ReadFull(r, p.body)
+	return
+}
+
+func writePacket(w io.Writer, p packet) error {
+	length := len(p.body)
+	if length > maxRemainingLength {
+		return fmt.Errorf("mqtt: packet too large: %d", length)
+	}
+	buf := make([]byte, 0, length+5)
+	buf = append(buf, p.typ<<4|p.flags)
+	for {
+		b := byte(length & 0x7f)
+		length >>= 7
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			break
+		}
+	}
+	buf = append(buf, p.body...)
+	_, err := w.Write(buf)
+	return err
+}
+
+func appendUint16(b []byte, v uint16) []byte {
+	return append(b, byte(v>>8), byte(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	b = appendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// reader 按顺序读取报文的字段
+type reader struct {
+	b   []byte
+	err error
+}
+
+func (r *reader) uint16() uint16 {
+	if r.err != nil || len(r.b) < 2 {
+		r.err = errMalformedPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint16(r.b)
+	r.b = r.b[2:]
+	return v
+}
+
+func (r *reader) byte() byte {
+	if r.err != nil || len(r.b) < 1 {
+		r.err = errMalformedPacket
+		return 0
+	}
+	v := r.b[0]
+	r.b = r.b[1:]
+	return v
+}
+
+func (r *reader) bytes() []byte {
+	n := int(r.uint16())
+	if r.err != nil || len(r.b) < n {
+		r.err = errMalformedPacket
+		return nil
+	}
+	v := r.b[:n]
+	r.b = r.b[n:]
+	return v
+}
+
+func (r *reader) string() string {
+	return string(r.bytes())
+}
+
+// idPacket 只包含报文标识符的报文，如PUBACK
+func idPacket(typ byte, id uint16) packet {
+	p := packet{typ: typ, body: appendUint16(nil, id)}
+	if typ == packetPubrel {
+		p.flags = 0x02
+	}
+	return p
+}
+
+func packetID(p packet) (uint16, error) {
+	r := reader{b: p.body}
+	id := r.uint16()
+	return id, r.err
+}
+
+// Message 应用消息
+type Message struct {
+	Topic    string
+	Payload  []byte
+	QoS      byte
+	Retained bool
+}
+
+func publishPacket(msg Message, id uint16, dup bool) packet {
+	p := packet{typ: packetPublish, flags: msg.QoS << 1}
+	if msg.Retained {
+		p.flags |= 0x01
+	}
+	if dup {
+		p.flags |= 0x08
+	}
+	p.body = appendString(p.body, msg.Topic)
+	if msg.QoS > 0 {
+		p.body = appendUint16(p.body, id)
+	}
+	p.body = append(p.body, msg.Payload...)
+	return p
+}
+
+func decodePublish(p packet) (msg Message, id uint16, err error) {
+	msg.QoS = p.flags >> 1 & 0x03
+	msg.Retained = p.flags&0x01 != 0
+	if msg.QoS > 2 {
+		return msg, 0, errMalformedPacket
+	}
+	r := reader{b: p.body}
+	msg.Topic = r.string()
+	if msg.QoS > 0 {
+		id = r.uint16()
+	}
+	if r.err != nil {
+		return msg, 0, r.err
+	}
+	msg.Payload = append([]byte(nil), r.b...)
+	return
+}
+
+// connectPacket CONNECT报文，协议版本为3.1.1，总是清除会话
+func connectPacket(opts Options, keepAlive uint16) packet {
+	flags := byte(0x02)
+	if opts.Username != "" {
+		flags |= 0x80
+		if opts.Password != "" {
+			flags |= 0x40
+		}
+	}
+	if opts.Will != nil {
+		flags |= 0x04 | opts.Will.QoS<<3
+		if opts.Will.Retained {
+			flags |= 0x20
+		}
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, 4, flags)
+	body = appendUint16(body, keepAlive)
+	body = appendString(body, opts.ClientID)
+	if opts.Will != nil {
+		body = appendString(body, opts.Will.Topic)
+		body = appendString(body, string(opts.Will.Payload))
+	}
+	if opts.Username != "" {
+		body = appendString(body, opts.Username)
+		if opts.Password != "" {
+			body = appendString(body, opts.Password)
+		}
+	}
+	return packet{typ: packetConnect, body: body}
+}
+
+// ConnectError 服务器拒绝连接
+type ConnectError byte
+
+func (e ConnectError) Error() string {
+	switch e {
+	case 1:
+		return "mqtt: connection refused: unacceptable protocol version"
+	case 2:
+		return "mqtt: connection refused: identifier rejected"
+	case 3:
+		return "mqtt: connection refused: server unavailable"
+	case 4:
+		return "mqtt: connection refused: bad user name or password"
+	case 5:
+		return "mqtt: connection refused: not authorized"
+	}
+	return fmt.Sprintf("mqtt: connection refused: %d", byte(e))
+}
@@ -0,0 +1,57 @@
+package mqtt
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidTopic  = errors.New("mqtt: invalid topic")
+	ErrInvalidFilter = errors.New("mqtt: invalid topic filter")
+)
+
+// ValidTopic 判断主题名是否合法，主题名不能包含通配符
+func ValidTopic(topic string) bool {
+	return topic != "" && len(topic) <= 65535 && utf8.ValidString(topic) &&
+		!strings.ContainsAny(topic, "+#\x00")
+}
+
+// ValidFilter 判断主题过滤器是否合法
+func ValidFilter(filter string) bool {
+	if filter == "" || len(filter) > 65535 || !utf8.ValidString(filter) || strings.Contains(filter, "\x00") {
+		return false
+	}
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if level == "#" && i == len(levels)-1 || level == "+" {
+			continue
+		}
+		if strings.ContainsAny(level, "+#") {
+			return false
+		}
+	}
+	return true
+}
+
+// Match 判断主题名是否匹配主题过滤器
+func Match(filter, topic string) bool {
+	// 通配符不匹配以$开头的主题
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	fs := strings.Split(filter, "/")
+	ts := strings.Split(topic, "/")
+	for i, f := range fs {
+		if f == "#" {
+			return true
+		}
+		if i >= len(ts) {
+			return false
+		}
+		if f != "+" && f != ts[i] {
+			return false
+		}
+	}
+	return len(fs) == len(ts)
+}
@@ -25,6 +25,272 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type StorageEvent_Type int32
+
+const (
+	StorageEvent_PUT    StorageEvent_Type = 0
+	StorageEvent_DELETE StorageEvent_Type = 1
+)
+
+// Enum value maps for StorageEvent_Type.
+var (
+	StorageEvent_Type_name = map[int32]string{
+		0: "PUT",
+		1: "DELETE",
+	}
+	StorageEvent_Type_value = map[string]int32{
+		"PUT":    0,
+		"DELETE": 1,
+	}
+)
+
+func (x StorageEvent_Type) Enum() *StorageEvent_Type {
+	p := new(StorageEvent_Type)
+	*p = x
+	return p
+}
+
+func (x StorageEvent_Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StorageEvent_Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_v2_plugin_proto_enumTypes[0].Descriptor()
+}
+
+func (StorageEvent_Type) Type() protoreflect.EnumType {
+	return &file_v2_plugin_proto_enumTypes[0]
+}
+
+func (x StorageEvent_Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StorageEvent_Type.Descriptor instead.
+func (StorageEvent_Type) EnumDescriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{27, 0}
+}
+
+type LeaseGrantReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ttl int64 `protobuf:"varint,1,opt,name=ttl,proto3" json:"ttl,omitempty"` // 秒
+}
+
+func (x *LeaseGrantReq) Reset() {
+	*x = LeaseGrantReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LeaseGrantReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseGrantReq) ProtoMessage() {}
+
+func (x *LeaseGrantReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseGrantReq.ProtoReflect.Descriptor instead.
+func (*LeaseGrantReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LeaseGrantReq) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+type Lease struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id  int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Ttl int64 `protobuf:"varint,2,opt,name=ttl,proto3" json:"ttl,omitempty"`
+}
+
+func (x *Lease) Reset() {
+	*x = Lease{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Lease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Lease) ProtoMessage() {}
+
+func (x *Lease) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Lease.ProtoReflect.Descriptor instead.
+func (*Lease) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Lease) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Lease) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+type RegistryEndpoint struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key      string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Addr     string `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	Metadata []byte `protobuf:"bytes,3,opt,name=metadata,proto3" json:"metadata,omitempty"` // json
+	LeaseId  int64  `protobuf:"varint,4,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
+}
+
+func (x *RegistryEndpoint) Reset() {
+	*x = RegistryEndpoint{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegistryEndpoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryEndpoint) ProtoMessage() {}
+
+func (x *RegistryEndpoint) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryEndpoint.ProtoReflect.Descriptor instead.
+func (*RegistryEndpoint) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegistryEndpoint) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *RegistryEndpoint) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *RegistryEndpoint) GetMetadata() []byte {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *RegistryEndpoint) GetLeaseId() int64 {
+	if x != nil {
+		return x.LeaseId
+	}
+	return 0
+}
+
+type RegistryKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *RegistryKey) Reset() {
+	*x = RegistryKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegistryKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistryKey) ProtoMessage() {}
+
+func (x *RegistryKey) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistryKey.ProtoReflect.Descriptor instead.
+func (*RegistryKey) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RegistryKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
 type OTAReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -37,7 +303,7 @@ type OTAReq struct {
 func (x *OTAReq) Reset() {
 	*x = OTAReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[0]
+		mi := &file_v2_plugin_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -50,7 +316,7 @@ func (x *OTAReq) String() string {
 func (*OTAReq) ProtoMessage() {}
 
 func (x *OTAReq) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[0]
+	mi := &file_v2_plugin_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -63,7 +329,7 @@ func (x *OTAReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OTAReq.ProtoReflect.Descriptor instead.
 func (*OTAReq) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{0}
+	return file_v2_plugin_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *OTAReq) GetIid() string {
@@ -92,7 +358,7 @@ type OTAResp struct {
 func (x *OTAResp) Reset() {
 	*x = OTAResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[1]
+		mi := &file_v2_plugin_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -105,7 +371,7 @@ func (x *OTAResp) String() string {
 func (*OTAResp) ProtoMessage() {}
 
 func (x *OTAResp) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[1]
+	mi := &file_v2_plugin_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -118,7 +384,7 @@ func (x *OTAResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use OTAResp.ProtoReflect.Descriptor instead.
 func (*OTAResp) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{1}
+	return file_v2_plugin_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *OTAResp) GetIid() string {
@@ -147,7 +413,7 @@ type AuthReq struct {
 func (x *AuthReq) Reset() {
 	*x = AuthReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[2]
+		mi := &file_v2_plugin_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -160,7 +426,7 @@ func (x *AuthReq) String() string {
 func (*AuthReq) ProtoMessage() {}
 
 func (x *AuthReq) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[2]
+	mi := &file_v2_plugin_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -173,7 +439,7 @@ func (x *AuthReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthReq.ProtoReflect.Descriptor instead.
 func (*AuthReq) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{2}
+	return file_v2_plugin_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *AuthReq) GetIid() string {
@@ -204,7 +470,7 @@ type Instance struct {
 func (x *Instance) Reset() {
 	*x = Instance{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[3]
+		mi := &file_v2_plugin_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -217,7 +483,7 @@ func (x *Instance) String() string {
 func (*Instance) ProtoMessage() {}
 
 func (x *Instance) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[3]
+	mi := &file_v2_plugin_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -230,7 +496,7 @@ func (x *Instance) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Instance.ProtoReflect.Descriptor instead.
 func (*Instance) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{3}
+	return file_v2_plugin_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *Instance) GetIid() string {
@@ -272,7 +538,7 @@ type SetAttributesReq struct {
 func (x *SetAttributesReq) Reset() {
 	*x = SetAttributesReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[4]
+		mi := &file_v2_plugin_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -285,7 +551,7 @@ func (x *SetAttributesReq) String() string {
 func (*SetAttributesReq) ProtoMessage() {}
 
 func (x *SetAttributesReq) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[4]
+	mi := &file_v2_plugin_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -298,7 +564,7 @@ func (x *SetAttributesReq) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetAttributesReq.ProtoReflect.Descriptor instead.
 func (*SetAttributesReq) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{4}
+	return file_v2_plugin_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *SetAttributesReq) GetData() []byte {
@@ -320,7 +586,7 @@ type SetAttributesResp struct {
 func (x *SetAttributesResp) Reset() {
 	*x = SetAttributesResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[5]
+		mi := &file_v2_plugin_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -333,7 +599,7 @@ func (x *SetAttributesResp) String() string {
 func (*SetAttributesResp) ProtoMessage() {}
 
 func (x *SetAttributesResp) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[5]
+	mi := &file_v2_plugin_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -346,7 +612,7 @@ func (x *SetAttributesResp) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SetAttributesResp.ProtoReflect.Descriptor instead.
 func (*SetAttributesResp) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{5}
+	return file_v2_plugin_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *SetAttributesResp) GetSuccess() bool {
@@ -363,36 +629,31 @@ func (x *SetAttributesResp) GetError() string {
 	return ""
 }
 
-type Device struct {
+type GetAttributesReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Iid          string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
-	Model        string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
-	Manufacturer string `protobuf:"bytes,3,opt,name=manufacturer,proto3" json:"manufacturer,omitempty"`
-	AuthRequired bool   `protobuf:"varint,4,opt,name=authRequired,proto3" json:"authRequired,omitempty"`
-	AuthParams   []byte `protobuf:"bytes,5,opt,name=authParams,proto3" json:"authParams,omitempty"`
-	Type         string `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 }
 
-func (x *Device) Reset() {
-	*x = Device{}
+func (x *GetAttributesReq) Reset() {
+	*x = GetAttributesReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[6]
+		mi := &file_v2_plugin_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Device) String() string {
+func (x *GetAttributesReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Device) ProtoMessage() {}
+func (*GetAttributesReq) ProtoMessage() {}
 
-func (x *Device) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[6]
+func (x *GetAttributesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -403,79 +664,155 @@ func (x *Device) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Device.ProtoReflect.Descriptor instead.
-func (*Device) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use GetAttributesReq.ProtoReflect.Descriptor instead.
+func (*GetAttributesReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *Device) GetIid() string {
+func (x *GetAttributesReq) GetData() []byte {
 	if x != nil {
-		return x.Iid
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-func (x *Device) GetModel() string {
-	if x != nil {
-		return x.Model
-	}
-	return ""
-}
+type GetAttributesResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-func (x *Device) GetManufacturer() string {
-	if x != nil {
-		return x.Manufacturer
+	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Data    []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *GetAttributesResp) Reset() {
+	*x = GetAttributesResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return ""
 }
 
-func (x *Device) GetAuthRequired() bool {
+func (x *GetAttributesResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAttributesResp) ProtoMessage() {}
+
+func (x *GetAttributesResp) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAttributesResp.ProtoReflect.Descriptor instead.
+func (*GetAttributesResp) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetAttributesResp) GetSuccess() bool {
 	if x != nil {
-		return x.AuthRequired
+		return x.Success
 	}
 	return false
 }
 
-func (x *Device) GetAuthParams() []byte {
+func (x *GetAttributesResp) GetError() string {
 	if x != nil {
-		return x.AuthParams
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetAttributesResp) GetData() []byte {
+	if x != nil {
+		return x.Data
 	}
 	return nil
 }
 
-func (x *Device) GetType() string {
+type InvokeActionReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *InvokeActionReq) Reset() {
+	*x = InvokeActionReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InvokeActionReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvokeActionReq) ProtoMessage() {}
+
+func (x *InvokeActionReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvokeActionReq.ProtoReflect.Descriptor instead.
+func (*InvokeActionReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *InvokeActionReq) GetData() []byte {
 	if x != nil {
-		return x.Type
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-type Event struct {
+type InvokeActionResp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // 事件类型
-	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	Success bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error   string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Data    []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *InvokeActionResp) Reset() {
+	*x = InvokeActionResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[7]
+		mi := &file_v2_plugin_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Event) String() string {
+func (x *InvokeActionResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*InvokeActionResp) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[7]
+func (x *InvokeActionResp) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -486,50 +823,57 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use InvokeActionResp.ProtoReflect.Descriptor instead.
+func (*InvokeActionResp) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *Event) GetType() string {
+func (x *InvokeActionResp) GetSuccess() bool {
 	if x != nil {
-		return x.Type
+		return x.Success
+	}
+	return false
+}
+
+func (x *InvokeActionResp) GetError() string {
+	if x != nil {
+		return x.Error
 	}
 	return ""
 }
 
-func (x *Event) GetData() []byte {
+func (x *InvokeActionResp) GetData() []byte {
 	if x != nil {
 		return x.Data
 	}
 	return nil
 }
 
-type HealthCheckReq struct {
+type UpdateSettingsReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Iid string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
 }
 
-func (x *HealthCheckReq) Reset() {
-	*x = HealthCheckReq{}
+func (x *UpdateSettingsReq) Reset() {
+	*x = UpdateSettingsReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[8]
+		mi := &file_v2_plugin_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *HealthCheckReq) String() string {
+func (x *UpdateSettingsReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckReq) ProtoMessage() {}
+func (*UpdateSettingsReq) ProtoMessage() {}
 
-func (x *HealthCheckReq) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[8]
+func (x *UpdateSettingsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -540,44 +884,45 @@ func (x *HealthCheckReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckReq.ProtoReflect.Descriptor instead.
-func (*HealthCheckReq) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use UpdateSettingsReq.ProtoReflect.Descriptor instead.
+func (*UpdateSettingsReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *HealthCheckReq) GetIid() string {
+func (x *UpdateSettingsReq) GetData() []byte {
 	if x != nil {
-		return x.Iid
+		return x.Data
 	}
-	return ""
+	return nil
 }
 
-type HealthCheckResp struct {
+type HandshakeReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Iid    string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
-	Online bool   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`
+	SaVersion        string   `protobuf:"bytes,1,opt,name=sa_version,json=saVersion,proto3" json:"sa_version,omitempty"`
+	ProtocolVersions []int32  `protobuf:"varint,2,rep,packed,name=protocol_versions,json=protocolVersions,proto3" json:"protocol_versions,omitempty"` // SA支持的协议版本
+	Capabilities     []string `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                                         // SA支持的功能
 }
 
-func (x *HealthCheckResp) Reset() {
-	*x = HealthCheckResp{}
+func (x *HandshakeReq) Reset() {
+	*x = HandshakeReq{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[9]
+		mi := &file_v2_plugin_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *HealthCheckResp) String() string {
+func (x *HandshakeReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheckResp) ProtoMessage() {}
+func (*HandshakeReq) ProtoMessage() {}
 
-func (x *HealthCheckResp) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[9]
+func (x *HandshakeReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -588,50 +933,59 @@ func (x *HealthCheckResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheckResp.ProtoReflect.Descriptor instead.
-func (*HealthCheckResp) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use HandshakeReq.ProtoReflect.Descriptor instead.
+func (*HandshakeReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *HealthCheckResp) GetIid() string {
+func (x *HandshakeReq) GetSaVersion() string {
 	if x != nil {
-		return x.Iid
+		return x.SaVersion
 	}
 	return ""
 }
 
-func (x *HealthCheckResp) GetOnline() bool {
+func (x *HandshakeReq) GetProtocolVersions() []int32 {
 	if x != nil {
-		return x.Online
+		return x.ProtocolVersions
 	}
-	return false
+	return nil
 }
 
-type GetInstancesReq struct {
+func (x *HandshakeReq) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type HandshakeResp struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Iid string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
+	SdkVersion      string   `protobuf:"bytes,1,opt,name=sdk_version,json=sdkVersion,proto3" json:"sdk_version,omitempty"`
+	ProtocolVersion int32    `protobuf:"varint,2,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // 协商后使用的协议版本
+	Capabilities    []string `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`                               // 插件支持的功能
 }
 
-func (x *GetInstancesReq) Reset() {
-	*x = GetInstancesReq{}
+func (x *HandshakeResp) Reset() {
+	*x = HandshakeResp{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[10]
+		mi := &file_v2_plugin_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetInstancesReq) String() string {
+func (x *HandshakeResp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetInstancesReq) ProtoMessage() {}
+func (*HandshakeResp) ProtoMessage() {}
 
-func (x *GetInstancesReq) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[10]
+func (x *HandshakeResp) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -642,49 +996,62 @@ func (x *GetInstancesReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetInstancesReq.ProtoReflect.Descriptor instead.
-func (*GetInstancesReq) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use HandshakeResp.ProtoReflect.Descriptor instead.
+func (*HandshakeResp) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *GetInstancesReq) GetIid() string {
+func (x *HandshakeResp) GetSdkVersion() string {
 	if x != nil {
-		return x.Iid
+		return x.SdkVersion
 	}
 	return ""
 }
 
-type GetInstancesResp struct {
+func (x *HandshakeResp) GetProtocolVersion() int32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *HandshakeResp) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+type Device struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Success      bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Error        string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
-	Instances    []byte `protobuf:"bytes,3,opt,name=instances,proto3" json:"instances,omitempty"`
-	OtaSupport   bool   `protobuf:"varint,4,opt,name=ota_support,json=otaSupport,proto3" json:"ota_support,omitempty"`
-	AuthRequired bool   `protobuf:"varint,5,opt,name=auth_required,json=authRequired,proto3" json:"auth_required,omitempty"`
-	IsAuth       bool   `protobuf:"varint,6,opt,name=is_auth,json=isAuth,proto3" json:"is_auth,omitempty"`
-	AuthParams   []byte `protobuf:"bytes,7,opt,name=authParams,proto3" json:"authParams,omitempty"`
+	Iid          string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
+	Model        string `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Manufacturer string `protobuf:"bytes,3,opt,name=manufacturer,proto3" json:"manufacturer,omitempty"`
+	AuthRequired bool   `protobuf:"varint,4,opt,name=authRequired,proto3" json:"authRequired,omitempty"`
+	AuthParams   []byte `protobuf:"bytes,5,opt,name=authParams,proto3" json:"authParams,omitempty"`
+	Type         string `protobuf:"bytes,6,opt,name=type,proto3" json:"type,omitempty"`
 }
 
-func (x *GetInstancesResp) Reset() {
-	*x = GetInstancesResp{}
+func (x *Device) Reset() {
+	*x = Device{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_v2_plugin_proto_msgTypes[11]
+		mi := &file_v2_plugin_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetInstancesResp) String() string {
+func (x *Device) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetInstancesResp) ProtoMessage() {}
+func (*Device) ProtoMessage() {}
 
-func (x *GetInstancesResp) ProtoReflect() protoreflect.Message {
-	mi := &file_v2_plugin_proto_msgTypes[11]
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -695,436 +1062,2003 @@ func (x *GetInstancesResp) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetInstancesResp.ProtoReflect.Descriptor instead.
-func (*GetInstancesResp) Descriptor() ([]byte, []int) {
-	return file_v2_plugin_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *GetInstancesResp) GetSuccess() bool {
+func (x *Device) GetIid() string {
 	if x != nil {
-		return x.Success
+		return x.Iid
 	}
-	return false
+	return ""
 }
 
-func (x *GetInstancesResp) GetError() string {
+func (x *Device) GetModel() string {
 	if x != nil {
-		return x.Error
+		return x.Model
 	}
 	return ""
 }
 
-func (x *GetInstancesResp) GetInstances() []byte {
+func (x *Device) GetManufacturer() string {
 	if x != nil {
-		return x.Instances
+		return x.Manufacturer
 	}
-	return nil
+	return ""
 }
 
-func (x *GetInstancesResp) GetOtaSupport() bool {
+func (x *Device) GetAuthRequired() bool {
 	if x != nil {
-		return x.OtaSupport
+		return x.AuthRequired
 	}
 	return false
 }
 
-func (x *GetInstancesResp) GetAuthRequired() bool {
+func (x *Device) GetAuthParams() []byte {
 	if x != nil {
-		return x.AuthRequired
+		return x.AuthParams
 	}
-	return false
+	return nil
 }
 
-func (x *GetInstancesResp) GetIsAuth() bool {
+func (x *Device) GetType() string {
 	if x != nil {
-		return x.IsAuth
+		return x.Type
 	}
-	return false
+	return ""
 }
 
-func (x *GetInstancesResp) GetAuthParams() []byte {
-	if x != nil {
-		return x.AuthParams
+type Event struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // 事件类型
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return nil
 }
 
-var File_v2_plugin_proto protoreflect.FileDescriptor
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-var file_v2_plugin_proto_rawDesc = []byte{
-	0x0a, 0x0f, 0x76, 0x32, 0x2f, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x14, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
-	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3d, 0x0a, 0x06, 0x4f, 0x54, 0x41, 0x52, 0x65, 0x71, 0x12, 0x10,
-	0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64,
-	0x12, 0x21, 0x0a, 0x0c, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x75, 0x72, 0x6c,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65,
-	0x55, 0x72, 0x6c, 0x22, 0x2f, 0x0a, 0x07, 0x4f, 0x54, 0x41, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10,
-	0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64,
-	0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
-	0x73, 0x74, 0x65, 0x70, 0x22, 0x33, 0x0a, 0x07, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x12,
-	0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69,
-	0x64, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0c, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x71, 0x0a, 0x08, 0x49, 0x6e, 0x73,
-	0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x73, 0x74, 0x61,
-	0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x69, 0x6e,
-	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72,
-	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x61, 0x74,
-	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x26, 0x0a, 0x10,
-	0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71,
-	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x22, 0x43, 0x0a, 0x11, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69,
-	0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63,
-	0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63,
-	0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xac, 0x01, 0x0a, 0x06, 0x64, 0x65,
-	0x76, 0x69, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x22, 0x0a, 0x0c,
-	0x6d, 0x61, 0x6e, 0x75, 0x66, 0x61, 0x63, 0x74, 0x75, 0x72, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0c, 0x6d, 0x61, 0x6e, 0x75, 0x66, 0x61, 0x63, 0x74, 0x75, 0x72, 0x65, 0x72,
-	0x12, 0x22, 0x0a, 0x0c, 0x61, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75,
-	0x69, 0x72, 0x65, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61,
-	0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61,
-	0x72, 0x61, 0x6d, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x2f, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e,
-	0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x22, 0x0a, 0x0e, 0x68, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x69,
-	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x22, 0x3b, 0x0a,
-	0x0f, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70,
-	0x12, 0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69,
-	0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x06, 0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x22, 0x23, 0x0a, 0x0f, 0x47, 0x65,
-	0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a,
-	0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x22,
-	0xdf, 0x01, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14,
-	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
-	0x72, 0x72, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
-	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
-	0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x74, 0x61, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72,
-	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x6f, 0x74, 0x61, 0x53, 0x75, 0x70, 0x70,
-	0x6f, 0x72, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x72, 0x65, 0x71, 0x75,
-	0x69, 0x72, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x75, 0x74, 0x68,
-	0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x61,
-	0x75, 0x74, 0x68, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x41, 0x75, 0x74,
-	0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18,
-	0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d,
-	0x73, 0x32, 0x8a, 0x05, 0x0a, 0x06, 0x50, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x12, 0x42, 0x0a, 0x08,
-	0x44, 0x69, 0x73, 0x63, 0x6f, 0x76, 0x65, 0x72, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x1a, 0x1c, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
-	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x30, 0x01,
-	0x12, 0x42, 0x0a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x16, 0x2e,
-	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1b, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e,
-	0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x65, 0x76, 0x65,
-	0x6e, 0x74, 0x30, 0x01, 0x12, 0x5a, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68,
-	0x65, 0x63, 0x6b, 0x12, 0x24, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61,
-	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x68, 0x65, 0x61, 0x6c, 0x74,
-	0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x1a, 0x25, 0x2e, 0x7a, 0x68, 0x69, 0x74,
-	0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32,
-	0x2e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70,
-	0x12, 0x44, 0x0a, 0x03, 0x4f, 0x54, 0x41, 0x12, 0x1c, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e,
-	0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x4f,
-	0x54, 0x41, 0x52, 0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e,
-	0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x4f, 0x54, 0x41,
-	0x52, 0x65, 0x73, 0x70, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
-	0x74, 0x12, 0x1d, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70,
-	0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71,
-	0x1a, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
-	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61,
-	0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x43, 0x0a, 0x0a, 0x44, 0x69, 0x73, 0x63,
-	0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x12, 0x1d, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67,
-	0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x41, 0x75,
-	0x74, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x5d, 0x0a,
-	0x0c, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x25, 0x2e,
-	0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
-	0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65,
-	0x73, 0x52, 0x65, 0x71, 0x1a, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73,
-	0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x49,
-	0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x60, 0x0a, 0x0d,
-	0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x26, 0x2e,
-	0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
-	0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
-	0x65, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x27, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e,
-	0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x65, 0x74,
-	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x42, 0x0a,
-	0x5a, 0x08, 0x2e, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var (
-	file_v2_plugin_proto_rawDescOnce sync.Once
-	file_v2_plugin_proto_rawDescData = file_v2_plugin_proto_rawDesc
-)
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{18}
+}
 
-func file_v2_plugin_proto_rawDescGZIP() []byte {
-	file_v2_plugin_proto_rawDescOnce.Do(func() {
-		file_v2_plugin_proto_rawDescData = protoimpl.X.CompressGZIP(file_v2_plugin_proto_rawDescData)
-	})
-	return file_v2_plugin_proto_rawDescData
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
 }
 
-var file_v2_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
-var file_v2_plugin_proto_goTypes = []interface{}{
-	(*OTAReq)(nil),            // 0: zhiting.sa.plugin.v2.OTAReq
-	(*OTAResp)(nil),           // 1: zhiting.sa.plugin.v2.OTAResp
-	(*AuthReq)(nil),           // 2: zhiting.sa.plugin.v2.AuthReq
-	(*Instance)(nil),          // 3: zhiting.sa.plugin.v2.Instance
-	(*SetAttributesReq)(nil),  // 4: zhiting.sa.plugin.v2.SetAttributesReq
-	(*SetAttributesResp)(nil), // 5: zhiting.sa.plugin.v2.SetAttributesResp
-	(*Device)(nil),            // 6: zhiting.sa.plugin.v2.device
-	(*Event)(nil),             // 7: zhiting.sa.plugin.v2.event
-	(*HealthCheckReq)(nil),    // 8: zhiting.sa.plugin.v2.healthCheckReq
-	(*HealthCheckResp)(nil),   // 9: zhiting.sa.plugin.v2.healthCheckResp
-	(*GetInstancesReq)(nil),   // 10: zhiting.sa.plugin.v2.GetInstancesReq
-	(*GetInstancesResp)(nil),  // 11: zhiting.sa.plugin.v2.GetInstancesResp
-	(*empty.Empty)(nil),       // 12: google.protobuf.Empty
+func (x *Event) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
 }
-var file_v2_plugin_proto_depIdxs = []int32{
-	12, // 0: zhiting.sa.plugin.v2.Plugin.Discover:input_type -> google.protobuf.Empty
-	12, // 1: zhiting.sa.plugin.v2.Plugin.Subscribe:input_type -> google.protobuf.Empty
-	8,  // 2: zhiting.sa.plugin.v2.Plugin.HealthCheck:input_type -> zhiting.sa.plugin.v2.healthCheckReq
-	0,  // 3: zhiting.sa.plugin.v2.Plugin.OTA:input_type -> zhiting.sa.plugin.v2.OTAReq
-	2,  // 4: zhiting.sa.plugin.v2.Plugin.Connect:input_type -> zhiting.sa.plugin.v2.AuthReq
-	2,  // 5: zhiting.sa.plugin.v2.Plugin.Disconnect:input_type -> zhiting.sa.plugin.v2.AuthReq
-	10, // 6: zhiting.sa.plugin.v2.Plugin.GetInstances:input_type -> zhiting.sa.plugin.v2.GetInstancesReq
-	4,  // 7: zhiting.sa.plugin.v2.Plugin.SetAttributes:input_type -> zhiting.sa.plugin.v2.SetAttributesReq
-	6,  // 8: zhiting.sa.plugin.v2.Plugin.Discover:output_type -> zhiting.sa.plugin.v2.device
-	7,  // 9: zhiting.sa.plugin.v2.Plugin.Subscribe:output_type -> zhiting.sa.plugin.v2.event
-	9,  // 10: zhiting.sa.plugin.v2.Plugin.HealthCheck:output_type -> zhiting.sa.plugin.v2.healthCheckResp
-	1,  // 11: zhiting.sa.plugin.v2.Plugin.OTA:output_type -> zhiting.sa.plugin.v2.OTAResp
-	11, // 12: zhiting.sa.plugin.v2.Plugin.Connect:output_type -> zhiting.sa.plugin.v2.GetInstancesResp
-	12, // 13: zhiting.sa.plugin.v2.Plugin.Disconnect:output_type -> google.protobuf.Empty
-	11, // 14: zhiting.sa.plugin.v2.Plugin.GetInstances:output_type -> zhiting.sa.plugin.v2.GetInstancesResp
-	5,  // 15: zhiting.sa.plugin.v2.Plugin.SetAttributes:output_type -> zhiting.sa.plugin.v2.SetAttributesResp
-	8,  // [8:16] is the sub-list for method output_type
-	0,  // [0:8] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+
+type HealthCheckReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Iid string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
 }
 
-func init() { file_v2_plugin_proto_init() }
-func file_v2_plugin_proto_init() {
-	if File_v2_plugin_proto != nil {
-		return
+func (x *HealthCheckReq) Reset() {
+	*x = HealthCheckReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_v2_plugin_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*OTAReq); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_v2_plugin_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*OTAResp); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_v2_plugin_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*AuthReq); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *HealthCheckReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckReq) ProtoMessage() {}
+
+func (x *HealthCheckReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Instance); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckReq.ProtoReflect.Descriptor instead.
+func (*HealthCheckReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *HealthCheckReq) GetIid() string {
+	if x != nil {
+		return x.Iid
+	}
+	return ""
+}
+
+type HealthCheckResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Iid    string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
+	Online bool   `protobuf:"varint,2,opt,name=online,proto3" json:"online,omitempty"`
+}
+
+func (x *HealthCheckResp) Reset() {
+	*x = HealthCheckResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HealthCheckResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheckResp) ProtoMessage() {}
+
+func (x *HealthCheckResp) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetAttributesReq); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheckResp.ProtoReflect.Descriptor instead.
+func (*HealthCheckResp) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *HealthCheckResp) GetIid() string {
+	if x != nil {
+		return x.Iid
+	}
+	return ""
+}
+
+func (x *HealthCheckResp) GetOnline() bool {
+	if x != nil {
+		return x.Online
+	}
+	return false
+}
+
+type GetInstancesReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Iid string `protobuf:"bytes,1,opt,name=iid,proto3" json:"iid,omitempty"`
+}
+
+func (x *GetInstancesReq) Reset() {
+	*x = GetInstancesReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetInstancesReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInstancesReq) ProtoMessage() {}
+
+func (x *GetInstancesReq) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SetAttributesResp); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_v2_plugin_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Device); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_v2_plugin_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Event); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInstancesReq.ProtoReflect.Descriptor instead.
+func (*GetInstancesReq) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetInstancesReq) GetIid() string {
+	if x != nil {
+		return x.Iid
+	}
+	return ""
+}
+
+type GetInstancesResp struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Success      bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Error        string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	Instances    []byte `protobuf:"bytes,3,opt,name=instances,proto3" json:"instances,omitempty"`
+	OtaSupport   bool   `protobuf:"varint,4,opt,name=ota_support,json=otaSupport,proto3" json:"ota_support,omitempty"`
+	AuthRequired bool   `protobuf:"varint,5,opt,name=auth_required,json=authRequired,proto3" json:"auth_required,omitempty"`
+	IsAuth       bool   `protobuf:"varint,6,opt,name=is_auth,json=isAuth,proto3" json:"is_auth,omitempty"`
+	AuthParams   []byte `protobuf:"bytes,7,opt,name=authParams,proto3" json:"authParams,omitempty"`
+}
+
+func (x *GetInstancesResp) Reset() {
+	*x = GetInstancesResp{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetInstancesResp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInstancesResp) ProtoMessage() {}
+
+func (x *GetInstancesResp) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HealthCheckReq); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInstancesResp.ProtoReflect.Descriptor instead.
+func (*GetInstancesResp) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetInstancesResp) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *GetInstancesResp) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *GetInstancesResp) GetInstances() []byte {
+	if x != nil {
+		return x.Instances
+	}
+	return nil
+}
+
+func (x *GetInstancesResp) GetOtaSupport() bool {
+	if x != nil {
+		return x.OtaSupport
+	}
+	return false
+}
+
+func (x *GetInstancesResp) GetAuthRequired() bool {
+	if x != nil {
+		return x.AuthRequired
+	}
+	return false
+}
+
+func (x *GetInstancesResp) GetIsAuth() bool {
+	if x != nil {
+		return x.IsAuth
+	}
+	return false
+}
+
+func (x *GetInstancesResp) GetAuthParams() []byte {
+	if x != nil {
+		return x.AuthParams
+	}
+	return nil
+}
+
+type StorageKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *StorageKey) Reset() {
+	*x = StorageKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageKey) ProtoMessage() {}
+
+func (x *StorageKey) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*HealthCheckResp); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageKey.ProtoReflect.Descriptor instead.
+func (*StorageKey) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StorageKey) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type StoragePrefix struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+}
+
+func (x *StoragePrefix) Reset() {
+	*x = StoragePrefix{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StoragePrefix) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoragePrefix) ProtoMessage() {}
+
+func (x *StoragePrefix) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetInstancesReq); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoragePrefix.ProtoReflect.Descriptor instead.
+func (*StoragePrefix) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *StoragePrefix) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+type StorageItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Key   string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (x *StorageItem) Reset() {
+	*x = StorageItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageItem) ProtoMessage() {}
+
+func (x *StorageItem) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_v2_plugin_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetInstancesResp); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageItem.ProtoReflect.Descriptor instead.
+func (*StorageItem) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *StorageItem) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StorageItem) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+type StorageItems struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*StorageItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *StorageItems) Reset() {
+	*x = StorageItems{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageItems) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageItems) ProtoMessage() {}
+
+func (x *StorageItems) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageItems.ProtoReflect.Descriptor instead.
+func (*StorageItems) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *StorageItems) GetItems() []*StorageItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type StorageEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type StorageEvent_Type `protobuf:"varint,1,opt,name=type,proto3,enum=zhiting.sa.plugin.v2.StorageEvent_Type" json:"type,omitempty"`
+	Item *StorageItem      `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
+}
+
+func (x *StorageEvent) Reset() {
+	*x = StorageEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_v2_plugin_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageEvent) ProtoMessage() {}
+
+func (x *StorageEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_v2_plugin_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageEvent.ProtoReflect.Descriptor instead.
+func (*StorageEvent) Descriptor() ([]byte, []int) {
+	return file_v2_plugin_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *StorageEvent) GetType() StorageEvent_Type {
+	if x != nil {
+		return x.Type
+	}
+	return StorageEvent_PUT
+}
+
+func (x *StorageEvent) GetItem() *StorageItem {
+	if x != nil {
+		return x.Item
+	}
+	return nil
+}
+
+var File_v2_plugin_proto protoreflect.FileDescriptor
+
+var file_v2_plugin_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x76, 0x32, 0x2f, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x14, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x22, 0x21, 0x0a, 0x0d, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x47, 0x72, 0x61,
+	0x6e, 0x74, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x22, 0x29, 0x0a, 0x05, 0x4c, 0x65, 0x61, 0x73, 0x65,
+	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64,
+	0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x74,
+	0x74, 0x6c, 0x22, 0x6f, 0x0a, 0x10, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x45, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x64, 0x64, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x64, 0x64, 0x72, 0x12, 0x1a, 0x0a, 0x08,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x19, 0x0a, 0x08, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x49, 0x64, 0x22, 0x1f, 0x0a, 0x0b, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x4b,
+	0x65, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x22, 0x3d, 0x0a, 0x06, 0x4f, 0x54, 0x41, 0x52, 0x65, 0x71, 0x12, 0x10,
+	0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64,
+	0x12, 0x21, 0x0a, 0x0c, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65, 0x5f, 0x75, 0x72, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x66, 0x69, 0x72, 0x6d, 0x77, 0x61, 0x72, 0x65,
+	0x55, 0x72, 0x6c, 0x22, 0x2f, 0x0a, 0x07, 0x4f, 0x54, 0x41, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10,
+	0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x74, 0x65, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04,
+	0x73, 0x74, 0x65, 0x70, 0x22, 0x33, 0x0a, 0x07, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x12,
+	0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x71, 0x0a, 0x08, 0x49, 0x6e, 0x73,
+	0x74, 0x61, 0x6e, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x69, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x49, 0x64, 0x12, 0x1e, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72,
+	0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0a, 0x61, 0x74,
+	0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x26, 0x0a, 0x10,
+	0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x22, 0x43, 0x0a, 0x11, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x26, 0x0a, 0x10, 0x47, 0x65, 0x74,
+	0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x57, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x25, 0x0a, 0x0f, 0x49, 0x6e,
+	0x76, 0x6f, 0x6b, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a,
+	0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x56, 0x0a, 0x10, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x27, 0x0a, 0x11, 0x55, 0x70, 0x64,
+	0x61, 0x74, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x65, 0x71, 0x12, 0x12,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x22, 0x7e, 0x0a, 0x0c, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x52,
+	0x65, 0x71, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x61, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x2b, 0x0a, 0x11, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x05, 0x52, 0x10, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x22,
+	0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69,
+	0x65, 0x73, 0x22, 0x7f, 0x0a, 0x0d, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x52,
+	0x65, 0x73, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x64, 0x6b, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x64, 0x6b, 0x56, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
+	0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0f,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x22, 0x0a, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18,
+	0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x69, 0x65, 0x73, 0x22, 0xac, 0x01, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x10,
+	0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64,
+	0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x22, 0x0a, 0x0c, 0x6d, 0x61, 0x6e, 0x75, 0x66, 0x61,
+	0x63, 0x74, 0x75, 0x72, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x61,
+	0x6e, 0x75, 0x66, 0x61, 0x63, 0x74, 0x75, 0x72, 0x65, 0x72, 0x12, 0x22, 0x0a, 0x0c, 0x61, 0x75,
+	0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0c, 0x61, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x1e,
+	0x0a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x22, 0x2f, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x22, 0x22, 0x0a, 0x0e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x22, 0x3b, 0x0a, 0x0f, 0x68, 0x65, 0x61, 0x6c, 0x74,
+	0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x6f, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6f, 0x6e,
+	0x6c, 0x69, 0x6e, 0x65, 0x22, 0x23, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x69, 0x69, 0x64, 0x22, 0xdf, 0x01, 0x0a, 0x10, 0x47, 0x65,
+	0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1c,
+	0x0a, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x09, 0x69, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
+	0x6f, 0x74, 0x61, 0x5f, 0x73, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0a, 0x6f, 0x74, 0x61, 0x53, 0x75, 0x70, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x23, 0x0a,
+	0x0d, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x61, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x69, 0x72,
+	0x65, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x73, 0x5f, 0x61, 0x75, 0x74, 0x68, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x06, 0x69, 0x73, 0x41, 0x75, 0x74, 0x68, 0x12, 0x1e, 0x0a, 0x0a, 0x61,
+	0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x0a, 0x61, 0x75, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x1e, 0x0a, 0x0a, 0x53,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x27, 0x0a, 0x0d, 0x53,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x16, 0x0a, 0x06,
+	0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72,
+	0x65, 0x66, 0x69, 0x78, 0x22, 0x35, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49,
+	0x74, 0x65, 0x6d, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x47, 0x0a, 0x0c, 0x53,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x37, 0x0a, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x7a, 0x68, 0x69,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x22, 0x9f, 0x01, 0x0a, 0x0c, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x3b, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x27, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61,
+	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61,
+	0x67, 0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x12, 0x35, 0x0a, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x21, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49,
+	0x74, 0x65, 0x6d, 0x52, 0x04, 0x69, 0x74, 0x65, 0x6d, 0x22, 0x1b, 0x0a, 0x04, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x07, 0x0a, 0x03, 0x50, 0x55, 0x54, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x44, 0x45,
+	0x4c, 0x45, 0x54, 0x45, 0x10, 0x01, 0x32, 0xac, 0x08, 0x0a, 0x06, 0x50, 0x6c, 0x75, 0x67, 0x69,
+	0x6e, 0x12, 0x54, 0x0a, 0x09, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x12, 0x22,
+	0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67,
+	0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68, 0x61, 0x6b, 0x65, 0x52,
+	0x65, 0x71, 0x1a, 0x23, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x73, 0x68,
+	0x61, 0x6b, 0x65, 0x52, 0x65, 0x73, 0x70, 0x12, 0x42, 0x0a, 0x08, 0x44, 0x69, 0x73, 0x63, 0x6f,
+	0x76, 0x65, 0x72, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1c, 0x2e, 0x7a, 0x68,
+	0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e,
+	0x76, 0x32, 0x2e, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x30, 0x01, 0x12, 0x42, 0x0a, 0x09, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x1a, 0x1b, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x12,
+	0x5a, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x24,
+	0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67,
+	0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63,
+	0x6b, 0x52, 0x65, 0x71, 0x1a, 0x25, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73,
+	0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x68, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x12, 0x36, 0x0a, 0x04, 0x50,
+	0x69, 0x6e, 0x67, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x12, 0x44, 0x0a, 0x03, 0x4f, 0x54, 0x41, 0x12, 0x1c, 0x2e, 0x7a, 0x68, 0x69,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x4f, 0x54, 0x41, 0x52, 0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e,
+	0x4f, 0x54, 0x41, 0x52, 0x65, 0x73, 0x70, 0x30, 0x01, 0x12, 0x50, 0x0a, 0x07, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x12, 0x1d, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73,
+	0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x41, 0x75, 0x74, 0x68,
+	0x52, 0x65, 0x71, 0x1a, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61,
+	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e,
+	0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12, 0x43, 0x0a, 0x0a, 0x44,
+	0x69, 0x73, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x12, 0x1d, 0x2e, 0x7a, 0x68, 0x69, 0x74,
+	0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32,
+	0x2e, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x12, 0x5d, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73,
+	0x12, 0x25, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61,
+	0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e,
+	0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47,
+	0x65, 0x74, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x12,
+	0x60, 0x0a, 0x0d, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x12, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x27, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e,
+	0x53, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x12, 0x60, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x73, 0x12, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x27, 0x2e, 0x7a, 0x68, 0x69,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x12, 0x5d, 0x0a, 0x0c, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x25, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61,
+	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b,
+	0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x1a, 0x26, 0x2e, 0x7a, 0x68, 0x69,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x49, 0x6e, 0x76, 0x6f, 0x6b, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x12, 0x51, 0x0a, 0x0e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x53, 0x65, 0x74, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x12, 0x27, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73,
+	0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x32, 0x80, 0x03, 0x0a, 0x07, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x12, 0x4a, 0x0a, 0x03, 0x47, 0x65, 0x74, 0x12, 0x20, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e,
+	0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x1a, 0x21, 0x2e, 0x7a, 0x68, 0x69,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x40, 0x0a,
+	0x03, 0x50, 0x75, 0x74, 0x12, 0x21, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73,
+	0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72,
+	0x61, 0x67, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12,
+	0x42, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x20, 0x2e, 0x7a, 0x68, 0x69, 0x74,
+	0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x4b, 0x65, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x12, 0x4f, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x23, 0x2e, 0x7a, 0x68,
+	0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e,
+	0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78,
+	0x1a, 0x22, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c,
+	0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49,
+	0x74, 0x65, 0x6d, 0x73, 0x12, 0x52, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x23, 0x2e,
+	0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
+	0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x50, 0x72, 0x65, 0x66,
+	0x69, 0x78, 0x1a, 0x22, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e,
+	0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67,
+	0x65, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x32, 0xf7, 0x02, 0x0a, 0x08, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x79, 0x12, 0x49, 0x0a, 0x05, 0x47, 0x72, 0x61, 0x6e, 0x74, 0x12, 0x23,
+	0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67,
+	0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x47, 0x72, 0x61, 0x6e, 0x74,
+	0x52, 0x65, 0x71, 0x1a, 0x1b, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61,
+	0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65,
+	0x12, 0x45, 0x0a, 0x09, 0x4b, 0x65, 0x65, 0x70, 0x41, 0x6c, 0x69, 0x76, 0x65, 0x12, 0x1b, 0x2e,
+	0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69,
+	0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x1a, 0x1b, 0x2e, 0x7a, 0x68, 0x69,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76,
+	0x32, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x06, 0x52, 0x65, 0x76, 0x6f, 0x6b,
+	0x65, 0x12, 0x1b, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70,
+	0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x4c, 0x65, 0x61, 0x73, 0x65, 0x1a, 0x16,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4d, 0x0a, 0x0b, 0x41, 0x64, 0x64, 0x45, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x26, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e, 0x67, 0x2e,
+	0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x52, 0x65, 0x67,
+	0x69, 0x73, 0x74, 0x72, 0x79, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x1a, 0x16, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4b, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x45,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x21, 0x2e, 0x7a, 0x68, 0x69, 0x74, 0x69, 0x6e,
+	0x67, 0x2e, 0x73, 0x61, 0x2e, 0x70, 0x6c, 0x75, 0x67, 0x69, 0x6e, 0x2e, 0x76, 0x32, 0x2e, 0x52,
+	0x65, 0x67, 0x69, 0x73, 0x74, 0x72, 0x79, 0x4b, 0x65, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x42, 0x0a, 0x5a, 0x08, 0x2e, 0x2e, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_v2_plugin_proto_rawDescOnce sync.Once
+	file_v2_plugin_proto_rawDescData = file_v2_plugin_proto_rawDesc
+)
+
+func file_v2_plugin_proto_rawDescGZIP() []byte {
+	file_v2_plugin_proto_rawDescOnce.Do(func() {
+		file_v2_plugin_proto_rawDescData = protoimpl.X.CompressGZIP(file_v2_plugin_proto_rawDescData)
+	})
+	return file_v2_plugin_proto_rawDescData
+}
+
+var file_v2_plugin_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_v2_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 28)
+var file_v2_plugin_proto_goTypes = []interface{}{
+	(StorageEvent_Type)(0),    // 0: zhiting.sa.plugin.v2.StorageEvent.Type
+	(*LeaseGrantReq)(nil),     // 1: zhiting.sa.plugin.v2.LeaseGrantReq
+	(*Lease)(nil),             // 2: zhiting.sa.plugin.v2.Lease
+	(*RegistryEndpoint)(nil),  // 3: zhiting.sa.plugin.v2.RegistryEndpoint
+	(*RegistryKey)(nil),       // 4: zhiting.sa.plugin.v2.RegistryKey
+	(*OTAReq)(nil),            // 5: zhiting.sa.plugin.v2.OTAReq
+	(*OTAResp)(nil),           // 6: zhiting.sa.plugin.v2.OTAResp
+	(*AuthReq)(nil),           // 7: zhiting.sa.plugin.v2.AuthReq
+	(*Instance)(nil),          // 8: zhiting.sa.plugin.v2.Instance
+	(*SetAttributesReq)(nil),  // 9: zhiting.sa.plugin.v2.SetAttributesReq
+	(*SetAttributesResp)(nil), // 10: zhiting.sa.plugin.v2.SetAttributesResp
+	(*GetAttributesReq)(nil),  // 11: zhiting.sa.plugin.v2.GetAttributesReq
+	(*GetAttributesResp)(nil), // 12: zhiting.sa.plugin.v2.GetAttributesResp
+	(*InvokeActionReq)(nil),   // 13: zhiting.sa.plugin.v2.InvokeActionReq
+	(*InvokeActionResp)(nil),  // 14: zhiting.sa.plugin.v2.InvokeActionResp
+	(*UpdateSettingsReq)(nil), // 15: zhiting.sa.plugin.v2.UpdateSettingsReq
+	(*HandshakeReq)(nil),      // 16: zhiting.sa.plugin.v2.HandshakeReq
+	(*HandshakeResp)(nil),     // 17: zhiting.sa.plugin.v2.HandshakeResp
+	(*Device)(nil),            // 18: zhiting.sa.plugin.v2.device
+	(*Event)(nil),             // 19: zhiting.sa.plugin.v2.event
+	(*HealthCheckReq)(nil),    // 20: zhiting.sa.plugin.v2.healthCheckReq
+	(*HealthCheckResp)(nil),   // 21: zhiting.sa.plugin.v2.healthCheckResp
+	(*GetInstancesReq)(nil),   // 22: zhiting.sa.plugin.v2.GetInstancesReq
+	(*GetInstancesResp)(nil),  // 23: zhiting.sa.plugin.v2.GetInstancesResp
+	(*StorageKey)(nil),        // 24: zhiting.sa.plugin.v2.StorageKey
+	(*StoragePrefix)(nil),     // 25: zhiting.sa.plugin.v2.StoragePrefix
+	(*StorageItem)(nil),       // 26: zhiting.sa.plugin.v2.StorageItem
+	(*StorageItems)(nil),      // 27: zhiting.sa.plugin.v2.StorageItems
+	(*StorageEvent)(nil),      // 28: zhiting.sa.plugin.v2.StorageEvent
+	(*empty.Empty)(nil),       // 29: google.protobuf.Empty
+}
+var file_v2_plugin_proto_depIdxs = []int32{
+	26, // 0: zhiting.sa.plugin.v2.StorageItems.items:type_name -> zhiting.sa.plugin.v2.StorageItem
+	0,  // 1: zhiting.sa.plugin.v2.StorageEvent.type:type_name -> zhiting.sa.plugin.v2.StorageEvent.Type
+	26, // 2: zhiting.sa.plugin.v2.StorageEvent.item:type_name -> zhiting.sa.plugin.v2.StorageItem
+	16, // 3: zhiting.sa.plugin.v2.Plugin.Handshake:input_type -> zhiting.sa.plugin.v2.HandshakeReq
+	29, // 4: zhiting.sa.plugin.v2.Plugin.Discover:input_type -> google.protobuf.Empty
+	29, // 5: zhiting.sa.plugin.v2.Plugin.Subscribe:input_type -> google.protobuf.Empty
+	20, // 6: zhiting.sa.plugin.v2.Plugin.HealthCheck:input_type -> zhiting.sa.plugin.v2.healthCheckReq
+	29, // 7: zhiting.sa.plugin.v2.Plugin.Ping:input_type -> google.protobuf.Empty
+	5,  // 8: zhiting.sa.plugin.v2.Plugin.OTA:input_type -> zhiting.sa.plugin.v2.OTAReq
+	7,  // 9: zhiting.sa.plugin.v2.Plugin.Connect:input_type -> zhiting.sa.plugin.v2.AuthReq
+	7,  // 10: zhiting.sa.plugin.v2.Plugin.Disconnect:input_type -> zhiting.sa.plugin.v2.AuthReq
+	22, // 11: zhiting.sa.plugin.v2.Plugin.GetInstances:input_type -> zhiting.sa.plugin.v2.GetInstancesReq
+	9,  // 12: zhiting.sa.plugin.v2.Plugin.SetAttributes:input_type -> zhiting.sa.plugin.v2.SetAttributesReq
+	11, // 13: zhiting.sa.plugin.v2.Plugin.GetAttributes:input_type -> zhiting.sa.plugin.v2.GetAttributesReq
+	13, // 14: zhiting.sa.plugin.v2.Plugin.InvokeAction:input_type -> zhiting.sa.plugin.v2.InvokeActionReq
+	15, // 15: zhiting.sa.plugin.v2.Plugin.UpdateSettings:input_type -> zhiting.sa.plugin.v2.UpdateSettingsReq
+	24, // 16: zhiting.sa.plugin.v2.Storage.Get:input_type -> zhiting.sa.plugin.v2.StorageKey
+	26, // 17: zhiting.sa.plugin.v2.Storage.Put:input_type -> zhiting.sa.plugin.v2.StorageItem
+	24, // 18: zhiting.sa.plugin.v2.Storage.Delete:input_type -> zhiting.sa.plugin.v2.StorageKey
+	25, // 19: zhiting.sa.plugin.v2.Storage.List:input_type -> zhiting.sa.plugin.v2.StoragePrefix
+	25, // 20: zhiting.sa.plugin.v2.Storage.Watch:input_type -> zhiting.sa.plugin.v2.StoragePrefix
+	1,  // 21: zhiting.sa.plugin.v2.Registry.Grant:input_type -> zhiting.sa.plugin.v2.LeaseGrantReq
+	2,  // 22: zhiting.sa.plugin.v2.Registry.KeepAlive:input_type -> zhiting.sa.plugin.v2.Lease
+	2,  // 23: zhiting.sa.plugin.v2.Registry.Revoke:input_type -> zhiting.sa.plugin.v2.Lease
+	3,  // 24: zhiting.sa.plugin.v2.Registry.AddEndpoint:input_type -> zhiting.sa.plugin.v2.RegistryEndpoint
+	4,  // 25: zhiting.sa.plugin.v2.Registry.DeleteEndpoint:input_type -> zhiting.sa.plugin.v2.RegistryKey
+	17, // 26: zhiting.sa.plugin.v2.Plugin.Handshake:output_type -> zhiting.sa.plugin.v2.HandshakeResp
+	18, // 27: zhiting.sa.plugin.v2.Plugin.Discover:output_type -> zhiting.sa.plugin.v2.device
+	19, // 28: zhiting.sa.plugin.v2.Plugin.Subscribe:output_type -> zhiting.sa.plugin.v2.event
+	21, // 29: zhiting.sa.plugin.v2.Plugin.HealthCheck:output_type -> zhiting.sa.plugin.v2.healthCheckResp
+	29, // 30: zhiting.sa.plugin.v2.Plugin.Ping:output_type -> google.protobuf.Empty
+	6,  // 31: zhiting.sa.plugin.v2.Plugin.OTA:output_type -> zhiting.sa.plugin.v2.OTAResp
+	23, // 32: zhiting.sa.plugin.v2.Plugin.Connect:output_type -> zhiting.sa.plugin.v2.GetInstancesResp
+	29, // 33: zhiting.sa.plugin.v2.Plugin.Disconnect:output_type -> google.protobuf.Empty
+	23, // 34: zhiting.sa.plugin.v2.Plugin.GetInstances:output_type -> zhiting.sa.plugin.v2.GetInstancesResp
+	10, // 35: zhiting.sa.plugin.v2.Plugin.SetAttributes:output_type -> zhiting.sa.plugin.v2.SetAttributesResp
+	12, // 36: zhiting.sa.plugin.v2.Plugin.GetAttributes:output_type -> zhiting.sa.plugin.v2.GetAttributesResp
+	14, // 37: zhiting.sa.plugin.v2.Plugin.InvokeAction:output_type -> zhiting.sa.plugin.v2.InvokeActionResp
+	29, // 38: zhiting.sa.plugin.v2.Plugin.UpdateSettings:output_type -> google.protobuf.Empty
+	26, // 39: zhiting.sa.plugin.v2.Storage.Get:output_type -> zhiting.sa.plugin.v2.StorageItem
+	29, // 40: zhiting.sa.plugin.v2.Storage.Put:output_type -> google.protobuf.Empty
+	29, // 41: zhiting.sa.plugin.v2.Storage.Delete:output_type -> google.protobuf.Empty
+	27, // 42: zhiting.sa.plugin.v2.Storage.List:output_type -> zhiting.sa.plugin.v2.StorageItems
+	28, // 43: zhiting.sa.plugin.v2.Storage.Watch:output_type -> zhiting.sa.plugin.v2.StorageEvent
+	2,  // 44: zhiting.sa.plugin.v2.Registry.Grant:output_type -> zhiting.sa.plugin.v2.Lease
+	2,  // 45: zhiting.sa.plugin.v2.Registry.KeepAlive:output_type -> zhiting.sa.plugin.v2.Lease
+	29, // 46: zhiting.sa.plugin.v2.Registry.Revoke:output_type -> google.protobuf.Empty
+	29, // 47: zhiting.sa.plugin.v2.Registry.AddEndpoint:output_type -> google.protobuf.Empty
+	29, // 48: zhiting.sa.plugin.v2.Registry.DeleteEndpoint:output_type -> google.protobuf.Empty
+	26, // [26:49] is the sub-list for method output_type
+	3,  // [3:26] is the sub-list for method input_type
+	3,  // [3:3] is the sub-list for extension type_name
+	3,  // [3:3] is the sub-list for extension extendee
+	0,  // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_v2_plugin_proto_init() }
+func file_v2_plugin_proto_init() {
+	if File_v2_plugin_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_v2_plugin_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LeaseGrantReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Lease); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistryEndpoint); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegistryKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OTAReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OTAResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Instance); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetAttributesReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
 			case 2:
 				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
+		file_v2_plugin_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetAttributesResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAttributesReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAttributesResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvokeActionReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvokeActionResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateSettingsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HandshakeReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HandshakeResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Device); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Event); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthCheckReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HealthCheckResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInstancesReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetInstancesResp); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageKey); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StoragePrefix); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageItems); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_v2_plugin_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_v2_plugin_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   28,
+			NumExtensions: 0,
+			NumServices:   3,
+		},
+		GoTypes:           file_v2_plugin_proto_goTypes,
+		DependencyIndexes: file_v2_plugin_proto_depIdxs,
+		EnumInfos:         file_v2_plugin_proto_enumTypes,
+		MessageInfos:      file_v2_plugin_proto_msgTypes,
+	}.Build()
+	File_v2_plugin_proto = out.File
+	file_v2_plugin_proto_rawDesc = nil
+	file_v2_plugin_proto_goTypes = nil
+	file_v2_plugin_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// PluginClient is the client API for Plugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PluginClient interface {
+	// Handshake 协商协议版本及插件支持的功能，SA连接插件时首先调用
+	Handshake(ctx context.Context, in *HandshakeReq, opts ...grpc.CallOption) (*HandshakeResp, error)
+	// Discover 发现设备
+	Discover(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_DiscoverClient, error)
+	// StateChange 监听所有设备状态变化
+	Subscribe(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_SubscribeClient, error)
+	HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...grpc.CallOption) (*HealthCheckResp, error)
+	// Ping 插件服务存活检查
+	Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error)
+	OTA(ctx context.Context, in *OTAReq, opts ...grpc.CallOption) (Plugin_OTAClient, error)
+	Connect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*GetInstancesResp, error)
+	Disconnect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*empty.Empty, error)
+	// GetInstances 获取设备所有实例
+	GetInstances(ctx context.Context, in *GetInstancesReq, opts ...grpc.CallOption) (*GetInstancesResp, error)
+	// SetAttributes 设置属性
+	SetAttributes(ctx context.Context, in *SetAttributesReq, opts ...grpc.CallOption) (*SetAttributesResp, error)
+	// GetAttributes 主动从设备读取属性的最新值
+	GetAttributes(ctx context.Context, in *GetAttributesReq, opts ...grpc.CallOption) (*GetAttributesResp, error)
+	// InvokeAction 执行设备的动作
+	InvokeAction(ctx context.Context, in *InvokeActionReq, opts ...grpc.CallOption) (*InvokeActionResp, error)
+	// UpdateSettings 下发插件设置，插件启动连接时及设置修改时调用
+	UpdateSettings(ctx context.Context, in *UpdateSettingsReq, opts ...grpc.CallOption) (*empty.Empty, error)
+}
+
+type pluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPluginClient(cc grpc.ClientConnInterface) PluginClient {
+	return &pluginClient{cc}
+}
+
+func (c *pluginClient) Handshake(ctx context.Context, in *HandshakeReq, opts ...grpc.CallOption) (*HandshakeResp, error) {
+	out := new(HandshakeResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/Handshake", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) Discover(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_DiscoverClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Plugin_serviceDesc.Streams[0], "/zhiting.sa.plugin.v2.Plugin/Discover", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pluginDiscoverClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Plugin_DiscoverClient interface {
+	Recv() (*Device, error)
+	grpc.ClientStream
+}
+
+type pluginDiscoverClient struct {
+	grpc.ClientStream
+}
+
+func (x *pluginDiscoverClient) Recv() (*Device, error) {
+	m := new(Device)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pluginClient) Subscribe(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Plugin_serviceDesc.Streams[1], "/zhiting.sa.plugin.v2.Plugin/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pluginSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Plugin_SubscribeClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type pluginSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *pluginSubscribeClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pluginClient) HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...grpc.CallOption) (*HealthCheckResp, error) {
+	out := new(HealthCheckResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/HealthCheck", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) Ping(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/Ping", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) OTA(ctx context.Context, in *OTAReq, opts ...grpc.CallOption) (Plugin_OTAClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Plugin_serviceDesc.Streams[2], "/zhiting.sa.plugin.v2.Plugin/OTA", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &pluginOTAClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Plugin_OTAClient interface {
+	Recv() (*OTAResp, error)
+	grpc.ClientStream
+}
+
+type pluginOTAClient struct {
+	grpc.ClientStream
+}
+
+func (x *pluginOTAClient) Recv() (*OTAResp, error) {
+	m := new(OTAResp)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *pluginClient) Connect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*GetInstancesResp, error) {
+	out := new(GetInstancesResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/Connect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) Disconnect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/Disconnect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) GetInstances(ctx context.Context, in *GetInstancesReq, opts ...grpc.CallOption) (*GetInstancesResp, error) {
+	out := new(GetInstancesResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/GetInstances", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) SetAttributes(ctx context.Context, in *SetAttributesReq, opts ...grpc.CallOption) (*SetAttributesResp, error) {
+	out := new(SetAttributesResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/SetAttributes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) GetAttributes(ctx context.Context, in *GetAttributesReq, opts ...grpc.CallOption) (*GetAttributesResp, error) {
+	out := new(GetAttributesResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/GetAttributes", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) InvokeAction(ctx context.Context, in *InvokeActionReq, opts ...grpc.CallOption) (*InvokeActionResp, error) {
+	out := new(InvokeActionResp)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/InvokeAction", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) UpdateSettings(ctx context.Context, in *UpdateSettingsReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/UpdateSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PluginServer is the server API for Plugin service.
+type PluginServer interface {
+	// Handshake 协商协议版本及插件支持的功能，SA连接插件时首先调用
+	Handshake(context.Context, *HandshakeReq) (*HandshakeResp, error)
+	// Discover 发现设备
+	Discover(*empty.Empty, Plugin_DiscoverServer) error
+	// StateChange 监听所有设备状态变化
+	Subscribe(*empty.Empty, Plugin_SubscribeServer) error
+	HealthCheck(context.Context, *HealthCheckReq) (*HealthCheckResp, error)
+	// Ping 插件服务存活检查
+	Ping(context.Context, *empty.Empty) (*empty.Empty, error)
+	OTA(*OTAReq, Plugin_OTAServer) error
+	Connect(context.Context, *AuthReq) (*GetInstancesResp, error)
+	Disconnect(context.Context, *AuthReq) (*empty.Empty, error)
+	// GetInstances 获取设备所有实例
+	GetInstances(context.Context, *GetInstancesReq) (*GetInstancesResp, error)
+	// SetAttributes 设置属性
+	SetAttributes(context.Context, *SetAttributesReq) (*SetAttributesResp, error)
+	// GetAttributes 主动从设备读取属性的最新值
+	GetAttributes(context.Context, *GetAttributesReq) (*GetAttributesResp, error)
+	// InvokeAction 执行设备的动作
+	InvokeAction(context.Context, *InvokeActionReq) (*InvokeActionResp, error)
+	// UpdateSettings 下发插件设置，插件启动连接时及设置修改时调用
+	UpdateSettings(context.Context, *UpdateSettingsReq) (*empty.Empty, error)
+}
+
+// UnimplementedPluginServer can be embedded to have forward compatible implementations.
+type UnimplementedPluginServer struct {
+}
+
+func (*UnimplementedPluginServer) Handshake(context.Context, *HandshakeReq) (*HandshakeResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Handshake not implemented")
+}
+func (*UnimplementedPluginServer) Discover(*empty.Empty, Plugin_DiscoverServer) error {
+	return status.Errorf(codes.Unimplemented, "method Discover not implemented")
+}
+func (*UnimplementedPluginServer) Subscribe(*empty.Empty, Plugin_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (*UnimplementedPluginServer) HealthCheck(context.Context, *HealthCheckReq) (*HealthCheckResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
+}
+func (*UnimplementedPluginServer) Ping(context.Context, *empty.Empty) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (*UnimplementedPluginServer) OTA(*OTAReq, Plugin_OTAServer) error {
+	return status.Errorf(codes.Unimplemented, "method OTA not implemented")
+}
+func (*UnimplementedPluginServer) Connect(context.Context, *AuthReq) (*GetInstancesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+func (*UnimplementedPluginServer) Disconnect(context.Context, *AuthReq) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Disconnect not implemented")
+}
+func (*UnimplementedPluginServer) GetInstances(context.Context, *GetInstancesReq) (*GetInstancesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInstances not implemented")
+}
+func (*UnimplementedPluginServer) SetAttributes(context.Context, *SetAttributesReq) (*SetAttributesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAttributes not implemented")
+}
+func (*UnimplementedPluginServer) GetAttributes(context.Context, *GetAttributesReq) (*GetAttributesResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAttributes not implemented")
+}
+func (*UnimplementedPluginServer) InvokeAction(context.Context, *InvokeActionReq) (*InvokeActionResp, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvokeAction not implemented")
+}
+func (*UnimplementedPluginServer) UpdateSettings(context.Context, *UpdateSettingsReq) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSettings not implemented")
+}
+
+func RegisterPluginServer(s *grpc.Server, srv PluginServer) {
+	s.RegisterService(&_Plugin_serviceDesc, srv)
+}
+
+func _Plugin_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandshakeReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Handshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/Handshake",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Handshake(ctx, req.(*HandshakeReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_Discover_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(empty.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PluginServer).Discover(m, &pluginDiscoverServer{stream})
+}
+
+type Plugin_DiscoverServer interface {
+	Send(*Device) error
+	grpc.ServerStream
+}
+
+type pluginDiscoverServer struct {
+	grpc.ServerStream
+}
+
+func (x *pluginDiscoverServer) Send(m *Device) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Plugin_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(empty.Empty)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PluginServer).Subscribe(m, &pluginSubscribeServer{stream})
+}
+
+type Plugin_SubscribeServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type pluginSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *pluginSubscribeServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Plugin_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthCheckReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).HealthCheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/HealthCheck",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).HealthCheck(ctx, req.(*HealthCheckReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(empty.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/Ping",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Ping(ctx, req.(*empty.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_OTA_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(OTAReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PluginServer).OTA(m, &pluginOTAServer{stream})
+}
+
+type Plugin_OTAServer interface {
+	Send(*OTAResp) error
+	grpc.ServerStream
+}
+
+type pluginOTAServer struct {
+	grpc.ServerStream
+}
+
+func (x *pluginOTAServer) Send(m *OTAResp) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Plugin_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Connect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/Connect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Connect(ctx, req.(*AuthReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Disconnect(ctx, in)
 	}
-	type x struct{}
-	out := protoimpl.TypeBuilder{
-		File: protoimpl.DescBuilder{
-			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: file_v2_plugin_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   12,
-			NumExtensions: 0,
-			NumServices:   1,
-		},
-		GoTypes:           file_v2_plugin_proto_goTypes,
-		DependencyIndexes: file_v2_plugin_proto_depIdxs,
-		MessageInfos:      file_v2_plugin_proto_msgTypes,
-	}.Build()
-	File_v2_plugin_proto = out.File
-	file_v2_plugin_proto_rawDesc = nil
-	file_v2_plugin_proto_goTypes = nil
-	file_v2_plugin_proto_depIdxs = nil
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/Disconnect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Disconnect(ctx, req.(*AuthReq))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConnInterface
+func _Plugin_GetInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInstancesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).GetInstances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/GetInstances",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).GetInstances(ctx, req.(*GetInstancesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion6
+func _Plugin_SetAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAttributesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).SetAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/SetAttributes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).SetAttributes(ctx, req.(*SetAttributesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
 
-// PluginClient is the client API for Plugin service.
+func _Plugin_GetAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAttributesReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).GetAttributes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/GetAttributes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).GetAttributes(ctx, req.(*GetAttributesReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_InvokeAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvokeActionReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).InvokeAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/InvokeAction",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).InvokeAction(ctx, req.(*InvokeActionReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_UpdateSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSettingsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).UpdateSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Plugin/UpdateSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).UpdateSettings(ctx, req.(*UpdateSettingsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Plugin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "zhiting.sa.plugin.v2.Plugin",
+	HandlerType: (*PluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Handshake",
+			Handler:    _Plugin_Handshake_Handler,
+		},
+		{
+			MethodName: "HealthCheck",
+			Handler:    _Plugin_HealthCheck_Handler,
+		},
+		{
+			MethodName: "Ping",
+			Handler:    _Plugin_Ping_Handler,
+		},
+		{
+			MethodName: "Connect",
+			Handler:    _Plugin_Connect_Handler,
+		},
+		{
+			MethodName: "Disconnect",
+			Handler:    _Plugin_Disconnect_Handler,
+		},
+		{
+			MethodName: "GetInstances",
+			Handler:    _Plugin_GetInstances_Handler,
+		},
+		{
+			MethodName: "SetAttributes",
+			Handler:    _Plugin_SetAttributes_Handler,
+		},
+		{
+			MethodName: "GetAttributes",
+			Handler:    _Plugin_GetAttributes_Handler,
+		},
+		{
+			MethodName: "InvokeAction",
+			Handler:    _Plugin_InvokeAction_Handler,
+		},
+		{
+			MethodName: "UpdateSettings",
+			Handler:    _Plugin_UpdateSettings_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Discover",
+			Handler:       _Plugin_Discover_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Plugin_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "OTA",
+			Handler:       _Plugin_OTA_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v2/plugin.proto",
+}
+
+// StorageClient is the client API for Storage service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type PluginClient interface {
-	// Discover 发现设备
-	Discover(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_DiscoverClient, error)
-	// StateChange 监听所有设备状态变化
-	Subscribe(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_SubscribeClient, error)
-	HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...grpc.CallOption) (*HealthCheckResp, error)
-	OTA(ctx context.Context, in *OTAReq, opts ...grpc.CallOption) (Plugin_OTAClient, error)
-	Connect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*GetInstancesResp, error)
-	Disconnect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*empty.Empty, error)
-	// GetInstances 获取设备所有实例
-	GetInstances(ctx context.Context, in *GetInstancesReq, opts ...grpc.CallOption) (*GetInstancesResp, error)
-	// SetAttributes 设置属性
-	SetAttributes(ctx context.Context, in *SetAttributesReq, opts ...grpc.CallOption) (*SetAttributesResp, error)
+type StorageClient interface {
+	Get(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*StorageItem, error)
+	Put(ctx context.Context, in *StorageItem, opts ...grpc.CallOption) (*empty.Empty, error)
+	Delete(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*empty.Empty, error)
+	// List 获取指定前缀的所有键值
+	List(ctx context.Context, in *StoragePrefix, opts ...grpc.CallOption) (*StorageItems, error)
+	// Watch 监听指定前缀的键值变化
+	Watch(ctx context.Context, in *StoragePrefix, opts ...grpc.CallOption) (Storage_WatchClient, error)
 }
 
-type pluginClient struct {
+type storageClient struct {
 	cc grpc.ClientConnInterface
 }
 
-func NewPluginClient(cc grpc.ClientConnInterface) PluginClient {
-	return &pluginClient{cc}
+func NewStorageClient(cc grpc.ClientConnInterface) StorageClient {
+	return &storageClient{cc}
 }
 
-func (c *pluginClient) Discover(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_DiscoverClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Plugin_serviceDesc.Streams[0], "/zhiting.sa.plugin.v2.Plugin/Discover", opts...)
+func (c *storageClient) Get(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*StorageItem, error) {
+	out := new(StorageItem)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Storage/Get", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
-	x := &pluginDiscoverClient{stream}
+	return out, nil
+}
+
+func (c *storageClient) Put(ctx context.Context, in *StorageItem, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Storage/Put", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Delete(ctx context.Context, in *StorageKey, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Storage/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) List(ctx context.Context, in *StoragePrefix, opts ...grpc.CallOption) (*StorageItems, error) {
+	out := new(StorageItems)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Storage/List", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageClient) Watch(ctx context.Context, in *StoragePrefix, opts ...grpc.CallOption) (Storage_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Storage_serviceDesc.Streams[0], "/zhiting.sa.plugin.v2.Storage/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageWatchClient{stream}
 	if err := x.ClientStream.SendMsg(in); err != nil {
 		return nil, err
 	}
@@ -1134,375 +3068,402 @@ func (c *pluginClient) Discover(ctx context.Context, in *empty.Empty, opts ...gr
 	return x, nil
 }
 
-type Plugin_DiscoverClient interface {
-	Recv() (*Device, error)
+type Storage_WatchClient interface {
+	Recv() (*StorageEvent, error)
 	grpc.ClientStream
 }
 
-type pluginDiscoverClient struct {
+type storageWatchClient struct {
 	grpc.ClientStream
 }
 
-func (x *pluginDiscoverClient) Recv() (*Device, error) {
-	m := new(Device)
+func (x *storageWatchClient) Recv() (*StorageEvent, error) {
+	m := new(StorageEvent)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
-	return m, nil
+	return m, nil
+}
+
+// StorageServer is the server API for Storage service.
+type StorageServer interface {
+	Get(context.Context, *StorageKey) (*StorageItem, error)
+	Put(context.Context, *StorageItem) (*empty.Empty, error)
+	Delete(context.Context, *StorageKey) (*empty.Empty, error)
+	// List 获取指定前缀的所有键值
+	List(context.Context, *StoragePrefix) (*StorageItems, error)
+	// Watch 监听指定前缀的键值变化
+	Watch(*StoragePrefix, Storage_WatchServer) error
+}
+
+// UnimplementedStorageServer can be embedded to have forward compatible implementations.
+type UnimplementedStorageServer struct {
+}
+
+func (*UnimplementedStorageServer) Get(context.Context, *StorageKey) (*StorageItem, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedStorageServer) Put(context.Context, *StorageItem) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Put not implemented")
+}
+func (*UnimplementedStorageServer) Delete(context.Context, *StorageKey) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedStorageServer) List(context.Context, *StoragePrefix) (*StorageItems, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
+}
+func (*UnimplementedStorageServer) Watch(*StoragePrefix, Storage_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+
+func RegisterStorageServer(s *grpc.Server, srv StorageServer) {
+	s.RegisterService(&_Storage_serviceDesc, srv)
+}
+
+func _Storage_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Storage/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Get(ctx, req.(*StorageKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Put_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageItem)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Put(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Storage/Put",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Put(ctx, req.(*StorageItem))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Storage_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StorageKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Storage/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).Delete(ctx, req.(*StorageKey))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *pluginClient) Subscribe(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (Plugin_SubscribeClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Plugin_serviceDesc.Streams[1], "/zhiting.sa.plugin.v2.Plugin/Subscribe", opts...)
-	if err != nil {
+func _Storage_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StoragePrefix)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	x := &pluginSubscribeClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
+	if interceptor == nil {
+		return srv.(StorageServer).List(ctx, in)
 	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/zhiting.sa.plugin.v2.Storage/List",
 	}
-	return x, nil
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServer).List(ctx, req.(*StoragePrefix))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-type Plugin_SubscribeClient interface {
-	Recv() (*Event, error)
-	grpc.ClientStream
+func _Storage_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StoragePrefix)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServer).Watch(m, &storageWatchServer{stream})
 }
 
-type pluginSubscribeClient struct {
-	grpc.ClientStream
+type Storage_WatchServer interface {
+	Send(*StorageEvent) error
+	grpc.ServerStream
 }
 
-func (x *pluginSubscribeClient) Recv() (*Event, error) {
-	m := new(Event)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
-	}
-	return m, nil
+type storageWatchServer struct {
+	grpc.ServerStream
 }
 
-func (c *pluginClient) HealthCheck(ctx context.Context, in *HealthCheckReq, opts ...grpc.CallOption) (*HealthCheckResp, error) {
-	out := new(HealthCheckResp)
-	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/HealthCheck", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+func (x *storageWatchServer) Send(m *StorageEvent) error {
+	return x.ServerStream.SendMsg(m)
 }
 
-func (c *pluginClient) OTA(ctx context.Context, in *OTAReq, opts ...grpc.CallOption) (Plugin_OTAClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Plugin_serviceDesc.Streams[2], "/zhiting.sa.plugin.v2.Plugin/OTA", opts...)
-	if err != nil {
-		return nil, err
-	}
-	x := &pluginOTAClient{stream}
-	if err := x.ClientStream.SendMsg(in); err != nil {
-		return nil, err
-	}
-	if err := x.ClientStream.CloseSend(); err != nil {
-		return nil, err
-	}
-	return x, nil
+var _Storage_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "zhiting.sa.plugin.v2.Storage",
+	HandlerType: (*StorageServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Storage_Get_Handler,
+		},
+		{
+			MethodName: "Put",
+			Handler:    _Storage_Put_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Storage_Delete_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Storage_List_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _Storage_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "v2/plugin.proto",
 }
 
-type Plugin_OTAClient interface {
-	Recv() (*OTAResp, error)
-	grpc.ClientStream
+// RegistryClient is the client API for Registry service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RegistryClient interface {
+	// Grant 创建租约
+	Grant(ctx context.Context, in *LeaseGrantReq, opts ...grpc.CallOption) (*Lease, error)
+	// KeepAlive 续约，租约已过期时返回NotFound
+	KeepAlive(ctx context.Context, in *Lease, opts ...grpc.CallOption) (*Lease, error)
+	// Revoke 撤销租约，并删除租约下的服务
+	Revoke(ctx context.Context, in *Lease, opts ...grpc.CallOption) (*empty.Empty, error)
+	// AddEndpoint 注册服务，服务随租约过期删除
+	AddEndpoint(ctx context.Context, in *RegistryEndpoint, opts ...grpc.CallOption) (*empty.Empty, error)
+	DeleteEndpoint(ctx context.Context, in *RegistryKey, opts ...grpc.CallOption) (*empty.Empty, error)
+}
+
+type registryClient struct {
+	cc grpc.ClientConnInterface
 }
 
-type pluginOTAClient struct {
-	grpc.ClientStream
+func NewRegistryClient(cc grpc.ClientConnInterface) RegistryClient {
+	return &registryClient{cc}
 }
 
-func (x *pluginOTAClient) Recv() (*OTAResp, error) {
-	m := new(OTAResp)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
+func (c *registryClient) Grant(ctx context.Context, in *LeaseGrantReq, opts ...grpc.CallOption) (*Lease, error) {
+	out := new(Lease)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Registry/Grant", in, out, opts...)
+	if err != nil {
 		return nil, err
 	}
-	return m, nil
+	return out, nil
 }
 
-func (c *pluginClient) Connect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*GetInstancesResp, error) {
-	out := new(GetInstancesResp)
-	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/Connect", in, out, opts...)
+func (c *registryClient) KeepAlive(ctx context.Context, in *Lease, opts ...grpc.CallOption) (*Lease, error) {
+	out := new(Lease)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Registry/KeepAlive", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *pluginClient) Disconnect(ctx context.Context, in *AuthReq, opts ...grpc.CallOption) (*empty.Empty, error) {
+func (c *registryClient) Revoke(ctx context.Context, in *Lease, opts ...grpc.CallOption) (*empty.Empty, error) {
 	out := new(empty.Empty)
-	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/Disconnect", in, out, opts...)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Registry/Revoke", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *pluginClient) GetInstances(ctx context.Context, in *GetInstancesReq, opts ...grpc.CallOption) (*GetInstancesResp, error) {
-	out := new(GetInstancesResp)
-	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/GetInstances", in, out, opts...)
+func (c *registryClient) AddEndpoint(ctx context.Context, in *RegistryEndpoint, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Registry/AddEndpoint", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-func (c *pluginClient) SetAttributes(ctx context.Context, in *SetAttributesReq, opts ...grpc.CallOption) (*SetAttributesResp, error) {
-	out := new(SetAttributesResp)
-	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Plugin/SetAttributes", in, out, opts...)
+func (c *registryClient) DeleteEndpoint(ctx context.Context, in *RegistryKey, opts ...grpc.CallOption) (*empty.Empty, error) {
+	out := new(empty.Empty)
+	err := c.cc.Invoke(ctx, "/zhiting.sa.plugin.v2.Registry/DeleteEndpoint", in, out, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return out, nil
 }
 
-// PluginServer is the server API for Plugin service.
-type PluginServer interface {
-	// Discover 发现设备
-	Discover(*empty.Empty, Plugin_DiscoverServer) error
-	// StateChange 监听所有设备状态变化
-	Subscribe(*empty.Empty, Plugin_SubscribeServer) error
-	HealthCheck(context.Context, *HealthCheckReq) (*HealthCheckResp, error)
-	OTA(*OTAReq, Plugin_OTAServer) error
-	Connect(context.Context, *AuthReq) (*GetInstancesResp, error)
-	Disconnect(context.Context, *AuthReq) (*empty.Empty, error)
-	// GetInstances 获取设备所有实例
-	GetInstances(context.Context, *GetInstancesReq) (*GetInstancesResp, error)
-	// SetAttributes 设置属性
-	SetAttributes(context.Context, *SetAttributesReq) (*SetAttributesResp, error)
-}
-
-// UnimplementedPluginServer can be embedded to have forward compatible implementations.
-type UnimplementedPluginServer struct {
-}
-
-func (*UnimplementedPluginServer) Discover(*empty.Empty, Plugin_DiscoverServer) error {
-	return status.Errorf(codes.Unimplemented, "method Discover not implemented")
-}
-func (*UnimplementedPluginServer) Subscribe(*empty.Empty, Plugin_SubscribeServer) error {
-	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
-}
-func (*UnimplementedPluginServer) HealthCheck(context.Context, *HealthCheckReq) (*HealthCheckResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
-}
-func (*UnimplementedPluginServer) OTA(*OTAReq, Plugin_OTAServer) error {
-	return status.Errorf(codes.Unimplemented, "method OTA not implemented")
-}
-func (*UnimplementedPluginServer) Connect(context.Context, *AuthReq) (*GetInstancesResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Connect not implemented")
-}
-func (*UnimplementedPluginServer) Disconnect(context.Context, *AuthReq) (*empty.Empty, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Disconnect not implemented")
-}
-func (*UnimplementedPluginServer) GetInstances(context.Context, *GetInstancesReq) (*GetInstancesResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method GetInstances not implemented")
-}
-func (*UnimplementedPluginServer) SetAttributes(context.Context, *SetAttributesReq) (*SetAttributesResp, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method SetAttributes not implemented")
-}
-
-func RegisterPluginServer(s *grpc.Server, srv PluginServer) {
-	s.RegisterService(&_Plugin_serviceDesc, srv)
-}
-
-func _Plugin_Discover_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(empty.Empty)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(PluginServer).Discover(m, &pluginDiscoverServer{stream})
+// RegistryServer is the server API for Registry service.
+type RegistryServer interface {
+	// Grant 创建租约
+	Grant(context.Context, *LeaseGrantReq) (*Lease, error)
+	// KeepAlive 续约，租约已过期时返回NotFound
+	KeepAlive(context.Context, *Lease) (*Lease, error)
+	// Revoke 撤销租约，并删除租约下的服务
+	Revoke(context.Context, *Lease) (*empty.Empty, error)
+	// AddEndpoint 注册服务，服务随租约过期删除
+	AddEndpoint(context.Context, *RegistryEndpoint) (*empty.Empty, error)
+	DeleteEndpoint(context.Context, *RegistryKey) (*empty.Empty, error)
 }
 
-type Plugin_DiscoverServer interface {
-	Send(*Device) error
-	grpc.ServerStream
+// UnimplementedRegistryServer can be embedded to have forward compatible implementations.
+type UnimplementedRegistryServer struct {
 }
 
-type pluginDiscoverServer struct {
-	grpc.ServerStream
+func (*UnimplementedRegistryServer) Grant(context.Context, *LeaseGrantReq) (*Lease, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Grant not implemented")
 }
-
-func (x *pluginDiscoverServer) Send(m *Device) error {
-	return x.ServerStream.SendMsg(m)
+func (*UnimplementedRegistryServer) KeepAlive(context.Context, *Lease) (*Lease, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KeepAlive not implemented")
 }
-
-func _Plugin_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(empty.Empty)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(PluginServer).Subscribe(m, &pluginSubscribeServer{stream})
+func (*UnimplementedRegistryServer) Revoke(context.Context, *Lease) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Revoke not implemented")
 }
-
-type Plugin_SubscribeServer interface {
-	Send(*Event) error
-	grpc.ServerStream
+func (*UnimplementedRegistryServer) AddEndpoint(context.Context, *RegistryEndpoint) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddEndpoint not implemented")
 }
-
-type pluginSubscribeServer struct {
-	grpc.ServerStream
+func (*UnimplementedRegistryServer) DeleteEndpoint(context.Context, *RegistryKey) (*empty.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteEndpoint not implemented")
 }
 
-func (x *pluginSubscribeServer) Send(m *Event) error {
-	return x.ServerStream.SendMsg(m)
+func RegisterRegistryServer(s *grpc.Server, srv RegistryServer) {
+	s.RegisterService(&_Registry_serviceDesc, srv)
 }
 
-func _Plugin_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(HealthCheckReq)
+func _Registry_Grant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseGrantReq)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PluginServer).HealthCheck(ctx, in)
+		return srv.(RegistryServer).Grant(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/zhiting.sa.plugin.v2.Plugin/HealthCheck",
+		FullMethod: "/zhiting.sa.plugin.v2.Registry/Grant",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PluginServer).HealthCheck(ctx, req.(*HealthCheckReq))
+		return srv.(RegistryServer).Grant(ctx, req.(*LeaseGrantReq))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Plugin_OTA_Handler(srv interface{}, stream grpc.ServerStream) error {
-	m := new(OTAReq)
-	if err := stream.RecvMsg(m); err != nil {
-		return err
-	}
-	return srv.(PluginServer).OTA(m, &pluginOTAServer{stream})
-}
-
-type Plugin_OTAServer interface {
-	Send(*OTAResp) error
-	grpc.ServerStream
-}
-
-type pluginOTAServer struct {
-	grpc.ServerStream
-}
-
-func (x *pluginOTAServer) Send(m *OTAResp) error {
-	return x.ServerStream.SendMsg(m)
-}
-
-func _Plugin_Connect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AuthReq)
+func _Registry_KeepAlive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Lease)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PluginServer).Connect(ctx, in)
+		return srv.(RegistryServer).KeepAlive(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/zhiting.sa.plugin.v2.Plugin/Connect",
+		FullMethod: "/zhiting.sa.plugin.v2.Registry/KeepAlive",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PluginServer).Connect(ctx, req.(*AuthReq))
+		return srv.(RegistryServer).KeepAlive(ctx, req.(*Lease))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Plugin_Disconnect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(AuthReq)
+func _Registry_Revoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Lease)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PluginServer).Disconnect(ctx, in)
+		return srv.(RegistryServer).Revoke(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/zhiting.sa.plugin.v2.Plugin/Disconnect",
+		FullMethod: "/zhiting.sa.plugin.v2.Registry/Revoke",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PluginServer).Disconnect(ctx, req.(*AuthReq))
+		return srv.(RegistryServer).Revoke(ctx, req.(*Lease))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Plugin_GetInstances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetInstancesReq)
+func _Registry_AddEndpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegistryEndpoint)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PluginServer).GetInstances(ctx, in)
+		return srv.(RegistryServer).AddEndpoint(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/zhiting.sa.plugin.v2.Plugin/GetInstances",
+		FullMethod: "/zhiting.sa.plugin.v2.Registry/AddEndpoint",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PluginServer).GetInstances(ctx, req.(*GetInstancesReq))
+		return srv.(RegistryServer).AddEndpoint(ctx, req.(*RegistryEndpoint))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _Plugin_SetAttributes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SetAttributesReq)
+func _Registry_DeleteEndpoint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegistryKey)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(PluginServer).SetAttributes(ctx, in)
+		return srv.(RegistryServer).DeleteEndpoint(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/zhiting.sa.plugin.v2.Plugin/SetAttributes",
+		FullMethod: "/zhiting.sa.plugin.v2.Registry/DeleteEndpoint",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PluginServer).SetAttributes(ctx, req.(*SetAttributesReq))
+		return srv.(RegistryServer).DeleteEndpoint(ctx, req.(*RegistryKey))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-var _Plugin_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "zhiting.sa.plugin.v2.Plugin",
-	HandlerType: (*PluginServer)(nil),
+var _Registry_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "zhiting.sa.plugin.v2.Registry",
+	HandlerType: (*RegistryServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
-			MethodName: "HealthCheck",
-			Handler:    _Plugin_HealthCheck_Handler,
-		},
-		{
-			MethodName: "Connect",
-			Handler:    _Plugin_Connect_Handler,
-		},
-		{
-			MethodName: "Disconnect",
-			Handler:    _Plugin_Disconnect_Handler,
-		},
-		{
-			MethodName: "GetInstances",
-			Handler:    _Plugin_GetInstances_Handler,
+			MethodName: "Grant",
+			Handler:    _Registry_Grant_Handler,
 		},
 		{
-			MethodName: "SetAttributes",
-			Handler:    _Plugin_SetAttributes_Handler,
+			MethodName: "KeepAlive",
+			Handler:    _Registry_KeepAlive_Handler,
 		},
-	},
-	Streams: []grpc.StreamDesc{
 		{
-			StreamName:    "Discover",
-			Handler:       _Plugin_Discover_Handler,
-			ServerStreams: true,
+			MethodName: "Revoke",
+			Handler:    _Registry_Revoke_Handler,
 		},
 		{
-			StreamName:    "Subscribe",
-			Handler:       _Plugin_Subscribe_Handler,
-			ServerStreams: true,
+			MethodName: "AddEndpoint",
+			Handler:    _Registry_AddEndpoint_Handler,
 		},
 		{
-			StreamName:    "OTA",
-			Handler:       _Plugin_OTA_Handler,
-			ServerStreams: true,
+			MethodName: "DeleteEndpoint",
+			Handler:    _Registry_DeleteEndpoint_Handler,
 		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "v2/plugin.proto",
 }
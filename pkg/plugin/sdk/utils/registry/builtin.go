@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/naming/endpoints"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/zhiting-tech/smartassistant/pkg/logger"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+)
+
+var errNoSAAddr = errors.New("SA_GRPC_ADDR is required by builtin registry")
+
+// dialBuiltin 连接SA内置的注册中心，地址与存储服务相同
+func dialBuiltin() (conn *grpc.ClientConn, err error) {
+	addr := os.Getenv("SA_GRPC_ADDR")
+	if addr == "" {
+		return nil, errNoSAAddr
+	}
+	return grpc.Dial(addr, grpc.WithInsecure())
+}
+
+// authContext 携带SA启动插件时设置的凭证，SA只允许插件注册自己的服务
+func authContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		MetaPluginID, os.Getenv("PLUGIN_DOMAIN"),
+		MetaAreaID, os.Getenv("AREA_ID"),
+		MetaToken, os.Getenv("SA_STORAGE_TOKEN"),
+	)
+}
+
+func registerBuiltin(ctx context.Context, key string, endpoint endpoints.Endpoint) {
+	logger.Info("register service to builtin registry:", key, endpoint.Addr)
+	ctx = authContext(ctx)
+	conn, err := dialBuiltin()
+	if err != nil {
+		logger.Errorf("dial registry err: %s", err.Error())
+		return
+	}
+	defer conn.Close()
+	cli := proto.NewRegistryClient(conn)
+	for {
+		if err = registerWithLease(ctx, cli, key, endpoint); err != nil {
+			logger.Errorf("register service err: %s", err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// registerWithLease 创建租约并注册服务，续约失败（如SA重启后租约丢失）时返回
+func registerWithLease(ctx context.Context, cli proto.RegistryClient, key string, endpoint endpoints.Endpoint) (err error) {
+	metadata, err := json.Marshal(endpoint.Metadata)
+	if err != nil {
+		return
+	}
+	lease, err := cli.Grant(ctx, &proto.LeaseGrantReq{Ttl: registerTTL})
+	if err != nil {
+		return
+	}
+	req := proto.RegistryEndpoint{
+		Key:      key,
+		Addr:     endpoint.Addr,
+		Metadata: metadata,
+		LeaseId:  lease.Id,
+	}
+	if _, err = cli.AddEndpoint(ctx, &req); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(lease.Ttl) * time.Second / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err = cli.KeepAlive(ctx, lease); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func unregisterBuiltin(ctx context.Context, key string) (err error) {
+	logger.Info("unregister service:", key)
+	conn, err := dialBuiltin()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_, err = proto.NewRegistryClient(conn).DeleteEndpoint(authContext(ctx), &proto.RegistryKey{Key: key})
+	return
+}
@@ -3,82 +3,54 @@ package registry
 import (
 	"context"
 	"fmt"
-	"time"
+	"os"
 
-	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/naming/endpoints"
-
-	"github.com/zhiting-tech/smartassistant/pkg/logger"
 )
 
 const (
 	registerTTL = 10
 
-	etcdURL = "http://0.0.0.0:2379"
-
 	ManagerTarget = "/sa/plugins"
 )
 
-func EndpointsKey(service string) string {
-	return fmt.Sprintf("%s/%s", ManagerTarget, service)
-}
+// 注册中心类型，由SA启动插件时通过环境变量 SA_REGISTRY 指定
+const (
+	TypeEtcd    = "etcd"    // 外部etcd，默认
+	TypeBuiltin = "builtin" // SA内置的注册中心
+)
 
-// RegisterService 注册服务
-func RegisterService(ctx context.Context, key string, endpoint endpoints.Endpoint) {
-	logger.Info("register service:", key, endpoint.Addr)
-	cli, err := clientv3.NewFromURL(etcdURL)
-	if err != nil {
-		logger.Errorf("new etcd client err: %s", err.Error())
-		return
-	}
-	defer cli.Close()
-	for {
-		if err = register(ctx, cli, key, endpoint); err != nil {
-			logger.Errorf("register service err: %s", err.Error())
-		}
-		time.Sleep(time.Second)
-	}
-}
+// 请求SA内置注册中心时携带的插件身份信息，与存储服务的凭证相同
+const (
+	MetaPluginID = "plugin-id"
+	MetaAreaID   = "area-id"
+	MetaToken    = "storage-token"
+)
 
-func register(ctx context.Context, cli *clientv3.Client, key string, endpoint endpoints.Endpoint) (err error) {
-	em, err := endpoints.NewManager(cli, ManagerTarget)
-	if err != nil {
-		return
+func registryType() string {
+	if t := os.Getenv("SA_REGISTRY"); t != "" {
+		return t
 	}
+	return TypeEtcd
+}
 
-	lease := clientv3.NewLease(cli)
-	resp, err := lease.Grant(ctx, registerTTL)
-	if err != nil {
-		return
-	}
-	kl, err := lease.KeepAlive(ctx, resp.ID)
-	if err != nil {
-		return
-	}
+func EndpointsKey(service string) string {
+	return fmt.Sprintf("%s/%s", ManagerTarget, service)
+}
 
-	err = em.AddEndpoint(ctx, key, endpoint, clientv3.WithLease(resp.ID))
-	if err != nil {
+// RegisterService 注册服务，并持续续约直到ctx结束
+func RegisterService(ctx context.Context, key string, endpoint endpoints.Endpoint) {
+	if registryType() == TypeBuiltin {
+		registerBuiltin(ctx, key, endpoint)
 		return
 	}
-	for {
-		if _, ok := <-kl; !ok {
-			time.Sleep(time.Second)
-			return register(ctx, cli, key, endpoint)
-		}
-	}
+	registerEtcd(ctx, key, endpoint)
 }
 
 // UnregisterService 取消注册服务
 func UnregisterService(ctx context.Context, key string) (err error) {
-	logger.Info("unregister service:", key)
-	cli, err := clientv3.NewFromURL(etcdURL)
-	if err != nil {
-		return
+	if registryType() == TypeBuiltin {
+		return unregisterBuiltin(ctx, key)
 	}
-	em, err := endpoints.NewManager(cli, ManagerTarget)
-	if err != nil {
-		return
-	}
-
-	return em.DeleteEndpoint(ctx, key)
+	return unregisterEtcd(ctx, key)
 }
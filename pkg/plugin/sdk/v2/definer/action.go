@@ -0,0 +1,49 @@
+package definer
+
+import (
+	"errors"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+var ActionNotFoundErr = errors.New("action not found")
+
+// ActionHandler 动作的实现，返回输出参数
+type ActionHandler func(inputs map[string]interface{}) (outputs map[string]interface{}, err error)
+
+func NewAction(action thingmodel.Action, handler ActionHandler) *Action {
+	return &Action{
+		meta:    &action,
+		handler: handler,
+	}
+}
+
+type Action struct {
+	meta    *thingmodel.Action
+	handler ActionHandler
+}
+
+// Invoke 校验输入参数并执行动作
+func (a *Action) Invoke(inputs map[string]interface{}) (map[string]interface{}, error) {
+	if a.handler == nil {
+		return nil, NotEnableErr
+	}
+	inputs, err := a.meta.ValidateInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range a.meta.Inputs {
+		if _, ok := inputs[p.Name]; !ok && p.Default != nil {
+			if inputs == nil {
+				inputs = make(map[string]interface{})
+			}
+			inputs[p.Name] = p.Default
+		}
+	}
+	return a.handler(inputs)
+}
+
+// Type 动作类型
+func (a *Action) Type() thingmodel.Action {
+	return *a.meta
+}
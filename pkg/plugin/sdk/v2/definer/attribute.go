@@ -43,6 +43,24 @@ func (a *Attribute) SetRange(min, max interface{}) *Attribute {
 	return a
 }
 
+// SetOptions 设置可选项，SA只允许设置为可选项中的值
+func (a *Attribute) SetOptions(options ...thingmodel.Option) *Attribute {
+	a.meta.Options = options
+	return a
+}
+
+// SetUnit 设置值的单位，使用设备原生的单位，SA按用户选择的单位制换算
+func (a *Attribute) SetUnit(unit thingmodel.Unit) *Attribute {
+	a.meta.Unit = unit
+	return a
+}
+
+// SetPrecision 设置浮点数的精度（小数位数）
+func (a *Attribute) SetPrecision(precision int) *Attribute {
+	a.meta.Precision = &precision
+	return a
+}
+
 // Type 属性类型
 func (a *Attribute) Type() thingmodel.Attribute {
 	return *a.meta
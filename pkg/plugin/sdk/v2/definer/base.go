@@ -13,6 +13,8 @@ func NewService(serviceType thingmodel.ServiceType) *BaseService {
 	b := BaseService{
 		serviceType:  serviceType,
 		attributeMap: make(map[string]*Attribute),
+		actionMap:    make(map[string]*Action),
+		eventMap:     make(map[string]thingmodel.Event),
 	}
 
 	return &b
@@ -24,6 +26,8 @@ type BaseService struct {
 	iid          string
 	_instance    *Instance
 	attributeMap map[string]*Attribute
+	actionMap    map[string]*Action
+	eventMap     map[string]thingmodel.Event
 }
 
 func (b BaseService) Type() thingmodel.ServiceType {
@@ -70,6 +74,17 @@ func (b *BaseService) WithAttribute(attr thingmodel.Attribute) *Attribute {
 	return a
 }
 
+// WithEnergyMonitoring 为插座、开关等服务添加功率、电压、电流及累计用电量属性，
+// 累计用电量（kWh）用于统计能耗，设备不支持的属性不需要调用Enable
+func (b *BaseService) WithEnergyMonitoring() *BaseService {
+	return b.WithAttributes(
+		thingmodel.Power,
+		thingmodel.Voltage,
+		thingmodel.ElectricCurrent,
+		thingmodel.Energy,
+	)
+}
+
 func (b *BaseService) Enable(attrType thingmodel.Attribute, getSetter thingmodel.IAttribute) *Attribute {
 	a := b.attributeMap[attrType.String()]
 	if a == nil {
@@ -107,3 +122,23 @@ func (b *BaseService) SetNotifyFunc(iid string, nf NotifyFunc) {
 func (b *BaseService) GetAttribute(attrType thingmodel.Attribute) *Attribute {
 	return b.attributeMap[attrType.String()]
 }
+
+// WithAction 为服务添加动作及其实现
+func (b *BaseService) WithAction(action thingmodel.Action, handler ActionHandler) *Action {
+	a := NewAction(action, handler)
+	b.actionMap[action.String()] = a
+	b._instance.AddAction(a)
+	return a
+}
+
+func (b *BaseService) GetAction(action thingmodel.Action) *Action {
+	return b.actionMap[action.String()]
+}
+
+// WithEvent 为服务添加无状态事件，通过 Instance.EmitEvent 发送
+func (b *BaseService) WithEvent(events ...thingmodel.Event) *BaseService {
+	for _, e := range events {
+		b.eventMap[e.String()] = e
+	}
+	return b
+}
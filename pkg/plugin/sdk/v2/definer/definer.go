@@ -3,6 +3,7 @@ package definer
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -15,6 +16,7 @@ var NotFoundErr = errors.New("attribute not found")
 
 type NotifyFunc func(event AttributeEvent) error
 type ThingModelNotifyFunc func(iid string, event ThingModelEvent) error
+type EventNotifyFunc func(event DeviceEvent) error
 
 type SetRequest struct {
 	IID   string
@@ -51,6 +53,7 @@ type Definer struct {
 
 	notifyFunc           NotifyFunc
 	thingModelNotifyFunc ThingModelNotifyFunc
+	eventNotifyFunc      EventNotifyFunc
 
 	sync.Mutex
 }
@@ -177,11 +180,17 @@ func (t *Definer) FromJSON(data []byte) {
 	return
 }
 
+// SetEventNotifyFunc 设置无状态事件的通知函数，需要在 SetNotifyFunc 之前调用
+func (t *Definer) SetEventNotifyFunc(fn EventNotifyFunc) {
+	t.eventNotifyFunc = fn
+}
+
 func (t *Definer) SetNotifyFunc() {
 	logrus.Debug("definer set notify func")
 	t.Lock()
 	defer t.Unlock()
 	for _, i := range t.instanceMap {
+		i.eventNotifyFunc = t.eventNotifyFunc
 		for _, a := range i.Services {
 			a.SetNotifyFunc(i.IID, t.notifyFunc)
 		}
@@ -199,6 +208,12 @@ func (t *Definer) ThingModel() (tm thingmodel.ThingModel) {
 			for _, a := range s.attributeMap {
 				srv.Attributes = append(srv.Attributes, *a.meta)
 			}
+			for _, a := range s.actionMap {
+				srv.Actions = append(srv.Actions, *a.meta)
+			}
+			for _, e := range s.eventMap {
+				srv.Events = append(srv.Events, e)
+			}
 			ins.Services = append(ins.Services, srv)
 		}
 		if ins.IsBridge() {
@@ -219,13 +234,39 @@ func (t *Definer) SetAttribute(iid string, aid int, val interface{}) error {
 	return attr.Set(val)
 }
 
+// GetAttribute 获取实例的属性，不存在时返回nil
+func (t *Definer) GetAttribute(iid string, aid int) *Attribute {
+	t.Lock()
+	defer t.Unlock()
+	ins, ok := t.instanceMap[iid]
+	if !ok {
+		return nil
+	}
+	return ins.GetAttribute(aid)
+}
+
+// InvokeAction 执行实例的动作
+func (t *Definer) InvokeAction(iid string, actionType string, inputs map[string]interface{}) (map[string]interface{}, error) {
+	t.Lock()
+	ins, ok := t.instanceMap[iid]
+	t.Unlock()
+	if !ok {
+		return nil, ActionNotFoundErr
+	}
+	action := ins.GetAction(actionType)
+	if action == nil {
+		return nil, ActionNotFoundErr
+	}
+	return action.Invoke(inputs)
+}
+
 func (t *Definer) getAttribute(iid string, aid int) *Attribute {
 	ins := t.Instance(iid)
 	return ins.GetAttribute(aid)
 }
 
 func NewInstance(id string) *Instance {
-	ins := Instance{IID: id, attributes: make(map[int]*Attribute)}
+	ins := Instance{IID: id, attributes: make(map[int]*Attribute), actions: make(map[string]*Action)}
 	return &ins
 }
 
@@ -233,7 +274,10 @@ type Instance struct {
 	IID        string         `json:"iid"`
 	Services   []*BaseService `json:"services"`
 	attributes map[int]*Attribute
+	actions    map[string]*Action
 	i          int
+
+	eventNotifyFunc EventNotifyFunc
 }
 
 func (t *Instance) AddAttribute(attr *Attribute) {
@@ -250,6 +294,36 @@ func (t *Instance) GetAttribute(aid int) *Attribute {
 	return t.attributes[aid]
 }
 
+func (t *Instance) AddAction(action *Action) {
+	t.actions[action.meta.Type] = action
+}
+
+func (t *Instance) GetAction(actionType string) *Action {
+	return t.actions[actionType]
+}
+
+// EmitEvent 发送无状态事件，事件需要先通过 BaseService.WithEvent 定义
+func (t *Instance) EmitEvent(event thingmodel.Event, params map[string]interface{}) error {
+	var defined bool
+	for _, srv := range t.Services {
+		if _, ok := srv.eventMap[event.String()]; ok {
+			defined = true
+			break
+		}
+	}
+	if !defined {
+		return fmt.Errorf("emit event err, event:%s not found of %s", event.String(), t.IID)
+	}
+	if err := event.CheckParams(params); err != nil {
+		return err
+	}
+	if t.eventNotifyFunc == nil {
+		logrus.Warnf("event notify function not set: %s", event.String())
+		return nil
+	}
+	return t.eventNotifyFunc(DeviceEvent{IID: t.IID, Type: event.Type, Params: params})
+}
+
 func (t *Instance) NewService(serviceType thingmodel.ServiceType) *BaseService {
 	srv := NewService(serviceType)
 	srv._instance = t
@@ -414,6 +488,42 @@ func (t *Instance) NewMedia() *BaseService {
 	)
 }
 
+// NewEnergyMeter 电能计量，如电表、计量插座的独立计量通道
+func (t *Instance) NewEnergyMeter() *BaseService {
+	return t.NewService(thingmodel.EnergyMeter).WithEnergyMonitoring()
+}
+
+// NewThermostat 温控器，风速模式、回差、当前动作等可选属性通过 Enable 添加，
+// 设备支持的工作模式可通过 SetOptions 设置
+func (t *Instance) NewThermostat() *BaseService {
+	return t.NewService(thingmodel.Thermostat).WithAttributes(
+		thingmodel.CurrentTemperature,
+		thingmodel.TargetTemperature,
+		thingmodel.HVACMode,
+	)
+}
+
+// NewMediaPlayer 媒体播放器，如音箱、播放盒子，播放控制通过 WithMediaControls 添加，
+// 媒体信息通过 WithMediaMetadata 添加
+func (t *Instance) NewMediaPlayer() *BaseService {
+	return t.NewService(thingmodel.MediaPlayer).WithAttributes(
+		thingmodel.PlaybackState,
+		thingmodel.Volume,
+		thingmodel.Mute,
+	)
+}
+
+// NewTelevision 电视，输入源的可选值通过 SetOptions 设置
+func (t *Instance) NewTelevision() *BaseService {
+	return t.NewService(thingmodel.Television).WithAttributes(
+		thingmodel.OnOff,
+		thingmodel.InputSource,
+		thingmodel.PlaybackState,
+		thingmodel.Volume,
+		thingmodel.Mute,
+	)
+}
+
 type Service struct {
 	Type       thingmodel.ServiceType `json:"type"`
 	Attributes []Attribute            `json:"attributes"`
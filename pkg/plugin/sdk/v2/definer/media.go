@@ -0,0 +1,95 @@
+package definer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// MediaControls 媒体播放控制，必须支持播放和暂停，
+// 其他操作通过同时实现 MediaStopper、MediaSkipper、MediaSeeker 支持
+type MediaControls interface {
+	Play() error
+	Pause() error
+}
+
+// MediaStopper 支持停止播放
+type MediaStopper interface {
+	Stop() error
+}
+
+// MediaSkipper 支持上一个、下一个
+type MediaSkipper interface {
+	Next() error
+	Previous() error
+}
+
+// MediaSeeker 支持跳转播放进度
+type MediaSeeker interface {
+	Seek(position int) error // 秒
+}
+
+// WithMediaMetadata 添加当前媒体的标题、艺术家、专辑、封面、时长及播放进度属性，
+// 设备不支持的属性不需要调用Enable
+func (b *BaseService) WithMediaMetadata() *BaseService {
+	return b.WithAttributes(
+		thingmodel.MediaTitle,
+		thingmodel.MediaArtist,
+		thingmodel.MediaAlbum,
+		thingmodel.MediaImageURL,
+		thingmodel.MediaDuration,
+		thingmodel.MediaPosition,
+	)
+}
+
+func mediaHandler(f func() error) ActionHandler {
+	return func(map[string]interface{}) (map[string]interface{}, error) {
+		return nil, f()
+	}
+}
+
+// WithMediaControls 根据c实现的接口添加播放、暂停、停止、上一个、下一个及跳转动作
+func (b *BaseService) WithMediaControls(c MediaControls) *BaseService {
+	b.WithAction(thingmodel.MediaPlay, mediaHandler(c.Play))
+	b.WithAction(thingmodel.MediaPause, mediaHandler(c.Pause))
+	if s, ok := c.(MediaStopper); ok {
+		b.WithAction(thingmodel.MediaStop, mediaHandler(s.Stop))
+	}
+	if s, ok := c.(MediaSkipper); ok {
+		b.WithAction(thingmodel.MediaNext, mediaHandler(s.Next))
+		b.WithAction(thingmodel.MediaPrevious, mediaHandler(s.Previous))
+	}
+	if s, ok := c.(MediaSeeker); ok {
+		b.WithAction(thingmodel.MediaSeek, func(inputs map[string]interface{}) (map[string]interface{}, error) {
+			position, err := intInput(inputs["position"])
+			if err != nil || position < 0 {
+				return nil, fmt.Errorf("action %s: invalid position %v", thingmodel.MediaSeek, inputs["position"])
+			}
+			return nil, s.Seek(position)
+		})
+	}
+	return b
+}
+
+// intInput 将动作的整数输入参数转为int，参数经过json编解码后可能为float64
+func intInput(v interface{}) (int, error) {
+	switch n := v.(type) {
+	case int:
+		return n, nil
+	case int32:
+		return int(n), nil
+	case int64:
+		return int(n), nil
+	case float64:
+		if n != float64(int(n)) {
+			return 0, fmt.Errorf("%v is not an integer", v)
+		}
+		return int(n), nil
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err
+	default:
+		return 0, fmt.Errorf("%v is not an integer", v)
+	}
+}
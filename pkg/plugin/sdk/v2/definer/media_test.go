@@ -0,0 +1,177 @@
+package definer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// player 模拟播放器，记录执行的操作
+type player struct {
+	calls []string
+	seeks []int
+}
+
+func (p *player) Play() error {
+	p.calls = append(p.calls, "play")
+	return nil
+}
+
+func (p *player) Pause() error {
+	p.calls = append(p.calls, "pause")
+	return nil
+}
+
+// seekPlayer 支持停止、上一个、下一个及跳转的播放器
+type seekPlayer struct {
+	player
+}
+
+func (p *seekPlayer) Stop() error {
+	p.calls = append(p.calls, "stop")
+	return nil
+}
+
+func (p *seekPlayer) Next() error {
+	p.calls = append(p.calls, "next")
+	return nil
+}
+
+func (p *seekPlayer) Previous() error {
+	p.calls = append(p.calls, "previous")
+	return nil
+}
+
+func (p *seekPlayer) Seek(position int) error {
+	p.seeks = append(p.seeks, position)
+	return nil
+}
+
+func newMediaDefiner(iid string, c MediaControls, events *[]AttributeEvent) (*Definer, *BaseService) {
+	d := NewThingModelDefiner(iid, func(ev AttributeEvent) error {
+		*events = append(*events, ev)
+		return nil
+	}, func(string, ThingModelEvent) error {
+		return nil
+	})
+	srv := d.Instance(iid).NewMediaPlayer().WithMediaMetadata().WithMediaControls(c)
+	d.SetNotifyFunc()
+	return d, srv
+}
+
+func TestIntInput(t *testing.T) {
+	cases := []struct {
+		val    interface{}
+		expect int
+	}{
+		{30, 30},
+		{int32(30), 30},
+		{int64(30), 30},
+		{float64(30), 30}, // json解码后的数字
+		{json.Number("30"), 30},
+		{-5, -5}, // 范围由调用方校验
+	}
+	for _, c := range cases {
+		v, err := intInput(c.val)
+		if assert.NoError(t, err, "%#v", c.val) {
+			assert.Equal(t, c.expect, v, "%#v", c.val)
+		}
+	}
+
+	for _, val := range []interface{}{12.5, json.Number("12.5"), "30", nil, true} {
+		_, err := intInput(val)
+		assert.Error(t, err, "%#v", val)
+	}
+}
+
+func TestWithMediaControls(t *testing.T) {
+	var events []AttributeEvent
+	p := &player{}
+	d, _ := newMediaDefiner("basic", p, &events)
+
+	// 只实现播放和暂停时只有这两个动作
+	for _, action := range []thingmodel.Action{thingmodel.MediaPlay, thingmodel.MediaPause} {
+		_, err := d.InvokeAction("basic", action.Type, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"play", "pause"}, p.calls)
+	for _, action := range []thingmodel.Action{thingmodel.MediaStop, thingmodel.MediaNext,
+		thingmodel.MediaPrevious, thingmodel.MediaSeek} {
+		_, err := d.InvokeAction("basic", action.Type, nil)
+		assert.Equal(t, ActionNotFoundErr, err, action.Type)
+	}
+
+	sp := &seekPlayer{}
+	d, _ = newMediaDefiner("full", sp, &events)
+	for _, action := range []thingmodel.Action{thingmodel.MediaStop, thingmodel.MediaNext, thingmodel.MediaPrevious} {
+		_, err := d.InvokeAction("full", action.Type, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"stop", "next", "previous"}, sp.calls)
+
+	tm := d.ThingModel()
+	require.Len(t, tm.Instances, 1)
+	srv, ok := tm.Instances[0].GetService(thingmodel.MediaPlayer)
+	require.True(t, ok)
+	assert.Len(t, srv.Actions, 6)
+}
+
+func TestMediaSeek(t *testing.T) {
+	var events []AttributeEvent
+	p := &seekPlayer{}
+	d, _ := newMediaDefiner("seek", p, &events)
+
+	seek := func(position interface{}) error {
+		_, err := d.InvokeAction("seek", thingmodel.MediaSeek.Type, map[string]interface{}{"position": position})
+		return err
+	}
+	assert.NoError(t, seek(90))
+	assert.NoError(t, seek(float64(120)))
+	assert.NoError(t, seek(json.Number("150")))
+	assert.NoError(t, seek(0))
+	assert.Equal(t, []int{90, 120, 150, 0}, p.seeks)
+
+	// 小数、负数及缺少进度时不跳转
+	assert.Error(t, seek(12.5))
+	assert.Error(t, seek(-1))
+	assert.Error(t, seek(json.Number("-1")))
+	assert.Error(t, seek("abc"))
+	_, err := d.InvokeAction("seek", thingmodel.MediaSeek.Type, nil)
+	assert.Error(t, err)
+	assert.Len(t, p.seeks, 4)
+}
+
+func TestPlaybackStateCondition(t *testing.T) {
+	var events []AttributeEvent
+	d, srv := newMediaDefiner("speaker", &player{}, &events)
+	tv := NewThingModelDefiner("tv", func(AttributeEvent) error { return nil }, nil)
+	tv.Instance("tv").NewTelevision()
+
+	// 播放状态可作为场景的触发条件，媒体信息不可以
+	for _, ins := range []thingmodel.Instance{d.ThingModel().Instances[0], tv.ThingModel().Instances[0]} {
+		var found bool
+		for _, s := range ins.Services {
+			for _, attr := range s.Attributes {
+				switch attr.Type {
+				case thingmodel.PlaybackState.Type:
+					found = true
+					assert.True(t, attr.PermissionNotify())
+					assert.False(t, attr.PermissionSceneHidden())
+					assert.Contains(t, attr.Options, thingmodel.Option{Name: "播放中", Val: thingmodel.PlaybackStatePlaying})
+				case thingmodel.MediaTitle.Type, thingmodel.MediaPosition.Type:
+					assert.True(t, attr.PermissionSceneHidden(), attr.Type)
+				}
+			}
+		}
+		assert.True(t, found, ins.IID)
+	}
+
+	// 播放状态变化时上报，用于触发场景
+	require.NoError(t, srv.Notify(thingmodel.PlaybackState, thingmodel.PlaybackStatePlaying))
+	aid := srv.GetAttribute(thingmodel.PlaybackState).Type().AID
+	assert.Equal(t, []AttributeEvent{{IID: "speaker", AID: aid, Val: thingmodel.PlaybackStatePlaying}}, events)
+}
@@ -8,6 +8,13 @@ type AttributeEvent struct {
 	Val interface{} `json:"val"`
 }
 
+// DeviceEvent 设备的无状态事件
+type DeviceEvent struct {
+	IID    string                 `json:"iid"`
+	Type   string                 `json:"type"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
 type ThingModelEvent struct {
 	ThingModel thingmodel.ThingModel `json:"thingModel"`
 	IID        string
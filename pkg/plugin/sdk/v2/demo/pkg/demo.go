@@ -19,6 +19,10 @@ type DemoProtocolDevice struct {
 	switches map[string]*definer.BaseService
 }
 
+func (sd *DemoProtocolDevice) Address() string {
+	return ""
+}
+
 func (sd *DemoProtocolDevice) Connect() error {
 	return nil
 }
@@ -28,11 +32,10 @@ func (sd *DemoProtocolDevice) Disconnect(iid string) error {
 }
 
 func (sd *DemoProtocolDevice) Define(def *definer.Definer) {
-	sd.id = "id111"
 	// 连接设备，根据协议描述物模型
 
 	// gateway
-	sd.gateway = def.Instance("id111").NewGateway()
+	sd.gateway = def.Instance(sd.id).NewGateway()
 	sd.gateway.Enable(thingmodel.OnOff, OnOff{}).SetVal("on")
 
 	// sub device light
@@ -96,6 +99,15 @@ func (sd *DemoProtocolDevice) RemoveAuthorization(params map[string]interface{})
 	return nil
 }
 
+// OTA 模拟固件更新，依次上报进度
+func (sd *DemoProtocolDevice) OTA(firmwareURL string) (chan sdk.OTAResp, error) {
+	ch := make(chan sdk.OTAResp, 2)
+	ch <- sdk.OTAResp{Step: sdk.OTAProgress(50)}
+	ch <- sdk.OTAResp{Step: sdk.OTAProgress(100)}
+	close(ch)
+	return ch, nil
+}
+
 func (sd *DemoProtocolDevice) Close() error {
 	return nil
 }
@@ -2,208 +2,127 @@ package pkg
 
 import (
 	"context"
-	"encoding/json"
 	"testing"
 
-	"github.com/sirupsen/logrus"
-	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
-
-	"github.com/zhiting-tech/smartassistant/pkg/logger"
-	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
 	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
-	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/sdktesting"
 	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
 )
 
-const (
-	// iid = "0x0000000017995bc5" // yeelight color
-	// iid = "84f7033a1b71"
-	iid = "84f703a5ead5" // 后面开关
-)
-
-func TestDemo(t *testing.T) {
+const iid = "demo"
 
-	d := NewDemo()
-	def := definer.NewThingModelDefiner("123", nil, nil)
-	d.Define(def)
-
-	tm := def.ThingModel()
-	data, err := json.Marshal(tm)
-	logger.Println(string(data), err)
+func newHarness(t *testing.T) (*sdktesting.Harness, *DemoProtocolDevice) {
+	d := NewDemo(iid)
+	h := sdktesting.New(t, func(ctx context.Context, devices chan<- sdk.Device) {
+		devices <- d
+	})
+	return h, d
 }
 
-func TestGetDevices(t *testing.T) {
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
+func connect(t *testing.T, h *sdktesting.Harness) thingmodel.ThingModel {
+	tm, err := h.Connect(iid, nil)
 	if err != nil {
-		logrus.Panic(err)
+		t.Fatalf("connect err: %s", err)
 	}
+	return tm
+}
 
-	cli := proto.NewPluginClient(conn)
+func TestHandshake(t *testing.T) {
+	h, _ := newHarness(t)
 
-	resp, err := cli.Discover(context.Background(), &emptypb.Empty{})
+	resp, err := h.Handshake()
 	if err != nil {
-		logrus.Panic(err)
+		t.Fatalf("handshake err: %s", err)
+	}
+	if resp.ProtocolVersion != sdk.ProtocolVersion || len(resp.Capabilities) == 0 {
+		t.Fatalf("unexpected handshake response: %v", resp)
 	}
-	for {
-		d, err := resp.Recv()
-		if err != nil {
-			logrus.Panic(err.Error())
-		}
-		m, _ := json.Marshal(d)
-		logrus.Println(string(m))
+	if _, err = h.Handshake(1); err == nil {
+		t.Error("handshake with unsupported protocol version should fail")
 	}
 }
 
-func TestConnectDevices(t *testing.T) {
+func TestDiscover(t *testing.T) {
+	h, _ := newHarness(t)
 
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
+	devices, err := h.Discover()
 	if err != nil {
-		logrus.Panic(err)
+		t.Fatalf("discover err: %s", err)
 	}
-
-	cli := proto.NewPluginClient(conn)
-
-	iids := []string{"84f7033bb9d9", "84f7033aa275", "84f703a5ead5", "7cdfa1a44b15", "84f7033a1b71"}
-
-	for _, i := range iids {
-		_, err = ConnectDevice(cli, i)
+	if len(devices) != 1 || devices[0].Iid != iid || devices[0].Manufacturer != "demo" {
+		t.Fatalf("unexpected devices: %v", devices)
 	}
-
 }
-func ConnectDevice(cli proto.PluginClient, iid string) (tm thingmodel.ThingModel, err error) {
-
-	resp, err := cli.Connect(context.Background(), &proto.AuthReq{Iid: iid})
-	if err != nil {
-		logrus.Panic(err)
-	}
-
-	if err != nil {
-		logrus.Panic(err)
-	}
-	logrus.Println(resp)
-
-	err = json.Unmarshal(resp.Instances, &tm.Instances)
-	return
 
-}
 func TestConnect(t *testing.T) {
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
-	if err != nil {
-		logrus.Panic(err)
-	}
+	h, _ := newHarness(t)
+	tm := connect(t, h)
 
-	cli := proto.NewPluginClient(conn)
-	tm, err := ConnectDevice(cli, iid)
-	if err != nil {
-		logrus.Panic(err)
+	sdktesting.AssertService(t, tm, iid, thingmodel.GatewayService)
+	sdktesting.AssertAttribute(t, tm, iid, thingmodel.OnOff, "on")
+	sdktesting.AssertService(t, tm, "id222", thingmodel.LightBulbService)
+	sdktesting.AssertAttribute(t, tm, "id222", thingmodel.Brightness, 55)
+	if !tm.OTASupport {
+		t.Error("demo should support OTA")
 	}
-
-	logrus.Printf("%+v", tm)
-	// data, _ := tm.MarshalJSON()
-	// logrus.Println(string(data))
-
 }
 
 func TestGetInstances(t *testing.T) {
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
-	if err != nil {
-		logrus.Panic(err)
-	}
-
-	cli := proto.NewPluginClient(conn)
+	h, _ := newHarness(t)
+	connect(t, h)
 
-	req := proto.GetInstancesReq{Iid: iid}
-	resp, err := cli.GetInstances(context.Background(), &req)
+	tm, err := h.GetInstances(iid)
 	if err != nil {
-		logrus.Panic(err)
+		t.Fatalf("get instances err: %s", err)
 	}
-	logrus.Println(resp)
-
-	var tm thingmodel.ThingModel
-
-	json.Unmarshal(resp.Instances, &tm.Instances)
-
-	logrus.Printf("%+v", tm)
-	// data, _ := tm.MarshalJSON()
-	// logrus.Println(string(data))
-
+	if len(tm.Instances) != 3 {
+		t.Fatalf("want 3 instances, got %d", len(tm.Instances))
+	}
+	sdktesting.AssertService(t, tm, "id333", thingmodel.SwitchService)
 }
 
 func TestSetAttributes(t *testing.T) {
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
-	if err != nil {
-		logrus.Panic(err)
-	}
-
-	cli := proto.NewPluginClient(conn)
-
-	// setReq := server.SetRequest{Attributes: []server.SetAttribute{{
-	//	IID: "0x0000000017995bc5",
-	//	AID: 2,
-	//	Val: 1,
-	// }}}
-	setReq := sdk.SetRequest{Attributes: []sdk.SetAttribute{{
-		IID: iid,
-		AID: 5,
-		Val: "off",
-	}}}
-	data, _ := json.Marshal(setReq)
-	req := proto.SetAttributesReq{Data: data}
-	resp, err := cli.SetAttributes(context.Background(), &req)
-	if err != nil {
-		logrus.Panic(err)
-	}
-	logrus.Println(resp)
-
-}
+	h, _ := newHarness(t)
+	tm := connect(t, h)
 
-func TestStateChange(t *testing.T) {
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
-	if err != nil {
-		logrus.Panic(err)
+	attr, ok := sdktesting.FindAttribute(tm, "id222", thingmodel.Brightness)
+	if !ok {
+		t.Fatal("brightness not found")
+	}
+	if err := h.SetAttributes(sdk.SetAttribute{IID: "id222", AID: attr.AID, Val: 80}); err != nil {
+		t.Fatalf("set attributes err: %s", err)
+	}
+	if err := h.SetAttributes(sdk.SetAttribute{IID: "id222", AID: 999, Val: 80}); err == nil {
+		t.Error("set unknown attribute should fail")
 	}
+}
 
-	cli := proto.NewPluginClient(conn)
+func TestSubscribe(t *testing.T) {
+	h, d := newHarness(t)
+	tm := connect(t, h)
 
-	empty := emptypb.Empty{}
-	resp, err := cli.Subscribe(context.Background(), &empty)
-	if err != nil {
-		logrus.Panic(err)
+	attr, _ := sdktesting.FindAttribute(tm, "id222", thingmodel.OnOff)
+	if err := d.light.Notify(thingmodel.OnOff, "off"); err != nil {
+		t.Fatalf("notify err: %s", err)
 	}
-
-	for {
-		attrs, err := resp.Recv()
-		if err != nil {
-			logrus.Panic(err.Error())
-		}
-		logrus.Println(attrs.String())
+	ae, ok := h.WaitAttrChange("id222", attr.AID)
+	if !ok {
+		t.Fatal("attribute change event not received")
+	}
+	if !sdktesting.EqualVal(ae.Val, "off") {
+		t.Errorf("want off, got %v", ae.Val)
 	}
 }
 
 func TestOTA(t *testing.T) {
-	conn, err := grpc.Dial("0.0.0.0:5555", grpc.WithInsecure())
-	if err != nil {
-		logrus.Panic(err)
-	}
+	h, _ := newHarness(t)
+	connect(t, h)
 
-	cli := proto.NewPluginClient(conn)
-
-	// switch 1.0.19
-	// url := "https://zt-editor.oss-cn-guangzhou.aliyuncs.com/a70bdb48d89222c8bab1dbc1b6bdcdb74ad273fc2848a8ec3235c306249551f3.bin"
-
-	// switch 1.0.20
-	url := "https://zt-editor.oss-cn-guangzhou.aliyuncs.com/d346a708a119c24e0fe56433201c4e6f45c33ab625f2d936ef4288cae65dddbe.bin"
-	resp, err := cli.OTA(context.Background(), &proto.OTAReq{Iid: iid, FirmwareUrl: url})
+	steps, err := h.OTA(iid, "http://example.com/firmware.bin")
 	if err != nil {
-		logrus.Panic(err)
+		t.Fatalf("ota err: %s", err)
 	}
-
-	for {
-		attrs, err := resp.Recv()
-		if err != nil {
-			logrus.Panic(err.Error())
-		}
-		logrus.Println(attrs.String())
+	if len(steps) != 2 || steps[1] != 100 {
+		t.Errorf("unexpected ota steps: %v", steps)
 	}
 }
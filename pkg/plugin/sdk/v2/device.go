@@ -48,6 +48,13 @@ type AuthDevice interface {
 	RemoveAuthorization(params map[string]interface{}) error
 }
 
+// Refresher 支持主动读取属性的设备，适用于很少主动上报状态的设备
+type Refresher interface {
+	Device
+	// Refresh 主动从设备读取实例的属性，返回 aid 对应的最新值，aids为空时读取所有属性
+	Refresh(iid string, aids []int) (map[int]interface{}, error)
+}
+
 type OTAProgressState int // OTA进度
 
 const (
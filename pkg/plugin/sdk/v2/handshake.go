@@ -0,0 +1,70 @@
+package sdk
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+)
+
+// ProtocolVersion SDK当前使用的协议版本，协议不兼容时增加
+const ProtocolVersion int32 = 2
+
+// SupportedProtocolVersions SDK支持的协议版本
+var SupportedProtocolVersions = []int32{ProtocolVersion}
+
+// 插件可选的功能，SA根据协商结果决定是否调用对应的接口
+const (
+	CapabilityOTA      = "ota"      // 设备固件更新
+	CapabilityAuth     = "auth"     // 连接设备时认证
+	CapabilityRefresh  = "refresh"  // 主动读取设备属性
+	CapabilityActions  = "actions"  // 设备动作
+	CapabilitySettings = "settings" // 插件设置
+	CapabilityEvents   = "events"   // 设备无状态事件
+)
+
+// Capabilities 当前SDK支持的功能
+var Capabilities = []string{
+	CapabilityOTA,
+	CapabilityAuth,
+	CapabilityRefresh,
+	CapabilityActions,
+	CapabilitySettings,
+	CapabilityEvents,
+}
+
+// LegacyCapabilities 未实现握手的旧版本SDK支持的功能
+var LegacyCapabilities = []string{
+	CapabilityOTA,
+	CapabilityAuth,
+}
+
+// NegotiateProtocol 返回双方都支持的最高协议版本，没有则返回false
+func NegotiateProtocol(local, remote []int32) (version int32, ok bool) {
+	for _, l := range local {
+		for _, r := range remote {
+			if l == r && l > version {
+				version = l
+				ok = true
+			}
+		}
+	}
+	return
+}
+
+// Handshake 与SA协商协议版本，并返回插件支持的功能
+func (p Server) Handshake(ctx context.Context, req *proto.HandshakeReq) (*proto.HandshakeResp, error) {
+	version, ok := NegotiateProtocol(SupportedProtocolVersions, req.ProtocolVersions)
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"no compatible protocol version, sdk supports %v, sa supports %v",
+			SupportedProtocolVersions, req.ProtocolVersions)
+	}
+	return &proto.HandshakeResp{
+		SdkVersion:      Version,
+		ProtocolVersion: version,
+		Capabilities:    Capabilities,
+	}, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -19,6 +20,7 @@ var (
 const (
 	ThingModelChangeEvent = "thing_model_change"
 	AttrChangeEvent       = "attr_change"
+	DeviceEvent           = "device_event" // 设备的无状态事件
 )
 
 func newDevice(d Device) *device {
@@ -159,6 +161,7 @@ func (m *Manager) Connect(d *device, params map[string]interface{}) (err error)
 
 	logrus.Debugf("device %s connected, define device's thing model", d.Info().IID)
 	d.df = definer.NewThingModelDefiner(d.Info().IID, m.notifyAttr, m.notifyThingModelChange)
+	d.df.SetEventNotifyFunc(m.notifyDeviceEvent)
 	d.Define(d.df)
 	if d.df != nil {
 		d.df.SetNotifyFunc()
@@ -253,6 +256,16 @@ func (m *Manager) notifyAttr(attrEvent definer.AttributeEvent) (err error) {
 	return m.notifyEvent(ev)
 }
 
+func (m *Manager) notifyDeviceEvent(deviceEvent definer.DeviceEvent) (err error) {
+	data, _ := json.Marshal(deviceEvent)
+	ev := Event{
+		Type: DeviceEvent,
+		Data: data,
+	}
+
+	return m.notifyEvent(ev)
+}
+
 // notifyThingModelChange iid是桥接设备iid，tme.IID是实际更新的设备iid
 func (m *Manager) notifyThingModelChange(iid string, tme definer.ThingModelEvent) (err error) {
 
@@ -300,6 +313,62 @@ func (m *Manager) SetAttributes(as []SetAttribute) error {
 	return nil
 }
 
+// GetAttributes 读取属性，设备实现了 Refresher 时先从设备读取最新值，值有变化时通知SA
+func (m *Manager) GetAttributes(as []GetAttribute) (result []SetAttribute, err error) {
+
+	// 按实例分组，每个实例只读取一次设备
+	aids := make(map[string][]int)
+	var iids []string
+	for _, a := range as {
+		if _, ok := aids[a.IID]; !ok {
+			iids = append(iids, a.IID)
+		}
+		aids[a.IID] = append(aids[a.IID], a.AID)
+	}
+
+	for _, iid := range iids {
+		var d *device
+		if d, err = m.GetDevice(iid); err != nil {
+			return
+		}
+		if d.df == nil {
+			err = fmt.Errorf("%s definer is nil", iid)
+			return
+		}
+		if r, ok := d.Device.(Refresher); ok {
+			var vals map[int]interface{}
+			if vals, err = r.Refresh(iid, aids[iid]); err != nil {
+				return
+			}
+			for aid, val := range vals {
+				attr := d.df.GetAttribute(iid, aid)
+				if attr == nil || reflect.DeepEqual(attr.GetVal(), val) {
+					continue
+				}
+				attr.SetVal(val)
+				_ = m.notifyAttr(definer.AttributeEvent{IID: iid, AID: aid, Val: val})
+			}
+		}
+		for _, aid := range aids[iid] {
+			attr := d.df.GetAttribute(iid, aid)
+			if attr == nil {
+				err = definer.NotFoundErr
+				return
+			}
+			result = append(result, SetAttribute{IID: iid, AID: aid, Val: attr.GetVal()})
+		}
+	}
+	return
+}
+
+func (m *Manager) InvokeAction(iid, action string, inputs map[string]interface{}) (map[string]interface{}, error) {
+	df, err := m.getDefiner(iid)
+	if err != nil {
+		return nil, err
+	}
+	return df.InvokeAction(iid, action, inputs)
+}
+
 func (m *Manager) GetThingModel(iid string) (tm thingmodel.ThingModel, err error) {
 	df, err := m.getDefiner(iid)
 	if err != nil {
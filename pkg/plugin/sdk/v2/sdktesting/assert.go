@@ -0,0 +1,60 @@
+package sdktesting
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// FindAttribute 根据类型查找实例中的属性，多个同类型属性时返回第一个
+func FindAttribute(tm thingmodel.ThingModel, iid string, attrType thingmodel.Attribute) (attr thingmodel.Attribute, ok bool) {
+	ins, err := tm.GetInstance(iid)
+	if err != nil {
+		return
+	}
+	for _, srv := range ins.Services {
+		for _, a := range srv.Attributes {
+			if a.Type == attrType.Type {
+				return a, true
+			}
+		}
+	}
+	return
+}
+
+// AssertAttribute 断言实例中属性的值，值按json格式比较
+func AssertAttribute(t testing.TB, tm thingmodel.ThingModel, iid string, attrType thingmodel.Attribute, val interface{}) {
+	t.Helper()
+	attr, ok := FindAttribute(tm, iid, attrType)
+	if !ok {
+		t.Errorf("attribute %s of %s not found", attrType.Type, iid)
+		return
+	}
+	if !EqualVal(attr.Val, val) {
+		t.Errorf("attribute %s of %s: got %v, want %v", attrType.Type, iid, attr.Val, val)
+	}
+}
+
+// AssertService 断言实例中存在该类型的服务
+func AssertService(t testing.TB, tm thingmodel.ThingModel, iid string, serviceType thingmodel.ServiceType) {
+	t.Helper()
+	ins, err := tm.GetInstance(iid)
+	if err != nil {
+		t.Errorf("instance %s not found", iid)
+		return
+	}
+	for _, srv := range ins.Services {
+		if srv.Type == serviceType {
+			return
+		}
+	}
+	t.Errorf("service %s of %s not found", serviceType, iid)
+}
+
+// EqualVal 按json格式比较两个值，避免数字类型不一致
+func EqualVal(a, b interface{}) bool {
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(da) == string(db)
+}
@@ -0,0 +1,272 @@
+// Package sdktesting 插件测试工具，在内存中运行插件服务并模拟SA调用，无需SA、etcd及docker
+package sdktesting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/v2/definer"
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+const (
+	bufSize = 1 << 20
+
+	// DefaultTimeout 调用插件及等待事件的默认超时时间
+	DefaultTimeout = time.Second * 10
+)
+
+// Harness 通过内存中的grpc连接调用插件服务，相当于一个假的SA
+type Harness struct {
+	t       testing.TB
+	Server  *sdk.Server
+	Client  proto.PluginClient
+	Timeout time.Duration
+
+	events chan sdk.Event
+}
+
+// New 使用设备发现函数创建插件服务并启动，测试结束时自动关闭
+func New(t testing.TB, discoverFunc sdk.DiscoverFunc, opts ...sdk.OptionFunc) *Harness {
+	t.Helper()
+	return NewWithServer(t, sdk.NewPluginServer(discoverFunc, opts...))
+}
+
+// NewWithServer 启动已创建的插件服务，测试结束时自动关闭
+func NewWithServer(t testing.TB, s *sdk.Server) *Harness {
+	t.Helper()
+
+	lis := bufconn.Listen(bufSize)
+	grpcServer := grpc.NewServer()
+	proto.RegisterPluginServer(grpcServer, s)
+	go grpcServer.Serve(lis)
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("dial plugin server err: %s", err)
+	}
+
+	h := &Harness{
+		t:       t,
+		Server:  s,
+		Client:  proto.NewPluginClient(conn),
+		Timeout: DefaultTimeout,
+		events:  make(chan sdk.Event, 100),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		conn.Close()
+		grpcServer.Stop()
+	})
+	h.subscribe(ctx)
+	return h
+}
+
+// subscribe 订阅插件的事件，订阅成功后才返回，保证不丢失之后的事件
+func (h *Harness) subscribe(ctx context.Context) {
+	h.t.Helper()
+	stream, err := h.Client.Subscribe(ctx, &emptypb.Empty{})
+	if err != nil {
+		h.t.Fatalf("subscribe err: %s", err)
+	}
+	if _, err = stream.Header(); err != nil {
+		h.t.Fatalf("subscribe err: %s", err)
+	}
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			var ev sdk.Event
+			if err = json.Unmarshal(resp.Data, &ev); err != nil {
+				continue
+			}
+			select {
+			case h.events <- ev:
+			default:
+			}
+		}
+	}()
+}
+
+func (h *Harness) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), h.Timeout)
+}
+
+// Handshake 模拟SA与插件握手，不指定协议版本时使用SDK支持的版本
+func (h *Harness) Handshake(protocolVersions ...int32) (*proto.HandshakeResp, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+	if len(protocolVersions) == 0 {
+		protocolVersions = sdk.SupportedProtocolVersions
+	}
+	return h.Client.Handshake(ctx, &proto.HandshakeReq{ProtocolVersions: protocolVersions})
+}
+
+// Discover 执行一次设备发现，返回插件发现的设备
+func (h *Harness) Discover() ([]*proto.Device, error) {
+	ctx, cancel := h.context()
+	defer cancel()
+	h.Server.DiscoverOnce(ctx)
+
+	stream, err := h.Client.Discover(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	var devices []*proto.Device
+	for {
+		d, err := stream.Recv()
+		if err == io.EOF {
+			return devices, nil
+		}
+		if err != nil {
+			return devices, err
+		}
+		devices = append(devices, d)
+	}
+}
+
+// Connect 连接设备，返回设备的物模型
+func (h *Harness) Connect(iid string, authParams map[string]interface{}) (tm thingmodel.ThingModel, err error) {
+	ctx, cancel := h.context()
+	defer cancel()
+	params, _ := json.Marshal(authParams)
+	resp, err := h.Client.Connect(ctx, &proto.AuthReq{Iid: iid, Params: params})
+	if err != nil {
+		return
+	}
+	return parseInstancesResp(resp)
+}
+
+// Disconnect 断开与设备的连接
+func (h *Harness) Disconnect(iid string, authParams map[string]interface{}) error {
+	ctx, cancel := h.context()
+	defer cancel()
+	params, _ := json.Marshal(authParams)
+	_, err := h.Client.Disconnect(ctx, &proto.AuthReq{Iid: iid, Params: params})
+	return err
+}
+
+// GetInstances 获取设备的物模型
+func (h *Harness) GetInstances(iid string) (tm thingmodel.ThingModel, err error) {
+	ctx, cancel := h.context()
+	defer cancel()
+	resp, err := h.Client.GetInstances(ctx, &proto.GetInstancesReq{Iid: iid})
+	if err != nil {
+		return
+	}
+	return parseInstancesResp(resp)
+}
+
+// SetAttributes 设置设备属性
+func (h *Harness) SetAttributes(attrs ...sdk.SetAttribute) error {
+	ctx, cancel := h.context()
+	defer cancel()
+	data, _ := json.Marshal(sdk.SetRequest{Attributes: attrs})
+	_, err := h.Client.SetAttributes(ctx, &proto.SetAttributesReq{Data: data})
+	return err
+}
+
+// OTA 更新设备固件，返回插件上报的所有进度
+func (h *Harness) OTA(iid, firmwareURL string) (steps []sdk.OTAProgressState, err error) {
+	ctx, cancel := h.context()
+	defer cancel()
+	stream, err := h.Client.OTA(ctx, &proto.OTAReq{Iid: iid, FirmwareUrl: firmwareURL})
+	if err != nil {
+		return
+	}
+	for {
+		var resp *proto.OTAResp
+		resp, err = stream.Recv()
+		if err == io.EOF {
+			return steps, nil
+		}
+		if err != nil {
+			return
+		}
+		steps = append(steps, sdk.OTAProgress(int(resp.Step)))
+	}
+}
+
+// Events 插件上报的所有事件
+func (h *Harness) Events() <-chan sdk.Event {
+	return h.events
+}
+
+// WaitEvent 等待满足条件的事件，超时返回false，不满足条件的事件会被丢弃
+func (h *Harness) WaitEvent(match func(ev sdk.Event) bool) (sdk.Event, bool) {
+	timeout := time.NewTimer(h.Timeout)
+	defer timeout.Stop()
+	for {
+		select {
+		case ev := <-h.events:
+			if match(ev) {
+				return ev, true
+			}
+		case <-timeout.C:
+			return sdk.Event{}, false
+		}
+	}
+}
+
+// WaitAttrChange 等待属性变化事件
+func (h *Harness) WaitAttrChange(iid string, aid int) (ae definer.AttributeEvent, ok bool) {
+	_, ok = h.WaitEvent(func(ev sdk.Event) bool {
+		if ev.Type != sdk.AttrChangeEvent {
+			return false
+		}
+		var e definer.AttributeEvent
+		_ = json.Unmarshal(ev.Data, &e)
+		if e.IID != iid || e.AID != aid {
+			return false
+		}
+		ae = e
+		return true
+	})
+	return
+}
+
+// WaitDeviceEvent 等待设备的无状态事件
+func (h *Harness) WaitDeviceEvent(iid, eventType string) (de definer.DeviceEvent, ok bool) {
+	_, ok = h.WaitEvent(func(ev sdk.Event) bool {
+		if ev.Type != sdk.DeviceEvent {
+			return false
+		}
+		var e definer.DeviceEvent
+		_ = json.Unmarshal(ev.Data, &e)
+		if e.IID != iid || e.Type != eventType {
+			return false
+		}
+		de = e
+		return true
+	})
+	return
+}
+
+func parseInstancesResp(resp *proto.GetInstancesResp) (tm thingmodel.ThingModel, err error) {
+	if err = json.Unmarshal(resp.Instances, &tm.Instances); err != nil {
+		return
+	}
+	tm.OTASupport = resp.OtaSupport
+	tm.AuthRequired = resp.AuthRequired
+	tm.IsAuth = resp.IsAuth
+	if resp.AuthRequired && len(resp.AuthParams) != 0 {
+		err = json.Unmarshal(resp.AuthParams, &tm.AuthParams)
+	}
+	return
+}
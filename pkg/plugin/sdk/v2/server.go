@@ -29,6 +29,7 @@ type Server struct {
 	configFile   string
 	staticDir    string
 	discoverFunc DiscoverFunc
+	settings     *pluginSettings
 }
 
 func (p Server) OTA(req *proto.OTAReq, server proto.Plugin_OTAServer) error {
@@ -69,6 +70,11 @@ func (p Server) HealthCheck(context context.Context, req *proto.HealthCheckReq)
 	return
 }
 
+// Ping 插件服务存活检查，能响应说明插件服务未卡死
+func (p Server) Ping(context context.Context, request *emptypb.Empty) (*emptypb.Empty, error) {
+	return new(emptypb.Empty), nil
+}
+
 func (p Server) Discover(request *emptypb.Empty, server proto.Plugin_DiscoverServer) error {
 
 	devices, err := p.Manager.Devices()
@@ -194,6 +200,69 @@ func (p Server) SetAttributes(context context.Context, request *proto.SetAttribu
 	return
 }
 
+type GetAttribute struct {
+	IID string `json:"iid"`
+	AID int    `json:"aid"`
+}
+
+type GetRequest struct {
+	Attributes []GetAttribute `json:"attributes"`
+}
+
+type GetResponse struct {
+	Attributes []SetAttribute `json:"attributes"`
+}
+
+// GetAttributes 主动从设备读取属性的最新值
+func (p Server) GetAttributes(context context.Context, request *proto.GetAttributesReq) (resp *proto.GetAttributesResp, err error) {
+	logrus.Debugf("%v GetAttributes", request)
+
+	var req GetRequest
+	err = json.Unmarshal(request.Data, &req)
+	if err != nil {
+		return
+	}
+	var getResp GetResponse
+	getResp.Attributes, err = p.Manager.GetAttributes(req.Attributes)
+	if err != nil {
+		return
+	}
+	resp = new(proto.GetAttributesResp)
+	resp.Data, _ = json.Marshal(getResp)
+	resp.Success = true
+	return
+}
+
+type ActionRequest struct {
+	IID    string                 `json:"iid"`
+	Action string                 `json:"action"`
+	Inputs map[string]interface{} `json:"inputs"`
+}
+
+type ActionResponse struct {
+	Outputs map[string]interface{} `json:"outputs"`
+}
+
+// InvokeAction 执行设备的动作
+func (p Server) InvokeAction(context context.Context, request *proto.InvokeActionReq) (resp *proto.InvokeActionResp, err error) {
+	logrus.Debugf("%v InvokeAction", request)
+
+	var req ActionRequest
+	err = json.Unmarshal(request.Data, &req)
+	if err != nil {
+		return
+	}
+	var actionResp ActionResponse
+	actionResp.Outputs, err = p.Manager.InvokeAction(req.IID, req.Action, req.Inputs)
+	if err != nil {
+		return
+	}
+	resp = new(proto.InvokeActionResp)
+	resp.Data, _ = json.Marshal(actionResp)
+	resp.Success = true
+	return
+}
+
 type Event struct {
 	Type string          `json:"type"`
 	Data json.RawMessage `json:"data"`
@@ -208,6 +277,10 @@ func (p Server) Subscribe(request *emptypb.Empty, server proto.Plugin_SubscribeS
 
 	p.Manager.Subscribe(nc)
 	defer p.Manager.Unsubscribe(nc)
+	// 订阅完成后发送header，客户端可据此确认不会丢失之后的事件
+	if err := server.SendHeader(nil); err != nil {
+		return err
+	}
 	for {
 		select {
 		case <-server.Context().Done():
@@ -240,6 +313,10 @@ func (p *Server) Init() {
 	p.pluginRouter.Group("html").Static("", p.staticDir)
 	p.pluginRouter.StaticFile("config.json", p.configFile)
 
+	if !Exist(p.staticDir) {
+		logrus.Warnf("static dir %s not exist", p.staticDir)
+		return
+	}
 	// 压缩静态文件，返回压缩包
 	fileName := fmt.Sprintf("%s.zip", p.Domain)
 
@@ -311,6 +388,7 @@ func NewPluginServer(discoverFunc DiscoverFunc, opts ...OptionFunc) *Server {
 		ApiRouter:    apiGroup,
 		staticDir:    "./html",
 		configFile:   "./config.json",
+		settings:     new(pluginSettings),
 	}
 	for _, opt := range opts {
 		opt(&s)
@@ -319,6 +397,11 @@ func NewPluginServer(discoverFunc DiscoverFunc, opts ...OptionFunc) *Server {
 	return &s
 }
 
+// DiscoverOnce 执行一次设备发现并刷新发现设备列表，Run 会定时执行，通常只在测试中直接调用
+func (p *Server) DiscoverOnce(ctx context.Context) {
+	p.discover(ctx)
+}
+
 // discover 发现设备并刷新发现设备列表
 func (p *Server) discover(ctx context.Context) {
 	defer func() {
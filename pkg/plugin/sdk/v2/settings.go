@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+)
+
+// SettingsHandler 处理SA下发的插件设置，格式由config.json中的settings定义
+type SettingsHandler func(settings json.RawMessage) error
+
+type pluginSettings struct {
+	mu       sync.Mutex
+	handler  SettingsHandler
+	settings json.RawMessage
+}
+
+// WithSettingsHandler 设置插件设置的处理函数，插件启动连接SA时及用户修改设置时调用
+func (p *Server) WithSettingsHandler(handler SettingsHandler) *Server {
+	p.settings.mu.Lock()
+	defer p.settings.mu.Unlock()
+	p.settings.handler = handler
+	// 已经收到过设置则立即处理
+	if p.settings.settings != nil && handler != nil {
+		if err := handler(p.settings.settings); err != nil {
+			logrus.Errorf("handle settings err: %s", err)
+		}
+	}
+	return p
+}
+
+// Settings 获取最近一次收到的插件设置
+func (p Server) Settings() json.RawMessage {
+	p.settings.mu.Lock()
+	defer p.settings.mu.Unlock()
+	return p.settings.settings
+}
+
+// UpdateSettings 接收SA下发的插件设置
+func (p Server) UpdateSettings(ctx context.Context, req *proto.UpdateSettingsReq) (resp *emptypb.Empty, err error) {
+	logrus.Debugf("update settings: %s", req.Data)
+
+	p.settings.mu.Lock()
+	defer p.settings.mu.Unlock()
+	if p.settings.handler != nil {
+		if err = p.settings.handler(req.Data); err != nil {
+			return
+		}
+	}
+	p.settings.settings = req.Data
+	resp = new(emptypb.Empty)
+	return
+}
@@ -0,0 +1,143 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/proto/v2"
+	"github.com/zhiting-tech/smartassistant/pkg/plugin/sdk/utils/registry"
+)
+
+// 请求存储服务时携带的插件身份信息
+const (
+	StorageMetaPluginID = registry.MetaPluginID
+	StorageMetaAreaID   = registry.MetaAreaID
+	StorageMetaToken    = registry.MetaToken
+)
+
+var (
+	StorageKeyNotExist = errors.New("storage key not exist")
+	StorageUnavailable = errors.New("storage unavailable")
+)
+
+// StorageEvent 存储的键值变化
+type StorageEvent struct {
+	Key     string
+	Value   []byte
+	Deleted bool
+}
+
+// Storage SA提供的键值存储，数据按插件及家庭隔离，随SA备份与还原，重新安装插件后仍可使用
+type Storage struct {
+	conn   *grpc.ClientConn
+	client proto.StorageClient
+	md     metadata.MD
+}
+
+// NewStorage 根据SA启动插件时设置的环境变量连接存储服务
+func NewStorage() (*Storage, error) {
+	addr := os.Getenv("SA_GRPC_ADDR")
+	token := os.Getenv("SA_STORAGE_TOKEN")
+	if addr == "" || token == "" {
+		return nil, StorageUnavailable
+	}
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return &Storage{
+		conn:   conn,
+		client: proto.NewStorageClient(conn),
+		md: metadata.Pairs(
+			StorageMetaPluginID, os.Getenv("PLUGIN_DOMAIN"),
+			StorageMetaAreaID, os.Getenv("AREA_ID"),
+			StorageMetaToken, token,
+		),
+	}, nil
+}
+
+func (s *Storage) ctx(ctx context.Context) context.Context {
+	return metadata.NewOutgoingContext(ctx, s.md)
+}
+
+// Get 获取值，不存在时返回 StorageKeyNotExist
+func (s *Storage) Get(ctx context.Context, key string) ([]byte, error) {
+	item, err := s.client.Get(s.ctx(ctx), &proto.StorageKey{Key: key})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, StorageKeyNotExist
+		}
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+// Put 保存值，已存在则覆盖
+func (s *Storage) Put(ctx context.Context, key string, value []byte) error {
+	_, err := s.client.Put(s.ctx(ctx), &proto.StorageItem{Key: key, Value: value})
+	return err
+}
+
+// Delete 删除值
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Delete(s.ctx(ctx), &proto.StorageKey{Key: key})
+	return err
+}
+
+// List 获取指定前缀的所有键值
+func (s *Storage) List(ctx context.Context, prefix string) (map[string][]byte, error) {
+	resp, err := s.client.List(s.ctx(ctx), &proto.StoragePrefix{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	items := make(map[string][]byte, len(resp.Items))
+	for _, item := range resp.Items {
+		items[item.Key] = item.Value
+	}
+	return items, nil
+}
+
+// Watch 监听指定前缀的键值变化，ctx 结束或连接断开时关闭 channel
+func (s *Storage) Watch(ctx context.Context, prefix string) (<-chan StorageEvent, error) {
+	stream, err := s.client.Watch(s.ctx(ctx), &proto.StoragePrefix{Prefix: prefix})
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan StorageEvent, 10)
+	go func() {
+		defer close(ch)
+		for {
+			ev, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF && status.Code(err) != codes.Canceled {
+					logrus.Errorf("watch storage err: %s", err)
+				}
+				return
+			}
+			se := StorageEvent{Deleted: ev.Type == proto.StorageEvent_DELETE}
+			if ev.Item != nil {
+				se.Key = ev.Item.Key
+				se.Value = ev.Item.Value
+			}
+			select {
+			case ch <- se:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Close 关闭与存储服务的连接
+func (s *Storage) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,227 @@
+// Package signature 插件包签名及校验
+//
+// 插件包目录（config.json所在目录）中包含 MANIFEST.json 及 MANIFEST.sig 两个文件：
+// MANIFEST.json 记录发布者及插件包中每个文件的sha256，MANIFEST.sig 为发布者私钥对
+// MANIFEST.json 内容的ed25519签名（base64编码）。
+package signature
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ManifestFile  = "MANIFEST.json"
+	SignatureFile = "MANIFEST.sig"
+
+	publicKeyExt = ".pub"
+)
+
+var (
+	// ErrUnsigned 插件包未签名
+	ErrUnsigned = errors.New("plugin package is unsigned")
+	// ErrUntrustedPublisher 发布者不在信任列表中
+	ErrUntrustedPublisher = errors.New("untrusted publisher")
+)
+
+// Manifest 插件包清单
+type Manifest struct {
+	Publisher string            `json:"publisher"`
+	Files     map[string]string `json:"files"` // 相对路径（使用/分隔） -> sha256
+}
+
+// GenerateManifest 计算目录下所有文件的hash生成清单
+func GenerateManifest(dir, publisher string) (m Manifest, err error) {
+	m = Manifest{
+		Publisher: publisher,
+		Files:     make(map[string]string),
+	}
+	err = walkFiles(dir, func(name, path string) error {
+		sum, err := fileHash(path)
+		if err != nil {
+			return err
+		}
+		m.Files[name] = sum
+		return nil
+	})
+	return
+}
+
+// Sign 使用发布者私钥签名插件目录，生成 MANIFEST.json 及 MANIFEST.sig
+func Sign(dir, publisher string, key ed25519.PrivateKey) (err error) {
+	m, err := GenerateManifest(dir, publisher)
+	if err != nil {
+		return
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return
+	}
+	sig := ed25519.Sign(key, data)
+	if err = ioutil.WriteFile(filepath.Join(dir, ManifestFile), data, 0644); err != nil {
+		return
+	}
+	return ioutil.WriteFile(filepath.Join(dir, SignatureFile),
+		[]byte(base64.StdEncoding.EncodeToString(sig)), 0644)
+}
+
+// Verify 校验插件目录的签名及所有文件的hash，返回插件包清单
+func Verify(dir string, ts TrustStore) (m Manifest, err error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if os.IsNotExist(err) {
+		return m, ErrUnsigned
+	}
+	if err != nil {
+		return
+	}
+	sigData, err := ioutil.ReadFile(filepath.Join(dir, SignatureFile))
+	if os.IsNotExist(err) {
+		return m, ErrUnsigned
+	}
+	if err != nil {
+		return
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil {
+		return m, fmt.Errorf("invalid signature: %w", err)
+	}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("invalid manifest: %w", err)
+	}
+
+	key, ok := ts[m.Publisher]
+	if !ok {
+		return m, fmt.Errorf("%w: %s", ErrUntrustedPublisher, m.Publisher)
+	}
+	if !ed25519.Verify(key, data, sig) {
+		return m, errors.New("signature mismatch")
+	}
+
+	// 文件与清单必须完全一致，不允许增加、缺少或修改文件
+	files := make(map[string]struct{})
+	err = walkFiles(dir, func(name, path string) error {
+		expected, ok := m.Files[name]
+		if !ok {
+			return fmt.Errorf("file %s not in manifest", name)
+		}
+		sum, err := fileHash(path)
+		if err != nil {
+			return err
+		}
+		if sum != expected {
+			return fmt.Errorf("file %s hash mismatch", name)
+		}
+		files[name] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	for name := range m.Files {
+		if _, ok := files[name]; !ok {
+			return m, fmt.Errorf("file %s missing", name)
+		}
+	}
+	return
+}
+
+// TrustStore 受信任的发布者公钥
+type TrustStore map[string]ed25519.PublicKey
+
+// LoadTrustStore 从目录中加载发布者公钥，文件名为 <发布者>.pub，内容为base64编码的ed25519公钥
+func LoadTrustStore(dir string) (ts TrustStore, err error) {
+	ts = make(TrustStore)
+	entries, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return ts, nil
+	}
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != publicKeyExt {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		key, err := ParsePublicKey(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		ts[strings.TrimSuffix(e.Name(), publicKeyExt)] = key
+	}
+	return
+}
+
+// ParsePublicKey 解析base64编码的ed25519公钥
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key size")
+	}
+	return data, nil
+}
+
+// ParsePrivateKey 解析base64编码的ed25519私钥
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid private key size")
+	}
+	return data, nil
+}
+
+// walkFiles 遍历目录下除签名文件外的所有文件，name 为使用/分隔的相对路径
+func walkFiles(dir string, fn func(name, path string) error) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("symlink %s not allowed", path)
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if name == ManifestFile || name == SignatureFile {
+			return nil
+		}
+		return fn(name, path)
+	})
+}
+
+func fileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
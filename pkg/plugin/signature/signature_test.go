@@ -0,0 +1,100 @@
+package signature
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newPluginDir(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"name":"demo"}`), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "html"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "html", "index.html"), []byte("<html></html>"), 0644))
+	return dir
+}
+
+func TestSignAndVerify(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	ts := TrustStore{"zhiting": pub}
+
+	dir := newPluginDir(t)
+	assert.NoError(t, Sign(dir, "zhiting", priv))
+
+	m, err := Verify(dir, ts)
+	assert.NoError(t, err)
+	assert.Equal(t, "zhiting", m.Publisher)
+	assert.Len(t, m.Files, 2)
+	assert.Contains(t, m.Files, "html/index.html")
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	_, err := Verify(newPluginDir(t), TrustStore{})
+	assert.Equal(t, ErrUnsigned, err)
+}
+
+func TestVerifyUntrusted(t *testing.T) {
+	_, priv, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	dir := newPluginDir(t)
+	assert.NoError(t, Sign(dir, "someone", priv))
+
+	_, err := Verify(dir, TrustStore{"zhiting": otherPub})
+	assert.True(t, errors.Is(err, ErrUntrustedPublisher))
+
+	// 发布者名称相同但密钥不同
+	assert.NoError(t, Sign(dir, "zhiting", priv))
+	_, err = Verify(dir, TrustStore{"zhiting": otherPub})
+	assert.Error(t, err)
+}
+
+func TestVerifyTampered(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	ts := TrustStore{"zhiting": pub}
+
+	// 修改文件
+	dir := newPluginDir(t)
+	assert.NoError(t, Sign(dir, "zhiting", priv))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"name":"evil"}`), 0644))
+	_, err := Verify(dir, ts)
+	assert.Error(t, err)
+
+	// 增加文件
+	dir = newPluginDir(t)
+	assert.NoError(t, Sign(dir, "zhiting", priv))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM scratch"), 0644))
+	_, err = Verify(dir, ts)
+	assert.Error(t, err)
+
+	// 删除文件
+	dir = newPluginDir(t)
+	assert.NoError(t, Sign(dir, "zhiting", priv))
+	assert.NoError(t, os.Remove(filepath.Join(dir, "html", "index.html")))
+	_, err = Verify(dir, ts)
+	assert.Error(t, err)
+}
+
+func TestLoadTrustStore(t *testing.T) {
+	pub, _, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "zhiting.pub"),
+		[]byte(base64.StdEncoding.EncodeToString(pub)+"\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("ignored"), 0644))
+
+	ts, err := LoadTrustStore(dir)
+	assert.NoError(t, err)
+	assert.Len(t, ts, 1)
+	assert.Equal(t, pub, ts["zhiting"])
+
+	ts, err = LoadTrustStore(filepath.Join(dir, "not_exist"))
+	assert.NoError(t, err)
+	assert.Len(t, ts, 0)
+}
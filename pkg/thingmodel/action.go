@@ -0,0 +1,117 @@
+package thingmodel
+
+import (
+	"fmt"
+)
+
+// Param 动作的输入或输出参数
+type Param struct {
+	Name     string   `json:"name"`
+	ValType  ValType  `json:"val_type"`
+	Required bool     `json:"required,omitempty"`
+	Options  []Option `json:"options,omitempty"`
+
+	Default interface{} `json:"default,omitempty"`
+	Min     interface{} `json:"min,omitempty"`
+	Max     interface{} `json:"max,omitempty"`
+}
+
+// Action 设备的动作（方法），如带密码开锁、云台转到预置位等无法用属性表示的操作
+type Action struct {
+	Type    string  `json:"type"` // 同一实例中唯一
+	Inputs  []Param `json:"inputs,omitempty"`
+	Outputs []Param `json:"outputs,omitempty"`
+}
+
+func (a Action) String() string {
+	return a.Type
+}
+
+// Validate 校验参数值的类型、范围、可选项，返回按val_type转换后的值
+func (p Param) Validate(val interface{}) (interface{}, error) {
+	v, code, reason := checkVal(p.ValType, p.Min, p.Max, p.Options, val)
+	if code != "" {
+		return nil, fmt.Errorf("%s: %s", p.Name, reason)
+	}
+	return v, nil
+}
+
+// CheckInputs 校验输入参数，必填参数不能为空，不接受未定义的参数，参数值需符合类型、范围及可选项
+func (a Action) CheckInputs(inputs map[string]interface{}) error {
+	_, err := a.ValidateInputs(inputs)
+	return err
+}
+
+// ValidateInputs 校验输入参数，返回按参数类型转换后的输入，如字符串"10"转换为int
+func (a Action) ValidateInputs(inputs map[string]interface{}) (map[string]interface{}, error) {
+	params := make(map[string]Param)
+	for _, p := range a.Inputs {
+		params[p.Name] = p
+		if _, ok := inputs[p.Name]; p.Required && !ok {
+			return nil, fmt.Errorf("action %s: input %s required", a.Type, p.Name)
+		}
+	}
+	var result map[string]interface{}
+	if inputs != nil {
+		result = make(map[string]interface{}, len(inputs))
+	}
+	for name, val := range inputs {
+		p, ok := params[name]
+		if !ok {
+			return nil, fmt.Errorf("action %s: unknown input %s", a.Type, name)
+		}
+		v, err := p.Validate(val)
+		if err != nil {
+			return nil, fmt.Errorf("action %s: %s", a.Type, err)
+		}
+		result[name] = v
+	}
+	return result, nil
+}
+
+// UnlockWithPIN 使用密码开锁
+var UnlockWithPIN = Action{
+	Type: "unlock_with_pin",
+	Inputs: []Param{
+		{Name: "pin", ValType: String, Required: true},
+	},
+}
+
+// PTZGotoPreset 云台转到预置位
+var PTZGotoPreset = Action{
+	Type: "ptz_goto_preset",
+	Inputs: []Param{
+		{Name: "preset", ValType: Int, Required: true, Min: 1, Max: 255},
+	},
+}
+
+// IdentifyBlink 设备闪烁，用于识别设备
+var IdentifyBlink = Action{
+	Type: "identify_blink",
+	Inputs: []Param{
+		{Name: "duration", ValType: Int, Default: 3, Min: 1, Max: 60}, // 秒
+	},
+}
+
+// MediaPlay 开始或继续播放
+var MediaPlay = Action{Type: "media_play"}
+
+// MediaPause 暂停播放
+var MediaPause = Action{Type: "media_pause"}
+
+// MediaStop 停止播放
+var MediaStop = Action{Type: "media_stop"}
+
+// MediaNext 下一个
+var MediaNext = Action{Type: "media_next"}
+
+// MediaPrevious 上一个
+var MediaPrevious = Action{Type: "media_previous"}
+
+// MediaSeek 跳转到指定的播放进度
+var MediaSeek = Action{
+	Type: "media_seek",
+	Inputs: []Param{
+		{Name: "position", ValType: Int, Required: true, Min: 0}, // 秒
+	},
+}
@@ -60,6 +60,14 @@ type Attribute struct {
 	Default interface{} `json:"default,omitempty"`
 	Min     interface{} `json:"min,omitempty"`
 	Max     interface{} `json:"max,omitempty"`
+	Options []Option    `json:"options,omitempty"` // 可选项，不为空时只能设置为可选项中的值
+
+	Unit      Unit `json:"unit,omitempty"`      // 值的单位，插件使用设备原生的单位
+	Precision *int `json:"precision,omitempty"` // 浮点数的精度（小数位数）
+}
+
+func precision(p int) *int {
+	return &p
 }
 
 func (t Attribute) GetString() string {
@@ -159,6 +167,7 @@ var SubType = Attribute{
 var Volume = Attribute{
 	Type:    "volume",
 	ValType: Int32,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -183,6 +192,7 @@ var Brightness = Attribute{
 	ValType: Int32,
 	Min:     1,
 	Max:     100,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -194,6 +204,7 @@ var Brightness = Attribute{
 var ColorTemperature = Attribute{
 	Type:    "color_temp",
 	ValType: Int32,
+	Unit:    UnitKelvin,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -271,6 +282,7 @@ var CurrentPosition = Attribute{
 	ValType: Int32,
 	Min:     1,
 	Max:     100,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionNotify,
@@ -283,6 +295,7 @@ var TargetPosition = Attribute{
 	ValType: Int32,
 	Min:     1,
 	Max:     100,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -316,6 +329,7 @@ var Direction = Attribute{
 var Humidity = Attribute{
 	Type:    "humidity",
 	ValType: Int32,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionNotify,
@@ -324,8 +338,10 @@ var Humidity = Attribute{
 
 // Temperature 温度
 var Temperature = Attribute{
-	Type:    "temperature",
-	ValType: Float32,
+	Type:      "temperature",
+	ValType:   Float32,
+	Unit:      UnitCelsius,
+	Precision: precision(1),
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionNotify,
@@ -387,6 +403,7 @@ var MotionDetected = Attribute{
 var Battery = Attribute{
 	Type:    "battery",
 	ValType: Float32,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionNotify,
@@ -474,8 +491,10 @@ var Active = Attribute{
 
 // CurrentTemperature 当前温度
 var CurrentTemperature = Attribute{
-	Type:    "current_temperature",
-	ValType: Float32,
+	Type:      "current_temperature",
+	ValType:   Float32,
+	Unit:      UnitCelsius,
+	Precision: precision(1),
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionNotify,
@@ -507,6 +526,7 @@ var TargetHeatingCoolingState = Attribute{
 var HeatingThresholdTemperature = Attribute{
 	Type:    "heating_threshold_temperature",
 	ValType: Int32,
+	Unit:    UnitCelsius,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -518,6 +538,7 @@ var HeatingThresholdTemperature = Attribute{
 var CoolingThresholdTemperature = Attribute{
 	Type:    "cooling_threshold_temperature",
 	ValType: Int32,
+	Unit:    UnitCelsius,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -529,6 +550,7 @@ var CoolingThresholdTemperature = Attribute{
 var RotationSpeed = Attribute{
 	Type:    "rotation_speed",
 	ValType: Int32,
+	Unit:    UnitPercent,
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionWrite,
@@ -601,6 +623,59 @@ var Mute = Attribute{
 var CurrentAmbientLightLevel = Attribute{
 	Type:    "current_ambient_light_level",
 	ValType: Float32,
+	Unit:    UnitLux,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+	),
+}
+
+// Power 当前功率
+var Power = Attribute{
+	Type:      "power",
+	ValType:   Float32,
+	Min:       0,
+	Unit:      UnitWatt,
+	Precision: precision(1),
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+	),
+}
+
+// Voltage 电压
+var Voltage = Attribute{
+	Type:      "voltage",
+	ValType:   Float32,
+	Min:       0,
+	Unit:      UnitVolt,
+	Precision: precision(1),
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+	),
+}
+
+// ElectricCurrent 电流
+var ElectricCurrent = Attribute{
+	Type:      "electric_current",
+	ValType:   Float32,
+	Min:       0,
+	Unit:      UnitAmpere,
+	Precision: precision(2),
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+	),
+}
+
+// Energy 累计用电量，为设备上报的累计值，设备重置或更换计量芯片后可能从0重新累计
+var Energy = Attribute{
+	Type:      "energy",
+	ValType:   Float64,
+	Min:       0,
+	Unit:      UnitKilowattHour,
+	Precision: precision(3),
 	Permission: SetPermissions(
 		AttributePermissionRead,
 		AttributePermissionNotify,
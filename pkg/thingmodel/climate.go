@@ -0,0 +1,114 @@
+package thingmodel
+
+// HVAC模式，温控器的工作模式
+const (
+	HVACModeOff     = "off"      // 关闭
+	HVACModeHeat    = "heat"     // 制热
+	HVACModeCool    = "cool"     // 制冷
+	HVACModeAuto    = "auto"     // 自动，按目标温度自动制热或制冷
+	HVACModeDry     = "dry"      // 除湿
+	HVACModeFanOnly = "fan_only" // 送风
+)
+
+// HVAC当前动作，温控器当前实际的工作状态
+const (
+	HVACActionIdle    = "idle"
+	HVACActionHeating = "heating"
+	HVACActionCooling = "cooling"
+	HVACActionDrying  = "drying"
+	HVACActionFan     = "fan"
+)
+
+// 风速模式
+const (
+	FanModeAuto   = "auto"
+	FanModeLow    = "low"
+	FanModeMedium = "medium"
+	FanModeHigh   = "high"
+)
+
+// TargetTemperature 目标温度
+var TargetTemperature = Attribute{
+	Type:      "target_temperature",
+	ValType:   Float32,
+	Min:       5,
+	Max:       35,
+	Unit:      UnitCelsius,
+	Precision: precision(1),
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionWrite,
+		AttributePermissionNotify,
+	),
+}
+
+// HVACMode 温控器工作模式，插件可通过 SetOptions 设置设备支持的模式
+var HVACMode = Attribute{
+	Type:    "hvac_mode",
+	ValType: Enum,
+	Default: HVACModeOff,
+	Options: []Option{
+		{Name: "关闭", Val: HVACModeOff},
+		{Name: "制热", Val: HVACModeHeat},
+		{Name: "制冷", Val: HVACModeCool},
+		{Name: "自动", Val: HVACModeAuto},
+		{Name: "除湿", Val: HVACModeDry},
+		{Name: "送风", Val: HVACModeFanOnly},
+	},
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionWrite,
+		AttributePermissionNotify,
+	),
+}
+
+// HVACAction 温控器当前动作
+var HVACAction = Attribute{
+	Type:    "hvac_action",
+	ValType: Enum,
+	Default: HVACActionIdle,
+	Options: []Option{
+		{Name: "空闲", Val: HVACActionIdle},
+		{Name: "制热中", Val: HVACActionHeating},
+		{Name: "制冷中", Val: HVACActionCooling},
+		{Name: "除湿中", Val: HVACActionDrying},
+		{Name: "送风中", Val: HVACActionFan},
+	},
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+	),
+}
+
+// FanMode 风速模式
+var FanMode = Attribute{
+	Type:    "fan_mode",
+	ValType: Enum,
+	Default: FanModeAuto,
+	Options: []Option{
+		{Name: "自动", Val: FanModeAuto},
+		{Name: "低", Val: FanModeLow},
+		{Name: "中", Val: FanModeMedium},
+		{Name: "高", Val: FanModeHigh},
+	},
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionWrite,
+		AttributePermissionNotify,
+	),
+}
+
+// Hysteresis 温控回差，当前温度与目标温度相差超过回差才启动制热或制冷，
+// 为温差而不是温度，不进行单位换算
+var Hysteresis = Attribute{
+	Type:      "hysteresis",
+	ValType:   Float32,
+	Min:       0.1,
+	Max:       5,
+	Precision: precision(1),
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionWrite,
+		AttributePermissionNotify,
+	),
+}
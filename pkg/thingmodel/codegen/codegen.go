@@ -0,0 +1,283 @@
+// Package codegen 根据物模型清单生成客户端使用的类型常量，避免客户端硬编码服务、属性类型
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+const (
+	LangGo         = "go"
+	LangTypeScript = "ts"
+)
+
+type constant struct {
+	Name        string
+	Value       string
+	Description string
+}
+
+type data struct {
+	Package     string
+	Version     int
+	Hash        string
+	ValTypes    []constant
+	Permissions []thingmodel.PermissionDef
+	Units       []constant
+	Services    []constant
+	Attributes  []constant
+	Actions     []constant
+	Events      []constant
+	Defs        string // 属性定义的json，用于ts
+}
+
+// Generate 生成指定语言的常量代码，pkg为go的包名
+func Generate(m thingmodel.Manifest, lang, pkg string) ([]byte, error) {
+	d, err := newData(m, pkg)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl *template.Template
+	switch lang {
+	case LangGo:
+		tmpl = goTemplate
+	case LangTypeScript:
+		tmpl = tsTemplate
+	default:
+		return nil, fmt.Errorf("unsupported lang %s", lang)
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	if lang == LangGo {
+		return format.Source(buf.Bytes())
+	}
+	return buf.Bytes(), nil
+}
+
+func newData(m thingmodel.Manifest, pkg string) (d data, err error) {
+	d = data{
+		Package:     pkg,
+		Version:     m.Version,
+		Hash:        m.Hash,
+		Permissions: m.Permissions,
+	}
+	for _, v := range m.ValTypes {
+		d.ValTypes = append(d.ValTypes, constant{Name: camel(string(v)), Value: string(v)})
+	}
+	for _, u := range m.Units {
+		d.Units = append(d.Units, constant{Name: unitName(u), Value: string(u)})
+	}
+	for _, s := range m.Services {
+		d.Services = append(d.Services, constant{Name: camel(string(s.Type)), Value: string(s.Type), Description: s.Description})
+	}
+	defs := make(map[string]thingmodel.AttributeDef)
+	for _, a := range m.Attributes {
+		d.Attributes = append(d.Attributes, constant{Name: camel(a.Type), Value: a.Type, Description: a.Description})
+		defs[a.Type] = a
+	}
+	for _, a := range m.Actions {
+		d.Actions = append(d.Actions, constant{Name: camel(a.Type), Value: a.Type, Description: a.Description})
+	}
+	for _, e := range m.Events {
+		d.Events = append(d.Events, constant{Name: camel(e.Type), Value: e.Type, Description: e.Description})
+	}
+	data, err := json.MarshalIndent(defs, "", "  ")
+	if err != nil {
+		return
+	}
+	d.Defs = string(data)
+	return
+}
+
+// camel 将下划线分隔的类型转换为驼峰命名，如 light_bulb 转换为 LightBulb
+func camel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	return b.String()
+}
+
+// unitNames 单位的常量名，单位符号大多不是合法的标识符
+var unitNames = map[thingmodel.Unit]string{
+	thingmodel.UnitCelsius:      "Celsius",
+	thingmodel.UnitFahrenheit:   "Fahrenheit",
+	thingmodel.UnitPercent:      "Percent",
+	thingmodel.UnitLux:          "Lux",
+	thingmodel.UnitKelvin:       "Kelvin",
+	thingmodel.UnitWatt:         "Watt",
+	thingmodel.UnitKilowatt:     "Kilowatt",
+	thingmodel.UnitWattHour:     "WattHour",
+	thingmodel.UnitKilowattHour: "KilowattHour",
+	thingmodel.UnitVolt:         "Volt",
+	thingmodel.UnitAmpere:       "Ampere",
+	thingmodel.UnitSecond:       "Second",
+	thingmodel.UnitMinute:       "Minute",
+	thingmodel.UnitMillimeter:   "Millimeter",
+	thingmodel.UnitCentimeter:   "Centimeter",
+	thingmodel.UnitMeter:        "Meter",
+	thingmodel.UnitInch:         "Inch",
+	thingmodel.UnitFoot:         "Foot",
+	thingmodel.UnitKilogram:     "Kilogram",
+	thingmodel.UnitPound:        "Pound",
+	thingmodel.UnitHectopascal:  "Hectopascal",
+	thingmodel.UnitInchMercury:  "InchMercury",
+	thingmodel.UnitPPM:          "PPM",
+	thingmodel.UnitMicrogramPM3: "MicrogramPM3",
+	thingmodel.UnitDecibel:      "Decibel",
+}
+
+// unitName 单位的常量名，未预设名称时由单位符号转换
+func unitName(u thingmodel.Unit) string {
+	if name, ok := unitNames[u]; ok {
+		return name
+	}
+	return camel(string(u))
+}
+
+func quote(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+var funcs = template.FuncMap{"quote": quote, "camel": camel}
+
+var goTemplate = template.Must(template.New("go").Funcs(funcs).Parse(`// Code generated by thingmodelgen. DO NOT EDIT.
+
+package {{.Package}}
+
+// 物模型清单的版本及内容hash，可与SA接口返回的清单比较判断是否需要重新生成
+const (
+	ManifestVersion = {{.Version}}
+	ManifestHash    = "{{.Hash}}"
+)
+
+// 值类型
+const (
+{{- range .ValTypes}}
+	ValType{{.Name}} = {{quote .Value}}
+{{- end}}
+)
+
+// 属性权限
+const (
+{{- range .Permissions}}
+	Permission{{camel .Name}} = {{.Value}}
+{{- end}}
+)
+
+// 单位
+const (
+{{- range .Units}}
+	Unit{{.Name}} = {{quote .Value}}
+{{- end}}
+)
+
+// 服务类型
+const (
+{{- range .Services}}
+	Service{{.Name}} = {{quote .Value}} // {{.Description}}
+{{- end}}
+)
+
+// 属性类型
+const (
+{{- range .Attributes}}
+	Attr{{.Name}} = {{quote .Value}} // {{.Description}}
+{{- end}}
+)
+
+// 动作类型
+const (
+{{- range .Actions}}
+	Action{{.Name}} = {{quote .Value}} // {{.Description}}
+{{- end}}
+)
+
+// 事件类型
+const (
+{{- range .Events}}
+	Event{{.Name}} = {{quote .Value}} // {{.Description}}
+{{- end}}
+)
+`))
+
+var tsTemplate = template.Must(template.New("ts").Funcs(funcs).Parse(`// Code generated by thingmodelgen. DO NOT EDIT.
+
+export const MANIFEST_VERSION = {{.Version}};
+export const MANIFEST_HASH = "{{.Hash}}";
+
+export enum ValType {
+{{- range .ValTypes}}
+  {{.Name}} = {{quote .Value}},
+{{- end}}
+}
+
+export enum Permission {
+{{- range .Permissions}}
+  {{camel .Name}} = {{.Value}},
+{{- end}}
+}
+
+export enum Unit {
+{{- range .Units}}
+  {{.Name}} = {{quote .Value}},
+{{- end}}
+}
+
+export enum ServiceType {
+{{- range .Services}}
+  /** {{.Description}} */
+  {{.Name}} = {{quote .Value}},
+{{- end}}
+}
+
+export enum AttributeType {
+{{- range .Attributes}}
+  /** {{.Description}} */
+  {{.Name}} = {{quote .Value}},
+{{- end}}
+}
+
+export enum ActionType {
+{{- range .Actions}}
+  /** {{.Description}} */
+  {{.Name}} = {{quote .Value}},
+{{- end}}
+}
+
+export enum EventType {
+{{- range .Events}}
+  /** {{.Description}} */
+  {{.Name}} = {{quote .Value}},
+{{- end}}
+}
+
+export interface AttributeDef {
+  type: string;
+  description: string;
+  val_type: string;
+  permission: number;
+  min?: number;
+  max?: number;
+  default?: unknown;
+  options?: { name: string; val: unknown }[];
+  unit?: string;
+  precision?: number;
+}
+
+export const ATTRIBUTE_DEFS: Record<string, AttributeDef> = {{.Defs}};
+`))
@@ -0,0 +1,70 @@
+package codegen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zhiting-tech/smartassistant/pkg/thingmodel"
+)
+
+// TestGeneratedUpToDate 提交的常量文件需与当前物模型一致，不一致时需执行 go generate
+func TestGeneratedUpToDate(t *testing.T) {
+	cases := []struct {
+		lang string
+		file string
+	}{
+		{LangGo, "../constants/constants_gen.go"},
+		{LangTypeScript, "../constants/thingmodel.ts"},
+	}
+	for _, c := range cases {
+		expected, err := Generate(thingmodel.GetManifest(), c.lang, "constants")
+		require.NoError(t, err)
+		actual, err := ioutil.ReadFile(c.file)
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual), "%s is outdated, run go generate ./pkg/thingmodel/constants", c.file)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	src, err := Generate(thingmodel.GetManifest(), LangGo, "constants")
+	require.NoError(t, err)
+
+	f, err := parser.ParseFile(token.NewFileSet(), "constants_gen.go", src, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "constants", f.Name.Name)
+
+	// 常量名不能重复，否则生成的代码无法编译
+	names := make(map[string]bool)
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			for _, name := range spec.(*ast.ValueSpec).Names {
+				assert.False(t, names[name.Name], "duplicate const %s", name.Name)
+				names[name.Name] = true
+			}
+		}
+	}
+	assert.True(t, names["ServiceLightBulb"])
+	assert.True(t, names["AttrOnOff"])
+	assert.True(t, names["UnitCelsius"])
+}
+
+func TestGenerateUnsupportedLang(t *testing.T) {
+	_, err := Generate(thingmodel.GetManifest(), "java", "constants")
+	assert.Error(t, err)
+}
+
+func TestCamel(t *testing.T) {
+	assert.Equal(t, "LightBulb", camel("light_bulb"))
+	assert.Equal(t, "OnOff", camel("on_off"))
+	assert.Equal(t, "Pm25", camel("pm2.5"))
+}
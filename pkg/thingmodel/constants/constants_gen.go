@@ -0,0 +1,193 @@
+// Code generated by thingmodelgen. DO NOT EDIT.
+
+package constants
+
+// 物模型清单的版本及内容hash，可与SA接口返回的清单比较判断是否需要重新生成
+const (
+	ManifestVersion = 1
+	ManifestHash    = "e23d9d2cc1bb0bf096bdf474a6c36e0a73f9434edb6498b9ce9a86b0485efb88"
+)
+
+// 值类型
+const (
+	ValTypeInt     = "int"
+	ValTypeInt32   = "int32"
+	ValTypeInt64   = "int64"
+	ValTypeString  = "string"
+	ValTypeBool    = "bool"
+	ValTypeFloat32 = "float32"
+	ValTypeFloat64 = "float64"
+	ValTypeEnum    = "enum"
+	ValTypeJson    = "json"
+)
+
+// 属性权限
+const (
+	PermissionRead        = 1
+	PermissionWrite       = 2
+	PermissionNotify      = 4
+	PermissionHidden      = 8
+	PermissionSceneHidden = 16
+)
+
+// 单位
+const (
+	UnitCelsius      = "°C"
+	UnitFahrenheit   = "°F"
+	UnitPercent      = "%"
+	UnitLux          = "lx"
+	UnitKelvin       = "K"
+	UnitWatt         = "W"
+	UnitKilowatt     = "kW"
+	UnitWattHour     = "Wh"
+	UnitKilowattHour = "kWh"
+	UnitVolt         = "V"
+	UnitAmpere       = "A"
+	UnitSecond       = "s"
+	UnitMinute       = "min"
+	UnitMillimeter   = "mm"
+	UnitCentimeter   = "cm"
+	UnitMeter        = "m"
+	UnitInch         = "in"
+	UnitFoot         = "ft"
+	UnitKilogram     = "kg"
+	UnitPound        = "lb"
+	UnitHectopascal  = "hPa"
+	UnitInchMercury  = "inHg"
+	UnitPPM          = "ppm"
+	UnitMicrogramPM3 = "µg/m³"
+	UnitDecibel      = "dB"
+)
+
+// 服务类型
+const (
+	ServiceInfo                      = "info"                         // 详情
+	ServiceGateway                   = "gateway"                      // 网关
+	ServiceLightBulb                 = "light_bulb"                   // 灯
+	ServiceSwitch                    = "switch"                       // 开关
+	ServiceOutlet                    = "outlet"                       // 插座
+	ServiceCurtain                   = "curtain"                      // 窗帘电机
+	ServiceTemperatureSensor         = "temperature_sensor"           // 温度传感器
+	ServiceHumiditySensor            = "humidity_sensor"              // 湿度传感器
+	ServiceHeaterCooler              = "heater_cooler"                // 加热器冷却器
+	ServiceLock                      = "lock"                         // 门锁
+	ServiceDoor                      = "door"                         // 门
+	ServiceDoorbell                  = "doorbell"                     // 门铃
+	ServiceMotionSensor              = "motion_sensor"                // 人体传感器
+	ServiceLeakSensor                = "leak_sensor"                  // 水浸传感器
+	ServiceBattery                   = "battery"                      // 电池
+	ServiceSecuritySystem            = "security_system"              // 安全系统
+	ServiceStatelessSwitch           = "stateless_switch"             // 无线开关
+	ServiceContactSensor             = "contact_sensor"               // 接触式传感器
+	ServiceSpeaker                   = "speaker"                      // 扬声器
+	ServiceMicrophone                = "microphone"                   // 麦克风
+	ServiceLightSensor               = "light_sensor"                 // 光传感器
+	ServiceCameraRtpStreamManagement = "camera_rtp_stream_management" // 流管理
+	ServiceOperatingMode             = "operating_mode"               // 工作模式
+	ServiceMediaNegotiation          = "media_negotiation"            // webrtc媒体交换
+	ServicePtz                       = "ptz"                          // 摄像头云台控制
+	ServiceMedia                     = "media"                        // 摄像头视频配置
+	ServiceEnergyMeter               = "energy_meter"                 // 电能计量
+	ServiceThermostat                = "thermostat"                   // 温控器
+	ServiceMediaPlayer               = "media_player"                 // 媒体播放器
+	ServiceTelevision                = "television"                   // 电视
+)
+
+// 属性类型
+const (
+	AttrSubType                     = "sub_type"                      // 子设备类型
+	AttrVolume                      = "volume"                        // 音量
+	AttrOnOff                       = "on_off"                        // 开关
+	AttrBrightness                  = "brightness"                    // 亮度
+	AttrColorTemp                   = "color_temp"                    // 色温
+	AttrRgb                         = "rgb"                           // RGB
+	AttrModel                       = "model"                         // 型号
+	AttrManufacturer                = "manufacturer"                  // 厂商
+	AttrIdentify                    = "identify"                      // 唯一标识
+	AttrVersion                     = "version"                       // 固件版本
+	AttrName                        = "name"                          // 设备名称
+	AttrType                        = "type"                          // 设备类型
+	AttrCurrentPosition             = "current_position"              // 当前位置
+	AttrTargetPosition              = "target_position"               // 目标位置
+	AttrState                       = "state"                         // 状态
+	AttrDirection                   = "direction"                     // 方向
+	AttrHumidity                    = "humidity"                      // 湿度
+	AttrTemperature                 = "temperature"                   // 温度
+	AttrLeakDetected                = "leak_detected"                 // 泄漏检测
+	AttrSwitchEvent                 = "switch_event"                  // 开关事件
+	AttrTargetState                 = "target_state"                  // 目标状态
+	AttrCurrentState                = "current_state"                 // 当前状态
+	AttrMotionDetected              = "motion_detected"               // 移动检测
+	AttrBattery                     = "battery"                       // 电池
+	AttrLockCurrentState            = "lock_current_state"            // 锁当前状态
+	AttrLockTargetState             = "lock_target_state"             // 锁目标状态
+	AttrLockEvent                   = "lock_event"                    // 锁事件
+	AttrLockNotification            = "lock_notification"             // 锁通知
+	AttrLogs                        = "logs"                          // 日志
+	AttrActive                      = "active"                        // 活动状态
+	AttrCurrentTemperature          = "current_temperature"           // 当前温度
+	AttrCurrentHeatingCoolingState  = "current_heating_cooling_state" // 当前加热冷却状态
+	AttrTargetHeatingCoolingState   = "target_heating_cooling_state"  // 目标加热冷却状态
+	AttrHeatingThresholdTemperature = "heating_threshold_temperature" // 加热阈值温度
+	AttrCoolingThresholdTemperature = "cooling_threshold_temperature" // 冷却阈值温度
+	AttrRotationSpeed               = "rotation_speed"                // 转速
+	AttrSwingMode                   = "swing_mode"                    // 摆动模式
+	AttrPermitJoin                  = "permit_join"                   // 是否允许加入
+	AttrAlert                       = "alert"                         // 告警
+	AttrStatusLowBattery            = "status_low_battery"            // 低电量状态
+	AttrContactSensorState          = "contact_sensor_state"          // 触点传感器状态
+	AttrMute                        = "mute"                          // 静音
+	AttrCurrentAmbientLightLevel    = "current_ambient_light_level"   // 环境光照度
+	AttrPower                       = "power"                         // 功率
+	AttrVoltage                     = "voltage"                       // 电压
+	AttrElectricCurrent             = "electric_current"              // 电流
+	AttrEnergy                      = "energy"                        // 累计用电量
+	AttrTargetTemperature           = "target_temperature"            // 目标温度
+	AttrHvacMode                    = "hvac_mode"                     // 温控器工作模式
+	AttrHvacAction                  = "hvac_action"                   // 温控器当前动作
+	AttrFanMode                     = "fan_mode"                      // 风速模式
+	AttrHysteresis                  = "hysteresis"                    // 温控回差
+	AttrPlaybackState               = "playback_state"                // 播放状态
+	AttrInputSource                 = "input_source"                  // 输入源
+	AttrMediaTitle                  = "media_title"                   // 媒体标题
+	AttrMediaArtist                 = "media_artist"                  // 媒体艺术家
+	AttrMediaAlbum                  = "media_album"                   // 媒体专辑
+	AttrMediaImageUrl               = "media_image_url"               // 媒体封面
+	AttrMediaDuration               = "media_duration"                // 媒体时长
+	AttrMediaPosition               = "media_position"                // 播放进度
+	AttrNightVision                 = "night_vision"                  // 夜视灯
+	AttrModeIndicator               = "mode_indicator"                // 模式指示灯
+	AttrWebrtcControl               = "webrtc_control"                // WebRTC控制
+	AttrAnswer                      = "answer"                        // WebRTC应答
+	AttrStreamingStatus             = "streaming_status"              // 流状态
+	AttrMove                        = "move"                          // 摄像头云台持续移动
+	AttrResolutionOptions           = "resolution_options"            // 摄像头分辨率可设属性
+	AttrResolution                  = "resolution"                    // 摄像头分辨率属性
+	AttrFrameRateLimit              = "frame_rate_limit"              // 摄像头帧率
+	AttrBitrateLimit                = "bitrate_limit"                 // 摄像头码率
+	AttrEncodingInterval            = "encoding_interval"             // 摄像头编码区间
+	AttrMediaQuality                = "media_quality"                 // 摄像头视频质量
+	AttrGovLength                   = "gov_length"                    // 摄像头I帧间隔长度
+	AttrTopDownCruise               = "top_down_cruise"               // 摄像头云台上下巡航
+	AttrLeftRightCruise             = "left_right_cruise"             // 摄像头云台左右巡航
+	AttrSelectItems                 = "select_items"                  // 场景选择项
+)
+
+// 动作类型
+const (
+	ActionUnlockWithPin = "unlock_with_pin" // 使用密码开锁
+	ActionPtzGotoPreset = "ptz_goto_preset" // 云台转到预置位
+	ActionIdentifyBlink = "identify_blink"  // 设备闪烁
+	ActionMediaPlay     = "media_play"      // 播放
+	ActionMediaPause    = "media_pause"     // 暂停
+	ActionMediaStop     = "media_stop"      // 停止
+	ActionMediaNext     = "media_next"      // 下一个
+	ActionMediaPrevious = "media_previous"  // 上一个
+	ActionMediaSeek     = "media_seek"      // 跳转播放进度
+)
+
+// 事件类型
+const (
+	EventButtonPress = "button_press" // 按键按下
+	EventAlarm       = "alarm"        // 报警
+)
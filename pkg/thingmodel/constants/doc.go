@@ -0,0 +1,6 @@
+// Package constants 物模型的类型常量，供go客户端使用，thingmodel.ts 供ts客户端使用，
+// 物模型变化后执行 go generate 重新生成
+package constants
+
+//go:generate go run ../../../cmd/thingmodelgen -lang go -pkg constants -o constants_gen.go
+//go:generate go run ../../../cmd/thingmodelgen -lang ts -o thingmodel.ts
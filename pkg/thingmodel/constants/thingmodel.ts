@@ -0,0 +1,906 @@
+// Code generated by thingmodelgen. DO NOT EDIT.
+
+export const MANIFEST_VERSION = 1;
+export const MANIFEST_HASH = "e23d9d2cc1bb0bf096bdf474a6c36e0a73f9434edb6498b9ce9a86b0485efb88";
+
+export enum ValType {
+  Int = "int",
+  Int32 = "int32",
+  Int64 = "int64",
+  String = "string",
+  Bool = "bool",
+  Float32 = "float32",
+  Float64 = "float64",
+  Enum = "enum",
+  Json = "json",
+}
+
+export enum Permission {
+  Read = 1,
+  Write = 2,
+  Notify = 4,
+  Hidden = 8,
+  SceneHidden = 16,
+}
+
+export enum Unit {
+  Celsius = "°C",
+  Fahrenheit = "°F",
+  Percent = "%",
+  Lux = "lx",
+  Kelvin = "K",
+  Watt = "W",
+  Kilowatt = "kW",
+  WattHour = "Wh",
+  KilowattHour = "kWh",
+  Volt = "V",
+  Ampere = "A",
+  Second = "s",
+  Minute = "min",
+  Millimeter = "mm",
+  Centimeter = "cm",
+  Meter = "m",
+  Inch = "in",
+  Foot = "ft",
+  Kilogram = "kg",
+  Pound = "lb",
+  Hectopascal = "hPa",
+  InchMercury = "inHg",
+  PPM = "ppm",
+  MicrogramPM3 = "µg/m³",
+  Decibel = "dB",
+}
+
+export enum ServiceType {
+  /** 详情 */
+  Info = "info",
+  /** 网关 */
+  Gateway = "gateway",
+  /** 灯 */
+  LightBulb = "light_bulb",
+  /** 开关 */
+  Switch = "switch",
+  /** 插座 */
+  Outlet = "outlet",
+  /** 窗帘电机 */
+  Curtain = "curtain",
+  /** 温度传感器 */
+  TemperatureSensor = "temperature_sensor",
+  /** 湿度传感器 */
+  HumiditySensor = "humidity_sensor",
+  /** 加热器冷却器 */
+  HeaterCooler = "heater_cooler",
+  /** 门锁 */
+  Lock = "lock",
+  /** 门 */
+  Door = "door",
+  /** 门铃 */
+  Doorbell = "doorbell",
+  /** 人体传感器 */
+  MotionSensor = "motion_sensor",
+  /** 水浸传感器 */
+  LeakSensor = "leak_sensor",
+  /** 电池 */
+  Battery = "battery",
+  /** 安全系统 */
+  SecuritySystem = "security_system",
+  /** 无线开关 */
+  StatelessSwitch = "stateless_switch",
+  /** 接触式传感器 */
+  ContactSensor = "contact_sensor",
+  /** 扬声器 */
+  Speaker = "speaker",
+  /** 麦克风 */
+  Microphone = "microphone",
+  /** 光传感器 */
+  LightSensor = "light_sensor",
+  /** 流管理 */
+  CameraRtpStreamManagement = "camera_rtp_stream_management",
+  /** 工作模式 */
+  OperatingMode = "operating_mode",
+  /** webrtc媒体交换 */
+  MediaNegotiation = "media_negotiation",
+  /** 摄像头云台控制 */
+  Ptz = "ptz",
+  /** 摄像头视频配置 */
+  Media = "media",
+  /** 电能计量 */
+  EnergyMeter = "energy_meter",
+  /** 温控器 */
+  Thermostat = "thermostat",
+  /** 媒体播放器 */
+  MediaPlayer = "media_player",
+  /** 电视 */
+  Television = "television",
+}
+
+export enum AttributeType {
+  /** 子设备类型 */
+  SubType = "sub_type",
+  /** 音量 */
+  Volume = "volume",
+  /** 开关 */
+  OnOff = "on_off",
+  /** 亮度 */
+  Brightness = "brightness",
+  /** 色温 */
+  ColorTemp = "color_temp",
+  /** RGB */
+  Rgb = "rgb",
+  /** 型号 */
+  Model = "model",
+  /** 厂商 */
+  Manufacturer = "manufacturer",
+  /** 唯一标识 */
+  Identify = "identify",
+  /** 固件版本 */
+  Version = "version",
+  /** 设备名称 */
+  Name = "name",
+  /** 设备类型 */
+  Type = "type",
+  /** 当前位置 */
+  CurrentPosition = "current_position",
+  /** 目标位置 */
+  TargetPosition = "target_position",
+  /** 状态 */
+  State = "state",
+  /** 方向 */
+  Direction = "direction",
+  /** 湿度 */
+  Humidity = "humidity",
+  /** 温度 */
+  Temperature = "temperature",
+  /** 泄漏检测 */
+  LeakDetected = "leak_detected",
+  /** 开关事件 */
+  SwitchEvent = "switch_event",
+  /** 目标状态 */
+  TargetState = "target_state",
+  /** 当前状态 */
+  CurrentState = "current_state",
+  /** 移动检测 */
+  MotionDetected = "motion_detected",
+  /** 电池 */
+  Battery = "battery",
+  /** 锁当前状态 */
+  LockCurrentState = "lock_current_state",
+  /** 锁目标状态 */
+  LockTargetState = "lock_target_state",
+  /** 锁事件 */
+  LockEvent = "lock_event",
+  /** 锁通知 */
+  LockNotification = "lock_notification",
+  /** 日志 */
+  Logs = "logs",
+  /** 活动状态 */
+  Active = "active",
+  /** 当前温度 */
+  CurrentTemperature = "current_temperature",
+  /** 当前加热冷却状态 */
+  CurrentHeatingCoolingState = "current_heating_cooling_state",
+  /** 目标加热冷却状态 */
+  TargetHeatingCoolingState = "target_heating_cooling_state",
+  /** 加热阈值温度 */
+  HeatingThresholdTemperature = "heating_threshold_temperature",
+  /** 冷却阈值温度 */
+  CoolingThresholdTemperature = "cooling_threshold_temperature",
+  /** 转速 */
+  RotationSpeed = "rotation_speed",
+  /** 摆动模式 */
+  SwingMode = "swing_mode",
+  /** 是否允许加入 */
+  PermitJoin = "permit_join",
+  /** 告警 */
+  Alert = "alert",
+  /** 低电量状态 */
+  StatusLowBattery = "status_low_battery",
+  /** 触点传感器状态 */
+  ContactSensorState = "contact_sensor_state",
+  /** 静音 */
+  Mute = "mute",
+  /** 环境光照度 */
+  CurrentAmbientLightLevel = "current_ambient_light_level",
+  /** 功率 */
+  Power = "power",
+  /** 电压 */
+  Voltage = "voltage",
+  /** 电流 */
+  ElectricCurrent = "electric_current",
+  /** 累计用电量 */
+  Energy = "energy",
+  /** 目标温度 */
+  TargetTemperature = "target_temperature",
+  /** 温控器工作模式 */
+  HvacMode = "hvac_mode",
+  /** 温控器当前动作 */
+  HvacAction = "hvac_action",
+  /** 风速模式 */
+  FanMode = "fan_mode",
+  /** 温控回差 */
+  Hysteresis = "hysteresis",
+  /** 播放状态 */
+  PlaybackState = "playback_state",
+  /** 输入源 */
+  InputSource = "input_source",
+  /** 媒体标题 */
+  MediaTitle = "media_title",
+  /** 媒体艺术家 */
+  MediaArtist = "media_artist",
+  /** 媒体专辑 */
+  MediaAlbum = "media_album",
+  /** 媒体封面 */
+  MediaImageUrl = "media_image_url",
+  /** 媒体时长 */
+  MediaDuration = "media_duration",
+  /** 播放进度 */
+  MediaPosition = "media_position",
+  /** 夜视灯 */
+  NightVision = "night_vision",
+  /** 模式指示灯 */
+  ModeIndicator = "mode_indicator",
+  /** WebRTC控制 */
+  WebrtcControl = "webrtc_control",
+  /** WebRTC应答 */
+  Answer = "answer",
+  /** 流状态 */
+  StreamingStatus = "streaming_status",
+  /** 摄像头云台持续移动 */
+  Move = "move",
+  /** 摄像头分辨率可设属性 */
+  ResolutionOptions = "resolution_options",
+  /** 摄像头分辨率属性 */
+  Resolution = "resolution",
+  /** 摄像头帧率 */
+  FrameRateLimit = "frame_rate_limit",
+  /** 摄像头码率 */
+  BitrateLimit = "bitrate_limit",
+  /** 摄像头编码区间 */
+  EncodingInterval = "encoding_interval",
+  /** 摄像头视频质量 */
+  MediaQuality = "media_quality",
+  /** 摄像头I帧间隔长度 */
+  GovLength = "gov_length",
+  /** 摄像头云台上下巡航 */
+  TopDownCruise = "top_down_cruise",
+  /** 摄像头云台左右巡航 */
+  LeftRightCruise = "left_right_cruise",
+  /** 场景选择项 */
+  SelectItems = "select_items",
+}
+
+export enum ActionType {
+  /** 使用密码开锁 */
+  UnlockWithPin = "unlock_with_pin",
+  /** 云台转到预置位 */
+  PtzGotoPreset = "ptz_goto_preset",
+  /** 设备闪烁 */
+  IdentifyBlink = "identify_blink",
+  /** 播放 */
+  MediaPlay = "media_play",
+  /** 暂停 */
+  MediaPause = "media_pause",
+  /** 停止 */
+  MediaStop = "media_stop",
+  /** 下一个 */
+  MediaNext = "media_next",
+  /** 上一个 */
+  MediaPrevious = "media_previous",
+  /** 跳转播放进度 */
+  MediaSeek = "media_seek",
+}
+
+export enum EventType {
+  /** 按键按下 */
+  ButtonPress = "button_press",
+  /** 报警 */
+  Alarm = "alarm",
+}
+
+export interface AttributeDef {
+  type: string;
+  description: string;
+  val_type: string;
+  permission: number;
+  min?: number;
+  max?: number;
+  default?: unknown;
+  options?: { name: string; val: unknown }[];
+  unit?: string;
+  precision?: number;
+}
+
+export const ATTRIBUTE_DEFS: Record<string, AttributeDef> = {
+  "active": {
+    "type": "active",
+    "description": "活动状态",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "alert": {
+    "type": "alert",
+    "description": "告警",
+    "val_type": "int32",
+    "permission": 4
+  },
+  "answer": {
+    "type": "answer",
+    "description": "WebRTC应答",
+    "val_type": "string",
+    "permission": 13
+  },
+  "battery": {
+    "type": "battery",
+    "description": "电池",
+    "val_type": "float32",
+    "permission": 5,
+    "unit": "%"
+  },
+  "bitrate_limit": {
+    "type": "bitrate_limit",
+    "description": "摄像头码率",
+    "val_type": "int32",
+    "permission": 15
+  },
+  "brightness": {
+    "type": "brightness",
+    "description": "亮度",
+    "val_type": "int32",
+    "permission": 7,
+    "min": 1,
+    "max": 100,
+    "unit": "%"
+  },
+  "color_temp": {
+    "type": "color_temp",
+    "description": "色温",
+    "val_type": "int32",
+    "permission": 7,
+    "unit": "K"
+  },
+  "contact_sensor_state": {
+    "type": "contact_sensor_state",
+    "description": "触点传感器状态",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "cooling_threshold_temperature": {
+    "type": "cooling_threshold_temperature",
+    "description": "冷却阈值温度",
+    "val_type": "int32",
+    "permission": 7,
+    "unit": "°C"
+  },
+  "current_ambient_light_level": {
+    "type": "current_ambient_light_level",
+    "description": "环境光照度",
+    "val_type": "float32",
+    "permission": 5,
+    "unit": "lx"
+  },
+  "current_heating_cooling_state": {
+    "type": "current_heating_cooling_state",
+    "description": "当前加热冷却状态",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "current_position": {
+    "type": "current_position",
+    "description": "当前位置",
+    "val_type": "int32",
+    "permission": 5,
+    "min": 1,
+    "max": 100,
+    "unit": "%"
+  },
+  "current_state": {
+    "type": "current_state",
+    "description": "当前状态",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "current_temperature": {
+    "type": "current_temperature",
+    "description": "当前温度",
+    "val_type": "float32",
+    "permission": 5,
+    "unit": "°C",
+    "precision": 1
+  },
+  "direction": {
+    "type": "direction",
+    "description": "方向",
+    "val_type": "bool",
+    "permission": 7
+  },
+  "electric_current": {
+    "type": "electric_current",
+    "description": "电流",
+    "val_type": "float32",
+    "permission": 5,
+    "min": 0,
+    "unit": "A",
+    "precision": 2
+  },
+  "encoding_interval": {
+    "type": "encoding_interval",
+    "description": "摄像头编码区间",
+    "val_type": "int32",
+    "permission": 15
+  },
+  "energy": {
+    "type": "energy",
+    "description": "累计用电量",
+    "val_type": "float64",
+    "permission": 5,
+    "min": 0,
+    "unit": "kWh",
+    "precision": 3
+  },
+  "fan_mode": {
+    "type": "fan_mode",
+    "description": "风速模式",
+    "val_type": "enum",
+    "permission": 7,
+    "default": "auto",
+    "options": [
+      {
+        "name": "自动",
+        "val": "auto"
+      },
+      {
+        "name": "低",
+        "val": "low"
+      },
+      {
+        "name": "中",
+        "val": "medium"
+      },
+      {
+        "name": "高",
+        "val": "high"
+      }
+    ]
+  },
+  "frame_rate_limit": {
+    "type": "frame_rate_limit",
+    "description": "摄像头帧率",
+    "val_type": "int32",
+    "permission": 15
+  },
+  "gov_length": {
+    "type": "gov_length",
+    "description": "摄像头I帧间隔长度",
+    "val_type": "int32",
+    "permission": 15
+  },
+  "heating_threshold_temperature": {
+    "type": "heating_threshold_temperature",
+    "description": "加热阈值温度",
+    "val_type": "int32",
+    "permission": 7,
+    "unit": "°C"
+  },
+  "humidity": {
+    "type": "humidity",
+    "description": "湿度",
+    "val_type": "int32",
+    "permission": 5,
+    "unit": "%"
+  },
+  "hvac_action": {
+    "type": "hvac_action",
+    "description": "温控器当前动作",
+    "val_type": "enum",
+    "permission": 5,
+    "default": "idle",
+    "options": [
+      {
+        "name": "空闲",
+        "val": "idle"
+      },
+      {
+        "name": "制热中",
+        "val": "heating"
+      },
+      {
+        "name": "制冷中",
+        "val": "cooling"
+      },
+      {
+        "name": "除湿中",
+        "val": "drying"
+      },
+      {
+        "name": "送风中",
+        "val": "fan"
+      }
+    ]
+  },
+  "hvac_mode": {
+    "type": "hvac_mode",
+    "description": "温控器工作模式",
+    "val_type": "enum",
+    "permission": 7,
+    "default": "off",
+    "options": [
+      {
+        "name": "关闭",
+        "val": "off"
+      },
+      {
+        "name": "制热",
+        "val": "heat"
+      },
+      {
+        "name": "制冷",
+        "val": "cool"
+      },
+      {
+        "name": "自动",
+        "val": "auto"
+      },
+      {
+        "name": "除湿",
+        "val": "dry"
+      },
+      {
+        "name": "送风",
+        "val": "fan_only"
+      }
+    ]
+  },
+  "hysteresis": {
+    "type": "hysteresis",
+    "description": "温控回差",
+    "val_type": "float32",
+    "permission": 7,
+    "min": 0.1,
+    "max": 5,
+    "precision": 1
+  },
+  "identify": {
+    "type": "identify",
+    "description": "唯一标识",
+    "val_type": "string",
+    "permission": 1
+  },
+  "input_source": {
+    "type": "input_source",
+    "description": "输入源",
+    "val_type": "enum",
+    "permission": 7
+  },
+  "leak_detected": {
+    "type": "leak_detected",
+    "description": "泄漏检测",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "left_right_cruise": {
+    "type": "left_right_cruise",
+    "description": "摄像头云台左右巡航",
+    "val_type": "string",
+    "permission": 7
+  },
+  "lock_current_state": {
+    "type": "lock_current_state",
+    "description": "锁当前状态",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "lock_event": {
+    "type": "lock_event",
+    "description": "锁事件",
+    "val_type": "int32",
+    "permission": 4
+  },
+  "lock_notification": {
+    "type": "lock_notification",
+    "description": "锁通知",
+    "val_type": "json",
+    "permission": 4
+  },
+  "lock_target_state": {
+    "type": "lock_target_state",
+    "description": "锁目标状态",
+    "val_type": "int32",
+    "permission": 7
+  },
+  "logs": {
+    "type": "logs",
+    "description": "日志",
+    "val_type": "string",
+    "permission": 7
+  },
+  "manufacturer": {
+    "type": "manufacturer",
+    "description": "厂商",
+    "val_type": "string",
+    "permission": 1
+  },
+  "media_album": {
+    "type": "media_album",
+    "description": "媒体专辑",
+    "val_type": "string",
+    "permission": 21
+  },
+  "media_artist": {
+    "type": "media_artist",
+    "description": "媒体艺术家",
+    "val_type": "string",
+    "permission": 21
+  },
+  "media_duration": {
+    "type": "media_duration",
+    "description": "媒体时长",
+    "val_type": "int",
+    "permission": 21,
+    "min": 0,
+    "unit": "s"
+  },
+  "media_image_url": {
+    "type": "media_image_url",
+    "description": "媒体封面",
+    "val_type": "string",
+    "permission": 21
+  },
+  "media_position": {
+    "type": "media_position",
+    "description": "播放进度",
+    "val_type": "int",
+    "permission": 21,
+    "min": 0,
+    "unit": "s"
+  },
+  "media_quality": {
+    "type": "media_quality",
+    "description": "摄像头视频质量",
+    "val_type": "float32",
+    "permission": 15
+  },
+  "media_title": {
+    "type": "media_title",
+    "description": "媒体标题",
+    "val_type": "string",
+    "permission": 21
+  },
+  "mode_indicator": {
+    "type": "mode_indicator",
+    "description": "模式指示灯",
+    "val_type": "int32",
+    "permission": 7
+  },
+  "model": {
+    "type": "model",
+    "description": "型号",
+    "val_type": "string",
+    "permission": 1
+  },
+  "motion_detected": {
+    "type": "motion_detected",
+    "description": "移动检测",
+    "val_type": "bool",
+    "permission": 5
+  },
+  "move": {
+    "type": "move",
+    "description": "摄像头云台持续移动",
+    "val_type": "string",
+    "permission": 2
+  },
+  "mute": {
+    "type": "mute",
+    "description": "静音",
+    "val_type": "bool",
+    "permission": 7
+  },
+  "name": {
+    "type": "name",
+    "description": "设备名称",
+    "val_type": "string",
+    "permission": 1
+  },
+  "night_vision": {
+    "type": "night_vision",
+    "description": "夜视灯",
+    "val_type": "bool",
+    "permission": 7
+  },
+  "on_off": {
+    "type": "on_off",
+    "description": "开关",
+    "val_type": "string",
+    "permission": 7
+  },
+  "permit_join": {
+    "type": "permit_join",
+    "description": "是否允许加入",
+    "val_type": "int32",
+    "permission": 15
+  },
+  "playback_state": {
+    "type": "playback_state",
+    "description": "播放状态",
+    "val_type": "enum",
+    "permission": 5,
+    "default": "idle",
+    "options": [
+      {
+        "name": "播放中",
+        "val": "playing"
+      },
+      {
+        "name": "已暂停",
+        "val": "paused"
+      },
+      {
+        "name": "已停止",
+        "val": "stopped"
+      },
+      {
+        "name": "空闲",
+        "val": "idle"
+      },
+      {
+        "name": "缓冲中",
+        "val": "buffering"
+      }
+    ]
+  },
+  "power": {
+    "type": "power",
+    "description": "功率",
+    "val_type": "float32",
+    "permission": 5,
+    "min": 0,
+    "unit": "W",
+    "precision": 1
+  },
+  "resolution": {
+    "type": "resolution",
+    "description": "摄像头分辨率属性",
+    "val_type": "string",
+    "permission": 23
+  },
+  "resolution_options": {
+    "type": "resolution_options",
+    "description": "摄像头分辨率可设属性",
+    "val_type": "string",
+    "permission": 1
+  },
+  "rgb": {
+    "type": "rgb",
+    "description": "RGB",
+    "val_type": "string",
+    "permission": 7
+  },
+  "rotation_speed": {
+    "type": "rotation_speed",
+    "description": "转速",
+    "val_type": "int32",
+    "permission": 7,
+    "unit": "%"
+  },
+  "select_items": {
+    "type": "select_items",
+    "description": "场景选择项",
+    "val_type": "json",
+    "permission": 2
+  },
+  "state": {
+    "type": "state",
+    "description": "状态",
+    "val_type": "int32",
+    "permission": 7
+  },
+  "status_low_battery": {
+    "type": "status_low_battery",
+    "description": "低电量状态",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "streaming_status": {
+    "type": "streaming_status",
+    "description": "流状态",
+    "val_type": "int32",
+    "permission": 13
+  },
+  "sub_type": {
+    "type": "sub_type",
+    "description": "子设备类型",
+    "val_type": "string",
+    "permission": 1
+  },
+  "swing_mode": {
+    "type": "swing_mode",
+    "description": "摆动模式",
+    "val_type": "int32",
+    "permission": 7
+  },
+  "switch_event": {
+    "type": "switch_event",
+    "description": "开关事件",
+    "val_type": "int32",
+    "permission": 5
+  },
+  "target_heating_cooling_state": {
+    "type": "target_heating_cooling_state",
+    "description": "目标加热冷却状态",
+    "val_type": "int32",
+    "permission": 7
+  },
+  "target_position": {
+    "type": "target_position",
+    "description": "目标位置",
+    "val_type": "int32",
+    "permission": 7,
+    "min": 1,
+    "max": 100,
+    "unit": "%"
+  },
+  "target_state": {
+    "type": "target_state",
+    "description": "目标状态",
+    "val_type": "int32",
+    "permission": 7
+  },
+  "target_temperature": {
+    "type": "target_temperature",
+    "description": "目标温度",
+    "val_type": "float32",
+    "permission": 7,
+    "min": 5,
+    "max": 35,
+    "unit": "°C",
+    "precision": 1
+  },
+  "temperature": {
+    "type": "temperature",
+    "description": "温度",
+    "val_type": "float32",
+    "permission": 5,
+    "unit": "°C",
+    "precision": 1
+  },
+  "top_down_cruise": {
+    "type": "top_down_cruise",
+    "description": "摄像头云台上下巡航",
+    "val_type": "string",
+    "permission": 7
+  },
+  "type": {
+    "type": "type",
+    "description": "设备类型",
+    "val_type": "string",
+    "permission": 1
+  },
+  "version": {
+    "type": "version",
+    "description": "固件版本",
+    "val_type": "string",
+    "permission": 1
+  },
+  "voltage": {
+    "type": "voltage",
+    "description": "电压",
+    "val_type": "float32",
+    "permission": 5,
+    "min": 0,
+    "unit": "V",
+    "precision": 1
+  },
+  "volume": {
+    "type": "volume",
+    "description": "音量",
+    "val_type": "int32",
+    "permission": 7,
+    "unit": "%"
+  },
+  "webrtc_control": {
+    "type": "webrtc_control",
+    "description": "WebRTC控制",
+    "val_type": "string",
+    "permission": 19
+  }
+};
@@ -0,0 +1,73 @@
+package thingmodel
+
+import (
+	"fmt"
+)
+
+// Event 设备的无状态事件，如按键按下、报警，事件不保存在设备影子中
+type Event struct {
+	Type   string  `json:"type"` // 同一实例中唯一
+	Params []Param `json:"params,omitempty"`
+}
+
+func (e Event) String() string {
+	return e.Type
+}
+
+// CheckParams 校验事件参数，不接受未定义的参数，参数值的类型需与定义一致（不做转换），并符合范围及可选项
+func (e Event) CheckParams(params map[string]interface{}) error {
+	for name, val := range params {
+		var (
+			param Param
+			found bool
+		)
+		for _, p := range e.Params {
+			if p.Name == name {
+				param, found = p, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("event %s: unknown param %s", e.Type, name)
+		}
+		v, err := param.Validate(val)
+		if err != nil {
+			return fmt.Errorf("event %s: %s", e.Type, err)
+		}
+		if !ValEqual(v, val) {
+			return fmt.Errorf("event %s: %s: 值%v的类型不正确，应为%s", e.Type, name, val, param.ValType)
+		}
+	}
+	return nil
+}
+
+const (
+	PressTypeSingle = "single" // 单击
+	PressTypeDouble = "double" // 双击
+	PressTypeLong   = "long"   // 长按
+)
+
+// ButtonPress 按键按下
+var ButtonPress = Event{
+	Type: "button_press",
+	Params: []Param{
+		{
+			Name:    "press_type",
+			ValType: Enum,
+			Options: []Option{
+				{Name: "单击", Val: PressTypeSingle},
+				{Name: "双击", Val: PressTypeDouble},
+				{Name: "长按", Val: PressTypeLong},
+			},
+		},
+	},
+}
+
+// Alarm 报警，如门锁防撬、烟雾报警
+var Alarm = Event{
+	Type: "alarm",
+	Params: []Param{
+		{Name: "alarm_type", ValType: String},
+		{Name: "message", ValType: String},
+	},
+}
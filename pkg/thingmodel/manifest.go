@@ -0,0 +1,259 @@
+package thingmodel
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// ManifestVersion 物模型清单格式的版本，格式不兼容变化时递增
+const ManifestVersion = 1
+
+// Manifest 物模型清单，包含所有预定义的服务、属性、动作、事件及值类型、权限、单位，
+// 用于客户端发现物模型及生成类型常量
+type Manifest struct {
+	Version     int             `json:"version"`
+	Hash        string          `json:"hash"` // 内容的sha256，预定义内容变化时改变
+	ValTypes    []ValType       `json:"val_types"`
+	Permissions []PermissionDef `json:"permissions"`
+	Units       []Unit          `json:"units"`
+	Services    []ServiceDef    `json:"services"`
+	Attributes  []AttributeDef  `json:"attributes"`
+	Actions     []ActionDef     `json:"actions"`
+	Events      []EventDef      `json:"events"`
+}
+
+// PermissionDef 属性权限位
+type PermissionDef struct {
+	Name  string     `json:"name"`
+	Value Permission `json:"value"`
+}
+
+// ServiceDef 服务类型
+type ServiceDef struct {
+	Type        ServiceType `json:"type"`
+	Description string      `json:"description"`
+}
+
+// AttributeDef 预定义的属性，aid由插件为每个实例分配，不在清单中
+type AttributeDef struct {
+	Type        string      `json:"type"`
+	Description string      `json:"description"`
+	ValType     ValType     `json:"val_type"`
+	Permission  uint        `json:"permission"`
+	Min         interface{} `json:"min,omitempty"`
+	Max         interface{} `json:"max,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	Options     []Option    `json:"options,omitempty"`
+	Unit        Unit        `json:"unit,omitempty"`
+	Precision   *int        `json:"precision,omitempty"`
+}
+
+// ActionDef 预定义的动作
+type ActionDef struct {
+	Action
+	Description string `json:"description"`
+}
+
+// EventDef 预定义的事件
+type EventDef struct {
+	Event
+	Description string `json:"description"`
+}
+
+var valTypes = []ValType{Int, Int32, Int64, String, Bool, Float32, Float64, Enum, JSON}
+
+var permissions = []PermissionDef{
+	{"read", AttributePermissionRead},
+	{"write", AttributePermissionWrite},
+	{"notify", AttributePermissionNotify},
+	{"hidden", AttributePermissionHidden},
+	{"scene_hidden", AttributePermissionSceneHidden},
+}
+
+var units = []Unit{
+	UnitCelsius, UnitFahrenheit, UnitPercent, UnitLux, UnitKelvin,
+	UnitWatt, UnitKilowatt, UnitWattHour, UnitKilowattHour, UnitVolt, UnitAmpere,
+	UnitSecond, UnitMinute,
+	UnitMillimeter, UnitCentimeter, UnitMeter, UnitInch, UnitFoot,
+	UnitKilogram, UnitPound,
+	UnitHectopascal, UnitInchMercury, UnitPPM, UnitMicrogramPM3, UnitDecibel,
+}
+
+// services 所有服务类型，新增服务类型时需要添加到这里
+var services = []ServiceDef{
+	{InfoService, "详情"},
+	{GatewayService, "网关"},
+	{LightBulbService, "灯"},
+	{SwitchService, "开关"},
+	{OutletService, "插座"},
+	{CurtainService, "窗帘电机"},
+	{TemperatureSensor, "温度传感器"},
+	{HumiditySensor, "湿度传感器"},
+	{HeaterCooler, "加热器冷却器"},
+	{Lock, "门锁"},
+	{Door, "门"},
+	{Doorbell, "门铃"},
+	{MotionSensor, "人体传感器"},
+	{LeakSensor, "水浸传感器"},
+	{BatteryService, "电池"},
+	{SecuritySystem, "安全系统"},
+	{StateLessSwitch, "无线开关"},
+	{ContactSensor, "接触式传感器"},
+	{Speaker, "扬声器"},
+	{Microphone, "麦克风"},
+	{LightSensor, "光传感器"},
+	{CameraRTPStreamManagement, "流管理"},
+	{OperatingMode, "工作模式"},
+	{MediaNegotiation, "webrtc媒体交换"},
+	{PTZ, "摄像头云台控制"},
+	{Media, "摄像头视频配置"},
+	{EnergyMeter, "电能计量"},
+	{Thermostat, "温控器"},
+	{MediaPlayer, "媒体播放器"},
+	{Television, "电视"},
+}
+
+// attributes 所有预定义的属性，新增属性时需要添加到这里
+var attributes = []struct {
+	Attribute
+	description string
+}{
+	{SubType, "子设备类型"},
+	{Volume, "音量"},
+	{OnOff, "开关"},
+	{Brightness, "亮度"},
+	{ColorTemperature, "色温"},
+	{RGB, "RGB"},
+	{Model, "型号"},
+	{Manufacturer, "厂商"},
+	{Identify, "唯一标识"},
+	{Version, "固件版本"},
+	{Name, "设备名称"},
+	{Type, "设备类型"},
+	{CurrentPosition, "当前位置"},
+	{TargetPosition, "目标位置"},
+	{State, "状态"},
+	{Direction, "方向"},
+	{Humidity, "湿度"},
+	{Temperature, "温度"},
+	{LeakDetected, "泄漏检测"},
+	{SwitchEvent, "开关事件"},
+	{TargetState, "目标状态"},
+	{CurrentState, "当前状态"},
+	{MotionDetected, "移动检测"},
+	{Battery, "电池"},
+	{LockCurrentState, "锁当前状态"},
+	{LockTargetState, "锁目标状态"},
+	{LockEvent, "锁事件"},
+	{LockNotification, "锁通知"},
+	{Logs, "日志"},
+	{Active, "活动状态"},
+	{CurrentTemperature, "当前温度"},
+	{CurrentHeatingCoolingState, "当前加热冷却状态"},
+	{TargetHeatingCoolingState, "目标加热冷却状态"},
+	{HeatingThresholdTemperature, "加热阈值温度"},
+	{CoolingThresholdTemperature, "冷却阈值温度"},
+	{RotationSpeed, "转速"},
+	{SwingMode, "摆动模式"},
+	{PermitJoin, "是否允许加入"},
+	{Alert, "告警"},
+	{StatusLowBattery, "低电量状态"},
+	{ContactSensorState, "触点传感器状态"},
+	{Mute, "静音"},
+	{CurrentAmbientLightLevel, "环境光照度"},
+	{Power, "功率"},
+	{Voltage, "电压"},
+	{ElectricCurrent, "电流"},
+	{Energy, "累计用电量"},
+	{TargetTemperature, "目标温度"},
+	{HVACMode, "温控器工作模式"},
+	{HVACAction, "温控器当前动作"},
+	{FanMode, "风速模式"},
+	{Hysteresis, "温控回差"},
+	{PlaybackState, "播放状态"},
+	{InputSource, "输入源"},
+	{MediaTitle, "媒体标题"},
+	{MediaArtist, "媒体艺术家"},
+	{MediaAlbum, "媒体专辑"},
+	{MediaImageURL, "媒体封面"},
+	{MediaDuration, "媒体时长"},
+	{MediaPosition, "播放进度"},
+	{NightVision, "夜视灯"},
+	{ModeIndicator, "模式指示灯"},
+	{WebRtcControl, "WebRTC控制"},
+	{Answer, "WebRTC应答"},
+	{StreamingStatus, "流状态"},
+	{PTZMove, "摄像头云台持续移动"},
+	{MediaResolutionOptions, "摄像头分辨率可设属性"},
+	{MediaResolution, "摄像头分辨率属性"},
+	{MediaFrameRateLimit, "摄像头帧率"},
+	{MediaBitRateLimit, "摄像头码率"},
+	{MediaEncodingInterval, "摄像头编码区间"},
+	{MediaQuality, "摄像头视频质量"},
+	{MediaGovLength, "摄像头I帧间隔长度"},
+	{PTZTDCruise, "摄像头云台上下巡航"},
+	{PTZLRCruise, "摄像头云台左右巡航"},
+	{SelectItems, "场景选择项"},
+}
+
+var actions = []ActionDef{
+	{UnlockWithPIN, "使用密码开锁"},
+	{PTZGotoPreset, "云台转到预置位"},
+	{IdentifyBlink, "设备闪烁"},
+	{MediaPlay, "播放"},
+	{MediaPause, "暂停"},
+	{MediaStop, "停止"},
+	{MediaNext, "下一个"},
+	{MediaPrevious, "上一个"},
+	{MediaSeek, "跳转播放进度"},
+}
+
+var events = []EventDef{
+	{ButtonPress, "按键按下"},
+	{Alarm, "报警"},
+}
+
+var (
+	manifest     Manifest
+	manifestOnce sync.Once
+)
+
+// GetManifest 获取物模型清单
+func GetManifest() Manifest {
+	manifestOnce.Do(func() {
+		manifest = buildManifest()
+	})
+	return manifest
+}
+
+func buildManifest() Manifest {
+	m := Manifest{
+		Version:     ManifestVersion,
+		ValTypes:    valTypes,
+		Permissions: permissions,
+		Units:       units,
+		Services:    services,
+		Actions:     actions,
+		Events:      events,
+	}
+	for _, a := range attributes {
+		m.Attributes = append(m.Attributes, AttributeDef{
+			Type:        a.Type,
+			Description: a.description,
+			ValType:     a.ValType,
+			Permission:  a.Permission,
+			Min:         a.Min,
+			Max:         a.Max,
+			Default:     a.Default,
+			Options:     a.Options,
+			Unit:        a.Unit,
+			Precision:   a.Precision,
+		})
+	}
+	data, _ := json.Marshal(m)
+	sum := sha256.Sum256(data)
+	m.Hash = hex.EncodeToString(sum[:])
+	return m
+}
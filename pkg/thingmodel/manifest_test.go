@@ -0,0 +1,75 @@
+package thingmodel
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// declaredNames 解析包源码，返回类型为typeName的包级变量或常量名
+func declaredNames(t *testing.T, typeName string) map[string]bool {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", nil, 0)
+	if !assert.NoError(t, err) {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, f := range pkgs["thingmodel"].Files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || (gd.Tok != token.VAR && gd.Tok != token.CONST) {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if !name.IsExported() {
+						continue
+					}
+					if ident, ok := vs.Type.(*ast.Ident); ok && ident.Name == typeName {
+						names[name.Name] = true
+						continue
+					}
+					if i < len(vs.Values) {
+						if cl, ok := vs.Values[i].(*ast.CompositeLit); ok {
+							if ident, ok := cl.Type.(*ast.Ident); ok && ident.Name == typeName {
+								names[name.Name] = true
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// 清单需要包含所有预定义的属性、动作、事件及服务类型
+func TestManifestComplete(t *testing.T) {
+	m := GetManifest()
+
+	attrTypes := make(map[string]bool)
+	for _, a := range m.Attributes {
+		assert.False(t, attrTypes[a.Type], "duplicate attribute %s", a.Type)
+		attrTypes[a.Type] = true
+	}
+	assert.Equal(t, len(declaredNames(t, "Attribute")), len(m.Attributes), "attribute missing in manifest")
+
+	serviceTypes := make(map[ServiceType]bool)
+	for _, s := range m.Services {
+		serviceTypes[s.Type] = true
+	}
+	assert.Equal(t, len(declaredNames(t, "ServiceType")), len(serviceTypes), "service type missing in manifest")
+	assert.Equal(t, len(declaredNames(t, "Action")), len(m.Actions), "action missing in manifest")
+	assert.Equal(t, len(declaredNames(t, "Event")), len(m.Events), "event missing in manifest")
+	assert.Equal(t, len(declaredNames(t, "Unit")), len(m.Units), "unit missing in manifest")
+}
+
+func TestManifestHash(t *testing.T) {
+	m := GetManifest()
+	assert.Len(t, m.Hash, 64)
+	assert.Equal(t, m.Hash, buildManifest().Hash)
+}
@@ -0,0 +1,111 @@
+package thingmodel
+
+// 播放状态
+const (
+	PlaybackStatePlaying   = "playing"   // 播放中
+	PlaybackStatePaused    = "paused"    // 已暂停
+	PlaybackStateStopped   = "stopped"   // 已停止
+	PlaybackStateIdle      = "idle"      // 空闲，没有媒体
+	PlaybackStateBuffering = "buffering" // 缓冲中
+)
+
+// PlaybackState 播放状态，通过 MediaPlay、MediaPause 等动作控制
+var PlaybackState = Attribute{
+	Type:    "playback_state",
+	ValType: Enum,
+	Default: PlaybackStateIdle,
+	Options: []Option{
+		{Name: "播放中", Val: PlaybackStatePlaying},
+		{Name: "已暂停", Val: PlaybackStatePaused},
+		{Name: "已停止", Val: PlaybackStateStopped},
+		{Name: "空闲", Val: PlaybackStateIdle},
+		{Name: "缓冲中", Val: PlaybackStateBuffering},
+	},
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+	),
+}
+
+// InputSource 输入源，如HDMI1、电视、蓝牙，插件通过 SetOptions 设置设备支持的输入源
+var InputSource = Attribute{
+	Type:    "input_source",
+	ValType: Enum,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionWrite,
+		AttributePermissionNotify,
+	),
+}
+
+// 当前媒体的信息，只用于展示，不作为场景的触发条件
+
+// MediaTitle 当前媒体的标题
+var MediaTitle = Attribute{
+	Type:    "media_title",
+	ValType: String,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+		AttributePermissionSceneHidden,
+	),
+}
+
+// MediaArtist 当前媒体的艺术家
+var MediaArtist = Attribute{
+	Type:    "media_artist",
+	ValType: String,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+		AttributePermissionSceneHidden,
+	),
+}
+
+// MediaAlbum 当前媒体的专辑
+var MediaAlbum = Attribute{
+	Type:    "media_album",
+	ValType: String,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+		AttributePermissionSceneHidden,
+	),
+}
+
+// MediaImageURL 当前媒体的封面地址
+var MediaImageURL = Attribute{
+	Type:    "media_image_url",
+	ValType: String,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+		AttributePermissionSceneHidden,
+	),
+}
+
+// MediaDuration 当前媒体的总时长
+var MediaDuration = Attribute{
+	Type:    "media_duration",
+	ValType: Int,
+	Min:     0,
+	Unit:    UnitSecond,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+		AttributePermissionSceneHidden,
+	),
+}
+
+// MediaPosition 当前播放进度，插件只在播放状态变化或跳转时上报，播放中的进度由客户端推算
+var MediaPosition = Attribute{
+	Type:    "media_position",
+	ValType: Int,
+	Min:     0,
+	Unit:    UnitSecond,
+	Permission: SetPermissions(
+		AttributePermissionRead,
+		AttributePermissionNotify,
+		AttributePermissionSceneHidden,
+	),
+}
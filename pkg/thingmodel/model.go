@@ -43,6 +43,24 @@ func (das ThingModel) GetAttribute(iid string, aid int) (Attribute, error) {
 	return ins.GetAttribute(aid)
 }
 
+func (das ThingModel) GetAction(iid string, actionType string) (Action, error) {
+
+	ins, err := das.GetInstance(iid)
+	if err != nil {
+		return Action{}, err
+	}
+	return ins.GetAction(actionType)
+}
+
+func (das ThingModel) GetEvent(iid string, eventType string) (Event, error) {
+
+	ins, err := das.GetInstance(iid)
+	if err != nil {
+		return Event{}, err
+	}
+	return ins.GetEvent(eventType)
+}
+
 func (das ThingModel) GetInstance(iid string) (Instance, error) {
 
 	for _, ins := range das.Instances {
@@ -158,3 +176,35 @@ func (i Instance) GetAttribute(aid int) (Attribute, error) {
 	}
 	return Attribute{}, fmt.Errorf("attribute %d not found", aid)
 }
+
+// GetService 获取实例中指定类型的服务
+func (i Instance) GetService(serviceType ServiceType) (Service, bool) {
+	for _, s := range i.Services {
+		if s.Type == serviceType {
+			return s, true
+		}
+	}
+	return Service{}, false
+}
+
+func (i Instance) GetAction(actionType string) (Action, error) {
+	for _, s := range i.Services {
+		for _, a := range s.Actions {
+			if a.Type == actionType {
+				return a, nil
+			}
+		}
+	}
+	return Action{}, fmt.Errorf("action %s not found", actionType)
+}
+
+func (i Instance) GetEvent(eventType string) (Event, error) {
+	for _, s := range i.Services {
+		for _, e := range s.Events {
+			if e.Type == eventType {
+				return e, nil
+			}
+		}
+	}
+	return Event{}, fmt.Errorf("event %s not found", eventType)
+}
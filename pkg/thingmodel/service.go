@@ -29,9 +29,25 @@ const (
 	MediaNegotiation          ServiceType = "media_negotiation"            // webrtc媒体交换
 	PTZ                       ServiceType = "ptz"                          // PTZ 摄像头云台控制功能
 	Media                     ServiceType = "media"                        // Media 摄像头视频相关配置
+	EnergyMeter               ServiceType = "energy_meter"                 // 电能计量
+	Thermostat                ServiceType = "thermostat"                   // 温控器
+	MediaPlayer               ServiceType = "media_player"                 // 媒体播放器
+	Television                ServiceType = "television"                   // 电视
 )
 
 type Service struct {
 	Type       ServiceType `json:"type"`
 	Attributes []Attribute `json:"attributes"`
+	Actions    []Action    `json:"actions,omitempty"`
+	Events     []Event     `json:"events,omitempty"`
+}
+
+// GetAttribute 获取服务中指定类型的属性
+func (s Service) GetAttribute(attrType string) (Attribute, bool) {
+	for _, a := range s.Attributes {
+		if a.Type == attrType {
+			return a, true
+		}
+	}
+	return Attribute{}, false
 }
@@ -0,0 +1,214 @@
+package thingmodel
+
+import (
+	"math"
+)
+
+// Unit 属性值的单位，使用通用的单位符号，客户端可直接显示
+type Unit string
+
+const (
+	UnitCelsius    Unit = "°C"
+	UnitFahrenheit Unit = "°F"
+	UnitPercent    Unit = "%"
+	UnitLux        Unit = "lx"
+	UnitKelvin     Unit = "K" // 用于色温
+
+	UnitWatt         Unit = "W"
+	UnitKilowatt     Unit = "kW"
+	UnitWattHour     Unit = "Wh"
+	UnitKilowattHour Unit = "kWh"
+	UnitVolt         Unit = "V"
+	UnitAmpere       Unit = "A"
+
+	UnitSecond Unit = "s"
+	UnitMinute Unit = "min"
+
+	UnitMillimeter Unit = "mm"
+	UnitCentimeter Unit = "cm"
+	UnitMeter      Unit = "m"
+	UnitInch       Unit = "in"
+	UnitFoot       Unit = "ft"
+
+	UnitKilogram Unit = "kg"
+	UnitPound    Unit = "lb"
+
+	UnitHectopascal  Unit = "hPa"
+	UnitInchMercury  Unit = "inHg"
+	UnitPPM          Unit = "ppm"
+	UnitMicrogramPM3 Unit = "µg/m³"
+	UnitDecibel      Unit = "dB"
+)
+
+// UnitSystem 单位制，用户可以选择读取属性时使用的单位制
+type UnitSystem string
+
+const (
+	UnitSystemMetric   UnitSystem = "metric"   // 公制，默认
+	UnitSystemImperial UnitSystem = "imperial" // 英制
+)
+
+// ValidUnitSystem 是否支持的单位制
+func ValidUnitSystem(s UnitSystem) bool {
+	return s == UnitSystemMetric || s == UnitSystemImperial
+}
+
+// 各单位制下与之对应的单位，未列出的单位在该单位制下不转换
+var unitSystemUnits = map[UnitSystem]map[Unit]Unit{
+	UnitSystemMetric: {
+		UnitFahrenheit:  UnitCelsius,
+		UnitInch:        UnitCentimeter,
+		UnitFoot:        UnitMeter,
+		UnitPound:       UnitKilogram,
+		UnitInchMercury: UnitHectopascal,
+	},
+	UnitSystemImperial: {
+		UnitCelsius:     UnitFahrenheit,
+		UnitMillimeter:  UnitInch,
+		UnitCentimeter:  UnitInch,
+		UnitMeter:       UnitFoot,
+		UnitKilogram:    UnitPound,
+		UnitHectopascal: UnitInchMercury,
+	},
+}
+
+// Unit 返回单位u在该单位制下对应的单位
+func (s UnitSystem) Unit(u Unit) Unit {
+	if s == "" {
+		s = UnitSystemMetric
+	}
+	if target, ok := unitSystemUnits[s][u]; ok {
+		return target
+	}
+	return u
+}
+
+// 同一物理量的单位换算为基准单位的系数，温度单独处理
+var unitFactors = []map[Unit]float64{
+	{UnitWatt: 1, UnitKilowatt: 1000},
+	{UnitWattHour: 1, UnitKilowattHour: 1000},
+	{UnitSecond: 1, UnitMinute: 60},
+	{UnitMillimeter: 0.001, UnitCentimeter: 0.01, UnitMeter: 1, UnitInch: 0.0254, UnitFoot: 0.3048},
+	{UnitKilogram: 1, UnitPound: 0.45359237},
+	{UnitHectopascal: 1, UnitInchMercury: 33.8638866667},
+}
+
+// Convert 将数值从from单位换算为to单位，单位不同且无法换算时返回false
+func Convert(val float64, from, to Unit) (float64, bool) {
+	if from == to {
+		return val, true
+	}
+	switch {
+	case from == UnitCelsius && to == UnitFahrenheit:
+		return val*9/5 + 32, true
+	case from == UnitFahrenheit && to == UnitCelsius:
+		return (val - 32) * 5 / 9, true
+	}
+	for _, factors := range unitFactors {
+		f, ok1 := factors[from]
+		t, ok2 := factors[to]
+		if ok1 && ok2 {
+			return val * f / t, true
+		}
+	}
+	return val, false
+}
+
+// Round 按精度（小数位数）四舍五入
+func Round(val float64, precision int) float64 {
+	p := math.Pow10(precision)
+	return math.Round(val*p) / p
+}
+
+// defaultPrecision 未设置精度的浮点数换算单位后保留的小数位数
+const defaultPrecision = 2
+
+// convertVal 将值从from单位换算为to单位，并按属性的值类型及精度取整
+func (t Attribute) convertVal(val interface{}, from, to Unit) (interface{}, bool) {
+	f, ok := toFloat64(val)
+	if !ok {
+		return val, false
+	}
+	f, ok = Convert(f, from, to)
+	if !ok {
+		return val, false
+	}
+	switch t.ValType {
+	case Int, Int32, Int64:
+		return int(math.Round(f)), true
+	}
+	precision := defaultPrecision
+	if t.Precision != nil {
+		precision = *t.Precision
+	}
+	return Round(f, precision), true
+}
+
+// ConvertUnit 将属性的值、默认值及范围换算为to单位，无法换算时返回原属性
+func (t Attribute) ConvertUnit(to Unit) Attribute {
+	if t.Unit == "" || to == "" || t.Unit == to {
+		return t
+	}
+	if _, ok := Convert(0, t.Unit, to); !ok {
+		return t
+	}
+	from := t.Unit
+	for _, v := range []*interface{}{&t.Val, &t.Default, &t.Min, &t.Max} {
+		if *v != nil {
+			*v, _ = t.convertVal(*v, from, to)
+		}
+	}
+	t.Unit = to
+	return t
+}
+
+// InSystem 将属性换算为单位制s下的单位
+func (t Attribute) InSystem(s UnitSystem) Attribute {
+	return t.ConvertUnit(s.Unit(t.Unit))
+}
+
+// ValFrom 将单位为from的值换算为属性的单位，用于将用户设置的值转换为设备的单位
+func (t Attribute) ValFrom(val interface{}, from Unit) interface{} {
+	if t.Unit == "" || from == "" || from == t.Unit {
+		return val
+	}
+	v, _ := t.convertVal(val, from, t.Unit)
+	return v
+}
+
+// InSystem 将物模型所有属性换算为单位制s下的单位
+func (das ThingModel) InSystem(s UnitSystem) ThingModel {
+	instances := make([]Instance, len(das.Instances))
+	for i, ins := range das.Instances {
+		instances[i] = ins
+		instances[i].Services = make([]Service, len(ins.Services))
+		for j, srv := range ins.Services {
+			instances[i].Services[j] = srv
+			attrs := make([]Attribute, len(srv.Attributes))
+			for k, attr := range srv.Attributes {
+				attrs[k] = attr.InSystem(s)
+			}
+			instances[i].Services[j].Attributes = attrs
+		}
+	}
+	das.Instances = instances
+	return das
+}
+
+// ValFrom 将单位为from的值换算为属性的单位，属性不存在时返回原值
+func (das ThingModel) ValFrom(iid string, aid int, val interface{}, from Unit) interface{} {
+	attr, err := das.GetAttribute(iid, aid)
+	if err != nil {
+		return val
+	}
+	return attr.ValFrom(val, from)
+}
+
+// ValFromSystem 将单位制s下的值换算为属性的单位，属性不存在时返回原值
+func (das ThingModel) ValFromSystem(iid string, aid int, val interface{}, s UnitSystem) interface{} {
+	attr, err := das.GetAttribute(iid, aid)
+	if err != nil {
+		return val
+	}
+	return attr.ValFrom(val, s.Unit(attr.Unit))
+}
@@ -0,0 +1,68 @@
+package thingmodel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		val      float64
+		from, to Unit
+		want     float64
+		ok       bool
+	}{
+		{25, UnitCelsius, UnitFahrenheit, 77, true},
+		{212, UnitFahrenheit, UnitCelsius, 100, true},
+		{1500, UnitWatt, UnitKilowatt, 1.5, true},
+		{1, UnitFoot, UnitInch, 12, true},
+		{50, UnitPercent, UnitPercent, 50, true},
+		{50, UnitPercent, UnitLux, 50, false},
+	}
+	for _, tt := range tests {
+		v, ok := Convert(tt.val, tt.from, tt.to)
+		assert.Equal(t, tt.ok, ok, "%v %s->%s", tt.val, tt.from, tt.to)
+		assert.InDelta(t, tt.want, v, 1e-9, "%v %s->%s", tt.val, tt.from, tt.to)
+	}
+}
+
+func TestAttributeInSystem(t *testing.T) {
+	temp := Temperature
+	temp.Val = 21.5
+	f := temp.InSystem(UnitSystemImperial)
+	assert.Equal(t, UnitFahrenheit, f.Unit)
+	assert.Equal(t, 70.7, f.Val)
+	// 原属性不变
+	assert.Equal(t, UnitCelsius, temp.Unit)
+	assert.Equal(t, temp, temp.InSystem(UnitSystemMetric))
+
+	threshold := HeatingThresholdTemperature
+	threshold.Val, threshold.Min, threshold.Max = 20, 10, 30
+	f = threshold.InSystem(UnitSystemImperial)
+	assert.Equal(t, 68, f.Val)
+	assert.Equal(t, 50, f.Min)
+	assert.Equal(t, 86, f.Max)
+
+	brightness := Brightness
+	brightness.Val = 50
+	assert.Equal(t, brightness, brightness.InSystem(UnitSystemImperial))
+}
+
+func TestAttributeValFrom(t *testing.T) {
+	threshold := HeatingThresholdTemperature
+	assert.Equal(t, 20, threshold.ValFrom(float64(68), UnitFahrenheit))
+	assert.Equal(t, float64(68), threshold.ValFrom(float64(68), ""))
+	assert.Equal(t, "on", threshold.ValFrom("on", UnitFahrenheit))
+
+	tm := ThingModel{Instances: []Instance{{IID: "1", Services: []Service{{
+		Type:       "heater",
+		Attributes: []Attribute{{AID: 1, Type: threshold.Type, ValType: Int32, Unit: UnitCelsius}},
+	}}}}}
+	assert.Equal(t, 25, tm.ValFromSystem("1", 1, float64(77), UnitSystemImperial))
+	assert.Equal(t, float64(25), tm.ValFromSystem("1", 1, float64(25), UnitSystemMetric))
+
+	imperial := tm.InSystem(UnitSystemImperial)
+	assert.Equal(t, UnitFahrenheit, imperial.Instances[0].Services[0].Attributes[0].Unit)
+	assert.Equal(t, UnitCelsius, tm.Instances[0].Services[0].Attributes[0].Unit)
+}
@@ -0,0 +1,295 @@
+package thingmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// FieldErrorCode 属性值校验失败的类型
+type FieldErrorCode string
+
+const (
+	FieldErrNotFound    FieldErrorCode = "not_found"    // 属性不存在
+	FieldErrNotWritable FieldErrorCode = "not_writable" // 属性不可写
+	FieldErrType        FieldErrorCode = "invalid_type" // 值的类型不正确且无法转换
+	FieldErrRange       FieldErrorCode = "out_of_range" // 值超出范围
+	FieldErrOption      FieldErrorCode = "not_option"   // 值不在可选项中
+)
+
+// FieldError 属性值校验失败的详情
+type FieldError struct {
+	IID    string         `json:"iid"`
+	AID    int            `json:"aid"`
+	Type   string         `json:"type,omitempty"` // 属性类型
+	Code   FieldErrorCode `json:"code"`
+	Reason string         `json:"reason"`
+}
+
+func (e FieldError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("%s.%d: %s", e.IID, e.AID, e.Reason)
+	}
+	return fmt.Sprintf("%s.%d(%s): %s", e.IID, e.AID, e.Type, e.Reason)
+}
+
+// FieldErrors 多个属性值的校验错误
+type FieldErrors []FieldError
+
+func (es FieldErrors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ValidateWrite 按物模型校验写入的属性值，返回按val_type转换后的值
+func (das ThingModel) ValidateWrite(iid string, aid int, val interface{}) (interface{}, error) {
+	attr, err := das.GetAttribute(iid, aid)
+	if err != nil {
+		return nil, FieldError{IID: iid, AID: aid, Code: FieldErrNotFound, Reason: "属性不存在"}
+	}
+	v, err := attr.ValidateWrite(val)
+	if fe, ok := err.(FieldError); ok {
+		fe.IID = iid
+		return nil, fe
+	}
+	return v, err
+}
+
+// ValidateWrite 校验属性是否可写以及值的类型、范围、可选项，
+// 返回转换后的值，如字符串"true"转换为bool、整数的浮点数转换为int
+func (t Attribute) ValidateWrite(val interface{}) (interface{}, error) {
+	fieldErr := func(code FieldErrorCode, format string, args ...interface{}) error {
+		return FieldError{AID: t.AID, Type: t.Type, Code: code, Reason: fmt.Sprintf(format, args...)}
+	}
+	if !t.PermissionWrite() {
+		return nil, fieldErr(FieldErrNotWritable, "属性不可写")
+	}
+
+	v, code, reason := checkVal(t.ValType, t.Min, t.Max, t.Options, val)
+	if code != "" {
+		return nil, fieldErr(code, "%s", reason)
+	}
+
+	if t.Type == SelectItems.Type {
+		if err := t.checkSelect(v); err != nil {
+			return nil, fieldErr(FieldErrOption, "%s", err)
+		}
+	}
+	return v, nil
+}
+
+// checkVal 校验值的类型、范围、可选项，返回转换后的值；校验失败时返回错误类型及原因
+func checkVal(valType ValType, min, max interface{}, options []Option, val interface{}) (interface{}, FieldErrorCode, string) {
+	v, ok := coerceVal(valType, val)
+	if !ok {
+		return nil, FieldErrType, fmt.Sprintf("值%v的类型不正确，应为%s", val, valType)
+	}
+
+	switch valType {
+	case Int, Int32, Int64, Float32, Float64:
+		f, _ := toFloat64(v)
+		if min, ok := toFloat64(min); ok && f < min {
+			return nil, FieldErrRange, fmt.Sprintf("值%v小于最小值%v", v, min)
+		}
+		if max, ok := toFloat64(max); ok && f > max {
+			return nil, FieldErrRange, fmt.Sprintf("值%v大于最大值%v", v, max)
+		}
+	}
+
+	if len(options) != 0 {
+		opt, ok := matchOption(options, v)
+		if !ok {
+			return nil, FieldErrOption, fmt.Sprintf("值%v不在可选项中", v)
+		}
+		v = opt
+	}
+	return v, "", ""
+}
+
+// checkSelect 校验选择的id是否在当前可选列表中，当前值无法解析时不校验
+func (t Attribute) checkSelect(val interface{}) error {
+	target, err := toSelect(val)
+	if err != nil {
+		return fmt.Errorf("值%v的格式不正确", val)
+	}
+	if target.ID == nil {
+		return nil
+	}
+	current, err := toSelect(t.Val)
+	if err != nil || len(current.Items) == 0 {
+		return nil
+	}
+	for _, item := range current.Items {
+		if item.ID != nil && *item.ID == *target.ID {
+			return nil
+		}
+	}
+	return fmt.Errorf("选项%d不存在", *target.ID)
+}
+
+func toSelect(val interface{}) (s Select, err error) {
+	switch v := val.(type) {
+	case nil:
+		return
+	case string:
+		if v == "" {
+			return
+		}
+		return SelectUnmarshal([]byte(v))
+	case []byte:
+		return SelectUnmarshal(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return s, err
+		}
+		return SelectUnmarshal(data)
+	}
+}
+
+// coerceVal 将值转换为valType对应的类型，无法转换时返回false
+func coerceVal(valType ValType, val interface{}) (interface{}, bool) {
+	switch valType {
+	case Int, Int32, Int64:
+		f, ok := toFloat64(val)
+		if !ok || f != math.Trunc(f) {
+			return nil, false
+		}
+		if valType == Int32 && (f < math.MinInt32 || f > math.MaxInt32) {
+			return nil, false
+		}
+		if f < math.MinInt64 || f > math.MaxInt64 {
+			return nil, false
+		}
+		return int(f), true
+	case Float32, Float64:
+		f, ok := toFloat64(val)
+		if !ok {
+			return nil, false
+		}
+		return f, true
+	case Bool:
+		return toBool(val)
+	case String:
+		s, ok := val.(string)
+		return s, ok
+	case Enum:
+		switch v := val.(type) {
+		case string:
+			return v, true
+		default:
+			f, ok := toFloat64(v)
+			if !ok {
+				return nil, false
+			}
+			if f == math.Trunc(f) {
+				return int(f), true
+			}
+			return f, true
+		}
+	default:
+		if val == nil {
+			return nil, false
+		}
+		return val, true
+	}
+}
+
+func toFloat64(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v) && !math.IsInf(v, 0)
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil && !math.IsNaN(f) && !math.IsInf(f, 0)
+	}
+	return 0, false
+}
+
+func toBool(val interface{}) (interface{}, bool) {
+	switch v := val.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "1", "on":
+			return true, true
+		case "false", "0", "off":
+			return false, true
+		}
+		return nil, false
+	}
+	if f, ok := toFloat64(val); ok && (f == 0 || f == 1) {
+		return f == 1, true
+	}
+	return nil, false
+}
+
+// matchOption 返回与值相等的可选项的值，数字按数值比较
+func matchOption(options []Option, val interface{}) (interface{}, bool) {
+	for _, opt := range options {
+		if optionEqual(opt.Val, val) {
+			return opt.Val, true
+		}
+	}
+	return nil, false
+}
+
+func optionEqual(a, b interface{}) bool {
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return ok && sa == sb
+	}
+	fa, ok := toFloat64(a)
+	if !ok {
+		return reflect.DeepEqual(a, b)
+	}
+	fb, ok := toFloat64(b)
+	return ok && fa == fb
+}
+
+// ValEqual 比较两个值是否相等，数字按数值比较（不转换字符串），对象、数组等深度比较
+func ValEqual(a, b interface{}) bool {
+	_, aStr := a.(string)
+	_, bStr := b.(string)
+	if aStr || bStr {
+		return aStr && bStr && a.(string) == b.(string)
+	}
+	fa, ok := toFloat64(a)
+	if !ok {
+		return reflect.DeepEqual(a, b)
+	}
+	fb, ok := toFloat64(b)
+	return ok && fa == fb
+}
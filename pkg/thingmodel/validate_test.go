@@ -0,0 +1,147 @@
+package thingmodel
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttributeValidateWrite(t *testing.T) {
+	rw := SetPermissions(AttributePermissionRead, AttributePermissionWrite)
+	brightness := Attribute{AID: 1, Type: "brightness", Permission: rw, ValType: Int, Min: 1, Max: 100}
+	power := Attribute{AID: 2, Type: "power", Permission: rw, ValType: Bool}
+	mode := Attribute{AID: 3, Type: "mode", Permission: rw, ValType: Enum,
+		Options: []Option{{Name: "auto", Val: 0}, {Name: "cool", Val: 1}}}
+	model := Attribute{AID: 4, Type: "model", Permission: SetPermissions(AttributePermissionRead), ValType: String}
+
+	tests := []struct {
+		attr Attribute
+		val  interface{}
+		want interface{}
+		code FieldErrorCode
+	}{
+		{brightness, float64(50), 50, ""},
+		{brightness, "50", 50, ""},
+		{brightness, 50.5, nil, FieldErrType},
+		{brightness, float64(0), nil, FieldErrRange},
+		{brightness, 101, nil, FieldErrRange},
+		{brightness, "bright", nil, FieldErrType},
+		{power, "true", true, ""},
+		{power, float64(0), false, ""},
+		{power, "yes", nil, FieldErrType},
+		{mode, float64(1), 1, ""},
+		{mode, "1", 1, ""},
+		{mode, float64(2), nil, FieldErrOption},
+		{model, "m1", nil, FieldErrNotWritable},
+	}
+	for _, tt := range tests {
+		v, err := tt.attr.ValidateWrite(tt.val)
+		if tt.code == "" {
+			assert.NoError(t, err, "%s %v", tt.attr.Type, tt.val)
+			assert.Equal(t, tt.want, v, "%s %v", tt.attr.Type, tt.val)
+			continue
+		}
+		if assert.IsType(t, FieldError{}, err, "%s %v", tt.attr.Type, tt.val) {
+			assert.Equal(t, tt.code, err.(FieldError).Code, "%s %v", tt.attr.Type, tt.val)
+		}
+	}
+}
+
+func TestAttributeValidateSelect(t *testing.T) {
+	s := NewSelectAttr()
+	var one, two int64 = 1, 2
+	s.Add(SelectItem{ID: &one, Name: "one"})
+	current, _ := s.Marshal()
+	attr := SelectItems
+	attr.Val = current
+
+	_, err := attr.ValidateWrite(map[string]interface{}{"id": 1})
+	assert.NoError(t, err)
+	_, err = attr.ValidateWrite(Select{ID: &two})
+	if assert.IsType(t, FieldError{}, err) {
+		assert.Equal(t, FieldErrOption, err.(FieldError).Code)
+	}
+}
+
+func TestThingModelValidateWrite(t *testing.T) {
+	tm := ThingModel{Instances: []Instance{{IID: "1", Services: []Service{{
+		Type: "light",
+		Attributes: []Attribute{
+			{AID: 1, Type: "on_off", Permission: SetPermissions(AttributePermissionWrite), ValType: String},
+		},
+	}}}}}
+
+	v, err := tm.ValidateWrite("1", 1, "on")
+	assert.NoError(t, err)
+	assert.Equal(t, "on", v)
+
+	_, err = tm.ValidateWrite("1", 2, "on")
+	if assert.IsType(t, FieldError{}, err) {
+		assert.Equal(t, FieldErrNotFound, err.(FieldError).Code)
+	}
+	_, err = tm.ValidateWrite("1", 1, 1)
+	if assert.IsType(t, FieldError{}, err) {
+		assert.Equal(t, "1", err.(FieldError).IID)
+		assert.Equal(t, FieldErrType, err.(FieldError).Code)
+	}
+}
+
+func TestActionValidateInputs(t *testing.T) {
+	inputs, err := MediaSeek.ValidateInputs(map[string]interface{}{"position": float64(30)})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"position": 30}, inputs)
+	inputs, err = MediaSeek.ValidateInputs(map[string]interface{}{"position": "30"})
+	assert.NoError(t, err)
+	assert.Equal(t, 30, inputs["position"])
+
+	invalid := []map[string]interface{}{
+		nil,
+		{"position": float64(-5)},
+		{"position": "abc"},
+		{"position": 1.5},
+		{"position": float64(1), "speed": 2},
+	}
+	for _, in := range invalid {
+		assert.Error(t, MediaSeek.CheckInputs(in), "%v", in)
+	}
+
+	// 范围及可选项
+	assert.Error(t, PTZGotoPreset.CheckInputs(map[string]interface{}{"preset": 256}))
+	assert.NoError(t, PTZGotoPreset.CheckInputs(map[string]interface{}{"preset": 255}))
+	assert.Error(t, UnlockWithPIN.CheckInputs(map[string]interface{}{"pin": 1234}))
+	mode := Action{Type: "set_mode", Inputs: []Param{
+		{Name: "mode", ValType: Enum, Required: true, Options: []Option{{Name: "auto", Val: "auto"}}},
+	}}
+	assert.NoError(t, mode.CheckInputs(map[string]interface{}{"mode": "auto"}))
+	assert.Error(t, mode.CheckInputs(map[string]interface{}{"mode": "turbo"}))
+
+	// 没有参数的动作
+	inputs, err = MediaPlay.ValidateInputs(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, inputs)
+}
+
+func TestEventCheckParams(t *testing.T) {
+	assert.NoError(t, ButtonPress.CheckParams(map[string]interface{}{"press_type": PressTypeDouble}))
+	assert.NoError(t, ButtonPress.CheckParams(nil))
+	assert.Error(t, ButtonPress.CheckParams(map[string]interface{}{"press_type": "triple"}))
+	assert.Error(t, ButtonPress.CheckParams(map[string]interface{}{"press_type": map[string]interface{}{}}))
+	assert.Error(t, ButtonPress.CheckParams(map[string]interface{}{"count": 1}))
+
+	// 不做类型转换，保存的条件需要与事件的值类型一致
+	ev := Event{Type: "alarm", Params: []Param{{Name: "level", ValType: Int, Min: 1, Max: 3}}}
+	assert.NoError(t, ev.CheckParams(map[string]interface{}{"level": float64(2)}))
+	assert.Error(t, ev.CheckParams(map[string]interface{}{"level": "2"}))
+	assert.Error(t, ev.CheckParams(map[string]interface{}{"level": 4}))
+	assert.Error(t, ev.CheckParams(map[string]interface{}{"level": []interface{}{1}}))
+}
+
+func TestValEqual(t *testing.T) {
+	assert.True(t, ValEqual(1, float64(1)))
+	assert.True(t, ValEqual("on", "on"))
+	assert.False(t, ValEqual("1", 1))
+	assert.False(t, ValEqual(1, "1"))
+	assert.True(t, ValEqual(map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}))
+	assert.False(t, ValEqual(map[string]interface{}{"a": 1}, []interface{}{1}))
+	assert.False(t, ValEqual(nil, 0))
+}